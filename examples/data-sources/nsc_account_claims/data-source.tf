@@ -0,0 +1,14 @@
+# Preview the claims an account JWT would carry, without the operator seed
+data "nsc_account_claims" "app" {
+  name    = "AppAccount"
+  subject = "AAQ5ZFD2KBY7NGAAFOGZYJ3VI3K4EVRPQTBENXZRC2I7ALKU2MWNRLWE"
+  issuer  = "OBSS7FP7UBJGBLHVODV7L6ZI2YKU3AKNA4GRARCVYXNZJYNOQRPWTDHP"
+
+  allow_pub       = ["app.>"]
+  allow_sub       = ["app.>", "_INBOX.>"]
+  max_connections = 100
+}
+
+output "account_claims" {
+  value = jsondecode(data.nsc_account_claims.app.claims_json)
+}
@@ -0,0 +1,19 @@
+data "nsc_account_registry" "all" {
+  accounts = {
+    system  = nsc_account.system.jwt
+    app     = nsc_account.app.jwt
+    billing = nsc_account.billing.jwt
+  }
+}
+
+locals {
+  nats_config = <<-EOT
+    ${nsc_operator.main.server_config}
+    resolver: MEMORY
+    ${data.nsc_account_registry.all.resolver_preload_config}
+  EOT
+}
+
+output "next_account_expiry" {
+  value = "${data.nsc_account_registry.all.next_expiry_account} expires at ${data.nsc_account_registry.all.next_expiry}"
+}
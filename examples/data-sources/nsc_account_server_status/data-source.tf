@@ -0,0 +1,14 @@
+# Wait for the account server to serve the new account JWT before deploying
+# the services that depend on it
+data "nsc_account_server_status" "app" {
+  url             = "https://accounts.example.com/jwt/v1"
+  account         = nsc_account.app.public_key
+  expected_jwt_id = nsc_account.app.jwt_id
+
+  lifecycle {
+    postcondition {
+      condition     = self.healthy && self.up_to_date
+      error_message = "Account server does not serve the new JWT: ${coalesce(self.error, "unknown")}"
+    }
+  }
+}
@@ -0,0 +1,19 @@
+# Keys of the auth callout service
+resource "nsc_nkey" "auth_issuer" {
+  type = "account"
+}
+
+data "nsc_auth_callout_config" "main" {
+  issuer           = nsc_nkey.auth_issuer.public_key
+  account          = "AUTH"
+  auth_users       = ["auth"]
+  allowed_accounts = ["APP"]
+  xkey             = var.auth_callout_xkey
+}
+
+# Include the block in the nats-server configuration; pass
+# nsc_nkey.auth_issuer.seed to the callout service for signing responses
+resource "local_file" "nats_config" {
+  filename = "${path.module}/auth-callout.conf"
+  content  = data.nsc_auth_callout_config.main.config
+}
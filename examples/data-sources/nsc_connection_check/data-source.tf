@@ -0,0 +1,19 @@
+# Verify that the cluster accepts the generated user, and that it lands in
+# the expected account
+check "nats_user_accepted" {
+  data "nsc_connection_check" "service" {
+    url     = "nats://nats.example.com:4222"
+    creds   = nsc_user.service.creds
+    timeout = "10s"
+  }
+
+  assert {
+    condition     = data.nsc_connection_check.service.connected
+    error_message = "NATS rejected the service user: ${coalesce(data.nsc_connection_check.service.error, "unknown error")}"
+  }
+
+  assert {
+    condition     = data.nsc_connection_check.service.account == nsc_account.service.public_key
+    error_message = "The service user was not authenticated into the service account."
+  }
+}
@@ -0,0 +1,12 @@
+# Credentials of the system account user created with the nsc CLI
+data "nsc_creds_file" "sys" {
+  path = pathexpand("~/.local/share/nats/nsc/keys/creds/main/SYS/sys.creds")
+}
+
+output "sys_user_expires_at" {
+  value = data.nsc_creds_file.sys.expires_at
+}
+
+output "sys_user_permissions" {
+  value = jsondecode(data.nsc_creds_file.sys.claims_json).nats.pub
+}
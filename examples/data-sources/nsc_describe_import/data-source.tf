@@ -0,0 +1,16 @@
+# Map an account described with:
+#   nsc describe account APP --json > app.json
+data "nsc_describe_import" "app" {
+  describe_json = file("${path.module}/app.json")
+}
+
+# Write the resource block to a file with:
+#   terraform output -raw app_resource > app.tf
+output "app_resource" {
+  value = data.nsc_describe_import.app.resource_block
+}
+
+# Claims such as exports and imports that have to be added by hand
+output "app_unmapped" {
+  value = data.nsc_describe_import.app.unmapped
+}
@@ -0,0 +1,24 @@
+# Build the claims of the planned account configuration, which are known
+# during plan, unlike the JWT that is only issued during apply
+data "nsc_account_claims" "app" {
+  name             = "App"
+  subject          = nsc_nkey.app.public_key
+  max_disk_storage = "10GiB"
+  max_streams      = 20
+}
+
+# Refuse to reduce JetStream limits below what the account already uses
+check "jetstream_limits" {
+  data "nsc_jetstream_usage" "cluster" {
+    url   = "nats://nats.example.com:4222"
+    creds = nsc_user.sys.creds
+    accounts = {
+      app = data.nsc_account_claims.app.claims_json
+    }
+  }
+
+  assert {
+    condition     = length(data.nsc_jetstream_usage.cluster.over_limit) == 0
+    error_message = "Accounts over their JetStream limits: ${join(", ", data.nsc_jetstream_usage.cluster.over_limit)}"
+  }
+}
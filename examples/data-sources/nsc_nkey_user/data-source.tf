@@ -0,0 +1,22 @@
+# Authenticate clients by nkey on a server without JWTs
+resource "nsc_nkey" "service" {
+  type = "user"
+}
+
+data "nsc_nkey_user" "service" {
+  public_key         = nsc_nkey.service.public_key
+  allow_pub          = ["orders.>"]
+  allow_sub          = ["_INBOX.>"]
+  allow_pub_response = 1
+}
+
+resource "local_file" "authorization_conf" {
+  content  = <<-EOT
+    authorization {
+      users: [
+        ${data.nsc_nkey_user.service.entry}
+      ]
+    }
+  EOT
+  filename = "${path.module}/authorization.conf"
+}
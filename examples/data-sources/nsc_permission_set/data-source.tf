@@ -0,0 +1,26 @@
+data "nsc_permission_set" "order_service" {
+  fragments = [
+    {
+      name      = "orders"
+      allow_pub = ["orders.>"]
+      allow_sub = ["_INBOX.>"]
+    },
+    {
+      name      = "jetstream"
+      allow_pub = ["$JS.API.CONSUMER.INFO.ORDERS.>", "$JS.ACK.ORDERS.>"]
+    },
+    {
+      name     = "platform"
+      deny_pub = ["orders.internal.>"]
+    },
+  ]
+}
+
+resource "nsc_user" "order_service" {
+  name        = "order-service"
+  subject     = nsc_nkey.order_service.public_key
+  issuer_seed = nsc_nkey.account.seed
+  allow_pub   = data.nsc_permission_set.order_service.allow_pub
+  allow_sub   = data.nsc_permission_set.order_service.allow_sub
+  deny_pub    = data.nsc_permission_set.order_service.deny_pub
+}
@@ -0,0 +1,14 @@
+# Warn when a cluster serves another account JWT than the one Terraform issued
+check "resolver_drift" {
+  data "nsc_resolver_drift" "app" {
+    url          = "nats://nats.example.com:4222"
+    creds        = nsc_user.sys.creds
+    account      = nsc_account.app.public_key
+    expected_jwt = nsc_account.app.jwt
+  }
+
+  assert {
+    condition     = data.nsc_resolver_drift.app.in_sync
+    error_message = "The resolver serves the JWT issued at ${coalesce(data.nsc_resolver_drift.app.deployed_issued_at, "never")} for account App, not the latest one."
+  }
+}
@@ -0,0 +1,23 @@
+data "nsc_role" "publisher" {
+  name               = "publisher"
+  allow_pub          = ["orders.>"]
+  allow_sub          = ["_INBOX.>"]
+  allow_pub_response = 1
+  max_payload        = "1MiB"
+}
+
+data "nsc_role" "consumer" {
+  name      = "consumer"
+  allow_sub = ["orders.>"]
+  allow_pub = ["$JS.API.>"]
+}
+
+resource "nsc_user" "order_service" {
+  name        = "order-service"
+  subject     = nsc_nkey.order_service.public_key
+  issuer_seed = nsc_nkey.account.seed
+  role        = data.nsc_role.publisher.role
+
+  # Attributes set on the user take precedence over the role
+  max_payload = "4MiB"
+}
@@ -0,0 +1,21 @@
+# Run a small deployment without operator mode, from the same account and
+# user definitions
+data "nsc_static_config" "nats" {
+  accounts = {
+    SYS = nsc_account.system.jwt
+    app = nsc_account.app.jwt
+  }
+  users = {
+    service = nsc_user.service.jwt
+    monitor = nsc_user.monitor.jwt
+  }
+  system_account = "SYS"
+}
+
+resource "local_file" "accounts_conf" {
+  content  = data.nsc_static_config.nats.config
+  filename = "${path.module}/accounts.conf"
+}
+
+# nats-server.conf:
+#   include ./accounts.conf
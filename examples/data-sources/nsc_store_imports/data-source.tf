@@ -0,0 +1,17 @@
+# Scan the stores of the nsc CLI for the keys of the "acme" operator, its
+# accounts and users
+data "nsc_store_imports" "nsc" {
+  stores_dir = pathexpand("~/.local/share/nats/nsc/stores")
+  operator   = "acme"
+}
+
+# Write the import blocks to a file with:
+#   terraform output -raw import_blocks > imports.tf
+output "import_blocks" {
+  value = data.nsc_store_imports.nsc.import_blocks
+}
+
+# Keys the keystore does not hold a seed for and that cannot be imported
+output "missing_seeds" {
+  value = [for key in data.nsc_store_imports.nsc.keys : key.address if key.seed_path == null]
+}
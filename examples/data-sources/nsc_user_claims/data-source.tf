@@ -0,0 +1,15 @@
+# Preview the claims a user JWT would carry, without the account seed
+data "nsc_user_claims" "service" {
+  name    = "ServiceUser"
+  subject = "UBMCWCTRCQASVN2WIOU2RAESLIP7JD3E7NKGMDJ4UY7GTZWQZ4VA3UAY"
+  issuer  = "AAQ5ZFD2KBY7NGAAFOGZYJ3VI3K4EVRPQTBENXZRC2I7ALKU2MWNRLWE"
+
+  allow_pub   = ["app.requests.>"]
+  allow_sub   = ["_INBOX.>"]
+  max_payload = "1MiB"
+  expires_in  = "720h"
+}
+
+output "user_claims" {
+  value = jsondecode(data.nsc_user_claims.service.claims_json)
+}
@@ -0,0 +1,11 @@
+data "aws_secretsmanager_secret_version" "bootstrap" {
+  secret_id = "nats/bootstrap-user"
+}
+
+locals {
+  # Split credentials kept in a secret manager into their JWT and seed. The
+  # secret is sensitive, so both results are too.
+  bootstrap      = provider::nsc::parse_creds(data.aws_secretsmanager_secret_version.bootstrap.secret_string)
+  bootstrap_jwt  = local.bootstrap.jwt
+  bootstrap_seed = local.bootstrap.seed
+}
@@ -0,0 +1,15 @@
+locals {
+  account_jwts = {
+    (nsc_account.system.public_key) = nsc_account.system.jwt
+    (nsc_account.app.public_key)    = nsc_account.app.jwt
+  }
+
+  # Render the resolver_preload block for a nats-server configuration
+  nats_config = <<-EOT
+    operator: ${nsc_operator.main.jwt}
+    system_account: ${nsc_account.system.public_key}
+
+    resolver: MEMORY
+    ${provider::nsc::resolver_preload(local.account_jwts)}
+  EOT
+}
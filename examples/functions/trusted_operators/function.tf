@@ -0,0 +1,10 @@
+locals {
+  # Trust both operators while accounts are migrated from the old to the
+  # new one
+  nats_config = <<-EOT
+    ${provider::nsc::trusted_operators([nsc_operator.old.jwt, nsc_operator.new.jwt])}
+
+    resolver: MEMORY
+    ${provider::nsc::resolver_preload(local.account_jwts)}
+  EOT
+}
@@ -0,0 +1,7 @@
+# Fail the plan when a JWT published elsewhere was not issued by the operator
+check "account_jwt_signature" {
+  assert {
+    condition     = provider::nsc::verify_jwt(var.published_account_jwt, nsc_operator.main.public_key)
+    error_message = "The published account JWT is not signed by the operator."
+  }
+}
@@ -0,0 +1,4 @@
+# Append a JSON line for every JWT issued during apply
+provider "nsc" {
+  audit_log = "${path.root}/nats-jwt-audit.jsonl"
+}
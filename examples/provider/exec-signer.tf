@@ -0,0 +1,23 @@
+# Sign account JWTs with an external command, e.g. a wrapper around an HSM
+# or a cloud KMS. The command is run with NSC_SIGNER_OPERATION and
+# NSC_SIGNER_KEY in its environment.
+provider "nsc" {
+  signer {
+    exec {
+      command = "/usr/local/bin/nats-kms-signer"
+      args    = ["--region", "eu-west-1"]
+    }
+  }
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+# With only issuer_public_key set, the public key is passed to the command
+# as NSC_SIGNER_KEY and no public key lookup is performed.
+resource "nsc_account" "example" {
+  name              = "example"
+  subject           = nsc_nkey.account.public_key
+  issuer_public_key = "OBX4EK25A4D7PVZUPZKM2NYXDBRGYVHAOD2W23WFKKNJW3Y7FVUZ7T2U"
+}
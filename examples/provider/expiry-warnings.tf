@@ -0,0 +1,4 @@
+# Warn about JWTs expiring within the next 30 days
+provider "nsc" {
+  warn_expiry_within = "720h"
+}
@@ -8,6 +8,6 @@ terraform {
 }
 
 provider "nsc" {
-  # This provider has no configuration options
-  # All JWT tokens and keys are managed through resources
+  # No configuration is required; all JWT tokens and keys are managed
+  # through resources. See "External Signing" for the optional signer block.
 }
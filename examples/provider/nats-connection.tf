@@ -0,0 +1,25 @@
+# Connect the connection check and JetStream usage data sources over mutual
+# TLS with a system account user by default
+provider "nsc" {
+  nats {
+    creds    = nsc_user.sys.creds
+    tls_ca   = file("${path.root}/tls/ca.pem")
+    tls_cert = file("${path.root}/tls/client.pem")
+    tls_key  = file("${path.root}/tls/client-key.pem")
+    timeout  = "15s"
+  }
+}
+
+# Only the URL is needed; the credentials of the service user replace those
+# of the provider, the TLS options are kept
+data "nsc_connection_check" "service" {
+  url   = "tls://nats.example.com:4222"
+  creds = nsc_user.service.creds
+}
+
+data "nsc_jetstream_usage" "all" {
+  url = "tls://nats.example.com:4222"
+  accounts = {
+    service = nsc_account.service.jwt
+  }
+}
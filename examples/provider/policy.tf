@@ -0,0 +1,24 @@
+provider "nsc" {
+  # Users must not publish to system subjects
+  policy {
+    name           = "no-system-publish"
+    claim_types    = ["user"]
+    deny_allow_pub = ["$SYS.>"]
+  }
+
+  # Accounts and users must be tagged with their team
+  policy {
+    name         = "team-tags"
+    claim_types  = ["account", "user"]
+    require_tags = ["team:*"]
+  }
+
+  # Warn about users valid for more than 90 days, or bearer tokens
+  policy {
+    name           = "short-lived-users"
+    claim_types    = ["user"]
+    severity       = "warning"
+    max_expires_in = "2160h"
+    forbid_bearer  = true
+  }
+}
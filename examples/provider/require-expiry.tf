@@ -0,0 +1,4 @@
+# Fail plans for accounts and users without expires_in or expires_at
+provider "nsc" {
+  require_expiry = ["account", "user"]
+}
@@ -0,0 +1,21 @@
+# Encrypt generated seeds in state. Keep the key outside of the state backend,
+# e.g. in the CI secret store, and pass it as NSC_STATE_ENCRYPTION_KEY.
+provider "nsc" {
+  state_encryption_key = var.nsc_state_encryption_key
+}
+
+variable "nsc_state_encryption_key" {
+  type      = string
+  sensitive = true
+}
+
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+# The encrypted seed is decrypted by the provider when it is used
+resource "nsc_operator" "main" {
+  name        = "main"
+  subject     = nsc_nkey.operator.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
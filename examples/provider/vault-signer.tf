@@ -0,0 +1,40 @@
+# Sign account and user JWTs with Vault transit keys.
+# The keys must be of type ed25519:
+#
+#   vault secrets enable transit
+#   vault write transit/keys/nats-operator type=ed25519
+#   vault write transit/keys/nats-account type=ed25519
+provider "nsc" {
+  signer {
+    vault {
+      address = "https://vault.example.com:8200"
+      mount   = "transit"
+      # token defaults to the VAULT_TOKEN environment variable
+    }
+  }
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+# The public key of the nats-operator transit key must be the operator key
+# or one of the operator's signing keys.
+resource "nsc_account" "example" {
+  name            = "example"
+  subject         = nsc_nkey.account.public_key
+  issuer_key_name = "nats-operator"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+# The public key of the nats-account transit key must be listed in the
+# account's signing_keys, since the user is not signed by its subject key.
+resource "nsc_user" "example" {
+  name            = "example"
+  subject         = nsc_nkey.user.public_key
+  issuer_key_name = "nats-account"
+  issuer_account  = nsc_account.example.public_key
+}
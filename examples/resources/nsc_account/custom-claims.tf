@@ -0,0 +1,20 @@
+# Account carrying claims the provider does not model as attributes
+resource "nsc_account" "billing" {
+  name        = "Billing"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+
+  max_connections = 100
+
+  # Deep-merged into the account claims: "nats.limits" keeps
+  # max_connections and adds the leaf node limit
+  custom_claims_json = jsonencode({
+    nats = {
+      description = "Billing services"
+      info_url    = "https://wiki.example.com/billing"
+      limits = {
+        leaf = 2
+      }
+    }
+  })
+}
@@ -0,0 +1,24 @@
+# Structured default permissions, an alternative to the flat
+# allow_pub/allow_sub/deny_pub/deny_sub attributes
+resource "nsc_account" "service" {
+  name        = "ServiceAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+
+  default_permissions {
+    pub {
+      allow = ["service.>", "_INBOX.>"]
+      deny  = ["service.admin.>"]
+    }
+
+    sub {
+      allow = ["service.requests.>", "_INBOX.>"]
+    }
+
+    # Allow replying to requests
+    resp {
+      max = 1
+      ttl = "5s"
+    }
+  }
+}
@@ -25,14 +25,29 @@ resource "nsc_account" "jetstream" {
   allow_sub = ["app.>", "_INBOX.>"]
 
   # JetStream limits (setting these enables JetStream for this account)
-  max_memory_storage = 1073741824  # 1GB memory storage
-  max_disk_storage   = 10737418240 # 10GB disk storage
-  max_streams        = 10          # Maximum 10 streams
-  max_consumers      = 100         # Maximum 100 consumers
+  max_memory_storage = "1GiB"
+  max_disk_storage   = "10GiB"
+  max_streams        = 10  # Maximum 10 streams
+  max_consumers      = 100 # Maximum 100 consumers
 
   # Optional: Additional JetStream stream limits
-  max_ack_pending         = 1000  # Max unacknowledged messages per consumer
-  max_memory_stream_bytes = -1    # Unlimited memory per stream
-  max_disk_stream_bytes   = -1    # Unlimited disk per stream
-  max_bytes_required      = false # Don't require max_bytes on streams
+  max_ack_pending         = 1000        # Max unacknowledged messages per consumer
+  max_memory_stream_bytes = "unlimited" # Unlimited memory per stream
+  max_disk_stream_bytes   = "unlimited" # Unlimited disk per stream
+  max_bytes_required      = false       # Don't require max_bytes on streams
+}
+
+resource "nsc_nkey" "unlimited" {
+  type = "account"
+}
+
+# Account with JetStream enabled without limits, except for the explicit
+# max_disk_storage
+resource "nsc_account" "unlimited" {
+  name        = "UnlimitedJetStreamAccount"
+  subject     = nsc_nkey.unlimited.public_key
+  issuer_seed = nsc_nkey.operator.seed
+
+  jetstream_enabled = true
+  max_disk_storage  = "100GiB"
 }
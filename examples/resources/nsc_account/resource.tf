@@ -26,7 +26,7 @@ resource "nsc_account" "app" {
 
   # Account limits
   max_connections = 1000
-  max_payload     = 1048576 # 1MB max message size
+  max_payload     = "1MiB"
 }
 
 # Access the account JWT
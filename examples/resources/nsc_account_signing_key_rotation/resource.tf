@@ -0,0 +1,37 @@
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+# Change rotation_trigger to rotate. The previous key stays listed on the
+# account for grace_period; the first plan after that removes it.
+resource "nsc_account_signing_key_rotation" "app" {
+  rotation_trigger = "2026-q1"
+  grace_period     = "168h"
+}
+
+resource "nsc_account" "app" {
+  name         = "AppAccount"
+  subject      = nsc_nkey.account.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = nsc_account_signing_key_rotation.app.signing_keys
+}
+
+# Users are re-issued with the new key in the same apply
+resource "nsc_user" "service" {
+  name           = "ServiceUser"
+  subject        = nsc_nkey.user.public_key
+  issuer_seed    = nsc_account_signing_key_rotation.app.current_seed
+  issuer_account = nsc_account.app.subject
+
+  lifecycle {
+    replace_triggered_by = [nsc_account_signing_key_rotation.app.current_public_key]
+  }
+}
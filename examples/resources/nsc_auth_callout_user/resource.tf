@@ -0,0 +1,27 @@
+resource "nsc_nkey" "app" {
+  type = "account"
+}
+
+# The user the auth callout service connects as
+resource "nsc_auth_callout_user" "auth" {
+  name         = "auth-callout"
+  issuer_seed  = nsc_nkey.app.seed
+  response_ttl = "2s"
+}
+
+# Users of the account, except the service itself, are authenticated by the
+# callout service
+resource "nsc_account" "app" {
+  name        = "App"
+  subject     = nsc_nkey.app.public_key
+  issuer_seed = var.operator_seed
+
+  auth_callout {
+    auth_users = [nsc_auth_callout_user.auth.public_key]
+  }
+}
+
+resource "local_sensitive_file" "auth_creds" {
+  content  = nsc_auth_callout_user.auth.creds
+  filename = "${path.module}/auth-callout.creds"
+}
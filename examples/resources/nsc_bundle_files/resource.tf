@@ -0,0 +1,23 @@
+# Hand the trust tree to the operations team of an air-gapped site:
+#   /srv/nats/bundle/operator.jwt
+#   /srv/nats/bundle/accounts/APP.jwt
+#   /srv/nats/bundle/users/APP/<name>.creds
+resource "nsc_bundle_files" "handoff" {
+  directory      = "/srv/nats/bundle"
+  file_mode      = "0640"
+  directory_mode = "0750"
+
+  operator_jwt = nsc_operator.main.jwt
+  accounts = {
+    (nsc_account.app.name) = nsc_account.app.jwt
+  }
+  users = {
+    (nsc_account.app.name) = nsc_users.app.creds
+  }
+}
+
+# The manifest lists the SHA-256, subject and expiry of every file, for the
+# deployment pipeline to verify the hand-off
+output "bundle_manifest" {
+  value = nsc_bundle_files.handoff.manifest
+}
@@ -0,0 +1,19 @@
+# Re-sign an account JWT issued elsewhere with an operator signing key,
+# keeping its claims unchanged
+resource "nsc_nkey" "operator_signing" {
+  type = "operator"
+}
+
+resource "nsc_jwt_resign" "legacy_account" {
+  jwt         = file("${path.module}/legacy-account.jwt")
+  issuer_seed = nsc_nkey.operator_signing.seed
+
+  # issuer_seed is write-only; re-sign when the key changes
+  lifecycle {
+    replace_triggered_by = [nsc_nkey.operator_signing.public_key]
+  }
+}
+
+output "account_jwt" {
+  value = nsc_jwt_resign.legacy_account.resigned_jwt
+}
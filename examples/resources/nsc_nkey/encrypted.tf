@@ -0,0 +1,23 @@
+# Encrypt the seed to an age recipient so it can be handed out safely
+resource "nsc_nkey" "ci" {
+  type = "user"
+
+  encryption_recipients = ["age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p"]
+}
+
+# Or encrypt it with a passphrase instead
+variable "seed_passphrase" {
+  type      = string
+  sensitive = true
+}
+
+resource "nsc_nkey" "admin" {
+  type = "user"
+
+  encryption_passphrase = var.seed_passphrase
+}
+
+# Only ciphertext leaves Terraform; decrypt with `age --decrypt`
+output "ci_seed" {
+  value = nsc_nkey.ci.encrypted_seed
+}
@@ -0,0 +1,10 @@
+variable "legacy_operator_seed" {
+  type      = string
+  sensitive = true
+}
+
+# Adopt an existing operator key, e.g. one created with the nsc CLI.
+# The type and public key are derived from the seed.
+resource "nsc_nkey" "legacy_operator" {
+  seed = var.legacy_operator_seed
+}
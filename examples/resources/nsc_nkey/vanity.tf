@@ -0,0 +1,12 @@
+# Generate account keys whose public keys are easy to tell apart:
+# production accounts start with ADPRD, staging accounts with ADSTG
+resource "nsc_nkey" "prod_account" {
+  type          = "account"
+  vanity_prefix = "ADPRD"
+}
+
+resource "nsc_nkey" "staging_account" {
+  type                = "account"
+  vanity_prefix       = "ADSTG"
+  vanity_max_attempts = 5000000
+}
@@ -0,0 +1,12 @@
+# Keep the seeds in an nsc keystore on the operations host, so that the nsc CLI
+# can sign with them when Terraform is unavailable:
+#   NKEYS_PATH=/secure/nats/keys nsc ...
+resource "nsc_nkey_files" "break_glass" {
+  directory = "/secure/nats/keys"
+  layout    = "nsc"
+  seeds = [
+    nsc_nkey.operator.seed,
+    nsc_nkey.operator_signing.seed,
+    nsc_nkey.account.seed,
+  ]
+}
@@ -0,0 +1,23 @@
+# Operator, system account and system user in one resource
+resource "nsc_operator_set" "main" {
+  name = "MyOperator"
+}
+
+# Further accounts are signed with the operator signing key
+resource "nsc_nkey" "app" {
+  type = "account"
+}
+
+resource "nsc_account" "app" {
+  name        = "App"
+  subject     = nsc_nkey.app.public_key
+  issuer_seed = nsc_operator_set.main.operator_signing_key_seed
+}
+
+resource "local_file" "server_config" {
+  content  = <<-EOT
+    ${nsc_operator_set.main.server_config}
+    ${provider::nsc::resolver_preload([nsc_operator_set.main.system_account_jwt, nsc_account.app.jwt])}
+  EOT
+  filename = "${path.module}/operator.conf"
+}
@@ -0,0 +1,32 @@
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+# Change rotation_trigger to rotate. The previous key stays listed on the
+# operator for grace_period; the first plan after that removes it.
+resource "nsc_operator_signing_key_rotation" "main" {
+  rotation_trigger = "2026-q1"
+  grace_period     = "720h"
+}
+
+resource "nsc_operator" "main" {
+  name         = "MyOperator"
+  subject      = nsc_nkey.operator.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = nsc_operator_signing_key_rotation.main.signing_keys
+}
+
+# Accounts are re-signed with the new key in the same apply
+resource "nsc_account" "app" {
+  name        = "AppAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_operator_signing_key_rotation.main.current_seed
+
+  lifecycle {
+    replace_triggered_by = [nsc_operator_signing_key_rotation.main.current_public_key]
+  }
+}
@@ -0,0 +1,9 @@
+# Terraform 1.12 and later can import by resource identity
+import {
+  to = nsc_revocation.partner
+  identity = {
+    account        = "ACOZKRJ4F67PN7XTHGSQMKQYK5JLQXOBWZRD2X7CDQAF6C3AGFXCY6ZM"
+    public_key     = "ADLGEVANYDKDQ6WYXPNBEGVUURXZY4LLLK5BJPOUDN6NGNXLNH4ATPWR"
+    export_subject = "orders.>"
+  }
+}
@@ -0,0 +1,8 @@
+# Import a user revocation: <account>/<user public key>
+terraform import nsc_revocation.compromised ACOZKRJ4F67PN7XTHGSQMKQYK5JLQXOBWZRD2X7CDQAF6C3AGFXCY6ZM/UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4
+
+# Import an activation revocation: <account>/<export subject>/<importing account public key>
+terraform import nsc_revocation.partner 'ACOZKRJ4F67PN7XTHGSQMKQYK5JLQXOBWZRD2X7CDQAF6C3AGFXCY6ZM/orders.>/ADLGEVANYDKDQ6WYXPNBEGVUURXZY4LLLK5BJPOUDN6NGNXLNH4ATPWR'
+
+# revoked_at is not part of the import ID. Set it in the configuration to keep
+# the time already in the account JWT.
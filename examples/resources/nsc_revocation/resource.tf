@@ -0,0 +1,40 @@
+# Security module: revoke a compromised user
+resource "nsc_revocation" "compromised" {
+  account    = var.app_account_public_key
+  public_key = "UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4"
+}
+
+# Revoke the activation token of an importing account. JWTs issued at or
+# before revoked_at are revoked.
+resource "nsc_revocation" "partner" {
+  account        = var.app_account_public_key
+  public_key     = "ADLGEVANYDKDQ6WYXPNBEGVUURXZY4LLLK5BJPOUDN6NGNXLNH4ATPWR"
+  export_subject = "orders.>"
+  revoked_at     = "2026-01-01T00:00:00Z"
+}
+
+# Incident response: revoke all users issued at or before revoked_at, so
+# that every user credential has to be reissued
+resource "nsc_revocation" "all_users" {
+  account    = var.app_account_public_key
+  public_key = "*"
+  revoked_at = "2026-03-01T12:00:00Z"
+}
+
+output "revocations" {
+  value = [nsc_revocation.compromised, nsc_revocation.partner, nsc_revocation.all_users]
+}
+
+# Application module: the account takes the revocations as they are
+resource "nsc_account" "app" {
+  name        = "AppAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+  revocations = data.terraform_remote_state.security.outputs.revocations
+
+  export {
+    subject        = "orders.>"
+    type           = "stream"
+    token_required = true
+  }
+}
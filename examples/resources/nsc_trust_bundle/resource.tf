@@ -0,0 +1,16 @@
+resource "nsc_trust_bundle" "fleet" {
+  operator_jwt       = nsc_operator.main.jwt
+  system_account_jwt = nsc_account.system.jwt
+  account_jwts       = [nsc_account.app.jwt]
+  format             = "tar"
+}
+
+# Ship the bundle to air-gapped sites
+resource "local_file" "bundle" {
+  content_base64 = nsc_trust_bundle.fleet.content_base64
+  filename       = "${path.module}/trust-bundle-v${nsc_trust_bundle.fleet.version}.tar"
+}
+
+output "bundle_sha256" {
+  value = nsc_trust_bundle.fleet.sha256
+}
@@ -39,7 +39,7 @@ resource "nsc_user" "api_client" {
 
   # Limits
   max_subscriptions = 10
-  max_payload       = 65536 # 64KB
+  max_payload       = "64KiB"
 
   # Expiry (recommended for bearer tokens)
   expires_in = "720h" # 30 days
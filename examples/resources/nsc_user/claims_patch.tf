@@ -0,0 +1,17 @@
+# User with a claim field the provider has no attribute for yet. The patch
+# is applied to the final claims right before signing; null removes a field.
+resource "nsc_user" "patched" {
+  name        = "PatchedUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+
+  allow_pub = ["app.>"]
+  allow_sub = ["app.>"]
+
+  claims_patch_json = jsonencode({
+    nats = {
+      times = [{ start = "08:00:00", end = "18:00:00" }]
+      tags  = null
+    }
+  })
+}
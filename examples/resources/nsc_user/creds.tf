@@ -0,0 +1,40 @@
+# Generate keys
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+# Create operator and account
+resource "nsc_operator" "main" {
+  name        = "MyOperator"
+  subject     = nsc_nkey.operator.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_account" "app" {
+  name        = "AppAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+# Pass the user seed to get a credentials file without a separate nsc_creds data source
+resource "nsc_user" "worker" {
+  name        = "Worker"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  seed        = nsc_nkey.user.seed
+
+  allow_sub = ["jobs.> workers"]
+}
+
+output "worker_creds" {
+  value     = nsc_user.worker.creds
+  sensitive = true
+}
@@ -0,0 +1,18 @@
+# User with vendor-specific claims read by an auth callout service
+resource "nsc_user" "custom" {
+  name        = "CustomUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+
+  allow_pub = ["app.>"]
+  allow_sub = ["app.>"]
+
+  # Top-level keys are added to the JWT as is, keys under "nats"
+  # are merged into the generated user claims
+  custom_claims_json = jsonencode({
+    acme = {
+      tenant = "tenant-42"
+      tier   = "gold"
+    }
+  })
+}
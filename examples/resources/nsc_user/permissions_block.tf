@@ -0,0 +1,24 @@
+# Structured permissions, an alternative to the flat
+# allow_pub/allow_sub/deny_pub/deny_sub attributes
+resource "nsc_user" "service" {
+  name        = "service"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+
+  permissions {
+    pub {
+      allow = ["service.>", "_INBOX.>"]
+      deny  = ["service.admin.>"]
+    }
+
+    sub {
+      allow = ["service.requests.>", "_INBOX.>"]
+    }
+
+    # Allow replying to requests
+    resp {
+      max = 1
+      ttl = "5s"
+    }
+  }
+}
@@ -36,7 +36,7 @@ resource "nsc_user" "service" {
 
   # Limits
   max_subscriptions = 100
-  max_payload       = 1048576 # 1MB
+  max_payload       = "1MiB"
 }
 
 # Access the JWT (safe to use in logs since bearer = false)
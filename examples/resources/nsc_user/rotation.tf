@@ -0,0 +1,18 @@
+# User re-issued every 7 days, with a 30-day expiry as a safety net
+resource "nsc_user" "rotated" {
+  name        = "RotatedUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+
+  # The first plan after rotate_at re-issues the JWT, even without
+  # configuration changes. Run plans at least as often as the period.
+  rotation_period = "168h" # 7 days
+  expires_in      = "720h" # 30 days
+
+  allow_pub = ["app.>"]
+  allow_sub = ["app.>"]
+}
+
+output "rotated_next_rotation" {
+  value = nsc_user.rotated.rotate_at
+}
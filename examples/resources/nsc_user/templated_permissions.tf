@@ -0,0 +1,13 @@
+# Permission subjects may contain the templates {{name()}}, {{subject()}},
+# {{tag(name)}}, {{account-name()}}, {{account-subject()}} and
+# {{account-tag(name)}}, expanded by nats-server from the user and account
+# claims. Other template functions are rejected at plan time.
+resource "nsc_user" "templated" {
+  name        = "sensor-17"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  tag         = ["region:eu"]
+
+  allow_pub = ["telemetry.{{tag(region)}}.{{name()}}"]
+  allow_sub = ["commands.{{subject()}}.>", "_INBOX.>"]
+}
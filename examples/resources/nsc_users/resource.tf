@@ -0,0 +1,34 @@
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+# One resource for all service users of the account
+resource "nsc_users" "services" {
+  issuer_seed = nsc_nkey.account.seed
+
+  template {
+    allow_pub         = ["app.>"]
+    allow_sub         = ["app.>", "_INBOX.>"]
+    max_subscriptions = 100
+    expires_in        = "2160h"
+  }
+
+  users = {
+    orders   = {}
+    payments = {}
+    billing = {
+      allow_pub = ["app.billing.>"]
+    }
+    monitor = {
+      deny_pub  = [">"]
+      allow_sub = ["$SYS.ACCOUNT.>"]
+    }
+  }
+}
+
+resource "local_sensitive_file" "creds" {
+  for_each = nsc_users.services.creds
+
+  content  = each.value
+  filename = "${path.module}/creds/${each.key}.creds"
+}
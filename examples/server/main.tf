@@ -107,12 +107,12 @@ resource "nsc_account" "application" {
   # Account limits (optional)
   max_connections   = 1000
   max_subscriptions = 10000
-  max_data          = -1      # Unlimited data
-  max_payload       = 1048576 # 1MB max message size
+  max_data          = "unlimited"
+  max_payload       = "1MiB"
 
   # JetStream limits (optional, enables JetStream for this account)
-  max_memory_storage = 1073741824  # 1GB memory storage
-  max_disk_storage   = 10737418240 # 10GB disk storage
+  max_memory_storage = "1GiB"
+  max_disk_storage   = "10GiB"
   max_streams        = 10
   max_consumers      = 100
 }
@@ -141,9 +141,9 @@ resource "nsc_user" "app_user" {
   deny_sub  = ["app.admin.>"]
 
   # User-level limits
-  max_subscriptions = 100       # Max number of subscriptions
-  max_data          = 104857600 # 100MB max data per day
-  max_payload       = 1048576   # 1MB max message size
+  max_subscriptions = 100 # Max number of subscriptions
+  max_data          = "100MiB"
+  max_payload       = "1MiB"
 
   # Connection type restrictions - only standard connections
   allowed_connection_types = ["STANDARD"]
@@ -180,6 +180,11 @@ data "nsc_creds" "app_admin" {
 
 # Generate NATS server configuration
 locals {
+  account_jwts = {
+    (nsc_account.system.public_key)      = nsc_account.system.jwt
+    (nsc_account.application.public_key) = nsc_account.application.jwt
+  }
+
   nats_config = <<-EOT
     # NATS Server Configuration with JWT Authentication
     # Generated by Terraform
@@ -194,10 +199,7 @@ locals {
 
     # Resolver for JWT/Accounts
     resolver: MEMORY
-    resolver_preload: {
-      ${nsc_account.system.public_key}: ${nsc_account.system.jwt}
-      ${nsc_account.application.public_key}: ${nsc_account.application.jwt}
-    }
+    ${provider::nsc::resolver_preload(local.account_jwts)}
 
     # Logging
     debug: false
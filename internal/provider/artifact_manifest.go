@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+)
+
+// artifactManifestVersion is the version of the manifest format.
+const artifactManifestVersion = 1
+
+// artifactManifest lists the files written by a resource, so that deployment
+// pipelines can verify them.
+type artifactManifest struct {
+	Version   int                     `json:"version"`
+	Artifacts []artifactManifestEntry `json:"artifacts"`
+}
+
+// artifactManifestEntry is a file of the manifest. Subject and type are those
+// of the JWT, creds or seed in the file.
+type artifactManifestEntry struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	SHA256    string `json:"sha256"`
+	Type      string `json:"type,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// newArtifactManifestEntry returns the manifest entry of a file written to
+// path. name is the path relative to the directory of the resource.
+func newArtifactManifestEntry(name, path string, content []byte) artifactManifestEntry {
+	entry := artifactManifestEntry{
+		Name:   filepath.ToSlash(name),
+		Path:   path,
+		SHA256: fmt.Sprintf("%x", sha256.Sum256(content)),
+	}
+
+	// Creds hold the JWT between decorations; a bare JWT is returned as is
+	if token, err := jwt.ParseDecoratedJWT(content); err == nil {
+		if claims, err := jwt.Decode(token); err == nil {
+			claimsData := claims.Claims()
+			entry.Type = string(claims.ClaimType())
+			entry.Subject = claimsData.Subject
+			if claimsData.Expires != 0 {
+				entry.ExpiresAt = time.Unix(claimsData.Expires, 0).UTC().Format(time.RFC3339)
+			}
+			return entry
+		}
+	}
+	if kp, keyType, err := parseNKeySeed(string(content)); err == nil {
+		entry.Type = keyType
+		entry.Subject, _ = kp.PublicKey()
+	}
+	return entry
+}
+
+// renderArtifactManifest renders the manifest of the entries as JSON, ordered
+// by name.
+func renderArtifactManifest(entries []artifactManifestEntry) (string, error) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	manifest := artifactManifest{
+		Version:   artifactManifestVersion,
+		Artifacts: entries,
+	}
+	if manifest.Artifacts == nil {
+		manifest.Artifacts = []artifactManifestEntry{}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to render manifest: %w", err)
+	}
+	return string(content), nil
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestNewArtifactManifestEntry(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	userSeed, _ := userKP.Seed()
+
+	expires := time.Now().Add(time.Hour).Unix()
+	claims := jwt.NewUserClaims(userPubKey)
+	claims.Expires = expires
+	token, err := claims.Encode(accountKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := jwt.FormatUserConfig(token, userSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range map[string][]byte{"user.jwt": []byte(token), "user.creds": creds} {
+		entry := newArtifactManifestEntry(name, "/bundle/"+name, content)
+		if entry.Type != "user" || entry.Subject != userPubKey || entry.ExpiresAt != time.Unix(expires, 0).UTC().Format(time.RFC3339) {
+			t.Errorf("%s: unexpected entry %+v", name, entry)
+		}
+		if entry.SHA256 != fmt.Sprintf("%x", sha256.Sum256(content)) {
+			t.Errorf("%s: unexpected checksum %s", name, entry.SHA256)
+		}
+	}
+
+	entry := newArtifactManifestEntry("key.nk", "/keys/key.nk", userSeed)
+	if entry.Type != "user" || entry.Subject != userPubKey || entry.ExpiresAt != "" {
+		t.Errorf("unexpected seed entry %+v", entry)
+	}
+
+	entry = newArtifactManifestEntry("notes.txt", "/notes.txt", []byte("text"))
+	if entry.Type != "" || entry.Subject != "" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
+
+func TestRenderArtifactManifest(t *testing.T) {
+	content, err := renderArtifactManifest([]artifactManifestEntry{{Name: "b"}, {Name: "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest artifactManifest
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Version != artifactManifestVersion || len(manifest.Artifacts) != 2 || manifest.Artifacts[0].Name != "a" {
+		t.Errorf("unexpected manifest: %s", content)
+	}
+
+	empty, err := renderArtifactManifest(nil)
+	if err != nil || !strings.Contains(empty, `"artifacts": []`) {
+		t.Errorf("unexpected empty manifest: %s, %v", empty, err)
+	}
+}
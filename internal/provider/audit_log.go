@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/nats-io/jwt/v2"
+)
+
+// auditLog appends a JSON line for every JWT the provider issues to the file
+// set by the provider's audit_log, so issuance can be audited independently
+// of Terraform state. The provider does not know resource addresses, so
+// records name the resource type and the subject instead.
+type auditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// auditRecord is a line of the audit log.
+type auditRecord struct {
+	Time          string `json:"time"`
+	Resource      string `json:"resource"`
+	Action        string `json:"action"`
+	ClaimType     string `json:"claim_type"`
+	Name          string `json:"name,omitempty"`
+	Subject       string `json:"subject"`
+	Issuer        string `json:"issuer"`
+	IssuerAccount string `json:"issuer_account,omitempty"`
+	JWTID         string `json:"jwt_id"`
+	IssuedAt      string `json:"issued_at"`
+	ExpiresAt     string `json:"expires_at,omitempty"`
+	Fingerprint   string `json:"fingerprint"`
+}
+
+func newAuditLog(path string) *auditLog {
+	return &auditLog{path: path}
+}
+
+// record appends a record of a JWT issued by a resource of the given type.
+// Action is create or update. A nil log records nothing. Failures are
+// warnings, as the JWT has been issued by then.
+func (l *auditLog) record(resourceType, action, token string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if l == nil {
+		return diags
+	}
+
+	line, err := auditLine(resourceType, action, token, time.Now())
+	if err == nil {
+		err = l.append(line)
+	}
+	if err != nil {
+		diags.AddWarning(
+			"Failed to write audit log",
+			fmt.Sprintf("The %s JWT was issued, but could not be recorded in %s: %s", resourceType, l.path, err),
+		)
+	}
+
+	return diags
+}
+
+func (l *auditLog) append(line []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// auditLine renders the audit record of a JWT as a line of JSON. The
+// fingerprint is the SHA-256 of the token.
+func auditLine(resourceType, action, token string, now time.Time) ([]byte, error) {
+	claims, err := jwt.Decode(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JWT: %w", err)
+	}
+	claimsData := claims.Claims()
+
+	record := auditRecord{
+		Time:        now.UTC().Format(time.RFC3339),
+		Resource:    resourceType,
+		Action:      action,
+		ClaimType:   string(claims.ClaimType()),
+		Name:        claimsData.Name,
+		Subject:     claimsData.Subject,
+		Issuer:      claimsData.Issuer,
+		JWTID:       claimsData.ID,
+		IssuedAt:    time.Unix(claimsData.IssuedAt, 0).UTC().Format(time.RFC3339),
+		Fingerprint: fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(token))),
+	}
+	switch c := claims.(type) {
+	case *jwt.UserClaims:
+		record.IssuerAccount = c.IssuerAccount
+	case *jwt.ActivationClaims:
+		record.IssuerAccount = c.IssuerAccount
+	}
+	if claimsData.Expires != 0 {
+		record.ExpiresAt = time.Unix(claimsData.Expires, 0).UTC().Format(time.RFC3339)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
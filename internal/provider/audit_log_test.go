@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAuditLog_record(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+
+	claims := jwt.NewUserClaims(userPubKey)
+	claims.Name = "app"
+	claims.Expires = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	userJWT, err := claims.Encode(accountKP)
+	if err != nil {
+		t.Fatalf("failed to encode user JWT: %v", err)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := newAuditLog(logPath)
+	for _, action := range []string{"create", "update"} {
+		if diags := log.record("nsc_user", action, userJWT); diags.HasError() || diags.WarningsCount() > 0 {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []auditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record auditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	record := records[0]
+	if record.Resource != "nsc_user" || record.Action != "create" || records[1].Action != "update" {
+		t.Errorf("unexpected resource or actions: %+v", records)
+	}
+	if record.ClaimType != string(jwt.UserClaim) || record.Name != "app" {
+		t.Errorf("unexpected claim type or name: %+v", record)
+	}
+	if record.Subject != userPubKey || record.Issuer != accountPubKey {
+		t.Errorf("unexpected subject or issuer: %+v", record)
+	}
+	if record.ExpiresAt != "2030-01-01T00:00:00Z" {
+		t.Errorf("expected expiry 2030-01-01T00:00:00Z, got %q", record.ExpiresAt)
+	}
+	if record.JWTID == "" || !strings.HasPrefix(record.Fingerprint, "sha256:") {
+		t.Errorf("expected jwt_id and fingerprint, got %+v", record)
+	}
+}
+
+func TestAuditLog_recordNil(t *testing.T) {
+	var log *auditLog
+	if diags := log.record("nsc_user", "create", "not-a-jwt"); len(diags) != 0 {
+		t.Errorf("expected no diagnostics without an audit log, got %v", diags)
+	}
+}
+
+func TestAuditLog_recordUnwritable(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	operatorJWT, err := jwt.NewOperatorClaims(operatorPubKey).Encode(operatorKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := newAuditLog(filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+	diags := log.record("nsc_operator", "create", operatorJWT)
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("expected a single warning, got %v", diags)
+	}
+}
@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = ByteSizeType{}
+	_ basetypes.StringValuableWithSemanticEquals = ByteSize{}
+	_ xattr.ValidateableAttribute                = ByteSize{}
+)
+
+// byteSizeUnits maps the accepted unit suffixes, in lower case, to their
+// size in bytes. KB, MB, ... are decimal and KiB, MiB, ... binary units.
+var byteSizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+var byteSizePattern = regexp.MustCompile(`^(-?[0-9]+)\s*([A-Za-z]*)$`)
+
+// parseByteSize parses a number of bytes, optionally followed by a unit such
+// as `MB` or `GiB`, or a limit sentinel such as `unlimited`. Negative values
+// are only accepted without a unit, as the -1 that limits use for unlimited.
+func parseByteSize(s string) (int64, error) {
+	if value, ok := limitSentinels[s]; ok {
+		return value, nil
+	}
+
+	match := byteSizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, fmt.Errorf("expected a number of bytes, a size such as 512MiB, or one of unlimited or disabled, got %q", s)
+	}
+
+	unit, ok := byteSizeUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q in %q; use B, KB, MB, GB, TB, KiB, MiB, GiB or TiB", match[2], s)
+	}
+
+	value, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	if value < 0 && match[2] != "" {
+		return 0, fmt.Errorf("negative size %q cannot have a unit", s)
+	}
+	if value > math.MaxInt64/unit {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+
+	return value * unit, nil
+}
+
+// ByteSizeType is a string type holding a number of bytes, either plain or
+// with a unit such as `512MiB`. Numbers in configuration are converted to
+// strings by Terraform, so existing configurations keep working.
+type ByteSizeType struct {
+	basetypes.StringType
+}
+
+func (t ByteSizeType) String() string {
+	return "provider.ByteSizeType"
+}
+
+func (t ByteSizeType) ValueType(ctx context.Context) attr.Value {
+	return ByteSize{}
+}
+
+func (t ByteSizeType) Equal(o attr.Type) bool {
+	other, ok := o.(ByteSizeType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t ByteSizeType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return ByteSize{StringValue: in}, nil
+}
+
+func (t ByteSizeType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	return ByteSize{StringValue: stringValue}, nil
+}
+
+// ByteSize is the value of a ByteSizeType attribute.
+type ByteSize struct {
+	basetypes.StringValue
+}
+
+// NewByteSizeNull returns a null ByteSize.
+func NewByteSizeNull() ByteSize {
+	return ByteSize{StringValue: basetypes.NewStringNull()}
+}
+
+// NewByteSizeValue returns a known ByteSize. The value is not parsed.
+func NewByteSizeValue(value string) ByteSize {
+	return ByteSize{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v ByteSize) Type(_ context.Context) attr.Type {
+	return ByteSizeType{}
+}
+
+func (v ByteSize) Equal(o attr.Value) bool {
+	other, ok := o.(ByteSize)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// ValueInt64 returns the number of bytes. Null, unknown and invalid values
+// return 0; invalid values are rejected during validation.
+func (v ByteSize) ValueInt64() int64 {
+	if v.IsNull() || v.IsUnknown() {
+		return 0
+	}
+	size, _ := parseByteSize(v.ValueString())
+	return size
+}
+
+// Int64 returns the number of bytes as an Int64 value, keeping null and
+// unknown values.
+func (v ByteSize) Int64() types.Int64 {
+	switch {
+	case v.IsNull():
+		return types.Int64Null()
+	case v.IsUnknown():
+		return types.Int64Unknown()
+	}
+	return types.Int64Value(v.ValueInt64())
+}
+
+// ValidateAttribute rejects values that are not a valid size.
+func (v ByteSize) ValidateAttribute(ctx context.Context, req xattr.ValidateAttributeRequest, resp *xattr.ValidateAttributeResponse) {
+	if v.IsNull() || v.IsUnknown() {
+		return
+	}
+
+	if _, err := parseByteSize(v.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid byte size", err.Error())
+	}
+}
+
+// StringSemanticEquals treats sizes of the same number of bytes as equal, so
+// changing `1024` to `1KiB` does not plan an update.
+func (v ByteSize) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(ByteSize)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T. Please report this issue to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	priorSize, err := parseByteSize(v.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	newSize, err := parseByteSize(newValue.ValueString())
+	if err != nil {
+		return false, diags
+	}
+
+	return priorSize == newSize, diags
+}
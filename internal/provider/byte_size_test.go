@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]struct {
+		input       string
+		expected    int64
+		expectError bool
+	}{
+		"plain":            {input: "1048576", expected: 1048576},
+		"unlimited":        {input: "-1", expected: -1},
+		"bytes":            {input: "512B", expected: 512},
+		"decimal":          {input: "512MB", expected: 512 * 1000 * 1000},
+		"binary":           {input: "1GiB", expected: 1 << 30},
+		"lower case":       {input: "64kib", expected: 64 << 10},
+		"space":            {input: "10 TiB", expected: 10 << 40},
+		"negative unit":    {input: "-1GiB", expectError: true},
+		"unknown unit":     {input: "1GiBs", expectError: true},
+		"fraction":         {input: "1.5GiB", expectError: true},
+		"empty":            {input: "", expectError: true},
+		"overflow":         {input: "9999999999TiB", expectError: true},
+		"int64 overflow":   {input: "99999999999999999999", expectError: true},
+		"unit only":        {input: "GiB", expectError: true},
+		"surrounding trim": {input: " 2KB ", expected: 2000},
+		"unlimited word":   {input: "unlimited", expected: -1},
+		"disabled word":    {input: "disabled", expected: 0},
+		"sentinel case":    {input: "Unlimited", expectError: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			size, err := parseByteSize(tc.input)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %d", size)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if size != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, size)
+			}
+		})
+	}
+}
+
+func TestByteSize_semanticEquals(t *testing.T) {
+	ctx := context.Background()
+
+	equal, diags := NewByteSizeValue("1024").StringSemanticEquals(ctx, NewByteSizeValue("1KiB"))
+	if diags.HasError() || !equal {
+		t.Errorf("expected 1024 and 1KiB to be equal")
+	}
+
+	equal, diags = NewByteSizeValue("1000").StringSemanticEquals(ctx, NewByteSizeValue("1KiB"))
+	if diags.HasError() || equal {
+		t.Errorf("expected 1000 and 1KiB to differ")
+	}
+
+	if NewByteSizeNull().ValueInt64() != 0 || !NewByteSizeNull().Int64().IsNull() {
+		t.Errorf("expected null size to stay null")
+	}
+}
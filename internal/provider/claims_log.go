@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// redacted replaces secrets in logged claims.
+const redacted = "<redacted>"
+
+// logEncodedClaims logs a summary of an issued JWT at DEBUG and its claims at
+// TRACE, so the claims can be compared with the configuration from TF_LOG
+// output. The token itself is never logged, as bearer JWTs are credentials,
+// and seeds and JWTs within the claims, such as activation tokens, are
+// redacted.
+func logEncodedClaims(ctx context.Context, token string) {
+	claims, err := jwt.Decode(token)
+	if err != nil {
+		return
+	}
+	claimsData := claims.Claims()
+
+	tflog.Debug(ctx, "encoded JWT", map[string]any{
+		"claim_type": string(claims.ClaimType()),
+		"subject":    claimsData.Subject,
+		"issuer":     claimsData.Issuer,
+		"jwt_id":     claimsData.ID,
+	})
+
+	payload, err := jwtPayload(token)
+	if err != nil {
+		return
+	}
+	var value any
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return
+	}
+	// Keep subjects such as foo.> readable
+	var logged strings.Builder
+	encoder := json.NewEncoder(&logged)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redactClaims(value)); err != nil {
+		return
+	}
+
+	tflog.Trace(ctx, "encoded JWT claims", map[string]any{
+		"claims": strings.TrimSuffix(logged.String(), "\n"),
+	})
+}
+
+// redactClaims replaces seeds and JWTs anywhere in decoded JSON claims.
+func redactClaims(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = redactClaims(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactClaims(item)
+		}
+	case string:
+		if isSecretString(v) {
+			return redacted
+		}
+	}
+	return value
+}
+
+// isSecretString reports whether s is an nkey seed or a JWT.
+func isSecretString(s string) bool {
+	if strings.HasPrefix(s, "S") {
+		if _, err := nkeys.FromSeed([]byte(s)); err == nil {
+			return true
+		}
+	}
+	if strings.Count(s, ".") == 2 {
+		if _, err := jwt.Decode(s); err == nil {
+			return true
+		}
+	}
+	return false
+}
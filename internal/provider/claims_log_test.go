@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestLogEncodedClaims(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	exporterKP, _ := nkeys.CreateAccount()
+	exporterPubKey, _ := exporterKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	userSeed, _ := userKP.Seed()
+
+	activation := jwt.NewActivationClaims(accountPubKey)
+	activation.ImportSubject = "shared.>"
+	activation.ImportType = jwt.Stream
+	activationToken, err := activation.Encode(exporterKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.NewAccountClaims(accountPubKey)
+	claims.Name = "app"
+	claims.Imports.Add(&jwt.Import{Subject: "shared.>", Account: exporterPubKey, Type: jwt.Stream, Token: activationToken})
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	token, err := encodeClaims(ctx, claims, operatorKP, nil, types.StringValue(`{"nats": {"deploy": {"seed": "`+string(userSeed)+`"}}}`), types.StringNull(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logged string
+	for _, entry := range entries {
+		if entry["@message"] == "encoded JWT claims" {
+			logged, _ = entry["claims"].(string)
+		}
+	}
+	if logged == "" {
+		t.Fatalf("expected the claims to be logged, got %v", entries)
+	}
+	if !strings.Contains(logged, `"name":"app"`) || !strings.Contains(logged, exporterPubKey) {
+		t.Errorf("expected the claims in the log, got %s", logged)
+	}
+	if strings.Count(logged, redacted) != 2 {
+		t.Errorf("expected the activation token and the seed to be redacted, got %s", logged)
+	}
+
+	all := output.String()
+	for name, secret := range map[string]string{"token": token, "activation token": activationToken, "seed": string(userSeed)} {
+		if strings.Contains(all, secret) {
+			t.Errorf("the %s must not be logged", name)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+// claimsPatchReserved lists the claims a claims patch cannot change. The
+// subject is the key of the resource and the issuer is the key signing it.
+var claimsPatchReserved = []string{"iss", "sub"}
+
+// claimsPatchJSONAttribute returns the claims_patch_json attribute of the
+// resource issuing claims of the given kind (user, account or operator).
+func claimsPatchJSONAttribute(kind string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: fmt.Sprintf("JSON merge patch ([RFC 7386](https://www.rfc-editor.org/rfc/rfc7386)) applied to the %s claims immediately before signing, "+
+			"after `custom_claims_json`. Use it for claim fields the provider does not support yet. "+
+			"Objects are merged recursively, `null` removes a field and any other value replaces it. "+
+			"Unlike `custom_claims_json`, the patch may change the standard fields, except for `sub` and `iss`; "+
+			"the `jti` is recomputed unless the patch sets it.",
+			kind),
+		Validators: []validator.String{
+			claimsPatchValidator{},
+		},
+	}
+}
+
+var _ validator.String = claimsPatchValidator{}
+
+// claimsPatchValidator checks that claims_patch_json is a JSON object that
+// leaves the subject and the issuer alone.
+type claimsPatchValidator struct{}
+
+func (v claimsPatchValidator) Description(_ context.Context) string {
+	return "must be a JSON object that does not change sub or iss"
+}
+
+func (v claimsPatchValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v claimsPatchValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := parseClaimsPatch(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid claims patch", err.Error())
+	}
+}
+
+// parseClaimsPatch decodes claims patch JSON. A patch that is not an object
+// would replace the claims as a whole, so only objects are accepted.
+func parseClaimsPatch(value string) (map[string]any, error) {
+	var patch map[string]any
+	if err := decodeJSONNumbers([]byte(value), &patch); err != nil {
+		return nil, fmt.Errorf("claims patch must be a JSON object: %w", err)
+	}
+	if patch == nil {
+		return nil, fmt.Errorf("claims patch must be a JSON object, got null")
+	}
+
+	for _, key := range claimsPatchReserved {
+		if _, ok := patch[key]; ok {
+			return nil, fmt.Errorf("claims patch cannot change %q", key)
+		}
+	}
+
+	return patch, nil
+}
+
+// applyClaimsPatch applies claims patch JSON to encoded claims JSON and
+// recomputes the jti for the patched standard fields, unless the patch sets
+// it.
+func applyClaimsPatch(claimsJSON []byte, patch types.String) ([]byte, error) {
+	if patch.IsNull() || patch.IsUnknown() {
+		return claimsJSON, nil
+	}
+
+	patchObject, err := parseClaimsPatch(patch.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeJSONNumbers(claimsJSON, &claims); err != nil {
+		return nil, fmt.Errorf("failed to decode claims: %w", err)
+	}
+
+	patched := mergePatch(claims, patchObject).(map[string]any)
+	if _, ok := patchObject["jti"]; !ok {
+		jti, err := claimsID(patched)
+		if err != nil {
+			return nil, err
+		}
+		patched["jti"] = jti
+	}
+
+	return json.Marshal(patched)
+}
+
+// claimsID returns the jti of claims, which hashes the standard fields the
+// way jwt.ClaimsData does.
+func claimsID(claims map[string]any) (string, error) {
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	var data jwt.ClaimsData
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		return "", fmt.Errorf("claims patch produces invalid standard fields: %w", err)
+	}
+	data.ID = ""
+	standard, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	hash := sha512.Sum512_256(standard)
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(hash[:]), nil
+}
+
+// decodeJSONNumbers decodes JSON like json.Unmarshal, but keeps numbers as
+// written; limits and timestamps do not all fit a float64.
+func decodeJSONNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// mergePatch applies a JSON merge patch to target as described in RFC 7386.
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// TestMergePatch runs the examples of RFC 7386, appendix A.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		target, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		var target, patch any
+		if err := json.Unmarshal([]byte(tt.target), &target); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(mergePatch(target, patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%s patched with %s: expected %s, got %s", tt.target, tt.patch, tt.expected, got)
+		}
+	}
+}
+
+func TestClaimsPatchValidator(t *testing.T) {
+	tests := map[string]bool{
+		`{"org": "acme"}`:                  false,
+		`{"exp": 1900000000, "iat": null}`: false,
+		`{"nats": {"tags": null}}`:         false,
+		`[1, 2]`:                           true,
+		`null`:                             true,
+		`"patch"`:                          true,
+		`{"org": `:                         true,
+		`{"sub": "UABC"}`:                  true,
+		`{"iss": null}`:                    true,
+	}
+
+	for value, expectError := range tests {
+		req := validator.StringRequest{
+			Path:        path.Root("claims_patch_json"),
+			ConfigValue: types.StringValue(value),
+		}
+		resp := &validator.StringResponse{}
+		claimsPatchValidator{}.ValidateString(context.Background(), req, resp)
+
+		if resp.Diagnostics.HasError() != expectError {
+			t.Errorf("%s: expected error %v, got %v", value, expectError, resp.Diagnostics)
+		}
+	}
+}
+
+func TestEncodeClaims_claimsPatch(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+
+	newClaims := func() *jwt.UserClaims {
+		claims := jwt.NewUserClaims(userPubKey)
+		claims.Name = "test"
+		claims.Tags = jwt.TagList{"team:a"}
+		claims.Limits.Subs = 10
+		claims.Limits.Data = 1 << 62
+		return claims
+	}
+
+	// The patch applies after the custom claims and may change standard fields
+	userClaims := newClaims()
+	token, err := encodeClaims(context.Background(), userClaims, accountKP, nil,
+		types.StringValue(`{"nats": {"payload": 1024}}`),
+		types.StringValue(`{"exp": 1900000000, "nats": {"payload": 2048, "tags": null, "pub": {"allow": ["a.>"]}}}`),
+		0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := jwt.DecodeUserClaims(token)
+	if err != nil {
+		t.Fatalf("failed to decode JWT: %v", err)
+	}
+	if claims.Expires != 1900000000 {
+		t.Errorf("expected patched expiry, got %d", claims.Expires)
+	}
+	if claims.Limits.Payload != 2048 || claims.Limits.Subs != 10 || claims.Limits.Data != 1<<62 {
+		t.Errorf("expected patched limits, got payload %d, subs %d, data %d", claims.Limits.Payload, claims.Limits.Subs, claims.Limits.Data)
+	}
+	if len(claims.Tags) != 0 {
+		t.Errorf("expected tags to be removed, got %v", claims.Tags)
+	}
+	if !claims.Pub.Allow.Contains("a.>") {
+		t.Errorf("expected patched permissions, got %v", claims.Pub.Allow)
+	}
+	if claims.Subject != userPubKey || claims.Issuer != accountPubKey {
+		t.Errorf("expected subject and issuer to be kept, got %s and %s", claims.Subject, claims.Issuer)
+	}
+
+	// The jti covers the patched standard fields
+	expected := newClaims()
+	expected.Expires = 1900000000
+	expected.IssuedAt = claims.IssuedAt
+	expected.Issuer = accountPubKey
+	expectedJTI, err := claimsID(map[string]any{
+		"exp": expected.Expires, "iat": expected.IssuedAt, "iss": expected.Issuer, "name": expected.Name, "sub": expected.Subject,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.ID == userClaims.ID || claims.ID != expectedJTI {
+		t.Errorf("expected recomputed jti %s, got %s", expectedJTI, claims.ID)
+	}
+
+	// A jti set by the patch is kept
+	token, err = encodeClaims(context.Background(), newClaims(), accountKP, nil, types.StringNull(), types.StringValue(`{"jti": "CUSTOM"}`), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims, err = jwt.DecodeUserClaims(token); err != nil || claims.ID != "CUSTOM" {
+		t.Errorf("expected jti from the patch, got %v, %v", claims, err)
+	}
+
+	for patch, expectedError := range map[string]string{
+		`{"sub": "UABC"}`:                 `cannot change "sub"`,
+		`{"nats": {"type": "account"}}`:   "claim type",
+		`{"exp": "tomorrow"}`:             "invalid standard fields",
+		`{"nats": {"subs": "unlimited"}}`: "invalid JWT",
+	} {
+		_, err := encodeClaims(context.Background(), newClaims(), accountKP, nil, types.StringNull(), types.StringValue(patch), 0)
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("%s: expected error containing %q, got %v", patch, expectedError, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/nats-io/jwt/v2"
+)
+
+// validateAccountClaims runs the jwt library's validation of account claims,
+// which encoding does not, and reports blocking issues at the attribute they
+// stem from, e.g. export[2].subject. Exports and imports must still be in
+// configuration order, i.e. the claims must not have been encoded yet.
+// Issues that cannot be traced to a block are reported without a path.
+func validateAccountClaims(claims *jwt.AccountClaims) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	vr := jwt.CreateValidationResults()
+	claims.Validate(vr)
+	if !vr.IsBlocking(false) {
+		return diags
+	}
+
+	// Trace issues to blocks by validating them one by one, most specific
+	// attribute first.
+	paths := issuePaths{}
+	for i, export := range claims.Exports {
+		exportPath := path.Root("export").AtListIndex(i)
+		paths.add(exportPath.AtName("subject"), export.Subject.Validate)
+		paths.add(exportPath, export.Validate)
+	}
+	for i, imp := range claims.Imports {
+		importPath := path.Root("import").AtListIndex(i)
+		paths.add(importPath.AtName("subject"), imp.Subject.Validate)
+		if imp.LocalSubject != "" {
+			paths.add(importPath.AtName("local_subject"), func(vr *jwt.ValidationResults) {
+				imp.LocalSubject.Validate(imp.Subject, vr)
+			})
+		}
+		paths.add(importPath, func(vr *jwt.ValidationResults) {
+			imp.Validate(claims.Subject, vr)
+		})
+	}
+	paths.add(path.Root("signing_keys"), claims.SigningKeys.Validate)
+	paths.add(path.Root("auth_callout"), claims.Authorization.Validate)
+
+	for _, issue := range vr.Issues {
+		if !issue.Blocking {
+			continue
+		}
+		if p, ok := paths.take(issue.Description); ok {
+			diags.AddAttributeError(p, "Invalid account claims", issue.Description)
+			continue
+		}
+		diags.AddError("Invalid account claims", issue.Description)
+	}
+
+	return diags
+}
+
+// issuePaths maps the descriptions of validation issues to the paths of the
+// attributes that produced them. Blocks producing the same issue are kept in
+// order, so each occurrence is reported at its own block.
+type issuePaths map[string][]path.Path
+
+// add records the blocking issues found by validate at p. Issues already
+// recorded by a more specific attribute of the same block are skipped.
+func (m issuePaths) add(p path.Path, validate func(*jwt.ValidationResults)) {
+	vr := jwt.CreateValidationResults()
+	validate(vr)
+	for _, issue := range vr.Issues {
+		if !issue.Blocking {
+			continue
+		}
+		if recorded := m[issue.Description]; len(recorded) > 0 && recorded[len(recorded)-1].ParentPath().Equal(p) {
+			continue
+		}
+		m[issue.Description] = append(m[issue.Description], p)
+	}
+}
+
+// take returns the next path recorded for an issue.
+func (m issuePaths) take(description string) (path.Path, bool) {
+	recorded := m[description]
+	if len(recorded) == 0 {
+		return path.Empty(), false
+	}
+	m[description] = recorded[1:]
+	return recorded[0], true
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestValidateAccountClaims(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	otherKP, _ := nkeys.CreateAccount()
+	otherPubKey, _ := otherKP.PublicKey()
+
+	newClaims := func() *jwt.AccountClaims {
+		claims := jwt.NewAccountClaims(accountPubKey)
+		claims.Exports.Add(
+			&jwt.Export{Subject: "api.orders", Type: jwt.Service},
+			&jwt.Export{Subject: "events.>", Type: jwt.Stream},
+		)
+		claims.Imports.Add(&jwt.Import{Subject: "shared.>", Account: otherPubKey, Type: jwt.Stream, LocalSubject: "remote.>"})
+		return claims
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		if diags := validateAccountClaims(newClaims()); diags.HasError() {
+			t.Errorf("unexpected diagnostics: %v", diags)
+		}
+	})
+
+	t.Run("export subject", func(t *testing.T) {
+		claims := newClaims()
+		claims.Exports[1].Subject = "events..>"
+
+		expectClaimsErrorAt(t, validateAccountClaims(claims), path.Root("export").AtListIndex(1).AtName("subject"), "consecutive")
+	})
+
+	t.Run("export", func(t *testing.T) {
+		claims := newClaims()
+		claims.Exports[1].ResponseType = jwt.ResponseTypeStream
+
+		expectClaimsErrorAt(t, validateAccountClaims(claims), path.Root("export").AtListIndex(1), "invalid response type for stream")
+	})
+
+	t.Run("same issue in two exports", func(t *testing.T) {
+		claims := newClaims()
+		claims.Exports[0].Subject = "api..orders"
+		claims.Exports[1].Subject = "api..orders"
+
+		diags := validateAccountClaims(claims)
+		if diags.ErrorsCount() != 2 {
+			t.Fatalf("expected 2 errors, got %v", diags)
+		}
+		for i, d := range diags.Errors() {
+			withPath, ok := d.(diag.DiagnosticWithPath)
+			if !ok || !withPath.Path().Equal(path.Root("export").AtListIndex(i).AtName("subject")) {
+				t.Errorf("expected error %d at export[%d].subject, got %v", i, i, d)
+			}
+		}
+	})
+
+	t.Run("import local subject", func(t *testing.T) {
+		claims := newClaims()
+		claims.Imports[0].LocalSubject = "remote.orders"
+
+		expectClaimsErrorAt(t, validateAccountClaims(claims), path.Root("import").AtListIndex(0).AtName("local_subject"), "need to end or not end in >")
+	})
+}
+
+// expectClaimsErrorAt checks that diags holds a single error at p whose
+// detail contains the given text.
+func expectClaimsErrorAt(t *testing.T, diags diag.Diagnostics, p path.Path, detail string) {
+	t.Helper()
+
+	if diags.ErrorsCount() != 1 {
+		t.Fatalf("expected a single error, got %v", diags)
+	}
+	d := diags.Errors()[0]
+	withPath, ok := d.(diag.DiagnosticWithPath)
+	if !ok || !withPath.Path().Equal(p) {
+		t.Errorf("expected error at %s, got %v", p, d)
+	}
+	if !strings.Contains(d.Detail(), detail) {
+		t.Errorf("expected error containing %q, got %q", detail, d.Detail())
+	}
+}
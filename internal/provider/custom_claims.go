@@ -0,0 +1,228 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// customClaimsReserved lists the standard JWT fields managed by the provider.
+// They feed into the jti hash and cannot be overridden by custom claims.
+var customClaimsReserved = []string{"aud", "exp", "iat", "iss", "jti", "name", "nbf", "sub"}
+
+// customClaimsNATSReserved lists the fields of the "nats" object that
+// identify the claim type and cannot be overridden by custom claims.
+var customClaimsNATSReserved = []string{"type", "version"}
+
+// customClaimsJSONAttribute returns the custom_claims_json attribute of the
+// resource issuing claims of the given kind (user, account or operator).
+func customClaimsJSONAttribute(kind string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		MarkdownDescription: fmt.Sprintf("JSON object deep-merged into the %s claims before signing. "+
+			"Objects are merged recursively, other values replace the generated ones and `null` removes a field. "+
+			"Fields of the NATS claims go under the `nats` key; any other top-level key is added to the JWT as is. "+
+			"The standard fields (`%s`) and `nats.type`/`nats.version` cannot be set.",
+			kind, strings.Join(customClaimsReserved, "`, `")),
+		Validators: []validator.String{
+			customClaimsValidator{},
+		},
+	}
+}
+
+var _ validator.String = customClaimsValidator{}
+
+// customClaimsValidator checks that custom_claims_json is a JSON object that
+// does not touch any of the reserved fields.
+type customClaimsValidator struct{}
+
+func (v customClaimsValidator) Description(_ context.Context) string {
+	return "must be a JSON object without standard JWT fields"
+}
+
+func (v customClaimsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v customClaimsValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := parseCustomClaims(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid custom claims", err.Error())
+	}
+}
+
+// parseCustomClaims decodes custom claims JSON and checks it for reserved
+// fields.
+func parseCustomClaims(value string) (map[string]any, error) {
+	var custom map[string]any
+	if err := decodeJSONNumbers([]byte(value), &custom); err != nil {
+		return nil, fmt.Errorf("custom claims must be a JSON object: %w", err)
+	}
+	if custom == nil {
+		return nil, fmt.Errorf("custom claims must be a JSON object, got null")
+	}
+
+	for _, key := range customClaimsReserved {
+		if _, ok := custom[key]; ok {
+			return nil, fmt.Errorf("custom claims cannot set the standard field %q", key)
+		}
+	}
+
+	if nats, ok := custom["nats"]; ok {
+		natsObject, ok := nats.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("custom claims field \"nats\" must be a JSON object")
+		}
+		for _, key := range customClaimsNATSReserved {
+			if _, ok := natsObject[key]; ok {
+				return nil, fmt.Errorf("custom claims cannot set \"nats.%s\"", key)
+			}
+		}
+	}
+
+	return custom, nil
+}
+
+// mergeCustomClaims deep-merges custom claims JSON into encoded claims JSON.
+// Objects are merged recursively, any other value replaces the existing one
+// and null removes it.
+func mergeCustomClaims(claimsJSON []byte, custom types.String) ([]byte, error) {
+	if custom.IsNull() || custom.IsUnknown() {
+		return claimsJSON, nil
+	}
+
+	customClaims, err := parseCustomClaims(custom.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeJSONNumbers(claimsJSON, &claims); err != nil {
+		return nil, fmt.Errorf("failed to decode claims: %w", err)
+	}
+
+	return json.Marshal(deepMerge(claims, customClaims))
+}
+
+func deepMerge(dst, src map[string]any) map[string]any {
+	for key, value := range src {
+		if value == nil {
+			delete(dst, key)
+			continue
+		}
+		srcObject, srcIsObject := value.(map[string]any)
+		dstObject, dstIsObject := dst[key].(map[string]any)
+		if srcIsObject && dstIsObject {
+			dst[key] = deepMerge(dstObject, srcObject)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// encodeClaims encodes and signs claims like Claims.EncodeWithSigner, with
+// iat pinned to issuedAt when it is not zero, custom claims JSON merged into
+// the payload and then the claims patch applied to it when set. The resulting
+// payload is signed again with the same key; the jti hash only covers the
+// standard fields, which custom claims cannot change, and is recomputed for a
+// pinned iat and by the claims patch. The resulting claims are logged with
+// logEncodedClaims.
+func encodeClaims(ctx context.Context, claims jwt.Claims, kp nkeys.KeyPair, signFn jwt.SignFn, custom, patch types.String, issuedAt int64) (string, error) {
+	token, err := claims.EncodeWithSigner(kp, signFn)
+	if err != nil {
+		return "", err
+	}
+	if custom.IsNull() && patch.IsNull() && issuedAt == 0 {
+		logEncodedClaims(ctx, token)
+		return token, nil
+	}
+
+	chunks := strings.Split(token, ".")
+	if len(chunks) != 3 {
+		return "", fmt.Errorf("unexpected JWT format")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(chunks[1])
+	if err != nil {
+		return "", fmt.Errorf("failed to decode JWT payload: %w", err)
+	}
+	if issuedAt != 0 {
+		payload, err = pinnedClaimsPayload(claims, issuedAt)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode JWT payload: %w", err)
+		}
+	}
+
+	if !custom.IsNull() {
+		payload, err = mergeCustomClaims(payload, custom)
+		if err != nil {
+			return "", err
+		}
+	}
+	if !patch.IsNull() {
+		payload, err = applyClaimsPatch(payload, patch)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	toSign := chunks[0] + "." + base64.RawURLEncoding.EncodeToString(payload)
+	var sig []byte
+	if signFn != nil {
+		issuer, err := kp.PublicKey()
+		if err != nil {
+			return "", err
+		}
+		sig, err = signFn(issuer, []byte(toSign))
+		if err != nil {
+			return "", err
+		}
+	} else {
+		sig, err = kp.Sign([]byte(toSign))
+		if err != nil {
+			return "", err
+		}
+	}
+	token = toSign + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	// Make sure the payload is still decodable as the same claim type
+	decoded, err := jwt.Decode(token)
+	if err != nil {
+		return "", fmt.Errorf("custom claims or claims patch produce an invalid JWT: %w", err)
+	}
+	if decoded.ClaimType() != claims.ClaimType() {
+		return "", fmt.Errorf("custom claims or claims patch change the claim type from %s to %s", claims.ClaimType(), decoded.ClaimType())
+	}
+
+	logEncodedClaims(ctx, token)
+	return token, nil
+}
+
+// pinnedClaimsPayload returns the JSON payload of claims encoded with iat set
+// to issuedAt, with the jti hash computed the way jwt.ClaimsData does.
+func pinnedClaimsPayload(claims jwt.Claims, issuedAt int64) ([]byte, error) {
+	data := claims.Claims()
+	data.IssuedAt = issuedAt
+	data.ID = ""
+	standard, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha512.Sum512_256(standard)
+	data.ID = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(hash[:])
+	return json.Marshal(claims)
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestEncodeClaims(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+
+	newClaims := func() *jwt.UserClaims {
+		claims := jwt.NewUserClaims(userPubKey)
+		claims.Name = "test"
+		claims.Tags = jwt.TagList{"team:a"}
+		claims.Limits.Subs = 10
+		return claims
+	}
+
+	userClaims := newClaims()
+	token, err := encodeClaims(context.Background(), userClaims, accountKP, nil, types.StringValue(`{
+  "vendor": {"tier": "gold"},
+  "nats": {"payload": 1024, "tags": null}
+}`), types.StringNull(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := jwt.DecodeUserClaims(token)
+	if err != nil {
+		t.Fatalf("failed to decode JWT: %v", err)
+	}
+	if claims.Limits.Payload != 1024 {
+		t.Errorf("expected merged payload limit 1024, got %d", claims.Limits.Payload)
+	}
+	if claims.Limits.Subs != 10 {
+		t.Errorf("expected subscription limit to be kept, got %d", claims.Limits.Subs)
+	}
+	if len(claims.Tags) != 0 {
+		t.Errorf("expected tags to be removed, got %v", claims.Tags)
+	}
+	if claims.ID != userClaims.ID {
+		t.Errorf("expected jti %q, got %q", userClaims.ID, claims.ID)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+	if err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	var raw struct {
+		Vendor struct {
+			Tier string `json:"tier"`
+		} `json:"vendor"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if raw.Vendor.Tier != "gold" {
+		t.Errorf("expected top-level custom claim, got %s", payload)
+	}
+
+	if _, err := encodeClaims(context.Background(), newClaims(), accountKP, nil, types.StringValue(`{"nats": {"subs": "none"}}`), types.StringNull(), 0); err == nil {
+		t.Error("expected error for custom claims that break the JWT")
+	}
+}
+
+func TestMergeCustomClaims(t *testing.T) {
+	merged, err := mergeCustomClaims([]byte(`{"sub":"U1","nats":{"pub":{"allow":["a"]},"type":"user"}}`), types.StringValue(`{"org":"acme","nats":{"pub":{"deny":["b"]}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"nats":{"pub":{"allow":["a"],"deny":["b"]},"type":"user"},"org":"acme","sub":"U1"}`
+	if string(merged) != expected {
+		t.Errorf("expected %s, got %s", expected, merged)
+	}
+}
+
+func TestCustomClaimsValidator(t *testing.T) {
+	tests := map[string]bool{
+		`{"org": "acme"}`:               false,
+		`{"nats": {"subs": 10}}`:        false,
+		`[1, 2]`:                        true,
+		`null`:                          true,
+		`{"org": `:                      true,
+		`{"sub": "UABC"}`:               true,
+		`{"iat": 0}`:                    true,
+		`{"nats": "user"}`:              true,
+		`{"nats": {"type": "account"}}`: true,
+	}
+
+	for value, expectError := range tests {
+		req := validator.StringRequest{
+			Path:        path.Root("custom_claims_json"),
+			ConfigValue: types.StringValue(value),
+		}
+		resp := &validator.StringResponse{}
+		customClaimsValidator{}.ValidateString(context.Background(), req, resp)
+
+		if resp.Diagnostics.HasError() != expectError {
+			t.Errorf("%s: expected error %v, got %v", value, expectError, resp.Diagnostics)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/nkeys"
+)
+
+var _ datasource.DataSource = &AccountClaimsDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &AccountClaimsDataSource{}
+
+func NewAccountClaimsDataSource() datasource.DataSource {
+	return &AccountClaimsDataSource{}
+}
+
+type AccountClaimsDataSource struct{}
+
+type AccountClaimsDataSourceModel struct {
+	ID     types.String `tfsdk:"id"`
+	Issuer types.String `tfsdk:"issuer"`
+
+	AccountClaimsModel
+
+	ClaimsJSON types.String `tfsdk:"claims_json"`
+}
+
+func (d *AccountClaimsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_claims"
+}
+
+func (d *AccountClaimsDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	var resourceSchema resource.SchemaResponse
+	(&AccountResource{}).Schema(ctx, resource.SchemaRequest{}, &resourceSchema)
+
+	attributes, blocks := claimsDataSourceSchema(resourceSchema.Schema, AccountClaimsModel{})
+
+	attributes["id"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "Account public key (same as subject)",
+	}
+	attributes["issuer"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Operator public key to record as the issuer. Left empty when not set.",
+		Validators: []validator.String{
+			stringvalidator.RegexMatches(
+				regexp.MustCompile(`^O[A-Z2-7]{55}$`),
+				"must be a valid operator public key starting with 'O'",
+			),
+		},
+	}
+	attributes["claims_json"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "Unsigned account claims in JSON format, as they would be encoded into the account JWT",
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Builds NATS account claims from the same inputs as the `nsc_account` resource without signing them. Useful for reviewing what would be issued without access to the operator seed.",
+		Attributes:          attributes,
+		Blocks:              blocks,
+	}
+}
+
+func (d *AccountClaimsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data AccountClaimsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.validate()...)
+	resp.Diagnostics.Append(data.validateLimits()...)
+	resp.Diagnostics.Append(data.validateExports(ctx)...)
+	resp.Diagnostics.Append(data.validateImports(ctx)...)
+	resp.Diagnostics.Append(data.validateImportTokens(ctx)...)
+	if !data.Issuer.IsNull() && !data.Issuer.IsUnknown() {
+		resp.Diagnostics.Append(data.validateIssuer(data.Issuer.ValueString())...)
+	}
+}
+
+func (d *AccountClaimsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AccountClaimsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accountPubKey := data.Subject.ValueString()
+	if !nkeys.IsValidPublicAccountKey(accountPubKey) {
+		resp.Diagnostics.AddError(
+			"Invalid account public key",
+			fmt.Sprintf("Subject must be a valid account public key, got: %s", accountPubKey),
+		)
+		return
+	}
+
+	accountClaims, diags := buildAccountClaims(ctx, &data.AccountClaimsModel)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	accountClaims.Issuer = data.Issuer.ValueString()
+
+	claimsJSON, err := json.Marshal(accountClaims)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode account claims", err.Error())
+		return
+	}
+	claimsJSON, err = mergeCustomClaims(claimsJSON, data.CustomClaimsJSON)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to merge custom claims", err.Error())
+		return
+	}
+
+	data.ID = types.StringValue(accountPubKey)
+	data.ClaimsJSON = types.StringValue(string(claimsJSON))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccAccountClaimsDataSource_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountClaimsDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nsc_account_claims.test", "id", "nsc_nkey.account", "public_key"),
+					resource.TestMatchResourceAttr("data.nsc_account_claims.test", "claims_json", regexp.MustCompile(`"name":"TestAccount"`)),
+					resource.TestMatchResourceAttr("data.nsc_account_claims.test", "claims_json", regexp.MustCompile(`"allow":\["foo\.>"\]`)),
+					resource.TestMatchResourceAttr("data.nsc_account_claims.test", "claims_json", regexp.MustCompile(`"conn":100`)),
+					resource.TestCheckResourceAttrSet("data.nsc_account_claims.test", "expires_at"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAccountClaimsDataSource_invalidSubject(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "nsc_account_claims" "test" {
+  name    = "TestAccount"
+  subject = "UBADKEY"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid account public key`),
+			},
+		},
+	})
+}
+
+func testAccAccountClaimsDataSourceConfig() string {
+	return `
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+data "nsc_account_claims" "test" {
+  name            = "TestAccount"
+  subject         = nsc_nkey.account.public_key
+  allow_pub       = ["foo.>"]
+  max_connections = 100
+  expires_in      = "720h"
+}
+`
+}
+
+func TestAccountClaimsDataSource_read(t *testing.T) {
+	ctx := context.Background()
+
+	operatorKP, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorPubKey, _ := operatorKP.PublicKey()
+	accountKP, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountPubKey, _ := accountKP.PublicKey()
+
+	config, err := json.Marshal(map[string]any{
+		"name":      "TestAccount",
+		"subject":   accountPubKey,
+		"issuer":    operatorPubKey,
+		"allow_sub": []string{"foo.> workers"},
+		"export": []map[string]any{
+			{"subject": "svc.>", "type": "service"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimsJSON := testReadClaimsDataSource(ctx, t, "nsc_account_claims", config)
+
+	var claims jwt.AccountClaims
+	if err := json.Unmarshal([]byte(claimsJSON), &claims); err != nil {
+		t.Fatalf("failed to decode claims_json: %s", err)
+	}
+	if claims.Subject != accountPubKey {
+		t.Errorf("expected subject %s, got %s", accountPubKey, claims.Subject)
+	}
+	if claims.Issuer != operatorPubKey {
+		t.Errorf("expected issuer %s, got %s", operatorPubKey, claims.Issuer)
+	}
+	if claims.ID != "" || claims.IssuedAt != 0 {
+		t.Errorf("expected unsigned claims without jti/iat, got %q/%d", claims.ID, claims.IssuedAt)
+	}
+	if len(claims.DefaultPermissions.Sub.Allow) != 1 || claims.DefaultPermissions.Sub.Allow[0] != "foo.> workers" {
+		t.Errorf("unexpected subscribe permissions: %v", claims.DefaultPermissions.Sub.Allow)
+	}
+	if len(claims.Exports) != 1 || claims.Exports[0].Type != jwt.Service {
+		t.Errorf("unexpected exports: %v", claims.Exports)
+	}
+}
+
+// testReadClaimsDataSource reads a claims data source through the provider
+// server with a JSON encoded config and returns its claims_json attribute.
+func testReadClaimsDataSource(ctx context.Context, t *testing.T, typeName string, config []byte) string {
+	t.Helper()
+
+	server, err := testAccProtoV6ProviderFactories["nsc"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range schemaResp.Diagnostics {
+		t.Fatalf("unexpected schema diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+
+	readResp, err := server.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: typeName,
+		Config:   &tfprotov6.DynamicValue{JSON: config},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range readResp.Diagnostics {
+		t.Fatalf("unexpected read diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+
+	state, err := readResp.State.Unmarshal(schemaResp.DataSourceSchemas[typeName].ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var attrs map[string]tftypes.Value
+	if err := state.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+
+	var claimsJSON string
+	if err := attrs["claims_json"].As(&claimsJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	return claimsJSON
+}
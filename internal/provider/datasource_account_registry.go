@@ -0,0 +1,201 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ datasource.DataSource = &AccountRegistryDataSource{}
+
+func NewAccountRegistryDataSource() datasource.DataSource {
+	return &AccountRegistryDataSource{}
+}
+
+type AccountRegistryDataSource struct{}
+
+type AccountRegistryDataSourceModel struct {
+	ID                    types.String      `tfsdk:"id"`
+	Accounts              types.Map         `tfsdk:"accounts"`
+	PublicKeys            types.List        `tfsdk:"public_keys"`
+	ResolverPreload       types.Map         `tfsdk:"resolver_preload"`
+	ResolverPreloadConfig types.String      `tfsdk:"resolver_preload_config"`
+	ExpiresAt             types.Map         `tfsdk:"expires_at"`
+	NextExpiry            timetypes.RFC3339 `tfsdk:"next_expiry"`
+	NextExpiryAccount     types.String      `tfsdk:"next_expiry_account"`
+	ClaimsJSON            types.Map         `tfsdk:"claims_json"`
+}
+
+func (d *AccountRegistryDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_registry"
+}
+
+func (d *AccountRegistryDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Aggregates a set of account JWTs into the values server configuration modules need: the resolver preload map, the account public keys, an expiry summary and the decoded claims of each account.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hash of the account public keys",
+			},
+			"accounts": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				MarkdownDescription: "Map of account names to account JWTs, e.g. `nsc_account.app.jwt`. Names are only used to key the outputs. Each account may only be listed once.",
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+				},
+			},
+			"public_keys": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Account public keys, ordered by account name",
+			},
+			"resolver_preload": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Map of account public keys to account JWTs, as taken by `provider::nsc::resolver_preload`",
+			},
+			"resolver_preload_config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The rendered `resolver_preload` block of a nats-server configuration",
+			},
+			"expires_at": schema.MapAttribute{
+				ElementType:         timetypes.RFC3339Type{},
+				Computed:            true,
+				MarkdownDescription: "Map of account names to the expiry of their JWT. Accounts whose JWT does not expire are omitted.",
+			},
+			"next_expiry": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Computed:            true,
+				MarkdownDescription: "Earliest expiry of the account JWTs. Null when none of them expire.",
+			},
+			"next_expiry_account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the account whose JWT expires first. Null when none of them expire.",
+			},
+			"claims_json": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Map of account names to their decoded JWT claims as JSON",
+			},
+		},
+	}
+}
+
+func (d *AccountRegistryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AccountRegistryDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts := make(map[string]types.String, len(data.Accounts.Elements()))
+	resp.Diagnostics.Append(data.Accounts.ElementsAs(ctx, &accounts, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	publicKeys := make([]string, 0, len(names))
+	preload := make(map[string]string, len(names))
+	owners := make(map[string]string, len(names))
+	expiresAt := make(map[string]attr.Value)
+	claimsJSON := make(map[string]string, len(names))
+	data.NextExpiry = timetypes.NewRFC3339Null()
+	data.NextExpiryAccount = types.StringNull()
+	var nextExpiry int64
+
+	for _, name := range names {
+		attrPath := path.Root("accounts").AtMapKey(name)
+		if accounts[name].IsNull() {
+			resp.Diagnostics.AddAttributeError(attrPath, "Missing account JWT", "The account JWT is null. For accounts with jwt_output = \"sensitive_only\", pass jwt_sensitive instead.")
+			continue
+		}
+		token := accounts[name].ValueString()
+
+		claims, err := jwt.DecodeAccountClaims(token)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(attrPath, "Invalid account JWT", "Failed to decode account JWT: "+err.Error())
+			continue
+		}
+		if owner, ok := owners[claims.Subject]; ok {
+			resp.Diagnostics.AddAttributeError(
+				attrPath,
+				"Duplicate account",
+				fmt.Sprintf("Accounts %q and %q are both JWTs of account %s.", owner, name, claims.Subject),
+			)
+			continue
+		}
+		owners[claims.Subject] = name
+
+		payload, err := jwtPayload(token)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(attrPath, "Invalid account JWT", "Failed to decode account JWT: "+err.Error())
+			continue
+		}
+
+		publicKeys = append(publicKeys, claims.Subject)
+		preload[claims.Subject] = token
+		claimsJSON[name] = string(payload)
+
+		if claims.Expires == 0 {
+			continue
+		}
+		expiry := time.Unix(claims.Expires, 0).UTC()
+		expiresAt[name] = timetypes.NewRFC3339TimeValue(expiry)
+		if nextExpiry == 0 || claims.Expires < nextExpiry {
+			nextExpiry = claims.Expires
+			data.NextExpiry = timetypes.NewRFC3339TimeValue(expiry)
+			data.NextExpiryAccount = types.StringValue(name)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	preloadConfig, err := formatResolverPreload(preload)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to render resolver preload", err.Error())
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(publicKeys, ",")))))
+	data.ResolverPreloadConfig = types.StringValue(preloadConfig)
+
+	var diags diag.Diagnostics
+	data.PublicKeys, diags = types.ListValueFrom(ctx, types.StringType, publicKeys)
+	resp.Diagnostics.Append(diags...)
+	data.ResolverPreload, diags = types.MapValueFrom(ctx, types.StringType, preload)
+	resp.Diagnostics.Append(diags...)
+	data.ExpiresAt, diags = types.MapValue(timetypes.RFC3339Type{}, expiresAt)
+	resp.Diagnostics.Append(diags...)
+	data.ClaimsJSON, diags = types.MapValueFrom(ctx, types.StringType, claimsJSON)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccAccountRegistryDataSource_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountRegistryDataSourceConfig("nsc_account.app.jwt"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_account_registry.test", "public_keys.#", "2"),
+					resource.TestCheckResourceAttrPair("data.nsc_account_registry.test", "public_keys.0", "nsc_account.app", "public_key"),
+					resource.TestCheckResourceAttrPair("data.nsc_account_registry.test", "public_keys.1", "nsc_account.system", "public_key"),
+					resource.TestCheckResourceAttr("data.nsc_account_registry.test", "resolver_preload.%", "2"),
+					resource.TestMatchResourceAttr("data.nsc_account_registry.test", "resolver_preload_config", regexp.MustCompile(`^resolver_preload: \{\n  A[A-Z0-9]{55}: "eyJ[^"]+"\n  A[A-Z0-9]{55}: "eyJ[^"]+"\n\}\n$`)),
+					resource.TestCheckResourceAttr("data.nsc_account_registry.test", "expires_at.%", "1"),
+					resource.TestCheckResourceAttrPair("data.nsc_account_registry.test", "expires_at.app", "nsc_account.app", "expires_at"),
+					resource.TestCheckResourceAttrPair("data.nsc_account_registry.test", "next_expiry", "nsc_account.app", "expires_at"),
+					resource.TestCheckResourceAttr("data.nsc_account_registry.test", "next_expiry_account", "app"),
+					resource.TestCheckResourceAttrSet("data.nsc_account_registry.test", "claims_json.system"),
+				),
+			},
+			{
+				Config:      testAccAccountRegistryDataSourceConfig("nsc_account.system.jwt"),
+				ExpectError: regexp.MustCompile("Duplicate account"),
+			},
+		},
+	})
+}
+
+func testAccAccountRegistryDataSourceConfig(appJWT string) string {
+	return `
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "system" {
+  type = "account"
+}
+
+resource "nsc_nkey" "app" {
+  type = "account"
+}
+
+resource "nsc_account" "system" {
+  name        = "SYS"
+  subject     = nsc_nkey.system.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_account" "app" {
+  name        = "App"
+  subject     = nsc_nkey.app.public_key
+  issuer_seed = nsc_nkey.operator.seed
+  expires_in  = "720h"
+}
+
+data "nsc_account_registry" "test" {
+  accounts = {
+    system = nsc_account.system.jwt
+    app    = ` + appJWT + `
+  }
+}
+`
+}
+
+func TestAccountRegistryDataSource_read(t *testing.T) {
+	ctx := context.Background()
+
+	operatorKP, _ := nkeys.CreateOperator()
+	encode := func(expires int64) (string, string) {
+		kp, _ := nkeys.CreateAccount()
+		pub, _ := kp.PublicKey()
+		claims := jwt.NewAccountClaims(pub)
+		claims.Expires = expires
+		token, err := claims.Encode(operatorKP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pub, token
+	}
+	later := time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+	sooner := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	systemPub, systemJWT := encode(0)
+	appPub, appJWT := encode(later.Unix())
+	billingPub, billingJWT := encode(sooner.Unix())
+
+	config, err := json.Marshal(map[string]any{
+		"accounts": map[string]string{"system": systemJWT, "app": appJWT, "billing": billingJWT},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := testAccProtoV6ProviderFactories["nsc"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaResp, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readResp, err := server.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: "nsc_account_registry",
+		Config:   &tfprotov6.DynamicValue{JSON: config},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range readResp.Diagnostics {
+		t.Fatalf("unexpected read diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+
+	state, err := readResp.State.Unmarshal(schemaResp.DataSourceSchemas["nsc_account_registry"].ValueType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	if err := state.As(&attrs); err != nil {
+		t.Fatal(err)
+	}
+
+	var publicKeyValues []tftypes.Value
+	if err := attrs["public_keys"].As(&publicKeyValues); err != nil {
+		t.Fatal(err)
+	}
+	var publicKeys []string
+	for _, v := range publicKeyValues {
+		var key string
+		if err := v.As(&key); err != nil {
+			t.Fatal(err)
+		}
+		publicKeys = append(publicKeys, key)
+	}
+	// Ordered by account name: app, billing, system
+	if want := []string{appPub, billingPub, systemPub}; len(publicKeys) != 3 || publicKeys[0] != want[0] || publicKeys[1] != want[1] || publicKeys[2] != want[2] {
+		t.Errorf("expected public keys %v, got %v", want, publicKeys)
+	}
+
+	var expiresAt map[string]tftypes.Value
+	if err := attrs["expires_at"].As(&expiresAt); err != nil {
+		t.Fatal(err)
+	}
+	if len(expiresAt) != 2 {
+		t.Errorf("expected 2 expiring accounts, got %d", len(expiresAt))
+	}
+
+	var nextExpiry, nextExpiryAccount string
+	if err := attrs["next_expiry"].As(&nextExpiry); err != nil {
+		t.Fatal(err)
+	}
+	if err := attrs["next_expiry_account"].As(&nextExpiryAccount); err != nil {
+		t.Fatal(err)
+	}
+	if nextExpiry != sooner.Format(time.RFC3339) || nextExpiryAccount != "billing" {
+		t.Errorf("expected billing to expire first at %s, got %s at %s", sooner.Format(time.RFC3339), nextExpiryAccount, nextExpiry)
+	}
+}
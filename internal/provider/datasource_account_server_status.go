@@ -0,0 +1,279 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ datasource.DataSource = &AccountServerStatusDataSource{}
+
+const accountServerStatusDefaultTimeout = 10 * time.Second
+
+// errAccountNotServed is returned when the account server has no JWT for the
+// account.
+var errAccountNotServed = errors.New("account server has no JWT for the account")
+
+func NewAccountServerStatusDataSource() datasource.DataSource {
+	return &AccountServerStatusDataSource{}
+}
+
+type AccountServerStatusDataSource struct{}
+
+type AccountServerStatusDataSourceModel struct {
+	ID                types.String         `tfsdk:"id"`
+	URL               types.String         `tfsdk:"url"`
+	Account           types.String         `tfsdk:"account"`
+	ExpectedJWT       types.String         `tfsdk:"expected_jwt"`
+	ExpectedJWTID     types.String         `tfsdk:"expected_jwt_id"`
+	Timeout           timetypes.GoDuration `tfsdk:"timeout"`
+	Healthy           types.Bool           `tfsdk:"healthy"`
+	Served            types.Bool           `tfsdk:"served"`
+	UpToDate          types.Bool           `tfsdk:"up_to_date"`
+	ServedJWTID       types.String         `tfsdk:"served_jwt_id"`
+	ServedFingerprint types.String         `tfsdk:"served_fingerprint"`
+	ServedIssuedAt    timetypes.RFC3339    `tfsdk:"served_issued_at"`
+	Error             types.String         `tfsdk:"error"`
+}
+
+func (d *AccountServerStatusDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_account_server_status"
+}
+
+func (d *AccountServerStatusDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Queries the health of a nats-account-server and the JWT it serves for an account, reporting whether the expected JWT is being served. Useful to gate subsequent deployment stages on the account server having picked up a change. " +
+			"An unreachable server or a missing or outdated JWT does not fail the read; assert on `healthy` and `up_to_date` in a `check` block or a postcondition.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "URL of the account JWT endpoint",
+			},
+			"url": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "URL of the JWT API of the account server, as set in `account_server_url` of the operator, e.g. `https://accounts.example.com/jwt/v1`. The health endpoint is expected at `/healthz` of the same host.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^https?://`), "must be an http or https URL"),
+				},
+			},
+			"account": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the account whose JWT is checked",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^A[A-Z2-7]{55}$`),
+						"must be a valid account public key starting with 'A'",
+					),
+				},
+			},
+			"expected_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Account JWT expected to be served, e.g. `nsc_account.app.jwt`. Compared by fingerprint. Mutually exclusive with `expected_jwt_id`.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("expected_jwt_id")),
+				},
+			},
+			"expected_jwt_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "ID (`jti`) of the account JWT expected to be served, e.g. `nsc_account.app.jwt_id`. Mutually exclusive with `expected_jwt`.",
+			},
+			"timeout": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Time limit for each request. Defaults to `10s`.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"healthy": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the health endpoint of the account server answered with `200 OK`",
+			},
+			"served": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the account server serves a JWT for the account",
+			},
+			"up_to_date": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the served JWT is the expected one. Without `expected_jwt` or `expected_jwt_id`, same as `served`.",
+			},
+			"served_jwt_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID (`jti`) of the served JWT. Null when no JWT is served.",
+			},
+			"served_fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "SHA-256 of the served JWT, hex encoded. Null when no JWT is served.",
+			},
+			"served_issued_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Computed:            true,
+				MarkdownDescription: "Issue time of the served JWT. Null when no JWT is served.",
+			},
+			"error": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Reason the server is not healthy or the expected JWT is not served. Null otherwise.",
+			},
+		},
+	}
+}
+
+func (d *AccountServerStatusDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AccountServerStatusDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout := accountServerStatusDefaultTimeout
+	if !data.Timeout.IsNull() {
+		duration, diags := data.Timeout.ValueGoDuration()
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		timeout = duration
+	}
+
+	baseURL, err := url.Parse(strings.TrimSuffix(data.URL.ValueString(), "/"))
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("url"), "Invalid URL", err.Error())
+		return
+	}
+	jwtURL := baseURL.JoinPath("accounts", data.Account.ValueString())
+	healthURL := baseURL.ResolveReference(&url.URL{Path: "/healthz"})
+
+	client := &http.Client{Timeout: timeout}
+
+	data.ID = types.StringValue(jwtURL.String())
+	data.Served = types.BoolValue(false)
+	data.UpToDate = types.BoolValue(false)
+	data.ServedJWTID = types.StringNull()
+	data.ServedFingerprint = types.StringNull()
+	data.ServedIssuedAt = timetypes.NewRFC3339Null()
+	data.Error = types.StringNull()
+
+	var problems []string
+
+	err = accountServerHealth(ctx, client, healthURL.String())
+	data.Healthy = types.BoolValue(err == nil)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	served, err := fetchAccountServerJWT(ctx, client, jwtURL.String())
+	if err == nil {
+		var claims *jwt.AccountClaims
+		claims, err = jwt.DecodeAccountClaims(served)
+		if err == nil && claims.Subject != data.Account.ValueString() {
+			err = fmt.Errorf("account server served a JWT for account %s", claims.Subject)
+		}
+		if err == nil {
+			data.Served = types.BoolValue(true)
+			data.ServedJWTID = types.StringValue(claims.ID)
+			data.ServedFingerprint = types.StringValue(jwtFingerprint(served))
+			data.ServedIssuedAt = timetypes.NewRFC3339TimeValue(time.Unix(claims.IssuedAt, 0).UTC())
+			err = accountServerExpectation(data, claims.ID, jwtFingerprint(served))
+			data.UpToDate = types.BoolValue(err == nil)
+		}
+	}
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) > 0 {
+		data.Error = types.StringValue(strings.Join(problems, "; "))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// accountServerExpectation checks the served JWT against the expected JWT or
+// JWT ID of the data source.
+func accountServerExpectation(data AccountServerStatusDataSourceModel, servedID, servedFingerprint string) error {
+	switch {
+	case !data.ExpectedJWT.IsNull():
+		expected := jwtFingerprint(data.ExpectedJWT.ValueString())
+		if servedFingerprint != expected {
+			return fmt.Errorf("served JWT has fingerprint %s, expected %s", servedFingerprint, expected)
+		}
+	case !data.ExpectedJWTID.IsNull():
+		if servedID != data.ExpectedJWTID.ValueString() {
+			return fmt.Errorf("served JWT has ID %s, expected %s", servedID, data.ExpectedJWTID.ValueString())
+		}
+	}
+	return nil
+}
+
+// jwtFingerprint returns the hex encoded SHA-256 of a JWT, ignoring
+// surrounding whitespace.
+func jwtFingerprint(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.TrimSpace(token))))
+}
+
+// accountServerHealth requests the health endpoint of an account server.
+func accountServerHealth(ctx context.Context, client *http.Client, healthURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned %s", resp.Status)
+	}
+	return nil
+}
+
+// fetchAccountServerJWT requests the JWT of an account from an account
+// server. It returns errAccountNotServed when the server has none.
+func fetchAccountServerJWT(ctx context.Context, client *http.Client, jwtURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwtURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("account JWT request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusNoContent:
+		return "", errAccountNotServed
+	default:
+		return "", fmt.Errorf("account JWT request returned %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read account JWT: %w", err)
+	}
+	token := strings.TrimSpace(string(body))
+	if token == "" {
+		return "", errAccountNotServed
+	}
+	return token, nil
+}
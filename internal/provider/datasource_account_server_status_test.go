@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// testAccountServer serves account JWTs like nats-account-server.
+func testAccountServer(t *testing.T, jwts map[string]string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("/jwt/v1/accounts/{account}", func(w http.ResponseWriter, r *http.Request) {
+		token, ok := jwts[r.PathValue("account")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/jwt")
+		w.Write([]byte(token))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testAccountJWT(t *testing.T) (string, string) {
+	t.Helper()
+	operatorKP, _ := nkeys.CreateOperator()
+	accountKP, _ := nkeys.CreateAccount()
+	account, _ := accountKP.PublicKey()
+	token, err := jwt.NewAccountClaims(account).Encode(operatorKP)
+	if err != nil {
+		t.Fatalf("failed to encode account JWT: %v", err)
+	}
+	return account, token
+}
+
+func TestFetchAccountServerJWT(t *testing.T) {
+	account, token := testAccountJWT(t)
+	server := testAccountServer(t, map[string]string{account: token})
+
+	got, err := fetchAccountServerJWT(context.Background(), server.Client(), server.URL+"/jwt/v1/accounts/"+account)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != token {
+		t.Errorf("expected the account JWT, got %q", got)
+	}
+
+	_, err = fetchAccountServerJWT(context.Background(), server.Client(), server.URL+"/jwt/v1/accounts/AOTHER")
+	if !errors.Is(err, errAccountNotServed) {
+		t.Errorf("expected errAccountNotServed, got %v", err)
+	}
+
+	if err := accountServerHealth(context.Background(), server.Client(), server.URL+"/healthz"); err != nil {
+		t.Errorf("unexpected health error: %v", err)
+	}
+	if err := accountServerHealth(context.Background(), server.Client(), server.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a 404 health error, got %v", err)
+	}
+}
+
+func TestAccountServerExpectation(t *testing.T) {
+	_, token := testAccountJWT(t)
+	fingerprint := jwtFingerprint(token)
+
+	tests := []struct {
+		name string
+		data AccountServerStatusDataSourceModel
+		want string
+	}{
+		{
+			name: "no expectation",
+			data: AccountServerStatusDataSourceModel{},
+		},
+		{
+			name: "expected JWT",
+			data: AccountServerStatusDataSourceModel{ExpectedJWT: types.StringValue(token + "\n")},
+		},
+		{
+			name: "other JWT",
+			data: AccountServerStatusDataSourceModel{ExpectedJWT: types.StringValue("other")},
+			want: "served JWT has fingerprint " + fingerprint,
+		},
+		{
+			name: "expected JWT ID",
+			data: AccountServerStatusDataSourceModel{ExpectedJWTID: types.StringValue("JTI")},
+		},
+		{
+			name: "other JWT ID",
+			data: AccountServerStatusDataSourceModel{ExpectedJWTID: types.StringValue("OTHER")},
+			want: "served JWT has ID JTI, expected OTHER",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := accountServerExpectation(tt.data, "JTI", fingerprint)
+			if tt.want == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestAccAccountServerStatusDataSource(t *testing.T) {
+	account, token := testAccountJWT(t)
+	claims, _ := jwt.DecodeAccountClaims(token)
+	server := testAccountServer(t, map[string]string{account: token})
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountServerStatusDataSourceConfig(server.URL, account, fmt.Sprintf("expected_jwt_id = %q", claims.ID)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_account_server_status.test", "healthy", "true"),
+					resource.TestCheckResourceAttr("data.nsc_account_server_status.test", "served", "true"),
+					resource.TestCheckResourceAttr("data.nsc_account_server_status.test", "up_to_date", "true"),
+					resource.TestCheckResourceAttr("data.nsc_account_server_status.test", "served_jwt_id", claims.ID),
+					resource.TestCheckResourceAttr("data.nsc_account_server_status.test", "served_fingerprint", jwtFingerprint(token)),
+					resource.TestCheckNoResourceAttr("data.nsc_account_server_status.test", "error"),
+				),
+			},
+			{
+				Config: testAccAccountServerStatusDataSourceConfig(server.URL, account, `expected_jwt_id = "OTHER"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_account_server_status.test", "served", "true"),
+					resource.TestCheckResourceAttr("data.nsc_account_server_status.test", "up_to_date", "false"),
+					resource.TestMatchResourceAttr("data.nsc_account_server_status.test", "error", regexp.MustCompile("expected OTHER")),
+				),
+			},
+			{
+				Config: testAccAccountServerStatusDataSourceConfig(server.URL, account, `
+  expected_jwt    = "a"
+  expected_jwt_id = "b"`),
+				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
+			},
+		},
+	})
+}
+
+func testAccAccountServerStatusDataSourceConfig(serverURL, account, expectation string) string {
+	return fmt.Sprintf(`
+data "nsc_account_server_status" "test" {
+  url     = "%s/jwt/v1"
+  account = %q
+  %s
+}
+`, serverURL, account, expectation)
+}
@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &AuthCalloutConfigDataSource{}
+
+func NewAuthCalloutConfigDataSource() datasource.DataSource {
+	return &AuthCalloutConfigDataSource{}
+}
+
+type AuthCalloutConfigDataSource struct{}
+
+type AuthCalloutConfigDataSourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	Issuer          types.String `tfsdk:"issuer"`
+	Account         types.String `tfsdk:"account"`
+	AuthUsers       types.List   `tfsdk:"auth_users"`
+	AllowedAccounts types.List   `tfsdk:"allowed_accounts"`
+	XKey            types.String `tfsdk:"xkey"`
+	Config          types.String `tfsdk:"config"`
+}
+
+func (d *AuthCalloutConfigDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_auth_callout_config"
+}
+
+func (d *AuthCalloutConfigDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders the `authorization { auth_callout { ... } }` block of a nats-server configuration from the auth callout settings, so the server configuration and the keys of the callout service come from the same Terraform values.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Issuer public key (same as issuer)",
+			},
+			"issuer": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Account public key the callout service signs authorization responses with, e.g. `nsc_nkey.auth_issuer.public_key`",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^A[A-Z0-9]{55}$`),
+						"must be a valid account public key starting with 'A'",
+					),
+				},
+			},
+			"account": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Name of the account the auth users belong to",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"auth_users": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				MarkdownDescription: "Users that bypass the callout, i.e. the users the callout service connects with",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"allowed_accounts": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Names of the accounts the callout service may assign users to. Defaults to all accounts.",
+			},
+			"xkey": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Curve public key (`X...`) to encrypt authorization requests to the callout service with",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^X[A-Z0-9]{55}$`),
+						"must be a valid curve public key starting with 'X'",
+					),
+				},
+			},
+			"config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The rendered `authorization` block",
+			},
+		},
+	}
+}
+
+func (d *AuthCalloutConfigDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AuthCalloutConfigDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	authUsers, diags := stringListValues(ctx, data.AuthUsers)
+	resp.Diagnostics.Append(diags...)
+	allowedAccounts, diags := stringListValues(ctx, data.AllowedAccounts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.Issuer
+	data.Config = types.StringValue(authCalloutConfig(
+		data.Issuer.ValueString(),
+		data.Account.ValueString(),
+		authUsers,
+		allowedAccounts,
+		data.XKey.ValueString(),
+	))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// authCalloutConfig renders the authorization block of nats-server with an
+// auth_callout section. Names are quoted, keys are not.
+func authCalloutConfig(issuer, account string, authUsers, allowedAccounts []string, xkey string) string {
+	var b strings.Builder
+	b.WriteString("authorization {\n")
+	b.WriteString("  auth_callout {\n")
+	fmt.Fprintf(&b, "    issuer: %s\n", issuer)
+	fmt.Fprintf(&b, "    account: %q\n", account)
+	fmt.Fprintf(&b, "    auth_users: [%s]\n", quotedList(authUsers))
+	if len(allowedAccounts) > 0 {
+		fmt.Fprintf(&b, "    allowed_accounts: [%s]\n", quotedList(allowedAccounts))
+	}
+	if xkey != "" {
+		fmt.Fprintf(&b, "    xkey: %s\n", xkey)
+	}
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// quotedList renders values as a comma separated list of quoted strings.
+func quotedList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, ", ")
+}
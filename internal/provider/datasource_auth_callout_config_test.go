@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAuthCalloutConfigDataSource_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "issuer" {
+  type = "account"
+}
+
+data "nsc_auth_callout_config" "test" {
+  issuer           = nsc_nkey.issuer.public_key
+  account          = "AUTH"
+  auth_users       = ["auth"]
+  allowed_accounts = ["APP", "OPS"]
+  xkey             = "XAB3NANV3M6N7AHSQP2U5FRWKKUT7EG2ZXXABV4XVXYQRJGM4S2CZGHT"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nsc_auth_callout_config.test", "id", "nsc_nkey.issuer", "public_key"),
+					resource.TestMatchResourceAttr("data.nsc_auth_callout_config.test", "config", regexp.MustCompile(
+						`^authorization \{\n  auth_callout \{\n    issuer: A[A-Z0-9]{55}\n    account: "AUTH"\n    auth_users: \["auth"\]\n    allowed_accounts: \["APP", "OPS"\]\n    xkey: XAB3NANV3M6N7AHSQP2U5FRWKKUT7EG2ZXXABV4XVXYQRJGM4S2CZGHT\n  \}\n\}\n$`,
+					)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAuthCalloutConfigDataSource_invalidXKey(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "issuer" {
+  type = "account"
+}
+
+data "nsc_auth_callout_config" "test" {
+  issuer     = nsc_nkey.issuer.public_key
+  account    = "AUTH"
+  auth_users = ["auth"]
+  xkey       = nsc_nkey.issuer.public_key
+}
+`,
+				ExpectError: regexp.MustCompile("must be a valid curve public key"),
+			},
+		},
+	})
+}
+
+func TestAuthCalloutConfig(t *testing.T) {
+	config := authCalloutConfig("AISSUER", "AUTH", []string{"auth", "auth2"}, nil, "")
+	expected := `authorization {
+  auth_callout {
+    issuer: AISSUER
+    account: "AUTH"
+    auth_users: ["auth", "auth2"]
+  }
+}
+`
+	if config != expected {
+		t.Errorf("unexpected config:\n%s", config)
+	}
+}
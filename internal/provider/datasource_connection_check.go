@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &ConnectionCheckDataSource{}
+var _ datasource.DataSourceWithConfigure = &ConnectionCheckDataSource{}
+
+const connectionCheckDefaultTimeout = 5 * time.Second
+
+func NewConnectionCheckDataSource() datasource.DataSource {
+	return &ConnectionCheckDataSource{}
+}
+
+type ConnectionCheckDataSource struct {
+	keys *keypairCache
+	nats *NATSConnectionModel
+}
+
+type ConnectionCheckDataSourceModel struct {
+	ID  types.String `tfsdk:"id"`
+	URL types.String `tfsdk:"url"`
+	NATSConnectionModel
+	Connected     types.Bool   `tfsdk:"connected"`
+	Error         types.String `tfsdk:"error"`
+	ServerID      types.String `tfsdk:"server_id"`
+	ServerName    types.String `tfsdk:"server_name"`
+	ServerVersion types.String `tfsdk:"server_version"`
+	Account       types.String `tfsdk:"account"`
+}
+
+func (d *ConnectionCheckDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_connection_check"
+}
+
+func (d *ConnectionCheckDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Connects to a NATS server with user credentials and reports whether the server accepted them, validating end to end that the operator, account and user JWTs are trusted by the cluster. A failed connection does not fail the read; assert on `connected` in a `check` block or a postcondition.",
+
+		Attributes: natsConnectionAttributes(map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Server URL (same as url)",
+			},
+			"url": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Server URL, e.g. `nats://nats.example.com:4222`. The `tls` scheme forces TLS, which is also used whenever the server requires it. The `ws` and `wss` schemes connect over WebSocket, e.g. `wss://nats.example.com/ws`, through the proxy of the `HTTPS_PROXY` or `HTTP_PROXY` environment variable. As with `nats`, setting TLS options forces TLS.",
+			},
+			"creds": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "User credentials file content, e.g. from `nsc_user.creds` or `data.nsc_creds`. Defaults to the provider `nats` block. One of `creds`, `nkey_seed` or `user` must be set here or there.",
+				Validators:          natsConnectionValidators()["creds"],
+			},
+			"timeout": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Time limit for connecting and authenticating. Defaults to `5s`, or the `timeout` of the provider `nats` block.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"connected": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the server accepted the credentials",
+			},
+			"error": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Reason the connection failed. Null when connected.",
+			},
+			"server_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID of the server connected to",
+			},
+			"server_name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the server connected to",
+			},
+			"server_version": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Version of the server connected to",
+			},
+			"account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account the server authenticated the user into. Null when the server does not answer `$SYS.REQ.USER.INFO` requests (nats-server before 2.10, or denied by the user's permissions).",
+			},
+		}),
+	}
+}
+
+func (d *ConnectionCheckDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.keys = providerData.Keys
+	d.nats = providerData.NATS
+}
+
+func (d *ConnectionCheckDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ConnectionCheckDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, timeout, diags := data.natsConnectOptions(d.nats, d.keys, connectionCheckDefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	data.ID = data.URL
+	data.ServerID = types.StringNull()
+	data.ServerName = types.StringNull()
+	data.ServerVersion = types.StringNull()
+	data.Account = types.StringNull()
+
+	conn, err := natsConnect(checkCtx, data.URL.ValueString(), opts)
+	if err != nil {
+		data.Connected = types.BoolValue(false)
+		data.Error = types.StringValue(err.Error())
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	data.Connected = types.BoolValue(true)
+	data.Error = types.StringNull()
+	data.ServerID = types.StringValue(conn.Server.ServerID)
+	data.ServerName = types.StringValue(conn.Server.ServerName)
+	data.ServerVersion = types.StringValue(conn.Server.Version)
+	if conn.Account != "" {
+		data.Account = types.StringValue(conn.Account)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
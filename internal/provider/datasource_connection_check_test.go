@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccConnectionCheckDataSource(t *testing.T) {
+	server := newFakeNATSServer(t)
+	server.account = "ATESTACCOUNT"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccConnectionCheckDataSourceConfig(server.URL()),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_connection_check.test", "connected", "true"),
+					resource.TestCheckNoResourceAttr("data.nsc_connection_check.test", "error"),
+					resource.TestCheckResourceAttr("data.nsc_connection_check.test", "server_version", "2.11.0"),
+					resource.TestCheckResourceAttr("data.nsc_connection_check.test", "account", "ATESTACCOUNT"),
+				),
+			},
+			{
+				// Nothing listens on port 1
+				Config: testAccConnectionCheckDataSourceConfig("nats://127.0.0.1:1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_connection_check.test", "connected", "false"),
+					resource.TestMatchResourceAttr("data.nsc_connection_check.test", "error", regexp.MustCompile(`failed to connect`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccConnectionCheckDataSource_providerDefaults(t *testing.T) {
+	server := newFakeNATSServer(t)
+	server.password = "secret"
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "nsc" {
+  nats {
+    user     = "app"
+    password = "secret"
+    timeout  = "2s"
+  }
+}
+
+data "nsc_connection_check" "test" {
+  url = %[1]q
+}
+`, server.URL()),
+				Check: resource.TestCheckResourceAttr("data.nsc_connection_check.test", "connected", "true"),
+			},
+			{
+				Config: fmt.Sprintf(`
+data "nsc_connection_check" "test" {
+  url = %[1]q
+}
+`, server.URL()),
+				ExpectError: regexp.MustCompile("Missing NATS credentials"),
+			},
+		},
+	})
+}
+
+func testAccConnectionCheckDataSourceConfig(url string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name        = "TestUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  seed        = nsc_nkey.user.seed
+}
+
+data "nsc_connection_check" "test" {
+  url     = %[1]q
+  creds   = nsc_user.test.creds
+  timeout = "2s"
+}
+`, url)
+}
@@ -6,16 +6,21 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
 )
 
 var _ datasource.DataSource = &CredsDataSource{}
+var _ datasource.DataSourceWithConfigure = &CredsDataSource{}
 
 func NewCredsDataSource() datasource.DataSource {
 	return &CredsDataSource{}
 }
 
-type CredsDataSource struct{}
+type CredsDataSource struct {
+	keys *keypairCache
+}
 
 type CredsDataSourceModel struct {
 	ID    types.String `tfsdk:"id"`
@@ -30,7 +35,8 @@ func (d *CredsDataSource) Metadata(_ context.Context, req datasource.MetadataReq
 
 func (d *CredsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Generates NATS credentials file content from a JWT and seed. Use with nsc_user resource outputs.",
+		MarkdownDescription: "Generates NATS credentials file content from a JWT and seed. Use with nsc_user resource outputs. " +
+			"Without `seed`, the JWT must be a bearer token (`bearer = true`) and the credentials hold the JWT only, as bearer authentication needs no signature.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -42,9 +48,9 @@ func (d *CredsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest,
 				MarkdownDescription: "User JWT token",
 			},
 			"seed": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
 				Sensitive:           true,
-				MarkdownDescription: "User seed (private key)",
+				MarkdownDescription: "User seed (private key), starting with `SU`. Seeds encrypted with the provider's `state_encryption_key` are decrypted. Omit it for JWT-only credentials of a bearer token.",
 			},
 			"creds": schema.StringAttribute{
 				Computed:            true,
@@ -55,6 +61,23 @@ func (d *CredsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest,
 	}
 }
 
+func (d *CredsDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.keys = providerData.Keys
+}
+
 func (d *CredsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
 	var data CredsDataSourceModel
 
@@ -63,11 +86,65 @@ func (d *CredsDataSource) Read(ctx context.Context, req datasource.ReadRequest,
 		return
 	}
 
-	jwt := data.JWT.ValueString()
-	seed := data.Seed.ValueString()
+	token := data.JWT.ValueString()
+
+	var creds string
+	if data.Seed.IsNull() {
+		claims, err := jwt.DecodeUserClaims(token)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("jwt"), "Invalid JWT", fmt.Sprintf("Failed to decode user JWT: %s", err))
+			return
+		}
+		if !claims.BearerToken {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("seed"),
+				"Missing seed",
+				fmt.Sprintf("Credentials without a seed only work for bearer tokens, but the JWT of user %s is not one. Set seed, or issue the user with bearer = true.", claims.Subject),
+			)
+			return
+		}
+		creds = formatBearerCreds(token)
+	} else {
+		seed, err := d.keys.seed(data.Seed.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("seed"), "Invalid seed", err.Error())
+			return
+		}
+		if err := validateUserSeed(seed); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("seed"), "Invalid seed", err.Error())
+			return
+		}
+		creds = formatCreds(token, seed)
+	}
 
-	// Generate creds file content
-	creds := fmt.Sprintf(`-----BEGIN NATS USER JWT-----
+	data.ID = types.StringValue(token)
+	data.Creds = types.StringValue(creds)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// validateUserSeed returns an error unless seed is a user seed, with guidance
+// for the account and operator seeds that are easily passed by mistake. The
+// error never contains the seed.
+func validateUserSeed(seed string) error {
+	_, keyType, err := parseNKeySeed(seed)
+	if err != nil {
+		return err
+	}
+
+	switch keyType {
+	case "user":
+		return nil
+	case "account":
+		return fmt.Errorf("expected a user seed (prefix SU), got an account seed (prefix SA). Account seeds sign user JWTs as the issuer_seed of nsc_user; credentials need the seed of the user itself, e.g. nsc_nkey.user.seed")
+	default:
+		return fmt.Errorf("expected a user seed (prefix SU), got an %s seed (prefix SO). Operator seeds sign account JWTs; credentials need the seed of the user itself, e.g. nsc_nkey.user.seed", keyType)
+	}
+}
+
+// formatCreds renders a NATS credentials file from a user JWT and seed.
+func formatCreds(jwt, seed string) string {
+	return fmt.Sprintf(`-----BEGIN NATS USER JWT-----
 %s
 ------END NATS USER JWT------
 
@@ -81,9 +158,13 @@ NKEYs are sensitive and should be treated as secrets.
 
 *************************************************************
 `, jwt, seed)
+}
 
-	data.ID = types.StringValue(jwt)
-	data.Creds = types.StringValue(creds)
-
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+// formatBearerCreds renders a NATS credentials file holding only the JWT of
+// a bearer user.
+func formatBearerCreds(jwt string) string {
+	return fmt.Sprintf(`-----BEGIN NATS USER JWT-----
+%s
+------END NATS USER JWT------
+`, jwt)
 }
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ datasource.DataSource = &CredsFileDataSource{}
+var _ datasource.DataSourceWithConfigure = &CredsFileDataSource{}
+
+func NewCredsFileDataSource() datasource.DataSource {
+	return &CredsFileDataSource{}
+}
+
+type CredsFileDataSource struct {
+	cipher *stateCipher
+}
+
+type CredsFileDataSourceModel struct {
+	ID            types.String      `tfsdk:"id"`
+	Path          types.String      `tfsdk:"path"`
+	JWT           types.String      `tfsdk:"jwt"`
+	Seed          types.String      `tfsdk:"seed"`
+	PublicKey     types.String      `tfsdk:"public_key"`
+	Type          types.String      `tfsdk:"type"`
+	Name          types.String      `tfsdk:"name"`
+	Issuer        types.String      `tfsdk:"issuer"`
+	IssuerAccount types.String      `tfsdk:"issuer_account"`
+	ExpiresAt     timetypes.RFC3339 `tfsdk:"expires_at"`
+	ClaimsJSON    types.String      `tfsdk:"claims_json"`
+}
+
+func (d *CredsFileDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_creds_file"
+}
+
+func (d *CredsFileDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads an existing NATS credentials file from disk and exposes its JWT, seed and decoded claims. Useful for bootstrapping from credentials issued outside Terraform, e.g. by the nsc CLI. The JWT signature is verified and the seed must belong to the JWT subject.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Path of the credentials file (same as path)",
+			},
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path of the credentials file",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "JWT from the credentials file",
+			},
+			"seed": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed (private key) from the credentials file. Encrypted when the provider has a `state_encryption_key`.",
+			},
+			"public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the seed (same as the JWT subject)",
+			},
+			"type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Claim type of the JWT, e.g. `user`",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name in the JWT",
+			},
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the key that signed the JWT",
+			},
+			"issuer_account": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Account public key when the JWT was signed by an account signing key. Null otherwise.",
+			},
+			"expires_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Computed:            true,
+				MarkdownDescription: "Expiry of the JWT. Null when the JWT does not expire.",
+			},
+			"claims_json": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Decoded JWT claims as JSON",
+			},
+		},
+	}
+}
+
+func (d *CredsFileDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.cipher = providerData.StateCipher
+}
+
+func (d *CredsFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data CredsFileDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	content, err := os.ReadFile(data.Path.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to read credentials file", err.Error())
+		return
+	}
+
+	token, err := jwt.ParseDecoratedJWT(content)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid credentials file", "Failed to read the JWT: "+err.Error())
+		return
+	}
+	kp, err := jwt.ParseDecoratedNKey(content)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid credentials file", "Failed to read the seed: "+err.Error())
+		return
+	}
+
+	claims, err := jwt.Decode(token)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid credentials file", "Failed to decode the JWT: "+err.Error())
+		return
+	}
+	claimsData := claims.Claims()
+
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid credentials file", "Failed to get the public key of the seed: "+err.Error())
+		return
+	}
+	if publicKey != claimsData.Subject {
+		resp.Diagnostics.AddError(
+			"Invalid credentials file",
+			fmt.Sprintf("The seed belongs to %s, but the JWT was issued for %s.", publicKey, claimsData.Subject),
+		)
+		return
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid credentials file", "Failed to read the seed: "+err.Error())
+		return
+	}
+	stateSeed, err := d.cipher.encrypt(string(seed))
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encrypt seed", err.Error())
+		return
+	}
+
+	payload, err := jwtPayload(token)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid credentials file", "Failed to decode the JWT: "+err.Error())
+		return
+	}
+
+	data.ID = data.Path
+	data.JWT = types.StringValue(token)
+	data.Seed = types.StringValue(stateSeed)
+	data.PublicKey = types.StringValue(publicKey)
+	data.Type = types.StringValue(string(claims.ClaimType()))
+	data.Name = types.StringValue(claimsData.Name)
+	data.Issuer = types.StringValue(claimsData.Issuer)
+	data.IssuerAccount = types.StringNull()
+	if userClaims, ok := claims.(*jwt.UserClaims); ok && userClaims.IssuerAccount != "" {
+		data.IssuerAccount = types.StringValue(userClaims.IssuerAccount)
+	}
+	data.ExpiresAt = timetypes.NewRFC3339Null()
+	if claimsData.Expires != 0 {
+		data.ExpiresAt = timetypes.NewRFC3339TimeValue(time.Unix(claimsData.Expires, 0).UTC())
+	}
+	data.ClaimsJSON = types.StringValue(string(payload))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// jwtPayload returns the decoded claims segment of a JWT as is, including
+// claims the jwt library does not know about.
+func jwtPayload(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("expected 3 JWT segments, got %d", len(parts))
+	}
+	return base64.RawURLEncoding.DecodeString(parts[1])
+}
@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccCredsFileDataSource_basic(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	userSeed, _ := userKP.Seed()
+
+	claims := jwt.NewUserClaims(userPubKey)
+	claims.Name = "bootstrap"
+	claims.Expires = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	userJWT, err := claims.Encode(accountKP)
+	if err != nil {
+		t.Fatalf("failed to encode user JWT: %v", err)
+	}
+
+	dir := t.TempDir()
+	credsPath := filepath.Join(dir, "bootstrap.creds")
+	if err := os.WriteFile(credsPath, []byte(formatCreds(userJWT, string(userSeed))), 0600); err != nil {
+		t.Fatalf("failed to write creds file: %v", err)
+	}
+
+	otherKP, _ := nkeys.CreateUser()
+	otherSeed, _ := otherKP.Seed()
+	mismatchPath := filepath.Join(dir, "mismatch.creds")
+	if err := os.WriteFile(mismatchPath, []byte(formatCreds(userJWT, string(otherSeed))), 0600); err != nil {
+		t.Fatalf("failed to write creds file: %v", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredsFileDataSourceConfig(credsPath),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_creds_file.test", "id", credsPath),
+					resource.TestCheckResourceAttr("data.nsc_creds_file.test", "jwt", userJWT),
+					resource.TestCheckResourceAttr("data.nsc_creds_file.test", "seed", string(userSeed)),
+					resource.TestCheckResourceAttr("data.nsc_creds_file.test", "public_key", userPubKey),
+					resource.TestCheckResourceAttr("data.nsc_creds_file.test", "type", "user"),
+					resource.TestCheckResourceAttr("data.nsc_creds_file.test", "name", "bootstrap"),
+					resource.TestCheckResourceAttr("data.nsc_creds_file.test", "issuer", accountPubKey),
+					resource.TestCheckNoResourceAttr("data.nsc_creds_file.test", "issuer_account"),
+					resource.TestCheckResourceAttr("data.nsc_creds_file.test", "expires_at", "2030-01-01T00:00:00Z"),
+					resource.TestMatchResourceAttr("data.nsc_creds_file.test", "claims_json", regexp.MustCompile(`"name":"bootstrap"`)),
+				),
+			},
+			{
+				Config:      testAccCredsFileDataSourceConfig(mismatchPath),
+				ExpectError: regexp.MustCompile("but the JWT was issued for"),
+			},
+			{
+				Config:      testAccCredsFileDataSourceConfig(filepath.Join(dir, "missing.creds")),
+				ExpectError: regexp.MustCompile("Failed to read credentials file"),
+			},
+		},
+	})
+}
+
+func testAccCredsFileDataSourceConfig(path string) string {
+	return fmt.Sprintf(`
+data "nsc_creds_file" "test" {
+  path = %[1]q
+}
+`, path)
+}
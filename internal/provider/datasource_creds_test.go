@@ -1,10 +1,14 @@
 package provider
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
 )
 
 func TestAccCredsDataSource_basic(t *testing.T) {
@@ -23,10 +27,83 @@ func TestAccCredsDataSource_basic(t *testing.T) {
 					resource.TestMatchResourceAttr("data.nsc_creds.test", "creds", regexp.MustCompile(`------END USER NKEY SEED------`)),
 				),
 			},
+			{
+				Config:      strings.Replace(testAccCredsDataSourceConfig(), "seed = nsc_nkey.user.seed", "seed = nsc_nkey.account.seed", 1),
+				ExpectError: regexp.MustCompile(`got an account seed`),
+			},
 		},
 	})
 }
 
+func TestAccCredsDataSource_bearer(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccCredsDataSourceBearerConfig(true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.nsc_creds.test", "creds", regexp.MustCompile(`-----BEGIN NATS USER JWT-----`)),
+					resource.TestCheckResourceAttrWith("data.nsc_creds.test", "creds", func(value string) error {
+						if strings.Contains(value, "NKEY SEED") {
+							return fmt.Errorf("expected JWT-only credentials, got a seed")
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config:      testAccCredsDataSourceBearerConfig(false),
+				ExpectError: regexp.MustCompile(`only work for bearer tokens`),
+			},
+		},
+	})
+}
+
+func testAccCredsDataSourceBearerConfig(bearer bool) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name        = "TestUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  bearer      = %t
+}
+
+data "nsc_creds" "test" {
+  jwt = nsc_user.test.jwt
+}
+`, bearer)
+}
+
+func TestFormatBearerCreds(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	claims := jwt.NewUserClaims(userPubKey)
+	claims.BearerToken = true
+	token, err := claims.Encode(accountKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	creds := formatBearerCreds(token)
+	parsed, err := jwt.ParseDecoratedJWT([]byte(creds))
+	if err != nil || parsed != token {
+		t.Errorf("expected the JWT to parse from the credentials, got %q, %v", parsed, err)
+	}
+	if _, err := jwt.ParseDecoratedUserNKey([]byte(creds)); err == nil {
+		t.Error("expected no seed in the credentials")
+	}
+}
+
 func testAccCredsDataSourceConfig() string {
 	return `
 resource "nsc_nkey" "operator" {
@@ -65,3 +142,29 @@ data "nsc_creds" "test" {
 }
 `
 }
+
+func TestValidateUserSeed(t *testing.T) {
+	userKP, _ := nkeys.CreateUser()
+	userSeed, _ := userKP.Seed()
+	accountKP, _ := nkeys.CreateAccount()
+	accountSeed, _ := accountKP.Seed()
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorSeed, _ := operatorKP.Seed()
+
+	if err := validateUserSeed(string(userSeed)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for seed, want := range map[string]string{
+		string(accountSeed):  "got an account seed (prefix SA)",
+		string(operatorSeed): "got an operator seed (prefix SO)",
+		"not-a-seed":         "failed to parse seed",
+	} {
+		err := validateUserSeed(seed)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+		if err != nil && strings.Contains(err.Error(), seed) {
+			t.Errorf("expected the error not to contain the seed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,467 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ datasource.DataSource = &DescribeImportDataSource{}
+
+func NewDescribeImportDataSource() datasource.DataSource {
+	return &DescribeImportDataSource{}
+}
+
+type DescribeImportDataSource struct{}
+
+type DescribeImportDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	DescribeJSON   types.String `tfsdk:"describe_json"`
+	ResourceName   types.String `tfsdk:"resource_name"`
+	Kind           types.String `tfsdk:"kind"`
+	Name           types.String `tfsdk:"name"`
+	Subject        types.String `tfsdk:"subject"`
+	Issuer         types.String `tfsdk:"issuer"`
+	AttributesJSON types.String `tfsdk:"attributes_json"`
+	ResourceBlock  types.String `tfsdk:"resource_block"`
+	Unmapped       types.List   `tfsdk:"unmapped"`
+}
+
+// describedAttribute is a resource attribute mapped from a claim. Values are
+// strings, int64, bools or string lists.
+type describedAttribute struct {
+	Name  string
+	Value any
+}
+
+// describedClaims holds the resource attributes mapped from the claims of an
+// operator, account or user, in the order of the resource schema.
+type describedClaims struct {
+	Kind       string
+	Name       string
+	Subject    string
+	Issuer     string
+	Attributes []describedAttribute
+	Unmapped   []string
+}
+
+func (d *DescribeImportDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_describe_import"
+}
+
+func (d *DescribeImportDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Maps the output of `nsc describe operator --json`, `nsc describe account --json` or `nsc describe user --json` onto the attributes of `nsc_operator`, `nsc_account` or `nsc_user`, " +
+			"to adopt entities of an nsc store when their JWTs and seeds cannot be handed over together. The result is a resource block to paste into the configuration; the seed of the issuer is added there. " +
+			"Claims without an attribute of the resource, such as exports and imports, are listed in `unmapped`.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the described entity (same as subject)",
+			},
+			"describe_json": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Output of `nsc describe <kind> --json`, e.g. `file(\"app.json\")`. A JWT is accepted as well.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"resource_name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Name of the resource in `resource_block`. Defaults to the kind and name of the entity, e.g. `account_app`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`), "must be a valid Terraform resource name"),
+				},
+			},
+			"kind": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Kind of the entity: `operator`, `account` or `user`",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the entity",
+			},
+			"subject": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the entity",
+			},
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key that issued the JWT. Its seed is the `issuer_seed` of the resource.",
+			},
+			"attributes_json": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "JSON object of the mapped resource attributes, without `issuer_seed`. Attributes left at their defaults are omitted; limits are numbers.",
+			},
+			"resource_block": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resource block with the mapped attributes. `issuer_seed` is left as a comment naming the issuer.",
+			},
+			"unmapped": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Claims that are not mapped and have to be added to the resource by hand, e.g. `exports`",
+			},
+		},
+	}
+}
+
+func (d *DescribeImportDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data DescribeImportDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	claims, err := decodeDescribeJSON(data.DescribeJSON.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("describe_json"),
+			"Invalid describe output",
+			err.Error(),
+		)
+		return
+	}
+	described := describeClaims(claims)
+
+	resourceName := data.ResourceName.ValueString()
+	if data.ResourceName.IsNull() {
+		resourceName = describedResourceName(described)
+	}
+
+	attributes := make(map[string]any, len(described.Attributes))
+	for _, a := range described.Attributes {
+		attributes[a.Name] = a.Value
+	}
+	attributesJSON, err := json.Marshal(attributes)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to render attributes", err.Error())
+		return
+	}
+
+	unmapped, diags := types.ListValueFrom(ctx, types.StringType, described.Unmapped)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = types.StringValue(described.Subject)
+	data.ResourceName = types.StringValue(resourceName)
+	data.Kind = types.StringValue(described.Kind)
+	data.Name = types.StringValue(described.Name)
+	data.Subject = types.StringValue(described.Subject)
+	data.Issuer = types.StringValue(described.Issuer)
+	data.AttributesJSON = types.StringValue(string(attributesJSON))
+	data.ResourceBlock = types.StringValue(renderDescribedResource(described, resourceName))
+	data.Unmapped = unmapped
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// decodeDescribeJSON decodes the claims printed by nsc describe with --json,
+// or the claims of a JWT.
+func decodeDescribeJSON(s string) (jwt.Claims, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") {
+		claims, err := jwt.Decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected the JSON output of nsc describe or a JWT: %w", err)
+		}
+		switch claims.ClaimType() {
+		case jwt.OperatorClaim, jwt.AccountClaim, jwt.UserClaim:
+			if err := checkDescribedSubject(claims); err != nil {
+				return nil, err
+			}
+			return claims, nil
+		}
+		return nil, fmt.Errorf("expected an operator, account or user JWT, got a JWT of type %q", claims.ClaimType())
+	}
+
+	var header struct {
+		Nats struct {
+			Type jwt.ClaimType `json:"type"`
+		} `json:"nats"`
+	}
+	if err := json.Unmarshal([]byte(s), &header); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	var claims jwt.Claims
+	switch header.Nats.Type {
+	case jwt.OperatorClaim:
+		claims = &jwt.OperatorClaims{}
+	case jwt.AccountClaim:
+		claims = &jwt.AccountClaims{}
+	case jwt.UserClaim:
+		claims = &jwt.UserClaims{}
+	default:
+		return nil, fmt.Errorf("expected the output of nsc describe operator, account or user, got claims of type %q", header.Nats.Type)
+	}
+	if err := json.Unmarshal([]byte(s), claims); err != nil {
+		return nil, fmt.Errorf("failed to parse %s claims: %w", header.Nats.Type, err)
+	}
+	if claims.Claims().Subject == "" {
+		return nil, fmt.Errorf("%s claims have no subject", header.Nats.Type)
+	}
+	if err := checkDescribedSubject(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// describedSubjectPrefixes maps the described claim types to the prefix of
+// their subject.
+var describedSubjectPrefixes = map[jwt.ClaimType]nkeys.PrefixByte{
+	jwt.OperatorClaim: nkeys.PrefixByteOperator,
+	jwt.AccountClaim:  nkeys.PrefixByteAccount,
+	jwt.UserClaim:     nkeys.PrefixByteUser,
+}
+
+// checkDescribedSubject checks that the subject of claims is a public key of
+// their kind. The resource name falls back to a part of the subject.
+func checkDescribedSubject(claims jwt.Claims) error {
+	subject := claims.Claims().Subject
+	prefix := describedSubjectPrefixes[claims.ClaimType()]
+	if !nkeys.IsValidPublicKey(subject) || nkeys.Prefix(subject) != prefix {
+		return fmt.Errorf("%s claims have subject %q, which is not a valid %s public key", claims.ClaimType(), subject, prefix)
+	}
+	return nil
+}
+
+// describeClaims maps claims onto the attributes of the resource of their
+// kind. Values equal to the defaults of the resource are left out.
+func describeClaims(claims jwt.Claims) describedClaims {
+	data := claims.Claims()
+	described := describedClaims{
+		Kind:    string(claims.ClaimType()),
+		Name:    data.Name,
+		Subject: data.Subject,
+		Issuer:  data.Issuer,
+	}
+	described.add("name", data.Name)
+	described.add("subject", data.Subject)
+
+	switch c := claims.(type) {
+	case *jwt.OperatorClaims:
+		described.describeOperator(c)
+	case *jwt.AccountClaims:
+		described.describeAccount(c)
+	case *jwt.UserClaims:
+		described.describeUser(c)
+	}
+
+	if data.Expires != 0 {
+		described.add("expires_at", time.Unix(data.Expires, 0).UTC().Format(time.RFC3339))
+	}
+	if data.NotBefore != 0 {
+		described.add("starts_at", time.Unix(data.NotBefore, 0).UTC().Format(time.RFC3339))
+	}
+	return described
+}
+
+func (d *describedClaims) describeOperator(c *jwt.OperatorClaims) {
+	if len(c.SigningKeys) > 0 {
+		d.add("signing_keys", []string(c.SigningKeys))
+	}
+	if c.SystemAccount != "" {
+		d.add("system_account", c.SystemAccount)
+	}
+
+	d.unmappedIf(c.AccountServerURL != "", "account_server_url")
+	d.unmappedIf(len(c.OperatorServiceURLs) > 0, "operator_service_urls")
+	d.unmappedIf(c.AssertServerVersion != "", "assert_server_version")
+	d.unmappedIf(c.StrictSigningKeyUsage, "strict_signing_key_usage")
+	d.unmappedIf(len(c.Tags) > 0, "tags")
+}
+
+func (d *describedClaims) describeAccount(c *jwt.AccountClaims) {
+	var signingKeys []string
+	for _, key := range sortedKeys(c.SigningKeys) {
+		if c.SigningKeys[key] != nil {
+			d.Unmapped = append(d.Unmapped, "signing_keys."+key)
+			continue
+		}
+		signingKeys = append(signingKeys, key)
+	}
+	if len(signingKeys) > 0 {
+		d.add("signing_keys", signingKeys)
+	}
+	d.describePermissions(c.DefaultPermissions)
+
+	defaults := jwt.NewAccountClaims(c.Subject).Limits
+	limits := c.Limits
+	d.addIfChanged("max_connections", limits.Conn, defaults.Conn)
+	d.addIfChanged("max_leaf_nodes", limits.LeafNodeConn, defaults.LeafNodeConn)
+	d.addIfChanged("max_data", limits.Data, defaults.Data)
+	d.addIfChanged("max_payload", limits.Payload, defaults.Payload)
+	d.addIfChanged("max_subscriptions", limits.Subs, defaults.Subs)
+	d.addIfChanged("max_imports", limits.Imports, defaults.Imports)
+	d.addIfChanged("max_exports", limits.Exports, defaults.Exports)
+	if limits.WildcardExports != defaults.WildcardExports {
+		d.add("allow_wildcard_exports", limits.WildcardExports)
+	}
+	if limits.DisallowBearer {
+		d.add("disallow_bearer_token", true)
+	}
+	if c.ClusterTraffic != "" {
+		d.add("cluster_traffic", string(c.ClusterTraffic))
+	}
+	d.addIfChanged("max_memory_storage", limits.MemoryStorage, defaults.MemoryStorage)
+	d.addIfChanged("max_disk_storage", limits.DiskStorage, defaults.DiskStorage)
+	d.addIfChanged("max_streams", limits.Streams, defaults.Streams)
+	d.addIfChanged("max_consumers", limits.Consumer, defaults.Consumer)
+	d.addIfChanged("max_ack_pending", limits.MaxAckPending, defaults.MaxAckPending)
+	d.addIfChanged("max_memory_stream_bytes", limits.MemoryMaxStreamBytes, defaults.MemoryMaxStreamBytes)
+	d.addIfChanged("max_disk_stream_bytes", limits.DiskMaxStreamBytes, defaults.DiskMaxStreamBytes)
+	if limits.MaxBytesRequired {
+		d.add("max_bytes_required", true)
+	}
+
+	d.unmappedIf(len(c.Exports) > 0, "exports")
+	d.unmappedIf(len(c.Imports) > 0, "imports")
+	d.unmappedIf(len(c.Revocations) > 0, "revocations")
+	d.unmappedIf(len(c.Mappings) > 0, "mappings")
+	d.unmappedIf(len(c.Limits.JetStreamTieredLimits) > 0, "tiered_limits")
+	d.unmappedIf(c.Authorization.IsEnabled(), "authorization")
+	d.unmappedIf(c.Trace != nil, "trace")
+	d.unmappedIf(len(c.Tags) > 0, "tags")
+	d.unmappedIf(c.Description != "" || c.InfoURL != "", "description")
+}
+
+func (d *describedClaims) describeUser(c *jwt.UserClaims) {
+	if c.IssuerAccount != "" {
+		d.add("issuer_account", c.IssuerAccount)
+	}
+	d.describePermissions(c.Permissions)
+	if c.BearerToken {
+		d.add("bearer", true)
+	}
+	if len(c.Tags) > 0 {
+		d.add("tag", []string(c.Tags))
+	}
+	if len(c.Src) > 0 {
+		d.add("source_network", []string(c.Src))
+	}
+
+	defaults := jwt.NewUserClaims(c.Subject).Limits
+	limits := c.Limits.NatsLimits
+	d.addIfChanged("max_subscriptions", limits.Subs, defaults.Subs)
+	d.addIfChanged("max_data", limits.Data, defaults.Data)
+	d.addIfChanged("max_payload", limits.Payload, defaults.Payload)
+	if len(c.AllowedConnectionTypes) > 0 {
+		d.add("allowed_connection_types", []string(c.AllowedConnectionTypes))
+	}
+
+	d.unmappedIf(len(c.Times) > 0, "times")
+	d.unmappedIf(c.Locale != "", "times_location")
+	d.unmappedIf(c.ProxyRequired, "proxy_required")
+}
+
+// describePermissions maps permissions onto the flat permission attributes
+// shared by nsc_account and nsc_user.
+func (d *describedClaims) describePermissions(p jwt.Permissions) {
+	for _, perm := range []struct {
+		name     string
+		subjects jwt.StringList
+	}{
+		{"allow_pub", p.Pub.Allow},
+		{"allow_sub", p.Sub.Allow},
+		{"deny_pub", p.Pub.Deny},
+		{"deny_sub", p.Sub.Deny},
+	} {
+		if len(perm.subjects) > 0 {
+			d.add(perm.name, []string(perm.subjects))
+		}
+	}
+	if p.Resp != nil {
+		d.add("allow_pub_response", int64(p.Resp.MaxMsgs))
+		if p.Resp.Expires != 0 {
+			d.add("response_ttl", p.Resp.Expires.String())
+		}
+	}
+}
+
+func (d *describedClaims) add(name string, value any) {
+	d.Attributes = append(d.Attributes, describedAttribute{Name: name, Value: value})
+}
+
+func (d *describedClaims) addIfChanged(name string, value, defaultValue int64) {
+	if value != defaultValue {
+		d.add(name, value)
+	}
+}
+
+func (d *describedClaims) unmappedIf(set bool, claim string) {
+	if set {
+		d.Unmapped = append(d.Unmapped, claim)
+	}
+}
+
+// describedResourceName derives a resource name from the kind and name of the
+// entity, in the form used by nsc_store_imports.
+func describedResourceName(d describedClaims) string {
+	name := strings.Trim(resourceNameInvalidChars.ReplaceAllString(strings.ToLower(d.Name), "_"), "_")
+	if name == "" {
+		name = strings.ToLower(d.Subject[1:7])
+	}
+	return d.Kind + "_" + name
+}
+
+// renderDescribedResource renders the resource block of the described entity,
+// aligned like terraform fmt does.
+func renderDescribedResource(d describedClaims, resourceName string) string {
+	width := 0
+	for _, a := range d.Attributes {
+		width = max(width, len(a.Name))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "resource \"nsc_%s\" %q {\n", d.Kind, resourceName)
+	fmt.Fprintf(&b, "  # issuer_seed = seed of %s\n", d.Issuer)
+	for _, a := range d.Attributes {
+		fmt.Fprintf(&b, "  %-*s = %s\n", width, a.Name, hclValue(a.Value))
+	}
+	if len(d.Unmapped) > 0 {
+		fmt.Fprintf(&b, "\n  # Not mapped: %s\n", strings.Join(d.Unmapped, ", "))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// hclValue renders a value as an HCL literal. Template sequences in strings
+// are escaped.
+func hclValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		s := strconv.Quote(v)
+		s = strings.ReplaceAll(s, "${", "$${")
+		return strings.ReplaceAll(s, "%{", "%%{")
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		values := make([]string, len(v))
+		for i, s := range v {
+			values[i] = hclValue(s)
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	}
+	panic(fmt.Sprintf("unexpected attribute value %T", value))
+}
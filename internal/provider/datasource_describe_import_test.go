@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccDescribeImportDataSource(t *testing.T) {
+	describeJSON, account := testDescribeAccountJSON(t)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "nsc_describe_import" "test" {
+  describe_json = %q
+}
+`, describeJSON),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_describe_import.test", "id", account),
+					resource.TestCheckResourceAttr("data.nsc_describe_import.test", "kind", "account"),
+					resource.TestCheckResourceAttr("data.nsc_describe_import.test", "name", "App Account"),
+					resource.TestCheckResourceAttr("data.nsc_describe_import.test", "resource_name", "account_app_account"),
+					resource.TestCheckResourceAttr("data.nsc_describe_import.test", "unmapped.#", "1"),
+					resource.TestCheckResourceAttr("data.nsc_describe_import.test", "unmapped.0", "exports"),
+					resource.TestCheckResourceAttrWith("data.nsc_describe_import.test", "attributes_json", func(value string) error {
+						var attributes map[string]any
+						if err := json.Unmarshal([]byte(value), &attributes); err != nil {
+							return err
+						}
+						if attributes["max_connections"] != float64(100) || attributes["subject"] != account {
+							return fmt.Errorf("unexpected attributes: %s", value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+// testDescribeAccountJSON returns the output of nsc describe account --json for
+// an account with a limit, default permissions and an export.
+func testDescribeAccountJSON(t *testing.T) (string, string) {
+	t.Helper()
+	operatorKP, _ := nkeys.CreateOperator()
+	accountKP, _ := nkeys.CreateAccount()
+	account, _ := accountKP.PublicKey()
+
+	claims := jwt.NewAccountClaims(account)
+	claims.Name = "App Account"
+	claims.Limits.Conn = 100
+	claims.DefaultPermissions.Pub.Allow.Add("app.>")
+	claims.DefaultPermissions.Resp = &jwt.ResponsePermission{MaxMsgs: 1, Expires: time.Minute}
+	claims.Exports.Add(&jwt.Export{Subject: "app.events.>", Type: jwt.Stream})
+	if _, err := claims.Encode(operatorKP); err != nil {
+		t.Fatal(err)
+	}
+
+	describeJSON, err := json.MarshalIndent(claims, "", " ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(describeJSON), account
+}
+
+func TestDecodeDescribeJSON(t *testing.T) {
+	describeJSON, account := testDescribeAccountJSON(t)
+	claims, err := decodeDescribeJSON(describeJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ac, ok := claims.(*jwt.AccountClaims); !ok || ac.Subject != account || ac.Limits.Conn != 100 {
+		t.Errorf("unexpected claims: %#v", claims)
+	}
+
+	operatorKP, _ := nkeys.CreateOperator()
+	operator, _ := operatorKP.PublicKey()
+	token, err := jwt.NewOperatorClaims(operator).Encode(operatorKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err = decodeDescribeJSON(token + "\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.ClaimType() != jwt.OperatorClaim {
+		t.Errorf("expected operator claims, got %s", claims.ClaimType())
+	}
+
+	for _, s := range []string{
+		"not a jwt",
+		`{"sub": "ABC"}`,
+		`{"sub": "ABC", "nats": {"type": "activation"}}`,
+		`{"nats": {"type": "account"}}`,
+	} {
+		if _, err := decodeDescribeJSON(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestDecodeDescribeJSON_invalidSubject(t *testing.T) {
+	userKP, _ := nkeys.CreateUser()
+	user, _ := userKP.PublicKey()
+
+	for _, s := range []string{
+		`{"sub": "AB", "nats": {"type": "account"}}`,
+		`{"sub": "!!!", "nats": {"type": "operator"}}`,
+		fmt.Sprintf(`{"sub": %q, "nats": {"type": "account"}}`, user),
+		fmt.Sprintf(`{"sub": %q, "nats": {"type": "user"}}`, user[:len(user)-1]+"A"),
+	} {
+		_, err := decodeDescribeJSON(s)
+		if err == nil || !strings.Contains(err.Error(), "not a valid") {
+			t.Errorf("%s: expected invalid subject error, got %v", s, err)
+		}
+	}
+
+	// jwt.Decode does not check the subject, so JWTs are checked as well.
+	// Encode refuses the subject, so the JWT is signed by hand.
+	operatorKP, _ := nkeys.CreateOperator()
+	operator, _ := operatorKP.PublicKey()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ed25519-nkey"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(
+		`{"jti":"ID","iat":1,"iss":%q,"sub":"AB","nats":{"type":"account","version":2}}`, operator)))
+	sig, err := operatorKP.Sign([]byte(header + "." + payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(sig)
+	if _, err := decodeDescribeJSON(token); err == nil || !strings.Contains(err.Error(), "not a valid") {
+		t.Errorf("expected invalid subject error for JWT, got %v", err)
+	}
+}
+
+func TestDescribeClaims_account(t *testing.T) {
+	describeJSON, _ := testDescribeAccountJSON(t)
+	claims, err := decodeDescribeJSON(describeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ac := claims.(*jwt.AccountClaims)
+	signingKP, _ := nkeys.CreateAccount()
+	signingKey, _ := signingKP.PublicKey()
+	scopedKP, _ := nkeys.CreateAccount()
+	scopedKey, _ := scopedKP.PublicKey()
+	scope := jwt.NewUserScope()
+	scope.Key = scopedKey
+	ac.SigningKeys = jwt.SigningKeys{}
+	ac.SigningKeys.Add(signingKey)
+	ac.SigningKeys.AddScopedSigner(scope)
+	ac.Limits.WildcardExports = false
+	ac.Expires = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	described := describeClaims(ac)
+	if described.Kind != "account" {
+		t.Errorf("expected kind account, got %s", described.Kind)
+	}
+	var names []string
+	for _, a := range described.Attributes {
+		names = append(names, a.Name)
+	}
+	want := "name subject signing_keys allow_pub allow_pub_response response_ttl max_connections allow_wildcard_exports expires_at"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("expected attributes %q, got %q", want, got)
+	}
+	if got := strings.Join(described.Unmapped, " "); got != "signing_keys."+scopedKey+" exports" {
+		t.Errorf("unexpected unmapped claims: %q", got)
+	}
+}
+
+func TestDescribeClaims_user(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	account, _ := accountKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	user, _ := userKP.PublicKey()
+
+	claims := jwt.NewUserClaims(user)
+	claims.Name = "alice"
+	claims.IssuerAccount = account
+	claims.Sub.Deny.Add("secret.>")
+	claims.BearerToken = true
+	claims.Limits.NatsLimits.Payload = 1024
+	claims.Times = []jwt.TimeRange{{Start: "08:00:00", End: "18:00:00"}}
+
+	described := describeClaims(claims)
+	attributes := make(map[string]any)
+	for _, a := range described.Attributes {
+		attributes[a.Name] = a.Value
+	}
+	if attributes["issuer_account"] != account || attributes["bearer"] != true || attributes["max_payload"] != int64(1024) {
+		t.Errorf("unexpected attributes: %v", attributes)
+	}
+	if _, ok := attributes["max_data"]; ok {
+		t.Errorf("expected the default max_data to be omitted: %v", attributes)
+	}
+	if len(described.Unmapped) != 1 || described.Unmapped[0] != "times" {
+		t.Errorf("unexpected unmapped claims: %v", described.Unmapped)
+	}
+}
+
+func TestRenderDescribedResource(t *testing.T) {
+	described := describedClaims{
+		Kind:    "operator",
+		Name:    "Main ${env}",
+		Subject: "OABC",
+		Issuer:  "OABC",
+		Attributes: []describedAttribute{
+			{"name", "Main ${env}"},
+			{"subject", "OABC"},
+			{"signing_keys", []string{"OKEY1", "OKEY2"}},
+		},
+		Unmapped: []string{"account_server_url"},
+	}
+
+	if got := describedResourceName(described); got != "operator_main_env" {
+		t.Errorf("unexpected resource name %q", got)
+	}
+
+	want := `resource "nsc_operator" "main" {
+  # issuer_seed = seed of OABC
+  name         = "Main $${env}"
+  subject      = "OABC"
+  signing_keys = ["OKEY1", "OKEY2"]
+
+  # Not mapped: account_server_url
+}
+`
+	if got := renderDescribedResource(described, "main"); got != want {
+		t.Errorf("unexpected resource block:\n%s", got)
+	}
+}
+
+func TestHCLValue(t *testing.T) {
+	for value, want := range map[any]string{
+		"a\"b":      `"a\"b"`,
+		"%{if}":     `"%%{if}"`,
+		int64(-1):   "-1",
+		true:        "true",
+		"line\nend": `"line\nend"`,
+	} {
+		if got := hclValue(value); got != want {
+			t.Errorf("%v: expected %s, got %s", value, want, got)
+		}
+	}
+}
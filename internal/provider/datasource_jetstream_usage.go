@@ -0,0 +1,370 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ datasource.DataSource = &JetStreamUsageDataSource{}
+var _ datasource.DataSourceWithConfigure = &JetStreamUsageDataSource{}
+
+const jetStreamUsageDefaultTimeout = 10 * time.Second
+
+// jetStreamAccountInfoSubject is the system service that reports the
+// JetStream usage of an account.
+const jetStreamAccountInfoSubject = "$SYS.REQ.ACCOUNT.%s.JSZ"
+
+func NewJetStreamUsageDataSource() datasource.DataSource {
+	return &JetStreamUsageDataSource{}
+}
+
+type JetStreamUsageDataSource struct {
+	keys *keypairCache
+	nats *NATSConnectionModel
+}
+
+type JetStreamUsageDataSourceModel struct {
+	ID  types.String `tfsdk:"id"`
+	URL types.String `tfsdk:"url"`
+	NATSConnectionModel
+	Accounts  types.Map  `tfsdk:"accounts"`
+	Usage     types.Map  `tfsdk:"usage"`
+	OverLimit types.List `tfsdk:"over_limit"`
+}
+
+type JetStreamUsageModel struct {
+	PublicKey  types.String `tfsdk:"public_key"`
+	Memory     types.Int64  `tfsdk:"memory"`
+	Storage    types.Int64  `tfsdk:"storage"`
+	Streams    types.Int64  `tfsdk:"streams"`
+	Consumers  types.Int64  `tfsdk:"consumers"`
+	Violations types.List   `tfsdk:"violations"`
+}
+
+var jetStreamUsageAttrTypes = map[string]attr.Type{
+	"public_key": types.StringType,
+	"memory":     types.Int64Type,
+	"storage":    types.Int64Type,
+	"streams":    types.Int64Type,
+	"consumers":  types.Int64Type,
+	"violations": types.ListType{ElemType: types.StringType},
+}
+
+func (d *JetStreamUsageDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_jetstream_usage"
+}
+
+func (d *JetStreamUsageDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Connects to a NATS server with system account credentials, fetches the JetStream usage of accounts and compares it against the JetStream limits of their account claims. Pass the claims of a planned change to find accounts that would be over their limits once the limits are reduced, and assert on `over_limit` in a `check` block or a precondition.",
+
+		Attributes: natsConnectionAttributes(map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Server URL (same as url)",
+			},
+			"url": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Server URL, e.g. `nats://nats.example.com:4222`. The `tls` scheme forces TLS, which is also used whenever the server requires it. The `ws` and `wss` schemes connect over WebSocket, e.g. `wss://nats.example.com/ws`, through the proxy of the `HTTPS_PROXY` or `HTTP_PROXY` environment variable. As with `nats`, setting TLS options forces TLS.",
+			},
+			"creds": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Credentials file content of a system account user, e.g. from `nsc_user.sys.creds`. The user must be allowed to publish to `$SYS.REQ.ACCOUNT.*.JSZ`. Defaults to the provider `nats` block. One of `creds`, `nkey_seed` or `user` must be set here or there.",
+				Validators:          natsConnectionValidators()["creds"],
+			},
+			"timeout": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Time limit for connecting and fetching the usage of all accounts. Defaults to `10s`, or the `timeout` of the provider `nats` block.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"accounts": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				MarkdownDescription: "Map of account names to account JWTs, e.g. `nsc_account.app.jwt`, or to unsigned account claims, e.g. `data.nsc_account_claims.app.claims_json`. Unsigned claims are known during plan, so they can describe limits that have not been applied yet. Names are only used to key the outputs.",
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+				},
+			},
+			"usage": schema.MapNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Map of account names to their JetStream usage. Accounts the server reports no JetStream usage for have zero usage.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"public_key": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Account public key",
+						},
+						"memory": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Bytes of memory storage in use, compared against `max_memory_storage`",
+						},
+						"storage": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Bytes of disk storage in use, compared against `max_disk_storage`",
+						},
+						"streams": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of streams, compared against `max_streams`",
+						},
+						"consumers": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "Number of consumers of the stream with the most consumers, compared against `max_consumers`, which the server enforces per stream",
+						},
+						"violations": schema.ListAttribute{
+							ElementType:         types.StringType,
+							Computed:            true,
+							MarkdownDescription: "Limits the usage exceeds, e.g. `max_disk_storage: 2147483648 bytes in use, limit is 1073741824`. Empty when the account is within its limits.",
+						},
+					},
+				},
+			},
+			"over_limit": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Names of the accounts whose usage exceeds any of their JetStream limits, sorted",
+			},
+		}),
+	}
+}
+
+func (d *JetStreamUsageDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.keys = providerData.Keys
+	d.nats = providerData.NATS
+}
+
+func (d *JetStreamUsageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data JetStreamUsageDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts := make(map[string]types.String, len(data.Accounts.Elements()))
+	resp.Diagnostics.Append(data.Accounts.ElementsAs(ctx, &accounts, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	claims := make(map[string]*jwt.AccountClaims, len(names))
+	for _, name := range names {
+		attrPath := path.Root("accounts").AtMapKey(name)
+		if accounts[name].IsNull() {
+			resp.Diagnostics.AddAttributeError(attrPath, "Missing account claims", "The account JWT is null. For accounts with jwt_output = \"sensitive_only\", pass jwt_sensitive instead.")
+			continue
+		}
+		accountClaims, err := decodeAccountClaimsOrJSON(accounts[name].ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(attrPath, "Invalid account claims", err.Error())
+			continue
+		}
+		claims[name] = accountClaims
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, timeout, diags := data.natsConnectOptions(d.nats, d.keys, jetStreamUsageDefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	session, err := natsDial(requestCtx, data.URL.ValueString(), opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to connect to NATS", err.Error())
+		return
+	}
+	defer session.Close()
+
+	usage := make(map[string]attr.Value, len(names))
+	overLimit := make([]string, 0)
+	for _, name := range names {
+		subject := claims[name].Subject
+		accountUsage, err := fetchJetStreamUsage(session, subject)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to fetch JetStream usage",
+				fmt.Sprintf("Failed to fetch the JetStream usage of account %q (%s): %s", name, subject, err),
+			)
+			return
+		}
+
+		violations := jetStreamLimitViolations(claims[name].Limits.JetStreamLimits, accountUsage)
+		if len(violations) > 0 {
+			overLimit = append(overLimit, name)
+		}
+		violationsValue, diags := types.ListValueFrom(ctx, types.StringType, violations)
+		resp.Diagnostics.Append(diags...)
+
+		value, diags := types.ObjectValueFrom(ctx, jetStreamUsageAttrTypes, JetStreamUsageModel{
+			PublicKey:  types.StringValue(subject),
+			Memory:     types.Int64Value(accountUsage.Memory),
+			Storage:    types.Int64Value(accountUsage.Storage),
+			Streams:    types.Int64Value(accountUsage.Streams),
+			Consumers:  types.Int64Value(accountUsage.Consumers),
+			Violations: violationsValue,
+		})
+		resp.Diagnostics.Append(diags...)
+		usage[name] = value
+	}
+
+	data.ID = data.URL
+	usageValue, diags := types.MapValue(types.ObjectType{AttrTypes: jetStreamUsageAttrTypes}, usage)
+	resp.Diagnostics.Append(diags...)
+	data.Usage = usageValue
+	overLimitValue, diags := types.ListValueFrom(ctx, types.StringType, overLimit)
+	resp.Diagnostics.Append(diags...)
+	data.OverLimit = overLimitValue
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// decodeAccountClaimsOrJSON decodes an account JWT, or unsigned account
+// claims in JSON as rendered by the nsc_account_claims data source.
+func decodeAccountClaimsOrJSON(value string) (*jwt.AccountClaims, error) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		claims, err := jwt.DecodeAccountClaims(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode account JWT: %w", err)
+		}
+		return claims, nil
+	}
+
+	// Omitted JetStream limits are 0, as in a decoded JWT
+	claims := &jwt.AccountClaims{}
+	if err := json.Unmarshal([]byte(value), claims); err != nil {
+		return nil, fmt.Errorf("failed to decode account claims: %w", err)
+	}
+	// Unsigned claims have no type, it is set when they are encoded
+	if claims.Type != "" && claims.Type != jwt.AccountClaim {
+		return nil, fmt.Errorf("expected account claims, got %s claims", claims.Type)
+	}
+	if !nkeys.IsValidPublicAccountKey(claims.Subject) {
+		return nil, fmt.Errorf("subject of the account claims must be an account public key, got %q", claims.Subject)
+	}
+	return claims, nil
+}
+
+// jetStreamUsage is the JetStream usage of an account.
+type jetStreamUsage struct {
+	Memory  int64
+	Storage int64
+	Streams int64
+	// Consumers is the number of consumers of the stream with the most
+	// consumers.
+	Consumers int64
+}
+
+// fetchJetStreamUsage asks the servers for the JetStream usage of an
+// account. Accounts nothing answers for, e.g. because JetStream is not
+// enabled for them, have zero usage.
+func fetchJetStreamUsage(session *natsSession, account string) (jetStreamUsage, error) {
+	var usage jetStreamUsage
+
+	payload, err := session.request(fmt.Sprintf(jetStreamAccountInfoSubject, account), []byte(`{"streams":true}`))
+	if err != nil {
+		return usage, err
+	}
+	if payload == nil {
+		return usage, nil
+	}
+
+	var response struct {
+		Data *struct {
+			Memory  int64 `json:"memory"`
+			Storage int64 `json:"storage"`
+			Streams []struct {
+				State struct {
+					Consumers int64 `json:"consumer_count"`
+				} `json:"state"`
+			} `json:"stream_detail"`
+		} `json:"data"`
+		Error *struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return usage, fmt.Errorf("failed to decode JetStream account info: %w", err)
+	}
+	if response.Error != nil {
+		return usage, fmt.Errorf("server error %d: %s", response.Error.Code, response.Error.Description)
+	}
+	if response.Data == nil {
+		return usage, nil
+	}
+
+	usage.Memory = response.Data.Memory
+	usage.Storage = response.Data.Storage
+	usage.Streams = int64(len(response.Data.Streams))
+	for _, stream := range response.Data.Streams {
+		usage.Consumers = max(usage.Consumers, stream.State.Consumers)
+	}
+	return usage, nil
+}
+
+// jetStreamLimitViolations returns a description of every limit the usage
+// exceeds. Negative limits are unlimited. A storage limit of 0 disables that
+// storage, while stream and consumer limits of 0 are not enforced by the
+// server.
+func jetStreamLimitViolations(limits jwt.JetStreamLimits, usage jetStreamUsage) []string {
+	violations := make([]string, 0)
+	if limits.MemoryStorage >= 0 && usage.Memory > limits.MemoryStorage {
+		violations = append(violations, fmt.Sprintf("max_memory_storage: %d bytes in use, limit is %d", usage.Memory, limits.MemoryStorage))
+	}
+	if limits.DiskStorage >= 0 && usage.Storage > limits.DiskStorage {
+		violations = append(violations, fmt.Sprintf("max_disk_storage: %d bytes in use, limit is %d", usage.Storage, limits.DiskStorage))
+	}
+	if limits.Streams > 0 && usage.Streams > limits.Streams {
+		violations = append(violations, fmt.Sprintf("max_streams: %d streams, limit is %d", usage.Streams, limits.Streams))
+	}
+	if limits.Consumer > 0 && usage.Consumers > limits.Consumer {
+		violations = append(violations, fmt.Sprintf("max_consumers: %d consumers on one stream, limit is %d", usage.Consumers, limits.Consumer))
+	}
+	return violations
+}
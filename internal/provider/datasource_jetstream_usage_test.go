@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccJetStreamUsageDataSource(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	account, _ := accountKP.PublicKey()
+
+	server := newFakeNATSServer(t)
+	server.jsz = map[string]string{
+		account: `{"name":"App","id":"` + account + `","memory":0,"storage":2147483648,"stream_detail":[{"name":"ORDERS","state":{"consumer_count":2}},{"name":"EVENTS","state":{"consumer_count":5}}]}`,
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccJetStreamUsageDataSourceConfig(server.URL(), account, "4GiB"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "usage.app.public_key", account),
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "usage.app.storage", "2147483648"),
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "usage.app.streams", "2"),
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "usage.app.consumers", "5"),
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "usage.app.violations.#", "0"),
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "over_limit.#", "0"),
+				),
+			},
+			{
+				Config: testAccJetStreamUsageDataSourceConfig(server.URL(), account, "1GiB"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "usage.app.violations.#", "1"),
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "usage.app.violations.0", "max_disk_storage: 2147483648 bytes in use, limit is 1073741824"),
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "over_limit.#", "1"),
+					resource.TestCheckResourceAttr("data.nsc_jetstream_usage.test", "over_limit.0", "app"),
+				),
+			},
+		},
+	})
+}
+
+func testAccJetStreamUsageDataSourceConfig(url, account, maxDiskStorage string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "system" {
+  type = "account"
+}
+
+resource "nsc_nkey" "sys_user" {
+  type = "user"
+}
+
+resource "nsc_user" "sys" {
+  name        = "sys"
+  subject     = nsc_nkey.sys_user.public_key
+  issuer_seed = nsc_nkey.system.seed
+  seed        = nsc_nkey.sys_user.seed
+}
+
+data "nsc_account_claims" "app" {
+  name             = "App"
+  subject          = %[2]q
+  max_disk_storage = %[3]q
+  max_streams      = 10
+}
+
+data "nsc_jetstream_usage" "test" {
+  url   = %[1]q
+  creds = nsc_user.sys.creds
+  accounts = {
+    app = data.nsc_account_claims.app.claims_json
+  }
+}
+`, url, account, maxDiskStorage)
+}
+
+func TestDecodeAccountClaimsOrJSON(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	accountKP, _ := nkeys.CreateAccount()
+	account, _ := accountKP.PublicKey()
+
+	claims := jwt.NewAccountClaims(account)
+	claims.Limits.DiskStorage = 1024
+	token, err := claims.Encode(operatorKP)
+	if err != nil {
+		t.Fatalf("failed to encode account JWT: %v", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("failed to marshal account claims: %v", err)
+	}
+
+	for _, value := range []string{token, string(claimsJSON)} {
+		decoded, err := decodeAccountClaimsOrJSON(value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if decoded.Subject != account || decoded.Limits.DiskStorage != 1024 || decoded.Limits.MemoryStorage != 0 {
+			t.Errorf("unexpected claims: %+v", decoded)
+		}
+	}
+
+	for value, want := range map[string]string{
+		"not-a-jwt": "failed to decode account JWT",
+		"{":         "failed to decode account claims",
+		`{"sub":"` + account + `","nats":{"type":"user"}}`: "expected account claims, got user claims",
+		`{"sub":"UABC","nats":{}}`:                         "must be an account public key",
+	} {
+		if _, err := decodeAccountClaimsOrJSON(value); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("decodeAccountClaimsOrJSON(%q): expected error containing %q, got %v", value, want, err)
+		}
+	}
+}
+
+func TestFetchJetStreamUsage(t *testing.T) {
+	userJWT, userKP := testUserCredentials(t)
+
+	server := newFakeNATSServer(t)
+	server.jsz = map[string]string{
+		"AUSED":    `{"memory":1024,"storage":4096,"stream_detail":[{"state":{"consumer_count":3}},{"state":{"consumer_count":1}},{"state":{}}]}`,
+		"AEMPTY":   `{}`,
+		"AINVALID": `"nope"`,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	session, err := natsDial(ctx, server.URL(), natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer session.Close()
+
+	for account, want := range map[string]jetStreamUsage{
+		"AUSED":    {Memory: 1024, Storage: 4096, Streams: 3, Consumers: 3},
+		"AEMPTY":   {},
+		"AUNKNOWN": {},
+	} {
+		usage, err := fetchJetStreamUsage(session, account)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", account, err)
+		}
+		if usage != want {
+			t.Errorf("%s: expected %+v, got %+v", account, want, usage)
+		}
+	}
+
+	if _, err := fetchJetStreamUsage(session, "AINVALID"); err == nil || !strings.Contains(err.Error(), "failed to decode") {
+		t.Errorf("expected decode error, got %v", err)
+	}
+}
+
+func TestJetStreamLimitViolations(t *testing.T) {
+	usage := jetStreamUsage{Memory: 100, Storage: 2000, Streams: 5, Consumers: 8}
+
+	tests := []struct {
+		name   string
+		limits jwt.JetStreamLimits
+		want   []string
+	}{
+		{
+			name:   "unlimited",
+			limits: jwt.JetStreamLimits{MemoryStorage: -1, DiskStorage: -1, Streams: -1, Consumer: -1},
+			want:   []string{},
+		},
+		{
+			name:   "within limits",
+			limits: jwt.JetStreamLimits{MemoryStorage: 100, DiskStorage: 2000, Streams: 5, Consumer: 8},
+			want:   []string{},
+		},
+		{
+			name:   "unenforced stream and consumer limits",
+			limits: jwt.JetStreamLimits{MemoryStorage: -1, DiskStorage: -1},
+			want:   []string{},
+		},
+		{
+			name:   "disabled memory storage",
+			limits: jwt.JetStreamLimits{MemoryStorage: 0, DiskStorage: -1},
+			want:   []string{"max_memory_storage: 100 bytes in use, limit is 0"},
+		},
+		{
+			name:   "all exceeded",
+			limits: jwt.JetStreamLimits{MemoryStorage: 50, DiskStorage: 1000, Streams: 4, Consumer: 2},
+			want: []string{
+				"max_memory_storage: 100 bytes in use, limit is 50",
+				"max_disk_storage: 2000 bytes in use, limit is 1000",
+				"max_streams: 5 streams, limit is 4",
+				"max_consumers: 8 consumers on one stream, limit is 2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := jetStreamLimitViolations(tt.limits, usage)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
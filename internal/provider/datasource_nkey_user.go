@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ datasource.DataSource = &NKeyUserDataSource{}
+
+func NewNKeyUserDataSource() datasource.DataSource {
+	return &NKeyUserDataSource{}
+}
+
+type NKeyUserDataSource struct{}
+
+// NKeyUserPermissionsModel holds the attributes of the nsc_user resource that
+// carry over to a user authenticating with its nkey.
+type NKeyUserPermissionsModel struct {
+	AllowPub               types.List           `tfsdk:"allow_pub"`
+	AllowSub               types.List           `tfsdk:"allow_sub"`
+	DenyPub                types.List           `tfsdk:"deny_pub"`
+	DenySub                types.List           `tfsdk:"deny_sub"`
+	AllowPubResponse       types.Int64          `tfsdk:"allow_pub_response"`
+	ResponseTTL            timetypes.GoDuration `tfsdk:"response_ttl"`
+	AllowedConnectionTypes types.List           `tfsdk:"allowed_connection_types"`
+}
+
+type NKeyUserDataSourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	PublicKey types.String `tfsdk:"public_key"`
+
+	NKeyUserPermissionsModel
+
+	Entry types.String `tfsdk:"entry"`
+}
+
+func (d *NKeyUserDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nkey_user"
+}
+
+func (d *NKeyUserDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	var resourceSchema resource.SchemaResponse
+	(&UserResource{}).Schema(ctx, resource.SchemaRequest{}, &resourceSchema)
+
+	attributes, _ := claimsDataSourceSchema(resourceSchema.Schema, NKeyUserPermissionsModel{})
+
+	attributes["id"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "User public key (same as public_key)",
+	}
+	attributes["public_key"] = schema.StringAttribute{
+		Required:            true,
+		MarkdownDescription: "User public key the client authenticates with, e.g. `nsc_nkey.user.public_key`",
+		Validators: []validator.String{
+			stringvalidator.RegexMatches(
+				regexp.MustCompile(`^U[A-Z2-7]{55}$`),
+				"must be a valid user public key starting with 'U'",
+			),
+		},
+	}
+	attributes["entry"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "The rendered entry of the `users` list, e.g. `{nkey: U..., permissions: {publish: {allow: [\"orders.>\"]}}}`",
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders a user entry of the `authorization { users: [...] }` block of a nats-server configuration, for servers that authenticate clients by nkey without JWTs. " +
+			"Permissions and allowed connection types take the same attributes as the `nsc_user` resource. Users without permissions get the `default_permissions` of the `authorization` block.",
+		Attributes: attributes,
+	}
+}
+
+func (d *NKeyUserDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data NKeyUserDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The claims are only built to convert the permissions the way nsc_user does
+	claims, diags := buildUserClaims(ctx, &UserClaimsModel{
+		Subject:                data.PublicKey,
+		AllowPub:               data.AllowPub,
+		AllowSub:               data.AllowSub,
+		DenyPub:                data.DenyPub,
+		DenySub:                data.DenySub,
+		AllowPubResponse:       data.AllowPubResponse,
+		ResponseTTL:            data.ResponseTTL,
+		AllowedConnectionTypes: data.AllowedConnectionTypes,
+	})
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.PublicKey
+	data.Entry = types.StringValue(staticNKeyUser(claims.Subject, claims.Permissions, claims.AllowedConnectionTypes))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestStaticNKeyUser(t *testing.T) {
+	publicKey := "UABC"
+
+	var permissions jwt.Permissions
+	if got, want := staticNKeyUser(publicKey, permissions, nil), "{nkey: UABC}"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	permissions.Pub.Allow.Add("orders.>")
+	permissions.Sub.Deny.Add("admin.>")
+	permissions.Resp = &jwt.ResponsePermission{MaxMsgs: -1, Expires: time.Minute}
+	got := staticNKeyUser(publicKey, permissions, jwt.StringList{jwt.ConnectionTypeStandard, jwt.ConnectionTypeWebsocket})
+	want := `{nkey: UABC, permissions: {publish: {allow: ["orders.>"]}, subscribe: {deny: ["admin.>"]}, allow_responses: {max: -1, expires: "1m0s"}}, allowed_connection_types: ["STANDARD", "WEBSOCKET"]}`
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestAccNKeyUserDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "service" {
+  type = "user"
+}
+
+data "nsc_nkey_user" "test" {
+  public_key               = nsc_nkey.service.public_key
+  allow_pub                = ["orders.>"]
+  deny_sub                 = ["admin.>"]
+  allow_pub_response       = 1
+  response_ttl             = "5s"
+  allowed_connection_types = ["STANDARD"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nsc_nkey_user.test", "id", "nsc_nkey.service", "public_key"),
+					resource.TestMatchResourceAttr("data.nsc_nkey_user.test", "entry", regexp.MustCompile(`^\{nkey: U[A-Z0-9]{55}, permissions: \{publish: \{allow: \["orders\.>"\]\}, subscribe: \{deny: \["admin\.>"\]\}, allow_responses: \{max: 1, expires: "5s"\}\}, allowed_connection_types: \["STANDARD"\]\}$`)),
+				),
+			},
+			{
+				Config: `
+data "nsc_nkey_user" "test" {
+  public_key = "ABC"
+}
+`,
+				ExpectError: regexp.MustCompile("must be a valid user public key"),
+			},
+		},
+	})
+}
@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ datasource.DataSource = &PermissionSetDataSource{}
+
+func NewPermissionSetDataSource() datasource.DataSource {
+	return &PermissionSetDataSource{}
+}
+
+type PermissionSetDataSource struct{}
+
+type PermissionSetDataSourceModel struct {
+	ID             types.String              `tfsdk:"id"`
+	Fragments      []PermissionFragmentModel `tfsdk:"fragments"`
+	AllowConflicts types.Bool                `tfsdk:"allow_conflicts"`
+	AllowPub       types.List                `tfsdk:"allow_pub"`
+	AllowSub       types.List                `tfsdk:"allow_sub"`
+	DenyPub        types.List                `tfsdk:"deny_pub"`
+	DenySub        types.List                `tfsdk:"deny_sub"`
+	Conflicts      types.List                `tfsdk:"conflicts"`
+}
+
+type PermissionFragmentModel struct {
+	Name     types.String `tfsdk:"name"`
+	AllowPub types.List   `tfsdk:"allow_pub"`
+	AllowSub types.List   `tfsdk:"allow_sub"`
+	DenyPub  types.List   `tfsdk:"deny_pub"`
+	DenySub  types.List   `tfsdk:"deny_sub"`
+}
+
+func (d *PermissionSetDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_permission_set"
+}
+
+func (d *PermissionSetDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	fragmentList := func(description string) schema.ListAttribute {
+		return schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: description,
+		}
+	}
+	mergedList := func(description string) schema.ListAttribute {
+		return schema.ListAttribute{
+			ElementType:         types.StringType,
+			Computed:            true,
+			MarkdownDescription: description + ", sorted and without duplicates or entries covered by a wildcard entry. Null when no fragment sets any.",
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Merges permission fragments, e.g. from different modules, into the `allow_pub`, `allow_sub`, `deny_pub` and `deny_sub` lists of `nsc_user`, `nsc_role` or the default permissions of `nsc_account`. " +
+			"Entries are normalized and deduplicated. An allowed entry that is entirely covered by a denied entry is a conflict, as the deny takes precedence in nats-server; conflicts fail the read unless `allow_conflicts` is set.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hash of the merged permissions",
+			},
+			"fragments": schema.ListNestedAttribute{
+				Required:            true,
+				MarkdownDescription: "Permission fragments to merge",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Name of the fragment, used in conflict and error messages. Defaults to `fragment <index>`.",
+						},
+						"allow_pub": fragmentList("Subjects allowed for publishing"),
+						"allow_sub": fragmentList("Subjects allowed for subscribing, optionally followed by a space and a queue group name"),
+						"deny_pub":  fragmentList("Subjects denied for publishing"),
+						"deny_sub":  fragmentList("Subjects denied for subscribing, optionally followed by a space and a queue group name"),
+					},
+				},
+			},
+			"allow_conflicts": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Report conflicts in `conflicts` instead of failing the read. Defaults to `false`.",
+			},
+			"allow_pub": mergedList("Subjects allowed for publishing"),
+			"allow_sub": mergedList("Subjects allowed for subscribing"),
+			"deny_pub":  mergedList("Subjects denied for publishing"),
+			"deny_sub":  mergedList("Subjects denied for subscribing"),
+			"conflicts": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Allowed entries that are entirely denied by another entry. Empty unless `allow_conflicts` is set.",
+			},
+		},
+	}
+}
+
+func (d *PermissionSetDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data PermissionSetDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	fragments := make([]permissionFragment, len(data.Fragments))
+	for i, f := range data.Fragments {
+		fragments[i].Name = fmt.Sprintf("fragment %d", i)
+		if !f.Name.IsNull() {
+			fragments[i].Name = f.Name.ValueString()
+		}
+		for _, list := range []struct {
+			name        string
+			value       types.List
+			permitQueue bool
+			dst         *jwt.StringList
+		}{
+			{"allow_pub", f.AllowPub, false, &fragments[i].Pub.Allow},
+			{"allow_sub", f.AllowSub, true, &fragments[i].Sub.Allow},
+			{"deny_pub", f.DenyPub, false, &fragments[i].Pub.Deny},
+			{"deny_sub", f.DenySub, true, &fragments[i].Sub.Deny},
+		} {
+			entries, diags := stringListValues(ctx, list.value)
+			resp.Diagnostics.Append(diags...)
+			for j, entry := range entries {
+				entry = normalizePermissionEntry(entry)
+				if err := validatePermissionEntry(entry, list.permitQueue); err != nil {
+					resp.Diagnostics.AddAttributeError(
+						path.Root("fragments").AtListIndex(i).AtName(list.name).AtListIndex(j),
+						"Invalid permission subject",
+						err.Error(),
+					)
+					continue
+				}
+				*list.dst = append(*list.dst, entry)
+			}
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	permissions, conflicts := composePermissions(fragments)
+	if len(conflicts) > 0 && !data.AllowConflicts.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("fragments"),
+			"Conflicting permissions",
+			"The following allowed entries are denied by another entry:\n\n"+strings.Join(conflicts, "\n")+"\n\nRemove the entries or set allow_conflicts.",
+		)
+		return
+	}
+
+	for _, list := range []struct {
+		entries jwt.StringList
+		dst     *types.List
+	}{
+		{permissions.Pub.Allow, &data.AllowPub},
+		{permissions.Sub.Allow, &data.AllowSub},
+		{permissions.Pub.Deny, &data.DenyPub},
+		{permissions.Sub.Deny, &data.DenySub},
+	} {
+		*list.dst = types.ListNull(types.StringType)
+		if len(list.entries) > 0 {
+			value, diags := types.ListValueFrom(ctx, types.StringType, list.entries)
+			resp.Diagnostics.Append(diags...)
+			*list.dst = value
+		}
+	}
+	conflictsValue, diags := types.ListValueFrom(ctx, types.StringType, append([]string{}, conflicts...))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Conflicts = conflictsValue
+
+	permissionsJSON, err := json.Marshal(permissions)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode permissions", err.Error())
+		return
+	}
+	data.ID = types.StringValue(fmt.Sprintf("%x", sha256.Sum256(permissionsJSON)))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// permissionFragment is a named set of permissions to merge.
+type permissionFragment struct {
+	Name string
+	Pub  jwt.Permission
+	Sub  jwt.Permission
+}
+
+// normalizePermissionEntry trims an entry and collapses the spaces between
+// subject and queue group.
+func normalizePermissionEntry(entry string) string {
+	return strings.Join(strings.Fields(entry), " ")
+}
+
+// validatePermissionEntry checks a normalized permission entry the way the
+// allow_pub/allow_sub/deny_pub/deny_sub validators do.
+func validatePermissionEntry(entry string, permitQueue bool) error {
+	subject, queue, hasQueue := strings.Cut(entry, " ")
+	if hasQueue && !permitQueue {
+		return fmt.Errorf("publish permission %q cannot contain a queue group; queue groups are only allowed on subscribe permissions", entry)
+	}
+	if err := validatePermissionSubject(subject); err != nil {
+		return err
+	}
+	if hasQueue {
+		return validateQueueName(queue)
+	}
+	return nil
+}
+
+// permissionEntryCovers reports whether every subscription or publish matched
+// by entry narrow is also matched by entry broad. Entries with templates only
+// cover themselves, as their subjects are not known until the user connects.
+func permissionEntryCovers(broad, narrow string) bool {
+	if broad == narrow {
+		return true
+	}
+	if strings.Contains(broad, "{{") || strings.Contains(narrow, "{{") {
+		return false
+	}
+	broadSubject, broadQueue, broadHasQueue := strings.Cut(broad, " ")
+	narrowSubject, narrowQueue, narrowHasQueue := strings.Cut(narrow, " ")
+	if !jwt.Subject(narrowSubject).IsContainedIn(jwt.Subject(broadSubject)) {
+		return false
+	}
+	if !broadHasQueue {
+		return true
+	}
+	return narrowHasQueue && jwt.Subject(narrowQueue).IsContainedIn(jwt.Subject(broadQueue))
+}
+
+// composePermissions merges the permissions of the fragments and returns the
+// allowed entries that a denied entry covers, naming the fragments of both.
+func composePermissions(fragments []permissionFragment) (jwt.Permissions, []string) {
+	var permissions jwt.Permissions
+	var conflicts []string
+
+	for _, direction := range []struct {
+		name   string
+		get    func(f permissionFragment) jwt.Permission
+		merged *jwt.Permission
+	}{
+		{"publish", func(f permissionFragment) jwt.Permission { return f.Pub }, &permissions.Pub},
+		{"subscribe", func(f permissionFragment) jwt.Permission { return f.Sub }, &permissions.Sub},
+	} {
+		allowOrigin := map[string]string{}
+		denyOrigin := map[string]string{}
+		for _, f := range fragments {
+			for _, entry := range direction.get(f).Allow {
+				if _, ok := allowOrigin[entry]; !ok {
+					allowOrigin[entry] = f.Name
+				}
+			}
+			for _, entry := range direction.get(f).Deny {
+				if _, ok := denyOrigin[entry]; !ok {
+					denyOrigin[entry] = f.Name
+				}
+			}
+		}
+
+		direction.merged.Allow = mergePermissionEntries(allowOrigin)
+		direction.merged.Deny = mergePermissionEntries(denyOrigin)
+
+		for _, allow := range direction.merged.Allow {
+			for _, deny := range direction.merged.Deny {
+				if permissionEntryCovers(deny, allow) {
+					conflicts = append(conflicts, fmt.Sprintf("%s %q of %s is denied by %q of %s", direction.name, allow, allowOrigin[allow], deny, denyOrigin[deny]))
+					break
+				}
+			}
+		}
+	}
+
+	return permissions, conflicts
+}
+
+// mergePermissionEntries returns the sorted entries, leaving out those
+// covered by another entry.
+func mergePermissionEntries(entries map[string]string) jwt.StringList {
+	var merged jwt.StringList
+	for entry := range entries {
+		covered := false
+		for other := range entries {
+			if other != entry && permissionEntryCovers(other, entry) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			merged = append(merged, entry)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
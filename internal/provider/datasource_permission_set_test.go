@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestPermissionEntryCovers(t *testing.T) {
+	tests := []struct {
+		broad, narrow string
+		want          bool
+	}{
+		{"orders.>", "orders.>", true},
+		{"orders.>", "orders.created", true},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.created.eu", false},
+		{"orders.created", "orders.>", false},
+		{"orders.>", "orders.created workers", true},
+		{"orders.> workers", "orders.created", false},
+		{"orders.> workers", "orders.created workers", true},
+		{"orders.> workers", "orders.created audit", false},
+		{"_INBOX.{{name()}}.>", "_INBOX.{{name()}}.x", false},
+	}
+
+	for _, tt := range tests {
+		if got := permissionEntryCovers(tt.broad, tt.narrow); got != tt.want {
+			t.Errorf("permissionEntryCovers(%q, %q) = %t, want %t", tt.broad, tt.narrow, got, tt.want)
+		}
+	}
+}
+
+func TestComposePermissions(t *testing.T) {
+	fragments := []permissionFragment{
+		{
+			Name: "orders",
+			Pub:  jwt.Permission{Allow: jwt.StringList{"orders.created", "orders.>"}},
+			Sub:  jwt.Permission{Allow: jwt.StringList{"_INBOX.>"}},
+		},
+		{
+			Name: "audit",
+			Pub:  jwt.Permission{Allow: jwt.StringList{"audit.>", "orders.>"}, Deny: jwt.StringList{"audit.secret"}},
+			Sub:  jwt.Permission{Deny: jwt.StringList{"_INBOX.admin"}},
+		},
+	}
+
+	permissions, conflicts := composePermissions(fragments)
+	if want := (jwt.StringList{"audit.>", "orders.>"}); !reflect.DeepEqual(permissions.Pub.Allow, want) {
+		t.Errorf("expected allow_pub %v, got %v", want, permissions.Pub.Allow)
+	}
+	if want := (jwt.StringList{"audit.secret"}); !reflect.DeepEqual(permissions.Pub.Deny, want) {
+		t.Errorf("expected deny_pub %v, got %v", want, permissions.Pub.Deny)
+	}
+	if want := (jwt.StringList{"_INBOX.>"}); !reflect.DeepEqual(permissions.Sub.Allow, want) {
+		t.Errorf("expected allow_sub %v, got %v", want, permissions.Sub.Allow)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %v", conflicts)
+	}
+
+	fragments = append(fragments, permissionFragment{
+		Name: "lockdown",
+		Pub:  jwt.Permission{Deny: jwt.StringList{"orders.>"}},
+	})
+	_, conflicts = composePermissions(fragments)
+	want := []string{`publish "orders.>" of orders is denied by "orders.>" of lockdown`}
+	if !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("expected conflicts %v, got %v", want, conflicts)
+	}
+}
+
+func TestValidatePermissionEntry(t *testing.T) {
+	if err := validatePermissionEntry(normalizePermissionEntry("  orders.>   workers "), true); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if err := validatePermissionEntry("orders.> workers", false); err == nil {
+		t.Error("expected error for queue group on publish permission")
+	}
+	if err := validatePermissionEntry("orders..created", false); err == nil {
+		t.Error("expected error for invalid subject")
+	}
+}
+
+func TestAccPermissionSetDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "nsc_permission_set" "test" {
+  fragments = [
+    {
+      name      = "orders"
+      allow_pub = ["orders.created", "orders.>"]
+      allow_sub = ["_INBOX.>"]
+    },
+    {
+      name      = "audit"
+      allow_pub = [" audit.> ", "orders.>"]
+      deny_pub  = ["audit.secret"]
+    },
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_permission_set.test", "allow_pub.#", "2"),
+					resource.TestCheckResourceAttr("data.nsc_permission_set.test", "allow_pub.0", "audit.>"),
+					resource.TestCheckResourceAttr("data.nsc_permission_set.test", "allow_pub.1", "orders.>"),
+					resource.TestCheckResourceAttr("data.nsc_permission_set.test", "deny_pub.0", "audit.secret"),
+					resource.TestCheckResourceAttr("data.nsc_permission_set.test", "allow_sub.0", "_INBOX.>"),
+					resource.TestCheckNoResourceAttr("data.nsc_permission_set.test", "deny_sub"),
+					resource.TestCheckResourceAttr("data.nsc_permission_set.test", "conflicts.#", "0"),
+				),
+			},
+			{
+				Config: `
+data "nsc_permission_set" "test" {
+  fragments = [
+    { name = "orders", allow_pub = ["orders.created"] },
+    { name = "lockdown", deny_pub = ["orders.>"] },
+  ]
+}
+`,
+				ExpectError: regexp.MustCompile("Conflicting permissions"),
+			},
+			{
+				Config: `
+data "nsc_permission_set" "test" {
+  allow_conflicts = true
+  fragments = [
+    { name = "orders", allow_pub = ["orders.created"] },
+    { name = "lockdown", deny_pub = ["orders.>"] },
+  ]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_permission_set.test", "conflicts.#", "1"),
+					resource.TestCheckResourceAttr("data.nsc_permission_set.test", "allow_pub.0", "orders.created"),
+				),
+			},
+			{
+				Config: `
+data "nsc_permission_set" "test" {
+  fragments = [
+    { allow_pub = ["orders.> workers"] },
+  ]
+}
+`,
+				ExpectError: regexp.MustCompile("cannot contain a queue group"),
+			},
+		},
+	})
+}
@@ -0,0 +1,230 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ datasource.DataSource = &ResolverDriftDataSource{}
+var _ datasource.DataSourceWithConfigure = &ResolverDriftDataSource{}
+
+const resolverDriftDefaultTimeout = 10 * time.Second
+
+// accountClaimsLookupSubject is the system service of the NATS resolver that
+// returns the account JWT it holds.
+const accountClaimsLookupSubject = "$SYS.REQ.ACCOUNT.%s.CLAIMS.LOOKUP"
+
+func NewResolverDriftDataSource() datasource.DataSource {
+	return &ResolverDriftDataSource{}
+}
+
+type ResolverDriftDataSource struct {
+	keys *keypairCache
+	nats *NATSConnectionModel
+}
+
+type ResolverDriftDataSourceModel struct {
+	ID                  types.String `tfsdk:"id"`
+	URL                 types.String `tfsdk:"url"`
+	Account             types.String `tfsdk:"account"`
+	ExpectedJWT         types.String `tfsdk:"expected_jwt"`
+	ExpectedFingerprint types.String `tfsdk:"expected_fingerprint"`
+	NATSConnectionModel
+	Deployed            types.Bool        `tfsdk:"deployed"`
+	InSync              types.Bool        `tfsdk:"in_sync"`
+	DeployedFingerprint types.String      `tfsdk:"deployed_fingerprint"`
+	DeployedJWTID       types.String      `tfsdk:"deployed_jwt_id"`
+	DeployedIssuedAt    timetypes.RFC3339 `tfsdk:"deployed_issued_at"`
+}
+
+func (d *ResolverDriftDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_resolver_drift"
+}
+
+func (d *ResolverDriftDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Connects to a NATS server with system account credentials, looks up the account JWT its resolver serves and compares it with the JWT Terraform issued. Assert on `in_sync` in a `check` block or a postcondition to find clusters lagging behind Terraform. " +
+			"An account the resolver does not know does not fail the read; `deployed` and `in_sync` are false then.",
+
+		Attributes: natsConnectionAttributes(map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Account public key (same as account)",
+			},
+			"url": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Server URL, e.g. `nats://nats.example.com:4222`. The `tls` scheme forces TLS, which is also used whenever the server requires it. The `ws` and `wss` schemes connect over WebSocket, e.g. `wss://nats.example.com/ws`, through the proxy of the `HTTPS_PROXY` or `HTTP_PROXY` environment variable. As with `nats`, setting TLS options forces TLS.",
+			},
+			"account": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the account whose JWT is looked up",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^A[A-Z2-7]{55}$`),
+						"must be a valid account public key starting with 'A'",
+					),
+				},
+			},
+			"expected_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Account JWT expected to be deployed, e.g. `nsc_account.app.jwt`. Compared by fingerprint. Exactly one of `expected_jwt` or `expected_fingerprint` must be set.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("expected_fingerprint")),
+				},
+			},
+			"expected_fingerprint": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "SHA-256 of the account JWT expected to be deployed, hex encoded, optionally prefixed with `sha256:` as in the audit log.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^(sha256:)?[0-9a-fA-F]{64}$`), "must be a hex encoded SHA-256"),
+				},
+			},
+			"creds": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Credentials file content of a system account user, e.g. from `nsc_user.sys.creds`. The user must be allowed to publish to `$SYS.REQ.ACCOUNT.*.CLAIMS.LOOKUP`. Defaults to the provider `nats` block. One of `creds`, `nkey_seed` or `user` must be set here or there.",
+				Validators:          natsConnectionValidators()["creds"],
+			},
+			"timeout": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Time limit for connecting and looking up the JWT. Defaults to `10s`, or the `timeout` of the provider `nats` block.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"deployed": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the resolver serves a JWT for the account",
+			},
+			"in_sync": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the deployed JWT is the expected one",
+			},
+			"deployed_fingerprint": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "SHA-256 of the deployed JWT, hex encoded. Null when no JWT is deployed.",
+			},
+			"deployed_jwt_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID (`jti`) of the deployed JWT. Null when no JWT is deployed.",
+			},
+			"deployed_issued_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Computed:            true,
+				MarkdownDescription: "Issue time of the deployed JWT. Null when no JWT is deployed.",
+			},
+		}),
+	}
+}
+
+func (d *ResolverDriftDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.keys = providerData.Keys
+	d.nats = providerData.NATS
+}
+
+func (d *ResolverDriftDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ResolverDriftDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	opts, timeout, diags := data.natsConnectOptions(d.nats, d.keys, resolverDriftDefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	session, err := natsDial(requestCtx, data.URL.ValueString(), opts)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to connect to NATS", err.Error())
+		return
+	}
+	defer session.Close()
+
+	account := data.Account.ValueString()
+	deployed, err := lookupAccountClaims(session, account)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to look up account JWT",
+			fmt.Sprintf("Failed to look up the JWT of account %s: %s", account, err),
+		)
+		return
+	}
+
+	data.ID = data.Account
+	data.Deployed = types.BoolValue(deployed != "")
+	data.InSync = types.BoolValue(false)
+	data.DeployedFingerprint = types.StringNull()
+	data.DeployedJWTID = types.StringNull()
+	data.DeployedIssuedAt = timetypes.NewRFC3339Null()
+
+	if deployed != "" {
+		claims, err := jwt.DecodeAccountClaims(deployed)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid deployed JWT", fmt.Sprintf("The resolver served an invalid JWT for account %s: %s", account, err))
+			return
+		}
+		if claims.Subject != account {
+			resp.Diagnostics.AddError("Invalid deployed JWT", fmt.Sprintf("The resolver served a JWT for account %s when looking up %s", claims.Subject, account))
+			return
+		}
+
+		fingerprint := jwtFingerprint(deployed)
+		data.DeployedFingerprint = types.StringValue(fingerprint)
+		data.DeployedJWTID = types.StringValue(claims.ID)
+		data.DeployedIssuedAt = timetypes.NewRFC3339TimeValue(time.Unix(claims.IssuedAt, 0).UTC())
+		data.InSync = types.BoolValue(fingerprint == data.expectedFingerprint())
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// expectedFingerprint returns the fingerprint of the expected JWT in the form
+// of jwtFingerprint.
+func (m ResolverDriftDataSourceModel) expectedFingerprint() string {
+	if !m.ExpectedJWT.IsNull() {
+		return jwtFingerprint(m.ExpectedJWT.ValueString())
+	}
+	return strings.ToLower(strings.TrimPrefix(m.ExpectedFingerprint.ValueString(), "sha256:"))
+}
+
+// lookupAccountClaims asks the resolver for the JWT of an account. An empty
+// JWT means that the resolver does not know the account.
+func lookupAccountClaims(session *natsSession, account string) (string, error) {
+	payload, err := session.request(fmt.Sprintf(accountClaimsLookupSubject, account), nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(payload)), nil
+}
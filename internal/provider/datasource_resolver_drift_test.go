@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestAccResolverDriftDataSource(t *testing.T) {
+	account, token := testAccountJWT(t)
+	_, otherToken := testAccountJWT(t)
+	claims, _ := jwt.DecodeAccountClaims(token)
+
+	server := newFakeNATSServer(t)
+	server.claims = map[string]string{account: token}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResolverDriftDataSourceConfig(server.URL(), account, fmt.Sprintf("expected_jwt = %q", token)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_resolver_drift.test", "deployed", "true"),
+					resource.TestCheckResourceAttr("data.nsc_resolver_drift.test", "in_sync", "true"),
+					resource.TestCheckResourceAttr("data.nsc_resolver_drift.test", "deployed_fingerprint", jwtFingerprint(token)),
+					resource.TestCheckResourceAttr("data.nsc_resolver_drift.test", "deployed_jwt_id", claims.ID),
+					resource.TestCheckResourceAttrSet("data.nsc_resolver_drift.test", "deployed_issued_at"),
+				),
+			},
+			{
+				Config: testAccResolverDriftDataSourceConfig(server.URL(), account, fmt.Sprintf("expected_fingerprint = %q", "sha256:"+jwtFingerprint(otherToken))),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_resolver_drift.test", "deployed", "true"),
+					resource.TestCheckResourceAttr("data.nsc_resolver_drift.test", "in_sync", "false"),
+				),
+			},
+		},
+	})
+}
+
+func testAccResolverDriftDataSourceConfig(url, account, expectation string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "system" {
+  type = "account"
+}
+
+resource "nsc_nkey" "sys_user" {
+  type = "user"
+}
+
+resource "nsc_user" "sys" {
+  name        = "sys"
+  subject     = nsc_nkey.sys_user.public_key
+  issuer_seed = nsc_nkey.system.seed
+  seed        = nsc_nkey.sys_user.seed
+}
+
+data "nsc_resolver_drift" "test" {
+  url     = %[1]q
+  creds   = nsc_user.sys.creds
+  account = %[2]q
+  %[3]s
+}
+`, url, account, expectation)
+}
+
+func TestLookupAccountClaims(t *testing.T) {
+	userJWT, userKP := testUserCredentials(t)
+	account, token := testAccountJWT(t)
+
+	server := newFakeNATSServer(t)
+	server.claims = map[string]string{account: token + "\n"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	session, err := natsDial(ctx, server.URL(), natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer session.Close()
+
+	deployed, err := lookupAccountClaims(session, account)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deployed != token {
+		t.Errorf("expected the account JWT, got %q", deployed)
+	}
+
+	deployed, err = lookupAccountClaims(session, "AUNKNOWN")
+	if err != nil || deployed != "" {
+		t.Errorf("expected no JWT for an unknown account, got %q, %v", deployed, err)
+	}
+}
+
+func TestResolverDriftDataSourceModel_expectedFingerprint(t *testing.T) {
+	_, token := testAccountJWT(t)
+	fingerprint := jwtFingerprint(token)
+
+	for _, data := range []ResolverDriftDataSourceModel{
+		{ExpectedJWT: types.StringValue(token)},
+		{ExpectedJWT: types.StringNull(), ExpectedFingerprint: types.StringValue(fingerprint)},
+		{ExpectedJWT: types.StringNull(), ExpectedFingerprint: types.StringValue("sha256:" + fingerprint)},
+		{ExpectedJWT: types.StringNull(), ExpectedFingerprint: types.StringValue(strings.ToUpper(fingerprint))},
+	} {
+		if got := data.expectedFingerprint(); got != fingerprint {
+			t.Errorf("expected %s, got %s", fingerprint, got)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ datasource.DataSource = &RoleDataSource{}
+
+func NewRoleDataSource() datasource.DataSource {
+	return &RoleDataSource{}
+}
+
+type RoleDataSource struct{}
+
+// UserRoleModel holds the attributes of the nsc_user resource that a role
+// bundles.
+type UserRoleModel struct {
+	AllowPub               types.List           `tfsdk:"allow_pub"`
+	AllowSub               types.List           `tfsdk:"allow_sub"`
+	DenyPub                types.List           `tfsdk:"deny_pub"`
+	DenySub                types.List           `tfsdk:"deny_sub"`
+	AllowPubResponse       types.Int64          `tfsdk:"allow_pub_response"`
+	ResponseTTL            timetypes.GoDuration `tfsdk:"response_ttl"`
+	MaxSubscriptions       Limit                `tfsdk:"max_subscriptions"`
+	MaxData                ByteSize             `tfsdk:"max_data"`
+	MaxPayload             ByteSize             `tfsdk:"max_payload"`
+	AllowedConnectionTypes types.List           `tfsdk:"allowed_connection_types"`
+}
+
+type RoleDataSourceModel struct {
+	ID   types.String `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+
+	UserRoleModel
+
+	Role types.String `tfsdk:"role"`
+}
+
+func (d *RoleDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_role"
+}
+
+func (d *RoleDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	var resourceSchema resource.SchemaResponse
+	(&UserResource{}).Schema(ctx, resource.SchemaRequest{}, &resourceSchema)
+
+	attributes, _ := claimsDataSourceSchema(resourceSchema.Schema, UserRoleModel{})
+
+	attributes["id"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "Role name (same as name)",
+	}
+	attributes["name"] = schema.StringAttribute{
+		Required:            true,
+		MarkdownDescription: "Role name, e.g. `publisher`. Used in error messages of the users referencing the role.",
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+	attributes["role"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "The role in JSON format, to pass to the `role` attribute of `nsc_user` or `nsc_user_claims`",
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Defines a named bundle of user permissions and limits, so that roles such as publisher, consumer or admin are defined once and shared by many users through the `role` attribute of `nsc_user`. " +
+			"Takes the same attributes as `nsc_user`; attributes set on a user take precedence over those of its role.",
+		Attributes: attributes,
+	}
+}
+
+func (d *RoleDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data RoleDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	role, diags := buildUserRole(ctx, data.Name.ValueString(), data.UserRoleModel)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roleJSON, err := json.Marshal(role)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode role", err.Error())
+		return
+	}
+
+	data.ID = data.Name
+	data.Role = types.StringValue(string(roleJSON))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// userRole is the JSON form of a role. Attributes not set on the role are
+// left out, so they do not override the defaults of the user.
+type userRole struct {
+	Name                   string   `json:"name"`
+	AllowPub               []string `json:"allow_pub,omitempty"`
+	AllowSub               []string `json:"allow_sub,omitempty"`
+	DenyPub                []string `json:"deny_pub,omitempty"`
+	DenySub                []string `json:"deny_sub,omitempty"`
+	AllowPubResponse       *int64   `json:"allow_pub_response,omitempty"`
+	ResponseTTL            string   `json:"response_ttl,omitempty"`
+	MaxSubscriptions       *int64   `json:"max_subscriptions,omitempty"`
+	MaxData                *int64   `json:"max_data,omitempty"`
+	MaxPayload             *int64   `json:"max_payload,omitempty"`
+	AllowedConnectionTypes []string `json:"allowed_connection_types,omitempty"`
+}
+
+// buildUserRole creates a role from the attributes of the data source.
+func buildUserRole(ctx context.Context, name string, m UserRoleModel) (*userRole, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	role := &userRole{Name: name}
+	for _, list := range []struct {
+		value types.List
+		dst   *[]string
+	}{
+		{m.AllowPub, &role.AllowPub},
+		{m.AllowSub, &role.AllowSub},
+		{m.DenyPub, &role.DenyPub},
+		{m.DenySub, &role.DenySub},
+		{m.AllowedConnectionTypes, &role.AllowedConnectionTypes},
+	} {
+		values, d := stringListValues(ctx, list.value)
+		diags.Append(d...)
+		*list.dst = values
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	if !m.AllowPubResponse.IsNull() {
+		role.AllowPubResponse = m.AllowPubResponse.ValueInt64Pointer()
+	}
+	if !m.ResponseTTL.IsNull() {
+		duration, d := m.ResponseTTL.ValueGoDuration()
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		role.ResponseTTL = duration.String()
+	}
+	for _, limit := range []struct {
+		value types.Int64
+		dst   **int64
+	}{
+		{m.MaxSubscriptions.Int64(), &role.MaxSubscriptions},
+		{m.MaxData.Int64(), &role.MaxData},
+		{m.MaxPayload.Int64(), &role.MaxPayload},
+	} {
+		if !limit.value.IsNull() {
+			*limit.dst = limit.value.ValueInt64Pointer()
+		}
+	}
+
+	return role, diags
+}
+
+// decodeUserRole decodes the JSON form of a role.
+func decodeUserRole(value string) (*userRole, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+
+	var role userRole
+	if err := decoder.Decode(&role); err != nil {
+		return nil, fmt.Errorf("role must be the role attribute of an nsc_role data source: %w", err)
+	}
+	if role.Name == "" {
+		return nil, fmt.Errorf("role must be the role attribute of an nsc_role data source: name is missing")
+	}
+	if role.ResponseTTL != "" {
+		if _, err := time.ParseDuration(role.ResponseTTL); err != nil {
+			return nil, fmt.Errorf("response_ttl of role %q is invalid: %w", role.Name, err)
+		}
+	}
+	return &role, nil
+}
+
+// apply sets the permissions and limits of the role on the user claims,
+// except for those set on the user itself. An allow_pub_response of 0 counts
+// as not set, as it is the default of the user. A permissions block on the
+// user replaces all permissions of the role.
+func (r *userRole) apply(data *UserClaimsModel, claims *jwt.UserClaims) {
+	if data.Permissions != nil {
+		r.AllowPub, r.AllowSub, r.DenyPub, r.DenySub, r.AllowPubResponse = nil, nil, nil, nil, nil
+	}
+
+	for _, list := range []struct {
+		value types.List
+		role  []string
+		dst   *jwt.StringList
+	}{
+		{data.AllowPub, r.AllowPub, &claims.Permissions.Pub.Allow},
+		{data.AllowSub, r.AllowSub, &claims.Permissions.Sub.Allow},
+		{data.DenyPub, r.DenyPub, &claims.Permissions.Pub.Deny},
+		{data.DenySub, r.DenySub, &claims.Permissions.Sub.Deny},
+		{data.AllowedConnectionTypes, r.AllowedConnectionTypes, &claims.AllowedConnectionTypes},
+	} {
+		if list.value.IsNull() && len(list.role) > 0 {
+			*list.dst = list.role
+		}
+	}
+
+	if r.AllowPubResponse != nil && claims.Permissions.Resp == nil && data.AllowPubResponse.ValueInt64() == 0 {
+		if max := *r.AllowPubResponse; max > 0 || max == responseUnlimited {
+			claims.Permissions.Resp = &jwt.ResponsePermission{MaxMsgs: int(max)}
+			if duration, err := time.ParseDuration(r.ResponseTTL); err == nil {
+				claims.Permissions.Resp.Expires = duration
+			}
+			if !data.ResponseTTL.IsNull() && !data.ResponseTTL.IsUnknown() {
+				duration, _ := data.ResponseTTL.ValueGoDuration()
+				claims.Permissions.Resp.Expires = duration
+			}
+		}
+	}
+
+	for _, limit := range []struct {
+		set  bool
+		role *int64
+		dst  *int64
+	}{
+		{!data.MaxSubscriptions.IsNull(), r.MaxSubscriptions, &claims.Limits.Subs},
+		{!data.MaxData.IsNull(), r.MaxData, &claims.Limits.Data},
+		{!data.MaxPayload.IsNull(), r.MaxPayload, &claims.Limits.Payload},
+	} {
+		if !limit.set && limit.role != nil {
+			*limit.dst = *limit.role
+		}
+	}
+}
+
+var _ validator.String = userRoleValidator{}
+
+// userRoleValidator checks that the role attribute of a user holds a role
+// rendered by the nsc_role data source.
+type userRoleValidator struct{}
+
+func (v userRoleValidator) Description(_ context.Context) string {
+	return "value must be the role attribute of an nsc_role data source"
+}
+
+func (v userRoleValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v userRoleValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := decodeUserRole(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid role", err.Error())
+	}
+}
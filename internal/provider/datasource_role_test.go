@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestDecodeUserRole(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{name: "valid", value: `{"name":"publisher","allow_pub":["orders.>"],"max_payload":1024}`},
+		{name: "not json", value: `publisher`, wantErr: "must be the role attribute"},
+		{name: "unknown field", value: `{"name":"publisher","allow_publish":["orders.>"]}`, wantErr: "unknown field"},
+		{name: "missing name", value: `{"allow_pub":["orders.>"]}`, wantErr: "name is missing"},
+		{name: "invalid response ttl", value: `{"name":"publisher","response_ttl":"soon"}`, wantErr: "response_ttl of role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeUserRole(tt.value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil || !regexp.MustCompile(tt.wantErr).MatchString(err.Error()) {
+				t.Fatalf("expected error matching %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildUserClaims_role(t *testing.T) {
+	ctx := context.Background()
+
+	role := `{"name":"publisher","allow_pub":["orders.>"],"deny_sub":["admin.>"],"allow_pub_response":1,"response_ttl":"5s","max_subscriptions":10,"max_payload":1024}`
+
+	// Attributes not set on the user come from the role
+	claims, diags := buildUserClaims(ctx, &UserClaimsModel{
+		Subject:          types.StringValue("UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4"),
+		AllowPubResponse: types.Int64Value(0),
+		Role:             types.StringValue(role),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := claims.Permissions.Pub.Allow; len(got) != 1 || got[0] != "orders.>" {
+		t.Errorf("expected allow_pub from role, got %v", got)
+	}
+	if got := claims.Permissions.Sub.Deny; len(got) != 1 || got[0] != "admin.>" {
+		t.Errorf("expected deny_sub from role, got %v", got)
+	}
+	if claims.Permissions.Resp == nil || claims.Permissions.Resp.MaxMsgs != 1 || claims.Permissions.Resp.Expires != 5*time.Second {
+		t.Errorf("expected response permission from role, got %+v", claims.Permissions.Resp)
+	}
+	if claims.Limits.Subs != 10 || claims.Limits.Payload != 1024 {
+		t.Errorf("expected limits from role, got subs %d, payload %d", claims.Limits.Subs, claims.Limits.Payload)
+	}
+
+	// Attributes set on the user take precedence
+	claims, diags = buildUserClaims(ctx, &UserClaimsModel{
+		Subject:          types.StringValue("UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4"),
+		AllowPub:         types.ListValueMust(types.StringType, []attr.Value{types.StringValue("audit.>")}),
+		AllowPubResponse: types.Int64Value(0),
+		ResponseTTL:      timetypes.NewGoDurationValue(time.Minute),
+		MaxPayload:       NewByteSizeValue("2048"),
+		Role:             types.StringValue(role),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := claims.Permissions.Pub.Allow; len(got) != 1 || got[0] != "audit.>" {
+		t.Errorf("expected allow_pub from user, got %v", got)
+	}
+	if claims.Permissions.Resp == nil || claims.Permissions.Resp.Expires != time.Minute {
+		t.Errorf("expected response_ttl from user, got %+v", claims.Permissions.Resp)
+	}
+	if claims.Limits.Payload != 2048 {
+		t.Errorf("expected max_payload from user, got %d", claims.Limits.Payload)
+	}
+}
+
+func TestAccRoleDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfig("TestUser") + `
+data "nsc_role" "publisher" {
+  name               = "publisher"
+  allow_pub          = ["orders.>"]
+  allow_pub_response = 1
+  max_payload        = "1KiB"
+}
+
+resource "nsc_user" "publisher" {
+  name        = "Publisher"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  role        = data.nsc_role.publisher.role
+  deny_sub    = ["admin.>"]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_role.publisher", "id", "publisher"),
+					resource.TestCheckResourceAttr("data.nsc_role.publisher", "role", `{"name":"publisher","allow_pub":["orders.>"],"allow_pub_response":1,"max_payload":1024}`),
+					testAccCheckUserClaims("nsc_user.publisher", func(claims *jwt.UserClaims) error {
+						if got := claims.Permissions.Pub.Allow; len(got) != 1 || got[0] != "orders.>" {
+							return fmt.Errorf("expected allow_pub from role, got %v", got)
+						}
+						if got := claims.Permissions.Sub.Deny; len(got) != 1 || got[0] != "admin.>" {
+							return fmt.Errorf("expected deny_sub from user, got %v", got)
+						}
+						if claims.Permissions.Resp == nil || claims.Permissions.Resp.MaxMsgs != 1 {
+							return fmt.Errorf("expected response permission from role, got %+v", claims.Permissions.Resp)
+						}
+						if claims.Limits.Payload != 1024 {
+							return fmt.Errorf("expected max_payload from role, got %d", claims.Limits.Payload)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: testAccUserResourceConfig("TestUser") + `
+resource "nsc_user" "invalid" {
+  name        = "Invalid"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  role        = "publisher"
+}
+`,
+				ExpectError: regexp.MustCompile("Invalid role"),
+			},
+		},
+	})
+}
+
+// testAccCheckUserClaims decodes the user JWT and passes the claims to check.
+func testAccCheckUserClaims(resourceName string, check func(*jwt.UserClaims) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", resourceName)
+		}
+
+		claims, err := jwt.DecodeUserClaims(rs.Primary.Attributes["jwt"])
+		if err != nil {
+			return fmt.Errorf("failed to decode user JWT: %w", err)
+		}
+		return check(claims)
+	}
+}
@@ -0,0 +1,463 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ datasource.DataSource = &StaticConfigDataSource{}
+
+func NewStaticConfigDataSource() datasource.DataSource {
+	return &StaticConfigDataSource{}
+}
+
+type StaticConfigDataSource struct{}
+
+type StaticConfigDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Accounts      types.Map    `tfsdk:"accounts"`
+	Users         types.Map    `tfsdk:"users"`
+	SystemAccount types.String `tfsdk:"system_account"`
+	Config        types.String `tfsdk:"config"`
+}
+
+func (d *StaticConfigDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_static_config"
+}
+
+func (d *StaticConfigDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Converts account and user claims into the `accounts` block of a nats-server configuration without operator mode, for small deployments that keep the same Terraform definitions but run servers with static configuration instead of JWTs. " +
+			"Users authenticate with their nkey. Permissions, allowed connection types, exports, imports and JetStream limits are converted; claims static configuration cannot express, such as connection limits, source networks or revocations, are reported as warnings.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hash of the rendered configuration",
+			},
+			"accounts": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				MarkdownDescription: "Map of account names to account JWTs, e.g. `nsc_account.app.jwt`, or to unsigned account claims, e.g. `data.nsc_account_claims.app.claims_json`. The names become the account names of the configuration. Every account imported from must be listed.",
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+				},
+			},
+			"users": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Map of user names to user JWTs, e.g. `nsc_user.app.jwt`, or to unsigned user claims, e.g. `data.nsc_user_claims.app.claims_json`. Each user is added to the account that issued it, which must be listed in `accounts`. Unsigned claims must set `issuer` or `issuer_account`. The names are rendered as comments.",
+			},
+			"system_account": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the account in `accounts` to render as `system_account`",
+			},
+			"config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The rendered `accounts` block, followed by `system_account` when set",
+			},
+		},
+	}
+}
+
+func (d *StaticConfigDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data StaticConfigDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accountValues := make(map[string]types.String, len(data.Accounts.Elements()))
+	resp.Diagnostics.Append(data.Accounts.ElementsAs(ctx, &accountValues, false)...)
+	userValues := make(map[string]types.String, len(data.Users.Elements()))
+	if !data.Users.IsNull() {
+		resp.Diagnostics.Append(data.Users.ElementsAs(ctx, &userValues, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accounts := make([]staticAccount, 0, len(accountValues))
+	for name, value := range accountValues {
+		attrPath := path.Root("accounts").AtMapKey(name)
+		if value.IsNull() {
+			resp.Diagnostics.AddAttributeError(attrPath, "Missing account claims", "The account JWT is null. For accounts with jwt_output = \"sensitive_only\", pass jwt_sensitive instead.")
+			continue
+		}
+		claims, err := decodeAccountClaimsOrJSON(value.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(attrPath, "Invalid account claims", err.Error())
+			continue
+		}
+		accounts = append(accounts, staticAccount{Name: name, Claims: claims})
+	}
+
+	users := make([]staticUser, 0, len(userValues))
+	for name, value := range userValues {
+		attrPath := path.Root("users").AtMapKey(name)
+		if value.IsNull() {
+			resp.Diagnostics.AddAttributeError(attrPath, "Missing user claims", "The user JWT is null. For users with jwt_output = \"never\", pass the claims_json of nsc_user_claims instead.")
+			continue
+		}
+		claims, err := decodeUserClaimsOrJSON(value.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(attrPath, "Invalid user claims", err.Error())
+			continue
+		}
+		users = append(users, staticUser{Name: name, Claims: claims})
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	systemAccount := data.SystemAccount.ValueString()
+	if systemAccount != "" {
+		if _, ok := accountValues[systemAccount]; !ok {
+			resp.Diagnostics.AddAttributeError(path.Root("system_account"), "Unknown system account", fmt.Sprintf("Account %q is not in accounts.", systemAccount))
+			return
+		}
+	}
+
+	config, issues, err := renderStaticConfig(accounts, users, systemAccount)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to convert claims", err.Error())
+		return
+	}
+	for _, issue := range issues {
+		resp.Diagnostics.AddWarning("Claims not supported by static configuration", issue)
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%x", sha256.Sum256([]byte(config))))
+	data.Config = types.StringValue(config)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// decodeUserClaimsOrJSON decodes a user JWT, or unsigned user claims in JSON
+// as rendered by the nsc_user_claims data source.
+func decodeUserClaimsOrJSON(value string) (*jwt.UserClaims, error) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		claims, err := jwt.DecodeUserClaims(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode user JWT: %w", err)
+		}
+		return claims, nil
+	}
+
+	claims := &jwt.UserClaims{}
+	if err := json.Unmarshal([]byte(value), claims); err != nil {
+		return nil, fmt.Errorf("failed to decode user claims: %w", err)
+	}
+	// Unsigned claims have no type, it is set when they are encoded
+	if claims.Type != "" && claims.Type != jwt.UserClaim {
+		return nil, fmt.Errorf("expected user claims, got %s claims", claims.Type)
+	}
+	if !nkeys.IsValidPublicUserKey(claims.Subject) {
+		return nil, fmt.Errorf("subject of the user claims must be a user public key, got %q", claims.Subject)
+	}
+	return claims, nil
+}
+
+// staticAccount is an account of a static configuration.
+type staticAccount struct {
+	Name   string
+	Claims *jwt.AccountClaims
+}
+
+// staticUser is a user of a static configuration.
+type staticUser struct {
+	Name   string
+	Claims *jwt.UserClaims
+}
+
+// renderStaticConfig renders the accounts block of a nats-server
+// configuration from account and user claims. Accounts and users are ordered
+// by name. It also returns a description of every claim that cannot be
+// expressed in static configuration and was left out.
+func renderStaticConfig(accounts []staticAccount, users []staticUser, systemAccount string) (string, []string, error) {
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Name < accounts[j].Name })
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+
+	names := make(map[string]string, len(accounts))
+	for _, account := range accounts {
+		if other, ok := names[account.Claims.Subject]; ok {
+			return "", nil, fmt.Errorf("accounts %q and %q have the same public key %s", other, account.Name, account.Claims.Subject)
+		}
+		names[account.Claims.Subject] = account.Name
+	}
+
+	accountUsers := make(map[string][]staticUser, len(accounts))
+	for _, user := range users {
+		issuer := user.Claims.IssuerAccount
+		if issuer == "" {
+			issuer = user.Claims.Issuer
+		}
+		if issuer == "" {
+			return "", nil, fmt.Errorf("user %q has no issuer; set issuer or issuer_account of its claims", user.Name)
+		}
+		account, ok := names[issuer]
+		if !ok {
+			return "", nil, fmt.Errorf("user %q is issued by account %s, which is not in accounts", user.Name, issuer)
+		}
+		accountUsers[account] = append(accountUsers[account], user)
+	}
+
+	var issues []string
+	var b strings.Builder
+	b.WriteString("accounts {\n")
+	for _, account := range accounts {
+		claims := account.Claims
+		fmt.Fprintf(&b, "  %q: {\n", account.Name)
+		issues = append(issues, staticAccountIssues(account)...)
+
+		if claims.Limits.MemoryStorage != 0 || claims.Limits.DiskStorage != 0 {
+			fmt.Fprintf(&b, "    jetstream: {max_mem: %d, max_file: %d, max_streams: %d, max_consumers: %d}\n",
+				claims.Limits.MemoryStorage,
+				claims.Limits.DiskStorage,
+				staticUnlimited(claims.Limits.Streams),
+				staticUnlimited(claims.Limits.Consumer),
+			)
+		}
+
+		if users := accountUsers[account.Name]; len(users) > 0 {
+			b.WriteString("    users: [\n")
+			for _, user := range users {
+				issues = append(issues, staticUserIssues(user)...)
+				fmt.Fprintf(&b, "      # %s\n", user.Name)
+				permissions := user.Claims.Permissions
+				// Like the server in operator mode, users without
+				// permissions get the default permissions of the account
+				if staticPermissionsEmpty(permissions) {
+					permissions = claims.DefaultPermissions
+				}
+				fmt.Fprintf(&b, "      %s\n", staticNKeyUser(user.Claims.Subject, permissions, user.Claims.AllowedConnectionTypes))
+			}
+			b.WriteString("    ]\n")
+		}
+
+		if len(claims.Exports) > 0 {
+			b.WriteString("    exports: [\n")
+			for _, export := range claims.Exports {
+				kind := "stream"
+				if export.IsService() {
+					kind = "service"
+				}
+				fmt.Fprintf(&b, "      {%s: %q", kind, string(export.Subject))
+				if export.TokenReq {
+					importers := staticImporters(accounts, claims.Subject, export)
+					fmt.Fprintf(&b, ", accounts: [%s]", quotedList(importers))
+				}
+				if kind == "service" {
+					if export.ResponseType != "" && export.ResponseType != jwt.ResponseTypeSingleton {
+						fmt.Fprintf(&b, ", response_type: %q", strings.ToLower(string(export.ResponseType)))
+					}
+					if export.ResponseThreshold > 0 {
+						fmt.Fprintf(&b, ", response_threshold: %q", export.ResponseThreshold.String())
+					}
+					if export.Latency != nil {
+						sampling := fmt.Sprintf("%d", export.Latency.Sampling)
+						if export.Latency.Sampling == jwt.Headers {
+							sampling = `"headers"`
+						}
+						fmt.Fprintf(&b, ", latency: {sampling: %s, subject: %q}", sampling, string(export.Latency.Results))
+					}
+				}
+				b.WriteString("}\n")
+			}
+			b.WriteString("    ]\n")
+		}
+
+		if len(claims.Imports) > 0 {
+			b.WriteString("    imports: [\n")
+			for _, imp := range claims.Imports {
+				exporter, ok := names[imp.Account]
+				if !ok {
+					return "", nil, fmt.Errorf("account %q imports %s from account %s, which is not in accounts", account.Name, imp.Subject, imp.Account)
+				}
+				kind := "stream"
+				if imp.IsService() {
+					kind = "service"
+				}
+				fmt.Fprintf(&b, "      {%s: {account: %q, subject: %q}", kind, exporter, string(imp.Subject))
+				switch {
+				case imp.LocalSubject != "":
+					fmt.Fprintf(&b, ", to: %q", string(imp.LocalSubject))
+				case imp.To != "" && kind == "service":
+					fmt.Fprintf(&b, ", to: %q", string(imp.To))
+				case imp.To != "":
+					// Stream imports used to take a prefix
+					fmt.Fprintf(&b, ", prefix: %q", string(imp.To))
+				}
+				b.WriteString("}\n")
+			}
+			b.WriteString("    ]\n")
+		}
+
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	if systemAccount != "" {
+		fmt.Fprintf(&b, "system_account: %q\n", systemAccount)
+	}
+
+	return b.String(), issues, nil
+}
+
+// staticImporters returns the names of the accounts that import from a
+// token-required export, the only accounts allowed to import it in static
+// configuration.
+func staticImporters(accounts []staticAccount, exporter string, export *jwt.Export) []string {
+	var importers []string
+	for _, account := range accounts {
+		for _, imp := range account.Claims.Imports {
+			if imp.Account == exporter && imp.Subject.IsContainedIn(export.Subject) {
+				importers = append(importers, account.Name)
+				break
+			}
+		}
+	}
+	return importers
+}
+
+// staticNKeyUser renders the entry of a user authenticating with its nkey.
+func staticNKeyUser(publicKey string, permissions jwt.Permissions, connectionTypes jwt.StringList) string {
+	entry := "{nkey: " + publicKey
+	if !staticPermissionsEmpty(permissions) {
+		entry += ", permissions: " + staticPermissions(permissions)
+	}
+	if len(connectionTypes) > 0 {
+		entry += fmt.Sprintf(", allowed_connection_types: [%s]", quotedList(connectionTypes))
+	}
+	return entry + "}"
+}
+
+// staticPermissions renders publish, subscribe and response permissions.
+func staticPermissions(permissions jwt.Permissions) string {
+	var parts []string
+	if clause := staticPermission(permissions.Pub); clause != "" {
+		parts = append(parts, "publish: "+clause)
+	}
+	if clause := staticPermission(permissions.Sub); clause != "" {
+		parts = append(parts, "subscribe: "+clause)
+	}
+	if permissions.Resp != nil {
+		resp := fmt.Sprintf("allow_responses: {max: %d", permissions.Resp.MaxMsgs)
+		if permissions.Resp.Expires > 0 {
+			resp += fmt.Sprintf(", expires: %q", permissions.Resp.Expires.String())
+		}
+		parts = append(parts, resp+"}")
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func staticPermission(permission jwt.Permission) string {
+	var parts []string
+	if len(permission.Allow) > 0 {
+		parts = append(parts, fmt.Sprintf("allow: [%s]", quotedList(permission.Allow)))
+	}
+	if len(permission.Deny) > 0 {
+		parts = append(parts, fmt.Sprintf("deny: [%s]", quotedList(permission.Deny)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func staticPermissionsEmpty(permissions jwt.Permissions) bool {
+	return len(permissions.Pub.Allow) == 0 && len(permissions.Pub.Deny) == 0 &&
+		len(permissions.Sub.Allow) == 0 && len(permissions.Sub.Deny) == 0 &&
+		permissions.Resp == nil
+}
+
+// staticUnlimited maps the stream and consumer limits of 0, which the server
+// does not enforce, to the -1 of static configuration.
+func staticUnlimited(limit int64) int64 {
+	if limit == 0 {
+		return jwt.NoLimit
+	}
+	return limit
+}
+
+// staticAccountIssues describes the account claims that are left out of
+// static configuration.
+func staticAccountIssues(account staticAccount) []string {
+	claims := account.Claims
+	var issues []string
+	limits := map[string]int64{
+		"max_connections":   claims.Limits.Conn,
+		"max_leaf_nodes":    claims.Limits.LeafNodeConn,
+		"max_subscriptions": claims.Limits.Subs,
+		"max_data":          claims.Limits.Data,
+		"max_payload":       claims.Limits.Payload,
+		"max_imports":       claims.Limits.Imports,
+		"max_exports":       claims.Limits.Exports,
+	}
+	for _, name := range sortedKeys(limits) {
+		if limits[name] != jwt.NoLimit {
+			issues = append(issues, fmt.Sprintf("Account %q: %s = %d is not enforced.", account.Name, name, limits[name]))
+		}
+	}
+	if len(claims.Revocations) > 0 {
+		issues = append(issues, fmt.Sprintf("Account %q: user revocations are not applied; remove revoked users instead.", account.Name))
+	}
+	for _, export := range claims.Exports {
+		if len(export.Revocations) > 0 {
+			issues = append(issues, fmt.Sprintf("Account %q: revocations of export %s are not applied.", account.Name, export.Subject))
+		}
+		if export.AccountTokenPosition > 0 {
+			issues = append(issues, fmt.Sprintf("Account %q: account_token_position of export %s is not enforced.", account.Name, export.Subject))
+		}
+	}
+	return issues
+}
+
+// staticUserIssues describes the user claims that are left out of static
+// configuration.
+func staticUserIssues(user staticUser) []string {
+	claims := user.Claims
+	var issues []string
+	if len(claims.Src) > 0 {
+		issues = append(issues, fmt.Sprintf("User %q: source networks are not enforced.", user.Name))
+	}
+	if len(claims.Times) > 0 {
+		issues = append(issues, fmt.Sprintf("User %q: connection time ranges are not enforced.", user.Name))
+	}
+	limits := map[string]int64{
+		"max_subscriptions": claims.NatsLimits.Subs,
+		"max_data":          claims.NatsLimits.Data,
+		"max_payload":       claims.NatsLimits.Payload,
+	}
+	for _, name := range sortedKeys(limits) {
+		if limits[name] != jwt.NoLimit {
+			issues = append(issues, fmt.Sprintf("User %q: %s = %d is not enforced.", user.Name, name, limits[name]))
+		}
+	}
+	if claims.Expires != 0 {
+		issues = append(issues, fmt.Sprintf("User %q: expiry is not enforced.", user.Name))
+	}
+	return issues
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
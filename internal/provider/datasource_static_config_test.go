@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestRenderStaticConfig(t *testing.T) {
+	newAccount := func(name string) (*jwt.AccountClaims, string) {
+		kp, _ := nkeys.CreateAccount()
+		pub, _ := kp.PublicKey()
+		claims := jwt.NewAccountClaims(pub)
+		claims.Name = name
+		return claims, pub
+	}
+	newUser := func(issuer string) (*jwt.UserClaims, string) {
+		kp, _ := nkeys.CreateUser()
+		pub, _ := kp.PublicKey()
+		claims := jwt.NewUserClaims(pub)
+		claims.Issuer = issuer
+		return claims, pub
+	}
+
+	app, appPub := newAccount("App")
+	app.Limits.DiskStorage = 1 << 30
+	app.Limits.MemoryStorage = -1
+	app.Limits.Conn = 10
+	app.DefaultPermissions.Sub.Allow.Add("_INBOX.>")
+	app.Exports.Add(
+		&jwt.Export{Subject: "events.>", Type: jwt.Stream},
+		&jwt.Export{Subject: "req.>", Type: jwt.Service, TokenReq: true, ResponseType: jwt.ResponseTypeStream, ResponseThreshold: 2 * time.Second},
+	)
+
+	billing, billingPub := newAccount("Billing")
+	billing.Imports.Add(
+		&jwt.Import{Subject: "events.>", Account: appPub, Type: jwt.Stream, LocalSubject: "app.events.>"},
+		&jwt.Import{Subject: "req.invoice", Account: appPub, Type: jwt.Service},
+	)
+
+	sys, _ := newAccount("SYS")
+
+	worker, workerPub := newUser(appPub)
+	worker.Pub.Allow.Add("req.>")
+	worker.Pub.Deny.Add("req.admin")
+	worker.Resp = &jwt.ResponsePermission{MaxMsgs: 1, Expires: time.Second}
+	worker.AllowedConnectionTypes.Add(jwt.ConnectionTypeStandard)
+	worker.Src.Add("10.0.0.0/8")
+
+	// Issued by a signing key of the account
+	reader, readerPub := newUser("AOTHERSIGNINGKEY")
+	reader.IssuerAccount = appPub
+
+	invoicer, invoicerPub := newUser(billingPub)
+
+	config, issues, err := renderStaticConfig(
+		[]staticAccount{{Name: "SYS", Claims: sys}, {Name: "billing", Claims: billing}, {Name: "app", Claims: app}},
+		[]staticUser{{Name: "worker", Claims: worker}, {Name: "reader", Claims: reader}, {Name: "invoicer", Claims: invoicer}},
+		"SYS",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := fmt.Sprintf(`accounts {
+  "SYS": {
+  }
+  "app": {
+    jetstream: {max_mem: -1, max_file: 1073741824, max_streams: -1, max_consumers: -1}
+    users: [
+      # reader
+      {nkey: %[2]s, permissions: {subscribe: {allow: ["_INBOX.>"]}}}
+      # worker
+      {nkey: %[3]s, permissions: {publish: {allow: ["req.>"], deny: ["req.admin"]}, allow_responses: {max: 1, expires: "1s"}}, allowed_connection_types: ["STANDARD"]}
+    ]
+    exports: [
+      {stream: "events.>"}
+      {service: "req.>", accounts: ["billing"], response_type: "stream", response_threshold: "2s"}
+    ]
+  }
+  "billing": {
+    users: [
+      # invoicer
+      {nkey: %[4]s}
+    ]
+    imports: [
+      {stream: {account: "app", subject: "events.>"}, to: "app.events.>"}
+      {service: {account: "app", subject: "req.invoice"}}
+    ]
+  }
+}
+system_account: "SYS"
+`, appPub, readerPub, workerPub, invoicerPub)
+	if config != want {
+		t.Errorf("unexpected config:\n%s\nexpected:\n%s", config, want)
+	}
+
+	wantIssues := []string{
+		`Account "app": max_connections = 10 is not enforced.`,
+		`User "worker": source networks are not enforced.`,
+	}
+	if !reflect.DeepEqual(issues, wantIssues) {
+		t.Errorf("expected issues %q, got %q", wantIssues, issues)
+	}
+
+	t.Run("unknown import account", func(t *testing.T) {
+		_, _, err := renderStaticConfig([]staticAccount{{Name: "billing", Claims: billing}}, nil, "")
+		if err == nil || !strings.Contains(err.Error(), "which is not in accounts") {
+			t.Errorf("expected unknown account error, got %v", err)
+		}
+	})
+
+	t.Run("unknown user account", func(t *testing.T) {
+		_, _, err := renderStaticConfig([]staticAccount{{Name: "SYS", Claims: sys}}, []staticUser{{Name: "worker", Claims: worker}}, "")
+		if err == nil || !strings.Contains(err.Error(), `user "worker" is issued by account`) {
+			t.Errorf("expected unknown account error, got %v", err)
+		}
+	})
+}
+
+func TestDecodeUserClaimsOrJSON(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	userKP, _ := nkeys.CreateUser()
+	user, _ := userKP.PublicKey()
+
+	claims := jwt.NewUserClaims(user)
+	claims.Pub.Allow.Add("foo")
+	token, err := claims.Encode(accountKP)
+	if err != nil {
+		t.Fatalf("failed to encode user JWT: %v", err)
+	}
+	claimsJSON, _ := json.Marshal(claims)
+
+	for _, value := range []string{token, string(claimsJSON)} {
+		decoded, err := decodeUserClaimsOrJSON(value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if decoded.Subject != user || !decoded.Pub.Allow.Contains("foo") || decoded.NatsLimits.Subs != jwt.NoLimit {
+			t.Errorf("unexpected claims: %+v", decoded)
+		}
+	}
+
+	for value, want := range map[string]string{
+		"not-a-jwt": "failed to decode user JWT",
+		`{"sub":"` + user + `","nats":{"type":"account"}}`: "expected user claims, got account claims",
+		`{"sub":"ABC","nats":{}}`:                          "must be a user public key",
+	} {
+		if _, err := decodeUserClaimsOrJSON(value); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("decodeUserClaimsOrJSON(%q): expected error containing %q, got %v", value, want, err)
+		}
+	}
+}
+
+func TestAccStaticConfigDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccStaticConfigDataSourceConfig(`"app"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("data.nsc_static_config.test", "config", regexp.MustCompile(`(?s)"app": \{\n    users: \[\n      # service\n      \{nkey: U[A-Z0-9]{55}, permissions: \{publish: \{allow: \["orders\.>"\]\}\}\}\n    \]\n  \}\n\}\nsystem_account: "app"\n$`)),
+					resource.TestCheckResourceAttrSet("data.nsc_static_config.test", "id"),
+				),
+			},
+			{
+				Config:      testAccStaticConfigDataSourceConfig(`"other"`),
+				ExpectError: regexp.MustCompile("Unknown system account"),
+			},
+		},
+	})
+}
+
+func testAccStaticConfigDataSourceConfig(systemAccount string) string {
+	return `
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "app" {
+  type = "account"
+}
+
+resource "nsc_nkey" "service" {
+  type = "user"
+}
+
+resource "nsc_account" "app" {
+  name        = "App"
+  subject     = nsc_nkey.app.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+data "nsc_user_claims" "service" {
+  name      = "service"
+  subject   = nsc_nkey.service.public_key
+  issuer    = nsc_nkey.app.public_key
+  allow_pub = ["orders.>"]
+}
+
+data "nsc_static_config" "test" {
+  accounts = {
+    app = nsc_account.app.jwt
+  }
+  users = {
+    service = data.nsc_user_claims.service.claims_json
+  }
+  system_account = ` + systemAccount + `
+}
+`
+}
@@ -0,0 +1,396 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ datasource.DataSource = &StoreImportsDataSource{}
+
+func NewStoreImportsDataSource() datasource.DataSource {
+	return &StoreImportsDataSource{}
+}
+
+type StoreImportsDataSource struct{}
+
+type StoreImportsDataSourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	StoresDir    types.String `tfsdk:"stores_dir"`
+	KeysDir      types.String `tfsdk:"keys_dir"`
+	Operator     types.String `tfsdk:"operator"`
+	Keys         types.List   `tfsdk:"keys"`
+	ImportBlocks types.String `tfsdk:"import_blocks"`
+}
+
+type StoreKeyModel struct {
+	Address   types.String `tfsdk:"address"`
+	Kind      types.String `tfsdk:"kind"`
+	Role      types.String `tfsdk:"role"`
+	Operator  types.String `tfsdk:"operator"`
+	Account   types.String `tfsdk:"account"`
+	Name      types.String `tfsdk:"name"`
+	PublicKey types.String `tfsdk:"public_key"`
+	SeedPath  types.String `tfsdk:"seed_path"`
+}
+
+var storeKeyAttrTypes = map[string]attr.Type{
+	"address":    types.StringType,
+	"kind":       types.StringType,
+	"role":       types.StringType,
+	"operator":   types.StringType,
+	"account":    types.StringType,
+	"name":       types.StringType,
+	"public_key": types.StringType,
+	"seed_path":  types.StringType,
+}
+
+func (d *StoreImportsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_store_imports"
+}
+
+func (d *StoreImportsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Scans an nsc store for operators, accounts and users and renders `import` blocks for `nsc_nkey` resources holding their identity and signing keys, with the seeds read from the nsc keystore. Used to migrate an environment managed with the nsc CLI; see [Importing Existing Keys](../index.md#importing-existing-keys).",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Stores directory (same as stores_dir)",
+			},
+			"stores_dir": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Directory of the nsc stores, holding a directory per operator, e.g. `~/.local/share/nats/nsc/stores`",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"keys_dir": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Directory of the nsc keystore. Defaults to the `NKEYS_PATH` environment variable, or `~/.local/share/nats/nsc/keys`.",
+			},
+			"operator": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the operator to scan. Defaults to all operators in the stores directory.",
+			},
+			"keys": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Keys found in the store, operators first, then accounts, then users, each ordered by name",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"address": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Suggested address of the `nsc_nkey` resource, e.g. `nsc_nkey.account_app`",
+						},
+						"kind": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Kind of entity the key belongs to: `operator`, `account` or `user`",
+						},
+						"role": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "`identity` for the key the entity is identified by, `signing_key` for its signing keys",
+						},
+						"operator": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Name of the operator",
+						},
+						"account": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Name of the account for account and user keys. Null for operator keys.",
+						},
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Name of the entity",
+						},
+						"public_key": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Public key",
+						},
+						"seed_path": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Path of the seed in the keystore. Null when the keystore does not hold the seed, in which case no import block is rendered for the key.",
+						},
+					},
+				},
+			},
+			"import_blocks": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "`import` blocks for every key with a seed, reading the seed from the keystore. Write them to a `.tf` file and run `terraform plan -generate-config-out=generated.tf` to generate the `nsc_nkey` resources.",
+			},
+		},
+	}
+}
+
+func (d *StoreImportsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data StoreImportsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keysDir := stringValueOrEnv(data.KeysDir, "NKEYS_PATH")
+	if keysDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			resp.Diagnostics.AddError("Missing keystore directory", "Set keys_dir or the NKEYS_PATH environment variable: "+err.Error())
+			return
+		}
+		keysDir = filepath.Join(home, ".local", "share", "nats", "nsc", "keys")
+	}
+
+	keys, err := scanNSCStore(data.StoresDir.ValueString(), keysDir, data.Operator.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to scan nsc store", err.Error())
+		return
+	}
+
+	values := make([]StoreKeyModel, len(keys))
+	for i, key := range keys {
+		values[i] = StoreKeyModel{
+			Address:   types.StringValue("nsc_nkey." + key.ResourceName),
+			Kind:      types.StringValue(key.Kind),
+			Role:      types.StringValue(key.Role),
+			Operator:  types.StringValue(key.Operator),
+			Account:   types.StringNull(),
+			Name:      types.StringValue(key.Name),
+			PublicKey: types.StringValue(key.PublicKey),
+			SeedPath:  types.StringNull(),
+		}
+		if key.Account != "" {
+			values[i].Account = types.StringValue(key.Account)
+		}
+		if key.SeedPath != "" {
+			values[i].SeedPath = types.StringValue(key.SeedPath)
+		}
+	}
+
+	keysValue, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: storeKeyAttrTypes}, values)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.StoresDir
+	data.Keys = keysValue
+	data.ImportBlocks = types.StringValue(renderImportBlocks(keys))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// nscStoreKey is a key of an operator, account or user in an nsc store.
+type nscStoreKey struct {
+	Kind      string
+	Role      string
+	Operator  string
+	Account   string
+	Name      string
+	PublicKey string
+	// SeedPath is the path of the seed in the keystore, empty when the
+	// keystore does not hold it.
+	SeedPath string
+	// ResourceName is the suggested name of the nsc_nkey resource, unique
+	// among the keys of a scan.
+	ResourceName string
+}
+
+// scanNSCStore reads the JWTs of an nsc store laid out as
+// <operator>/<operator>.jwt, <operator>/accounts/<account>/<account>.jwt and
+// <operator>/accounts/<account>/users/<user>.jwt, and returns the identity
+// and signing keys of every operator, account and user. Seeds are looked up
+// in the keystore at keys/<K>/<EY>/<KEY>.nk, as nsc stores them. An empty
+// operator scans all operators.
+func scanNSCStore(storesDir, keysDir, operator string) ([]nscStoreKey, error) {
+	operatorDirs, err := os.ReadDir(storesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var operators, accounts, users []nscStoreKey
+	found := false
+	for _, operatorDir := range operatorDirs {
+		if !operatorDir.IsDir() || (operator != "" && operatorDir.Name() != operator) {
+			continue
+		}
+		operatorPath := filepath.Join(storesDir, operatorDir.Name())
+		operatorJWT := filepath.Join(operatorPath, operatorDir.Name()+".jwt")
+		token, err := os.ReadFile(operatorJWT)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		operatorClaims, err := jwt.DecodeOperatorClaims(strings.TrimSpace(string(token)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid operator JWT in %s: %w", operatorPath, err)
+		}
+		if err := checkStorePublicKeys(operatorJWT, nkeys.PrefixByteOperator, operatorClaims.Subject, operatorClaims.SigningKeys...); err != nil {
+			return nil, err
+		}
+		found = true
+		operatorName := operatorClaims.Name
+		operators = append(operators, nscStoreKey{Kind: "operator", Role: "identity", Operator: operatorName, Name: operatorName, PublicKey: operatorClaims.Subject})
+		for _, signingKey := range operatorClaims.SigningKeys {
+			operators = append(operators, nscStoreKey{Kind: "operator", Role: "signing_key", Operator: operatorName, Name: operatorName, PublicKey: signingKey})
+		}
+
+		accountJWTs, err := filepath.Glob(filepath.Join(operatorPath, "accounts", "*", "*.jwt"))
+		if err != nil {
+			return nil, err
+		}
+		for _, accountJWT := range accountJWTs {
+			token, err := os.ReadFile(accountJWT)
+			if err != nil {
+				return nil, err
+			}
+			accountClaims, err := jwt.DecodeAccountClaims(strings.TrimSpace(string(token)))
+			if err != nil {
+				return nil, fmt.Errorf("invalid account JWT %s: %w", accountJWT, err)
+			}
+			if err := checkStorePublicKeys(accountJWT, nkeys.PrefixByteAccount, accountClaims.Subject, accountClaims.SigningKeys.Keys()...); err != nil {
+				return nil, err
+			}
+			accountName := accountClaims.Name
+			accounts = append(accounts, nscStoreKey{Kind: "account", Role: "identity", Operator: operatorName, Account: accountName, Name: accountName, PublicKey: accountClaims.Subject})
+			for _, signingKey := range accountClaims.SigningKeys.Keys() {
+				accounts = append(accounts, nscStoreKey{Kind: "account", Role: "signing_key", Operator: operatorName, Account: accountName, Name: accountName, PublicKey: signingKey})
+			}
+
+			userJWTs, err := filepath.Glob(filepath.Join(filepath.Dir(accountJWT), "users", "*.jwt"))
+			if err != nil {
+				return nil, err
+			}
+			for _, userJWT := range userJWTs {
+				token, err := os.ReadFile(userJWT)
+				if err != nil {
+					return nil, err
+				}
+				userClaims, err := jwt.DecodeUserClaims(strings.TrimSpace(string(token)))
+				if err != nil {
+					return nil, fmt.Errorf("invalid user JWT %s: %w", userJWT, err)
+				}
+				if err := checkStorePublicKeys(userJWT, nkeys.PrefixByteUser, userClaims.Subject); err != nil {
+					return nil, err
+				}
+				users = append(users, nscStoreKey{Kind: "user", Role: "identity", Operator: operatorName, Account: accountName, Name: userClaims.Name, PublicKey: userClaims.Subject})
+			}
+		}
+	}
+	if operator != "" && !found {
+		return nil, fmt.Errorf("operator %q not found in %s", operator, storesDir)
+	}
+
+	keys := make([]nscStoreKey, 0, len(operators)+len(accounts)+len(users))
+	for _, group := range [][]nscStoreKey{operators, accounts, users} {
+		sort.SliceStable(group, func(i, j int) bool {
+			a, b := group[i], group[j]
+			if a.Operator != b.Operator {
+				return a.Operator < b.Operator
+			}
+			if a.Account != b.Account {
+				return a.Account < b.Account
+			}
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+			// Identity keys before signing keys
+			if a.Role != b.Role {
+				return a.Role == "identity"
+			}
+			return a.PublicKey < b.PublicKey
+		})
+		keys = append(keys, group...)
+	}
+
+	names := make(map[string]bool, len(keys))
+	for i := range keys {
+		key := &keys[i]
+		seedPath := nscKeystorePath(keysDir, key.PublicKey)
+		if _, err := os.Stat(seedPath); err == nil {
+			key.SeedPath = seedPath
+		}
+		key.ResourceName = uniqueResourceName(storeKeyResourceName(*key), key.PublicKey, names)
+	}
+
+	return keys, nil
+}
+
+// checkStorePublicKeys checks that the subject and signing keys of a store
+// JWT are public keys of the given type. Decoding the JWT does not check
+// them, and resource names and keystore paths are derived from them.
+func checkStorePublicKeys(file string, prefix nkeys.PrefixByte, subject string, signingKeys ...string) error {
+	for _, publicKey := range append([]string{subject}, signingKeys...) {
+		if !nkeys.IsValidPublicKey(publicKey) || nkeys.Prefix(publicKey) != prefix {
+			return fmt.Errorf("invalid JWT %s: %q is not a valid %s public key", file, publicKey, prefix)
+		}
+	}
+	return nil
+}
+
+var resourceNameInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// storeKeyResourceName derives a resource name from the kind and names of a
+// key, e.g. user_app_service for the user service of the account app. Signing
+// keys are suffixed with the start of their public key.
+func storeKeyResourceName(key nscStoreKey) string {
+	parts := []string{key.Kind}
+	if key.Kind == "user" {
+		parts = append(parts, key.Account)
+	}
+	parts = append(parts, key.Name)
+	if key.Role == "signing_key" {
+		parts = append(parts, "sk", key.PublicKey[1:7])
+	}
+	for i, part := range parts {
+		parts[i] = strings.Trim(resourceNameInvalidChars.ReplaceAllString(strings.ToLower(part), "_"), "_")
+	}
+	return strings.Join(parts, "_")
+}
+
+// uniqueResourceName returns name, suffixed with the start of the public key
+// when it is already taken, and marks the result as taken. The same public
+// key listed twice gets a counter on top.
+func uniqueResourceName(name, publicKey string, taken map[string]bool) string {
+	if taken[name] {
+		name += "_" + strings.ToLower(publicKey[1:7])
+	}
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// renderImportBlocks renders an import block for every key with a seed.
+func renderImportBlocks(keys []nscStoreKey) string {
+	var b strings.Builder
+	for _, key := range keys {
+		if key.SeedPath == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# %s %s %s\n", key.Kind, key.Name, strings.ReplaceAll(key.Role, "_", " "))
+		b.WriteString("import {\n")
+		fmt.Fprintf(&b, "  to = nsc_nkey.%s\n", key.ResourceName)
+		fmt.Fprintf(&b, "  id = trimspace(file(%q))\n", key.SeedPath)
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
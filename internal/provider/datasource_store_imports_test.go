@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// testNSCStore is an nsc store and keystore in temporary directories.
+type testNSCStore struct {
+	t         *testing.T
+	storesDir string
+	keysDir   string
+}
+
+func newTestNSCStore(t *testing.T) *testNSCStore {
+	t.Helper()
+	return &testNSCStore{t: t, storesDir: t.TempDir(), keysDir: t.TempDir()}
+}
+
+// write stores a JWT at the path relative to the stores directory.
+func (s *testNSCStore) write(rel string, token string) {
+	s.t.Helper()
+	file := filepath.Join(s.storesDir, rel)
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		s.t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(token), 0o600); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// key creates a key pair and stores its seed in the keystore.
+func (s *testNSCStore) key(create func() (nkeys.KeyPair, error)) (nkeys.KeyPair, string) {
+	s.t.Helper()
+	kp, _ := create()
+	pub, _ := kp.PublicKey()
+	seed, _ := kp.Seed()
+	file := filepath.Join(s.keysDir, "keys", pub[:1], pub[1:3], pub+".nk")
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		s.t.Fatal(err)
+	}
+	if err := os.WriteFile(file, seed, 0o600); err != nil {
+		s.t.Fatal(err)
+	}
+	return kp, pub
+}
+
+func (s *testNSCStore) seedPath(pub string) string {
+	return filepath.Join(s.keysDir, "keys", pub[:1], pub[1:3], pub+".nk")
+}
+
+func TestScanNSCStore(t *testing.T) {
+	store := newTestNSCStore(t)
+
+	operatorKP, operatorPub := store.key(nkeys.CreateOperator)
+	_, operatorSK := store.key(nkeys.CreateOperator)
+	operatorClaims := jwt.NewOperatorClaims(operatorPub)
+	operatorClaims.Name = "Acme"
+	operatorClaims.SigningKeys.Add(operatorSK)
+	operatorJWT, _ := operatorClaims.Encode(operatorKP)
+	store.write("Acme/Acme.jwt", operatorJWT+"\n")
+
+	appKP, appPub := store.key(nkeys.CreateAccount)
+	// The seed of the signing key is kept elsewhere
+	appSKKP, _ := nkeys.CreateAccount()
+	appSK, _ := appSKKP.PublicKey()
+	appClaims := jwt.NewAccountClaims(appPub)
+	appClaims.Name = "App"
+	appClaims.SigningKeys.Add(appSK)
+	appJWT, _ := appClaims.Encode(operatorKP)
+	store.write("Acme/accounts/App/App.jwt", appJWT)
+
+	_, sysPub := store.key(nkeys.CreateAccount)
+	sysClaims := jwt.NewAccountClaims(sysPub)
+	sysClaims.Name = "SYS"
+	sysJWT, _ := sysClaims.Encode(operatorKP)
+	store.write("Acme/accounts/SYS/SYS.jwt", sysJWT)
+
+	var userPubs []string
+	for _, name := range []string{"web-api", "web_api"} {
+		_, userPub := store.key(nkeys.CreateUser)
+		userClaims := jwt.NewUserClaims(userPub)
+		userClaims.Name = name
+		userJWT, _ := userClaims.Encode(appKP)
+		store.write("Acme/accounts/App/users/"+name+".jwt", userJWT)
+		userPubs = append(userPubs, userPub)
+	}
+
+	// Directories without an operator JWT are not stores
+	if err := os.MkdirAll(filepath.Join(store.storesDir, "scratch"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := scanNSCStore(store.storesDir, store.keysDir, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []nscStoreKey{
+		{Kind: "operator", Role: "identity", Operator: "Acme", Name: "Acme", PublicKey: operatorPub, SeedPath: store.seedPath(operatorPub), ResourceName: "operator_acme"},
+		{Kind: "operator", Role: "signing_key", Operator: "Acme", Name: "Acme", PublicKey: operatorSK, SeedPath: store.seedPath(operatorSK), ResourceName: "operator_acme_sk_" + strings.ToLower(operatorSK[1:7])},
+		{Kind: "account", Role: "identity", Operator: "Acme", Account: "App", Name: "App", PublicKey: appPub, SeedPath: store.seedPath(appPub), ResourceName: "account_app"},
+		{Kind: "account", Role: "signing_key", Operator: "Acme", Account: "App", Name: "App", PublicKey: appSK, ResourceName: "account_app_sk_" + strings.ToLower(appSK[1:7])},
+		{Kind: "account", Role: "identity", Operator: "Acme", Account: "SYS", Name: "SYS", PublicKey: sysPub, SeedPath: store.seedPath(sysPub), ResourceName: "account_sys"},
+		{Kind: "user", Role: "identity", Operator: "Acme", Account: "App", Name: "web-api", PublicKey: userPubs[0], SeedPath: store.seedPath(userPubs[0]), ResourceName: "user_app_web_api"},
+		{Kind: "user", Role: "identity", Operator: "Acme", Account: "App", Name: "web_api", PublicKey: userPubs[1], SeedPath: store.seedPath(userPubs[1]), ResourceName: "user_app_web_api_" + strings.ToLower(userPubs[1][1:7])},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("unexpected keys:\nexpected %+v\ngot      %+v", want, keys)
+	}
+
+	blocks := renderImportBlocks(keys)
+	if strings.Count(blocks, "import {") != 6 {
+		t.Errorf("expected 6 import blocks, got:\n%s", blocks)
+	}
+	if strings.Contains(blocks, appSK) {
+		t.Errorf("expected no import block for the signing key without seed, got:\n%s", blocks)
+	}
+	wantBlock := fmt.Sprintf("# account App identity\nimport {\n  to = nsc_nkey.account_app\n  id = trimspace(file(%q))\n}\n", store.seedPath(appPub))
+	if !strings.Contains(blocks, wantBlock) {
+		t.Errorf("expected import block\n%s\ngot:\n%s", wantBlock, blocks)
+	}
+
+	if _, err := scanNSCStore(store.storesDir, store.keysDir, "Other"); err == nil || !strings.Contains(err.Error(), `operator "Other" not found`) {
+		t.Errorf("expected operator not found error, got %v", err)
+	}
+}
+
+func TestScanNSCStore_invalidKeys(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPub, _ := operatorKP.PublicKey()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPub, _ := accountKP.PublicKey()
+
+	// A hand-edited operator JWT with a short signing key
+	store := newTestNSCStore(t)
+	operatorClaims := jwt.NewOperatorClaims(operatorPub)
+	operatorClaims.Name = "Acme"
+	operatorClaims.SigningKeys.Add("OAB")
+	operatorJWT, err := operatorClaims.Encode(operatorKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.write("Acme/Acme.jwt", operatorJWT)
+
+	_, err = scanNSCStore(store.storesDir, store.keysDir, "")
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(store.storesDir, "Acme", "Acme.jwt")) || !strings.Contains(err.Error(), `"OAB"`) {
+		t.Errorf("expected an error naming the operator JWT and the key, got %v", err)
+	}
+
+	// An account signing key of the wrong type
+	store = newTestNSCStore(t)
+	operatorClaims = jwt.NewOperatorClaims(operatorPub)
+	operatorClaims.Name = "Acme"
+	operatorJWT, _ = operatorClaims.Encode(operatorKP)
+	store.write("Acme/Acme.jwt", operatorJWT)
+	accountClaims := jwt.NewAccountClaims(accountPub)
+	accountClaims.Name = "App"
+	accountClaims.SigningKeys.Add(operatorPub)
+	accountJWT, err := accountClaims.Encode(operatorKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.write("Acme/accounts/App/App.jwt", accountJWT)
+
+	_, err = scanNSCStore(store.storesDir, store.keysDir, "")
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(store.storesDir, "Acme", "accounts", "App", "App.jwt")) || !strings.Contains(err.Error(), "not a valid account public key") {
+		t.Errorf("expected an error naming the account JWT, got %v", err)
+	}
+}
+
+func TestUniqueResourceName(t *testing.T) {
+	taken := make(map[string]bool)
+	got := []string{
+		uniqueResourceName("user_app_svc", "UABCDEFGH", taken),
+		uniqueResourceName("user_app_svc", "UXYZXYZXY", taken),
+		uniqueResourceName("user_app_svc", "UXYZXYZXY", taken),
+	}
+	want := []string{"user_app_svc", "user_app_svc_xyzxyz", "user_app_svc_xyzxyz_2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestAccStoreImportsDataSource(t *testing.T) {
+	store := newTestNSCStore(t)
+	operatorKP, operatorPub := store.key(nkeys.CreateOperator)
+	operatorClaims := jwt.NewOperatorClaims(operatorPub)
+	operatorClaims.Name = "Acme"
+	operatorJWT, _ := operatorClaims.Encode(operatorKP)
+	store.write("Acme/Acme.jwt", operatorJWT)
+
+	_, accountPub := store.key(nkeys.CreateAccount)
+	accountClaims := jwt.NewAccountClaims(accountPub)
+	accountClaims.Name = "App"
+	accountJWT, _ := accountClaims.Encode(operatorKP)
+	store.write("Acme/accounts/App/App.jwt", accountJWT)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+data "nsc_store_imports" "test" {
+  stores_dir = %q
+  keys_dir   = %q
+}
+`, store.storesDir, store.keysDir),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.nsc_store_imports.test", "keys.#", "2"),
+					resource.TestCheckResourceAttr("data.nsc_store_imports.test", "keys.0.address", "nsc_nkey.operator_acme"),
+					resource.TestCheckResourceAttr("data.nsc_store_imports.test", "keys.0.public_key", operatorPub),
+					resource.TestCheckNoResourceAttr("data.nsc_store_imports.test", "keys.0.account"),
+					resource.TestCheckResourceAttr("data.nsc_store_imports.test", "keys.1.address", "nsc_nkey.account_app"),
+					resource.TestCheckResourceAttr("data.nsc_store_imports.test", "keys.1.seed_path", store.seedPath(accountPub)),
+					resource.TestCheckResourceAttrWith("data.nsc_store_imports.test", "import_blocks", func(value string) error {
+						if strings.Count(value, "import {") != 2 {
+							return fmt.Errorf("expected 2 import blocks, got:\n%s", value)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
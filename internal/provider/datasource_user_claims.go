@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/nkeys"
+)
+
+var _ datasource.DataSource = &UserClaimsDataSource{}
+var _ datasource.DataSourceWithValidateConfig = &UserClaimsDataSource{}
+
+func NewUserClaimsDataSource() datasource.DataSource {
+	return &UserClaimsDataSource{}
+}
+
+type UserClaimsDataSource struct{}
+
+type UserClaimsDataSourceModel struct {
+	ID     types.String `tfsdk:"id"`
+	Issuer types.String `tfsdk:"issuer"`
+
+	UserClaimsModel
+
+	ClaimsJSON types.String `tfsdk:"claims_json"`
+}
+
+func (d *UserClaimsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_claims"
+}
+
+func (d *UserClaimsDataSource) Schema(ctx context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	var resourceSchema resource.SchemaResponse
+	(&UserResource{}).Schema(ctx, resource.SchemaRequest{}, &resourceSchema)
+
+	attributes, blocks := claimsDataSourceSchema(resourceSchema.Schema, UserClaimsModel{})
+
+	issuerAccount := attributes["issuer_account"].(schema.StringAttribute)
+	issuerAccount.MarkdownDescription = "Account public key (subject) when `issuer` is a signing key. If not provided, derived from `issuer`."
+	attributes["issuer_account"] = issuerAccount
+
+	attributes["id"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "User public key (same as subject)",
+	}
+	attributes["issuer"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Account or account signing key public key to record as the issuer. Left empty when not set. When `issuer_account` is not set, it is derived from this key.",
+		Validators: []validator.String{
+			stringvalidator.RegexMatches(
+				regexp.MustCompile(`^A[A-Z2-7]{55}$`),
+				"must be a valid account public key starting with 'A'",
+			),
+		},
+	}
+	attributes["claims_json"] = schema.StringAttribute{
+		Computed:            true,
+		MarkdownDescription: "Unsigned user claims in JSON format, as they would be encoded into the user JWT",
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Builds NATS user claims from the same inputs as the `nsc_user` resource without signing them. Useful for reviewing what would be issued without access to the account seed.",
+		Attributes:          attributes,
+		Blocks:              blocks,
+	}
+}
+
+func (d *UserClaimsDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data UserClaimsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.validate()...)
+	resp.Diagnostics.Append(data.validateLimits()...)
+	if !data.Issuer.IsNull() && !data.Issuer.IsUnknown() {
+		resp.Diagnostics.Append(data.validateIssuer(data.Issuer.ValueString())...)
+	}
+}
+
+func (d *UserClaimsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data UserClaimsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	userPubKey := data.Subject.ValueString()
+	if !nkeys.IsValidPublicUserKey(userPubKey) {
+		resp.Diagnostics.AddError(
+			"Invalid user public key",
+			fmt.Sprintf("Subject must be a valid user public key, got: %s", userPubKey),
+		)
+		return
+	}
+
+	// Mirror nsc_user: issuer_account defaults to the issuer
+	if data.IssuerAccount.IsNull() && !data.Issuer.IsNull() {
+		data.IssuerAccount = data.Issuer
+	}
+
+	userClaims, diags := buildUserClaims(ctx, &data.UserClaimsModel)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	userClaims.Issuer = data.Issuer.ValueString()
+
+	claimsJSON, err := json.Marshal(userClaims)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode user claims", err.Error())
+		return
+	}
+	claimsJSON, err = mergeCustomClaims(claimsJSON, data.CustomClaimsJSON)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to merge custom claims", err.Error())
+		return
+	}
+
+	data.ID = types.StringValue(userPubKey)
+	data.ClaimsJSON = types.StringValue(string(claimsJSON))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
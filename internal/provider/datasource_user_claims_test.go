@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccUserClaimsDataSource_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserClaimsDataSourceConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.nsc_user_claims.test", "id", "nsc_nkey.user", "public_key"),
+					resource.TestCheckResourceAttrPair("data.nsc_user_claims.test", "issuer_account", "nsc_nkey.account", "public_key"),
+					resource.TestMatchResourceAttr("data.nsc_user_claims.test", "claims_json", regexp.MustCompile(`"name":"TestUser"`)),
+					resource.TestMatchResourceAttr("data.nsc_user_claims.test", "claims_json", regexp.MustCompile(`"bearer_token":true`)),
+					resource.TestMatchResourceAttr("data.nsc_user_claims.test", "claims_json", regexp.MustCompile(`"deny":\["admin\.>"\]`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserClaimsDataSource_invalidSubject(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+data "nsc_user_claims" "test" {
+  name    = "TestUser"
+  subject = "ABADKEY"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid user public key`),
+			},
+		},
+	})
+}
+
+func testAccUserClaimsDataSourceConfig() string {
+	return `
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+data "nsc_user_claims" "test" {
+  name     = "TestUser"
+  subject  = nsc_nkey.user.public_key
+  issuer   = nsc_nkey.account.public_key
+  bearer   = true
+  deny_pub = ["admin.>"]
+}
+`
+}
+
+func TestUserClaimsDataSource_read(t *testing.T) {
+	ctx := context.Background()
+
+	accountKP, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountPubKey, _ := accountKP.PublicKey()
+	signingKP, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingPubKey, _ := signingKP.PublicKey()
+	userKP, err := nkeys.CreateUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	userPubKey, _ := userKP.PublicKey()
+
+	config, err := json.Marshal(map[string]any{
+		"name":           "TestUser",
+		"subject":        userPubKey,
+		"issuer":         signingPubKey,
+		"issuer_account": accountPubKey,
+		"tag":            []string{"team:a"},
+		"max_payload":    1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimsJSON := testReadClaimsDataSource(ctx, t, "nsc_user_claims", config)
+
+	var claims jwt.UserClaims
+	if err := json.Unmarshal([]byte(claimsJSON), &claims); err != nil {
+		t.Fatalf("failed to decode claims_json: %s", err)
+	}
+	if claims.Subject != userPubKey {
+		t.Errorf("expected subject %s, got %s", userPubKey, claims.Subject)
+	}
+	if claims.Issuer != signingPubKey {
+		t.Errorf("expected issuer %s, got %s", signingPubKey, claims.Issuer)
+	}
+	if claims.IssuerAccount != accountPubKey {
+		t.Errorf("expected issuer_account %s, got %s", accountPubKey, claims.IssuerAccount)
+	}
+	if claims.Limits.Payload != 1024 {
+		t.Errorf("expected payload limit 1024, got %d", claims.Limits.Payload)
+	}
+	if len(claims.Tags) != 1 || claims.Tags[0] != "team:a" {
+		t.Errorf("unexpected tags: %v", claims.Tags)
+	}
+}
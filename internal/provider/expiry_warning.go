@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// expiryWarning warns when a JWT expires within the window set by the
+// provider's warn_expiry_within, so expiring credentials show up in routine
+// plans. A zero window or a JWT without expiry produces no warning.
+func expiryWarning(kind, name, subject string, expiresAt timetypes.RFC3339, window time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if window <= 0 || expiresAt.IsNull() || expiresAt.IsUnknown() {
+		return diags
+	}
+	expires, d := expiresAt.ValueRFC3339Time()
+	if d.HasError() {
+		return diags
+	}
+
+	remaining := time.Until(expires)
+	if remaining > window {
+		return diags
+	}
+
+	if remaining <= 0 {
+		diags.AddWarning(
+			"JWT expired",
+			fmt.Sprintf("The %s JWT of %q (%s) expired at %s, %s ago.", kind, name, subject, expires.UTC().Format(time.RFC3339), (-remaining).Round(time.Minute)),
+		)
+		return diags
+	}
+
+	diags.AddWarning(
+		"JWT expiring soon",
+		fmt.Sprintf("The %s JWT of %q (%s) expires at %s, in %s.", kind, name, subject, expires.UTC().Format(time.RFC3339), remaining.Round(time.Minute)),
+	)
+	return diags
+}
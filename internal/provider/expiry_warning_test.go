@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestExpiryWarning(t *testing.T) {
+	window := 72 * time.Hour
+
+	tests := []struct {
+		name      string
+		expiresAt timetypes.RFC3339
+		window    time.Duration
+		summary   string
+		detail    string
+	}{
+		{
+			name:      "no expiry",
+			expiresAt: timetypes.NewRFC3339Null(),
+			window:    window,
+		},
+		{
+			name:      "disabled",
+			expiresAt: timetypes.NewRFC3339TimeValue(time.Now().Add(time.Hour)),
+		},
+		{
+			name:      "outside window",
+			expiresAt: timetypes.NewRFC3339TimeValue(time.Now().Add(100 * time.Hour)),
+			window:    window,
+		},
+		{
+			name:      "within window",
+			expiresAt: timetypes.NewRFC3339TimeValue(time.Now().Add(48*time.Hour + 30*time.Second)),
+			window:    window,
+			summary:   "JWT expiring soon",
+			detail:    `The user JWT of "app" (UABC) expires at`,
+		},
+		{
+			name:      "expired",
+			expiresAt: timetypes.NewRFC3339TimeValue(time.Now().Add(-2 * time.Hour)),
+			window:    window,
+			summary:   "JWT expired",
+			detail:    "2h0m0s ago",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := expiryWarning("user", "app", "UABC", tt.expiresAt, tt.window)
+			if tt.summary == "" {
+				if len(diags) != 0 {
+					t.Errorf("expected no diagnostics, got %v", diags)
+				}
+				return
+			}
+			if len(diags) != 1 || diags[0].Severity() != diag.SeverityWarning {
+				t.Fatalf("expected one warning, got %v", diags)
+			}
+			if diags[0].Summary() != tt.summary || !strings.Contains(diags[0].Detail(), tt.detail) {
+				t.Errorf("unexpected warning: %s: %s", diags[0].Summary(), diags[0].Detail())
+			}
+		})
+	}
+}
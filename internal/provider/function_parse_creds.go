@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ function.Function = &ParseCredsFunction{}
+
+func NewParseCredsFunction() function.Function {
+	return &ParseCredsFunction{}
+}
+
+type ParseCredsFunction struct{}
+
+// parseCredsAttributeTypes are the attributes of the parse_creds result.
+var parseCredsAttributeTypes = map[string]attr.Type{
+	"jwt":  types.StringType,
+	"seed": types.StringType,
+}
+
+func (f *ParseCredsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_creds"
+}
+
+func (f *ParseCredsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Split a NATS credentials file into its JWT and seed",
+		MarkdownDescription: "Splits the content of a NATS credentials file, e.g. one kept in a secret manager, into an object with `jwt` and `seed` attributes. The JWT must decode and the seed must belong to the JWT subject. The result is only sensitive when the argument is, so pass the credentials as a sensitive value to keep the seed out of plan output.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "creds",
+				MarkdownDescription: "Content of a credentials file",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: parseCredsAttributeTypes,
+		},
+	}
+}
+
+func (f *ParseCredsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var creds string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &creds))
+	if resp.Error != nil {
+		return
+	}
+
+	token, seed, err := parseCreds([]byte(creds))
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	result, diags := types.ObjectValue(parseCredsAttributeTypes, map[string]attr.Value{
+		"jwt":  types.StringValue(token),
+		"seed": types.StringValue(seed),
+	})
+	resp.Error = function.ConcatFuncErrors(resp.Error, function.FuncErrorFromDiags(ctx, diags))
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
+
+// parseCreds returns the JWT and seed of a credentials file. Errors never
+// include the seed.
+func parseCreds(content []byte) (string, string, error) {
+	token, err := jwt.ParseDecoratedJWT(content)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the JWT: %s", err)
+	}
+	claims, err := jwt.Decode(token)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode the JWT: %s", err)
+	}
+
+	kp, err := jwt.ParseDecoratedNKey(content)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the seed: %s", err)
+	}
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get the public key of the seed: %s", err)
+	}
+	if subject := claims.Claims().Subject; publicKey != subject {
+		return "", "", fmt.Errorf("the seed belongs to %s, but the JWT was issued for %s", publicKey, subject)
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the seed: %s", err)
+	}
+
+	return token, string(seed), nil
+}
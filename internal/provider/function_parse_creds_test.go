@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccParseCredsFunction_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccParseCredsFunctionConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("jwt_matches", "true"),
+					resource.TestCheckOutput("seed_matches", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccParseCredsFunction_invalid(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "creds" {
+  value = provider::nsc::parse_creds("not a credentials file")
+}
+`,
+				ExpectError: regexp.MustCompile("failed to decode the JWT"),
+			},
+		},
+	})
+}
+
+const testAccParseCredsFunctionConfig = `
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_operator" "test" {
+  name        = "TestOperator"
+  subject     = nsc_nkey.operator.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_user" "test" {
+  name        = "TestUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  seed        = nsc_nkey.user.seed
+}
+
+locals {
+  creds = provider::nsc::parse_creds(nsc_user.test.creds)
+}
+
+output "jwt_matches" {
+  value     = local.creds.jwt == nsc_user.test.jwt
+  sensitive = true
+}
+
+output "seed_matches" {
+  value     = local.creds.seed == nsc_nkey.user.seed
+  sensitive = true
+}
+`
+
+func TestParseCreds(t *testing.T) {
+	accountKP, _ := nkeys.CreateAccount()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	userSeed, _ := userKP.Seed()
+
+	userJWT, err := jwt.NewUserClaims(userPubKey).Encode(accountKP)
+	if err != nil {
+		t.Fatalf("failed to encode user JWT: %v", err)
+	}
+
+	token, seed, err := parseCreds([]byte(formatCreds(userJWT, string(userSeed))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != userJWT {
+		t.Errorf("expected the user JWT, got %q", token)
+	}
+	if seed != string(userSeed) {
+		t.Errorf("expected the user seed")
+	}
+
+	otherKP, _ := nkeys.CreateUser()
+	otherSeed, _ := otherKP.Seed()
+	_, _, err = parseCreds([]byte(formatCreds(userJWT, string(otherSeed))))
+	if err == nil || !strings.Contains(err.Error(), "but the JWT was issued for") {
+		t.Errorf("expected a seed mismatch error, got %v", err)
+	}
+	if err != nil && strings.Contains(err.Error(), string(otherSeed)) {
+		t.Errorf("error must not include the seed")
+	}
+
+	if _, _, err := parseCreds([]byte(userJWT)); err == nil {
+		t.Errorf("expected an error for credentials without a seed")
+	}
+}
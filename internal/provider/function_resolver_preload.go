@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ function.Function = &ResolverPreloadFunction{}
+
+func NewResolverPreloadFunction() function.Function {
+	return &ResolverPreloadFunction{}
+}
+
+type ResolverPreloadFunction struct{}
+
+func (f *ResolverPreloadFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "resolver_preload"
+}
+
+func (f *ResolverPreloadFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Render a nats-server resolver_preload block",
+		MarkdownDescription: "Renders the `resolver_preload` block of a nats-server configuration from a map of account public keys to account JWTs. Entries are sorted by account public key so the output is stable across runs.",
+		Parameters: []function.Parameter{
+			function.MapParameter{
+				Name:                "jwts",
+				ElementType:         types.StringType,
+				MarkdownDescription: "Map of account public keys to account JWTs",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *ResolverPreloadFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var jwts map[string]string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &jwts))
+	if resp.Error != nil {
+		return
+	}
+
+	preload, err := formatResolverPreload(jwts)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, preload))
+}
+
+// formatResolverPreload renders the resolver_preload block for nats-server.
+// Each JWT must be an account JWT issued for the public key it is keyed by.
+func formatResolverPreload(jwts map[string]string) (string, error) {
+	keys := make([]string, 0, len(jwts))
+	for key, token := range jwts {
+		if !nkeys.IsValidPublicAccountKey(key) {
+			return "", fmt.Errorf("invalid account public key %q", key)
+		}
+		claims, err := jwt.DecodeAccountClaims(token)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode account JWT for %s: %s", key, err)
+		}
+		if claims.Subject != key {
+			return "", fmt.Errorf("account JWT for %s was issued for %s", key, claims.Subject)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("resolver_preload: {\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "  %s: %q\n", key, jwts[key])
+	}
+	b.WriteString("}\n")
+
+	return b.String(), nil
+}
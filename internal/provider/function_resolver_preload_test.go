@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccResolverPreloadFunction_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccResolverPreloadFunctionConfig(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchOutput("preload", regexp.MustCompile(`^resolver_preload: \{\n  A[A-Z0-9]{55}: "eyJ[^"]+"\n\}\n$`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResolverPreloadFunction_mismatchedKey(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "other" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+output "preload" {
+  value = provider::nsc::resolver_preload({
+    (nsc_nkey.other.public_key) = nsc_account.test.jwt
+  })
+}
+`,
+				ExpectError: regexp.MustCompile("was issued for"),
+			},
+		},
+	})
+}
+
+func testAccResolverPreloadFunctionConfig() string {
+	return `
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+output "preload" {
+  value = provider::nsc::resolver_preload({
+    (nsc_account.test.public_key) = nsc_account.test.jwt
+  })
+}
+`
+}
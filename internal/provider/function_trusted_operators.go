@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ function.Function = &TrustedOperatorsFunction{}
+
+func NewTrustedOperatorsFunction() function.Function {
+	return &TrustedOperatorsFunction{}
+}
+
+type TrustedOperatorsFunction struct{}
+
+func (f *TrustedOperatorsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "trusted_operators"
+}
+
+func (f *TrustedOperatorsFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:             "Render the trusted operators of a nats-server configuration",
+		MarkdownDescription: "Renders the `operator` entry of a nats-server configuration from a list of operator JWTs, so a cluster can trust more than one operator, e.g. while migrating accounts from one operator to another. Operators are rendered in the given order. When the operators name a system account, a `system_account` entry follows; nats-server requires all operators naming one to agree on it.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:                "operator_jwts",
+				ElementType:         types.StringType,
+				MarkdownDescription: "List of operator JWTs",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *TrustedOperatorsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var jwts []string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &jwts))
+	if resp.Error != nil {
+		return
+	}
+
+	config, err := formatTrustedOperators(jwts)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, config))
+}
+
+// formatTrustedOperators renders the operator and system_account entries for
+// nats-server. Each JWT must be a distinct operator JWT, and operators that
+// name a system account must name the same one.
+func formatTrustedOperators(jwts []string) (string, error) {
+	if len(jwts) == 0 {
+		return "", fmt.Errorf("at least one operator JWT is required")
+	}
+
+	seen := make(map[string]bool, len(jwts))
+	var systemAccount string
+	for i, token := range jwts {
+		claims, err := jwt.DecodeOperatorClaims(token)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode operator JWT at index %d: %s", i, err)
+		}
+		if seen[claims.Subject] {
+			return "", fmt.Errorf("operator %s is listed more than once", claims.Subject)
+		}
+		seen[claims.Subject] = true
+
+		if claims.SystemAccount == "" {
+			continue
+		}
+		if systemAccount != "" && claims.SystemAccount != systemAccount {
+			return "", fmt.Errorf("operator %s names system account %s, but another operator names %s", claims.Subject, claims.SystemAccount, systemAccount)
+		}
+		systemAccount = claims.SystemAccount
+	}
+
+	var b strings.Builder
+	b.WriteString("operator: [\n")
+	for _, token := range jwts {
+		fmt.Fprintf(&b, "  %q\n", token)
+	}
+	b.WriteString("]\n")
+	if systemAccount != "" {
+		fmt.Fprintf(&b, "system_account: %s\n", systemAccount)
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccTrustedOperatorsFunction_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTrustedOperatorsFunctionConfig("nsc_nkey.system.public_key"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchOutput("operators", regexp.MustCompile(`^operator: \[\n  "eyJ[^"]+"\n  "eyJ[^"]+"\n\]\nsystem_account: A[A-Z0-9]{55}\n$`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTrustedOperatorsFunction_systemAccountMismatch(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTrustedOperatorsFunctionConfig("nsc_nkey.other_system.public_key"),
+				ExpectError: regexp.MustCompile("another operator names"),
+			},
+		},
+	})
+}
+
+func testAccTrustedOperatorsFunctionConfig(newSystemAccount string) string {
+	return `
+resource "nsc_nkey" "old" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "new" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "system" {
+  type = "account"
+}
+
+resource "nsc_nkey" "other_system" {
+  type = "account"
+}
+
+resource "nsc_operator" "old" {
+  name           = "OldOperator"
+  subject        = nsc_nkey.old.public_key
+  issuer_seed    = nsc_nkey.old.seed
+  system_account = nsc_nkey.system.public_key
+}
+
+resource "nsc_operator" "new" {
+  name           = "NewOperator"
+  subject        = nsc_nkey.new.public_key
+  issuer_seed    = nsc_nkey.new.seed
+  system_account = ` + newSystemAccount + `
+}
+
+output "operators" {
+  value = provider::nsc::trusted_operators([nsc_operator.old.jwt, nsc_operator.new.jwt])
+}
+`
+}
+
+func TestFormatTrustedOperators(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	operatorJWT, _ := jwt.NewOperatorClaims(operatorPubKey).Encode(operatorKP)
+
+	config, err := formatTrustedOperators([]string{operatorJWT})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config != "operator: [\n  \""+operatorJWT+"\"\n]\n" {
+		t.Errorf("unexpected config: %q", config)
+	}
+
+	_, err = formatTrustedOperators([]string{operatorJWT, operatorJWT})
+	if err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("expected duplicate error, got %v", err)
+	}
+
+	_, err = formatTrustedOperators(nil)
+	if err == nil {
+		t.Error("expected error for empty list")
+	}
+}
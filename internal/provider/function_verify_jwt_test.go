@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccVerifyJWTFunction_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "other" {
+  type = "operator"
+}
+
+resource "nsc_operator" "test" {
+  name        = "TestOperator"
+  subject     = nsc_nkey.operator.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+output "valid" {
+  value = provider::nsc::verify_jwt(nsc_operator.test.jwt, nsc_nkey.operator.public_key)
+}
+
+output "other_issuer" {
+  value = provider::nsc::verify_jwt(nsc_operator.test.jwt, nsc_nkey.other.public_key)
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckOutput("valid", "true"),
+					resource.TestCheckOutput("other_issuer", "false"),
+				),
+			},
+			{
+				Config: `
+output "invalid" {
+  value = provider::nsc::verify_jwt("not-a-jwt", "ODYWI4E5Q732IDQEFK6CGCNKHMOZ2JOEBGMJYITXTPHYISM6E2ZB67EF")
+}
+`,
+				ExpectError: regexp.MustCompile("expected 3 JWT segments"),
+			},
+		},
+	})
+}
+
+func TestVerifyJWT(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	otherKP, _ := nkeys.CreateOperator()
+	otherPubKey, _ := otherKP.PublicKey()
+
+	claims := jwt.NewOperatorClaims(operatorPubKey)
+	claims.Name = "original"
+	token, err := claims.Encode(operatorKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Swap the payload for one with another name, keeping the signature
+	claims.Name = "tampered"
+	otherToken, err := claims.Encode(operatorKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + strings.Split(otherToken, ".")[1] + "." + parts[2]
+
+	tests := []struct {
+		name    string
+		token   string
+		issuer  string
+		kp      nkeys.KeyPair
+		want    bool
+		wantErr bool
+	}{
+		{name: "valid", token: token, issuer: operatorPubKey, kp: operatorKP, want: true},
+		{name: "other issuer", token: token, issuer: otherPubKey, kp: otherKP, want: false},
+		{name: "tampered payload", token: tampered, issuer: operatorPubKey, kp: operatorKP, want: false},
+		{name: "malformed", token: "not-a-jwt", issuer: operatorPubKey, kp: operatorKP, wantErr: true},
+		{name: "invalid payload", token: parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte("[]")) + "." + parts[2], issuer: operatorPubKey, kp: operatorKP, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifyJWT(tt.token, tt.issuer, tt.kp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
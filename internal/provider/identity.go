@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// PublicKeyIdentityModel is the resource identity of resources identified by
+// a single public key.
+type PublicKeyIdentityModel struct {
+	PublicKey types.String `tfsdk:"public_key"`
+}
+
+// publicKeyIdentitySchema returns the identity schema of resources
+// identified by a single public key.
+func publicKeyIdentitySchema(description string) identityschema.Schema {
+	return identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"public_key": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       description,
+			},
+		},
+	}
+}
+
+// setPublicKeyIdentity sets the identity of a resource identified by a
+// single public key. The identity is nil when Terraform does not support
+// resource identity.
+func setPublicKeyIdentity(ctx context.Context, identity *tfsdk.ResourceIdentity, publicKey types.String) diag.Diagnostics {
+	if identity == nil {
+		return nil
+	}
+	return identity.Set(ctx, PublicKeyIdentityModel{PublicKey: publicKey})
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// issuerSeedEnvAttribute returns the issuer_seed_env attribute, which names
+// the environment variable holding the issuer seed. Only the name is stored
+// in state.
+func issuerSeedEnvAttribute(description string, conflicts ...path.Expression) schema.StringAttribute {
+	validators := []validator.String{
+		stringvalidator.RegexMatches(
+			regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`),
+			"must be a valid environment variable name",
+		),
+	}
+	if len(conflicts) > 0 {
+		validators = append(validators, stringvalidator.ConflictsWith(conflicts...))
+	}
+
+	return schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: description,
+		Validators:          validators,
+	}
+}
+
+// issuerSeedValue returns the issuer seed from config, or, when issuer_seed_env
+// is set, from the environment of the provider at apply time.
+func issuerSeedValue(seed, seedEnv types.String) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if seedEnv.IsNull() || seedEnv.IsUnknown() {
+		return seed, diags
+	}
+
+	value := strings.TrimSpace(os.Getenv(seedEnv.ValueString()))
+	if value == "" {
+		diags.AddAttributeError(
+			path.Root("issuer_seed_env"),
+			"Missing issuer seed",
+			fmt.Sprintf("Environment variable %s named by issuer_seed_env is not set or empty", seedEnv.ValueString()),
+		)
+		return types.StringNull(), diags
+	}
+
+	return types.StringValue(value), diags
+}
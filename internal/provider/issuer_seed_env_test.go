@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestIssuerSeedValue(t *testing.T) {
+	// Without issuer_seed_env the configured seed is used
+	got, diags := issuerSeedValue(types.StringValue("SOCONFIGURED"), types.StringNull())
+	if diags.HasError() || got.ValueString() != "SOCONFIGURED" {
+		t.Errorf("expected configured seed, got %q, %v", got.ValueString(), diags)
+	}
+
+	t.Setenv("NSC_TEST_ISSUER_SEED", " SOFROMENV\n")
+	got, diags = issuerSeedValue(types.StringNull(), types.StringValue("NSC_TEST_ISSUER_SEED"))
+	if diags.HasError() || got.ValueString() != "SOFROMENV" {
+		t.Errorf("expected seed from environment, got %q, %v", got.ValueString(), diags)
+	}
+
+	t.Setenv("NSC_TEST_ISSUER_SEED", "")
+	_, diags = issuerSeedValue(types.StringNull(), types.StringValue("NSC_TEST_ISSUER_SEED"))
+	if !diags.HasError() {
+		t.Error("expected error for empty environment variable")
+	}
+}
+
+func TestAccIssuerSeedEnv(t *testing.T) {
+	operatorKP, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorSeed, _ := operatorKP.Seed()
+	operatorPubKey, _ := operatorKP.PublicKey()
+
+	t.Setenv("NSC_TEST_OPERATOR_SEED", string(operatorSeed))
+
+	config := fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_operator" "test" {
+  name            = "TestOperator"
+  subject         = %[1]q
+  issuer_seed_env = "NSC_TEST_OPERATOR_SEED"
+}
+
+resource "nsc_account" "test" {
+  name            = "TestAccount"
+  subject         = nsc_nkey.account.public_key
+  issuer_seed_env = "NSC_TEST_OPERATOR_SEED"
+}
+`, operatorPubKey)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "issuer_seed_env", "NSC_TEST_OPERATOR_SEED"),
+					resource.TestCheckNoResourceAttr("nsc_account.test", "issuer_seed"),
+					testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+						if claims.Issuer != operatorPubKey {
+							return fmt.Errorf("expected issuer %s, got %s", operatorPubKey, claims.Issuer)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_account" "missing" {
+  name            = "MissingAccount"
+  subject         = nsc_nkey.account.public_key
+  issuer_seed_env = "NSC_TEST_UNSET_SEED"
+}
+`,
+				ExpectError: regexp.MustCompile("NSC_TEST_UNSET_SEED"),
+			},
+		},
+	})
+}
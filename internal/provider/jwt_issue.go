@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+// pinnedIssuedAtFallback is the iat of pinned JWTs without issued_at or nbf.
+// It is not zero, as claims without iat count as revoked.
+const pinnedIssuedAtFallback = 1
+
+// pinIssuedAtAttribute returns the pin_issued_at attribute of the resources
+// issuing JWTs.
+func pinIssuedAtAttribute() schema.BoolAttribute {
+	return schema.BoolAttribute{
+		Optional: true,
+		MarkdownDescription: "Issue the JWT with a fixed `iat` instead of the current time, so the same claims signed with the same key make the same JWT byte for byte on any machine. " +
+			"The `iat` is `issued_at` when set, which pins the JWT on its own, otherwise `starts_at`, otherwise `1970-01-01T00:00:01Z`. " +
+			"Revocations apply to JWTs issued at or before the revocation time, so a pinned JWT stays revoked until `issued_at` is moved past it.",
+	}
+}
+
+// pinnedIssuedAt returns the iat to pin the JWT of claims to, from the
+// configured pin_issued_at and issued_at, or zero to issue it at the current
+// time.
+func pinnedIssuedAt(pin types.Bool, issuedAt timetypes.RFC3339, claims jwt.Claims) (int64, diag.Diagnostics) {
+	if !issuedAt.IsNull() && !issuedAt.IsUnknown() {
+		t, diags := issuedAt.ValueRFC3339Time()
+		if !diags.HasError() && t.Unix() <= 0 {
+			diags.AddAttributeError(path.Root("issued_at"), "Invalid issued_at", "issued_at must be after 1970-01-01T00:00:00Z")
+		}
+		return t.Unix(), diags
+	}
+	if !pin.ValueBool() {
+		return 0, nil
+	}
+	if notBefore := claims.Claims().NotBefore; notBefore != 0 {
+		return notBefore, nil
+	}
+	return pinnedIssuedAtFallback, nil
+}
+
+// jwtIssueValues returns the issued_at and jwt_id attribute values of a
+// signed JWT. They are decoded from the token rather than taken from the
+// encoded claims, as custom claims re-sign the token.
+func jwtIssueValues(token string) (timetypes.RFC3339, types.String, error) {
+	payload, err := jwtPayload(token)
+	if err != nil {
+		return timetypes.NewRFC3339Null(), types.StringNull(), err
+	}
+
+	var claims struct {
+		IssuedAt int64  `json:"iat"`
+		ID       string `json:"jti"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return timetypes.NewRFC3339Null(), types.StringNull(), fmt.Errorf("failed to decode JWT claims: %w", err)
+	}
+
+	return timetypes.NewRFC3339TimeValue(time.Unix(claims.IssuedAt, 0).UTC()), types.StringValue(claims.ID), nil
+}
+
+// sameJWTClaims reports whether token carries the same claims as the stored
+// JWT, ignoring iat and jti, which change on every encoding. A missing or
+// undecodable stored JWT never matches.
+func sameJWTClaims(stored, token string) bool {
+	if stored == "" {
+		return false
+	}
+
+	decode := func(token string) (map[string]any, error) {
+		payload, err := jwtPayload(token)
+		if err != nil {
+			return nil, err
+		}
+		var claims map[string]any
+		if err := json.Unmarshal(payload, &claims); err != nil {
+			return nil, err
+		}
+		delete(claims, "iat")
+		delete(claims, "jti")
+		return claims, nil
+	}
+
+	storedClaims, err := decode(stored)
+	if err != nil {
+		return false
+	}
+	claims, err := decode(token)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(storedClaims, claims)
+}
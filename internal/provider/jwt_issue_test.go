@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestJWTIssueValues(t *testing.T) {
+	kp, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := kp.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.NewOperatorClaims(pub).Encode(kp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := jwt.DecodeOperatorClaims(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuedAt, jwtID, err := jwtIssueValues(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Unix(claims.IssuedAt, 0).UTC().Format(time.RFC3339); issuedAt.ValueString() != want {
+		t.Errorf("expected issued_at %q, got %q", want, issuedAt.ValueString())
+	}
+	if jwtID.ValueString() != claims.ID {
+		t.Errorf("expected jwt_id %q, got %q", claims.ID, jwtID.ValueString())
+	}
+
+	if _, _, err := jwtIssueValues("not-a-jwt"); err == nil {
+		t.Error("expected an error for a malformed token")
+	}
+}
+
+func TestSameJWTClaims(t *testing.T) {
+	kp, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := kp.PublicKey()
+
+	encode := func(name string) string {
+		claims := jwt.NewOperatorClaims(pub)
+		claims.Name = name
+		token, err := claims.Encode(kp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	// An earlier issue of the same claims, with another iat and jti
+	token := encode("op")
+	parts := strings.Split(token, ".")
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	claims["iat"], claims["jti"] = 1, "EARLIER"
+	payload, _ = json.Marshal(claims)
+	stored := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+
+	if !sameJWTClaims(stored, token) {
+		t.Error("expected tokens differing only in iat and jti to match")
+	}
+	if sameJWTClaims(stored, encode("renamed")) {
+		t.Error("expected tokens with different names not to match")
+	}
+	if sameJWTClaims("", token) || sameJWTClaims("not-a-jwt", token) {
+		t.Error("expected a missing or malformed stored JWT not to match")
+	}
+}
+
+func TestPinnedIssuedAt(t *testing.T) {
+	claims := jwt.NewUserClaims("UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4")
+
+	for name, tt := range map[string]struct {
+		pin       types.Bool
+		issuedAt  timetypes.RFC3339
+		notBefore int64
+		want      int64
+	}{
+		"not pinned":        {types.BoolNull(), timetypes.NewRFC3339Null(), 0, 0},
+		"pinned":            {types.BoolValue(true), timetypes.NewRFC3339Null(), 0, pinnedIssuedAtFallback},
+		"pinned to nbf":     {types.BoolValue(true), timetypes.NewRFC3339Null(), 1700000000, 1700000000},
+		"explicit":          {types.BoolNull(), timetypes.NewRFC3339ValueMust("2024-01-01T00:00:00Z"), 1700000000, 1704067200},
+		"pinned explicitly": {types.BoolValue(false), timetypes.NewRFC3339ValueMust("2024-01-01T00:00:00Z"), 0, 1704067200},
+	} {
+		claims.NotBefore = tt.notBefore
+		got, diags := pinnedIssuedAt(tt.pin, tt.issuedAt, claims)
+		if diags.HasError() || got != tt.want {
+			t.Errorf("%s: expected %d, got %d, %v", name, tt.want, got, diags)
+		}
+	}
+
+	_, diags := pinnedIssuedAt(types.BoolNull(), timetypes.NewRFC3339ValueMust("1970-01-01T00:00:00Z"), claims)
+	if !diags.HasError() {
+		t.Error("expected an error for issued_at at the epoch")
+	}
+}
+
+func TestEncodeClaims_pinnedIssuedAt(t *testing.T) {
+	kp, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := kp.PublicKey()
+
+	encode := func() string {
+		claims := jwt.NewOperatorClaims(pub)
+		claims.Name = "op"
+		token, err := encodeClaims(context.Background(), claims, kp, nil, types.StringNull(), types.StringNull(), 1700000000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	token := encode()
+	if again := encode(); again != token {
+		t.Errorf("expected the same JWT, got %s and %s", token, again)
+	}
+
+	claims, err := jwt.DecodeOperatorClaims(token)
+	if err != nil {
+		t.Fatalf("failed to decode JWT: %v", err)
+	}
+	if claims.IssuedAt != 1700000000 {
+		t.Errorf("expected iat 1700000000, got %d", claims.IssuedAt)
+	}
+
+	// The jti is the hash jwt computes over the standard claims
+	expected := *claims.Claims()
+	expected.ID = ""
+	standard, _ := json.Marshal(expected)
+	hash := sha512.Sum512_256(standard)
+	if want := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(hash[:]); claims.ID != want {
+		t.Errorf("expected jti %s, got %s", want, claims.ID)
+	}
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// JWT output modes control which of the jwt/jwt_sensitive attributes are
+// populated with the issued token.
+const (
+	// jwtOutputAlways populates both jwt and jwt_sensitive.
+	jwtOutputAlways = "always"
+	// jwtOutputSensitiveOnly populates jwt_sensitive only; jwt is null.
+	jwtOutputSensitiveOnly = "sensitive_only"
+	// jwtOutputNever leaves both jwt and jwt_sensitive null.
+	jwtOutputNever = "never"
+)
+
+// jwtOutputValues returns the jwt and jwt_sensitive attribute values for the
+// given output mode.
+func jwtOutputValues(mode string, token string) (types.String, types.String) {
+	switch mode {
+	case jwtOutputSensitiveOnly:
+		return types.StringNull(), types.StringValue(token)
+	case jwtOutputNever:
+		return types.StringNull(), types.StringNull()
+	default:
+		return types.StringValue(token), types.StringValue(token)
+	}
+}
+
+// jwtOutputPlanValues returns the planned jwt and jwt_sensitive values of a
+// resource whose JWT is (re)issued: unknown where the output mode populates
+// the attribute, null where it does not.
+func jwtOutputPlanValues(mode string) (types.String, types.String) {
+	switch mode {
+	case jwtOutputSensitiveOnly:
+		return types.StringNull(), types.StringUnknown()
+	case jwtOutputNever:
+		return types.StringNull(), types.StringNull()
+	default:
+		return types.StringUnknown(), types.StringUnknown()
+	}
+}
+
+// jwtPlanned reports whether a plan (re)issues the resource JWT, which is the
+// case on create and whenever any attribute changes on update.
+func jwtPlanned(req resource.ModifyPlanRequest) bool {
+	if req.Plan.Raw.IsNull() {
+		return false
+	}
+	return req.State.Raw.IsNull() || !req.Plan.Raw.Equal(req.State.Raw)
+}
+
+// checksOnlyPlan reports whether an update changes nothing but the named
+// attributes, which are used for checks only and do not reissue the JWT.
+// Attributes the framework marked unknown because of the change, i.e.
+// unknown in the plan but not set in the configuration, are ignored too.
+func checksOnlyPlan(req resource.ModifyPlanRequest, names ...string) bool {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return false
+	}
+
+	plan, err := tftypes.Transform(req.Plan.Raw, func(p *tftypes.AttributePath, v tftypes.Value) (tftypes.Value, error) {
+		steps := p.Steps()
+		if len(steps) != 1 {
+			return v, nil
+		}
+		name, ok := steps[0].(tftypes.AttributeName)
+		if !ok {
+			return v, nil
+		}
+		if !slices.Contains(names, string(name)) {
+			if v.IsKnown() {
+				return v, nil
+			}
+			config, _, err := tftypes.WalkAttributePath(req.Config.Raw, p)
+			if err != nil || !config.(tftypes.Value).IsNull() {
+				return v, nil
+			}
+		}
+		state, _, err := tftypes.WalkAttributePath(req.State.Raw, p)
+		if err != nil {
+			return v, nil
+		}
+		return state.(tftypes.Value), nil
+	})
+	if err != nil {
+		return false
+	}
+
+	return plan.Equal(req.State.Raw)
+}
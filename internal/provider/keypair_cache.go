@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/nats-io/nkeys"
+)
+
+// keypairCache caches keypairs parsed from seeds for the lifetime of the
+// provider. Large configurations sign many user and account JWTs with the
+// same issuer seed, and nkeys derives the ed25519 key from the seed on every
+// PublicKey and Sign call. Entries are keyed by the SHA-256 of the seed so
+// the cache itself does not hold seeds as map keys.
+//
+// Seeds encrypted with the state encryption key are decrypted with cipher
+// before they are parsed.
+type keypairCache struct {
+	mu     sync.Mutex
+	pairs  map[[sha256.Size]byte]nkeys.KeyPair
+	cipher *stateCipher
+}
+
+func newKeypairCache() *keypairCache {
+	return &keypairCache{
+		pairs: make(map[[sha256.Size]byte]nkeys.KeyPair),
+	}
+}
+
+// seed returns the plain form of a seed that may be encrypted with the state
+// encryption key. A nil cache only accepts plain seeds.
+func (c *keypairCache) seed(value string) (string, error) {
+	var cipher *stateCipher
+	if c != nil {
+		cipher = c.cipher
+	}
+	return cipher.decrypt(value)
+}
+
+// fromSeed returns the keypair of the seed, parsing it on first use. A nil
+// cache parses the seed on every call.
+func (c *keypairCache) fromSeed(seed string) (nkeys.KeyPair, error) {
+	seed, err := c.seed(seed)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nkeys.FromSeed([]byte(seed))
+	}
+
+	hash := sha256.Sum256([]byte(seed))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if kp, ok := c.pairs[hash]; ok {
+		return kp, nil
+	}
+
+	kp, err := newCachedKeyPair(seed)
+	if err != nil {
+		return nil, err
+	}
+	c.pairs[hash] = kp
+
+	return kp, nil
+}
+
+// cachedKeyPair is a keypair with the ed25519 keys derived once up front.
+type cachedKeyPair struct {
+	nkeys.KeyPair
+	publicKey  string
+	privateKey ed25519.PrivateKey
+}
+
+func newCachedKeyPair(seed string) (*cachedKeyPair, error) {
+	kp, err := nkeys.FromSeed([]byte(seed))
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	encodedPrivateKey, err := kp.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := nkeys.Decode(nkeys.PrefixBytePrivate, encodedPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachedKeyPair{
+		KeyPair:    kp,
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}, nil
+}
+
+func (kp *cachedKeyPair) PublicKey() (string, error) {
+	return kp.publicKey, nil
+}
+
+func (kp *cachedKeyPair) Sign(input []byte) ([]byte, error) {
+	return ed25519.Sign(kp.privateKey, input), nil
+}
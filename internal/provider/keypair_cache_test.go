@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestKeypairCache_fromSeed(t *testing.T) {
+	operatorKP, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorSeed, _ := operatorKP.Seed()
+	operatorPubKey, _ := operatorKP.PublicKey()
+
+	cache := newKeypairCache()
+
+	kp, err := cache.fromSeed(string(operatorSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := cache.fromSeed(string(operatorSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kp != again {
+		t.Error("expected the cached keypair to be returned for the same seed")
+	}
+
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if publicKey != operatorPubKey {
+		t.Errorf("expected public key %s, got %s", operatorPubKey, publicKey)
+	}
+
+	// Signatures of the cached keypair must verify against the original key
+	sig, err := kp.Sign([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := operatorKP.Verify([]byte("data"), sig); err != nil {
+		t.Errorf("signature of cached keypair does not verify: %s", err)
+	}
+
+	accountKP, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountPubKey, _ := accountKP.PublicKey()
+
+	token, err := jwt.NewAccountClaims(accountPubKey).Encode(kp)
+	if err != nil {
+		t.Fatalf("failed to encode account JWT: %s", err)
+	}
+	if _, err := jwt.DecodeAccountClaims(token); err != nil {
+		t.Errorf("failed to decode account JWT: %s", err)
+	}
+}
+
+func TestKeypairCache_invalidSeed(t *testing.T) {
+	var nilCache *keypairCache
+
+	for name, cache := range map[string]*keypairCache{"cache": newKeypairCache(), "nil": nilCache} {
+		if _, err := cache.fromSeed("SOBADSEED"); err == nil {
+			t.Errorf("%s: expected error for invalid seed", name)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/attr/xattr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable                    = LimitType{}
+	_ basetypes.StringValuableWithSemanticEquals = Limit{}
+	_ xattr.ValidateableAttribute                = Limit{}
+)
+
+// Limit sentinels spell out the special values of JWT limits.
+const (
+	// limitUnlimited is -1, which lifts a limit.
+	limitUnlimited = "unlimited"
+	// limitDisabled is 0. Only some limits treat 0 as disabled; limits where
+	// 0 means unlimited reject it.
+	limitDisabled = "disabled"
+)
+
+var limitSentinels = map[string]int64{
+	limitUnlimited: -1,
+	limitDisabled:  0,
+}
+
+// parseLimit parses a count limit: an integer or a limit sentinel.
+func parseLimit(s string) (int64, error) {
+	if value, ok := limitSentinels[s]; ok {
+		return value, nil
+	}
+
+	value, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected an integer or one of %s or %s, got %q", limitUnlimited, limitDisabled, s)
+	}
+	return value, nil
+}
+
+// LimitType is a string type holding a count limit, either as a number or
+// as `unlimited` or `disabled`. Numbers in configuration are converted to
+// strings by Terraform, so existing configurations keep working.
+type LimitType struct {
+	basetypes.StringType
+}
+
+func (t LimitType) String() string {
+	return "provider.LimitType"
+}
+
+func (t LimitType) ValueType(ctx context.Context) attr.Value {
+	return Limit{}
+}
+
+func (t LimitType) Equal(o attr.Type) bool {
+	other, ok := o.(LimitType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t LimitType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return Limit{StringValue: in}, nil
+}
+
+func (t LimitType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	return Limit{StringValue: stringValue}, nil
+}
+
+// Limit is the value of a LimitType attribute.
+type Limit struct {
+	basetypes.StringValue
+}
+
+// NewLimitNull returns a null Limit.
+func NewLimitNull() Limit {
+	return Limit{StringValue: basetypes.NewStringNull()}
+}
+
+// NewLimitValue returns a known Limit. The value is not parsed.
+func NewLimitValue(value string) Limit {
+	return Limit{StringValue: basetypes.NewStringValue(value)}
+}
+
+func (v Limit) Type(_ context.Context) attr.Type {
+	return LimitType{}
+}
+
+func (v Limit) Equal(o attr.Value) bool {
+	other, ok := o.(Limit)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// ValueInt64 returns the limit. Null, unknown and invalid values return 0;
+// invalid values are rejected during validation.
+func (v Limit) ValueInt64() int64 {
+	if v.IsNull() || v.IsUnknown() {
+		return 0
+	}
+	limit, _ := parseLimit(v.ValueString())
+	return limit
+}
+
+// Int64 returns the limit as an Int64 value, keeping null and unknown values.
+func (v Limit) Int64() types.Int64 {
+	switch {
+	case v.IsNull():
+		return types.Int64Null()
+	case v.IsUnknown():
+		return types.Int64Unknown()
+	}
+	return types.Int64Value(v.ValueInt64())
+}
+
+// ValidateAttribute rejects values that are not a valid limit.
+func (v Limit) ValidateAttribute(ctx context.Context, req xattr.ValidateAttributeRequest, resp *xattr.ValidateAttributeResponse) {
+	if v.IsNull() || v.IsUnknown() {
+		return
+	}
+
+	if _, err := parseLimit(v.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid limit", err.Error())
+	}
+}
+
+// StringSemanticEquals treats equal limits as equal, so changing `-1` to
+// `unlimited` does not plan an update.
+func (v Limit) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(Limit)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T. Please report this issue to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	priorLimit, err := parseLimit(v.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	newLimit, err := parseLimit(newValue.ValueString())
+	if err != nil {
+		return false, diags
+	}
+
+	return priorLimit == newLimit, diags
+}
+
+// limitAtLeast returns a validator that requires a Limit or ByteSize
+// attribute to be at least min.
+func limitAtLeast(min int64) validator.String {
+	return limitAtLeastValidator{min: min}
+}
+
+type limitAtLeastValidator struct {
+	min int64
+}
+
+func (v limitAtLeastValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("value must be at least %d", v.min)
+}
+
+func (v limitAtLeastValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v limitAtLeastValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	// Byte sizes are a superset of count limits
+	value, err := parseByteSize(req.ConfigValue.ValueString())
+	if err != nil {
+		// Reported by the attribute type
+		return
+	}
+	if value < v.min {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid limit",
+			fmt.Sprintf("Attribute %s must be at least %d, got: %s", req.Path, v.min, req.ConfigValue.ValueString()),
+		)
+	}
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := map[string]struct {
+		input       string
+		expected    int64
+		expectError bool
+	}{
+		"number":    {input: "100", expected: 100},
+		"negative":  {input: "-1", expected: -1},
+		"unlimited": {input: "unlimited", expected: -1},
+		"disabled":  {input: "disabled", expected: 0},
+		"trim":      {input: " 5 ", expected: 5},
+		"upper":     {input: "UNLIMITED", expectError: true},
+		"unit":      {input: "10KiB", expectError: true},
+		"empty":     {input: "", expectError: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			limit, err := parseLimit(tc.input)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %d", limit)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if limit != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, limit)
+			}
+		})
+	}
+}
+
+func TestLimit_semanticEquals(t *testing.T) {
+	ctx := context.Background()
+
+	equal, diags := NewLimitValue("-1").StringSemanticEquals(ctx, NewLimitValue("unlimited"))
+	if diags.HasError() || !equal {
+		t.Errorf("expected -1 and unlimited to be equal")
+	}
+
+	equal, diags = NewLimitValue("0").StringSemanticEquals(ctx, NewLimitValue("unlimited"))
+	if diags.HasError() || equal {
+		t.Errorf("expected 0 and unlimited to differ")
+	}
+
+	if NewLimitNull().ValueInt64() != 0 || !NewLimitNull().Int64().IsNull() {
+		t.Errorf("expected null limit to stay null")
+	}
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// stringListValues returns the elements of a list of strings. It is used on
+// the claims encode path instead of ElementsAs, which goes through the
+// reflection based conversion and allocates heavily when run for thousands of
+// resources. Null and unknown lists yield nil. Null and unknown elements are
+// errors, like with ElementsAs; callers running at plan time check for
+// unknown elements first.
+func stringListValues(ctx context.Context, list types.List) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if list.IsNull() || list.IsUnknown() {
+		return nil, diags
+	}
+
+	elements := list.Elements()
+	values := make([]string, 0, len(elements))
+	for i, element := range elements {
+		value, ok := element.(types.String)
+		if !ok {
+			// Not a plain string list, fall back to the generic conversion
+			var converted []string
+			diags := list.ElementsAs(ctx, &converted, false)
+			return converted, diags
+		}
+		switch {
+		case value.IsNull():
+			diags.AddError("Invalid list element", fmt.Sprintf("Element %d of the list is null; list elements must be set.", i))
+		case value.IsUnknown():
+			diags.AddError("Invalid list element", fmt.Sprintf("Element %d of the list is not known yet.", i))
+		}
+		values = append(values, value.ValueString())
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return values, diags
+}
+
+// listHasUnknown reports whether a list or any of its elements is unknown.
+func listHasUnknown(list types.List) bool {
+	return list.IsUnknown() || slices.ContainsFunc(list.Elements(), attr.Value.IsUnknown)
+}
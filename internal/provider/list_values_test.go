@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestStringListValues(t *testing.T) {
+	ctx := context.Background()
+
+	list, diags := types.ListValueFrom(ctx, types.StringType, []string{"a.>", "b"})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	tests := []struct {
+		name     string
+		list     types.List
+		expected []string
+	}{
+		{name: "values", list: list, expected: []string{"a.>", "b"}},
+		{name: "empty", list: types.ListValueMust(types.StringType, nil), expected: []string{}},
+		{name: "null", list: types.ListNull(types.StringType), expected: nil},
+		{name: "unknown", list: types.ListUnknown(types.StringType), expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, diags := stringListValues(ctx, tt.list)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if !reflect.DeepEqual(values, tt.expected) {
+				t.Errorf("expected %#v, got %#v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestStringListValues_invalidElements(t *testing.T) {
+	ctx := context.Background()
+
+	for name, element := range map[string]types.String{
+		"null element":    types.StringNull(),
+		"unknown element": types.StringUnknown(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			list := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("a"), element})
+			if !listHasUnknown(list) && element.IsUnknown() {
+				t.Error("expected listHasUnknown to report the unknown element")
+			}
+			values, diags := stringListValues(ctx, list)
+			if !diags.HasError() {
+				t.Fatalf("expected error, got %#v", values)
+			}
+			if !strings.Contains(diags[0].Detail(), "Element 1") {
+				t.Errorf("expected the element index in the error, got %q", diags[0].Detail())
+			}
+		})
+	}
+}
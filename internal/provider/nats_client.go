@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// natsUserInfoSubject is the system service that reports the account and
+// permissions of the requesting user.
+const natsUserInfoSubject = "$SYS.REQ.USER.INFO"
+
+// natsClientName is the connection name shown by the server for connections
+// of the provider.
+const natsClientName = "terraform-provider-nsc"
+
+// natsProxy returns the proxy for an http or https URL from the HTTPS_PROXY,
+// HTTP_PROXY and NO_PROXY environment variables, or nil to connect directly.
+var natsProxy = httpproxy.FromEnvironment().ProxyFunc()
+
+// natsServerInfo describes the server a session is connected to.
+type natsServerInfo struct {
+	ServerID   string
+	ServerName string
+	Version    string
+}
+
+// natsConnection is the result of a successful connection check.
+type natsConnection struct {
+	Server natsServerInfo
+	// Account is the account the user was authenticated into, as reported by
+	// the server. Empty when the server does not answer user info requests.
+	Account string
+}
+
+// natsSession is an authenticated connection to a NATS server that can make
+// requests.
+type natsSession struct {
+	Server natsServerInfo
+
+	nc  *nats.Conn
+	ctx context.Context
+
+	// cancelRequest cancels the pending request when the server reports a
+	// permissions violation, which nats.go only passes to the error handler.
+	mu            sync.Mutex
+	cancelRequest context.CancelFunc
+}
+
+// natsConnectOptions holds how to authenticate to a NATS server and how to
+// secure the connection.
+type natsConnectOptions struct {
+	// UserJWT authenticates with a user JWT, KeyPair signing the server
+	// nonce. A KeyPair without a UserJWT authenticates as an nkey user.
+	UserJWT string
+	KeyPair nkeys.KeyPair
+	// User and Password authenticate with a username and password.
+	User     string
+	Password string
+	// TLS configures the TLS client and forces TLS when set. Without it the
+	// system roots are used whenever the server requires TLS.
+	TLS *tls.Config
+}
+
+// natsConnect connects to a NATS server, authenticates and asks the server
+// which account the user was bound to. The connection is closed before
+// returning; ctx bounds the whole exchange.
+func natsConnect(ctx context.Context, serverURL string, opts natsConnectOptions) (*natsConnection, error) {
+	session, err := natsDial(ctx, serverURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	result := &natsConnection{Server: session.Server}
+
+	// Servers predating the user info service may not answer at all, which
+	// leaves the account unknown rather than failing the check.
+	payload, err := session.request(natsUserInfoSubject, nil)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+	if payload != nil {
+		var userInfo struct {
+			Data struct {
+				Account string `json:"account"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &userInfo); err != nil {
+			return nil, fmt.Errorf("failed to decode user info: %w", err)
+		}
+		result.Account = userInfo.Data.Account
+	}
+
+	return result, nil
+}
+
+// natsDial connects to a NATS server and authenticates. The deadline of ctx
+// applies to the connection for its whole lifetime; the caller closes the
+// session.
+func natsDial(ctx context.Context, serverURL string, opts natsConnectOptions) (*natsSession, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+	switch u.Scheme {
+	case "nats", "tls", "ws", "wss":
+	default:
+		return nil, fmt.Errorf("unsupported server URL scheme %q, expected nats, tls, ws or wss", u.Scheme)
+	}
+
+	dialer := &natsContextDialer{ctx: ctx}
+	session := &natsSession{ctx: ctx}
+	options := []nats.Option{
+		nats.Name(natsClientName),
+		nats.NoReconnect(),
+		nats.SetCustomDialer(dialer),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			if errors.Is(err, nats.ErrPermissionViolation) {
+				session.abortRequest()
+			}
+		}),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		options = append(options, nats.Timeout(time.Until(deadline)))
+	}
+	if opts.TLS != nil {
+		options = append(options, nats.Secure(opts.TLS))
+	}
+	if u.Scheme == "ws" || u.Scheme == "wss" {
+		// nats.go connects to the root path unless told otherwise
+		if path := strings.TrimPrefix(u.Path, "/"); path != "" {
+			options = append(options, nats.ProxyPath(path))
+		}
+		httpURL := *u
+		httpURL.Scheme = strings.Replace(u.Scheme, "ws", "http", 1)
+		dialer.proxy, err = natsProxy(&httpURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		if dialer.proxy != nil {
+			// The proxy resolves the server name
+			options = append(options, nats.SkipHostLookup())
+		}
+	}
+	switch {
+	case opts.KeyPair != nil && opts.UserJWT != "":
+		userJWT := opts.UserJWT
+		options = append(options, nats.UserJWT(
+			func() (string, error) { return userJWT, nil },
+			opts.KeyPair.Sign,
+		))
+	case opts.KeyPair != nil:
+		publicKey, err := opts.KeyPair.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, nats.Nkey(publicKey, opts.KeyPair.Sign))
+	case opts.User != "":
+		options = append(options, nats.UserInfo(opts.User, opts.Password))
+	}
+
+	nc, err := nats.Connect(serverURL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", u.Host, err)
+	}
+	session.nc = nc
+	session.Server = natsServerInfo{
+		ServerID:   nc.ConnectedServerId(),
+		ServerName: nc.ConnectedServerName(),
+		Version:    nc.ConnectedServerVersion(),
+	}
+	return session, nil
+}
+
+// request publishes payload to subject and returns the first reply. A nil
+// reply means that nothing answers on the subject or that the user is not
+// permitted to publish to it.
+func (s *natsSession) request(subject string, payload []byte) ([]byte, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.cancelRequest = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.cancelRequest = nil
+		s.mu.Unlock()
+	}()
+
+	msg, err := s.nc.RequestWithContext(ctx, subject, payload)
+	switch {
+	case err == nil:
+		return msg.Data, nil
+	case errors.Is(err, nats.ErrNoResponders):
+		return nil, nil
+	case errors.Is(err, context.Canceled) && s.ctx.Err() == nil:
+		// Cancelled by a permissions violation; the user stays connected
+		return nil, nil
+	}
+	return nil, fmt.Errorf("request to %s failed: %w", subject, err)
+}
+
+func (s *natsSession) abortRequest() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancelRequest != nil {
+		s.cancelRequest()
+	}
+}
+
+func (s *natsSession) Close() error {
+	s.nc.Close()
+	return nil
+}
+
+// natsContextDialer dials the server with the context of the session, so
+// that cancelling it aborts connecting as well. With a proxy, WebSocket
+// connections are tunnelled through it with an HTTP CONNECT request.
+type natsContextDialer struct {
+	ctx    context.Context
+	dialer net.Dialer
+	proxy  *url.URL
+}
+
+func (d *natsContextDialer) Dial(network, address string) (net.Conn, error) {
+	if d.proxy == nil {
+		return d.dialer.DialContext(d.ctx, network, address)
+	}
+
+	proxyAddress := d.proxy.Host
+	if d.proxy.Port() == "" {
+		port := "80"
+		if d.proxy.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddress = net.JoinHostPort(d.proxy.Hostname(), port)
+	}
+	conn, err := d.dialer.DialContext(d.ctx, network, proxyAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %s: %w", proxyAddress, err)
+	}
+	tunnel, err := d.connect(conn, address)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tunnel, nil
+}
+
+// connect asks the proxy on conn for a tunnel to address and returns the
+// connection to use for it, which is wrapped in TLS for https proxies.
+func (d *natsContextDialer) connect(conn net.Conn, address string) (net.Conn, error) {
+	if deadline, ok := d.ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+	if d.proxy.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: d.proxy.Hostname(), MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(d.ctx); err != nil {
+			return nil, fmt.Errorf("TLS handshake with proxy %s failed: %w", d.proxy.Host, err)
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if user := d.proxy.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed to send CONNECT to proxy %s: %w", d.proxy.Host, err)
+	}
+	// The server does not send anything before the WebSocket upgrade, so
+	// nothing past the response is buffered. What follows a successful
+	// response is the tunnel, so its body is not read.
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONNECT response of proxy %s: %w", d.proxy.Host, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("proxy %s refused to connect to %s: %s", d.proxy.Host, address, resp.Status)
+	}
+	return conn, nil
+}
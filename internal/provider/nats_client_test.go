@@ -0,0 +1,523 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// fakeNATSServer accepts connections and speaks just enough of the NATS
+// protocol to authenticate users by their JWT and answer user info,
+// JetStream account info and account claims lookup requests.
+type fakeNATSServer struct {
+	listener net.Listener
+	start    sync.Once
+	// account is returned for user info requests. Without an account the
+	// server answers with a no responders status.
+	account string
+	// jsz holds the JetStream account details returned for
+	// $SYS.REQ.ACCOUNT.<account>.JSZ requests, by account public key.
+	// Other accounts get a no responders status.
+	jsz map[string]string
+	// claims holds the account JWTs returned for
+	// $SYS.REQ.ACCOUNT.<account>.CLAIMS.LOOKUP requests, by account public
+	// key. Other accounts get a no responders status.
+	claims map[string]string
+	// deny lists subjects the users are not permitted to publish to.
+	deny []string
+	// reject makes the server refuse every CONNECT.
+	reject bool
+	// password, when set, is required for user and password authentication
+	// of any user.
+	password string
+	// tls, when set, makes the server require TLS.
+	tls *tls.Config
+	// paths records the paths of WebSocket upgrade requests.
+	paths []string
+}
+
+func newFakeNATSServer(t *testing.T) *fakeNATSServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeNATSServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+// URL starts serving, so the fields must be set before it is called.
+func (s *fakeNATSServer) URL() string {
+	s.start.Do(func() { go s.serve() })
+	return "nats://" + s.listener.Addr().String()
+}
+
+// serveWebSocket serves the fake server over WebSocket as well and returns
+// the HTTP server, with TLS when secure is set. Upgrade requests are recorded
+// in paths.
+func (s *fakeNATSServer) serveWebSocket(t *testing.T, secure bool) *httptest.Server {
+	t.Helper()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.paths = append(s.paths, r.URL.Path)
+		key := r.Header.Get("Sec-WebSocket-Key")
+		if r.Header.Get("Upgrade") != "websocket" || key == "" {
+			http.Error(w, "not a WebSocket upgrade", http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", fakeWebSocketAccept(key))
+		rw.Flush()
+		s.handle(&fakeWebSocketConn{Conn: conn, r: rw.Reader})
+	})
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	if secure {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+// fakeWebSocketAccept returns the Sec-WebSocket-Accept value of a
+// Sec-WebSocket-Key (RFC 6455, section 1.3).
+func fakeWebSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// fakeWebSocketConn is the server side of a WebSocket connection carrying the
+// NATS protocol. It reads the masked, uncompressed frames clients send and
+// writes every message as one unmasked binary frame.
+type fakeWebSocketConn struct {
+	net.Conn
+	r *bufio.Reader
+
+	remaining uint64
+	mask      [4]byte
+	pos       int
+}
+
+func (c *fakeWebSocketConn) Read(p []byte) (int, error) {
+	for c.remaining == 0 {
+		var header [2]byte
+		if _, err := io.ReadFull(c.r, header[:]); err != nil {
+			return 0, err
+		}
+		if opcode := header[0] & 0x0f; opcode == 0x8 {
+			return 0, io.EOF
+		}
+		c.remaining = uint64(header[1] & 0x7f)
+		switch c.remaining {
+		case 126:
+			var length [2]byte
+			io.ReadFull(c.r, length[:])
+			c.remaining = uint64(binary.BigEndian.Uint16(length[:]))
+		case 127:
+			var length [8]byte
+			io.ReadFull(c.r, length[:])
+			c.remaining = binary.BigEndian.Uint64(length[:])
+		}
+		if _, err := io.ReadFull(c.r, c.mask[:]); err != nil {
+			return 0, err
+		}
+		c.pos = 0
+	}
+	if uint64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	for i := range p[:n] {
+		p[i] ^= c.mask[c.pos%4]
+		c.pos++
+	}
+	c.remaining -= uint64(n)
+	return n, err
+}
+
+func (c *fakeWebSocketConn) Write(p []byte) (int, error) {
+	frame := []byte{0x82}
+	switch {
+	case len(p) <= 125:
+		frame = append(frame, byte(len(p)))
+	case len(p) <= 0xffff:
+		frame = binary.BigEndian.AppendUint16(append(frame, 126), uint16(len(p)))
+	default:
+		frame = binary.BigEndian.AppendUint64(append(frame, 127), uint64(len(p)))
+	}
+	if _, err := c.Conn.Write(append(frame, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// newFakeProxy starts an HTTP proxy that only tunnels CONNECT requests and
+// returns its URL. The addresses it was asked to connect to are sent to
+// targets.
+func newFakeProxy(t *testing.T, targets chan<- string) *url.URL {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "only CONNECT is supported", http.StatusMethodNotAllowed)
+			return
+		}
+		targets <- r.Host
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		go func() {
+			io.Copy(upstream, rw)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(server.Close)
+	u, _ := url.Parse(server.URL)
+	return u
+}
+
+func (s *fakeNATSServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeNATSServer) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	nonce := "test-nonce"
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"NFAKE\",\"server_name\":\"fake\",\"version\":\"2.11.0\",\"nonce\":%q,\"headers\":true,\"max_payload\":1048576,\"tls_required\":%t}\r\n", nonce, s.tls != nil)
+	if s.tls != nil {
+		tlsConn := tls.Server(conn, s.tls)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn = tlsConn
+	}
+
+	r := bufio.NewReader(conn)
+	sids := make(map[string]string)
+	for {
+		line, err := fakeNATSReadLine(r)
+		if err != nil {
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			var connect struct {
+				JWT  string `json:"jwt"`
+				NKey string `json:"nkey"`
+				Sig  string `json:"sig"`
+				User string `json:"user"`
+				Pass string `json:"pass"`
+			}
+			json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &connect)
+			sig, _ := base64.RawURLEncoding.DecodeString(connect.Sig)
+			var verified bool
+			switch {
+			case connect.JWT != "":
+				if claims, err := jwt.DecodeUserClaims(connect.JWT); err == nil {
+					userKP, _ := nkeys.FromPublicKey(claims.Subject)
+					verified = userKP.Verify([]byte(nonce), sig) == nil
+				}
+			case connect.NKey != "":
+				if userKP, err := nkeys.FromPublicKey(connect.NKey); err == nil {
+					verified = userKP.Verify([]byte(nonce), sig) == nil
+				}
+			case connect.User != "":
+				verified = s.password != "" && connect.Pass == s.password
+			}
+			if s.reject || !verified {
+				fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+		case line == "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case strings.HasPrefix(line, "SUB "):
+			fields := strings.Fields(line)
+			sids[fields[1]] = fields[2]
+		case strings.HasPrefix(line, "PUB "):
+			fields := strings.Fields(line)
+			fakeNATSReadLine(r)
+			subject, inbox := fields[1], fields[2]
+			if slices.Contains(s.deny, subject) {
+				fmt.Fprintf(conn, "-ERR 'Permissions Violation for Publish to \"%s\"'\r\n", subject)
+				continue
+			}
+			// nats.go subscribes to a wildcard inbox and waits for the reply on
+			// one of its tokens
+			sid, ok := sids[inbox]
+			if !ok {
+				sid = sids[inbox[:strings.LastIndex(inbox, ".")]+".*"]
+			}
+
+			var payload string
+			if account, ok := strings.CutSuffix(strings.TrimPrefix(subject, "$SYS.REQ.ACCOUNT."), ".CLAIMS.LOOKUP"); ok {
+				payload = s.claims[account]
+			} else if account, ok := strings.CutPrefix(subject, "$SYS.REQ.ACCOUNT."); ok {
+				if data, ok := s.jsz[strings.TrimSuffix(account, ".JSZ")]; ok {
+					payload = fmt.Sprintf(`{"server":{"name":"fake"},"data":%s}`, data)
+				}
+			} else if subject == natsUserInfoSubject && s.account != "" {
+				payload = fmt.Sprintf(`{"data":{"user":"U","account":%q}}`, s.account)
+			}
+			if payload == "" {
+				fmt.Fprintf(conn, "HMSG %s %s 16 16\r\nNATS/1.0 503\r\n\r\n\r\n", inbox, sid)
+				continue
+			}
+			fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", inbox, sid, len(payload), payload)
+		}
+	}
+}
+
+func fakeNATSReadLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func testUserCredentials(t *testing.T) (string, nkeys.KeyPair) {
+	t.Helper()
+	accountKP, _ := nkeys.CreateAccount()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	userJWT, err := jwt.NewUserClaims(userPubKey).Encode(accountKP)
+	if err != nil {
+		t.Fatalf("failed to encode user JWT: %v", err)
+	}
+	return userJWT, userKP
+}
+
+func TestNatsConnect(t *testing.T) {
+	userJWT, userKP := testUserCredentials(t)
+
+	t.Run("authenticated", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+		server.account = "ATESTACCOUNT"
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, err := natsConnect(ctx, server.URL(), natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if conn.Server.Version != "2.11.0" || conn.Server.ServerName != "fake" {
+			t.Errorf("unexpected server info: %+v", conn.Server)
+		}
+		if conn.Account != "ATESTACCOUNT" {
+			t.Errorf("expected account ATESTACCOUNT, got %q", conn.Account)
+		}
+	})
+
+	t.Run("no user info", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, err := natsConnect(ctx, server.URL(), natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if conn.Account != "" {
+			t.Errorf("expected no account, got %q", conn.Account)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+		server.reject = true
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := natsConnect(ctx, server.URL(), natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err == nil || !strings.Contains(err.Error(), "Authorization Violation") {
+			t.Errorf("expected authorization error, got %v", err)
+		}
+	})
+
+	t.Run("permissions violation", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+		server.account = "ATESTACCOUNT"
+		server.deny = []string{natsUserInfoSubject}
+
+		// The violation ends the request without waiting for the deadline
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, err := natsConnect(ctx, server.URL(), natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if conn.Account != "" {
+			t.Errorf("expected no account, got %q", conn.Account)
+		}
+		if ctx.Err() != nil {
+			t.Errorf("expected the request to end before the deadline")
+		}
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		_, err := natsConnect(context.Background(), "http://localhost:8080", natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err == nil || !strings.Contains(err.Error(), "unsupported server URL scheme") {
+			t.Errorf("expected scheme error, got %v", err)
+		}
+	})
+	t.Run("nkey", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+		userKP, _ := nkeys.CreateUser()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := natsConnect(ctx, server.URL(), natsConnectOptions{KeyPair: userKP}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("user and password", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+		server.password = "secret"
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := natsConnect(ctx, server.URL(), natsConnectOptions{User: "app", Password: "secret"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err := natsConnect(ctx, server.URL(), natsConnectOptions{User: "app", Password: "wrong"})
+		if err == nil || !strings.Contains(err.Error(), "Authorization Violation") {
+			t.Errorf("expected authorization error, got %v", err)
+		}
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		certs := newTestTLSCertificates(t)
+		server := newFakeNATSServer(t)
+		server.tls = certs.serverConfig()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		opts, _, diags := NATSConnectionModel{
+			Creds:   types.StringValue(testCreds(t, userJWT, userKP)),
+			TLSCA:   types.StringValue(certs.CA),
+			TLSCert: types.StringValue(certs.ClientCert),
+			TLSKey:  types.StringValue(certs.ClientKey),
+		}.natsConnectOptions(nil, nil, time.Second)
+		if diags.HasError() {
+			t.Fatalf("unexpected error: %v", diags)
+		}
+		if _, err := natsConnect(ctx, server.URL(), opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Without the CA the server certificate is not trusted
+		_, err := natsConnect(ctx, server.URL(), natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err == nil || !strings.Contains(err.Error(), "certificate") {
+			t.Errorf("expected certificate error, got %v", err)
+		}
+	})
+	t.Run("WebSocket", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+		server.account = "ATESTACCOUNT"
+		httpServer := server.serveWebSocket(t, false)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn, err := natsConnect(ctx, "ws"+strings.TrimPrefix(httpServer.URL, "http"), natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if conn.Account != "ATESTACCOUNT" {
+			t.Errorf("expected account ATESTACCOUNT, got %q", conn.Account)
+		}
+	})
+
+	t.Run("WebSocket path and proxy", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+		server.account = "ATESTACCOUNT"
+		httpServer := server.serveWebSocket(t, false)
+		targets := make(chan string, 1)
+		proxyURL := newFakeProxy(t, targets)
+
+		proxy := natsProxy
+		natsProxy = func(*url.URL) (*url.URL, error) { return proxyURL, nil }
+		t.Cleanup(func() { natsProxy = proxy })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		wsURL := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/nats/ws"
+		conn, err := natsConnect(ctx, wsURL, natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if conn.Account != "ATESTACCOUNT" {
+			t.Errorf("expected account ATESTACCOUNT, got %q", conn.Account)
+		}
+		if target := <-targets; target != httpServer.Listener.Addr().String() {
+			t.Errorf("expected a tunnel to %s, got %s", httpServer.Listener.Addr(), target)
+		}
+		if len(server.paths) != 1 || server.paths[0] != "/nats/ws" {
+			t.Errorf("expected the upgrade on /nats/ws, got %v", server.paths)
+		}
+	})
+
+	t.Run("secure WebSocket", func(t *testing.T) {
+		server := newFakeNATSServer(t)
+		httpServer := server.serveWebSocket(t, true)
+		wssURL := "wss" + strings.TrimPrefix(httpServer.URL, "https")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		pool := x509.NewCertPool()
+		pool.AddCert(httpServer.Certificate())
+		opts := natsConnectOptions{UserJWT: userJWT, KeyPair: userKP, TLS: &tls.Config{RootCAs: pool}}
+		if _, err := natsConnect(ctx, wssURL, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err := natsConnect(ctx, wssURL, natsConnectOptions{UserJWT: userJWT, KeyPair: userKP})
+		if err == nil || !strings.Contains(err.Error(), "certificate") {
+			t.Errorf("expected certificate error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// NATSConnectionModel holds the options for connecting to a NATS server. The
+// provider nats block sets defaults that data sources connecting to NATS
+// override attribute by attribute.
+type NATSConnectionModel struct {
+	Creds    types.String         `tfsdk:"creds"`
+	NKeySeed types.String         `tfsdk:"nkey_seed"`
+	User     types.String         `tfsdk:"user"`
+	Password types.String         `tfsdk:"password"`
+	TLSCA    types.String         `tfsdk:"tls_ca"`
+	TLSCert  types.String         `tfsdk:"tls_cert"`
+	TLSKey   types.String         `tfsdk:"tls_key"`
+	Timeout  timetypes.GoDuration `tfsdk:"timeout"`
+}
+
+// natsConnectionValidators returns the validators of the connection
+// attributes shared by the provider nats block and the data sources.
+func natsConnectionValidators() map[string][]validator.String {
+	exclusive := func(others ...string) []validator.String {
+		expressions := make([]path.Expression, len(others))
+		for i, other := range others {
+			expressions[i] = path.MatchRelative().AtParent().AtName(other)
+		}
+		return []validator.String{stringvalidator.ConflictsWith(expressions...)}
+	}
+	requires := func(other string) []validator.String {
+		return []validator.String{stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName(other))}
+	}
+
+	return map[string][]validator.String{
+		"creds":     exclusive("nkey_seed", "user"),
+		"nkey_seed": exclusive("creds", "user"),
+		"user":      exclusive("creds", "nkey_seed"),
+		"password":  requires("user"),
+		"tls_cert":  requires("tls_key"),
+		"tls_key":   requires("tls_cert"),
+	}
+}
+
+// natsConnectionAttributes adds the connection attributes other than creds
+// and timeout, whose descriptions differ between data sources, to attrs.
+func natsConnectionAttributes(attrs map[string]schema.Attribute) map[string]schema.Attribute {
+	validators := natsConnectionValidators()
+
+	attrs["nkey_seed"] = schema.StringAttribute{
+		Optional:            true,
+		Sensitive:           true,
+		MarkdownDescription: "Seed of a user nkey to authenticate with instead of `creds`, for servers configured with nkey users. Defaults to the provider `nats` block.",
+		Validators:          validators["nkey_seed"],
+	}
+	attrs["user"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "Username to authenticate with instead of `creds`. Defaults to the provider `nats` block.",
+		Validators:          validators["user"],
+	}
+	attrs["password"] = schema.StringAttribute{
+		Optional:            true,
+		Sensitive:           true,
+		MarkdownDescription: "Password of `user`.",
+		Validators:          validators["password"],
+	}
+	attrs["tls_ca"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "PEM encoded CA certificates to verify the server certificate with instead of the system roots. Setting it forces TLS. Defaults to the provider `nats` block.",
+	}
+	attrs["tls_cert"] = schema.StringAttribute{
+		Optional:            true,
+		MarkdownDescription: "PEM encoded client certificate for mutual TLS. Setting it forces TLS. Defaults to the provider `nats` block.",
+		Validators:          validators["tls_cert"],
+	}
+	attrs["tls_key"] = schema.StringAttribute{
+		Optional:            true,
+		Sensitive:           true,
+		MarkdownDescription: "PEM encoded private key of `tls_cert`.",
+		Validators:          validators["tls_key"],
+	}
+	return attrs
+}
+
+// natsConnectOptions merges the connection options of a data source with the
+// defaults of the provider nats block and parses them. Authentication
+// (creds, nkey_seed or user and password) and the client certificate are
+// taken from one place as a whole, so a data source setting creds does not
+// mix them with a provider user. Seeds may be encrypted with the state
+// encryption key.
+func (m NATSConnectionModel) natsConnectOptions(defaults *NATSConnectionModel, keys *keypairCache, defaultTimeout time.Duration) (natsConnectOptions, time.Duration, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var opts natsConnectOptions
+
+	if defaults == nil {
+		defaults = &NATSConnectionModel{}
+	}
+	auth := m
+	if m.Creds.IsNull() && m.NKeySeed.IsNull() && m.User.IsNull() {
+		auth = *defaults
+	}
+	tlsCA := m.TLSCA
+	if tlsCA.IsNull() {
+		tlsCA = defaults.TLSCA
+	}
+	tlsCert, tlsKey := m.TLSCert, m.TLSKey
+	if tlsCert.IsNull() {
+		tlsCert, tlsKey = defaults.TLSCert, defaults.TLSKey
+	}
+
+	timeout := defaultTimeout
+	for _, value := range []timetypes.GoDuration{defaults.Timeout, m.Timeout} {
+		if value.IsNull() || value.IsUnknown() {
+			continue
+		}
+		duration, d := value.ValueGoDuration()
+		diags.Append(d...)
+		timeout = duration
+	}
+	if diags.HasError() {
+		return opts, 0, diags
+	}
+
+	switch {
+	case !auth.Creds.IsNull():
+		creds := []byte(auth.Creds.ValueString())
+		userJWT, err := jwt.ParseDecoratedJWT(creds)
+		if err != nil {
+			diags.AddError("Invalid credentials", "Failed to read the user JWT from creds: "+err.Error())
+			return opts, 0, diags
+		}
+		userKP, err := jwt.ParseDecoratedUserNKey(creds)
+		if err != nil {
+			diags.AddError("Invalid credentials", "Failed to read the user seed from creds: "+err.Error())
+			return opts, 0, diags
+		}
+		opts.UserJWT = userJWT
+		opts.KeyPair = userKP
+	case !auth.NKeySeed.IsNull():
+		kp, err := keys.fromSeed(auth.NKeySeed.ValueString())
+		if err != nil {
+			diags.AddError("Invalid nkey seed", "Failed to read the user nkey from nkey_seed: "+err.Error())
+			return opts, 0, diags
+		}
+		if publicKey, _ := kp.PublicKey(); !nkeys.IsValidPublicUserKey(publicKey) {
+			diags.AddError("Invalid nkey seed", "nkey_seed must be the seed of a user nkey")
+			return opts, 0, diags
+		}
+		opts.KeyPair = kp
+	case !auth.User.IsNull():
+		opts.User = auth.User.ValueString()
+		opts.Password = auth.Password.ValueString()
+	default:
+		diags.AddError(
+			"Missing NATS credentials",
+			"Set creds, nkey_seed or user on the data source or in the provider nats block.",
+		)
+		return opts, 0, diags
+	}
+
+	if !tlsCA.IsNull() || !tlsCert.IsNull() {
+		opts.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if !tlsCA.IsNull() {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(tlsCA.ValueString())) {
+			diags.AddError("Invalid TLS CA", "tls_ca does not contain any PEM encoded certificates")
+			return opts, 0, diags
+		}
+		opts.TLS.RootCAs = pool
+	}
+	if !tlsCert.IsNull() {
+		cert, err := tls.X509KeyPair([]byte(tlsCert.ValueString()), []byte(tlsKey.ValueString()))
+		if err != nil {
+			diags.AddError("Invalid TLS client certificate", "Failed to load tls_cert and tls_key: "+err.Error())
+			return opts, 0, diags
+		}
+		opts.TLS.Certificates = []tls.Certificate{cert}
+	}
+
+	return opts, timeout, diags
+}
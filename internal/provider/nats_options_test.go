@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// testTLSCertificates is a CA with a server certificate for 127.0.0.1 and a
+// client certificate, all PEM encoded.
+type testTLSCertificates struct {
+	CA         string
+	ServerCert string
+	ServerKey  string
+	ClientCert string
+	ClientKey  string
+}
+
+func newTestTLSCertificates(t *testing.T) testTLSCertificates {
+	t.Helper()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) (string, string) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "test"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyDER, _ := x509.MarshalECPrivateKey(key)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+			string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	}
+
+	certs := testTLSCertificates{
+		CA: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})),
+	}
+	certs.ServerCert, certs.ServerKey = issue(2, x509.ExtKeyUsageServerAuth)
+	certs.ClientCert, certs.ClientKey = issue(3, x509.ExtKeyUsageClientAuth)
+	return certs
+}
+
+// serverConfig requires clients to present a certificate issued by the CA.
+func (c testTLSCertificates) serverConfig() *tls.Config {
+	cert, _ := tls.X509KeyPair([]byte(c.ServerCert), []byte(c.ServerKey))
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM([]byte(c.CA))
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+}
+
+func testCreds(t *testing.T, userJWT string, kp nkeys.KeyPair) string {
+	t.Helper()
+	seed, _ := kp.Seed()
+	creds, err := jwt.FormatUserConfig(userJWT, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(creds)
+}
+
+func TestNATSConnectionModel_natsConnectOptions(t *testing.T) {
+	userJWT, userKP := testUserCredentials(t)
+	nkeyKP, _ := nkeys.CreateUser()
+	nkeySeed, _ := nkeyKP.Seed()
+	nkeyPubKey, _ := nkeyKP.PublicKey()
+	accountKP, _ := nkeys.CreateAccount()
+	accountSeed, _ := accountKP.Seed()
+	certs := newTestTLSCertificates(t)
+
+	defaults := &NATSConnectionModel{
+		Creds:   types.StringValue(testCreds(t, userJWT, userKP)),
+		TLSCA:   types.StringValue(certs.CA),
+		Timeout: timetypes.NewGoDurationValueFromStringMust("3s"),
+	}
+
+	// Everything but the URL from the provider
+	opts, timeout, diags := NATSConnectionModel{}.natsConnectOptions(defaults, nil, time.Second)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if opts.UserJWT != userJWT || opts.TLS == nil || opts.TLS.RootCAs == nil {
+		t.Errorf("expected creds and CA of the provider, got %+v", opts)
+	}
+	if timeout != 3*time.Second {
+		t.Errorf("expected provider timeout 3s, got %s", timeout)
+	}
+
+	// Authentication is overridden as a whole, the CA is kept
+	opts, timeout, diags = NATSConnectionModel{
+		User:     types.StringValue("app"),
+		Password: types.StringValue("secret"),
+		Timeout:  timetypes.NewGoDurationValueFromStringMust("7s"),
+	}.natsConnectOptions(defaults, nil, time.Second)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if opts.UserJWT != "" || opts.KeyPair != nil || opts.User != "app" || opts.Password != "secret" {
+		t.Errorf("expected user and password only, got %+v", opts)
+	}
+	if opts.TLS == nil || opts.TLS.RootCAs == nil {
+		t.Error("expected the CA of the provider")
+	}
+	if timeout != 7*time.Second {
+		t.Errorf("expected data source timeout 7s, got %s", timeout)
+	}
+
+	opts, timeout, diags = NATSConnectionModel{
+		NKeySeed: types.StringValue(string(nkeySeed)),
+	}.natsConnectOptions(nil, nil, time.Second)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if publicKey, _ := opts.KeyPair.PublicKey(); publicKey != nkeyPubKey || opts.UserJWT != "" || opts.TLS != nil {
+		t.Errorf("expected nkey %s without TLS, got %+v", nkeyPubKey, opts)
+	}
+	if timeout != time.Second {
+		t.Errorf("expected default timeout 1s, got %s", timeout)
+	}
+
+	for name, tt := range map[string]struct {
+		model NATSConnectionModel
+		want  string
+	}{
+		"missing credentials": {NATSConnectionModel{}, "Missing NATS credentials"},
+		"account nkey": {NATSConnectionModel{
+			NKeySeed: types.StringValue(string(accountSeed)),
+		}, "Invalid nkey seed"},
+		"invalid CA": {NATSConnectionModel{
+			User:  types.StringValue("app"),
+			TLSCA: types.StringValue("not a certificate"),
+		}, "Invalid TLS CA"},
+		"mismatched key": {NATSConnectionModel{
+			User:    types.StringValue("app"),
+			TLSCert: types.StringValue(certs.ClientCert),
+			TLSKey:  types.StringValue(certs.ServerKey),
+		}, "Invalid TLS client certificate"},
+	} {
+		_, _, diags := tt.model.natsConnectOptions(nil, nil, time.Second)
+		if !diags.HasError() || !strings.Contains(diags[0].Summary(), tt.want) {
+			t.Errorf("%s: expected %q error, got %v", name, tt.want, diags)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+// defaultResponseMaxMsgs is the number of responses allowed when a resp
+// block does not set max, matching the nsc CLI default.
+const defaultResponseMaxMsgs = 1
+
+// responseUnlimited is the number of responses that lifts the limit on
+// responses per request.
+const responseUnlimited = -1
+
+// responseMaxMsgs validates a number of responses per request: a positive
+// number or -1 for unlimited.
+func responseMaxMsgs() validator.Int64 {
+	return int64validator.Any(
+		int64validator.OneOf(responseUnlimited),
+		int64validator.AtLeast(1),
+	)
+}
+
+// PermissionsModel is the structured alternative to the flat
+// allow_pub/allow_sub/deny_pub/deny_sub/allow_pub_response/response_ttl
+// attributes.
+type PermissionsModel struct {
+	Pub  *SubjectPermissionModel  `tfsdk:"pub"`
+	Sub  *SubjectPermissionModel  `tfsdk:"sub"`
+	Resp *ResponsePermissionModel `tfsdk:"resp"`
+}
+
+type SubjectPermissionModel struct {
+	Allow types.List `tfsdk:"allow"`
+	Deny  types.List `tfsdk:"deny"`
+}
+
+type ResponsePermissionModel struct {
+	Max types.Int64          `tfsdk:"max"`
+	TTL timetypes.GoDuration `tfsdk:"ttl"`
+}
+
+// permissionsBlock returns the schema of a structured permissions block. The
+// block conflicts with the flat permission attributes of the same resource.
+func permissionsBlock(description string) schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: description + " Alternative to the flat `allow_pub`, `allow_sub`, `deny_pub`, `deny_sub`, `allow_pub_response` and `response_ttl` attributes, which cannot be combined with this block.",
+		Validators: []validator.Object{
+			objectvalidator.ConflictsWith(
+				path.MatchRoot("allow_pub"),
+				path.MatchRoot("allow_sub"),
+				path.MatchRoot("deny_pub"),
+				path.MatchRoot("deny_sub"),
+				path.MatchRoot("allow_pub_response"),
+				path.MatchRoot("response_ttl"),
+			),
+		},
+		Blocks: map[string]schema.Block{
+			"pub": schema.SingleNestedBlock{
+				MarkdownDescription: "Publish permissions",
+				Attributes: map[string]schema.Attribute{
+					"allow": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: "Subjects allowed for publishing",
+						Validators: []validator.List{
+							listvalidator.ValueStringsAre(publishPermission()),
+						},
+					},
+					"deny": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: "Subjects denied for publishing",
+						Validators: []validator.List{
+							listvalidator.ValueStringsAre(publishPermission()),
+						},
+					},
+				},
+			},
+			"sub": schema.SingleNestedBlock{
+				MarkdownDescription: "Subscribe permissions",
+				Attributes: map[string]schema.Attribute{
+					"allow": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: "Subjects allowed for subscribing. Use `\"subject queue\"` to restrict subscriptions to a queue group",
+						Validators: []validator.List{
+							listvalidator.ValueStringsAre(subscribePermission()),
+						},
+					},
+					"deny": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: "Subjects denied for subscribing. Use `\"subject queue\"` to target a queue group",
+						Validators: []validator.List{
+							listvalidator.ValueStringsAre(subscribePermission()),
+						},
+					},
+				},
+			},
+			"resp": schema.SingleNestedBlock{
+				MarkdownDescription: "Allow publishing to reply subjects of received requests",
+				Attributes: map[string]schema.Attribute{
+					"max": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: "Maximum number of responses per request (-1 for unlimited). Defaults to 1.",
+						Validators: []validator.Int64{
+							responseMaxMsgs(),
+						},
+					},
+					"ttl": schema.StringAttribute{
+						CustomType:          timetypes.GoDurationType{},
+						Optional:            true,
+						MarkdownDescription: "Time limit for responses",
+						Validators: []validator.String{
+							nonNegativeDuration(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// apply sets the permissions on the JWT permissions.
+func (m *PermissionsModel) apply(ctx context.Context, p *jwt.Permissions) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Pub != nil {
+		diags.Append(m.Pub.apply(ctx, &p.Pub)...)
+	}
+	if m.Sub != nil {
+		diags.Append(m.Sub.apply(ctx, &p.Sub)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	if m.Resp != nil {
+		p.Resp = &jwt.ResponsePermission{
+			MaxMsgs: defaultResponseMaxMsgs,
+		}
+		if !m.Resp.Max.IsNull() && !m.Resp.Max.IsUnknown() {
+			p.Resp.MaxMsgs = int(m.Resp.Max.ValueInt64())
+		}
+		if !m.Resp.TTL.IsNull() && !m.Resp.TTL.IsUnknown() {
+			duration, d := m.Resp.TTL.ValueGoDuration()
+			diags.Append(d...)
+			if diags.HasError() {
+				return diags
+			}
+			p.Resp.Expires = duration
+		}
+	}
+
+	return diags
+}
+
+func (m *SubjectPermissionModel) apply(ctx context.Context, p *jwt.Permission) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	allow, d := stringListValues(ctx, m.Allow)
+	diags.Append(d...)
+	deny, d := stringListValues(ctx, m.Deny)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	p.Allow = allow
+	p.Deny = deny
+
+	return diags
+}
+
+// normalizePermissionLists sorts the subjects of the permissions and removes
+// duplicates, for normalize_permissions.
+func normalizePermissionLists(p *jwt.Permissions) {
+	for _, list := range []*jwt.StringList{&p.Pub.Allow, &p.Pub.Deny, &p.Sub.Allow, &p.Sub.Deny} {
+		*list = normalizePermissionList(*list)
+	}
+}
+
+func normalizePermissionList(entries []string) jwt.StringList {
+	if len(entries) == 0 {
+		return entries
+	}
+	normalized := make(jwt.StringList, len(entries))
+	for i, entry := range entries {
+		normalized[i] = normalizePermissionEntry(entry)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// permissionAttributes are the flat permission lists and the permissions
+// block of an account or user.
+type permissionAttributes struct {
+	AllowPub, AllowSub, DenyPub, DenySub types.List
+	Block                                *PermissionsModel
+}
+
+func accountPermissionAttributes(m AccountResourceModel) permissionAttributes {
+	return permissionAttributes{m.AllowPub, m.AllowSub, m.DenyPub, m.DenySub, m.DefaultPermissions}
+}
+
+func userPermissionAttributes(m UserResourceModel) permissionAttributes {
+	return permissionAttributes{m.AllowPub, m.AllowSub, m.DenyPub, m.DenySub, m.Permissions}
+}
+
+// normalizedPermissionAttributes returns the names of the permission
+// attributes whose state and planned values differ only in order,
+// duplicates or whitespace, which normalize_permissions removes from the
+// JWT. block is the name of the permissions block.
+func normalizedPermissionAttributes(ctx context.Context, block string, state, plan permissionAttributes) []string {
+	var names []string
+	for name, lists := range map[string][2]types.List{
+		"allow_pub": {state.AllowPub, plan.AllowPub},
+		"allow_sub": {state.AllowSub, plan.AllowSub},
+		"deny_pub":  {state.DenyPub, plan.DenyPub},
+		"deny_sub":  {state.DenySub, plan.DenySub},
+	} {
+		if samePermissionList(ctx, lists[0], lists[1]) {
+			names = append(names, name)
+		}
+	}
+	if samePermissions(ctx, state.Block, plan.Block) {
+		names = append(names, block)
+	}
+	return names
+}
+
+// samePermissions reports whether two permissions blocks are the same once
+// their lists are normalized.
+func samePermissions(ctx context.Context, a, b *PermissionsModel) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	sameSubject := func(a, b *SubjectPermissionModel) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return samePermissionList(ctx, a.Allow, b.Allow) && samePermissionList(ctx, a.Deny, b.Deny)
+	}
+	sameResp := a.Resp == nil && b.Resp == nil ||
+		a.Resp != nil && b.Resp != nil && a.Resp.Max.Equal(b.Resp.Max) && a.Resp.TTL.Equal(b.Resp.TTL)
+	return sameSubject(a.Pub, b.Pub) && sameSubject(a.Sub, b.Sub) && sameResp
+}
+
+// samePermissionList reports whether two permission lists are the same once
+// normalized. Lists with unknown values never are.
+func samePermissionList(ctx context.Context, a, b types.List) bool {
+	if listHasUnknown(a) || listHasUnknown(b) {
+		return false
+	}
+	aValues, diags := stringListValues(ctx, a)
+	if diags.HasError() {
+		return false
+	}
+	bValues, diags := stringListValues(ctx, b)
+	if diags.HasError() {
+		return false
+	}
+	return slices.Equal(normalizePermissionList(aValues), normalizePermissionList(bValues))
+}
+
+// validate warns about allowed subjects that a deny of the same block
+// entirely covers, as the deny takes precedence in nats-server. Lists with
+// unknown values are skipped.
+func (m *PermissionsModel) validate(ctx context.Context, p path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for _, direction := range []struct {
+		name       string
+		permission *SubjectPermissionModel
+	}{
+		{"pub", m.Pub},
+		{"sub", m.Sub},
+	} {
+		if direction.permission == nil || listHasUnknown(direction.permission.Allow) || listHasUnknown(direction.permission.Deny) {
+			continue
+		}
+		allow, d := stringListValues(ctx, direction.permission.Allow)
+		diags.Append(d...)
+		deny, d := stringListValues(ctx, direction.permission.Deny)
+		diags.Append(d...)
+		if diags.HasError() {
+			return diags
+		}
+
+		for i, allowed := range allow {
+			for _, denied := range deny {
+				if permissionEntryCovers(normalizePermissionEntry(denied), normalizePermissionEntry(allowed)) {
+					diags.AddAttributeWarning(
+						p.AtName(direction.name).AtName("allow").AtListIndex(i),
+						"Permission has no effect",
+						fmt.Sprintf("%q is allowed but denied by %q; denies take precedence, so the allow has no effect.", allowed, denied),
+					)
+					break
+				}
+			}
+		}
+	}
+
+	return diags
+}
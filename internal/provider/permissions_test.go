@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestPermissionsModel_apply(t *testing.T) {
+	ctx := context.Background()
+
+	list := func(values ...string) types.List {
+		l, _ := types.ListValueFrom(ctx, types.StringType, values)
+		return l
+	}
+
+	tests := []struct {
+		name     string
+		model    PermissionsModel
+		expected jwt.Permissions
+	}{
+		{
+			name: "pub and sub",
+			model: PermissionsModel{
+				Pub: &SubjectPermissionModel{Allow: list("app.>"), Deny: list("admin.>")},
+				Sub: &SubjectPermissionModel{Allow: list("app.>", "_INBOX.>"), Deny: types.ListNull(types.StringType)},
+			},
+			expected: jwt.Permissions{
+				Pub: jwt.Permission{Allow: jwt.StringList{"app.>"}, Deny: jwt.StringList{"admin.>"}},
+				Sub: jwt.Permission{Allow: jwt.StringList{"app.>", "_INBOX.>"}},
+			},
+		},
+		{
+			name: "resp defaults",
+			model: PermissionsModel{
+				Resp: &ResponsePermissionModel{Max: types.Int64Null(), TTL: timetypes.NewGoDurationNull()},
+			},
+			expected: jwt.Permissions{
+				Resp: &jwt.ResponsePermission{MaxMsgs: 1},
+			},
+		},
+		{
+			name: "resp",
+			model: PermissionsModel{
+				Resp: &ResponsePermissionModel{Max: types.Int64Value(10), TTL: timetypes.NewGoDurationValue(time.Minute)},
+			},
+			expected: jwt.Permissions{
+				Resp: &jwt.ResponsePermission{MaxMsgs: 10, Expires: time.Minute},
+			},
+		},
+		{
+			name: "resp unlimited",
+			model: PermissionsModel{
+				Resp: &ResponsePermissionModel{Max: types.Int64Value(-1), TTL: timetypes.NewGoDurationNull()},
+			},
+			expected: jwt.Permissions{
+				Resp: &jwt.ResponsePermission{MaxMsgs: -1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p jwt.Permissions
+			if diags := tt.model.apply(ctx, &p); diags.HasError() {
+				t.Fatal(diags)
+			}
+
+			if !reflect.DeepEqual(p, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, p)
+			}
+		})
+	}
+}
+
+func TestPermissionsModel_validate(t *testing.T) {
+	ctx := context.Background()
+
+	list := func(values ...string) types.List {
+		l, _ := types.ListValueFrom(ctx, types.StringType, values)
+		return l
+	}
+
+	model := PermissionsModel{
+		Pub: &SubjectPermissionModel{Allow: list("app.>", "admin.users"), Deny: list("admin.>")},
+		Sub: &SubjectPermissionModel{Allow: list("app.>"), Deny: list("app.internal")},
+	}
+
+	diags := model.validate(ctx, path.Root("permissions"))
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if diags.WarningsCount() != 1 {
+		t.Fatalf("expected 1 warning, got %v", diags)
+	}
+	if want := path.Root("permissions").AtName("pub").AtName("allow").AtListIndex(1); !diags[0].(diag.DiagnosticWithPath).Path().Equal(want) {
+		t.Errorf("expected warning at %s, got %s", want, diags[0].(diag.DiagnosticWithPath).Path())
+	}
+}
+
+func TestNormalizePermissionLists(t *testing.T) {
+	permissions := jwt.Permissions{
+		Pub: jwt.Permission{Allow: jwt.StringList{"b.>", "a.>", "b.>"}},
+		Sub: jwt.Permission{Allow: jwt.StringList{"jobs  workers", "app.>"}, Deny: jwt.StringList{}},
+	}
+	normalizePermissionLists(&permissions)
+
+	expected := jwt.Permissions{
+		Pub: jwt.Permission{Allow: jwt.StringList{"a.>", "b.>"}},
+		Sub: jwt.Permission{Allow: jwt.StringList{"app.>", "jobs workers"}, Deny: jwt.StringList{}},
+	}
+	if !reflect.DeepEqual(permissions, expected) {
+		t.Errorf("expected %+v, got %+v", expected, permissions)
+	}
+}
+
+func TestNormalizedPermissionAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	list := func(values ...string) types.List {
+		l, _ := types.ListValueFrom(ctx, types.StringType, values)
+		return l
+	}
+	null := types.ListNull(types.StringType)
+
+	state := permissionAttributes{
+		AllowPub: list("a.>", "b.>"),
+		AllowSub: list("a.>"),
+		DenyPub:  null,
+		DenySub:  list("secret.>"),
+		Block: &PermissionsModel{
+			Pub: &SubjectPermissionModel{Allow: list("x.>", "y.>"), Deny: null},
+		},
+	}
+	plan := permissionAttributes{
+		AllowPub: list("b.>", "a.>", "a.>"),
+		AllowSub: list("a.>", "c.>"),
+		DenyPub:  null,
+		DenySub:  types.ListUnknown(types.StringType),
+		Block: &PermissionsModel{
+			Pub: &SubjectPermissionModel{Allow: list("y.>", "x.>"), Deny: null},
+		},
+	}
+
+	got := normalizedPermissionAttributes(ctx, "permissions", state, plan)
+	slices.Sort(got)
+	expected := []string{"allow_pub", "deny_pub", "permissions"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,344 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+)
+
+const (
+	policySeverityError   = "error"
+	policySeverityWarning = "warning"
+)
+
+// policyPlanSubject stands in for a subject that is unknown at plan time, so
+// the claims can be built for the policy check.
+const policyPlanSubject = "unknown"
+
+// PolicyRuleModel is a policy block of the provider.
+type PolicyRuleModel struct {
+	Name         types.String         `tfsdk:"name"`
+	ClaimTypes   types.Set            `tfsdk:"claim_types"`
+	Severity     types.String         `tfsdk:"severity"`
+	DenyAllowPub types.List           `tfsdk:"deny_allow_pub"`
+	DenyAllowSub types.List           `tfsdk:"deny_allow_sub"`
+	RequireTags  types.List           `tfsdk:"require_tags"`
+	MaxExpiresIn timetypes.GoDuration `tfsdk:"max_expires_in"`
+	ForbidBearer types.Bool           `tfsdk:"forbid_bearer"`
+}
+
+// policyBlock returns the policy block of the provider schema.
+func policyBlock() schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		MarkdownDescription: "Rules the claims of every operator, account and user JWT the resources issue must follow. Rules are checked at plan time against the claims built from the plan, and again against the signed JWT on apply, which includes values unknown at plan time. The JWTs of `nsc_operator_set` and `nsc_auth_callout_user` are checked on apply only. See [Policies](#policies).",
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"name": schema.StringAttribute{
+					Required:            true,
+					MarkdownDescription: "Name of the rule, shown in the diagnostics of violations.",
+					Validators: []validator.String{
+						stringvalidator.LengthAtLeast(1),
+					},
+				},
+				"claim_types": schema.SetAttribute{
+					ElementType:         types.StringType,
+					Optional:            true,
+					MarkdownDescription: "Claim types the rule applies to, out of `operator`, `account` and `user`. Defaults to all.",
+					Validators: []validator.Set{
+						setvalidator.ValueStringsAre(stringvalidator.OneOf(issuedClaimTypes...)),
+					},
+				},
+				"severity": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: "`error` fails the plan on a violation, `warning` only reports it. Defaults to `error`.",
+					Validators: []validator.String{
+						stringvalidator.OneOf(policySeverityError, policySeverityWarning),
+					},
+				},
+				"deny_allow_pub": schema.ListAttribute{
+					ElementType:         types.StringType,
+					Optional:            true,
+					MarkdownDescription: "Subjects the publish permissions of users and the default permissions of accounts must not allow. An allowed subject violates the rule when it overlaps one of them, e.g. `>` overlaps `$SYS.>`. JWTs without allowed publish subjects are not checked.",
+				},
+				"deny_allow_sub": schema.ListAttribute{
+					ElementType:         types.StringType,
+					Optional:            true,
+					MarkdownDescription: "Subjects the subscribe permissions of users and the default permissions of accounts must not allow, like `deny_allow_pub`.",
+				},
+				"require_tags": schema.ListAttribute{
+					ElementType:         types.StringType,
+					Optional:            true,
+					MarkdownDescription: "Tags the JWT must have, each as a shell pattern, e.g. `team:*`. Tags are compared case-insensitively.",
+				},
+				"max_expires_in": schema.StringAttribute{
+					CustomType:          timetypes.GoDurationType{},
+					Optional:            true,
+					MarkdownDescription: "Longest validity of the JWT from the time it is issued, e.g. `2160h`. JWTs without expiry violate the rule.",
+					Validators: []validator.String{
+						nonNegativeDuration(),
+					},
+				},
+				"forbid_bearer": schema.BoolAttribute{
+					Optional:            true,
+					MarkdownDescription: "User JWTs must not be bearer tokens.",
+				},
+			},
+		},
+	}
+}
+
+// claimsPolicy holds the policy rules of the provider. A nil policy has no
+// rules.
+type claimsPolicy []policyRule
+
+type policyRule struct {
+	name         string
+	claimTypes   []string
+	warning      bool
+	denyAllowPub []string
+	denyAllowSub []string
+	requireTags  []string
+	maxExpiresIn time.Duration
+	forbidBearer bool
+}
+
+// newClaimsPolicy parses the policy blocks of the provider.
+func newClaimsPolicy(ctx context.Context, rules []PolicyRuleModel) (claimsPolicy, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var policy claimsPolicy
+
+	for i, rule := range rules {
+		rulePath := tfpath.Root("policy").AtListIndex(i)
+		parsed := policyRule{
+			name:         rule.Name.ValueString(),
+			warning:      rule.Severity.ValueString() == policySeverityWarning,
+			forbidBearer: rule.ForbidBearer.ValueBool(),
+		}
+
+		if !rule.ClaimTypes.IsNull() && !rule.ClaimTypes.IsUnknown() {
+			diags.Append(rule.ClaimTypes.ElementsAs(ctx, &parsed.claimTypes, false)...)
+		}
+		for _, list := range []struct {
+			value  types.List
+			target *[]string
+		}{
+			{rule.DenyAllowPub, &parsed.denyAllowPub},
+			{rule.DenyAllowSub, &parsed.denyAllowSub},
+			{rule.RequireTags, &parsed.requireTags},
+		} {
+			values, d := stringListValues(ctx, list.value)
+			diags.Append(d...)
+			*list.target = values
+		}
+		if !rule.MaxExpiresIn.IsNull() && !rule.MaxExpiresIn.IsUnknown() {
+			duration, d := rule.MaxExpiresIn.ValueGoDuration()
+			diags.Append(d...)
+			parsed.maxExpiresIn = duration
+		}
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		for j, pattern := range parsed.requireTags {
+			if _, err := path.Match(pattern, ""); err != nil {
+				diags.AddAttributeError(rulePath.AtName("require_tags").AtListIndex(j), "Invalid tag pattern", err.Error())
+			}
+		}
+		if len(parsed.denyAllowPub) == 0 && len(parsed.denyAllowSub) == 0 && len(parsed.requireTags) == 0 &&
+			parsed.maxExpiresIn == 0 && !parsed.forbidBearer {
+			diags.AddAttributeError(
+				rulePath,
+				"Empty policy rule",
+				fmt.Sprintf("Policy %q checks nothing. Set deny_allow_pub, deny_allow_sub, require_tags, max_expires_in or forbid_bearer.", parsed.name),
+			)
+		}
+
+		policy = append(policy, parsed)
+	}
+
+	return policy, diags
+}
+
+// policyUnknown marks the checks depending on values not known at plan time.
+type policyUnknown struct {
+	tags   bool
+	expiry bool
+}
+
+// check evaluates the rules against claims and reports every violation as an
+// error or a warning, by the severity of the rule. Checks marked unknown are
+// skipped.
+func (p claimsPolicy) check(claims jwt.Claims, now time.Time, unknown policyUnknown) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// The claim type is only set on encoding, so it is taken from the Go type
+	var kind string
+	var tags jwt.TagList
+	var permissions *jwt.Permissions
+	bearer := false
+	switch c := claims.(type) {
+	case *jwt.OperatorClaims:
+		kind = "operator"
+		tags = c.Tags
+	case *jwt.AccountClaims:
+		kind = "account"
+		tags = c.Tags
+		permissions = &c.DefaultPermissions
+	case *jwt.UserClaims:
+		kind = "user"
+		tags = c.Tags
+		permissions = &c.Permissions
+		bearer = c.BearerToken
+	}
+	data := claims.Claims()
+
+	for _, rule := range p {
+		if len(rule.claimTypes) > 0 && !slices.Contains(rule.claimTypes, kind) {
+			continue
+		}
+
+		var violations []string
+		if permissions != nil {
+			violations = append(violations, deniedSubjects("publishing to", permissions.Pub.Allow, rule.denyAllowPub)...)
+			violations = append(violations, deniedSubjects("subscribing to", permissions.Sub.Allow, rule.denyAllowSub)...)
+		}
+		for _, pattern := range rule.requireTags {
+			if unknown.tags {
+				break
+			}
+			if !slices.ContainsFunc(tags, func(tag string) bool {
+				matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(tag))
+				return matched
+			}) {
+				violations = append(violations, fmt.Sprintf("has no tag matching %q", pattern))
+			}
+		}
+		if rule.maxExpiresIn > 0 && !unknown.expiry {
+			if data.Expires == 0 {
+				violations = append(violations, fmt.Sprintf("does not expire, but must within %s", rule.maxExpiresIn))
+			} else if validity := time.Unix(data.Expires, 0).Sub(now); validity > rule.maxExpiresIn {
+				violations = append(violations, fmt.Sprintf("expires in %s, more than %s", validity.Round(time.Second), rule.maxExpiresIn))
+			}
+		}
+		if rule.forbidBearer && bearer {
+			violations = append(violations, "is a bearer token")
+		}
+
+		for _, violation := range violations {
+			detail := fmt.Sprintf("Policy %q: the %s JWT of %q %s.", rule.name, kind, data.Name, violation)
+			if rule.warning {
+				diags.AddWarning("Policy violation", detail)
+			} else {
+				diags.AddError("Policy violation", detail)
+			}
+		}
+	}
+
+	return diags
+}
+
+// checkToken evaluates the rules against the claims of a signed JWT, which
+// include custom claims. Only errors are reported when the rules were
+// already checked at plan time, so warnings do not repeat on apply.
+func (p claimsPolicy) checkToken(token string, errorsOnly bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(p) == 0 {
+		return diags
+	}
+	claims, err := jwt.Decode(token)
+	if err != nil {
+		diags.AddError("Failed to decode JWT", err.Error())
+		return diags
+	}
+
+	diags = p.check(claims, time.Now(), policyUnknown{})
+	if errorsOnly {
+		return diags.Errors()
+	}
+	return diags
+}
+
+// deniedSubjects lists the allowed subjects that overlap a denied subject.
+// Subscribe permissions may carry a queue group after the subject.
+func deniedSubjects(action string, allowed jwt.StringList, denied []string) []string {
+	var violations []string
+	for _, entry := range allowed {
+		subject, _, _ := strings.Cut(entry, " ")
+		for _, deny := range denied {
+			if jwt.Subject(subject).IsContainedIn(jwt.Subject(deny)) || jwt.Subject(deny).IsContainedIn(jwt.Subject(subject)) {
+				violations = append(violations, fmt.Sprintf("allows %s %q, which overlaps the denied %q", action, subject, deny))
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// checkPlan evaluates the rules against claims built from a planned resource,
+// with custom claims JSON merged and the claims patch applied as on signing.
+// Unknown custom claims JSON or claims patches may add tags and change the
+// expiry, so those checks wait for the signed JWT on apply, as do the checks
+// marked unknown.
+func (p claimsPolicy) checkPlan(claims jwt.Claims, custom, patch types.String, unknown policyUnknown) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(p) == 0 {
+		return diags
+	}
+	if custom.IsUnknown() || patch.IsUnknown() {
+		unknown = policyUnknown{tags: true, expiry: true}
+	} else if !custom.IsNull() || !patch.IsNull() {
+		payload, err := json.Marshal(claims)
+		if err == nil {
+			payload, err = mergeCustomClaims(payload, custom)
+		}
+		if err == nil {
+			payload, err = applyClaimsPatch(payload, patch)
+		}
+		if err != nil {
+			// Invalid custom claims or claims patches fail validation and
+			// signing instead
+			return diags
+		}
+		if err := json.Unmarshal(payload, claims); err != nil {
+			return diags
+		}
+	}
+
+	return p.check(claims, time.Now(), unknown)
+}
+
+// policyPlanExpiry returns the expiry attributes of the configuration of a
+// planned resource for building its claims, since the planned expires_at of
+// a JWT without expiry stays unknown until apply. unknown reports an expiry
+// not known yet.
+func policyPlanExpiry(ctx context.Context, req resource.ModifyPlanRequest) (expiresIn timetypes.GoDuration, expiresAt timetypes.RFC3339, unknown bool, diags diag.Diagnostics) {
+	diags.Append(req.Config.GetAttribute(ctx, tfpath.Root("expires_in"), &expiresIn)...)
+	diags.Append(req.Config.GetAttribute(ctx, tfpath.Root("expires_at"), &expiresAt)...)
+	return expiresIn, expiresAt, expiresIn.IsUnknown() || expiresAt.IsUnknown(), diags
+}
+
+// planSubject returns the subject of a plan for building claims to check,
+// with a placeholder for an unknown subject.
+func planSubject(subject types.String) types.String {
+	if subject.IsUnknown() || subject.ValueString() == "" {
+		return types.StringValue(policyPlanSubject)
+	}
+	return subject
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestClaimsPolicy_check(t *testing.T) {
+	// Expiry is in whole seconds
+	now := time.Unix(time.Now().Unix(), 0)
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+
+	policy := claimsPolicy{
+		{name: "no-system", claimTypes: []string{"user", "account"}, denyAllowPub: []string{"$SYS.>"}, denyAllowSub: []string{"$SYS.>"}},
+		{name: "tags", requireTags: []string{"team:*"}},
+		{name: "expiry", claimTypes: []string{"user"}, maxExpiresIn: 24 * time.Hour, warning: true},
+		{name: "bearer", forbidBearer: true},
+	}
+
+	compliant := jwt.NewUserClaims(userPubKey)
+	compliant.Name = "app"
+	compliant.Pub.Allow.Add("app.>")
+	compliant.Sub.Allow.Add("app.> workers")
+	compliant.Tags.Add("Team:Payments")
+	compliant.Expires = now.Add(time.Hour).Unix()
+
+	if diags := policy.check(compliant, now, policyUnknown{}); len(diags) != 0 {
+		t.Errorf("expected no violations, got %v", diags)
+	}
+
+	violating := jwt.NewUserClaims(userPubKey)
+	violating.Name = "admin"
+	violating.Pub.Allow.Add(">")
+	violating.Sub.Allow.Add("$SYS.REQ.> workers")
+	violating.BearerToken = true
+
+	diags := policy.check(violating, now, policyUnknown{})
+	if diags.ErrorsCount() != 4 || diags.WarningsCount() != 1 {
+		t.Fatalf("expected 4 errors and 1 warning, got %v", diags)
+	}
+	for _, want := range []string{
+		`Policy "no-system": the user JWT of "admin" allows publishing to ">", which overlaps the denied "$SYS.>".`,
+		`Policy "no-system": the user JWT of "admin" allows subscribing to "$SYS.REQ.>", which overlaps the denied "$SYS.>".`,
+		`Policy "tags": the user JWT of "admin" has no tag matching "team:*".`,
+		`Policy "bearer": the user JWT of "admin" is a bearer token.`,
+	} {
+		found := false
+		for _, d := range diags.Errors() {
+			found = found || d.Detail() == want
+		}
+		if !found {
+			t.Errorf("missing violation %q in %v", want, diags)
+		}
+	}
+
+	// Unknown tags and expiry are not checked
+	if diags := policy.check(violating, now, policyUnknown{tags: true, expiry: true}); diags.ErrorsCount() != 3 || diags.WarningsCount() != 0 {
+		t.Errorf("expected 3 errors, got %v", diags)
+	}
+
+	violating.Expires = now.Add(48 * time.Hour).Unix()
+	if diags := policy.check(violating, now, policyUnknown{}); diags.WarningsCount() != 1 ||
+		!strings.Contains(diags.Warnings()[0].Detail(), "expires in 48h0m0s, more than 24h0m0s") {
+		t.Errorf("expected expiry warning, got %v", diags)
+	}
+
+	// Rules not applying to the claim type are skipped
+	account := jwt.NewAccountClaims(accountPubKey)
+	account.Name = "A"
+	account.Tags.Add("team:core")
+	if diags := policy.check(account, now, policyUnknown{}); len(diags) != 0 {
+		t.Errorf("expected no violations, got %v", diags)
+	}
+	account.DefaultPermissions.Pub.Allow.Add("$SYS.REQ.ACCOUNT.>")
+	if diags := policy.check(account, now, policyUnknown{}); diags.ErrorsCount() != 1 {
+		t.Errorf("expected 1 error, got %v", diags)
+	}
+}
+
+func TestClaimsPolicy_checkPlan(t *testing.T) {
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	policy := claimsPolicy{{name: "tags", requireTags: []string{"team:*"}}}
+
+	claims := jwt.NewUserClaims(userPubKey)
+	if diags := policy.checkPlan(claims, types.StringValue(`{"nats":{"tags":["team:core"]}}`), types.StringNull(), policyUnknown{}); len(diags) != 0 {
+		t.Errorf("expected tags from custom claims to pass, got %v", diags)
+	}
+
+	claims = jwt.NewUserClaims(userPubKey)
+	if diags := policy.checkPlan(claims, types.StringUnknown(), types.StringNull(), policyUnknown{}); len(diags) != 0 {
+		t.Errorf("expected unknown custom claims to skip the tags check, got %v", diags)
+	}
+	if diags := policy.checkPlan(claims, types.StringNull(), types.StringNull(), policyUnknown{}); !diags.HasError() {
+		t.Error("expected missing tags error")
+	}
+
+	claims = jwt.NewUserClaims(userPubKey)
+	if diags := policy.checkPlan(claims, types.StringNull(), types.StringValue(`{"nats":{"tags":["team:core"]}}`), policyUnknown{}); len(diags) != 0 {
+		t.Errorf("expected tags from the claims patch to pass, got %v", diags)
+	}
+	claims = jwt.NewUserClaims(userPubKey)
+	if diags := policy.checkPlan(claims, types.StringNull(), types.StringUnknown(), policyUnknown{}); len(diags) != 0 {
+		t.Errorf("expected an unknown claims patch to skip the tags check, got %v", diags)
+	}
+}
+
+func TestNewClaimsPolicy(t *testing.T) {
+	ctx := context.Background()
+	rule := PolicyRuleModel{
+		Name:         types.StringValue("strict"),
+		ClaimTypes:   types.SetValueMust(types.StringType, []attr.Value{types.StringValue("user")}),
+		Severity:     types.StringValue("warning"),
+		DenyAllowPub: types.ListValueMust(types.StringType, []attr.Value{types.StringValue(">")}),
+		DenyAllowSub: types.ListNull(types.StringType),
+		RequireTags:  types.ListNull(types.StringType),
+		MaxExpiresIn: timetypes.NewGoDurationValueFromStringMust("720h"),
+		ForbidBearer: types.BoolNull(),
+	}
+
+	policy, diags := newClaimsPolicy(ctx, []PolicyRuleModel{rule})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if len(policy) != 1 || !policy[0].warning || policy[0].maxExpiresIn != 720*time.Hour ||
+		len(policy[0].claimTypes) != 1 || len(policy[0].denyAllowPub) != 1 {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	empty := rule
+	empty.DenyAllowPub = types.ListNull(types.StringType)
+	empty.MaxExpiresIn = timetypes.NewGoDurationNull()
+	if _, diags := newClaimsPolicy(ctx, []PolicyRuleModel{empty}); !diags.HasError() || diags[0].Summary() != "Empty policy rule" {
+		t.Errorf("expected empty rule error, got %v", diags)
+	}
+
+	invalid := rule
+	invalid.RequireTags = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("team:[")})
+	if _, diags := newClaimsPolicy(ctx, []PolicyRuleModel{invalid}); !diags.HasError() || diags[0].Summary() != "Invalid tag pattern" {
+		t.Errorf("expected invalid pattern error, got %v", diags)
+	}
+}
+
+func TestAccPolicy(t *testing.T) {
+	policyConfig := `
+provider "nsc" {
+  policy {
+    name           = "no-system-publish"
+    claim_types    = ["user"]
+    deny_allow_pub = ["$SYS.>"]
+  }
+
+  policy {
+    name         = "team-tags"
+    claim_types  = ["user"]
+    severity     = "warning"
+    require_tags = ["team:*"]
+  }
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: policyConfig + testAccUserResourceConfigWithPermissions(),
+				Check:  resource.TestCheckResourceAttrSet("nsc_user.test", "jwt"),
+			},
+			{
+				Config:      policyConfig + strings.Replace(testAccUserResourceConfigWithPermissions(), `"app.events.>"`, `">"`, 1),
+				ExpectError: regexp.MustCompile(`overlaps the denied "\$SYS\.>"`),
+			},
+		},
+	})
+}
@@ -2,12 +2,21 @@ package provider
 
 import (
 	"context"
+	"os"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var _ provider.Provider = &NSCProvider{}
@@ -17,7 +26,57 @@ type NSCProvider struct {
 	version string
 }
 
-type NSCProviderModel struct{}
+type NSCProviderModel struct {
+	Signer             *SignerModel         `tfsdk:"signer"`
+	WarnExpiryWithin   timetypes.GoDuration `tfsdk:"warn_expiry_within"`
+	RequireExpiry      types.Set            `tfsdk:"require_expiry"`
+	Policy             []PolicyRuleModel    `tfsdk:"policy"`
+	AuditLog           types.String         `tfsdk:"audit_log"`
+	StateEncryptionKey types.String         `tfsdk:"state_encryption_key"`
+	NATS               *NATSConnectionModel `tfsdk:"nats"`
+}
+
+type SignerModel struct {
+	Vault *VaultSignerModel `tfsdk:"vault"`
+	Exec  *ExecSignerModel  `tfsdk:"exec"`
+}
+
+type VaultSignerModel struct {
+	Address   types.String `tfsdk:"address"`
+	Token     types.String `tfsdk:"token"`
+	Namespace types.String `tfsdk:"namespace"`
+	Mount     types.String `tfsdk:"mount"`
+}
+
+type ExecSignerModel struct {
+	Command types.String `tfsdk:"command"`
+	Args    types.List   `tfsdk:"args"`
+	Env     types.Map    `tfsdk:"env"`
+}
+
+// NSCProviderData is passed to resources that sign JWTs.
+type NSCProviderData struct {
+	// Signer signs JWTs for resources using issuer_key_name. Nil when no
+	// signer is configured.
+	Signer externalSigner
+	// Keys caches keypairs parsed from issuer seeds across resources.
+	Keys *keypairCache
+	// WarnExpiryWithin is the window in which expiring JWTs produce warnings.
+	// Zero disables the warnings.
+	WarnExpiryWithin time.Duration
+	// RequireExpiry lists the claim types whose JWTs must have an expiry.
+	RequireExpiry []string
+	// Policy holds the rules the claims of issued JWTs are checked against.
+	Policy claimsPolicy
+	// Audit records issued JWTs. Nil when no audit log is configured.
+	Audit *auditLog
+	// StateCipher encrypts generated seeds before they are written to state.
+	// Nil when no state encryption key is configured.
+	StateCipher *stateCipher
+	// NATS holds the default options of data sources connecting to a NATS
+	// server. Nil when no nats block is configured.
+	NATS *NATSConnectionModel
+}
 
 func (p *NSCProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "nsc"
@@ -25,8 +84,146 @@ func (p *NSCProvider) Metadata(ctx context.Context, req provider.MetadataRequest
 }
 
 func (p *NSCProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	natsValidators := natsConnectionValidators()
+
 	resp.Schema = schema.Schema{
 		MarkdownDescription: `Provider for managing NATS JWT tokens. All keys and JWTs are stored in Terraform state.`,
+
+		Attributes: map[string]schema.Attribute{
+			"warn_expiry_within": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Warn during refresh about operator, account, user and re-signed JWTs that expire within this duration, e.g. `720h`, or have expired. The warning names the JWT and the time remaining.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"require_expiry": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Claim types whose JWTs must expire, out of `operator`, `account` and `user`, e.g. `[\"account\", \"user\"]`. Plans fail for resources of the listed types set without `expires_in` or `expires_at`, and for `nsc_operator_set` and `nsc_auth_callout_user`, which issue JWTs without expiry.",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(issuedClaimTypes...)),
+				},
+			},
+			"audit_log": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path of a file to append a JSON line to for every operator, account, user and re-signed JWT issued during apply, e.g. for an issuance audit trail. See [Audit Log](#audit-log) for the record format.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"state_encryption_key": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Base64 encoded 256-bit key, e.g. from `openssl rand -base64 32`, to encrypt the seeds `nsc_nkey`, `nsc_operator_set`, `nsc_auth_callout_user`, `nsc_users`, `nsc_creds_file` and the signing key rotation resources write to state with. Resources using the seeds decrypt them transparently. Defaults to the `NSC_STATE_ENCRYPTION_KEY` environment variable. See [State Encryption](#state-encryption).",
+			},
+		},
+
+		Blocks: map[string]schema.Block{
+			"policy": policyBlock(),
+			"nats": schema.SingleNestedBlock{
+				MarkdownDescription: "Default connection options of the data sources connecting to a NATS server, `nsc_connection_check`, `nsc_jetstream_usage` and `nsc_resolver_drift`. Data sources override them attribute by attribute; authentication and the client certificate are overridden as a whole. See [NATS Connections](#nats-connections).",
+				Attributes: map[string]schema.Attribute{
+					"creds": schema.StringAttribute{
+						Optional:            true,
+						Sensitive:           true,
+						MarkdownDescription: "User credentials file content to authenticate with.",
+						Validators:          natsValidators["creds"],
+					},
+					"nkey_seed": schema.StringAttribute{
+						Optional:            true,
+						Sensitive:           true,
+						MarkdownDescription: "Seed of a user nkey to authenticate with, for servers configured with nkey users.",
+						Validators:          natsValidators["nkey_seed"],
+					},
+					"user": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Username to authenticate with.",
+						Validators:          natsValidators["user"],
+					},
+					"password": schema.StringAttribute{
+						Optional:            true,
+						Sensitive:           true,
+						MarkdownDescription: "Password of `user`.",
+						Validators:          natsValidators["password"],
+					},
+					"tls_ca": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "PEM encoded CA certificates to verify server certificates with instead of the system roots. Setting it forces TLS.",
+					},
+					"tls_cert": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "PEM encoded client certificate for mutual TLS. Setting it forces TLS.",
+						Validators:          natsValidators["tls_cert"],
+					},
+					"tls_key": schema.StringAttribute{
+						Optional:            true,
+						Sensitive:           true,
+						MarkdownDescription: "PEM encoded private key of `tls_cert`.",
+						Validators:          natsValidators["tls_key"],
+					},
+					"timeout": schema.StringAttribute{
+						CustomType:          timetypes.GoDurationType{},
+						Optional:            true,
+						MarkdownDescription: "Time limit for connecting and the requests of a data source, replacing the default of the data source.",
+						Validators: []validator.String{
+							nonNegativeDuration(),
+						},
+					},
+				},
+			},
+			"signer": schema.SingleNestedBlock{
+				MarkdownDescription: "External signer for account and user JWTs. Resources using `issuer_key_name` or `issuer_public_key` instead of `issuer_seed` are signed by this signer, so issuer seeds never appear in configuration or state. Only one of `vault` or `exec` can be configured.",
+				Blocks: map[string]schema.Block{
+					"vault": schema.SingleNestedBlock{
+						MarkdownDescription: "Sign with ed25519 keys of a HashiCorp Vault transit secrets engine. `issuer_key_name` is the name of the transit key.",
+						Validators: []validator.Object{
+							objectvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("exec")),
+						},
+						Attributes: map[string]schema.Attribute{
+							"address": schema.StringAttribute{
+								Optional:            true,
+								MarkdownDescription: "Vault address. Defaults to the `VAULT_ADDR` environment variable.",
+							},
+							"token": schema.StringAttribute{
+								Optional:            true,
+								Sensitive:           true,
+								MarkdownDescription: "Vault token with `read` on `<mount>/keys/*` and `update` on `<mount>/sign/*`. Defaults to the `VAULT_TOKEN` environment variable.",
+							},
+							"namespace": schema.StringAttribute{
+								Optional:            true,
+								MarkdownDescription: "Vault Enterprise namespace. Defaults to the `VAULT_NAMESPACE` environment variable.",
+							},
+							"mount": schema.StringAttribute{
+								Optional:            true,
+								MarkdownDescription: "Path of the transit secrets engine. Defaults to `transit`.",
+							},
+						},
+					},
+					"exec": schema.SingleNestedBlock{
+						MarkdownDescription: "Sign by running an external command, e.g. a wrapper around an HSM or cloud KMS. See [External Signing](#external-signing) for the protocol.",
+						Attributes: map[string]schema.Attribute{
+							"command": schema.StringAttribute{
+								Optional:            true,
+								MarkdownDescription: "Path of the signer command. Required when the `exec` block is present.",
+							},
+							"args": schema.ListAttribute{
+								ElementType:         types.StringType,
+								Optional:            true,
+								MarkdownDescription: "Arguments passed to the signer command.",
+							},
+							"env": schema.MapAttribute{
+								ElementType:         types.StringType,
+								Optional:            true,
+								Sensitive:           true,
+								MarkdownDescription: "Additional environment variables for the signer command. The provider's environment is inherited.",
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -37,6 +234,106 @@ func (p *NSCProvider) Configure(ctx context.Context, req provider.ConfigureReque
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	providerData := &NSCProviderData{
+		Keys: newKeypairCache(),
+	}
+
+	if !data.WarnExpiryWithin.IsNull() && !data.WarnExpiryWithin.IsUnknown() {
+		window, diags := data.WarnExpiryWithin.ValueGoDuration()
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		providerData.WarnExpiryWithin = window
+	}
+
+	if !data.RequireExpiry.IsNull() && !data.RequireExpiry.IsUnknown() {
+		resp.Diagnostics.Append(data.RequireExpiry.ElementsAs(ctx, &providerData.RequireExpiry, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	policy, diags := newClaimsPolicy(ctx, data.Policy)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	providerData.Policy = policy
+
+	if !data.AuditLog.IsNull() && !data.AuditLog.IsUnknown() {
+		providerData.Audit = newAuditLog(data.AuditLog.ValueString())
+	}
+
+	if key := stringValueOrEnv(data.StateEncryptionKey, stateEncryptionKeyEnv); key != "" {
+		cipher, err := newStateCipher(key)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("state_encryption_key"), "Invalid state encryption key", err.Error())
+			return
+		}
+		providerData.StateCipher = cipher
+		providerData.Keys.cipher = cipher
+	}
+
+	providerData.NATS = data.NATS
+
+	if data.Signer != nil && data.Signer.Vault != nil {
+		vault := data.Signer.Vault
+		address := stringValueOrEnv(vault.Address, "VAULT_ADDR")
+		if address == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("signer").AtName("vault").AtName("address"),
+				"Missing Vault address",
+				"Set the address attribute or the VAULT_ADDR environment variable.",
+			)
+			return
+		}
+		mount := vault.Mount.ValueString()
+		if mount == "" {
+			mount = "transit"
+		}
+		providerData.Signer = newVaultSigner(
+			address,
+			stringValueOrEnv(vault.Token, "VAULT_TOKEN"),
+			stringValueOrEnv(vault.Namespace, "VAULT_NAMESPACE"),
+			mount,
+		)
+	}
+
+	if data.Signer != nil && data.Signer.Exec != nil {
+		execConfig := data.Signer.Exec
+		if execConfig.Command.ValueString() == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("signer").AtName("exec").AtName("command"),
+				"Missing signer command",
+				"The command attribute is required in the exec signer block.",
+			)
+			return
+		}
+
+		var args []string
+		resp.Diagnostics.Append(execConfig.Args.ElementsAs(ctx, &args, false)...)
+		env := make(map[string]string)
+		resp.Diagnostics.Append(execConfig.Env.ElementsAs(ctx, &env, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		providerData.Signer = newExecSigner(execConfig.Command.ValueString(), args, env)
+	}
+
+	resp.ResourceData = providerData
+	resp.DataSourceData = providerData
+}
+
+// stringValueOrEnv returns the configured value, falling back to the
+// environment variable when the attribute is not set.
+func stringValueOrEnv(value types.String, env string) string {
+	if !value.IsNull() && !value.IsUnknown() {
+		return value.ValueString()
+	}
+	return os.Getenv(env)
 }
 
 func (p *NSCProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -45,17 +342,47 @@ func (p *NSCProvider) Resources(ctx context.Context) []func() resource.Resource
 		NewOperatorResource,
 		NewAccountResource,
 		NewUserResource,
+		NewOperatorSigningKeyRotationResource,
+		NewAccountSigningKeyRotationResource,
+		NewJWTResignResource,
+		NewRevocationResource,
+		NewTrustBundleResource,
+		NewNKeyFilesResource,
+		NewBundleFilesResource,
+		NewOperatorSetResource,
+		NewAuthCalloutUserResource,
+		NewUsersResource,
 	}
 }
 
 func (p *NSCProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewCredsDataSource,
+		NewCredsFileDataSource,
+		NewAccountClaimsDataSource,
+		NewUserClaimsDataSource,
+		NewConnectionCheckDataSource,
+		NewAuthCalloutConfigDataSource,
+		NewAccountRegistryDataSource,
+		NewJetStreamUsageDataSource,
+		NewStoreImportsDataSource,
+		NewStaticConfigDataSource,
+		NewNKeyUserDataSource,
+		NewAccountServerStatusDataSource,
+		NewResolverDriftDataSource,
+		NewDescribeImportDataSource,
+		NewRoleDataSource,
+		NewPermissionSetDataSource,
 	}
 }
 
 func (p *NSCProvider) Functions(ctx context.Context) []func() function.Function {
-	return []func() function.Function{}
+	return []func() function.Function{
+		NewResolverPreloadFunction,
+		NewTrustedOperatorsFunction,
+		NewParseCredsFunction,
+		NewVerifyJWTFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// issuedClaimTypes are the claim types require_expiry and the policy rules of
+// the provider can name.
+var issuedClaimTypes = []string{"operator", "account", "user"}
+
+// requireExpiry fails the plan of a JWT of the given kind without expiry when
+// the provider's require_expiry lists the kind. expires_in of 0 means no
+// expiry; unknown values are skipped until they are known.
+func requireExpiry(required []string, kind string, expiresIn timetypes.GoDuration, expiresAt timetypes.RFC3339) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !slices.Contains(required, kind) || expiresIn.IsUnknown() || expiresAt.IsUnknown() {
+		return diags
+	}
+	if !expiresAt.IsNull() {
+		return diags
+	}
+	if !expiresIn.IsNull() {
+		if duration, d := expiresIn.ValueGoDuration(); d.HasError() || duration > 0 {
+			return diags
+		}
+	}
+
+	diags.AddAttributeError(
+		path.Root("expires_in"),
+		"Missing expiry",
+		fmt.Sprintf("The provider requires %s JWTs to expire (require_expiry). Set expires_in or expires_at.", kind),
+	)
+	return diags
+}
+
+// requireExpiryPlan runs requireExpiry on the expiry attributes of the
+// configuration of a planned resource. Destroy plans are not checked.
+func requireExpiryPlan(ctx context.Context, req resource.ModifyPlanRequest, required []string, kind string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(required) == 0 || req.Plan.Raw.IsNull() {
+		return diags
+	}
+
+	var expiresIn timetypes.GoDuration
+	var expiresAt timetypes.RFC3339
+	diags.Append(req.Config.GetAttribute(ctx, path.Root("expires_in"), &expiresIn)...)
+	diags.Append(req.Config.GetAttribute(ctx, path.Root("expires_at"), &expiresAt)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(requireExpiry(required, kind, expiresIn, expiresAt)...)
+	return diags
+}
+
+// requireExpiryUnsupported fails the plan of a resource issuing JWTs of the
+// given kinds without any way to set an expiry, when the provider's
+// require_expiry lists one of them.
+func requireExpiryUnsupported(req resource.ModifyPlanRequest, required []string, resourceType string, kinds ...string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if req.Plan.Raw.IsNull() {
+		return diags
+	}
+	for _, kind := range kinds {
+		if slices.Contains(required, kind) {
+			diags.AddError(
+				"Missing expiry",
+				fmt.Sprintf("The provider requires %s JWTs to expire (require_expiry), but %s issues them without expiry. Use nsc_%s with expires_in or expires_at instead.", kind, resourceType, kind),
+			)
+			return diags
+		}
+	}
+	return diags
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestRequireExpiry(t *testing.T) {
+	required := []string{"account", "user"}
+
+	tests := []struct {
+		name      string
+		kind      string
+		expiresIn timetypes.GoDuration
+		expiresAt timetypes.RFC3339
+		wantError bool
+	}{
+		{"not required", "operator", timetypes.NewGoDurationNull(), timetypes.NewRFC3339Null(), false},
+		{"expires_in", "user", timetypes.NewGoDurationValueFromStringMust("720h"), timetypes.NewRFC3339Null(), false},
+		{"expires_at", "user", timetypes.NewGoDurationNull(), timetypes.NewRFC3339TimeValue(time.Now().Add(time.Hour)), false},
+		{"unknown", "account", timetypes.NewGoDurationUnknown(), timetypes.NewRFC3339Null(), false},
+		{"missing", "account", timetypes.NewGoDurationNull(), timetypes.NewRFC3339Null(), true},
+		{"zero expires_in", "user", timetypes.NewGoDurationValueFromStringMust("0s"), timetypes.NewRFC3339Null(), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := requireExpiry(required, tt.kind, tt.expiresIn, tt.expiresAt)
+			if diags.HasError() != tt.wantError {
+				t.Errorf("expected error %v, got %v", tt.wantError, diags)
+			}
+		})
+	}
+}
+
+func TestAccRequireExpiry(t *testing.T) {
+	requireExpiryConfig := `
+provider "nsc" {
+  require_expiry = ["account", "user"]
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      requireExpiryConfig + testAccAccountResourceConfigWithValidity(""),
+				ExpectError: regexp.MustCompile("requires account JWTs to expire"),
+			},
+			{
+				Config: requireExpiryConfig + testAccAccountResourceConfigWithValidity(`
+  expires_in = "720h"
+`),
+				Check: resource.TestCheckResourceAttrSet("nsc_account.test", "expires_at"),
+			},
+			{
+				Config: requireExpiryConfig + `
+resource "nsc_operator_set" "test" {
+  name = "Acme"
+}
+`,
+				ExpectError: regexp.MustCompile("nsc_operator_set issues them without expiry"),
+			},
+		},
+	})
+}
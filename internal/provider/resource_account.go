@@ -3,15 +3,23 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/nats-io/jwt/v2"
@@ -19,12 +27,23 @@ import (
 )
 
 var _ resource.Resource = &AccountResource{}
+var _ resource.ResourceWithUpgradeState = &AccountResource{}
+var _ resource.ResourceWithConfigure = &AccountResource{}
+var _ resource.ResourceWithModifyPlan = &AccountResource{}
+var _ resource.ResourceWithIdentity = &AccountResource{}
 
 func NewAccountResource() resource.Resource {
 	return &AccountResource{}
 }
 
-type AccountResource struct{}
+type AccountResource struct {
+	signer           externalSigner
+	keys             *keypairCache
+	warnExpiryWithin time.Duration
+	requireExpiry    []string
+	policy           claimsPolicy
+	audit            *auditLog
+}
 
 type ExportModel struct {
 	Name                 types.String         `tfsdk:"name"`
@@ -46,16 +65,51 @@ type ImportModel struct {
 	Account      types.String `tfsdk:"account"`
 	Token        types.String `tfsdk:"token"`
 	LocalSubject types.String `tfsdk:"local_subject"`
+	To           types.String `tfsdk:"to"`
 	Type         types.String `tfsdk:"type"`
 	Share        types.Bool   `tfsdk:"share"`
 	AllowTrace   types.Bool   `tfsdk:"allow_trace"`
+
+	// ExportingAccountJWT is the JWT of the exporting account, used for
+	// checks only.
+	ExportingAccountJWT types.String `tfsdk:"exporting_account_jwt"`
+}
+
+type AuthCalloutModel struct {
+	AuthUsers       types.List   `tfsdk:"auth_users"`
+	AllowedAccounts types.List   `tfsdk:"allowed_accounts"`
+	XKey            types.String `tfsdk:"xkey"`
 }
 
 type AccountResourceModel struct {
-	ID               types.String         `tfsdk:"id"`
+	ID              types.String `tfsdk:"id"`
+	IssuerSeed      types.String `tfsdk:"issuer_seed"`
+	IssuerSeedEnv   types.String `tfsdk:"issuer_seed_env"`
+	IssuerKeyName   types.String `tfsdk:"issuer_key_name"`
+	IssuerPublicKey types.String `tfsdk:"issuer_public_key"`
+
+	AccountClaimsModel
+
+	JWT          types.String      `tfsdk:"jwt"`
+	JWTSensitive types.String      `tfsdk:"jwt_sensitive"`
+	JWTOutput    types.String      `tfsdk:"jwt_output"`
+	IssuedAt     timetypes.RFC3339 `tfsdk:"issued_at"`
+	PinIssuedAt  types.Bool        `tfsdk:"pin_issued_at"`
+	JWTID        types.String      `tfsdk:"jwt_id"`
+	PublicKey    types.String      `tfsdk:"public_key"`
+
+	ClaimsPatchJSON types.String `tfsdk:"claims_patch_json"`
+
+	// UserJWTs are the JWTs of users issued under the account, used for
+	// checks only.
+	UserJWTs types.List `tfsdk:"user_jwts"`
+}
+
+// AccountClaimsModel holds the attributes that make up the account claims.
+// It is shared with the nsc_account_claims data source.
+type AccountClaimsModel struct {
 	Name             types.String         `tfsdk:"name"`
 	Subject          types.String         `tfsdk:"subject"`
-	IssuerSeed       types.String         `tfsdk:"issuer_seed"`
 	SigningKeys      types.List           `tfsdk:"signing_keys"`
 	AllowPub         types.List           `tfsdk:"allow_pub"`
 	AllowSub         types.List           `tfsdk:"allow_sub"`
@@ -63,38 +117,48 @@ type AccountResourceModel struct {
 	DenySub          types.List           `tfsdk:"deny_sub"`
 	AllowPubResponse types.Int64          `tfsdk:"allow_pub_response"`
 	ResponseTTL      timetypes.GoDuration `tfsdk:"response_ttl"`
-	ExpiresIn        timetypes.GoDuration `tfsdk:"expires_in"`
-	ExpiresAt        timetypes.RFC3339    `tfsdk:"expires_at"`
-	StartsIn         timetypes.GoDuration `tfsdk:"starts_in"`
-	StartsAt         timetypes.RFC3339    `tfsdk:"starts_at"`
+
+	DefaultPermissions   *PermissionsModel `tfsdk:"default_permissions"`
+	NormalizePermissions types.Bool        `tfsdk:"normalize_permissions"`
+
+	AuthCallout *AuthCalloutModel `tfsdk:"auth_callout"`
+
+	ValidityModel
 
 	// Account Limits
-	MaxConnections       types.Int64 `tfsdk:"max_connections"`
-	MaxLeafNodes         types.Int64 `tfsdk:"max_leaf_nodes"`
-	MaxData              types.Int64 `tfsdk:"max_data"`
-	MaxPayload           types.Int64 `tfsdk:"max_payload"`
-	MaxSubscriptions     types.Int64 `tfsdk:"max_subscriptions"`
-	MaxImports           types.Int64 `tfsdk:"max_imports"`
-	MaxExports           types.Int64 `tfsdk:"max_exports"`
-	AllowWildcardExports types.Bool  `tfsdk:"allow_wildcard_exports"`
-	DisallowBearerToken  types.Bool  `tfsdk:"disallow_bearer_token"`
+	MaxConnections       Limit      `tfsdk:"max_connections"`
+	MaxLeafNodes         Limit      `tfsdk:"max_leaf_nodes"`
+	MaxData              ByteSize   `tfsdk:"max_data"`
+	MaxPayload           ByteSize   `tfsdk:"max_payload"`
+	MaxSubscriptions     Limit      `tfsdk:"max_subscriptions"`
+	MaxImports           Limit      `tfsdk:"max_imports"`
+	MaxExports           Limit      `tfsdk:"max_exports"`
+	AllowWildcardExports types.Bool `tfsdk:"allow_wildcard_exports"`
+	DisallowBearerToken  types.Bool `tfsdk:"disallow_bearer_token"`
+
+	ClusterTraffic types.String `tfsdk:"cluster_traffic"`
 
 	// JetStream Limits
-	MaxMemoryStorage     types.Int64 `tfsdk:"max_memory_storage"`
-	MaxDiskStorage       types.Int64 `tfsdk:"max_disk_storage"`
-	MaxStreams           types.Int64 `tfsdk:"max_streams"`
-	MaxConsumers         types.Int64 `tfsdk:"max_consumers"`
-	MaxAckPending        types.Int64 `tfsdk:"max_ack_pending"`
-	MaxMemoryStreamBytes types.Int64 `tfsdk:"max_memory_stream_bytes"`
-	MaxDiskStreamBytes   types.Int64 `tfsdk:"max_disk_stream_bytes"`
-	MaxBytesRequired     types.Bool  `tfsdk:"max_bytes_required"`
+	JetStreamEnabled     types.Bool `tfsdk:"jetstream_enabled"`
+	MaxMemoryStorage     ByteSize   `tfsdk:"max_memory_storage"`
+	MaxDiskStorage       ByteSize   `tfsdk:"max_disk_storage"`
+	MaxStreams           Limit      `tfsdk:"max_streams"`
+	MaxConsumers         Limit      `tfsdk:"max_consumers"`
+	MaxAckPending        Limit      `tfsdk:"max_ack_pending"`
+	MaxMemoryStreamBytes ByteSize   `tfsdk:"max_memory_stream_bytes"`
+	MaxDiskStreamBytes   ByteSize   `tfsdk:"max_disk_stream_bytes"`
+	MaxBytesRequired     types.Bool `tfsdk:"max_bytes_required"`
 
 	// Imports/Exports
 	Exports types.List `tfsdk:"export"`
 	Imports types.List `tfsdk:"import"`
 
-	JWT       types.String `tfsdk:"jwt"`
-	PublicKey types.String `tfsdk:"public_key"`
+	Revocations types.List `tfsdk:"revocations"`
+
+	// OperatorJWT is the JWT of the issuing operator, used for checks only.
+	OperatorJWT types.String `tfsdk:"operator_jwt"`
+
+	CustomClaimsJSON types.String `tfsdk:"custom_claims_json"`
 }
 
 func (r *AccountResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -104,6 +168,7 @@ func (r *AccountResource) Metadata(ctx context.Context, req resource.MetadataReq
 func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Manages a NATS JWT Account",
+		Version:             3,
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -125,54 +190,104 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 				},
 			},
 			"issuer_seed": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
 				Sensitive:           true,
 				WriteOnly:           true,
-				MarkdownDescription: "Operator seed for signing the account JWT (issuer). Never stored in state.",
+				MarkdownDescription: "Operator seed for signing the account JWT (issuer). Never stored in state. Conflicts with `issuer_seed_env`, `issuer_key_name` and `issuer_public_key`; one of the four must be set.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("issuer_seed_env"), path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key")),
+					stringvalidator.AtLeastOneOf(path.MatchRoot("issuer_seed_env"), path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key")),
+				},
+			},
+			"issuer_seed_env": issuerSeedEnvAttribute(
+				"Name of the environment variable holding the operator seed, e.g. `NATS_OPERATOR_SEED`. Read by the provider when the account JWT is issued, so the seed only has to be present where Terraform applies, such as a CI secret. Only the name is stored in state. Alternative to `issuer_seed`.",
+				path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key"),
+			),
+			"issuer_key_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the operator key held by the provider's external `signer` (e.g. the Vault transit key name). Alternative to `issuer_seed`; the operator seed never enters Terraform.",
+			},
+			"issuer_public_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the operator key held by the provider's external `signer`. Alternative to `issuer_seed`. When `issuer_key_name` is not set, the public key is the key reference passed to the signer; when it is set, the public key is not looked up from the signer.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^O[A-Z2-7]{55}$`),
+						"must be a valid operator public key starting with 'O'",
+					),
+				},
 			},
 			"signing_keys": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "Optional signing key public keys (for signing user JWTs)",
+				MarkdownDescription: "Optional signing keys (for signing user JWTs), given as public keys or seeds. Only the public keys derived from seeds are put into the JWT; seeds are kept in state as given, so pass them from sensitive values such as `nsc_nkey.signing.seed`. The JWT holds each key once, sorted, so reordering or repeating keys does not reissue it.",
 			},
 			"allow_pub": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
 				MarkdownDescription: "Publish permissions",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(publishPermission()),
+				},
 			},
 			"allow_sub": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "Subscribe permissions",
+				MarkdownDescription: "Subscribe permissions. Use `\"subject queue\"` to restrict subscriptions to a queue group",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(subscribePermission()),
+				},
 			},
 			"deny_pub": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
 				MarkdownDescription: "Deny publish permissions",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(publishPermission()),
+				},
 			},
 			"deny_sub": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "Deny subscribe permissions",
+				MarkdownDescription: "Deny subscribe permissions. Use `\"subject queue\"` to target a queue group",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(subscribePermission()),
+				},
 			},
 			"allow_pub_response": schema.Int64Attribute{
 				Optional:            true,
 				Computed:            true,
 				Default:             int64default.StaticInt64(0),
-				MarkdownDescription: "Allow publishing to reply subjects",
+				MarkdownDescription: "Allow publishing to reply subjects of received requests, up to this many responses per request (-1 for unlimited, 0 to disallow)",
+				Validators: []validator.Int64{
+					int64validator.Any(
+						int64validator.OneOf(0),
+						responseMaxMsgs(),
+					),
+				},
 			},
 			"response_ttl": schema.StringAttribute{
 				CustomType:          timetypes.GoDurationType{},
 				Optional:            true,
 				MarkdownDescription: "Time limit for response permissions",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"normalize_permissions": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Sort the permission subjects and remove duplicates before they go into the JWT, so reordering or repeating them, for example when refactoring variables, does not reissue the JWT. Defaults to `false`.",
 			},
 			"expires_in": schema.StringAttribute{
 				CustomType:          timetypes.GoDurationType{},
 				Optional:            true,
 				MarkdownDescription: "Relative expiry duration (e.g., '8760h' for 1 year). Mutually exclusive with expires_at.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
 			},
 			"expires_at": schema.StringAttribute{
 				CustomType:          timetypes.RFC3339Type{},
@@ -184,6 +299,9 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 				CustomType:          timetypes.GoDurationType{},
 				Optional:            true,
 				MarkdownDescription: "Relative start delay (e.g., '72h' for 3 days). Mutually exclusive with starts_at.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
 			},
 			"starts_at": schema.StringAttribute{
 				CustomType:          timetypes.RFC3339Type{},
@@ -191,9 +309,42 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 				Computed:            true,
 				MarkdownDescription: "Absolute start timestamp (RFC3339). Can be specified directly or computed from starts_in. Mutually exclusive with starts_in.",
 			},
+			"backdate": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Moves the start of validity (`nbf`) into the past by this duration, e.g. `5m`, so that servers with a slightly slow clock do not reject freshly issued JWTs as not yet valid. Applies when the start is relative to now, i.e. unset or `starts_in`; has no effect with `starts_at`. Defaults to no backdating.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
 			"jwt": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "Generated JWT token",
+				MarkdownDescription: "Generated JWT token. Null when `jwt_output = \"sensitive_only\"`; use `jwt_sensitive` instead.",
+			},
+			"jwt_sensitive": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Generated JWT token (always populated, marked as sensitive)",
+			},
+			"jwt_output": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(jwtOutputAlways),
+				MarkdownDescription: "Controls which JWT attributes are populated: `always` (default) populates both `jwt` and `jwt_sensitive`, `sensitive_only` leaves `jwt` null so the token is only exposed as a sensitive value",
+				Validators: []validator.String{
+					stringvalidator.OneOf(jwtOutputAlways, jwtOutputSensitiveOnly),
+				},
+			},
+			"pin_issued_at": pinIssuedAtAttribute(),
+			"issued_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Time the JWT was issued (`iat` claim). Set it to pin the JWT to a fixed `iat`, see `pin_issued_at`.",
+			},
+			"jwt_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID of the JWT (`jti` claim), a hash of its claims. Changes whenever the JWT is reissued, so it can be compared with the JWT the resolver serves.",
 			},
 			"public_key": schema.StringAttribute{
 				Computed:            true,
@@ -201,33 +352,40 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 			},
 
 			// Account Limits
-			"max_connections": schema.Int64Attribute{
+			"max_connections": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of active connections (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of active connections (-1 or `unlimited` for unlimited)",
 			},
-			"max_leaf_nodes": schema.Int64Attribute{
+			"max_leaf_nodes": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of active leaf node connections (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of active leaf node connections (-1 or `unlimited` for unlimited)",
 			},
-			"max_data": schema.Int64Attribute{
+			"max_data": schema.StringAttribute{
+				CustomType:          ByteSizeType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of bytes (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of bytes, e.g. `10GiB` (-1 or `unlimited` for unlimited)",
 			},
-			"max_payload": schema.Int64Attribute{
+			"max_payload": schema.StringAttribute{
+				CustomType:          ByteSizeType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum message payload in bytes (-1 for unlimited)",
+				MarkdownDescription: "Maximum message payload, e.g. `1MiB` (-1 or `unlimited` for unlimited)",
 			},
-			"max_subscriptions": schema.Int64Attribute{
+			"max_subscriptions": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of subscriptions (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of subscriptions (-1 or `unlimited` for unlimited)",
 			},
-			"max_imports": schema.Int64Attribute{
+			"max_imports": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of imports (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of imports (-1 or `unlimited` for unlimited)",
 			},
-			"max_exports": schema.Int64Attribute{
+			"max_exports": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of exports (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of exports (-1 or `unlimited` for unlimited)",
 			},
 			"allow_wildcard_exports": schema.BoolAttribute{
 				Optional:            true,
@@ -237,42 +395,103 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 				Optional:            true,
 				MarkdownDescription: "Disallow user JWTs to be bearer tokens",
 			},
+			"cluster_traffic": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Account that cluster and route traffic for this account is accounted to: `system` (server default) or `owner`. Honored by newer nats-server versions",
+				Validators: []validator.String{
+					stringvalidator.OneOf(string(jwt.ClusterTrafficSystem), string(jwt.ClusterTrafficOwner)),
+				},
+			},
 
 			// JetStream Limits
-			"max_memory_storage": schema.Int64Attribute{
+			"jetstream_enabled": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Enable JetStream without limits: sets `max_memory_storage`, `max_disk_storage`, `max_streams` and `max_consumers` to unlimited. Limits set explicitly take precedence. Defaults to `false`, where JetStream is enabled only by setting `max_memory_storage` or `max_disk_storage`.",
+			},
+			"max_memory_storage": schema.StringAttribute{
+				CustomType:          ByteSizeType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum bytes stored in memory across all streams (0 for disabled)",
+				MarkdownDescription: "Maximum bytes stored in memory across all streams, e.g. `1GiB` (0 or `disabled` for disabled, -1 or `unlimited` for unlimited)",
 			},
-			"max_disk_storage": schema.Int64Attribute{
+			"max_disk_storage": schema.StringAttribute{
+				CustomType:          ByteSizeType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum bytes stored on disk across all streams (0 for disabled)",
+				MarkdownDescription: "Maximum bytes stored on disk across all streams, e.g. `10GiB` (0 or `disabled` for disabled, -1 or `unlimited` for unlimited)",
 			},
-			"max_streams": schema.Int64Attribute{
+			"max_streams": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of streams (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of streams (-1 or `unlimited` for unlimited)",
 			},
-			"max_consumers": schema.Int64Attribute{
+			"max_consumers": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of consumers (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of consumers (-1 or `unlimited` for unlimited)",
 			},
-			"max_ack_pending": schema.Int64Attribute{
+			"max_ack_pending": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum ack pending of a stream (-1 for unlimited)",
+				MarkdownDescription: "Maximum ack pending of a stream (-1 or `unlimited` for unlimited)",
 			},
-			"max_memory_stream_bytes": schema.Int64Attribute{
+			"max_memory_stream_bytes": schema.StringAttribute{
+				CustomType:          ByteSizeType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum bytes a memory backed stream can have (0 for unlimited)",
+				MarkdownDescription: "Maximum bytes a memory backed stream can have, e.g. `512MiB` (0, -1 or `unlimited` for unlimited)",
+				Validators: []validator.String{
+					// 0 is unlimited here, so disabled would be misleading
+					stringvalidator.NoneOf(limitDisabled),
+				},
 			},
-			"max_disk_stream_bytes": schema.Int64Attribute{
+			"max_disk_stream_bytes": schema.StringAttribute{
+				CustomType:          ByteSizeType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum bytes a disk backed stream can have (0 for unlimited)",
+				MarkdownDescription: "Maximum bytes a disk backed stream can have, e.g. `1GiB` (0, -1 or `unlimited` for unlimited)",
+				Validators: []validator.String{
+					// 0 is unlimited here, so disabled would be misleading
+					stringvalidator.NoneOf(limitDisabled),
+				},
 			},
 			"max_bytes_required": schema.BoolAttribute{
 				Optional:            true,
 				MarkdownDescription: "Require max bytes to be set for all streams",
 			},
+			"revocations": revocationsAttribute(),
+			"operator_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "JWT of the issuing operator. Not part of the account JWT; when set, the issuer must be the operator or one of its signing keys, and only a signing key when the operator sets `strict_signing_key_usage`.",
+			},
+			"custom_claims_json": customClaimsJSONAttribute("account"),
+			"claims_patch_json":  claimsPatchJSONAttribute("account"),
+			"user_jwts": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "JWTs of users issued under this account, e.g. `[for u in nsc_user.all : u.jwt_sensitive]`. Not part of the account JWT and changing it alone does not reissue it; when a plan removes a key from `signing_keys`, a warning lists the users whose JWTs were issued by that key. Do not set it from users that reference this account's `jwt` in `account_jwt`, as that is a dependency cycle.",
+			},
 		},
 		Blocks: map[string]schema.Block{
+			"default_permissions": permissionsBlock("Default permissions for users of this account."),
+			"auth_callout": schema.SingleNestedBlock{
+				MarkdownDescription: "Delegates the authentication of the account's users to an auth callout service, for servers in operator mode. The service connects as one of `auth_users`, e.g. an `nsc_auth_callout_user`, and answers requests on `$SYS.REQ.USER.AUTH` with user JWTs.",
+				Attributes: map[string]schema.Attribute{
+					"auth_users": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: "Public keys of the users the callout service connects as. They bypass the callout. Required when the block is set.",
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+					"allowed_accounts": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: "Public keys of the accounts the callout service may place users in, or `[\"*\"]` for any account. Defaults to this account only.",
+					},
+					"xkey": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Public curve key (`X...`) the server encrypts callout requests with, so only the service can read them.",
+					},
+				},
+			},
 			"export": schema.ListNestedBlock{
 				MarkdownDescription: "Exports this account provides to other accounts",
 				NestedObject: schema.NestedBlockObject{
@@ -283,7 +502,7 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 						},
 						"subject": schema.StringAttribute{
 							Required:            true,
-							MarkdownDescription: "Subject pattern to export",
+							MarkdownDescription: "Subject pattern to export. Must not be contained in the subject of another export of the same type",
 						},
 						"type": schema.StringAttribute{
 							Required:            true,
@@ -295,16 +514,25 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 						},
 						"response_type": schema.StringAttribute{
 							Optional:            true,
-							MarkdownDescription: "Service response type: 'Singleton' (single response), 'Stream' (multiple responses), or 'Chunked' (chunked single response)",
+							MarkdownDescription: "Service response type: 'Singleton' (single response), 'Stream' (multiple responses), or 'Chunked' (chunked single response). Case-insensitive. Only supported on service exports",
+							Validators: []validator.String{
+								stringvalidator.OneOfCaseInsensitive(exportResponseTypes...),
+							},
 						},
 						"response_threshold": schema.StringAttribute{
 							CustomType:          timetypes.GoDurationType{},
 							Optional:            true,
 							MarkdownDescription: "Maximum time to wait for service response (e.g., '5s')",
+							Validators: []validator.String{
+								nonNegativeDuration(),
+							},
 						},
 						"account_token_position": schema.Int64Attribute{
 							Optional:            true,
-							MarkdownDescription: "Position in the subject where the account token appears (for multi-tenant exports)",
+							MarkdownDescription: "Position in the subject where the account token appears (for multi-tenant exports). Counts from 1 and must point at a `*` wildcard token of the subject.",
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
 						},
 						"advertise": schema.BoolAttribute{
 							Optional:            true,
@@ -312,7 +540,7 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 						},
 						"allow_trace": schema.BoolAttribute{
 							Optional:            true,
-							MarkdownDescription: "Allow tracing for this export",
+							MarkdownDescription: "Allow tracing for this export. Only supported on service exports",
 						},
 						"description": schema.StringAttribute{
 							Optional:            true,
@@ -344,12 +572,19 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 						"token": schema.StringAttribute{
 							Optional:            true,
 							Sensitive:           true,
-							MarkdownDescription: "Activation token if required by the export",
+							MarkdownDescription: "Activation token if required by the export. Must be issued by `account` (or one of its signing keys) to this account, for the import type and a subject covering `subject`; mismatches are rejected at plan time",
 						},
 						"local_subject": schema.StringAttribute{
 							Optional:            true,
 							MarkdownDescription: "Local subject mapping (can use $1, $2 for wildcard references)",
 						},
+						"to": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Deprecated predecessor of `local_subject`, still written by older nsc versions: the subject the stream is delivered on in this account. Only for stream imports; use it instead of `local_subject` to reproduce JWTs of those versions byte-for-byte.",
+							Validators: []validator.String{
+								stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("local_subject")),
+							},
+						},
 						"type": schema.StringAttribute{
 							Required:            true,
 							MarkdownDescription: "Import type: 'stream' for pub/sub or 'service' for request/reply",
@@ -360,7 +595,11 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 						},
 						"allow_trace": schema.BoolAttribute{
 							Optional:            true,
-							MarkdownDescription: "Allow tracing for this import",
+							MarkdownDescription: "Allow tracing for this import. Only supported on stream imports",
+						},
+						"exporting_account_jwt": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "JWT of the exporting account, e.g. `nsc_account.exporter.jwt`. Not part of the account JWT; when set, the import is checked at plan time against the exports of the account: an export of the same type must cover `subject`, and exports requiring a token need `token`.",
 						},
 					},
 				},
@@ -369,6 +608,32 @@ func (r *AccountResource) Schema(ctx context.Context, req resource.SchemaRequest
 	}
 }
 
+func (r *AccountResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = publicKeyIdentitySchema("Account public key (subject)")
+}
+
+func (r *AccountResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.signer = providerData.Signer
+	r.keys = providerData.Keys
+	r.warnExpiryWithin = providerData.WarnExpiryWithin
+	r.requireExpiry = providerData.RequireExpiry
+	r.policy = providerData.Policy
+	r.audit = providerData.Audit
+}
+
 func (r *AccountResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
 	var data AccountResourceModel
 
@@ -377,23 +642,153 @@ func (r *AccountResource) ValidateConfig(ctx context.Context, req resource.Valid
 		return
 	}
 
-	// Validate expiry attributes are mutually exclusive
-	if !data.ExpiresIn.IsNull() && !data.ExpiresIn.IsUnknown() && !data.ExpiresAt.IsNull() && !data.ExpiresAt.IsUnknown() {
-		resp.Diagnostics.AddError(
-			"Conflicting Expiry Configuration",
-			"Only one of 'expires_in' or 'expires_at' can be specified.",
-		)
+	resp.Diagnostics.Append(data.validate()...)
+	resp.Diagnostics.Append(data.validateLimits()...)
+	resp.Diagnostics.Append(data.validateExports(ctx)...)
+	resp.Diagnostics.Append(data.validateImports(ctx)...)
+	resp.Diagnostics.Append(data.validateImportTokens(ctx)...)
+	if data.DefaultPermissions != nil {
+		resp.Diagnostics.Append(data.DefaultPermissions.validate(ctx, path.Root("default_permissions"))...)
+	}
+	resp.Diagnostics.Append(validateIssuedJWTs(data.UserJWTs, "user_jwts", jwt.UserClaim)...)
+
+	// The issuer is only known here when given as a seed or public key;
+	// issuer_key_name and issuer_seed_env are checked on apply. Seeds
+	// encrypted with the state encryption key can only be checked once the
+	// provider is configured, which terraform validate does not do.
+	if !data.IssuerSeed.IsNull() && !data.IssuerSeed.IsUnknown() {
+		if kp, err := r.keys.fromSeed(data.IssuerSeed.ValueString()); err == nil {
+			if issuerPubKey, err := kp.PublicKey(); err == nil {
+				resp.Diagnostics.Append(data.validateIssuer(issuerPubKey)...)
+			}
+		}
+	} else if !data.IssuerPublicKey.IsNull() && !data.IssuerPublicKey.IsUnknown() {
+		resp.Diagnostics.Append(data.validateIssuer(data.IssuerPublicKey.ValueString())...)
 	}
+}
 
-	// Validate start attributes are mutually exclusive
-	if !data.StartsIn.IsNull() && !data.StartsIn.IsUnknown() && !data.StartsAt.IsNull() && !data.StartsAt.IsUnknown() {
-		resp.Diagnostics.AddError(
-			"Conflicting Start Configuration",
-			"Only one of 'starts_in' or 'starts_at' can be specified.",
-		)
+// ModifyPlan marks the JWT outputs unknown whenever the JWT is reissued, so
+// resources referencing them plan their own updates in the same run.
+func (r *AccountResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	resp.Diagnostics.Append(requireExpiryPlan(ctx, req, r.requireExpiry, "account")...)
+	resp.Diagnostics.Append(r.checkPolicyPlan(ctx, req)...)
+	if !jwtPlanned(req) {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var plan, state AccountResourceModel
+		resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		resp.Diagnostics.Append(removedSigningKeyWarnings(ctx, state.SigningKeys, plan.SigningKeys, plan.UserJWTs, nkeys.PrefixByteAccount, "user")...)
+
+		// user_jwts is used for checks only, and signing_keys differing only
+		// in order or duplicates make the same JWT, as do permissions under
+		// normalize_permissions, so changes to them alone keep the JWT
+		checksOnly := []string{"user_jwts"}
+		if sameSigningKeys(ctx, state.SigningKeys, plan.SigningKeys, nkeys.PrefixByteAccount) {
+			checksOnly = append(checksOnly, "signing_keys")
+		}
+		if state.NormalizePermissions.ValueBool() && plan.NormalizePermissions.ValueBool() {
+			checksOnly = append(checksOnly, normalizedPermissionAttributes(ctx, "default_permissions",
+				accountPermissionAttributes(state), accountPermissionAttributes(plan))...)
+		}
+		if checksOnlyPlan(req, checksOnly...) {
+			state.UserJWTs = plan.UserJWTs
+			state.SigningKeys = plan.SigningKeys
+			state.AllowPub, state.AllowSub = plan.AllowPub, plan.AllowSub
+			state.DenyPub, state.DenySub = plan.DenyPub, plan.DenySub
+			state.DefaultPermissions = plan.DefaultPermissions
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, &state)...)
+			return
+		}
+	}
+
+	var jwtOutput types.String
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("jwt_output"), &jwtOutput)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token, tokenSensitive := jwtOutputPlanValues(jwtOutput.ValueString())
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), token)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt_sensitive"), tokenSensitive)...)
+}
+
+// checkPolicyPlan evaluates the provider policy against the claims built from
+// the plan. Plans with claims that fail to build are left to validation and
+// apply.
+func (r *AccountResource) checkPolicyPlan(ctx context.Context, req resource.ModifyPlanRequest) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(r.policy) == 0 || req.Plan.Raw.IsNull() {
+		return diags
+	}
+
+	var data AccountResourceModel
+	diags.Append(req.Plan.Get(ctx, &data)...)
+	expiresIn, expiresAt, expiryUnknown, d := policyPlanExpiry(ctx, req)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	data.Subject = planSubject(data.Subject)
+	data.ExpiresIn, data.ExpiresAt = expiresIn, expiresAt
+	claims, d := buildAccountClaims(ctx, &data.AccountClaimsModel)
+	if d.HasError() {
+		return diags
+	}
+
+	diags.Append(r.policy.checkPlan(claims, data.CustomClaimsJSON, data.ClaimsPatchJSON, policyUnknown{expiry: expiryUnknown})...)
+	return diags
+}
+
+func (r *AccountResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	return map[int64]resource.StateUpgrader{
+		// Version 0 covers both the legacy expiry/start attributes (ADR-007)
+		// and states written before jwt_output/jwt_sensitive existed.
+		0: {
+			StateUpgrader: func(_ context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				attrs, err := rawStateAttributes(req)
+				if err != nil {
+					resp.Diagnostics.AddError("Unable to Upgrade Account State", err.Error())
+					return
+				}
+
+				renameStateAttribute(attrs, "expiry", "expires_in")
+				renameStateAttribute(attrs, "start", "starts_in")
+				defaultStateAttribute(attrs, "jwt_output", jwtOutputAlways)
+				defaultStateAttribute(attrs, "jwt_sensitive", attrs["jwt"])
+
+				stringifyStateAttributes(attrs, accountLimitAttributes...)
+
+				resp.DynamicValue, err = upgradedState(attrs, schemaResp.Schema)
+				if err != nil {
+					resp.Diagnostics.AddError("Unable to Upgrade Account State", err.Error())
+				}
+			},
+		},
+		// Versions 1 and 2 stored limits as numbers.
+		1: stringifyUpgrader(schemaResp.Schema, "Account", accountLimitAttributes...),
+		2: stringifyUpgrader(schemaResp.Schema, "Account", accountLimitAttributes...),
 	}
 }
 
+// accountLimitAttributes lists the limits that changed from numbers to
+// strings: byte limits in schema version 2 and count limits in version 3.
+var accountLimitAttributes = []string{
+	"max_data", "max_payload", "max_memory_storage", "max_disk_storage", "max_memory_stream_bytes", "max_disk_stream_bytes",
+	"max_connections", "max_leaf_nodes", "max_subscriptions", "max_imports", "max_exports", "max_streams", "max_consumers", "max_ack_pending",
+}
+
 func (r *AccountResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data AccountResourceModel
 
@@ -420,30 +815,15 @@ func (r *AccountResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	// Get operator seed (issuer) for signing from Config
-	operatorSeedStr := config.IssuerSeed.ValueString()
-	if operatorSeedStr == "" {
-		resp.Diagnostics.AddError(
-			"Missing operator seed",
-			"Operator seed (issuer_seed) is required",
-		)
-		return
-	}
-	if !strings.HasPrefix(operatorSeedStr, "SO") {
-		prefix := operatorSeedStr
-		if len(prefix) > 2 {
-			prefix = prefix[:2]
-		}
-		resp.Diagnostics.AddError(
-			"Invalid operator seed",
-			fmt.Sprintf("Operator seed must start with 'SO', got: %s", prefix),
-		)
+	// Get operator keypair (issuer) for signing
+	issuerSeed, diags := issuerSeedValue(config.IssuerSeed, data.IssuerSeedEnv)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	operatorKP, err := nkeys.FromSeed([]byte(operatorSeedStr))
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to parse operator seed", err.Error())
+	operatorKP, signFn, diags := accountIssuer(ctx, r.signer, r.keys, data.IssuerKeyName, data.IssuerPublicKey, issuerSeed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
@@ -452,428 +832,235 @@ func (r *AccountResource) Create(ctx context.Context, req resource.CreateRequest
 		resp.Diagnostics.AddError("Failed to get operator public key", err.Error())
 		return
 	}
-
-	// Validate it's actually an operator key
-	if !strings.HasPrefix(operatorPubKey, "O") {
-		resp.Diagnostics.AddError(
-			"Invalid operator seed",
-			fmt.Sprintf("Seed does not generate an operator public key (expected O*, got %s)", operatorPubKey),
-		)
+	resp.Diagnostics.Append(data.validateIssuer(operatorPubKey)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
 	// Create account claims
-	accountClaims := jwt.NewAccountClaims(accountPubKey)
-	accountClaims.Name = data.Name.ValueString()
+	accountClaims, diags := buildAccountClaims(ctx, &data.AccountClaimsModel)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	accountClaims.Issuer = operatorPubKey
 
-	// Handle permissions
-	if !data.AllowPub.IsNull() {
-		var allowPub []string
-		resp.Diagnostics.Append(data.AllowPub.ElementsAs(ctx, &allowPub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		accountClaims.DefaultPermissions.Pub.Allow = allowPub
+	// Sign the JWT with operator key (already have operatorKP from above)
+	issuedAt, diags := pinnedIssuedAt(data.PinIssuedAt, config.IssuedAt, accountClaims)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	if !data.AllowSub.IsNull() {
-		var allowSub []string
-		resp.Diagnostics.Append(data.AllowSub.ElementsAs(ctx, &allowSub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		accountClaims.DefaultPermissions.Sub.Allow = allowSub
+	accountJWT, err := encodeClaims(ctx, accountClaims, operatorKP, signFn, data.CustomClaimsJSON, data.ClaimsPatchJSON, issuedAt)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode account JWT", err.Error())
+		return
 	}
-
-	if !data.DenyPub.IsNull() {
-		var denyPub []string
-		resp.Diagnostics.Append(data.DenyPub.ElementsAs(ctx, &denyPub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		accountClaims.DefaultPermissions.Pub.Deny = denyPub
+	resp.Diagnostics.Append(r.policy.checkToken(accountJWT, true)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if !data.DenySub.IsNull() {
-		var denySub []string
-		resp.Diagnostics.Append(data.DenySub.ElementsAs(ctx, &denySub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		accountClaims.DefaultPermissions.Sub.Deny = denySub
+	// Set computed values
+	data.ID = types.StringValue(accountPubKey)
+	data.PublicKey = types.StringValue(accountPubKey)
+	data.JWT, data.JWTSensitive = jwtOutputValues(data.JWTOutput.ValueString(), accountJWT)
+	data.IssuedAt, data.JWTID, err = jwtIssueValues(accountJWT)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode account JWT", err.Error())
+		return
 	}
 
-	// Handle response permissions
-	if !data.AllowPubResponse.IsNull() {
-		max := data.AllowPubResponse.ValueInt64()
-		if max > 0 {
-			accountClaims.DefaultPermissions.Resp = &jwt.ResponsePermission{
-				MaxMsgs: int(max),
-			}
+	resp.Diagnostics.Append(r.audit.record("nsc_account", "create", accountJWT)...)
 
-			if !data.ResponseTTL.IsNull() && !data.ResponseTTL.IsUnknown() {
-				duration, diags := data.ResponseTTL.ValueGoDuration()
-				resp.Diagnostics.Append(diags...)
-				if resp.Diagnostics.HasError() {
-					return
-				}
-				accountClaims.DefaultPermissions.Resp.Expires = duration
-			}
-		}
+	tflog.Trace(ctx, "created account resource")
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *AccountResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AccountResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Handle expiry (support old, new, and absolute variants)
-	var expiresAtTime time.Time
-	if !data.ExpiresIn.IsNull() && !data.ExpiresIn.IsUnknown() {
-		// New relative duration - compute and store absolute
-		duration, diags := data.ExpiresIn.ValueGoDuration()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		if duration != 0 {
-			expiresAtTime = time.Now().Add(duration)
-			data.ExpiresAt = timetypes.NewRFC3339TimeValue(expiresAtTime)
-			accountClaims.Expires = expiresAtTime.Unix()
-		} else {
-			data.ExpiresAt = timetypes.NewRFC3339Null()
-		}
-	} else if !data.ExpiresAt.IsNull() && !data.ExpiresAt.IsUnknown() {
-		// Absolute timestamp provided
-		expiresAtTime, diags := data.ExpiresAt.ValueRFC3339Time()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		accountClaims.Expires = expiresAtTime.Unix()
-	} else {
-		// No expiry specified - set to null
-		data.ExpiresAt = timetypes.NewRFC3339Null()
-	}
-
-	// Handle start time (support old, new, and absolute variants)
-	var startsAtTime time.Time
-	if !data.StartsIn.IsNull() && !data.StartsIn.IsUnknown() {
-		// New relative duration - compute and store absolute
-		duration, diags := data.StartsIn.ValueGoDuration()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		if duration != 0 {
-			startsAtTime = time.Now().Add(duration)
-			data.StartsAt = timetypes.NewRFC3339TimeValue(startsAtTime)
-			accountClaims.NotBefore = startsAtTime.Unix()
-		} else {
-			data.StartsAt = timetypes.NewRFC3339Null()
-		}
-	} else if !data.StartsAt.IsNull() && !data.StartsAt.IsUnknown() {
-		// Absolute timestamp provided
-		startsAtTime, diags := data.StartsAt.ValueRFC3339Time()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
+	// Fill in issued_at and jwt_id for states written before they existed
+	if data.JWTID.IsNull() && !data.JWTSensitive.IsNull() {
+		issuedAt, jwtID, err := jwtIssueValues(data.JWTSensitive.ValueString())
+		if err == nil {
+			data.IssuedAt, data.JWTID = issuedAt, jwtID
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		}
-		accountClaims.NotBefore = startsAtTime.Unix()
-	} else {
-		// No start time specified - set to null
-		data.StartsAt = timetypes.NewRFC3339Null()
 	}
 
-	// Set Account Limits
-	if !data.MaxConnections.IsNull() {
-		accountClaims.Limits.Conn = data.MaxConnections.ValueInt64()
+	// For state-only storage, nothing to read externally
+	resp.Diagnostics.Append(expiryWarning("account", data.Name.ValueString(), data.Subject.ValueString(), data.ExpiresAt, r.warnExpiryWithin)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data AccountResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !data.MaxLeafNodes.IsNull() {
-		accountClaims.Limits.LeafNodeConn = data.MaxLeafNodes.ValueInt64()
+
+	// ModifyPlan keeps the JWT when only user_jwts or the order of
+	// signing_keys or normalized permissions changes
+	if !data.JWTID.IsUnknown() {
+		tflog.Trace(ctx, "updated account resource without reissuing the JWT")
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+		return
 	}
-	if !data.MaxData.IsNull() {
-		accountClaims.Limits.Data = data.MaxData.ValueInt64()
-	}
-	if !data.MaxPayload.IsNull() {
-		accountClaims.Limits.Payload = data.MaxPayload.ValueInt64()
-	}
-	if !data.MaxSubscriptions.IsNull() {
-		accountClaims.Limits.Subs = data.MaxSubscriptions.ValueInt64()
-	}
-	if !data.MaxImports.IsNull() {
-		accountClaims.Limits.Imports = data.MaxImports.ValueInt64()
-	}
-	if !data.MaxExports.IsNull() {
-		accountClaims.Limits.Exports = data.MaxExports.ValueInt64()
-	}
-	if !data.AllowWildcardExports.IsNull() {
-		accountClaims.Limits.WildcardExports = data.AllowWildcardExports.ValueBool()
-	}
-	if !data.DisallowBearerToken.IsNull() {
-		accountClaims.Limits.DisallowBearer = data.DisallowBearerToken.ValueBool()
+
+	// Get current state to preserve immutable fields
+	var state AccountResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Set JetStream Limits
-	if !data.MaxMemoryStorage.IsNull() {
-		accountClaims.Limits.MemoryStorage = data.MaxMemoryStorage.ValueInt64()
-	}
-	if !data.MaxDiskStorage.IsNull() {
-		accountClaims.Limits.DiskStorage = data.MaxDiskStorage.ValueInt64()
-	}
-	if !data.MaxStreams.IsNull() {
-		accountClaims.Limits.Streams = data.MaxStreams.ValueInt64()
-	}
-	if !data.MaxConsumers.IsNull() {
-		accountClaims.Limits.Consumer = data.MaxConsumers.ValueInt64()
-	}
-	if !data.MaxAckPending.IsNull() {
-		accountClaims.Limits.MaxAckPending = data.MaxAckPending.ValueInt64()
-	}
-	if !data.MaxMemoryStreamBytes.IsNull() {
-		accountClaims.Limits.MemoryMaxStreamBytes = data.MaxMemoryStreamBytes.ValueInt64()
-	}
-	if !data.MaxDiskStreamBytes.IsNull() {
-		accountClaims.Limits.DiskMaxStreamBytes = data.MaxDiskStreamBytes.ValueInt64()
-	}
-	if !data.MaxBytesRequired.IsNull() {
-		accountClaims.Limits.MaxBytesRequired = data.MaxBytesRequired.ValueBool()
-	}
-
-	// Handle exports
-	if !data.Exports.IsNull() && len(data.Exports.Elements()) > 0 {
-		var exports []ExportModel
-		resp.Diagnostics.Append(data.Exports.ElementsAs(ctx, &exports, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-
-		for _, export := range exports {
-			jwtExport := &jwt.Export{
-				Subject: jwt.Subject(export.Subject.ValueString()),
-			}
-
-			// Set export type
-			switch export.Type.ValueString() {
-			case "stream":
-				jwtExport.Type = jwt.Stream
-			case "service":
-				jwtExport.Type = jwt.Service
-			default:
-				resp.Diagnostics.AddError(
-					"Invalid export type",
-					fmt.Sprintf("Export type must be 'stream' or 'service', got: %s", export.Type.ValueString()),
-				)
-				return
-			}
-
-			// Optional fields
-			if !export.Name.IsNull() {
-				jwtExport.Name = export.Name.ValueString()
-			}
-			if !export.TokenRequired.IsNull() {
-				jwtExport.TokenReq = export.TokenRequired.ValueBool()
-			}
-			if !export.ResponseType.IsNull() {
-				jwtExport.ResponseType = jwt.ResponseType(export.ResponseType.ValueString())
-			}
-			if !export.ResponseThreshold.IsNull() && !export.ResponseThreshold.IsUnknown() {
-				duration, diags := export.ResponseThreshold.ValueGoDuration()
-				resp.Diagnostics.Append(diags...)
-				if resp.Diagnostics.HasError() {
-					return
-				}
-				jwtExport.ResponseThreshold = duration
-			}
-			if !export.AccountTokenPosition.IsNull() {
-				jwtExport.AccountTokenPosition = uint(export.AccountTokenPosition.ValueInt64())
-			}
-			if !export.Advertise.IsNull() {
-				jwtExport.Advertise = export.Advertise.ValueBool()
-			}
-			if !export.AllowTrace.IsNull() {
-				jwtExport.AllowTrace = export.AllowTrace.ValueBool()
-			}
-			if !export.Description.IsNull() {
-				jwtExport.Description = export.Description.ValueString()
-			}
-			if !export.InfoURL.IsNull() {
-				jwtExport.InfoURL = export.InfoURL.ValueString()
-			}
-
-			accountClaims.Exports.Add(jwtExport)
-		}
+	// Get WriteOnly issuer_seed from Config
+	var config AccountResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Handle imports
-	if !data.Imports.IsNull() && len(data.Imports.Elements()) > 0 {
-		var imports []ImportModel
-		resp.Diagnostics.Append(data.Imports.ElementsAs(ctx, &imports, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-
-		for _, imp := range imports {
-			jwtImport := &jwt.Import{
-				Subject: jwt.Subject(imp.Subject.ValueString()),
-				Account: imp.Account.ValueString(),
-			}
-
-			// Set import type
-			switch imp.Type.ValueString() {
-			case "stream":
-				jwtImport.Type = jwt.Stream
-			case "service":
-				jwtImport.Type = jwt.Service
-			default:
-				resp.Diagnostics.AddError(
-					"Invalid import type",
-					fmt.Sprintf("Import type must be 'stream' or 'service', got: %s", imp.Type.ValueString()),
-				)
-				return
-			}
-
-			// Optional fields
-			if !imp.Name.IsNull() {
-				jwtImport.Name = imp.Name.ValueString()
-			}
-			if !imp.Token.IsNull() {
-				jwtImport.Token = imp.Token.ValueString()
-			}
-			if !imp.LocalSubject.IsNull() {
-				jwtImport.LocalSubject = jwt.RenamingSubject(imp.LocalSubject.ValueString())
-			}
-			if !imp.Share.IsNull() {
-				jwtImport.Share = imp.Share.ValueBool()
-			}
-			if !imp.AllowTrace.IsNull() {
-				jwtImport.AllowTrace = imp.AllowTrace.ValueBool()
-			}
-
-			accountClaims.Imports.Add(jwtImport)
-		}
+	// Get operator keypair (issuer) for signing
+	issuerSeed, diags := issuerSeedValue(config.IssuerSeed, data.IssuerSeedEnv)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	// Add signing keys if provided
-	if !data.SigningKeys.IsNull() && !data.SigningKeys.IsUnknown() {
-		var signingKeys []string
-		resp.Diagnostics.Append(data.SigningKeys.ElementsAs(ctx, &signingKeys, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-
-		for _, key := range signingKeys {
-			if !strings.HasPrefix(key, "A") {
-				resp.Diagnostics.AddError(
-					"Invalid signing key",
-					fmt.Sprintf("Signing keys must be account public keys (start with 'A'), got: %s", key),
-				)
-				return
-			}
-			accountClaims.SigningKeys.Add(key)
-		}
+	operatorKP, signFn, diags := accountIssuer(ctx, r.signer, r.keys, data.IssuerKeyName, data.IssuerPublicKey, issuerSeed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Sign the JWT with operator key (already have operatorKP from above)
-	accountJWT, err := accountClaims.Encode(operatorKP)
+	operatorPubKey, err := operatorKP.PublicKey()
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to encode account JWT", err.Error())
+		resp.Diagnostics.AddError("Failed to get operator public key", err.Error())
 		return
 	}
-
-	// Set computed values
-	data.ID = types.StringValue(accountPubKey)
-	data.PublicKey = types.StringValue(accountPubKey)
-	data.JWT = types.StringValue(accountJWT)
-
-	tflog.Trace(ctx, "created account resource")
-
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
-}
-
-func (r *AccountResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var data AccountResourceModel
-
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	resp.Diagnostics.Append(data.validateIssuer(operatorPubKey)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// For state-only storage, nothing to read externally
-}
-
-func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data AccountResourceModel
-
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	// Recreate account claims with updated values
+	data.Subject = state.Subject
+	accountClaims, diags := buildAccountClaims(ctx, &data.AccountClaimsModel)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	accountClaims.Issuer = operatorPubKey
 
-	// Get current state to preserve immutable fields
-	var state AccountResourceModel
-	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	// Sign the JWT with operator key (already have operatorKP from above)
+	issuedAt, diags := pinnedIssuedAt(data.PinIssuedAt, config.IssuedAt, accountClaims)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	// Get WriteOnly issuer_seed from Config
-	var config AccountResourceModel
-	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	accountJWT, err := encodeClaims(ctx, accountClaims, operatorKP, signFn, data.CustomClaimsJSON, data.ClaimsPatchJSON, issuedAt)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode account JWT", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(r.policy.checkToken(accountJWT, true)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Get account public key from state and operator seed from config (both immutable)
-	accountPubKey := state.Subject.ValueString()
-	operatorSeedStr := config.IssuerSeed.ValueString()
+	// Keep the stored JWT when no claim changed, so its iat and jti, and
+	// everything derived from the token, stay the same. A pinned iat makes
+	// the same JWT anyway.
+	stored := state.JWTSensitive.ValueString()
+	if issuedAt == 0 && sameJWTClaims(stored, accountJWT) {
+		accountJWT = stored
+	}
+	reissued := accountJWT != stored
 
-	operatorKP, err := nkeys.FromSeed([]byte(operatorSeedStr))
+	// Update JWT while preserving immutable fields
+	data.ID = state.ID
+	data.PublicKey = state.PublicKey
+	data.JWT, data.JWTSensitive = jwtOutputValues(data.JWTOutput.ValueString(), accountJWT)
+	data.IssuedAt, data.JWTID, err = jwtIssueValues(accountJWT)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to restore operator keypair", err.Error())
+		resp.Diagnostics.AddError("Failed to decode account JWT", err.Error())
 		return
 	}
 
-	operatorPubKey, err := operatorKP.PublicKey()
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to get operator public key", err.Error())
+	if reissued {
+		resp.Diagnostics.Append(r.audit.record("nsc_account", "update", accountJWT)...)
+	}
+
+	tflog.Trace(ctx, "updated account resource")
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *AccountResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AccountResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Recreate account claims with updated values
-	accountClaims := jwt.NewAccountClaims(accountPubKey)
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted account resource")
+}
+
+// buildAccountClaims creates unsigned account claims from the model. Relative
+// expiry and start times are resolved into the absolute model attributes.
+func buildAccountClaims(ctx context.Context, data *AccountClaimsModel) (*jwt.AccountClaims, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	accountClaims := jwt.NewAccountClaims(data.Subject.ValueString())
 	accountClaims.Name = data.Name.ValueString()
-	accountClaims.Issuer = operatorPubKey
 
-	// Handle permissions (same as create)
+	// Handle permissions
 	if !data.AllowPub.IsNull() {
-		var allowPub []string
-		resp.Diagnostics.Append(data.AllowPub.ElementsAs(ctx, &allowPub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		allowPub, d := stringListValues(ctx, data.AllowPub)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		accountClaims.DefaultPermissions.Pub.Allow = allowPub
 	}
 
 	if !data.AllowSub.IsNull() {
-		var allowSub []string
-		resp.Diagnostics.Append(data.AllowSub.ElementsAs(ctx, &allowSub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		allowSub, d := stringListValues(ctx, data.AllowSub)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		accountClaims.DefaultPermissions.Sub.Allow = allowSub
 	}
 
 	if !data.DenyPub.IsNull() {
-		var denyPub []string
-		resp.Diagnostics.Append(data.DenyPub.ElementsAs(ctx, &denyPub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		denyPub, d := stringListValues(ctx, data.DenyPub)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		accountClaims.DefaultPermissions.Pub.Deny = denyPub
 	}
 
 	if !data.DenySub.IsNull() {
-		var denySub []string
-		resp.Diagnostics.Append(data.DenySub.ElementsAs(ctx, &denySub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		denySub, d := stringListValues(ctx, data.DenySub)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		accountClaims.DefaultPermissions.Sub.Deny = denySub
 	}
@@ -881,78 +1068,34 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 	// Handle response permissions
 	if !data.AllowPubResponse.IsNull() {
 		max := data.AllowPubResponse.ValueInt64()
-		if max > 0 {
+		if max > 0 || max == responseUnlimited {
 			accountClaims.DefaultPermissions.Resp = &jwt.ResponsePermission{
 				MaxMsgs: int(max),
 			}
 
 			if !data.ResponseTTL.IsNull() && !data.ResponseTTL.IsUnknown() {
-				duration, diags := data.ResponseTTL.ValueGoDuration()
-				resp.Diagnostics.Append(diags...)
-				if resp.Diagnostics.HasError() {
-					return
+				duration, d := data.ResponseTTL.ValueGoDuration()
+				diags.Append(d...)
+				if diags.HasError() {
+					return nil, diags
 				}
 				accountClaims.DefaultPermissions.Resp.Expires = duration
 			}
 		}
 	}
 
-	// Handle expiry (support old, new, and absolute variants)
-	var expiresAtTime time.Time
-	if !data.ExpiresIn.IsNull() && !data.ExpiresIn.IsUnknown() {
-		// New relative duration - compute and store absolute
-		duration, diags := data.ExpiresIn.ValueGoDuration()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		if duration != 0 {
-			expiresAtTime = time.Now().Add(duration)
-			data.ExpiresAt = timetypes.NewRFC3339TimeValue(expiresAtTime)
-			accountClaims.Expires = expiresAtTime.Unix()
-		} else {
-			data.ExpiresAt = timetypes.NewRFC3339Null()
-		}
-	} else if !data.ExpiresAt.IsNull() && !data.ExpiresAt.IsUnknown() {
-		// Absolute timestamp provided
-		expiresAtTime, diags := data.ExpiresAt.ValueRFC3339Time()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		accountClaims.Expires = expiresAtTime.Unix()
-	} else {
-		// No expiry specified - set to null
-		data.ExpiresAt = timetypes.NewRFC3339Null()
-	}
-
-	// Handle start time (support old, new, and absolute variants)
-	var startsAtTime time.Time
-	if !data.StartsIn.IsNull() && !data.StartsIn.IsUnknown() {
-		// New relative duration - compute and store absolute
-		duration, diags := data.StartsIn.ValueGoDuration()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		if duration != 0 {
-			startsAtTime = time.Now().Add(duration)
-			data.StartsAt = timetypes.NewRFC3339TimeValue(startsAtTime)
-			accountClaims.NotBefore = startsAtTime.Unix()
-		} else {
-			data.StartsAt = timetypes.NewRFC3339Null()
-		}
-	} else if !data.StartsAt.IsNull() && !data.StartsAt.IsUnknown() {
-		// Absolute timestamp provided
-		startsAtTime, diags := data.StartsAt.ValueRFC3339Time()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
+	// Handle structured default permissions (exclusive with the flat attributes)
+	if data.DefaultPermissions != nil {
+		diags.Append(data.DefaultPermissions.apply(ctx, &accountClaims.DefaultPermissions)...)
+		if diags.HasError() {
+			return nil, diags
 		}
-		accountClaims.NotBefore = startsAtTime.Unix()
-	} else {
-		// No start time specified - set to null
-		data.StartsAt = timetypes.NewRFC3339Null()
+	}
+
+	// Handle expiry and start time
+	diags.Append(data.ValidityModel.apply(&accountClaims.ClaimsData)...)
+	if diags.HasError() {
+		return nil, diags
 	}
 
 	// Set Account Limits
@@ -983,8 +1126,17 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 	if !data.DisallowBearerToken.IsNull() {
 		accountClaims.Limits.DisallowBearer = data.DisallowBearerToken.ValueBool()
 	}
+	if !data.ClusterTraffic.IsNull() {
+		accountClaims.ClusterTraffic = jwt.ClusterTraffic(data.ClusterTraffic.ValueString())
+	}
 
-	// Set JetStream Limits
+	// Set JetStream Limits, with explicit limits overriding jetstream_enabled
+	if data.JetStreamEnabled.ValueBool() {
+		accountClaims.Limits.MemoryStorage = jwt.NoLimit
+		accountClaims.Limits.DiskStorage = jwt.NoLimit
+		accountClaims.Limits.Streams = jwt.NoLimit
+		accountClaims.Limits.Consumer = jwt.NoLimit
+	}
 	if !data.MaxMemoryStorage.IsNull() {
 		accountClaims.Limits.MemoryStorage = data.MaxMemoryStorage.ValueInt64()
 	}
@@ -1013,9 +1165,9 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 	// Handle exports
 	if !data.Exports.IsNull() && len(data.Exports.Elements()) > 0 {
 		var exports []ExportModel
-		resp.Diagnostics.Append(data.Exports.ElementsAs(ctx, &exports, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		diags.Append(data.Exports.ElementsAs(ctx, &exports, false)...)
+		if diags.HasError() {
+			return nil, diags
 		}
 
 		for _, export := range exports {
@@ -1030,11 +1182,11 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 			case "service":
 				jwtExport.Type = jwt.Service
 			default:
-				resp.Diagnostics.AddError(
+				diags.AddError(
 					"Invalid export type",
 					fmt.Sprintf("Export type must be 'stream' or 'service', got: %s", export.Type.ValueString()),
 				)
-				return
+				return nil, diags
 			}
 
 			// Optional fields
@@ -1045,13 +1197,17 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 				jwtExport.TokenReq = export.TokenRequired.ValueBool()
 			}
 			if !export.ResponseType.IsNull() {
-				jwtExport.ResponseType = jwt.ResponseType(export.ResponseType.ValueString())
+				if err := validateExportResponseType(export); err != nil {
+					diags.AddError("Invalid export", err.Error())
+					return nil, diags
+				}
+				jwtExport.ResponseType = exportResponseType(export.ResponseType.ValueString())
 			}
 			if !export.ResponseThreshold.IsNull() && !export.ResponseThreshold.IsUnknown() {
-				duration, diags := export.ResponseThreshold.ValueGoDuration()
-				resp.Diagnostics.Append(diags...)
-				if resp.Diagnostics.HasError() {
-					return
+				duration, d := export.ResponseThreshold.ValueGoDuration()
+				diags.Append(d...)
+				if diags.HasError() {
+					return nil, diags
 				}
 				jwtExport.ResponseThreshold = duration
 			}
@@ -1062,6 +1218,10 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 				jwtExport.Advertise = export.Advertise.ValueBool()
 			}
 			if !export.AllowTrace.IsNull() {
+				if err := validateExportAllowTrace(export); err != nil {
+					diags.AddError("Invalid export", err.Error())
+					return nil, diags
+				}
 				jwtExport.AllowTrace = export.AllowTrace.ValueBool()
 			}
 			if !export.Description.IsNull() {
@@ -1075,12 +1235,18 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 		}
 	}
 
+	// Handle revocations after exports, which export revocations refer to
+	diags.Append(applyRevocations(ctx, data.Revocations, accountClaims)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
 	// Handle imports
 	if !data.Imports.IsNull() && len(data.Imports.Elements()) > 0 {
 		var imports []ImportModel
-		resp.Diagnostics.Append(data.Imports.ElementsAs(ctx, &imports, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		diags.Append(data.Imports.ElementsAs(ctx, &imports, false)...)
+		if diags.HasError() {
+			return nil, diags
 		}
 
 		for _, imp := range imports {
@@ -1096,11 +1262,11 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 			case "service":
 				jwtImport.Type = jwt.Service
 			default:
-				resp.Diagnostics.AddError(
+				diags.AddError(
 					"Invalid import type",
 					fmt.Sprintf("Import type must be 'stream' or 'service', got: %s", imp.Type.ValueString()),
 				)
-				return
+				return nil, diags
 			}
 
 			// Optional fields
@@ -1108,15 +1274,30 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 				jwtImport.Name = imp.Name.ValueString()
 			}
 			if !imp.Token.IsNull() {
+				if err := validateImportToken(imp, data.Subject); err != nil {
+					diags.AddError("Invalid activation token", err.Error())
+					return nil, diags
+				}
 				jwtImport.Token = imp.Token.ValueString()
 			}
 			if !imp.LocalSubject.IsNull() {
 				jwtImport.LocalSubject = jwt.RenamingSubject(imp.LocalSubject.ValueString())
 			}
+			if !imp.To.IsNull() {
+				if err := validateImportTo(imp); err != nil {
+					diags.AddError("Invalid import", err.Error())
+					return nil, diags
+				}
+				jwtImport.To = jwt.Subject(imp.To.ValueString())
+			}
 			if !imp.Share.IsNull() {
 				jwtImport.Share = imp.Share.ValueBool()
 			}
 			if !imp.AllowTrace.IsNull() {
+				if err := validateImportAllowTrace(imp); err != nil {
+					diags.AddError("Invalid import", err.Error())
+					return nil, diags
+				}
 				jwtImport.AllowTrace = imp.AllowTrace.ValueBool()
 			}
 
@@ -1126,50 +1307,546 @@ func (r *AccountResource) Update(ctx context.Context, req resource.UpdateRequest
 
 	// Add signing keys if provided
 	if !data.SigningKeys.IsNull() && !data.SigningKeys.IsUnknown() {
-		var signingKeys []string
-		resp.Diagnostics.Append(data.SigningKeys.ElementsAs(ctx, &signingKeys, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		signingKeys, d := signingKeyPublicKeys(ctx, data.SigningKeys, nkeys.PrefixByteAccount)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
+		accountClaims.SigningKeys.Add(signingKeys...)
+	}
 
-		for _, key := range signingKeys {
-			if !strings.HasPrefix(key, "A") {
-				resp.Diagnostics.AddError(
-					"Invalid signing key",
-					fmt.Sprintf("Signing keys must be account public keys (start with 'A'), got: %s", key),
-				)
-				return
+	if data.NormalizePermissions.ValueBool() {
+		normalizePermissionLists(&accountClaims.DefaultPermissions)
+	}
+
+	if data.AuthCallout != nil {
+		diags.Append(data.AuthCallout.apply(ctx, &accountClaims.Authorization)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	diags.Append(validateAccountClaims(accountClaims)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return accountClaims, diags
+}
+
+// apply sets the external authorization of the account claims.
+func (m *AuthCalloutModel) apply(ctx context.Context, a *jwt.ExternalAuthorization) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.AuthUsers.IsNull() {
+		diags.AddAttributeError(
+			path.Root("auth_callout").AtName("auth_users"),
+			"Missing auth callout users",
+			"auth_callout requires at least one user in auth_users, the users the callout service connects as.",
+		)
+		return diags
+	}
+	for _, list := range []struct {
+		value  types.List
+		target *jwt.StringList
+	}{
+		{m.AuthUsers, &a.AuthUsers},
+		{m.AllowedAccounts, &a.AllowedAccounts},
+	} {
+		values, d := stringListValues(ctx, list.value)
+		diags.Append(d...)
+		if diags.HasError() {
+			return diags
+		}
+		list.target.Add(values...)
+	}
+	a.XKey = m.XKey.ValueString()
+
+	return diags
+}
+
+// validateLimits checks that the configured exports and imports fit into
+// max_exports and max_imports, so the contradiction fails at plan time
+// instead of in nats-server.
+func (m AccountClaimsModel) validateLimits() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	diags.Append(validateBlockCount(m.Exports, m.MaxExports.Int64(), "export", "max_exports")...)
+	diags.Append(validateBlockCount(m.Imports, m.MaxImports.Int64(), "import", "max_imports")...)
+	diags.Append(m.validateJetStreamTiers()...)
+
+	return diags
+}
+
+// jetStreamLimitKeys are the JSON keys of the global JetStream limits in the
+// limits of the account claims.
+var jetStreamLimitKeys = []string{
+	"mem_storage", "disk_storage", "streams", "consumer", "max_ack_pending",
+	"mem_max_stream_bytes", "disk_max_stream_bytes", "max_bytes_required",
+}
+
+// validateJetStreamTiers checks that tiered JetStream limits, which can only
+// be set through custom_claims_json, are not mixed with the global JetStream
+// limits, as the server ignores the global limits of an account with tiers.
+func (m AccountClaimsModel) validateJetStreamTiers() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.CustomClaimsJSON.IsNull() || m.CustomClaimsJSON.IsUnknown() {
+		return diags
+	}
+	// Invalid custom claims are reported by the attribute validator
+	custom, err := parseCustomClaims(m.CustomClaimsJSON.ValueString())
+	if err != nil {
+		return diags
+	}
+	nats, _ := custom["nats"].(map[string]any)
+	limits, _ := nats["limits"].(map[string]any)
+	if tiers, _ := limits["tiered_limits"].(map[string]any); len(tiers) == 0 {
+		return diags
+	}
+
+	var global []string
+	for _, attribute := range []struct {
+		name string
+		set  bool
+	}{
+		{"jetstream_enabled", m.JetStreamEnabled.ValueBool()},
+		{"max_memory_storage", !m.MaxMemoryStorage.IsNull()},
+		{"max_disk_storage", !m.MaxDiskStorage.IsNull()},
+		{"max_streams", !m.MaxStreams.IsNull()},
+		{"max_consumers", !m.MaxConsumers.IsNull()},
+		{"max_ack_pending", !m.MaxAckPending.IsNull()},
+		{"max_memory_stream_bytes", !m.MaxMemoryStreamBytes.IsNull()},
+		{"max_disk_stream_bytes", !m.MaxDiskStreamBytes.IsNull()},
+		{"max_bytes_required", !m.MaxBytesRequired.IsNull()},
+	} {
+		if attribute.set {
+			global = append(global, attribute.name)
+		}
+	}
+	for _, key := range jetStreamLimitKeys {
+		if _, ok := limits[key]; ok {
+			global = append(global, "nats.limits."+key)
+		}
+	}
+	if len(global) > 0 {
+		diags.AddAttributeError(
+			path.Root("custom_claims_json"),
+			"Conflicting JetStream limits",
+			fmt.Sprintf("custom_claims_json sets tiered JetStream limits in nats.limits.tiered_limits, which replace the global JetStream limits: the server ignores %s for an account with tiers. "+
+				"Set the limits per tier instead, or remove the tiers to use the global limits.", strings.Join(global, ", ")),
+		)
+	}
+
+	return diags
+}
+
+// validateBlockCount checks that the number of blocks does not exceed the
+// limit. Negative limits are unlimited; unknown values are skipped.
+func validateBlockCount(blocks types.List, limit types.Int64, blockName, limitName string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if limit.IsNull() || limit.IsUnknown() || limit.ValueInt64() < 0 {
+		return diags
+	}
+	if blocks.IsNull() || blocks.IsUnknown() {
+		return diags
+	}
+	if count := len(blocks.Elements()); int64(count) > limit.ValueInt64() {
+		diags.AddAttributeError(
+			path.Root(limitName),
+			fmt.Sprintf("Too many %ss", blockName),
+			fmt.Sprintf("The account has %d %s blocks but %s is %d. Raise %s, set it to unlimited, or remove %ss.", count, blockName, limitName, limit.ValueInt64(), limitName, blockName),
+		)
+	}
+
+	return diags
+}
+
+// validateImportTokens checks the activation tokens of all imports with known
+// values, so mismatched tokens fail at plan time.
+func (m AccountClaimsModel) validateImportTokens(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Imports.IsNull() || m.Imports.IsUnknown() {
+		return diags
+	}
+
+	var imports []ImportModel
+	diags.Append(m.Imports.ElementsAs(ctx, &imports, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, imp := range imports {
+		if err := validateImportToken(imp, m.Subject); err != nil {
+			diags.AddAttributeError(
+				path.Root("import").AtListIndex(i).AtName("token"),
+				"Invalid activation token",
+				err.Error(),
+			)
+		}
+	}
+
+	return diags
+}
+
+// validateImports checks the fields of all imports with known values that
+// only apply to some import types.
+func (m AccountClaimsModel) validateImports(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Imports.IsNull() || m.Imports.IsUnknown() {
+		return diags
+	}
+
+	var imports []ImportModel
+	diags.Append(m.Imports.ElementsAs(ctx, &imports, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, imp := range imports {
+		if err := validateImportTo(imp); err != nil {
+			diags.AddAttributeError(path.Root("import").AtListIndex(i).AtName("to"), "Invalid import", err.Error())
+		}
+		if err := validateImportAllowTrace(imp); err != nil {
+			diags.AddAttributeError(path.Root("import").AtListIndex(i).AtName("allow_trace"), "Invalid import", err.Error())
+		}
+		if err := validateImportExport(imp, m.Subject); err != nil {
+			diags.AddAttributeError(path.Root("import").AtListIndex(i).AtName("exporting_account_jwt"), "Import does not match an export", err.Error())
+		}
+	}
+
+	return diags
+}
+
+// validateExports checks the fields of all exports with known values that
+// only apply to some export types.
+func (m AccountClaimsModel) validateExports(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Exports.IsNull() || m.Exports.IsUnknown() {
+		return diags
+	}
+
+	var exports []ExportModel
+	diags.Append(m.Exports.ElementsAs(ctx, &exports, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, export := range exports {
+		if err := validateExportAllowTrace(export); err != nil {
+			diags.AddAttributeError(path.Root("export").AtListIndex(i).AtName("allow_trace"), "Invalid export", err.Error())
+		}
+		if err := validateExportSubject(exports, i); err != nil {
+			diags.AddAttributeError(path.Root("export").AtListIndex(i).AtName("subject"), "Overlapping export subjects", err.Error())
+		}
+		if err := validateExportResponseType(export); err != nil {
+			diags.AddAttributeError(path.Root("export").AtListIndex(i).AtName("response_type"), "Invalid export", err.Error())
+		}
+		if err := validateExportAccountTokenPosition(export); err != nil {
+			diags.AddAttributeError(path.Root("export").AtListIndex(i).AtName("account_token_position"), "Invalid export", err.Error())
+		}
+	}
+
+	return diags
+}
+
+// validateExportSubject checks that the subject of the i-th export is not
+// contained in the subject of another export of the same type, which the jwt
+// library rejects when the JWT is encoded. Duplicates fail for both exports.
+func validateExportSubject(exports []ExportModel, i int) error {
+	export := exports[i]
+	if export.Subject.IsUnknown() || export.Type.IsUnknown() {
+		return nil
+	}
+
+	for j, other := range exports {
+		if j == i || other.Subject.IsUnknown() || other.Type.IsUnknown() {
+			continue
+		}
+		if other.Type.ValueString() != export.Type.ValueString() {
+			continue
+		}
+		if jwt.Subject(export.Subject.ValueString()).IsContainedIn(jwt.Subject(other.Subject.ValueString())) {
+			return fmt.Errorf("%s export %q is already covered by %s export %q; exports of the same type must not overlap", export.Type.ValueString(), export.Subject.ValueString(), other.Type.ValueString(), other.Subject.ValueString())
+		}
+	}
+	return nil
+}
+
+// validateExportAccountTokenPosition checks that the account token position
+// of an export points at a * wildcard token of its subject, as the jwt
+// library rejects other positions when the JWT is encoded.
+func validateExportAccountTokenPosition(export ExportModel) error {
+	if export.AccountTokenPosition.IsNull() || export.AccountTokenPosition.IsUnknown() || export.Subject.IsUnknown() {
+		return nil
+	}
+	position := export.AccountTokenPosition.ValueInt64()
+	if position < 1 {
+		// Reported by the attribute validator
+		return nil
+	}
+
+	subject := export.Subject.ValueString()
+	tokens := strings.Split(subject, ".")
+	if position > int64(len(tokens)) {
+		return fmt.Errorf("account_token_position %d exceeds the %d tokens of export subject %q", position, len(tokens), subject)
+	}
+	if token := tokens[position-1]; token != "*" {
+		return fmt.Errorf("account_token_position %d of export %q points at %q, but must point at a * wildcard token", position, subject, token)
+	}
+	return nil
+}
+
+// exportResponseTypes lists the response types of service exports.
+var exportResponseTypes = []string{
+	string(jwt.ResponseTypeSingleton),
+	string(jwt.ResponseTypeStream),
+	string(jwt.ResponseTypeChunked),
+}
+
+// exportResponseType returns the response type matching the value regardless
+// of case, so that the JWT always carries the spelling the server expects.
+func exportResponseType(value string) jwt.ResponseType {
+	for _, responseType := range exportResponseTypes {
+		if strings.EqualFold(value, responseType) {
+			return jwt.ResponseType(responseType)
+		}
+	}
+	return jwt.ResponseType(value)
+}
+
+// validateExportResponseType checks that the response type is only set on
+// service exports; stream exports have no responses.
+func validateExportResponseType(export ExportModel) error {
+	if export.ResponseType.IsNull() || export.ResponseType.IsUnknown() || export.Type.IsUnknown() {
+		return nil
+	}
+	if export.Type.ValueString() != "service" {
+		return fmt.Errorf("export %q sets response_type, which is only supported on service exports", export.Subject.ValueString())
+	}
+	return nil
+}
+
+// validateExportAllowTrace checks that tracing is only allowed on service
+// exports, as the jwt library rejects it on stream exports.
+func validateExportAllowTrace(export ExportModel) error {
+	if !export.AllowTrace.ValueBool() || export.Type.IsUnknown() {
+		return nil
+	}
+	if export.Type.ValueString() != "service" {
+		return fmt.Errorf("export %q sets allow_trace, which is only supported on service exports", export.Subject.ValueString())
+	}
+	return nil
+}
+
+// validateImportAllowTrace checks that tracing is only allowed on stream
+// imports, as the jwt library rejects it on service imports.
+func validateImportAllowTrace(imp ImportModel) error {
+	if !imp.AllowTrace.ValueBool() || imp.Type.IsUnknown() {
+		return nil
+	}
+	if imp.Type.ValueString() != "stream" {
+		return fmt.Errorf("import %q sets allow_trace, which is only supported on stream imports", imp.Subject.ValueString())
+	}
+	return nil
+}
+
+// validateImportTo checks that the legacy to field is only set on stream
+// imports. On service imports to has different semantics, which
+// local_subject replaced.
+func validateImportTo(imp ImportModel) error {
+	if imp.To.IsNull() || imp.To.IsUnknown() || imp.Type.IsUnknown() {
+		return nil
+	}
+	if imp.Type.ValueString() != "stream" {
+		return fmt.Errorf("import %q sets to, which is only supported on stream imports; use local_subject instead", imp.Subject.ValueString())
+	}
+	return nil
+}
+
+// validateImportExport checks, when exporting_account_jwt is set, that the
+// exporting account has an export of the import type covering the imported
+// subject. The server silently ignores imports without a matching export.
+// Unknown values are skipped.
+func validateImportExport(imp ImportModel, accountPubKey types.String) error {
+	if imp.ExportingAccountJWT.IsNull() || imp.ExportingAccountJWT.IsUnknown() || imp.Subject.IsUnknown() || imp.Type.IsUnknown() {
+		return nil
+	}
+
+	exporter, err := jwt.DecodeAccountClaims(imp.ExportingAccountJWT.ValueString())
+	if err != nil {
+		return fmt.Errorf("failed to decode exporting account JWT of import %q: %w", imp.Subject.ValueString(), err)
+	}
+	if !imp.Account.IsUnknown() && exporter.Subject != imp.Account.ValueString() {
+		return fmt.Errorf("exporting_account_jwt of import %q is the JWT of account %s, not of account %s", imp.Subject.ValueString(), exporter.Subject, imp.Account.ValueString())
+	}
+
+	subject := jwt.Subject(imp.Subject.ValueString())
+	var otherType *jwt.Export
+	for _, export := range exporter.Exports {
+		if !subject.IsContainedIn(export.Subject) {
+			continue
+		}
+		if export.Type.String() != imp.Type.ValueString() {
+			otherType = export
+			continue
+		}
+		if export.TokenReq && imp.Token.IsNull() {
+			return fmt.Errorf("%s export %q of account %s requires an activation token; set token on import %q", export.Type, export.Subject, exporter.Subject, subject)
+		}
+		if export.AccountTokenPosition > 0 && !accountPubKey.IsNull() && !accountPubKey.IsUnknown() {
+			tokens := strings.Split(string(subject), ".")
+			if position := int(export.AccountTokenPosition); position <= len(tokens) && tokens[position-1] != accountPubKey.ValueString() {
+				return fmt.Errorf("%s export %q of account %s expects the public key of the importing account as token %d of the subject, got %q", export.Type, export.Subject, exporter.Subject, position, tokens[position-1])
 			}
-			accountClaims.SigningKeys.Add(key)
 		}
+		return nil
 	}
 
-	// Sign the JWT with operator key (already have operatorKP from above)
-	accountJWT, err := accountClaims.Encode(operatorKP)
+	if otherType != nil {
+		return fmt.Errorf("import %q is a %s import, but account %s exports %q as a %s", subject, imp.Type.ValueString(), exporter.Subject, otherType.Subject, otherType.Type)
+	}
+	return fmt.Errorf("account %s has no %s export covering %q", exporter.Subject, imp.Type.ValueString(), subject)
+}
+
+// validateImportToken checks that the activation token of an import was
+// issued by the exporting account to the importing account, for the same
+// export type and for a subject that covers the imported one. The server
+// silently ignores imports with mismatched tokens. Unknown values are skipped.
+func validateImportToken(imp ImportModel, accountPubKey types.String) error {
+	if imp.Token.IsNull() || imp.Token.IsUnknown() {
+		return nil
+	}
+
+	activation, err := jwt.DecodeActivationClaims(imp.Token.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to encode account JWT", err.Error())
-		return
+		return fmt.Errorf("failed to decode activation token of import %q: %w", imp.Subject.ValueString(), err)
 	}
 
-	// Update JWT while preserving immutable fields
-	data.ID = state.ID
-	data.PublicKey = state.PublicKey
-	data.Subject = state.Subject
-	data.JWT = types.StringValue(accountJWT)
+	if !imp.Account.IsUnknown() {
+		issuerAccount := activation.IssuerAccount
+		if issuerAccount == "" {
+			issuerAccount = activation.Issuer
+		}
+		if issuerAccount != imp.Account.ValueString() {
+			return fmt.Errorf("activation token for import %q was issued by account %s, not by the exporting account %s", imp.Subject.ValueString(), issuerAccount, imp.Account.ValueString())
+		}
+	}
 
-	tflog.Trace(ctx, "updated account resource")
+	if !accountPubKey.IsUnknown() && activation.Subject != accountPubKey.ValueString() {
+		return fmt.Errorf("activation token for import %q was issued to account %s, not to this account %s", imp.Subject.ValueString(), activation.Subject, accountPubKey.ValueString())
+	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if !imp.Type.IsUnknown() && activation.ImportType.String() != imp.Type.ValueString() {
+		return fmt.Errorf("activation token for import %q is for a %s export, but the import type is %s", imp.Subject.ValueString(), activation.ImportType, imp.Type.ValueString())
+	}
+
+	if !imp.Subject.IsUnknown() && !jwt.Subject(imp.Subject.ValueString()).IsContainedIn(activation.ImportSubject) {
+		return fmt.Errorf("activation token subject %q does not cover import %q", activation.ImportSubject, imp.Subject.ValueString())
+	}
+
+	return nil
 }
 
-func (r *AccountResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var data AccountResourceModel
+// validateIssuer checks, when operator_jwt is set, that the issuer is the
+// operator or one of its signing keys, and a signing key when the operator
+// requires strict signing key usage.
+func (m AccountClaimsModel) validateIssuer(issuer string) diag.Diagnostics {
+	var diags diag.Diagnostics
 
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() {
-		return
+	if m.OperatorJWT.IsNull() || m.OperatorJWT.IsUnknown() {
+		return diags
+	}
+	operator, err := jwt.DecodeOperatorClaims(m.OperatorJWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("operator_jwt"), "Invalid operator JWT", "Failed to decode operator JWT: "+err.Error())
+		return diags
 	}
 
-	// Nothing to clean up - all data is in state
-	tflog.Trace(ctx, "deleted account resource")
+	if issuer != operator.Subject && !operator.SigningKeys.Contains(issuer) {
+		diags.AddAttributeError(
+			path.Root("operator_jwt"),
+			"Issuer does not belong to operator",
+			fmt.Sprintf("The account is issued by %s, which is neither operator %s nor one of its signing keys.", issuer, operator.Subject),
+		)
+		return diags
+	}
+	if issuer == operator.Subject && operator.StrictSigningKeyUsage {
+		diags.AddAttributeError(
+			path.Root("operator_jwt"),
+			"Operator requires signing keys",
+			fmt.Sprintf("Operator %s sets strict_signing_key_usage, so accounts must be issued by one of its signing keys, not the operator key.", operator.Subject),
+		)
+	}
+
+	return diags
+}
+
+// accountIssuer returns the operator keypair used to sign the account JWT.
+// With issuer_key_name or issuer_public_key the keypair only holds the public
+// key and signing is delegated to the provider's external signer through the
+// returned SignFn.
+func accountIssuer(ctx context.Context, signer externalSigner, keys *keypairCache, keyName, publicKey, seed types.String) (nkeys.KeyPair, jwt.SignFn, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !keyName.IsNull() || !publicKey.IsNull() {
+		operatorKP, signFn, err := externalIssuer(ctx, signer, keyName.ValueString(), publicKey.ValueString(), nkeys.PrefixByteOperator)
+		if err != nil {
+			diags.AddError("Failed to load operator key", err.Error())
+			return nil, nil, diags
+		}
+		return operatorKP, signFn, diags
+	}
+
+	// Get operator seed (issuer) for signing from Config
+	operatorSeedStr, err := keys.seed(seed.ValueString())
+	if err != nil {
+		diags.AddError("Invalid operator seed", err.Error())
+		return nil, nil, diags
+	}
+	if operatorSeedStr == "" {
+		diags.AddError(
+			"Missing operator seed",
+			"Operator seed (issuer_seed) is required",
+		)
+		return nil, nil, diags
+	}
+	if !strings.HasPrefix(operatorSeedStr, "SO") {
+		prefix := operatorSeedStr
+		if len(prefix) > 2 {
+			prefix = prefix[:2]
+		}
+		diags.AddError(
+			"Invalid operator seed",
+			fmt.Sprintf("Operator seed must start with 'SO', got: %s", prefix),
+		)
+		return nil, nil, diags
+	}
+
+	operatorKP, err := keys.fromSeed(operatorSeedStr)
+	if err != nil {
+		diags.AddError("Failed to parse operator seed", err.Error())
+		return nil, nil, diags
+	}
+
+	operatorPubKey, err := operatorKP.PublicKey()
+	if err != nil {
+		diags.AddError("Failed to get operator public key", err.Error())
+		return nil, nil, diags
+	}
+
+	// Validate it's actually an operator key
+	if !strings.HasPrefix(operatorPubKey, "O") {
+		diags.AddError(
+			"Invalid operator seed",
+			fmt.Sprintf("Seed does not generate an operator public key (expected O*, got %s)", operatorPubKey),
+		)
+		return nil, nil, diags
+	}
+
+	return operatorKP, nil, diags
 }
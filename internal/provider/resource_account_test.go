@@ -1,12 +1,22 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
 )
 
 func TestAccAccountResource_basic(t *testing.T) {
@@ -24,6 +34,7 @@ func TestAccAccountResource_basic(t *testing.T) {
 					resource.TestCheckResourceAttrSet("nsc_account.test", "public_key"),
 					testAccCheckAccountPublicKeyFormat("nsc_account.test", "public_key"),
 					testAccCheckAccountPublicKeyFormat("nsc_account.test", "subject"),
+					testAccCheckJWTIssueValues("nsc_account.test", "jwt"),
 				),
 			},
 			// Update and Read testing
@@ -31,6 +42,7 @@ func TestAccAccountResource_basic(t *testing.T) {
 				Config: testAccAccountResourceConfig("UpdatedAccount"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("nsc_account.test", "name", "UpdatedAccount"),
+					testAccCheckJWTIssueValues("nsc_account.test", "jwt"),
 				),
 			},
 		},
@@ -124,13 +136,34 @@ func TestAccAccountResource_withResponsePermissions(t *testing.T) {
 		Steps: []resource.TestStep{
 			// Create with response permissions
 			{
-				Config: testAccAccountResourceConfigWithResponsePermissions(),
+				Config: testAccAccountResourceConfigWithResponsePermissions(5),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("nsc_account.test", "name", "TestAccount"),
 					resource.TestCheckResourceAttr("nsc_account.test", "allow_pub_response", "5"),
 					resource.TestCheckResourceAttr("nsc_account.test", "response_ttl", "10s"),
 				),
 			},
+			// Unlimited responses
+			{
+				Config: testAccAccountResourceConfigWithResponsePermissions(-1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "allow_pub_response", "-1"),
+					resource.TestCheckResourceAttrWith("nsc_account.test", "jwt", func(value string) error {
+						claims, err := jwt.DecodeAccountClaims(value)
+						if err != nil {
+							return err
+						}
+						if claims.DefaultPermissions.Resp == nil || claims.DefaultPermissions.Resp.MaxMsgs != -1 {
+							return fmt.Errorf("expected unlimited response permission, got %+v", claims.DefaultPermissions.Resp)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config:      testAccAccountResourceConfigWithResponsePermissions(-2),
+				ExpectError: regexp.MustCompile(`allow_pub_response`),
+			},
 		},
 	})
 }
@@ -193,6 +226,49 @@ func TestAccAccountResource_withImports(t *testing.T) {
 	})
 }
 
+func TestAccAccountResource_importTo(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithImport(`
+    subject = "shared.events.>"
+    type    = "stream"
+    to      = "events.>"
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.consumer", "import.0.to", "events.>"),
+					testAccCheckAccountClaims("nsc_account.consumer", func(claims *jwt.AccountClaims) error {
+						imp := claims.Imports[0]
+						if imp.GetTo() != "events.>" || imp.LocalSubject != "" {
+							return fmt.Errorf("expected to = events.> without local_subject, got to %q, local_subject %q", imp.GetTo(), imp.LocalSubject)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: testAccAccountResourceConfigWithImport(`
+    subject       = "shared.events.>"
+    type          = "stream"
+    to            = "events.>"
+    local_subject = "events.>"
+`),
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+			{
+				Config: testAccAccountResourceConfigWithImport(`
+    subject = "shared.rpc"
+    type    = "service"
+    to      = "rpc"
+`),
+				ExpectError: regexp.MustCompile(`only supported on stream imports`),
+			},
+		},
+	})
+}
+
 func testAccAccountResourceConfig(name string) string {
 	return fmt.Sprintf(`
 resource "nsc_nkey" "operator" {
@@ -273,8 +349,8 @@ resource "nsc_account" "test" {
 `
 }
 
-func testAccAccountResourceConfigWithResponsePermissions() string {
-	return `
+func testAccAccountResourceConfigWithResponsePermissions(allowPubResponse int) string {
+	return fmt.Sprintf(`
 resource "nsc_nkey" "operator" {
   type = "operator"
 }
@@ -293,10 +369,10 @@ resource "nsc_account" "test" {
   name               = "TestAccount"
   subject            = nsc_nkey.account.public_key
   issuer_seed        = nsc_nkey.operator.seed
-  allow_pub_response = 5
+  allow_pub_response = %d
   response_ttl       = "10s"
 }
-`
+`, allowPubResponse)
 }
 
 func testAccAccountResourceConfigWithExpiry(expiry, start string) string {
@@ -495,6 +571,32 @@ resource "nsc_account" "consumer" {
 `
 }
 
+func testAccAccountResourceConfigWithImport(importBody string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "provider_account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "consumer_account" {
+  type = "account"
+}
+
+resource "nsc_account" "consumer" {
+  name        = "ConsumerAccount"
+  subject     = nsc_nkey.consumer_account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+
+  import {
+    account = nsc_nkey.provider_account.public_key
+%s  }
+}
+`, importBody)
+}
+
 func testAccCheckAccountPublicKeyFormat(resourceName, attrName string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[resourceName]
@@ -515,3 +617,1690 @@ func testAccCheckAccountPublicKeyFormat(resourceName, attrName string) resource.
 		return nil
 	}
 }
+
+func TestAccAccountResource_jwtOutput(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Default: both jwt and jwt_sensitive are populated
+			{
+				Config: testAccAccountResourceConfig("TestAccount"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "jwt_output", "always"),
+					resource.TestCheckResourceAttrSet("nsc_account.test", "jwt"),
+					resource.TestCheckResourceAttrSet("nsc_account.test", "jwt_sensitive"),
+				),
+			},
+			// sensitive_only: jwt is null, jwt_sensitive is populated
+			{
+				Config: testAccAccountResourceConfigWithJWTOutput("sensitive_only"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "jwt_output", "sensitive_only"),
+					resource.TestCheckNoResourceAttr("nsc_account.test", "jwt"),
+					resource.TestCheckResourceAttrSet("nsc_account.test", "jwt_sensitive"),
+					testAccCheckJWTIssueValues("nsc_account.test", "jwt_sensitive"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_invalidJWTOutput(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccAccountResourceConfigWithJWTOutput("never"),
+				ExpectError: regexp.MustCompile(`value must be one of`),
+			},
+		},
+	})
+}
+
+func testAccAccountResourceConfigWithJWTOutput(mode string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_operator" "test" {
+  name        = "TestOperator"
+  subject     = nsc_nkey.operator.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+  jwt_output  = %[1]q
+}
+`, mode)
+}
+
+func TestAccountResource_upgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	r := &AccountResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	// Legacy state using the deprecated expiry/start attributes
+	req := fwresource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{
+			JSON: []byte(`{
+				"id": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+				"name": "Legacy",
+				"subject": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+				"expiry": "720h",
+				"start": "1h",
+				"allow_pub_response": 0,
+				"jwt": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig",
+				"public_key": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF"
+			}`),
+		},
+	}
+	var resp fwresource.UpgradeStateResponse
+	r.UpgradeState(ctx)[0].StateUpgrader(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	raw, err := resp.DynamicValue.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		t.Fatalf("upgraded state does not match schema: %s", err)
+	}
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: raw}
+
+	var data AccountResourceModel
+	if diags := state.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read upgraded state: %v", diags)
+	}
+
+	if data.ExpiresIn.ValueString() != "720h" {
+		t.Errorf("expected expires_in = 720h, got %q", data.ExpiresIn.ValueString())
+	}
+	if data.StartsIn.ValueString() != "1h" {
+		t.Errorf("expected starts_in = 1h, got %q", data.StartsIn.ValueString())
+	}
+	if data.JWTOutput.ValueString() != "always" {
+		t.Errorf("expected jwt_output = always, got %q", data.JWTOutput.ValueString())
+	}
+	if data.JWTSensitive.ValueString() != data.JWT.ValueString() {
+		t.Errorf("expected jwt_sensitive to match jwt, got %q", data.JWTSensitive.ValueString())
+	}
+}
+
+func TestAccountResource_upgradeStateV1(t *testing.T) {
+	ctx := context.Background()
+	r := &AccountResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	// Byte limits were numbers before schema version 2
+	req := fwresource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{
+			JSON: []byte(`{
+				"id": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+				"name": "Limited",
+				"subject": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+				"max_data": -1,
+				"max_disk_storage": 9007199254740993,
+				"max_connections": 100,
+				"jwt_output": "always",
+				"public_key": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF"
+			}`),
+		},
+	}
+	var resp fwresource.UpgradeStateResponse
+	r.UpgradeState(ctx)[1].StateUpgrader(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	raw, err := resp.DynamicValue.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		t.Fatalf("upgraded state does not match schema: %s", err)
+	}
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: raw}
+
+	var data AccountResourceModel
+	if diags := state.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read upgraded state: %v", diags)
+	}
+
+	if data.MaxData.ValueString() != "-1" {
+		t.Errorf("expected max_data = -1, got %q", data.MaxData.ValueString())
+	}
+	if data.MaxDiskStorage.ValueString() != "9007199254740993" {
+		t.Errorf("expected max_disk_storage to keep its exact value, got %q", data.MaxDiskStorage.ValueString())
+	}
+	if !data.MaxPayload.IsNull() {
+		t.Errorf("expected max_payload to stay null, got %q", data.MaxPayload.ValueString())
+	}
+	if data.MaxConnections.ValueInt64() != 100 {
+		t.Errorf("expected max_connections = 100, got %d", data.MaxConnections.ValueInt64())
+	}
+}
+
+func TestAccountResource_upgradeStateV2(t *testing.T) {
+	ctx := context.Background()
+	r := &AccountResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	// Count limits were numbers before schema version 3
+	req := fwresource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{
+			JSON: []byte(`{
+				"id": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+				"name": "Limited",
+				"subject": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+				"max_data": "10GiB",
+				"max_connections": -1,
+				"max_streams": 10,
+				"jwt_output": "always",
+				"public_key": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF"
+			}`),
+		},
+	}
+	var resp fwresource.UpgradeStateResponse
+	r.UpgradeState(ctx)[2].StateUpgrader(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	raw, err := resp.DynamicValue.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		t.Fatalf("upgraded state does not match schema: %s", err)
+	}
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: raw}
+
+	var data AccountResourceModel
+	if diags := state.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read upgraded state: %v", diags)
+	}
+
+	if data.MaxData.ValueString() != "10GiB" {
+		t.Errorf("expected max_data = 10GiB, got %q", data.MaxData.ValueString())
+	}
+	if data.MaxConnections.ValueString() != "-1" {
+		t.Errorf("expected max_connections = -1, got %q", data.MaxConnections.ValueString())
+	}
+	if data.MaxStreams.ValueInt64() != 10 {
+		t.Errorf("expected max_streams = 10, got %d", data.MaxStreams.ValueInt64())
+	}
+	if !data.MaxConsumers.IsNull() {
+		t.Errorf("expected max_consumers to stay null, got %q", data.MaxConsumers.ValueString())
+	}
+}
+
+func TestAccountResource_modifyPlan(t *testing.T) {
+	ctx := context.Background()
+	r := &AccountResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	state := testRawValue(t, schemaResp.Schema, `{
+		"name": "Before",
+		"subject": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+		"jwt_output": "sensitive_only",
+		"jwt_sensitive": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig"
+	}`)
+	plan := testRawValue(t, schemaResp.Schema, `{
+		"name": "After",
+		"subject": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+		"jwt_output": "sensitive_only",
+		"jwt_sensitive": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig"
+	}`)
+
+	req := fwresource.ModifyPlanRequest{
+		State: tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+		Plan:  tfsdk.Plan{Schema: schemaResp.Schema, Raw: plan},
+	}
+	resp := fwresource.ModifyPlanResponse{
+		Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: plan},
+	}
+	r.ModifyPlan(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var data AccountResourceModel
+	if diags := resp.Plan.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read plan: %v", diags)
+	}
+	if !data.JWTSensitive.IsUnknown() {
+		t.Errorf("expected jwt_sensitive to be unknown, got %s", data.JWTSensitive)
+	}
+	if !data.JWT.IsNull() {
+		t.Errorf("expected jwt to be null for sensitive_only, got %s", data.JWT)
+	}
+
+	// An unchanged plan keeps the current JWT
+	req.Plan.Raw = state
+	resp = fwresource.ModifyPlanResponse{
+		Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: state},
+	}
+	r.ModifyPlan(ctx, req, &resp)
+	if !resp.Plan.Raw.Equal(state) {
+		t.Error("expected unchanged plan not to be modified")
+	}
+}
+
+// testRawValue decodes a JSON object into a value of the schema type.
+// Attributes missing from the JSON are null.
+func testRawValue(t *testing.T, s schema.Schema, value string) tftypes.Value {
+	t.Helper()
+
+	raw, err := (&tfprotov6.DynamicValue{JSON: []byte(value)}).Unmarshal(s.Type().TerraformType(context.Background()))
+	if err != nil {
+		t.Fatalf("failed to decode value: %s", err)
+	}
+	return raw
+}
+
+func TestAccAccountResource_withExpiresAt(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  expires_at = "2030-01-01T00:00:00Z"
+  starts_at  = "2025-01-01T00:00:00Z"
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "expires_at", "2030-01-01T00:00:00Z"),
+					resource.TestCheckResourceAttr("nsc_account.test", "starts_at", "2025-01-01T00:00:00Z"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_conflictingValidityAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  expires_in = "720h"
+  expires_at = "2030-01-01T00:00:00Z"
+`),
+				ExpectError: regexp.MustCompile("Only one of 'expires_in' or 'expires_at' can be specified"),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  starts_in = "1h"
+  starts_at = "2025-01-01T00:00:00Z"
+`),
+				ExpectError: regexp.MustCompile("Only one of 'starts_in' or 'starts_at' can be specified"),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  expires_in = "-720h"
+`),
+				ExpectError: regexp.MustCompile("must not be negative"),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_defaultPermissionsBlock(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  default_permissions {
+    pub {
+      allow = ["app.>", "events.>"]
+      deny  = ["admin.>"]
+    }
+    sub {
+      allow = ["app.>"]
+    }
+    resp {
+      max = 5
+      ttl = "1m"
+    }
+  }
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "default_permissions.pub.allow.#", "2"),
+					resource.TestCheckResourceAttr("nsc_account.test", "default_permissions.pub.deny.0", "admin.>"),
+					resource.TestCheckResourceAttr("nsc_account.test", "default_permissions.sub.allow.0", "app.>"),
+					resource.TestCheckResourceAttr("nsc_account.test", "default_permissions.resp.max", "5"),
+					resource.TestCheckNoResourceAttr("nsc_account.test", "allow_pub.#"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_userJWTs(t *testing.T) {
+	var accountJWTID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithUserJWTs("alice"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "user_jwts.#", "1"),
+					resource.TestCheckResourceAttrWith("nsc_account.test", "jwt_id", func(value string) error {
+						accountJWTID = value
+						return nil
+					}),
+				),
+			},
+			// A change to the users alone keeps the account JWT
+			{
+				Config: testAccAccountResourceConfigWithUserJWTs("bob"),
+				Check: resource.TestCheckResourceAttrWith("nsc_account.test", "jwt_id", func(value string) error {
+					if value != accountJWTID {
+						return fmt.Errorf("expected the account JWT to be kept")
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_signingKeysOrder(t *testing.T) {
+	var accountJWTID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithSigningKeys("nsc_nkey.first.public_key, nsc_nkey.second.public_key"),
+				Check: resource.TestCheckResourceAttrWith("nsc_account.test", "jwt_id", func(value string) error {
+					accountJWTID = value
+					return nil
+				}),
+			},
+			// Reordered and repeated keys make the same JWT
+			{
+				Config: testAccAccountResourceConfigWithSigningKeys("nsc_nkey.second.public_key, nsc_nkey.first.seed, nsc_nkey.second.public_key"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "signing_keys.#", "3"),
+					resource.TestCheckResourceAttrWith("nsc_account.test", "jwt_id", func(value string) error {
+						if value != accountJWTID {
+							return fmt.Errorf("expected the account JWT to be kept")
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_pinIssuedAt(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithIssuedAt("TestAccount", "2024-01-01T00:00:00Z"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "issued_at", "2024-01-01T00:00:00Z"),
+					testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+						if claims.IssuedAt != 1704067200 {
+							return fmt.Errorf("expected iat 1704067200, got %d", claims.IssuedAt)
+						}
+						return nil
+					}),
+				),
+			},
+			// A re-issue keeps the pinned iat
+			{
+				Config: testAccAccountResourceConfigWithIssuedAt("RenamedAccount", "2024-01-01T00:00:00Z"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "name", "RenamedAccount"),
+					resource.TestCheckResourceAttr("nsc_account.test", "issued_at", "2024-01-01T00:00:00Z"),
+				),
+			},
+		},
+	})
+}
+
+func testAccAccountResourceConfigWithIssuedAt(name, issuedAt string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name        = %[1]q
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+  issued_at   = %[2]q
+}
+`, name, issuedAt)
+}
+
+func testAccAccountResourceConfigWithSigningKeys(signingKeys string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "first" {
+  type = "account"
+}
+
+resource "nsc_nkey" "second" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name         = "TestAccount"
+  subject      = nsc_nkey.account.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = [%s]
+}
+`, signingKeys)
+}
+
+func testAccAccountResourceConfigWithUserJWTs(userName string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "signing_key" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name           = %[1]q
+  subject        = nsc_nkey.user.public_key
+  issuer_seed    = nsc_nkey.signing_key.seed
+  issuer_account = nsc_nkey.account.public_key
+}
+
+resource "nsc_account" "test" {
+  name         = "TestAccount"
+  subject      = nsc_nkey.account.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = [nsc_nkey.signing_key.public_key]
+  user_jwts    = [nsc_user.test.jwt]
+}
+`, userName)
+}
+
+func TestAccAccountResource_defaultPermissionsConflict(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  allow_pub = ["app.>"]
+
+  default_permissions {
+    sub {
+      allow = ["app.>"]
+    }
+  }
+`),
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func testAccAccountResourceConfigWithValidity(validity string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+%[1]s}
+`, validity)
+}
+
+func TestAccAccountResource_signingKeySeed(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithSigningKey("nsc_nkey.signing.seed"),
+				Check: func(s *terraform.State) error {
+					publicKey := s.RootModule().Resources["nsc_nkey.signing"].Primary.Attributes["public_key"]
+					return testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+						if len(claims.SigningKeys) != 1 || !claims.SigningKeys.Contains(publicKey) {
+							return fmt.Errorf("expected signing keys [%s], got %v", publicKey, claims.SigningKeys.Keys())
+						}
+						return nil
+					})(s)
+				},
+			},
+			{
+				Config:      testAccAccountResourceConfigWithSigningKey("nsc_nkey.operator.seed"),
+				ExpectError: regexp.MustCompile(`is not an account seed`),
+			},
+		},
+	})
+}
+
+func testAccAccountResourceConfigWithSigningKey(signingKey string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "signing" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name         = "TestAccount"
+  subject      = nsc_nkey.account.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = [%s]
+}
+`, signingKey)
+}
+
+func TestAccAccountResource_byteSizes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_data           = "10GiB"
+  max_payload        = "1MiB"
+  max_memory_storage = "512MB"
+  max_disk_storage   = -1
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "max_data", "10GiB"),
+					testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+						limits := claims.Limits
+						if limits.Data != 10<<30 || limits.Payload != 1<<20 || limits.MemoryStorage != 512000000 || limits.DiskStorage != -1 {
+							return fmt.Errorf("unexpected limits: %+v", limits)
+						}
+						return nil
+					}),
+				),
+			},
+			// The same sizes written differently do not change the account
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_data           = 10737418240
+  max_payload        = "1024KiB"
+  max_memory_storage = "512000000"
+  max_disk_storage   = "-1"
+`),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_payload = "1 GB of data"
+`),
+				ExpectError: regexp.MustCompile(`Invalid byte size`),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_limitSentinels(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_connections       = "unlimited"
+  max_data              = "unlimited"
+  max_memory_storage    = "disabled"
+  max_disk_storage      = "10GiB"
+  max_streams           = 10
+  max_disk_stream_bytes = "unlimited"
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "max_connections", "unlimited"),
+					testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+						limits := claims.Limits
+						if limits.Conn != -1 || limits.Data != -1 || limits.MemoryStorage != 0 || limits.Streams != 10 || limits.DiskMaxStreamBytes != -1 {
+							return fmt.Errorf("unexpected limits: %+v", limits)
+						}
+						return nil
+					}),
+				),
+			},
+			// Numbers and sentinels of the same limit do not change the account
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_connections       = -1
+  max_data              = -1
+  max_memory_storage    = 0
+  max_disk_storage      = "10GiB"
+  max_streams           = "10"
+  max_disk_stream_bytes = -1
+`),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_connections       = "lots"
+`),
+				ExpectError: regexp.MustCompile(`Invalid limit`),
+			},
+			// 0 means unlimited for stream bytes, so disabled is rejected
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_memory_stream_bytes = "disabled"
+`),
+				ExpectError: regexp.MustCompile(`max_memory_stream_bytes`),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_jetstreamEnabled(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  jetstream_enabled = true
+`),
+				Check: testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+					if !claims.Limits.IsJSEnabled() || !claims.Limits.JetStreamLimits.IsUnlimited() {
+						return fmt.Errorf("expected unlimited JetStream, got %+v", claims.Limits.JetStreamLimits)
+					}
+					return nil
+				}),
+			},
+			// Explicit limits override the unlimited defaults
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  jetstream_enabled = true
+  max_disk_storage  = "10GiB"
+  max_streams       = 5
+`),
+				Check: testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+					limits := claims.Limits
+					if limits.MemoryStorage != -1 || limits.DiskStorage != 10<<30 || limits.Streams != 5 || limits.Consumer != -1 {
+						return fmt.Errorf("unexpected JetStream limits: %+v", limits.JetStreamLimits)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_clusterTraffic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`  cluster_traffic = "owner"
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "cluster_traffic", "owner"),
+					testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+						if claims.ClusterTraffic != jwt.ClusterTrafficOwner {
+							return fmt.Errorf("expected cluster_traffic %q, got %q", jwt.ClusterTrafficOwner, claims.ClusterTraffic)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`  cluster_traffic = "everyone"
+`),
+				ExpectError: regexp.MustCompile(`value must be one of`),
+			},
+		},
+	})
+}
+
+// testAccCheckAccountClaims decodes the account JWT and passes the claims to check.
+func testAccCheckAccountClaims(resourceName string, check func(*jwt.AccountClaims) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", resourceName)
+		}
+
+		claims, err := jwt.DecodeAccountClaims(rs.Primary.Attributes["jwt"])
+		if err != nil {
+			return fmt.Errorf("failed to decode account JWT: %w", err)
+		}
+		return check(claims)
+	}
+}
+
+func TestAccountClaimsModel_validateJetStreamTiers(t *testing.T) {
+	tiers := `{"nats": {"limits": {"tiered_limits": {"R1": {"disk_storage": 1024}}}}}`
+
+	for name, tt := range map[string]struct {
+		model AccountClaimsModel
+		want  string
+	}{
+		"tiers only": {AccountClaimsModel{
+			CustomClaimsJSON: types.StringValue(tiers),
+		}, ""},
+		"global limits only": {AccountClaimsModel{
+			JetStreamEnabled: types.BoolValue(true),
+			MaxDiskStorage:   NewByteSizeValue("1GiB"),
+			CustomClaimsJSON: types.StringValue(`{"nats": {"limits": {"streams": 5}}}`),
+		}, ""},
+		"tiers with attributes": {AccountClaimsModel{
+			JetStreamEnabled: types.BoolValue(true),
+			MaxStreams:       NewLimitValue("10"),
+			CustomClaimsJSON: types.StringValue(tiers),
+		}, "jetstream_enabled, max_streams"},
+		"tiers with global custom limits": {AccountClaimsModel{
+			CustomClaimsJSON: types.StringValue(`{"nats": {"limits": {"mem_storage": -1, "tiered_limits": {"R3": {}}}}}`),
+		}, "nats.limits.mem_storage"},
+	} {
+		diags := tt.model.validateJetStreamTiers()
+		if tt.want == "" {
+			if diags.HasError() {
+				t.Errorf("%s: unexpected error: %v", name, diags)
+			}
+			continue
+		}
+		if !diags.HasError() || !strings.Contains(diags[0].Detail(), tt.want) {
+			t.Errorf("%s: expected error naming %q, got %v", name, tt.want, diags)
+		}
+	}
+}
+
+func TestAccAccountResource_customClaimsJSON(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`  max_connections = 10
+
+  custom_claims_json = jsonencode({
+    nats = {
+      description = "Billing services"
+      info_url    = "https://example.com/billing"
+      limits      = { leaf = 2 }
+    }
+  })
+`),
+				Check: testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+					if claims.Description != "Billing services" || claims.InfoURL != "https://example.com/billing" {
+						return fmt.Errorf("expected custom description and info_url, got %q and %q", claims.Description, claims.InfoURL)
+					}
+					if claims.Limits.Conn != 10 || claims.Limits.LeafNodeConn != 2 {
+						return fmt.Errorf("expected merged limits, got conn %d and leaf %d", claims.Limits.Conn, claims.Limits.LeafNodeConn)
+					}
+					return nil
+				}),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`  custom_claims_json = jsonencode({ nats = { type = "operator" } })
+`),
+				ExpectError: regexp.MustCompile(`cannot set "nats.type"`),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_claimsPatchJSON(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`  max_connections = 10
+  expires_in      = "720h"
+
+  custom_claims_json = jsonencode({ nats = { description = "Billing services" } })
+  claims_patch_json  = jsonencode({
+    exp  = null
+    nats = {
+      description = "Patched"
+      limits      = { leaf = 2 }
+    }
+  })
+`),
+				Check: testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+					if claims.Description != "Patched" {
+						return fmt.Errorf("expected the patch to apply after custom claims, got description %q", claims.Description)
+					}
+					if claims.Expires != 0 {
+						return fmt.Errorf("expected the patch to remove the expiry, got %d", claims.Expires)
+					}
+					if claims.Limits.Conn != 10 || claims.Limits.LeafNodeConn != 2 {
+						return fmt.Errorf("expected merged limits, got conn %d and leaf %d", claims.Limits.Conn, claims.Limits.LeafNodeConn)
+					}
+					return nil
+				}),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`  claims_patch_json = jsonencode({ iss = "OABC" })
+`),
+				ExpectError: regexp.MustCompile(`claims patch cannot change "iss"`),
+			},
+		},
+	})
+}
+
+func TestValidateImportExport(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	exporterKP, _ := nkeys.CreateAccount()
+	exporterPubKey, _ := exporterKP.PublicKey()
+	importerKP, _ := nkeys.CreateAccount()
+	importerPubKey, _ := importerKP.PublicKey()
+
+	exporter := jwt.NewAccountClaims(exporterPubKey)
+	exporter.Exports.Add(
+		&jwt.Export{Subject: "events.>", Type: jwt.Stream},
+		&jwt.Export{Subject: "svc.orders", Type: jwt.Service},
+		&jwt.Export{Subject: "private.>", Type: jwt.Stream, TokenReq: true},
+		&jwt.Export{Subject: "tenant.*.jobs", Type: jwt.Service, AccountTokenPosition: 2},
+	)
+	exporterJWT, err := exporter.Encode(operatorKP)
+	if err != nil {
+		t.Fatalf("failed to encode exporter: %v", err)
+	}
+
+	imp := func(subject, importType string) ImportModel {
+		return ImportModel{
+			Subject:             types.StringValue(subject),
+			Account:             types.StringValue(exporterPubKey),
+			Type:                types.StringValue(importType),
+			Token:               types.StringNull(),
+			ExportingAccountJWT: types.StringValue(exporterJWT),
+		}
+	}
+	withToken := imp("private.orders", "stream")
+	withToken.Token = types.StringValue("token")
+	otherAccount := imp("events.>", "stream")
+	otherAccount.Account = types.StringValue(importerPubKey)
+
+	tests := map[string]struct {
+		imp           ImportModel
+		expectedError string
+	}{
+		"matching":               {imp: imp("events.orders", "stream")},
+		"matching service":       {imp: imp("svc.orders", "service")},
+		"token":                  {imp: withToken},
+		"account token position": {imp: imp("tenant."+importerPubKey+".jobs", "service")},
+		"no exporting JWT":       {imp: ImportModel{Subject: types.StringValue("other"), Type: types.StringValue("stream"), ExportingAccountJWT: types.StringNull()}},
+		"unknown exporting JWT":  {imp: ImportModel{Subject: types.StringValue("other"), Type: types.StringValue("stream"), ExportingAccountJWT: types.StringUnknown()}},
+		"type mismatch": {
+			imp:           imp("svc.orders", "stream"),
+			expectedError: "is a stream import, but account " + exporterPubKey + ` exports "svc.orders" as a service`,
+		},
+		"no export": {
+			imp:           imp("other.>", "stream"),
+			expectedError: `has no stream export covering "other.>"`,
+		},
+		"wider subject": {
+			imp:           imp(">", "stream"),
+			expectedError: "has no stream export",
+		},
+		"token required": {
+			imp:           imp("private.orders", "stream"),
+			expectedError: "requires an activation token",
+		},
+		"other importer": {
+			imp:           imp("tenant."+exporterPubKey+".jobs", "service"),
+			expectedError: "expects the public key of the importing account as token 2",
+		},
+		"other account": {
+			imp:           otherAccount,
+			expectedError: "is the JWT of account " + exporterPubKey,
+		},
+		"invalid JWT": {
+			imp:           ImportModel{Subject: types.StringValue("events.>"), Type: types.StringValue("stream"), ExportingAccountJWT: types.StringValue("not-a-jwt")},
+			expectedError: "failed to decode exporting account JWT",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateImportExport(tt.imp, types.StringValue(importerPubKey))
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestAccAccountResource_importExportingAccountJWT(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithExportingAccount(""),
+			},
+			{
+				Config:      testAccAccountResourceConfigWithExportingAccount(`type = "service"`),
+				ExpectError: regexp.MustCompile(`is a service import, but account`),
+			},
+			{
+				Config: testAccAccountResourceConfigWithExportingAccount(`type = "stream"`),
+				Check:  resource.TestCheckResourceAttrPair("nsc_account.consumer", "import.0.exporting_account_jwt", "nsc_account.provider", "jwt"),
+			},
+		},
+	})
+}
+
+// testAccAccountResourceConfigWithExportingAccount renders an exporting and an
+// importing account. The import references the JWT of the exporting account
+// only when importType is set, so that the JWT is known when it is checked.
+func testAccAccountResourceConfigWithExportingAccount(importType string) string {
+	imp := `
+  import {
+    subject = "shared.events.orders"
+    account = nsc_account.provider.public_key
+    type    = "stream"
+  }
+`
+	if importType != "" {
+		imp = fmt.Sprintf(`
+  import {
+    subject               = "shared.events.orders"
+    account               = nsc_account.provider.public_key
+    %s
+    exporting_account_jwt = nsc_account.provider.jwt
+  }
+`, importType)
+	}
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "provider_account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "consumer_account" {
+  type = "account"
+}
+
+resource "nsc_account" "provider" {
+  name        = "ProviderAccount"
+  subject     = nsc_nkey.provider_account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+
+  export {
+    subject = "shared.events.>"
+    type    = "stream"
+  }
+}
+
+resource "nsc_account" "consumer" {
+  name        = "ConsumerAccount"
+  subject     = nsc_nkey.consumer_account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+%s}
+`, imp)
+}
+
+func TestValidateImportToken(t *testing.T) {
+	exporterKP, _ := nkeys.CreateAccount()
+	exporterPubKey, _ := exporterKP.PublicKey()
+	signingKP, _ := nkeys.CreateAccount()
+	importerKP, _ := nkeys.CreateAccount()
+	importerPubKey, _ := importerKP.PublicKey()
+	otherKP, _ := nkeys.CreateAccount()
+	otherPubKey, _ := otherKP.PublicKey()
+
+	activation := func(issuer nkeys.KeyPair, issuerAccount, subject string, importType jwt.ExportType, importSubject string) string {
+		claims := jwt.NewActivationClaims(subject)
+		claims.IssuerAccount = issuerAccount
+		claims.ImportType = importType
+		claims.ImportSubject = jwt.Subject(importSubject)
+		token, err := claims.Encode(issuer)
+		if err != nil {
+			t.Fatalf("failed to encode activation: %v", err)
+		}
+		return token
+	}
+	imp := func(token types.String, subject, importType string) ImportModel {
+		return ImportModel{
+			Subject: types.StringValue(subject),
+			Account: types.StringValue(exporterPubKey),
+			Token:   token,
+			Type:    types.StringValue(importType),
+		}
+	}
+
+	tests := map[string]struct {
+		imp           ImportModel
+		expectedError string
+	}{
+		"matching": {
+			imp: imp(types.StringValue(activation(exporterKP, "", importerPubKey, jwt.Stream, "private.>")), "private.orders", "stream"),
+		},
+		"signing key": {
+			imp: imp(types.StringValue(activation(signingKP, exporterPubKey, importerPubKey, jwt.Service, "svc.>")), "svc.>", "service"),
+		},
+		"unknown token": {
+			imp: imp(types.StringUnknown(), "private.>", "stream"),
+		},
+		"invalid token": {
+			imp:           imp(types.StringValue("not-a-jwt"), "private.>", "stream"),
+			expectedError: "failed to decode",
+		},
+		"other exporter": {
+			imp:           imp(types.StringValue(activation(otherKP, "", importerPubKey, jwt.Stream, "private.>")), "private.>", "stream"),
+			expectedError: "not by the exporting account",
+		},
+		"other importer": {
+			imp:           imp(types.StringValue(activation(exporterKP, "", otherPubKey, jwt.Stream, "private.>")), "private.>", "stream"),
+			expectedError: "not to this account",
+		},
+		"type mismatch": {
+			imp:           imp(types.StringValue(activation(exporterKP, "", importerPubKey, jwt.Service, "private.>")), "private.>", "stream"),
+			expectedError: "import type is stream",
+		},
+		"subject not covered": {
+			imp:           imp(types.StringValue(activation(exporterKP, "", importerPubKey, jwt.Stream, "private.orders")), "private.>", "stream"),
+			expectedError: "does not cover",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateImportToken(tc.imp, types.StringValue(importerPubKey))
+			switch {
+			case tc.expectedError == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.expectedError != "" && err == nil:
+				t.Errorf("expected error containing %q", tc.expectedError)
+			case tc.expectedError != "" && !strings.Contains(err.Error(), tc.expectedError):
+				t.Errorf("expected error containing %q, got: %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestAccAccountResource_maxExportsExceeded(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_exports = 1
+
+  export {
+    subject = "events.>"
+    type    = "stream"
+  }
+
+  export {
+    subject = "api.requests"
+    type    = "service"
+  }
+`),
+				ExpectError: regexp.MustCompile("The account has 2 export blocks but max_exports is 1"),
+			},
+		},
+	})
+}
+
+func TestValidateImportTo(t *testing.T) {
+	imp := func(to, importType types.String) ImportModel {
+		return ImportModel{Subject: types.StringValue("shared.>"), To: to, Type: importType}
+	}
+
+	if err := validateImportTo(imp(types.StringValue("local.>"), types.StringValue("stream"))); err != nil {
+		t.Errorf("unexpected error for stream import: %v", err)
+	}
+	if err := validateImportTo(imp(types.StringNull(), types.StringValue("service"))); err != nil {
+		t.Errorf("unexpected error for service import without to: %v", err)
+	}
+	if err := validateImportTo(imp(types.StringValue("local.>"), types.StringUnknown())); err != nil {
+		t.Errorf("unexpected error for unknown type: %v", err)
+	}
+	err := validateImportTo(imp(types.StringValue("local.>"), types.StringValue("service")))
+	if err == nil || !strings.Contains(err.Error(), "only supported on stream imports") {
+		t.Errorf("expected stream-only error, got %v", err)
+	}
+}
+
+func TestValidateAllowTrace(t *testing.T) {
+	export := func(exportType string) ExportModel {
+		return ExportModel{Subject: types.StringValue("svc.>"), Type: types.StringValue(exportType), AllowTrace: types.BoolValue(true)}
+	}
+	imp := func(importType string) ImportModel {
+		return ImportModel{Subject: types.StringValue("events.>"), Type: types.StringValue(importType), AllowTrace: types.BoolValue(true)}
+	}
+
+	if err := validateExportAllowTrace(export("service")); err != nil {
+		t.Errorf("unexpected error for service export: %v", err)
+	}
+	if err := validateExportAllowTrace(export("stream")); err == nil {
+		t.Errorf("expected error for stream export")
+	}
+	if err := validateImportAllowTrace(imp("stream")); err != nil {
+		t.Errorf("unexpected error for stream import: %v", err)
+	}
+	if err := validateImportAllowTrace(imp("service")); err == nil {
+		t.Errorf("expected error for service import")
+	}
+	if err := validateImportAllowTrace(ImportModel{Type: types.StringValue("service"), AllowTrace: types.BoolValue(false)}); err != nil {
+		t.Errorf("unexpected error for allow_trace = false: %v", err)
+	}
+}
+
+func TestValidateExportAccountTokenPosition(t *testing.T) {
+	export := func(subject string, position types.Int64) ExportModel {
+		return ExportModel{Subject: types.StringValue(subject), Type: types.StringValue("service"), AccountTokenPosition: position}
+	}
+
+	tests := []struct {
+		name    string
+		export  ExportModel
+		wantErr string
+	}{
+		{name: "not set", export: export("api.>", types.Int64Null())},
+		{name: "wildcard", export: export("api.*.orders", types.Int64Value(2))},
+		{name: "last token", export: export("api.orders.*", types.Int64Value(3))},
+		{name: "unknown position", export: export("api.orders", types.Int64Unknown())},
+		{name: "unknown subject", export: ExportModel{Subject: types.StringUnknown(), AccountTokenPosition: types.Int64Value(5)}},
+		{name: "literal token", export: export("api.*.orders", types.Int64Value(3)), wantErr: `points at "orders"`},
+		{name: "full wildcard", export: export("api.>", types.Int64Value(2)), wantErr: `points at ">"`},
+		{name: "too few tokens", export: export("api.*", types.Int64Value(3)), wantErr: "exceeds the 2 tokens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateExportAccountTokenPosition(tt.export)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestAccAccountResource_accountTokenPosition(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject                = "tenant.*.events"
+    type                   = "stream"
+    account_token_position = 3
+  }
+`),
+				ExpectError: regexp.MustCompile(`must point at a \* wildcard token`),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject                = "tenant.*.events"
+    type                   = "stream"
+    account_token_position = 0
+  }
+`),
+				ExpectError: regexp.MustCompile(`must be at least 1`),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject                = "tenant.*.events"
+    type                   = "stream"
+    account_token_position = 2
+  }
+`),
+				Check: testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+					if claims.Exports[0].AccountTokenPosition != 2 {
+						return fmt.Errorf("expected account_token_position 2, got %d", claims.Exports[0].AccountTokenPosition)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestExportResponseType(t *testing.T) {
+	for value, want := range map[string]jwt.ResponseType{
+		"Singleton": jwt.ResponseTypeSingleton,
+		"stream":    jwt.ResponseTypeStream,
+		"CHUNKED":   jwt.ResponseTypeChunked,
+	} {
+		if got := exportResponseType(value); got != want {
+			t.Errorf("exportResponseType(%q): expected %s, got %s", value, want, got)
+		}
+	}
+
+	export := func(exportType string) ExportModel {
+		return ExportModel{Subject: types.StringValue("svc.>"), Type: types.StringValue(exportType), ResponseType: types.StringValue("Stream")}
+	}
+	if err := validateExportResponseType(export("service")); err != nil {
+		t.Errorf("unexpected error for service export: %v", err)
+	}
+	if err := validateExportResponseType(export("stream")); err == nil || !strings.Contains(err.Error(), "only supported on service exports") {
+		t.Errorf("expected service-only error for stream export, got %v", err)
+	}
+}
+
+func TestAccAccountResource_responseType(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject       = "events.>"
+    type          = "stream"
+    response_type = "Stream"
+  }
+`),
+				ExpectError: regexp.MustCompile(`only supported on service exports`),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject       = "svc.>"
+    type          = "service"
+    response_type = "Multiple"
+  }
+`),
+				ExpectError: regexp.MustCompile(`value must be one of`),
+			},
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject       = "svc.>"
+    type          = "service"
+    response_type = "stream"
+  }
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "export.0.response_type", "stream"),
+					testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+						if claims.Exports[0].ResponseType != jwt.ResponseTypeStream {
+							return fmt.Errorf("expected response type Stream, got %q", claims.Exports[0].ResponseType)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_allowTrace(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject     = "events.>"
+    type        = "stream"
+    allow_trace = true
+  }
+`),
+				ExpectError: regexp.MustCompile(`only supported on service exports`),
+			},
+			{
+				Config: testAccAccountResourceConfigWithImport(`
+    subject     = "shared.rpc"
+    type        = "service"
+    allow_trace = true
+`),
+				ExpectError: regexp.MustCompile(`only supported on stream imports`),
+			},
+			{
+				Config: testAccAccountResourceConfigWithImport(`
+    subject     = "shared.events.>"
+    type        = "stream"
+    allow_trace = true
+`),
+				Check: testAccCheckAccountClaims("nsc_account.consumer", func(claims *jwt.AccountClaims) error {
+					if !claims.Imports[0].AllowTrace {
+						return fmt.Errorf("expected allow_trace on the stream import")
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestValidateExportSubject(t *testing.T) {
+	export := func(subject, exportType string) ExportModel {
+		return ExportModel{Subject: types.StringValue(subject), Type: types.StringValue(exportType)}
+	}
+
+	tests := []struct {
+		name    string
+		exports []ExportModel
+		wantErr []bool
+	}{
+		{
+			name:    "disjoint",
+			exports: []ExportModel{export("api.orders.*", "service"), export("api.users.*", "service")},
+			wantErr: []bool{false, false},
+		},
+		{
+			name:    "contained",
+			exports: []ExportModel{export("api.>", "service"), export("api.orders.*", "service")},
+			wantErr: []bool{false, true},
+		},
+		{
+			name:    "duplicate",
+			exports: []ExportModel{export("events.>", "stream"), export("events.>", "stream")},
+			wantErr: []bool{true, true},
+		},
+		{
+			name:    "different types",
+			exports: []ExportModel{export("api.>", "service"), export("api.orders.*", "stream")},
+			wantErr: []bool{false, false},
+		},
+		{
+			name:    "unknown subject",
+			exports: []ExportModel{export("api.>", "service"), {Subject: types.StringUnknown(), Type: types.StringValue("service")}},
+			wantErr: []bool{false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.exports {
+				err := validateExportSubject(tt.exports, i)
+				if (err != nil) != tt.wantErr[i] {
+					t.Errorf("export %d: expected error %v, got %v", i, tt.wantErr[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestAccAccountResource_overlappingExports(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject = "api.>"
+    type    = "service"
+  }
+
+  export {
+    subject = "api.orders.*"
+    type    = "service"
+  }
+`),
+				ExpectError: regexp.MustCompile(`exports of the same type must not overlap`),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_invalidClaims(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  export {
+    subject = "api..orders"
+    type    = "service"
+  }
+`),
+				ExpectError: regexp.MustCompile(`Invalid account claims(.|\n)*cannot contain consecutive`),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_maxImportsExceeded(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  max_imports = 0
+
+  import {
+    subject = "shared.events.>"
+    account = "ADLGEVANYDKDQ6WYXPNBEGVUURXZY4LLLK5BJPOUDN6NGNXLNH4ATPWR"
+    type    = "stream"
+  }
+`),
+				ExpectError: regexp.MustCompile("The account has 1 import blocks but max_imports is 0"),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_operatorJWTIssuer(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Signed with a different operator
+			{
+				Config:      testAccAccountResourceConfigWithOperatorJWT("nsc_nkey.other.seed"),
+				ExpectError: regexp.MustCompile("neither operator"),
+			},
+			{
+				Config: testAccAccountResourceConfigWithOperatorJWT("nsc_nkey.signing.seed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("nsc_account.test", "jwt"),
+				),
+			},
+		},
+	})
+}
+
+func testAccAccountResourceConfigWithOperatorJWT(issuerSeed string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "signing" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "other" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_operator" "test" {
+  name         = "TestOperator"
+  subject      = nsc_nkey.operator.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = [nsc_nkey.signing.public_key]
+}
+
+resource "nsc_account" "test" {
+  name         = "TestAccount"
+  subject      = nsc_nkey.account.public_key
+  issuer_seed  = %s
+  operator_jwt = nsc_operator.test.jwt
+}
+`, issuerSeed)
+}
+
+func TestAccountClaimsModel_validateIssuer(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	signingKP, _ := nkeys.CreateOperator()
+	signingPubKey, _ := signingKP.PublicKey()
+	otherKP, _ := nkeys.CreateOperator()
+	otherPubKey, _ := otherKP.PublicKey()
+
+	operatorClaims := jwt.NewOperatorClaims(operatorPubKey)
+	operatorClaims.SigningKeys.Add(signingPubKey)
+	operatorJWT, _ := operatorClaims.Encode(operatorKP)
+	operatorClaims.StrictSigningKeyUsage = true
+	strictOperatorJWT, _ := operatorClaims.Encode(operatorKP)
+
+	tests := map[string]struct {
+		operatorJWT   types.String
+		issuer        string
+		expectedError string
+	}{
+		"no operator JWT": {
+			operatorJWT: types.StringNull(),
+			issuer:      otherPubKey,
+		},
+		"operator key": {
+			operatorJWT: types.StringValue(operatorJWT),
+			issuer:      operatorPubKey,
+		},
+		"signing key": {
+			operatorJWT: types.StringValue(operatorJWT),
+			issuer:      signingPubKey,
+		},
+		"other operator": {
+			operatorJWT:   types.StringValue(operatorJWT),
+			issuer:        otherPubKey,
+			expectedError: "neither operator",
+		},
+		"strict operator key": {
+			operatorJWT:   types.StringValue(strictOperatorJWT),
+			issuer:        operatorPubKey,
+			expectedError: "strict_signing_key_usage",
+		},
+		"strict signing key": {
+			operatorJWT: types.StringValue(strictOperatorJWT),
+			issuer:      signingPubKey,
+		},
+		"invalid operator JWT": {
+			operatorJWT:   types.StringValue("not-a-jwt"),
+			issuer:        operatorPubKey,
+			expectedError: "Failed to decode operator JWT",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			diags := AccountClaimsModel{OperatorJWT: tc.operatorJWT}.validateIssuer(tc.issuer)
+			switch {
+			case tc.expectedError == "" && diags.HasError():
+				t.Errorf("unexpected error: %v", diags)
+			case tc.expectedError != "" && !diags.HasError():
+				t.Errorf("expected error containing %q", tc.expectedError)
+			case tc.expectedError != "" && !strings.Contains(diags[0].Detail(), tc.expectedError):
+				t.Errorf("expected error containing %q, got: %v", tc.expectedError, diags)
+			}
+		})
+	}
+}
+
+func TestAccountResource_validateConfigEncryptedIssuerSeed(t *testing.T) {
+	ctx := context.Background()
+
+	cipher, err := newStateCipher(testStateEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := newKeypairCache()
+	keys.cipher = cipher
+	r := &AccountResource{keys: keys}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	operatorJWT, _ := jwt.NewOperatorClaims(operatorPubKey).Encode(operatorKP)
+	otherKP, _ := nkeys.CreateOperator()
+	otherSeed, _ := otherKP.Seed()
+	encryptedSeed, err := cipher.encrypt(string(otherSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := testRawValue(t, schemaResp.Schema, fmt.Sprintf(`{
+		"name": "TestAccount",
+		"subject": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+		"issuer_seed": %q,
+		"operator_jwt": %q
+	}`, encryptedSeed, operatorJWT))
+
+	resp := fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config},
+	}, &resp)
+	if !resp.Diagnostics.HasError() || !strings.Contains(fmt.Sprint(resp.Diagnostics), "neither operator") {
+		t.Errorf("expected issuer mismatch error, got %v", resp.Diagnostics)
+	}
+}
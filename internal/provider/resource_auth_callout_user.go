@@ -0,0 +1,379 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ resource.Resource = &AuthCalloutUserResource{}
+var _ resource.ResourceWithConfigure = &AuthCalloutUserResource{}
+var _ resource.ResourceWithModifyPlan = &AuthCalloutUserResource{}
+
+// authCalloutSubject is the subject the server sends auth callout requests
+// to, in the account of the callout service.
+const authCalloutSubject = "$SYS.REQ.USER.AUTH"
+
+// defaultAuthCalloutMaxSubscriptions leaves room for a few queue
+// subscriptions of the service.
+const defaultAuthCalloutMaxSubscriptions = 10
+
+func NewAuthCalloutUserResource() resource.Resource {
+	return &AuthCalloutUserResource{}
+}
+
+// AuthCalloutUserResource creates the user an auth callout service connects
+// as. The user key is generated once and kept in state; the JWT is reissued
+// when the attributes change.
+type AuthCalloutUserResource struct {
+	keys          *keypairCache
+	cipher        *stateCipher
+	audit         *auditLog
+	requireExpiry []string
+	policy        claimsPolicy
+}
+
+type AuthCalloutUserResourceModel struct {
+	ID               types.String         `tfsdk:"id"`
+	Name             types.String         `tfsdk:"name"`
+	IssuerSeed       types.String         `tfsdk:"issuer_seed"`
+	IssuerSeedEnv    types.String         `tfsdk:"issuer_seed_env"`
+	IssuerAccount    types.String         `tfsdk:"issuer_account"`
+	MaxSubscriptions Limit                `tfsdk:"max_subscriptions"`
+	ResponseTTL      timetypes.GoDuration `tfsdk:"response_ttl"`
+
+	PublicKey types.String      `tfsdk:"public_key"`
+	Seed      types.String      `tfsdk:"seed"`
+	JWT       types.String      `tfsdk:"jwt"`
+	Creds     types.String      `tfsdk:"creds"`
+	IssuedAt  timetypes.RFC3339 `tfsdk:"issued_at"`
+	JWTID     types.String      `tfsdk:"jwt_id"`
+}
+
+func (r *AuthCalloutUserResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_auth_callout_user"
+}
+
+func (r *AuthCalloutUserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	// Keys are generated on create and never change afterwards
+	key := func(description string, sensitive bool) schema.StringAttribute {
+		return schema.StringAttribute{
+			Computed:            true,
+			Sensitive:           sensitive,
+			MarkdownDescription: description,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		}
+	}
+	computed := func(description string, sensitive bool) schema.StringAttribute {
+		return schema.StringAttribute{
+			Computed:            true,
+			Sensitive:           sensitive,
+			MarkdownDescription: description,
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Creates the user an auth callout service connects as, with the permissions the service needs: it may subscribe to `$SYS.REQ.USER.AUTH` and publish only the single response to each callout request. " +
+			"The user key is generated on create and kept in state, like with `nsc_nkey`, with the seed encrypted when the provider has a `state_encryption_key`. " +
+			"Pair it with the `auth_callout` block of the `nsc_account` issuing it, e.g. `auth_callout { auth_users = [nsc_auth_callout_user.auth.public_key] }`, so the service itself bypasses the callout.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "User public key (same as public_key)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "User name",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"issuer_seed": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				MarkdownDescription: "Account seed for signing the user JWT (issuer). Never stored in state. Conflicts with `issuer_seed_env`; one of the two must be set.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("issuer_seed_env")),
+					stringvalidator.AtLeastOneOf(path.MatchRoot("issuer_seed_env")),
+				},
+			},
+			"issuer_seed_env": issuerSeedEnvAttribute(
+				"Name of the environment variable holding the account (or account signing) seed, e.g. `NATS_ACCOUNT_SEED`. Only the name is stored in state. Alternative to `issuer_seed`.",
+			),
+			"issuer_account": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Account public key when the issuer seed is an account signing key. Defaults to the public key of the issuer seed.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^A[A-Z2-7]{55}$`),
+						"must be a valid account public key starting with 'A'",
+					),
+				},
+			},
+			"max_subscriptions": schema.StringAttribute{
+				CustomType:          LimitType{},
+				Optional:            true,
+				MarkdownDescription: fmt.Sprintf("Maximum number of subscriptions (-1 or `unlimited` for unlimited). Defaults to `%d`.", defaultAuthCalloutMaxSubscriptions),
+				Validators: []validator.String{
+					limitAtLeast(-1),
+				},
+			},
+			"response_ttl": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Time limit for the response to a callout request. Defaults to no limit.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+
+			"public_key": key("User public key", false),
+			"seed":       key("User seed", true),
+			"jwt":        computed("User JWT", false),
+			"creds":      computed("User credentials file content, for the callout service to connect with", true),
+			"issued_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Computed:            true,
+				MarkdownDescription: "Time the JWT was issued (`iat`), in RFC 3339 format",
+			},
+			"jwt_id": computed("ID of the JWT (`jti`)", false),
+		},
+	}
+}
+
+func (r *AuthCalloutUserResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.keys = providerData.Keys
+	r.cipher = providerData.StateCipher
+	r.audit = providerData.Audit
+	r.requireExpiry = providerData.RequireExpiry
+	r.policy = providerData.Policy
+}
+
+// ModifyPlan marks the JWT outputs unknown whenever they are reissued, so
+// resources referencing them plan their own updates in the same run.
+func (r *AuthCalloutUserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	resp.Diagnostics.Append(requireExpiryUnsupported(req, r.requireExpiry, "nsc_auth_callout_user", "user")...)
+	if !jwtPlanned(req) {
+		return
+	}
+
+	for _, name := range []string{"jwt", "creds", "jwt_id"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("issued_at"), timetypes.NewRFC3339Unknown())...)
+}
+
+func (r *AuthCalloutUserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data, config AuthCalloutUserResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kp, err := nkeys.CreateUser()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to generate key pair", err.Error())
+		return
+	}
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to get public key", err.Error())
+		return
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to get seed", err.Error())
+		return
+	}
+	stateSeed, err := r.cipher.encrypt(string(seed))
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encrypt seed", err.Error())
+		return
+	}
+	data.ID = types.StringValue(publicKey)
+	data.PublicKey = types.StringValue(publicKey)
+	data.Seed = types.StringValue(stateSeed)
+
+	resp.Diagnostics.Append(r.issue(ctx, &data, config.IssuerSeed, "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "created auth callout user resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AuthCalloutUserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AuthCalloutUserResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// For state-only storage, nothing to read externally
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AuthCalloutUserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state, config AuthCalloutUserResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the key of the state
+	data.ID, data.PublicKey, data.Seed = state.ID, state.PublicKey, state.Seed
+
+	resp.Diagnostics.Append(r.issue(ctx, &data, config.IssuerSeed, "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "updated auth callout user resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AuthCalloutUserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AuthCalloutUserResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted auth callout user resource")
+}
+
+// issue signs the user JWT with the issuer seed, which is write-only and so
+// comes from the configuration, and sets the JWT outputs.
+func (r *AuthCalloutUserResource) issue(ctx context.Context, data *AuthCalloutUserResourceModel, configSeed types.String, action string) diag.Diagnostics {
+	issuerSeed, diags := issuerSeedValue(configSeed, data.IssuerSeedEnv)
+	if diags.HasError() {
+		return diags
+	}
+	issuerKP, err := r.keys.fromSeed(issuerSeed.ValueString())
+	if err != nil {
+		diags.AddError("Invalid issuer seed", err.Error())
+		return diags
+	}
+	issuerPubKey, err := issuerKP.PublicKey()
+	if err != nil {
+		diags.AddError("Failed to get public key from issuer", err.Error())
+		return diags
+	}
+	if !nkeys.IsValidPublicAccountKey(issuerPubKey) {
+		diags.AddError("Invalid issuer seed", "The issuer seed must be an account or account signing key seed")
+		return diags
+	}
+	if data.IssuerAccount.IsNull() || data.IssuerAccount.IsUnknown() {
+		data.IssuerAccount = types.StringValue(issuerPubKey)
+	}
+
+	claims, d := buildAuthCalloutUserClaims(data, issuerPubKey)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+	userJWT, err := encodeClaims(ctx, claims, issuerKP, nil, types.StringNull(), types.StringNull(), 0)
+	if err != nil {
+		diags.AddError("Failed to encode user JWT", err.Error())
+		return diags
+	}
+	diags.Append(r.policy.checkToken(userJWT, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	userSeed, err := r.keys.seed(data.Seed.ValueString())
+	if err != nil {
+		diags.AddError("Invalid user seed", err.Error())
+		return diags
+	}
+
+	data.JWT = types.StringValue(userJWT)
+	data.Creds = types.StringValue(formatCreds(userJWT, userSeed))
+	data.IssuedAt, data.JWTID, err = jwtIssueValues(userJWT)
+	if err != nil {
+		diags.AddError("Failed to decode user JWT", err.Error())
+		return diags
+	}
+
+	diags.Append(r.audit.record("nsc_auth_callout_user", action, userJWT)...)
+	return diags
+}
+
+// buildAuthCalloutUserClaims returns the claims of an auth callout service
+// user: it subscribes to the callout subject and may only publish responses,
+// one per request, through the response permission.
+func buildAuthCalloutUserClaims(data *AuthCalloutUserResourceModel, issuerPubKey string) (*jwt.UserClaims, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	claims := jwt.NewUserClaims(data.PublicKey.ValueString())
+	claims.Name = data.Name.ValueString()
+	if account := data.IssuerAccount.ValueString(); account != issuerPubKey {
+		claims.IssuerAccount = account
+	}
+
+	claims.Sub.Allow.Add(authCalloutSubject)
+	claims.Pub.Deny.Add(">")
+	claims.Resp = &jwt.ResponsePermission{MaxMsgs: 1}
+	if !data.ResponseTTL.IsNull() {
+		ttl, d := data.ResponseTTL.ValueGoDuration()
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		claims.Resp.Expires = ttl
+	}
+
+	claims.Limits.Subs = defaultAuthCalloutMaxSubscriptions
+	if !data.MaxSubscriptions.IsNull() {
+		claims.Limits.Subs = data.MaxSubscriptions.ValueInt64()
+	}
+
+	return claims, diags
+}
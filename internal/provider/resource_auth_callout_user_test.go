@@ -0,0 +1,188 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestBuildAuthCalloutUserClaims(t *testing.T) {
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	signingKP, _ := nkeys.CreateAccount()
+	signingPubKey, _ := signingKP.PublicKey()
+
+	data := AuthCalloutUserResourceModel{
+		Name:             types.StringValue("auth"),
+		IssuerAccount:    types.StringValue(accountPubKey),
+		PublicKey:        types.StringValue(userPubKey),
+		MaxSubscriptions: NewLimitNull(),
+		ResponseTTL:      timetypes.NewGoDurationNull(),
+	}
+
+	claims, diags := buildAuthCalloutUserClaims(&data, accountPubKey)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if claims.Subject != userPubKey || claims.Name != "auth" || claims.IssuerAccount != "" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if len(claims.Sub.Allow) != 1 || claims.Sub.Allow[0] != "$SYS.REQ.USER.AUTH" {
+		t.Errorf("expected to subscribe to the callout subject only, got %v", claims.Sub.Allow)
+	}
+	if len(claims.Pub.Allow) != 0 || len(claims.Pub.Deny) != 1 || claims.Pub.Deny[0] != ">" {
+		t.Errorf("expected all publishing denied, got allow %v, deny %v", claims.Pub.Allow, claims.Pub.Deny)
+	}
+	if claims.Resp == nil || claims.Resp.MaxMsgs != 1 || claims.Resp.Expires != 0 {
+		t.Errorf("expected a single response per request, got %+v", claims.Resp)
+	}
+	if claims.Limits.Subs != defaultAuthCalloutMaxSubscriptions {
+		t.Errorf("expected %d subscriptions, got %d", defaultAuthCalloutMaxSubscriptions, claims.Limits.Subs)
+	}
+	vr := jwt.CreateValidationResults()
+	claims.Validate(vr)
+	if vr.IsBlocking(true) {
+		t.Errorf("auth callout user claims are invalid: %v", vr.Errors())
+	}
+
+	// Signed with an account signing key
+	data.MaxSubscriptions = NewLimitValue("unlimited")
+	data.ResponseTTL = timetypes.NewGoDurationValueFromStringMust("2s")
+	claims, diags = buildAuthCalloutUserClaims(&data, signingPubKey)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if claims.IssuerAccount != accountPubKey {
+		t.Errorf("expected issuer account %s, got %q", accountPubKey, claims.IssuerAccount)
+	}
+	if claims.Resp.Expires != 2*time.Second || claims.Limits.Subs != jwt.NoLimit {
+		t.Errorf("expected response TTL 2s and unlimited subscriptions, got %+v, %d", claims.Resp, claims.Limits.Subs)
+	}
+}
+
+func TestAuthCalloutModel_apply(t *testing.T) {
+	ctx := context.Background()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	xKP, _ := nkeys.CreateCurveKeys()
+	xPubKey, _ := xKP.PublicKey()
+
+	var authorization jwt.ExternalAuthorization
+	diags := (&AuthCalloutModel{
+		AuthUsers:       types.ListValueMust(types.StringType, []attr.Value{types.StringValue(userPubKey)}),
+		AllowedAccounts: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("*")}),
+		XKey:            types.StringValue(xPubKey),
+	}).apply(ctx, &authorization)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if !authorization.IsEnabled() || authorization.AuthUsers[0] != userPubKey ||
+		len(authorization.AllowedAccounts) != 1 || authorization.AllowedAccounts[0] != "*" || authorization.XKey != xPubKey {
+		t.Errorf("unexpected authorization: %+v", authorization)
+	}
+
+	diags = (&AuthCalloutModel{
+		AuthUsers:       types.ListNull(types.StringType),
+		AllowedAccounts: types.ListNull(types.StringType),
+		XKey:            types.StringNull(),
+	}).apply(ctx, &jwt.ExternalAuthorization{})
+	if !diags.HasError() || diags[0].Summary() != "Missing auth callout users" {
+		t.Errorf("expected missing users error, got %v", diags)
+	}
+}
+
+func TestAccAuthCalloutUserResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAuthCalloutUserResourceConfig(`max_subscriptions = 5`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("nsc_auth_callout_user.test", "id", "nsc_auth_callout_user.test", "public_key"),
+					resource.TestCheckResourceAttrPair("nsc_auth_callout_user.test", "issuer_account", "nsc_nkey.account", "public_key"),
+					testAccCheckUserCredsFormat("nsc_auth_callout_user.test", "creds"),
+					testAccCheckUserClaims("nsc_auth_callout_user.test", func(claims *jwt.UserClaims) error {
+						if claims.Limits.Subs != 5 || claims.Resp == nil || !claims.Sub.Allow.Contains("$SYS.REQ.USER.AUTH") {
+							return fmt.Errorf("unexpected auth callout user claims: %+v", claims)
+						}
+						return nil
+					}),
+					testAccCheckAccountClaims("nsc_account.test", func(claims *jwt.AccountClaims) error {
+						if !claims.Authorization.IsEnabled() {
+							return fmt.Errorf("expected auth callout to be enabled")
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: testAccAuthCalloutUserResourceConfig(`response_ttl = "5s"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckUserClaims("nsc_auth_callout_user.test", func(claims *jwt.UserClaims) error {
+						if claims.Resp == nil || claims.Resp.Expires != 5*time.Second {
+							return fmt.Errorf("expected response TTL 5s, got %+v", claims.Resp)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccAccountResource_authCalloutInvalidUser(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountResourceConfigWithValidity(`
+  auth_callout {
+    auth_users = ["ADLGEVANYDKDQ6WYXPNBEGVUURXZY4LLLK5BJPOUDN6NGNXLNH4ATPWR"]
+  }
+`),
+				ExpectError: regexp.MustCompile("not a valid user public key"),
+			},
+		},
+	})
+}
+
+func testAccAuthCalloutUserResourceConfig(extra string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+
+  auth_callout {
+    auth_users = [nsc_auth_callout_user.test.public_key]
+  }
+}
+
+resource "nsc_auth_callout_user" "test" {
+  name        = "auth"
+  issuer_seed = nsc_nkey.account.seed
+  %s
+}
+`, extra)
+}
@@ -0,0 +1,400 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &BundleFilesResource{}
+
+func NewBundleFilesResource() resource.Resource {
+	return &BundleFilesResource{}
+}
+
+// BundleFilesResource writes the JWTs and creds of a trust tree to a
+// directory, for hand-off to operations teams and air-gapped installations.
+type BundleFilesResource struct{}
+
+type BundleFilesResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Directory     types.String `tfsdk:"directory"`
+	FileMode      types.String `tfsdk:"file_mode"`
+	DirectoryMode types.String `tfsdk:"directory_mode"`
+	OperatorJWT   types.String `tfsdk:"operator_jwt"`
+	Accounts      types.Map    `tfsdk:"accounts"`
+	Users         types.Map    `tfsdk:"users"`
+	Files         types.Map    `tfsdk:"files"`
+	Manifest      types.String `tfsdk:"manifest"`
+}
+
+// bundleNameValidators validate account and user names used as file names.
+var bundleNameValidators = []validator.String{
+	stringvalidator.RegexMatches(regexp.MustCompile(`^[^/\\]+$`), "must not contain path separators"),
+	stringvalidator.NoneOf(".", ".."),
+}
+
+// fileModeValidator validates an octal file mode such as 0600.
+var fileModeValidator = stringvalidator.RegexMatches(regexp.MustCompile(`^0?[0-7]{3}$`), "must be an octal file mode such as 0600")
+
+func (r *BundleFilesResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bundle_files"
+}
+
+func (r *BundleFilesResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Writes a trust tree to a directory as `operator.jwt`, `accounts/<name>.jwt` and `users/<account>/<name>.creds`, for hand-off to operations teams and air-gapped installations. " +
+			"Files that go missing, are modified or change mode outside Terraform are written again on the next apply. Destroying the resource removes the files it wrote.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Directory the files are written to (same as directory)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"directory": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Directory to write the files to. Created when it does not exist.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"file_mode": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("0600"),
+				MarkdownDescription: "Octal mode of the files. Defaults to `0600`.",
+				Validators: []validator.String{
+					fileModeValidator,
+				},
+			},
+			"directory_mode": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("0700"),
+				MarkdownDescription: "Octal mode of `directory` and the directories within it. Defaults to `0700`.",
+				Validators: []validator.String{
+					fileModeValidator,
+				},
+			},
+			"operator_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Operator JWT written to `operator.jwt`, e.g. `nsc_operator.main.jwt`",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.AtLeastOneOf(path.MatchRoot("accounts"), path.MatchRoot("users")),
+				},
+			},
+			"accounts": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Map of account names to account JWTs, each written to `accounts/<name>.jwt`",
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(bundleNameValidators...),
+				},
+			},
+			"users": schema.MapAttribute{
+				ElementType:         types.MapType{ElemType: types.StringType},
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Map of account names to maps of user names to creds, each written to `users/<account>/<name>.creds`, e.g. `{ APP = nsc_users.app.creds }`",
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(bundleNameValidators...),
+					mapvalidator.ValueMapsAre(mapvalidator.KeysAre(bundleNameValidators...)),
+				},
+			},
+			"files": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Map of the paths of the files relative to directory to their full paths",
+			},
+			"manifest": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "JSON manifest of the files with their SHA-256, claim type, subject and expiry, for deployment pipelines to verify them",
+			},
+		},
+	}
+}
+
+func (r *BundleFilesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data BundleFilesResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files, manifest, diags := writeBundleFiles(ctx, data, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.Directory
+	data.Files = files
+	data.Manifest = manifest
+
+	tflog.Trace(ctx, "created bundle files resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BundleFilesResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data BundleFilesResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files, mode, _, diags := bundleFiles(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A missing or modified file makes Terraform write all files again
+	entries := make([]artifactManifestEntry, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file.path)
+		if err != nil || info.Mode().Perm() != mode {
+			tflog.Debug(ctx, "bundle file missing or mode changed, removing from state", map[string]any{"path": file.path})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		content, err := os.ReadFile(file.path)
+		if err != nil || !bytes.Equal(content, file.content) {
+			tflog.Debug(ctx, "bundle file modified, removing from state", map[string]any{"path": file.path})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		entries = append(entries, newArtifactManifestEntry(file.name, file.path, file.content))
+	}
+
+	manifest, err := renderArtifactManifest(entries)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to render manifest", err.Error())
+		return
+	}
+	data.Manifest = types.StringValue(manifest)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BundleFilesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state BundleFilesResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous := make(map[string]string, len(state.Files.Elements()))
+	resp.Diagnostics.Append(state.Files.ElementsAs(ctx, &previous, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files, manifest, diags := writeBundleFiles(ctx, data, previous)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.Directory
+	data.Files = files
+	data.Manifest = manifest
+
+	tflog.Trace(ctx, "updated bundle files resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *BundleFilesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data BundleFilesResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files := make(map[string]string, len(data.Files.Elements()))
+	resp.Diagnostics.Append(data.Files.ElementsAs(ctx, &files, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			resp.Diagnostics.AddError("Failed to remove bundle file", err.Error())
+		}
+	}
+	removeEmptyBundleDirs(data.Directory.ValueString(), files)
+
+	tflog.Trace(ctx, "deleted bundle files resource")
+}
+
+// bundleFile is the content of a file of the bundle and its path.
+type bundleFile struct {
+	name    string
+	path    string
+	content []byte
+}
+
+// bundleFiles returns the files of the bundle ordered by name, and the file
+// and directory modes.
+func bundleFiles(ctx context.Context, data BundleFilesResourceModel) ([]bundleFile, os.FileMode, os.FileMode, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	mode, err := parseFileMode(data.FileMode.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("file_mode"), "Invalid file mode", err.Error())
+	}
+	dirMode, err := parseFileMode(data.DirectoryMode.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("directory_mode"), "Invalid file mode", err.Error())
+	}
+
+	var accounts map[string]string
+	if !data.Accounts.IsNull() {
+		diags.Append(data.Accounts.ElementsAs(ctx, &accounts, false)...)
+	}
+	var users map[string]map[string]string
+	if !data.Users.IsNull() {
+		diags.Append(data.Users.ElementsAs(ctx, &users, false)...)
+	}
+	if diags.HasError() {
+		return nil, 0, 0, diags
+	}
+
+	var files []bundleFile
+	add := func(name, content string) {
+		files = append(files, bundleFile{
+			name:    name,
+			path:    filepath.Join(data.Directory.ValueString(), name),
+			content: []byte(content),
+		})
+	}
+
+	if !data.OperatorJWT.IsNull() {
+		add("operator.jwt", data.OperatorJWT.ValueString())
+	}
+	for name, token := range accounts {
+		add(filepath.Join("accounts", name+".jwt"), token)
+	}
+	for account, creds := range users {
+		for name, content := range creds {
+			add(filepath.Join("users", account, name+".creds"), content)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	return files, mode, dirMode, diags
+}
+
+// writeBundleFiles writes the files of the bundle and removes the previously
+// written files that are no longer part of it. It returns the files and
+// manifest attributes.
+func writeBundleFiles(ctx context.Context, data BundleFilesResourceModel, previous map[string]string) (types.Map, types.String, diag.Diagnostics) {
+	files, mode, dirMode, diags := bundleFiles(ctx, data)
+	if diags.HasError() {
+		return types.MapNull(types.StringType), types.StringNull(), diags
+	}
+
+	directory := data.Directory.ValueString()
+	paths := make(map[string]string, len(files))
+	entries := make([]artifactManifestEntry, 0, len(files))
+	dirs := map[string]bool{directory: true}
+	for _, file := range files {
+		if err := writeFileAtomicMode(file.path, file.content, mode, dirMode); err != nil {
+			diags.AddError("Failed to write bundle file", err.Error())
+			return types.MapNull(types.StringType), types.StringNull(), diags
+		}
+		paths[file.name] = file.path
+		entries = append(entries, newArtifactManifestEntry(file.name, file.path, file.content))
+		for dir := filepath.Dir(file.name); dir != "."; dir = filepath.Dir(dir) {
+			dirs[filepath.Join(directory, dir)] = true
+		}
+	}
+
+	// MkdirAll leaves the mode of existing directories as is and applies the
+	// umask to new ones
+	for dir := range dirs {
+		if err := os.Chmod(dir, dirMode); err != nil {
+			diags.AddError("Failed to set directory mode", err.Error())
+			return types.MapNull(types.StringType), types.StringNull(), diags
+		}
+	}
+
+	removed := make(map[string]string)
+	for name, file := range previous {
+		if paths[name] == file {
+			continue
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			diags.AddError("Failed to remove bundle file", err.Error())
+		}
+		removed[name] = file
+	}
+	removeEmptyBundleDirs(directory, removed)
+
+	manifest, err := renderArtifactManifest(entries)
+	if err != nil {
+		diags.AddError("Failed to render manifest", err.Error())
+		return types.MapNull(types.StringType), types.StringNull(), diags
+	}
+
+	filesValue, d := types.MapValueFrom(ctx, types.StringType, paths)
+	diags.Append(d...)
+	return filesValue, types.StringValue(manifest), diags
+}
+
+// removeEmptyBundleDirs removes the directories within directory that held
+// the removed files and are empty now. directory itself is kept.
+func removeEmptyBundleDirs(directory string, removed map[string]string) {
+	dirs := make(map[string]bool)
+	for name := range removed {
+		for dir := filepath.Dir(name); dir != "."; dir = filepath.Dir(dir) {
+			dirs[filepath.Join(directory, dir)] = true
+		}
+	}
+
+	// Deeper directories first; removing a non-empty directory fails
+	ordered := sortedKeys(dirs)
+	sort.Slice(ordered, func(i, j int) bool { return len(ordered[i]) > len(ordered[j]) })
+	for _, dir := range ordered {
+		os.Remove(dir)
+	}
+}
+
+// parseFileMode parses an octal file mode such as 0600.
+func parseFileMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("%q is not an octal file mode such as 0600", s)
+	}
+	return os.FileMode(mode), nil
+}
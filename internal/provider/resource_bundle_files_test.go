@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccBundleFilesResource(t *testing.T) {
+	dir := t.TempDir()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccBundleFilesResourceConfig(dir, "0640"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_bundle_files.test", "files.%", "4"),
+					resource.TestCheckResourceAttrWith("nsc_bundle_files.test", "manifest", func(value string) error {
+						var manifest artifactManifest
+						if err := json.Unmarshal([]byte(value), &manifest); err != nil {
+							return err
+						}
+						if len(manifest.Artifacts) != 4 || manifest.Artifacts[0].Type != "account" || manifest.Artifacts[3].Name != "users/APP/bob.creds" {
+							return fmt.Errorf("unexpected manifest: %s", value)
+						}
+						return nil
+					}),
+					testAccCheckBundleFile(dir, "operator.jwt", "nsc_operator.test", "jwt", 0o640),
+					testAccCheckBundleFile(dir, "accounts/APP.jwt", "nsc_account.app", "jwt", 0o640),
+					testAccCheckBundleFile(dir, "users/APP/alice.creds", "nsc_users.app", "creds.alice", 0o640),
+				),
+			},
+			{
+				// A modified file is written again
+				PreConfig: func() {
+					os.WriteFile(filepath.Join(dir, "accounts", "APP.jwt"), []byte("modified"), 0o640)
+				},
+				Config: testAccBundleFilesResourceConfig(dir, "0640"),
+				Check:  testAccCheckBundleFile(dir, "accounts/APP.jwt", "nsc_account.app", "jwt", 0o640),
+			},
+			{
+				Config: testAccBundleFilesResourceConfig(dir, "0600"),
+				Check:  testAccCheckBundleFile(dir, "users/APP/bob.creds", "nsc_users.app", "creds.bob", 0o600),
+			},
+		},
+		CheckDestroy: testAccCheckNKeyFilesRemoved(dir),
+	})
+}
+
+func testAccBundleFilesResourceConfig(dir, fileMode string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "app" {
+  type = "account"
+}
+
+resource "nsc_operator" "test" {
+  name        = "TestOperator"
+  subject     = nsc_nkey.operator.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_account" "app" {
+  name        = "APP"
+  subject     = nsc_nkey.app.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_users" "app" {
+  issuer_seed = nsc_nkey.app.seed
+
+  users = {
+    alice = {}
+    bob   = {}
+  }
+}
+
+resource "nsc_bundle_files" "test" {
+  directory    = %[1]q
+  file_mode    = %[2]q
+  operator_jwt = nsc_operator.test.jwt
+  accounts = {
+    (nsc_account.app.name) = nsc_account.app.jwt
+  }
+  users = {
+    (nsc_account.app.name) = nsc_users.app.creds
+  }
+}
+`, dir, fileMode)
+}
+
+// testAccCheckBundleFile checks that an attribute of a resource was written to
+// the file of the bundle with the given mode.
+func testAccCheckBundleFile(dir, name, resourceName, attribute string, mode os.FileMode) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+		file := filepath.Join(dir, name)
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm() != mode {
+			return fmt.Errorf("expected mode %o for %s, got %o", mode, file, info.Mode().Perm())
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if string(content) != rs.Primary.Attributes[attribute] {
+			return fmt.Errorf("unexpected content of %s", file)
+		}
+		return nil
+	}
+}
+
+func TestWriteBundleFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	data := BundleFilesResourceModel{
+		Directory:     types.StringValue(dir),
+		FileMode:      types.StringValue("0640"),
+		DirectoryMode: types.StringValue("0750"),
+		OperatorJWT:   types.StringValue("operator"),
+		Accounts:      types.MapValueMust(types.StringType, map[string]attr.Value{"APP": types.StringValue("app")}),
+		Users: types.MapValueMust(types.MapType{ElemType: types.StringType}, map[string]attr.Value{
+			"APP": types.MapValueMust(types.StringType, map[string]attr.Value{
+				"alice": types.StringValue("alice creds"),
+				"bob":   types.StringValue("bob creds"),
+			}),
+		}),
+	}
+
+	files, manifest, diags := writeBundleFiles(ctx, data, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	paths := make(map[string]string)
+	files.ElementsAs(ctx, &paths, false)
+	if len(paths) != 4 || paths[filepath.Join("users", "APP", "bob.creds")] != filepath.Join(dir, "users", "APP", "bob.creds") {
+		t.Fatalf("unexpected files: %v", paths)
+	}
+	for _, dir := range []string{dir, filepath.Join(dir, "users", "APP")} {
+		info, err := os.Stat(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o750 {
+			t.Errorf("expected directory mode 0750 for %s, got %o", dir, info.Mode().Perm())
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "accounts", "APP.jwt"))
+	if err != nil || string(content) != "app" {
+		t.Errorf("unexpected account file: %q, %v", content, err)
+	}
+	var m artifactManifest
+	if err := json.Unmarshal([]byte(manifest.ValueString()), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Artifacts) != 4 || m.Artifacts[0].Name != "accounts/APP.jwt" || m.Artifacts[0].SHA256 != fmt.Sprintf("%x", sha256.Sum256([]byte("app"))) {
+		t.Errorf("unexpected manifest: %s", manifest.ValueString())
+	}
+
+	// Files no longer part of the bundle are removed with their empty
+	// directories
+	data.Users = types.MapNull(types.MapType{ElemType: types.StringType})
+	if _, _, diags := writeBundleFiles(ctx, data, paths); diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "users")); !os.IsNotExist(err) {
+		t.Errorf("expected the users directory to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "operator.jwt")); err != nil {
+		t.Errorf("expected the operator file to be kept, got %v", err)
+	}
+}
+
+func TestParseFileMode(t *testing.T) {
+	for s, want := range map[string]os.FileMode{"0600": 0o600, "644": 0o644, "0755": 0o755} {
+		if got, err := parseFileMode(s); err != nil || got != want {
+			t.Errorf("%s: expected %o, got %o, %v", s, want, got, err)
+		}
+	}
+	for _, s := range []string{"", "0800", "rw", "01777"} {
+		if _, err := parseFileMode(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ resource.Resource = &JWTResignResource{}
+var _ resource.ResourceWithConfigure = &JWTResignResource{}
+var _ resource.ResourceWithIdentity = &JWTResignResource{}
+
+func NewJWTResignResource() resource.Resource {
+	return &JWTResignResource{}
+}
+
+// JWTResignResource re-signs the claims of an existing JWT with a new issuer.
+type JWTResignResource struct {
+	signer           externalSigner
+	keys             *keypairCache
+	warnExpiryWithin time.Duration
+	audit            *auditLog
+}
+
+type JWTResignResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	JWT             types.String `tfsdk:"jwt"`
+	IssuerSeed      types.String `tfsdk:"issuer_seed"`
+	IssuerSeedEnv   types.String `tfsdk:"issuer_seed_env"`
+	IssuerKeyName   types.String `tfsdk:"issuer_key_name"`
+	IssuerPublicKey types.String `tfsdk:"issuer_public_key"`
+	ClaimType       types.String `tfsdk:"claim_type"`
+	Subject         types.String `tfsdk:"subject"`
+	Issuer          types.String `tfsdk:"issuer"`
+	OriginalIssuer  types.String `tfsdk:"original_issuer"`
+	ResignedJWT     types.String `tfsdk:"resigned_jwt"`
+}
+
+func (r *JWTResignResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_jwt_resign"
+	// The identity follows the subject of the JWT, which can change in place
+	resp.ResourceBehavior.MutableIdentity = true
+}
+
+func (r *JWTResignResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Re-signs the claims of an existing JWT (operator, account, user, activation or generic) with a new issuer. " +
+			"The claims are kept as they are except for the issuer, the issue time and the JWT ID. The new issuer must be of the same key type as the original one.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Identifier (subject of the JWT)",
+			},
+			"jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "JWT whose claims are re-signed. Its signature must be valid.",
+			},
+			"issuer_seed": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				MarkdownDescription: "Seed of the new issuer. Never stored in state. Conflicts with `issuer_seed_env`, `issuer_key_name` and `issuer_public_key`; one of the four must be set.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("issuer_seed_env"), path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key")),
+					stringvalidator.AtLeastOneOf(path.MatchRoot("issuer_seed_env"), path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key")),
+				},
+			},
+			"issuer_seed_env": issuerSeedEnvAttribute(
+				"Name of the environment variable holding the seed of the new issuer. Read by the provider when the JWT is re-signed. Only the name is stored in state. Alternative to `issuer_seed`.",
+				path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key"),
+			),
+			"issuer_key_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the new issuer key held by the provider's external `signer`. Alternative to `issuer_seed`.",
+			},
+			"issuer_public_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the new issuer key held by the provider's external `signer`. Alternative to `issuer_seed`.",
+			},
+			"claim_type": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Claim type of the JWT (e.g. `account`, `user`)",
+			},
+			"subject": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Subject of the JWT",
+			},
+			"issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the new issuer",
+			},
+			"original_issuer": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the issuer of `jwt`",
+			},
+			"resigned_jwt": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Re-signed JWT",
+			},
+		},
+	}
+}
+
+func (r *JWTResignResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = publicKeyIdentitySchema("Subject of the re-signed JWT")
+}
+
+func (r *JWTResignResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.signer = providerData.Signer
+	r.keys = providerData.Keys
+	r.warnExpiryWithin = providerData.WarnExpiryWithin
+	r.audit = providerData.Audit
+}
+
+func (r *JWTResignResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data JWTResignResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get WriteOnly issuer_seed from Config
+	var config JWTResignResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	issuerSeed, diags := issuerSeedValue(config.IssuerSeed, data.IssuerSeedEnv)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.resign(ctx, &data, issuerSeed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.audit.record("nsc_jwt_resign", "create", data.ResignedJWT.ValueString())...)
+
+	tflog.Trace(ctx, "created jwt resign resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *JWTResignResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data JWTResignResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// For state-only storage, nothing to read externally
+	if r.warnExpiryWithin > 0 && !data.ResignedJWT.IsNull() {
+		claims, err := jwt.Decode(data.ResignedJWT.ValueString())
+		if err == nil && claims.Claims().Expires != 0 {
+			expiresAt := timetypes.NewRFC3339TimeValue(time.Unix(claims.Claims().Expires, 0))
+			resp.Diagnostics.Append(expiryWarning(data.ClaimType.ValueString(), claims.Claims().Name, data.Subject.ValueString(), expiresAt, r.warnExpiryWithin)...)
+		}
+	}
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *JWTResignResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data JWTResignResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get WriteOnly issuer_seed from Config
+	var config JWTResignResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	issuerSeed, diags := issuerSeedValue(config.IssuerSeed, data.IssuerSeedEnv)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.resign(ctx, &data, issuerSeed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.audit.record("nsc_jwt_resign", "update", data.ResignedJWT.ValueString())...)
+
+	tflog.Trace(ctx, "updated jwt resign resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *JWTResignResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data JWTResignResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted jwt resign resource")
+}
+
+// resign decodes the JWT, re-signs its claims with the new issuer and sets
+// the computed attributes.
+func (r *JWTResignResource) resign(ctx context.Context, data *JWTResignResourceModel, seed types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	claims, err := jwt.Decode(data.JWT.ValueString())
+	if err != nil {
+		diags.AddError("Failed to decode JWT", err.Error())
+		return diags
+	}
+
+	originalIssuer := claims.Claims().Issuer
+	issuerKP, signFn, d := resignIssuer(ctx, r.signer, r.keys, data.IssuerKeyName, data.IssuerPublicKey, seed, nkeys.Prefix(originalIssuer))
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	issuerPubKey, err := issuerKP.PublicKey()
+	if err != nil {
+		diags.AddError("Failed to get issuer public key", err.Error())
+		return diags
+	}
+
+	// User and activation JWTs issued by a signing key name the account they
+	// are issued for. Keep pointing at the original account.
+	switch c := claims.(type) {
+	case *jwt.UserClaims:
+		c.IssuerAccount = resignIssuerAccount(c.IssuerAccount, originalIssuer, issuerPubKey)
+	case *jwt.ActivationClaims:
+		c.IssuerAccount = resignIssuerAccount(c.IssuerAccount, originalIssuer, issuerPubKey)
+	}
+
+	token, err := claims.EncodeWithSigner(issuerKP, signFn)
+	if err != nil {
+		diags.AddError("Failed to encode JWT", err.Error())
+		return diags
+	}
+	logEncodedClaims(ctx, token)
+
+	data.ID = types.StringValue(claims.Claims().Subject)
+	data.ClaimType = types.StringValue(string(claims.ClaimType()))
+	data.Subject = types.StringValue(claims.Claims().Subject)
+	data.Issuer = types.StringValue(issuerPubKey)
+	data.OriginalIssuer = types.StringValue(originalIssuer)
+	data.ResignedJWT = types.StringValue(token)
+
+	return diags
+}
+
+// resignIssuer loads the new issuer of a re-signed JWT. The issuer must be
+// of the same key type as the original issuer.
+func resignIssuer(ctx context.Context, signer externalSigner, keys *keypairCache, keyName, publicKey, seed types.String, prefix nkeys.PrefixByte) (nkeys.KeyPair, jwt.SignFn, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !keyName.IsNull() || !publicKey.IsNull() {
+		kp, signFn, err := externalIssuer(ctx, signer, keyName.ValueString(), publicKey.ValueString(), prefix)
+		if err != nil {
+			diags.AddError("Failed to load issuer key", err.Error())
+			return nil, nil, diags
+		}
+		return kp, signFn, diags
+	}
+
+	if seed.ValueString() == "" {
+		diags.AddError(
+			"Missing issuer seed",
+			"Issuer seed (issuer_seed) is required",
+		)
+		return nil, nil, diags
+	}
+
+	kp, err := keys.fromSeed(seed.ValueString())
+	if err != nil {
+		diags.AddError("Failed to parse issuer seed", err.Error())
+		return nil, nil, diags
+	}
+
+	issuerPubKey, err := kp.PublicKey()
+	if err != nil {
+		diags.AddError("Failed to get issuer public key", err.Error())
+		return nil, nil, diags
+	}
+
+	if nkeys.Prefix(issuerPubKey) != prefix {
+		diags.AddError(
+			"Invalid issuer seed",
+			fmt.Sprintf("Seed generates %s, which is not of the same key type as the original issuer (%s)", issuerPubKey, prefix),
+		)
+		return nil, nil, diags
+	}
+
+	return kp, nil, diags
+}
+
+// resignIssuerAccount returns the issuer account of a re-signed user or
+// activation JWT.
+func resignIssuerAccount(issuerAccount, originalIssuer, issuer string) string {
+	if issuerAccount == "" {
+		issuerAccount = originalIssuer
+	}
+	if issuerAccount == issuer {
+		return ""
+	}
+	return issuerAccount
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccJWTResignResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccJWTResignResourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_jwt_resign.test", "claim_type", "account"),
+					resource.TestCheckResourceAttrPair("nsc_jwt_resign.test", "subject", "nsc_nkey.account", "public_key"),
+					resource.TestCheckResourceAttrPair("nsc_jwt_resign.test", "original_issuer", "nsc_nkey.operator", "public_key"),
+					resource.TestCheckResourceAttrPair("nsc_jwt_resign.test", "issuer", "nsc_nkey.operator_signing", "public_key"),
+				),
+			},
+		},
+	})
+}
+
+const testAccJWTResignResourceConfig = `
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "operator_signing" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+  allow_pub   = ["foo.>"]
+}
+
+resource "nsc_jwt_resign" "test" {
+  jwt         = nsc_account.test.jwt
+  issuer_seed = nsc_nkey.operator_signing.seed
+}
+`
+
+func TestJWTResignResource_resign(t *testing.T) {
+	ctx := context.Background()
+
+	accountKP, _ := nkeys.CreateAccount()
+	accountSeed, _ := accountKP.Seed()
+	accountPubKey, _ := accountKP.PublicKey()
+	signingKP, _ := nkeys.CreateAccount()
+	signingSeed, _ := signingKP.Seed()
+	signingPubKey, _ := signingKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorSeed, _ := operatorKP.Seed()
+
+	claims := jwt.NewUserClaims(userPubKey)
+	claims.Name = "TestUser"
+	claims.Pub.Allow.Add("foo.>")
+	claims.Expires = 2000000000
+	token, err := claims.Encode(accountKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &JWTResignResource{}
+	resign := func(t *testing.T, token string, seed []byte) (JWTResignResourceModel, bool) {
+		t.Helper()
+		data := JWTResignResourceModel{
+			JWT:             types.StringValue(token),
+			IssuerKeyName:   types.StringNull(),
+			IssuerPublicKey: types.StringNull(),
+		}
+		diags := r.resign(ctx, &data, types.StringValue(string(seed)))
+		return data, !diags.HasError()
+	}
+
+	// Re-signing with a signing key names the original account
+	data, ok := resign(t, token, signingSeed)
+	if !ok {
+		t.Fatal("unexpected error re-signing with signing key")
+	}
+	resigned, err := jwt.DecodeUserClaims(data.ResignedJWT.ValueString())
+	if err != nil {
+		t.Fatalf("failed to decode re-signed JWT: %s", err)
+	}
+	if resigned.Issuer != signingPubKey {
+		t.Errorf("expected issuer %s, got %s", signingPubKey, resigned.Issuer)
+	}
+	if resigned.IssuerAccount != accountPubKey {
+		t.Errorf("expected issuer_account %s, got %s", accountPubKey, resigned.IssuerAccount)
+	}
+	if resigned.Name != "TestUser" || resigned.Expires != 2000000000 || !resigned.Pub.Allow.Contains("foo.>") {
+		t.Errorf("expected claims to be kept, got %s", resigned)
+	}
+	if data.OriginalIssuer.ValueString() != accountPubKey {
+		t.Errorf("expected original_issuer %s, got %s", accountPubKey, data.OriginalIssuer)
+	}
+
+	// Re-signing back with the account key drops issuer_account again
+	data, ok = resign(t, data.ResignedJWT.ValueString(), accountSeed)
+	if !ok {
+		t.Fatal("unexpected error re-signing with account key")
+	}
+	resigned, err = jwt.DecodeUserClaims(data.ResignedJWT.ValueString())
+	if err != nil {
+		t.Fatalf("failed to decode re-signed JWT: %s", err)
+	}
+	if resigned.IssuerAccount != "" {
+		t.Errorf("expected no issuer_account, got %s", resigned.IssuerAccount)
+	}
+
+	for name, tt := range map[string]struct {
+		jwt  string
+		seed []byte
+	}{
+		"wrong key type": {jwt: token, seed: operatorSeed},
+		"invalid jwt":    {jwt: "not.a.jwt", seed: signingSeed},
+		"tampered jwt":   {jwt: token[:len(token)-4] + "AAAA", seed: signingSeed},
+	} {
+		if _, ok := resign(t, tt.jwt, tt.seed); ok {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -3,9 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -19,18 +23,38 @@ import (
 
 var _ resource.Resource = &NKeyResource{}
 var _ resource.ResourceWithImportState = &NKeyResource{}
+var _ resource.ResourceWithValidateConfig = &NKeyResource{}
+var _ resource.ResourceWithIdentity = &NKeyResource{}
 
 func NewNKeyResource() resource.Resource {
 	return &NKeyResource{}
 }
 
-type NKeyResource struct{}
+type NKeyResource struct {
+	cipher *stateCipher
+}
 
 type NKeyResourceModel struct {
-	ID        types.String `tfsdk:"id"`
-	Type      types.String `tfsdk:"type"`
-	PublicKey types.String `tfsdk:"public_key"`
-	Seed      types.String `tfsdk:"seed"`
+	ID                   types.String `tfsdk:"id"`
+	Type                 types.String `tfsdk:"type"`
+	PublicKey            types.String `tfsdk:"public_key"`
+	Seed                 types.String `tfsdk:"seed"`
+	EncryptionRecipients types.List   `tfsdk:"encryption_recipients"`
+	EncryptionPassphrase types.String `tfsdk:"encryption_passphrase"`
+	EncryptedSeed        types.String `tfsdk:"encrypted_seed"`
+	VanityPrefix         types.String `tfsdk:"vanity_prefix"`
+	VanityMaxAttempts    types.Int64  `tfsdk:"vanity_max_attempts"`
+}
+
+// nkeyVanityDefaultAttempts is the number of keys generated for a
+// vanity_prefix when vanity_max_attempts is not set.
+const nkeyVanityDefaultAttempts = 1000000
+
+// nkeyTypePrefixes maps key types to the first character of their public keys.
+var nkeyTypePrefixes = map[string]string{
+	"operator": "O",
+	"account":  "A",
+	"user":     "U",
 }
 
 func (r *NKeyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -39,7 +63,7 @@ func (r *NKeyResource) Metadata(_ context.Context, req resource.MetadataRequest,
 
 func (r *NKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		MarkdownDescription: "Generates a NATS NKey keypair. Use with nsc_operator, nsc_account, or nsc_user resources to create JWTs.",
+		MarkdownDescription: "Generates a NATS NKey keypair, or adopts an existing one from `seed`. Use with nsc_operator, nsc_account, or nsc_user resources to create JWTs.",
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -50,12 +74,14 @@ func (r *NKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				},
 			},
 			"type": schema.StringAttribute{
-				Required:            true,
-				MarkdownDescription: "NKey type: operator, account, or user",
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "NKey type: operator, account, or user. Required unless `seed` is set, in which case it is derived from the seed.",
 				Validators: []validator.String{
 					stringvalidator.OneOf("operator", "account", "user"),
 				},
 				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
@@ -67,19 +93,138 @@ func (r *NKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				},
 			},
 			"seed": schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
 				Sensitive:           true,
-				MarkdownDescription: "NKey seed (private key)",
+				MarkdownDescription: "NKey seed (private key). Set it to adopt an existing key instead of generating one; changing it replaces the resource. A generated seed is encrypted when the provider has a `state_encryption_key`; other resources of the provider decrypt it when it is passed on.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"encryption_recipients": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "[age](https://age-encryption.org) X25519 recipients (`age1...`) to encrypt the seed to. When set, `encrypted_seed` is populated. Conflicts with `encryption_passphrase`.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(
+						stringvalidator.RegexMatches(
+							regexp.MustCompile(`^age1[02-9ac-hj-np-z]+$`),
+							"must be an age X25519 recipient starting with 'age1'",
+						),
+					),
+					listvalidator.ConflictsWith(path.MatchRoot("encryption_passphrase")),
+				},
+			},
+			"encryption_passphrase": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				MarkdownDescription: "Passphrase to encrypt the seed with (age scrypt). When set, `encrypted_seed` is populated. Never stored in state; the seed is re-encrypted when `encrypted_seed` no longer decrypts with the passphrase. Conflicts with `encryption_recipients`.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"encrypted_seed": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "NKey seed encrypted with age (ASCII armored). Only populated when `encryption_recipients` or `encryption_passphrase` is set. Safe to hand out, unlike `seed`; decrypt with `age --decrypt`.",
+				PlanModifiers: []planmodifier.String{
+					encryptedSeedModifier{},
+				},
+			},
+			"vanity_prefix": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Generate keys until the public key starts with this prefix, e.g. `ADPRD` for production accounts. The first character is fixed by the key type (`O`, `A` or `U`) and the second is always one of `A`-`D`; the rest are base32 characters (`A`-`Z`, `2`-`7`). Each further character multiplies the expected number of attempts by 32, so keep it short. Changing it replaces the resource. Conflicts with `seed`.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^[OAU]([A-D][A-Z2-7]*)?$`),
+						"must start with O, A or U, followed by one of A-D and base32 characters (A-Z, 2-7)",
+					),
+					stringvalidator.ConflictsWith(path.MatchRoot("seed")),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vanity_max_attempts": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Maximum number of keys generated for `vanity_prefix` before giving up. Defaults to 1000000.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
 				},
 			},
 		},
 	}
 }
 
-func (r *NKeyResource) Configure(_ context.Context, _ resource.ConfigureRequest, _ *resource.ConfigureResponse) {
-	// No provider configuration needed
+func (r *NKeyResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = publicKeyIdentitySchema("Public key of the key pair")
+}
+
+func (r *NKeyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.cipher = providerData.StateCipher
+}
+
+func (r *NKeyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data NKeyResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.Seed.IsNull() {
+		if data.Type.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("type"),
+				"Missing NKey type",
+				"The type attribute is required unless seed is set.",
+			)
+		}
+		if !data.Type.IsNull() && !data.Type.IsUnknown() && !data.VanityPrefix.IsNull() && !data.VanityPrefix.IsUnknown() {
+			expected := nkeyTypePrefixes[data.Type.ValueString()]
+			if !strings.HasPrefix(data.VanityPrefix.ValueString(), expected) {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("vanity_prefix"),
+					"Invalid vanity prefix",
+					fmt.Sprintf("Public keys of %s keys start with %s; the vanity prefix %q can never match.", data.Type.ValueString(), expected, data.VanityPrefix.ValueString()),
+				)
+			}
+		}
+		return
+	}
+	// Encrypted seeds are checked on create, when the state encryption key
+	// is known
+	if data.Seed.IsUnknown() || isEncryptedSeed(data.Seed.ValueString()) {
+		return
+	}
+
+	_, keyType, err := parseNKeySeed(data.Seed.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "Invalid seed", err.Error())
+		return
+	}
+	if !data.Type.IsNull() && !data.Type.IsUnknown() && data.Type.ValueString() != keyType {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("type"),
+			"Seed type mismatch",
+			fmt.Sprintf("The seed is of type %s, but type is %s.", keyType, data.Type.ValueString()),
+		)
+	}
 }
 
 func (r *NKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -90,24 +235,47 @@ func (r *NKeyResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	// Create key pair based on type
+	// Get WriteOnly encryption_passphrase from Config
+	var config NKeyResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Adopt the configured seed, or create a key pair based on type
 	keyType := data.Type.ValueString()
 	var kp nkeys.KeyPair
 	var err error
 
-	switch keyType {
-	case "operator":
-		kp, err = nkeys.CreateOperator()
-	case "account":
-		kp, err = nkeys.CreateAccount()
-	case "user":
-		kp, err = nkeys.CreateUser()
-	default:
-		resp.Diagnostics.AddError(
-			"Invalid NKey type",
-			fmt.Sprintf("Type must be one of: operator, account, user. Got: %s", keyType),
-		)
-		return
+	adopted := !data.Seed.IsNull() && !data.Seed.IsUnknown()
+	if adopted {
+		var seed string
+		seed, err = r.cipher.decrypt(data.Seed.ValueString())
+		if err == nil {
+			kp, keyType, err = parseNKeySeed(seed)
+		}
+	} else {
+		var create func() (nkeys.KeyPair, error)
+		switch keyType {
+		case "operator":
+			create = nkeys.CreateOperator
+		case "account":
+			create = nkeys.CreateAccount
+		case "user":
+			create = nkeys.CreateUser
+		default:
+			resp.Diagnostics.AddError(
+				"Invalid NKey type",
+				fmt.Sprintf("Type must be one of: operator, account, user. Got: %s", keyType),
+			)
+			return
+		}
+
+		attempts := int64(nkeyVanityDefaultAttempts)
+		if !data.VanityMaxAttempts.IsNull() {
+			attempts = data.VanityMaxAttempts.ValueInt64()
+		}
+		kp, err = createVanityNKey(ctx, create, data.VanityPrefix.ValueString(), attempts)
 	}
 
 	if err != nil {
@@ -128,15 +296,7 @@ func (r *NKeyResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	// Validate the key type matches
-	var expectedPrefix string
-	switch keyType {
-	case "operator":
-		expectedPrefix = "O"
-	case "account":
-		expectedPrefix = "A"
-	case "user":
-		expectedPrefix = "U"
-	}
+	expectedPrefix := nkeyTypePrefixes[keyType]
 
 	if !strings.HasPrefix(publicKey, expectedPrefix) {
 		resp.Diagnostics.AddError(
@@ -146,13 +306,30 @@ func (r *NKeyResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	// Set computed values
+	// Set computed values. An adopted seed is kept as configured, a
+	// generated one is encrypted when a state encryption key is configured.
 	data.ID = types.StringValue(publicKey)
+	data.Type = types.StringValue(keyType)
 	data.PublicKey = types.StringValue(publicKey)
-	data.Seed = types.StringValue(string(seed))
+	if !adopted {
+		stateSeed, err := r.cipher.encrypt(string(seed))
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to encrypt seed", err.Error())
+			return
+		}
+		data.Seed = types.StringValue(stateSeed)
+	}
+
+	encryptedSeed, diags := nkeyEncryptedSeed(ctx, data.EncryptionRecipients, config.EncryptionPassphrase, string(seed))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.EncryptedSeed = encryptedSeed
 
 	tflog.Trace(ctx, "created nkey resource", map[string]any{"type": keyType})
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
 }
 
 func (r *NKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -163,23 +340,60 @@ func (r *NKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
+	// State written before encryption_passphrase became write-only holds
+	// the passphrase
+	if !data.EncryptionPassphrase.IsNull() {
+		data.EncryptionPassphrase = types.StringNull()
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	}
+
 	// For state-only storage, nothing to read externally
 	// Keys remain valid in state
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
 }
 
 func (r *NKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	// NKeys are immutable - type has RequiresReplace modifier
-	// This should never be called, but implement for completeness
+	// Only the seed encryption settings can change
 	var data NKeyResourceModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	resp.Diagnostics.AddError(
-		"NKey Update Not Supported",
-		"NKeys are immutable. Changing the type requires replacing the resource.",
-	)
+	var state NKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get WriteOnly encryption_passphrase from Config
+	var config NKeyResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = state.ID
+	data.PublicKey = state.PublicKey
+	data.Seed = state.Seed
+
+	seed, err := r.cipher.decrypt(data.Seed.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("seed"), "Failed to decrypt seed", err.Error())
+		return
+	}
+
+	encryptedSeed, diags := nkeyEncryptedSeed(ctx, data.EncryptionRecipients, config.EncryptionPassphrase, seed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.EncryptedSeed = encryptedSeed
+
+	tflog.Trace(ctx, "updated nkey resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
 }
 
 func (r *NKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -195,49 +409,38 @@ func (r *NKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, r
 }
 
 func (r *NKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Import format: just the seed
+	// Import format: just the seed. The public key of an identity cannot
+	// restore the key pair.
 	seedStr := req.ID
+	if seedStr == "" {
+		resp.Diagnostics.AddError(
+			"Missing seed",
+			"NKeys are imported by their seed. Use the seed as import ID instead of the public_key identity.",
+		)
+		return
+	}
 
 	// Parse the seed to determine type and validate
-	kp, err := nkeys.FromSeed([]byte(seedStr))
+	seed, err := r.cipher.decrypt(seedStr)
 	if err != nil {
-		resp.Diagnostics.AddError("Invalid seed", fmt.Sprintf("Failed to parse seed: %v", err))
+		resp.Diagnostics.AddError("Invalid seed", err.Error())
 		return
 	}
-
-	publicKey, err := kp.PublicKey()
+	kp, keyType, err := parseNKeySeed(seed)
 	if err != nil {
-		resp.Diagnostics.AddError("Invalid keypair", fmt.Sprintf("Failed to get public key: %v", err))
+		resp.Diagnostics.AddError("Invalid seed", err.Error())
 		return
 	}
 
-	// Determine type from public key prefix
-	var keyType string
-	var seedPrefix string
-	switch {
-	case strings.HasPrefix(publicKey, "O"):
-		keyType = "operator"
-		seedPrefix = "SO"
-	case strings.HasPrefix(publicKey, "A"):
-		keyType = "account"
-		seedPrefix = "SA"
-	case strings.HasPrefix(publicKey, "U"):
-		keyType = "user"
-		seedPrefix = "SU"
-	default:
-		resp.Diagnostics.AddError(
-			"Invalid key type",
-			fmt.Sprintf("Unknown key type from public key: %s", publicKey[:1]),
-		)
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid keypair", fmt.Sprintf("Failed to get public key: %v", err))
 		return
 	}
 
-	// Validate seed prefix matches
-	if !strings.HasPrefix(seedStr, seedPrefix) {
-		resp.Diagnostics.AddError(
-			"Seed type mismatch",
-			fmt.Sprintf("Seed prefix %s does not match expected %s for %s key", seedStr[:2], seedPrefix, keyType),
-		)
+	seedStr, err = r.cipher.encrypt(seed)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encrypt seed", err.Error())
 		return
 	}
 
@@ -247,3 +450,128 @@ func (r *NKeyResource) ImportState(ctx context.Context, req resource.ImportState
 	resp.State.SetAttribute(ctx, path.Root("public_key"), types.StringValue(publicKey))
 	resp.State.SetAttribute(ctx, path.Root("seed"), types.StringValue(seedStr))
 }
+
+// createVanityNKey generates key pairs until the public key starts with the
+// prefix, giving up after the given number of attempts. An empty prefix
+// accepts the first key pair.
+func createVanityNKey(ctx context.Context, create func() (nkeys.KeyPair, error), prefix string, attempts int64) (nkeys.KeyPair, error) {
+	for i := int64(0); i < attempts; i++ {
+		if i%1000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		kp, err := create()
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := kp.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(publicKey, prefix) {
+			if i > 0 {
+				tflog.Debug(ctx, "generated vanity nkey", map[string]any{"prefix": prefix, "attempts": i + 1})
+			}
+			return kp, nil
+		}
+	}
+	return nil, fmt.Errorf("no public key starting with %q found in %d attempts; use a shorter vanity_prefix or raise vanity_max_attempts", prefix, attempts)
+}
+
+// parseNKeySeed parses an operator, account or user seed and returns the key
+// pair with its type.
+func parseNKeySeed(seed string) (nkeys.KeyPair, string, error) {
+	kp, err := nkeys.FromSeed([]byte(seed))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse seed: %v", err)
+	}
+
+	prefix, _, err := nkeys.DecodeSeed([]byte(seed))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse seed: %v", err)
+	}
+
+	switch prefix {
+	case nkeys.PrefixByteOperator:
+		return kp, "operator", nil
+	case nkeys.PrefixByteAccount:
+		return kp, "account", nil
+	case nkeys.PrefixByteUser:
+		return kp, "user", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported key type %s; expected an operator, account or user seed", prefix)
+	}
+}
+
+// nkeyEncryptedSeed returns the encrypted_seed value of the plain seed for
+// the configured encryption settings, or null when seed encryption is not
+// configured.
+func nkeyEncryptedSeed(ctx context.Context, encryptionRecipients types.List, encryptionPassphrase types.String, seed string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var recipients []string
+	if !encryptionRecipients.IsNull() {
+		diags.Append(encryptionRecipients.ElementsAs(ctx, &recipients, false)...)
+		if diags.HasError() {
+			return types.StringNull(), diags
+		}
+	}
+	passphrase := encryptionPassphrase.ValueString()
+
+	if len(recipients) == 0 && passphrase == "" {
+		return types.StringNull(), diags
+	}
+
+	encrypted, err := encryptSeed(seed, recipients, passphrase)
+	if err != nil {
+		diags.AddError("Failed to encrypt seed", err.Error())
+		return types.StringNull(), diags
+	}
+
+	return types.StringValue(encrypted), diags
+}
+
+var _ planmodifier.String = encryptedSeedModifier{}
+
+// encryptedSeedModifier keeps the prior encrypted_seed while the encryption
+// settings are unchanged. age output differs on every encryption, so a new
+// value is only planned when the recipients change or when the prior value
+// does not decrypt with the configured passphrase, which is not kept in
+// state.
+type encryptedSeedModifier struct{}
+
+func (m encryptedSeedModifier) Description(_ context.Context) string {
+	return "Uses the prior value unless the seed encryption settings change."
+}
+
+func (m encryptedSeedModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m encryptedSeedModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// Nothing to keep on create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config, plan, state NKeyResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.EncryptionRecipients.Equal(state.EncryptionRecipients) || config.EncryptionPassphrase.IsUnknown() {
+		return
+	}
+	passphrase := config.EncryptionPassphrase.ValueString()
+	switch {
+	case !plan.EncryptionRecipients.IsNull():
+		resp.PlanValue = req.StateValue
+	case passphrase == "" && req.StateValue.IsNull():
+		resp.PlanValue = req.StateValue
+	case passphrase != "" && !req.StateValue.IsNull() && seedDecryptsWithPassphrase(req.StateValue.ValueString(), passphrase):
+		resp.PlanValue = req.StateValue
+	}
+}
@@ -0,0 +1,385 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &NKeyFilesResource{}
+var _ resource.ResourceWithConfigure = &NKeyFilesResource{}
+
+const (
+	nkeyFilesLayoutFlat = "flat"
+	nkeyFilesLayoutNSC  = "nsc"
+)
+
+func NewNKeyFilesResource() resource.Resource {
+	return &NKeyFilesResource{}
+}
+
+// NKeyFilesResource writes seeds as .nk files, so that the keys can be used
+// with the nsc and nk CLIs when Terraform is not at hand.
+type NKeyFilesResource struct {
+	keys *keypairCache
+}
+
+type NKeyFilesResourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	Directory types.String `tfsdk:"directory"`
+	Layout    types.String `tfsdk:"layout"`
+	Seeds     types.Set    `tfsdk:"seeds"`
+	Files     types.Map    `tfsdk:"files"`
+	Manifest  types.String `tfsdk:"manifest"`
+}
+
+func (r *NKeyFilesResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nkey_files"
+}
+
+func (r *NKeyFilesResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Writes seeds as `.nk` files named by their public key, readable only by the owner, so that break-glass operations with the nsc and nk CLIs remain possible. " +
+			"Files that go missing or are modified outside Terraform are written again on the next apply. Destroying the resource removes the files it wrote.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Directory the files are written to (same as directory)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"directory": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Directory to write the files to. Created with mode `0700` when it does not exist.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"layout": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(nkeyFilesLayoutFlat),
+				MarkdownDescription: "`flat` (default) writes `<directory>/<public key>.nk`. `nsc` writes `<directory>/keys/<K>/<EY>/<public key>.nk`, the layout of the nsc keystore, so that `directory` can be used as `NKEYS_PATH`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(nkeyFilesLayoutFlat, nkeyFilesLayoutNSC),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"seeds": schema.SetAttribute{
+				ElementType:         types.StringType,
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Operator, account and user seeds to write, e.g. `nsc_nkey.account.seed`. Seeds encrypted with the provider's `state_encryption_key` are written decrypted.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"files": schema.MapAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: "Map of public keys to the paths of their files",
+			},
+			"manifest": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "JSON manifest of the files with their SHA-256, key type and public key (`subject`), for deployment pipelines to verify them",
+			},
+		},
+	}
+}
+
+func (r *NKeyFilesResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.keys = providerData.Keys
+}
+
+func (r *NKeyFilesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data NKeyFilesResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files, manifest, diags := writeNKeyFiles(ctx, r.keys, data, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.Directory
+	data.Files = files
+	data.Manifest = manifest
+
+	tflog.Trace(ctx, "created nkey files resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NKeyFilesResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data NKeyFilesResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files, diags := nkeyFiles(ctx, r.keys, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A missing or modified file makes Terraform write all files again
+	entries := make([]artifactManifestEntry, 0, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file.path)
+		if err != nil || !bytes.Equal(content, file.seed) {
+			tflog.Debug(ctx, "nkey file missing or modified, removing from state", map[string]any{"path": file.path})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		entries = append(entries, nkeyFileManifestEntry(data.Directory.ValueString(), file))
+	}
+
+	// State written before the manifest was added gets one
+	manifest, err := renderArtifactManifest(entries)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to render manifest", err.Error())
+		return
+	}
+	data.Manifest = types.StringValue(manifest)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NKeyFilesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state NKeyFilesResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous := make(map[string]string, len(state.Files.Elements()))
+	resp.Diagnostics.Append(state.Files.ElementsAs(ctx, &previous, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files, manifest, diags := writeNKeyFiles(ctx, r.keys, data, previous)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = data.Directory
+	data.Files = files
+	data.Manifest = manifest
+
+	tflog.Trace(ctx, "updated nkey files resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *NKeyFilesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data NKeyFilesResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	files := make(map[string]string, len(data.Files.Elements()))
+	resp.Diagnostics.Append(data.Files.ElementsAs(ctx, &files, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			resp.Diagnostics.AddError("Failed to remove nkey file", err.Error())
+		}
+	}
+
+	tflog.Trace(ctx, "deleted nkey files resource")
+}
+
+// nkeyFile is a seed and the path of its file.
+type nkeyFile struct {
+	publicKey string
+	path      string
+	seed      []byte
+}
+
+// nkeyFiles returns the files of the seeds of the resource, ordered by path.
+// Seeds encrypted with the state encryption key are written decrypted.
+func nkeyFiles(ctx context.Context, keys *keypairCache, data NKeyFilesResourceModel) ([]nkeyFile, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var seeds []string
+	diags.Append(data.Seeds.ElementsAs(ctx, &seeds, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	files := make([]nkeyFile, 0, len(seeds))
+	for _, seed := range seeds {
+		seed, err := keys.seed(seed)
+		if err != nil {
+			diags.AddAttributeError(path.Root("seeds"), "Invalid seed", err.Error())
+			continue
+		}
+		kp, _, err := parseNKeySeed(seed)
+		if err != nil {
+			// The error never contains the seed
+			diags.AddAttributeError(path.Root("seeds"), "Invalid seed", err.Error())
+			continue
+		}
+		publicKey, err := kp.PublicKey()
+		if err != nil {
+			diags.AddAttributeError(path.Root("seeds"), "Invalid seed", fmt.Sprintf("Failed to get public key: %v", err))
+			continue
+		}
+		files = append(files, nkeyFile{
+			publicKey: publicKey,
+			path:      nkeyFilePath(data.Directory.ValueString(), data.Layout.ValueString(), publicKey),
+			seed:      []byte(seed),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	return files, diags
+}
+
+// nkeyFilePath returns the path of the file of a public key in the layout.
+func nkeyFilePath(directory, layout, publicKey string) string {
+	if layout == nkeyFilesLayoutNSC {
+		return nscKeystorePath(directory, publicKey)
+	}
+	return filepath.Join(directory, publicKey+".nk")
+}
+
+// nscKeystorePath returns the path nsc stores the seed of a public key at in
+// the keystore directory.
+func nscKeystorePath(keysDir, publicKey string) string {
+	return filepath.Join(keysDir, "keys", publicKey[:1], publicKey[1:3], publicKey+".nk")
+}
+
+// writeNKeyFiles writes the files of the seeds of the resource and removes the
+// previously written files of seeds that are no longer listed. It returns the
+// files and manifest attributes.
+func writeNKeyFiles(ctx context.Context, keys *keypairCache, data NKeyFilesResourceModel, previous map[string]string) (types.Map, types.String, diag.Diagnostics) {
+	files, diags := nkeyFiles(ctx, keys, data)
+	if diags.HasError() {
+		return types.MapNull(types.StringType), types.StringNull(), diags
+	}
+
+	paths := make(map[string]string, len(files))
+	entries := make([]artifactManifestEntry, 0, len(files))
+	for _, file := range files {
+		if err := writeFileAtomic(file.path, file.seed); err != nil {
+			diags.AddError("Failed to write nkey file", err.Error())
+			return types.MapNull(types.StringType), types.StringNull(), diags
+		}
+		paths[file.publicKey] = file.path
+		entries = append(entries, nkeyFileManifestEntry(data.Directory.ValueString(), file))
+	}
+
+	for publicKey, file := range previous {
+		if paths[publicKey] == file {
+			continue
+		}
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			diags.AddError("Failed to remove nkey file", err.Error())
+		}
+	}
+
+	manifest, err := renderArtifactManifest(entries)
+	if err != nil {
+		diags.AddError("Failed to render manifest", err.Error())
+		return types.MapNull(types.StringType), types.StringNull(), diags
+	}
+
+	filesValue, d := types.MapValueFrom(ctx, types.StringType, paths)
+	diags.Append(d...)
+	return filesValue, types.StringValue(manifest), diags
+}
+
+// nkeyFileManifestEntry returns the manifest entry of the file of a seed.
+func nkeyFileManifestEntry(directory string, file nkeyFile) artifactManifestEntry {
+	name, err := filepath.Rel(directory, file.path)
+	if err != nil {
+		name = file.path
+	}
+	return newArtifactManifestEntry(name, file.path, file.seed)
+}
+
+// writeFileAtomic writes content to a file readable only by the owner,
+// creating its directory with mode 0700.
+func writeFileAtomic(name string, content []byte) error {
+	return writeFileAtomicMode(name, content, 0o600, 0o700)
+}
+
+// writeFileAtomicMode writes content to a file with the given mode, creating
+// its directory with dirMode. The content is written to a temporary file
+// first, so the file is never seen partially written.
+func writeFileAtomicMode(name string, content []byte, mode, dirMode os.FileMode) error {
+	dir := filepath.Dir(name)
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,195 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccNKeyFilesResource(t *testing.T) {
+	dir := t.TempDir()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckNKeyFilesRemoved(dir),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNKeyFilesResourceConfig(dir, "flat", "[nsc_nkey.account.seed, nsc_nkey.user.seed]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_nkey_files.test", "files.%", "2"),
+					resource.TestCheckResourceAttrWith("nsc_nkey_files.test", "manifest", func(value string) error {
+						var manifest artifactManifest
+						if err := json.Unmarshal([]byte(value), &manifest); err != nil {
+							return err
+						}
+						for _, artifact := range manifest.Artifacts {
+							if artifact.Name != artifact.Subject+".nk" || len(artifact.SHA256) != 64 {
+								return fmt.Errorf("unexpected manifest: %s", value)
+							}
+						}
+						if len(manifest.Artifacts) != 2 {
+							return fmt.Errorf("unexpected manifest: %s", value)
+						}
+						return nil
+					}),
+					testAccCheckNKeyFile("nsc_nkey.account", dir, "flat"),
+					testAccCheckNKeyFile("nsc_nkey.user", dir, "flat"),
+				),
+			},
+			{
+				// A deleted file is written again
+				PreConfig: func() {
+					entries, _ := filepath.Glob(filepath.Join(dir, "U*.nk"))
+					for _, entry := range entries {
+						os.Remove(entry)
+					}
+				},
+				Config: testAccNKeyFilesResourceConfig(dir, "flat", "[nsc_nkey.account.seed, nsc_nkey.user.seed]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckNKeyFile("nsc_nkey.user", dir, "flat"),
+				),
+			},
+			{
+				// Files of removed seeds are removed
+				Config: testAccNKeyFilesResourceConfig(dir, "flat", "[nsc_nkey.account.seed]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_nkey_files.test", "files.%", "1"),
+					testAccCheckNKeyFile("nsc_nkey.account", dir, "flat"),
+					func(s *terraform.State) error {
+						entries, _ := filepath.Glob(filepath.Join(dir, "U*.nk"))
+						if len(entries) != 0 {
+							return fmt.Errorf("expected the user file to be removed, found %v", entries)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: testAccNKeyFilesResourceConfig(dir, "nsc", "[nsc_nkey.account.seed]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckNKeyFile("nsc_nkey.account", dir, "nsc"),
+				),
+			},
+		},
+	})
+}
+
+func testAccNKeyFilesResourceConfig(dir, layout, seeds string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_nkey_files" "test" {
+  directory = %[1]q
+  layout    = %[2]q
+  seeds     = %[3]s
+}
+`, dir, layout, seeds)
+}
+
+// testAccCheckNKeyFile checks that the seed of an nsc_nkey resource was
+// written to its file with mode 0600.
+func testAccCheckNKeyFile(resourceName, dir, layout string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+		publicKey := rs.Primary.Attributes["public_key"]
+		file := nkeyFilePath(dir, layout, publicKey)
+
+		files := s.RootModule().Resources["nsc_nkey_files.test"].Primary.Attributes
+		if files["files."+publicKey] != file {
+			return fmt.Errorf("expected files.%s to be %s, got %q", publicKey, file, files["files."+publicKey])
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm() != 0o600 {
+			return fmt.Errorf("expected mode 0600 for %s, got %o", file, info.Mode().Perm())
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if string(content) != rs.Primary.Attributes["seed"] {
+			return fmt.Errorf("unexpected content of %s", file)
+		}
+		return nil
+	}
+}
+
+func testAccCheckNKeyFilesRemoved(dir string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		var found []string
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if len(found) != 0 {
+			return fmt.Errorf("expected all nkey files to be removed, found %v", found)
+		}
+		return nil
+	}
+}
+
+func TestNKeyFilePath(t *testing.T) {
+	publicKey := "ABCDEFGHIJ"
+	if got, want := nkeyFilePath("/keys", nkeyFilesLayoutFlat, publicKey), filepath.Join("/keys", "ABCDEFGHIJ.nk"); got != want {
+		t.Errorf("flat layout: expected %s, got %s", want, got)
+	}
+	if got, want := nkeyFilePath("/keys", nkeyFilesLayoutNSC, publicKey), filepath.Join("/keys", "keys", "A", "BC", "ABCDEFGHIJ.nk"); got != want {
+		t.Errorf("nsc layout: expected %s, got %s", want, got)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nested", "key.nk")
+
+	for _, content := range []string{"first", "second"} {
+		if err := writeFileAtomic(file, []byte(content)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("expected %q, got %q", content, got)
+		}
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, got %o", info.Mode().Perm())
+	}
+	dirInfo, err := os.Stat(filepath.Dir(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirInfo.Mode().Perm() != 0o700 {
+		t.Errorf("expected directory mode 0700, got %o", dirInfo.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(file))
+	if len(entries) != 1 {
+		t.Errorf("expected no temporary files to remain, found %d entries", len(entries))
+	}
+}
@@ -1,11 +1,25 @@
 package provider
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"regexp"
+	"strings"
 	"testing"
 
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/nats-io/nkeys"
 )
 
 func TestAccNKeyResource_operator(t *testing.T) {
@@ -110,6 +124,9 @@ func TestAccNKeyResource_importWithType(t *testing.T) {
 					resource.TestCheckResourceAttr("nsc_nkey.test", "type", "account"),
 					testAccCheckNKeyPublicKeyPrefix("nsc_nkey.test", "A"),
 				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentityValueMatchesState("nsc_nkey.test", tfjsonpath.New("public_key")),
+				},
 			},
 			// Import with seed - type should be auto-detected
 			{
@@ -171,3 +188,376 @@ func testAccCheckNKeySeedPrefix(resourceName, expectedPrefix string) resource.Te
 		return nil
 	}
 }
+
+const (
+	testAccAgeIdentity  = "AGE-SECRET-KEY-1Q2CGF50KS7SZ0ESAXUMAA4XNXTQ085XL2YSNZY4KCS49S2KW3J0SCTPUFN"
+	testAccAgeRecipient = "age175pjjz570tyxunpaw8cze9q4tdsvytft3ngcekap2n09r0vclpfs3r0yvf"
+)
+
+func TestAccNKeyResource_encryptedSeed(t *testing.T) {
+	var publicKey string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Encrypt to an age recipient
+			{
+				Config: testAccNKeyResourceConfigWithEncryption(fmt.Sprintf("encryption_recipients = [%q]", testAccAgeRecipient)),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckNKeyEncryptedSeed("nsc_nkey.test", func() (age.Identity, error) {
+						return age.ParseX25519Identity(testAccAgeIdentity)
+					}),
+					func(s *terraform.State) error {
+						publicKey = s.RootModule().Resources["nsc_nkey.test"].Primary.Attributes["public_key"]
+						return nil
+					},
+				),
+			},
+			// Switching to a passphrase re-encrypts the same key
+			{
+				Config: testAccNKeyResourceConfigWithEncryption(`encryption_passphrase = "correct horse battery staple"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckNKeyEncryptedSeed("nsc_nkey.test", func() (age.Identity, error) {
+						return age.NewScryptIdentity("correct horse battery staple")
+					}),
+					resource.TestCheckNoResourceAttr("nsc_nkey.test", "encryption_passphrase"),
+					func(s *terraform.State) error {
+						if got := s.RootModule().Resources["nsc_nkey.test"].Primary.Attributes["public_key"]; got != publicKey {
+							return fmt.Errorf("key was replaced: %s != %s", got, publicKey)
+						}
+						return nil
+					},
+				),
+			},
+			// The passphrase is not in state; a new one is detected through
+			// encrypted_seed
+			{
+				Config: testAccNKeyResourceConfigWithEncryption(`encryption_passphrase = "tr0ub4dor&3"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckNKeyEncryptedSeed("nsc_nkey.test", func() (age.Identity, error) {
+						return age.NewScryptIdentity("tr0ub4dor&3")
+					}),
+				),
+			},
+			// Removing the settings clears the encrypted seed
+			{
+				Config: testAccNKeyResourceConfig("account"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("nsc_nkey.test", "encrypted_seed"),
+				),
+			},
+		},
+	})
+}
+
+func TestEncryptedSeedModifier(t *testing.T) {
+	ctx := context.Background()
+	const seed = "SAAH3BSEHXVMGSFQHN2QL6RTZ7OY5XGOJR36UHL66QXNNZW5MYCKCXNGWE"
+
+	var schemaResp fwresource.SchemaResponse
+	(&NKeyResource{}).Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	withPassphrase, err := encryptSeed(seed, nil, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	withRecipient, err := encryptSeed(seed, []string{testAccAgeRecipient}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		state    string
+		config   string
+		wantKeep bool
+	}{
+		{
+			name:     "same passphrase",
+			state:    fmt.Sprintf(`{"type": "account", "encrypted_seed": %q}`, withPassphrase),
+			config:   `{"type": "account", "encryption_passphrase": "passphrase"}`,
+			wantKeep: true,
+		},
+		{
+			name:   "changed passphrase",
+			state:  fmt.Sprintf(`{"type": "account", "encrypted_seed": %q}`, withPassphrase),
+			config: `{"type": "account", "encryption_passphrase": "other"}`,
+		},
+		{
+			name:   "removed passphrase",
+			state:  fmt.Sprintf(`{"type": "account", "encrypted_seed": %q}`, withPassphrase),
+			config: `{"type": "account"}`,
+		},
+		{
+			name:     "same recipients",
+			state:    fmt.Sprintf(`{"type": "account", "encryption_recipients": [%q], "encrypted_seed": %q}`, testAccAgeRecipient, withRecipient),
+			config:   fmt.Sprintf(`{"type": "account", "encryption_recipients": [%q]}`, testAccAgeRecipient),
+			wantKeep: true,
+		},
+		{
+			name:   "recipients to passphrase",
+			state:  fmt.Sprintf(`{"type": "account", "encryption_recipients": [%q], "encrypted_seed": %q}`, testAccAgeRecipient, withRecipient),
+			config: `{"type": "account", "encryption_passphrase": "passphrase"}`,
+		},
+		{
+			name:     "no encryption",
+			state:    `{"type": "account"}`,
+			config:   `{"type": "account"}`,
+			wantKeep: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := testRawValue(t, schemaResp.Schema, tt.state)
+			config := testRawValue(t, schemaResp.Schema, tt.config)
+
+			var stateData NKeyResourceModel
+			if diags := (tfsdk.State{Schema: schemaResp.Schema, Raw: state}).Get(ctx, &stateData); diags.HasError() {
+				t.Fatalf("failed to read state: %v", diags)
+			}
+
+			// Write-only attributes are null in the plan
+			var planData NKeyResourceModel
+			if diags := (tfsdk.Config{Schema: schemaResp.Schema, Raw: config}).Get(ctx, &planData); diags.HasError() {
+				t.Fatalf("failed to read config: %v", diags)
+			}
+			planData.EncryptionPassphrase = types.StringNull()
+			plan := tfsdk.Plan{Schema: schemaResp.Schema}
+			if diags := plan.Set(ctx, &planData); diags.HasError() {
+				t.Fatalf("failed to set plan: %v", diags)
+			}
+
+			req := planmodifier.StringRequest{
+				Config:     tfsdk.Config{Schema: schemaResp.Schema, Raw: config},
+				Plan:       plan,
+				State:      tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+				StateValue: stateData.EncryptedSeed,
+				PlanValue:  types.StringUnknown(),
+			}
+			resp := planmodifier.StringResponse{PlanValue: req.PlanValue}
+			encryptedSeedModifier{}.PlanModifyString(ctx, req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			if kept := resp.PlanValue.Equal(req.StateValue); kept != tt.wantKeep {
+				t.Errorf("expected prior value kept to be %t, got plan %s", tt.wantKeep, resp.PlanValue)
+			}
+		})
+	}
+}
+
+func TestAccNKeyResource_encryptedSeedConflict(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccNKeyResourceConfigWithEncryption(fmt.Sprintf(`
+  encryption_recipients = [%q]
+  encryption_passphrase = "secret"`, testAccAgeRecipient)),
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func testAccNKeyResourceConfigWithEncryption(encryption string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "test" {
+  type = "account"
+  %[1]s
+}
+`, encryption)
+}
+
+func testAccCheckNKeyEncryptedSeed(resourceName string, identity func() (age.Identity, error)) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", resourceName)
+		}
+
+		id, err := identity()
+		if err != nil {
+			return err
+		}
+
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(rs.Primary.Attributes["encrypted_seed"])), id)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt encrypted_seed: %w", err)
+		}
+		seed, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		if string(seed) != rs.Primary.Attributes["seed"] {
+			return fmt.Errorf("encrypted_seed does not decrypt to seed")
+		}
+
+		return nil
+	}
+}
+
+func TestAccNKeyResource_seed(t *testing.T) {
+	kp, _ := nkeys.CreateAccount()
+	seed, _ := kp.Seed()
+	publicKey, _ := kp.PublicKey()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Adopt an existing seed, type is derived
+			{
+				Config: fmt.Sprintf(`
+resource "nsc_nkey" "test" {
+  seed = %[1]q
+}
+`, seed),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_nkey.test", "type", "account"),
+					resource.TestCheckResourceAttr("nsc_nkey.test", "public_key", publicKey),
+					resource.TestCheckResourceAttr("nsc_nkey.test", "id", publicKey),
+				),
+			},
+			// Explicit matching type does not replace the key
+			{
+				Config: fmt.Sprintf(`
+resource "nsc_nkey" "test" {
+  type = "account"
+  seed = %[1]q
+}
+`, seed),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nsc_nkey.test", plancheck.ResourceActionNoop),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccNKeyResource_invalidSeed(t *testing.T) {
+	kp, _ := nkeys.CreateUser()
+	seed, _ := kp.Seed()
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+resource "nsc_nkey" "test" {
+  type = "account"
+  seed = %[1]q
+}
+`, seed),
+				ExpectError: regexp.MustCompile(`The seed is of type user, but type is account`),
+			},
+			{
+				Config: `
+resource "nsc_nkey" "test" {
+  seed = "SAnotaseed"
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid seed`),
+			},
+			{
+				Config: `
+resource "nsc_nkey" "test" {
+}
+`,
+				ExpectError: regexp.MustCompile(`type attribute is required unless seed is set`),
+			},
+		},
+	})
+}
+
+func TestParseNKeySeed(t *testing.T) {
+	for _, create := range []func() (nkeys.KeyPair, error){nkeys.CreateOperator, nkeys.CreateAccount, nkeys.CreateUser} {
+		kp, _ := create()
+		seed, _ := kp.Seed()
+		publicKey, _ := kp.PublicKey()
+
+		parsed, keyType, err := parseNKeySeed(string(seed))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		parsedPublicKey, _ := parsed.PublicKey()
+		if parsedPublicKey != publicKey {
+			t.Errorf("expected public key %s, got %s", publicKey, parsedPublicKey)
+		}
+		if keyType[:1] != strings.ToLower(publicKey[:1]) {
+			t.Errorf("unexpected type %s for %s", keyType, publicKey)
+		}
+	}
+
+	kp, _ := nkeys.CreateServer()
+	seed, _ := kp.Seed()
+	if _, _, err := parseNKeySeed(string(seed)); err == nil {
+		t.Error("expected error for server seed")
+	}
+}
+
+func TestAccNKeyResource_vanityPrefix(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "test" {
+  type          = "account"
+  vanity_prefix = "AA"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("nsc_nkey.test", "public_key", regexp.MustCompile(`^AA`)),
+				),
+			},
+			// Prefix of another key type
+			{
+				Config: `
+resource "nsc_nkey" "test" {
+  type          = "account"
+  vanity_prefix = "UA"
+}
+`,
+				ExpectError: regexp.MustCompile(`can never match`),
+			},
+			// Second character outside A-D
+			{
+				Config: `
+resource "nsc_nkey" "test" {
+  type          = "account"
+  vanity_prefix = "APROD"
+}
+`,
+				ExpectError: regexp.MustCompile(`followed by one of A-D`),
+			},
+		},
+	})
+}
+
+func TestCreateVanityNKey(t *testing.T) {
+	ctx := context.Background()
+
+	kp, err := createVanityNKey(ctx, nkeys.CreateUser, "UA", 10000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	publicKey, _ := kp.PublicKey()
+	if !strings.HasPrefix(publicKey, "UA") {
+		t.Errorf("expected prefix UA, got %s", publicKey)
+	}
+
+	_, err = createVanityNKey(ctx, nkeys.CreateUser, "UE", 100)
+	if err == nil || !strings.Contains(err.Error(), "in 100 attempts") {
+		t.Errorf("expected attempts error, got %v", err)
+	}
+}
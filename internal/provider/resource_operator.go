@@ -7,10 +7,14 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/nats-io/jwt/v2"
@@ -18,26 +22,46 @@ import (
 )
 
 var _ resource.Resource = &OperatorResource{}
+var _ resource.ResourceWithUpgradeState = &OperatorResource{}
+var _ resource.ResourceWithModifyPlan = &OperatorResource{}
+var _ resource.ResourceWithIdentity = &OperatorResource{}
 
 func NewOperatorResource() resource.Resource {
 	return &OperatorResource{}
 }
 
-type OperatorResource struct{}
+type OperatorResource struct {
+	keys             *keypairCache
+	warnExpiryWithin time.Duration
+	requireExpiry    []string
+	policy           claimsPolicy
+	audit            *auditLog
+}
 
 type OperatorResourceModel struct {
-	ID            types.String         `tfsdk:"id"`
-	Name          types.String         `tfsdk:"name"`
-	Subject       types.String         `tfsdk:"subject"`
-	IssuerSeed    types.String         `tfsdk:"issuer_seed"`
-	SigningKeys   types.List           `tfsdk:"signing_keys"`
-	SystemAccount types.String         `tfsdk:"system_account"`
-	ExpiresIn     timetypes.GoDuration `tfsdk:"expires_in"`
-	ExpiresAt     timetypes.RFC3339    `tfsdk:"expires_at"`
-	StartsIn      timetypes.GoDuration `tfsdk:"starts_in"`
-	StartsAt      timetypes.RFC3339    `tfsdk:"starts_at"`
-	JWT           types.String         `tfsdk:"jwt"`
-	PublicKey     types.String         `tfsdk:"public_key"`
+	ID               types.String         `tfsdk:"id"`
+	Name             types.String         `tfsdk:"name"`
+	Subject          types.String         `tfsdk:"subject"`
+	IssuerSeed       types.String         `tfsdk:"issuer_seed"`
+	IssuerSeedEnv    types.String         `tfsdk:"issuer_seed_env"`
+	SigningKeys      types.List           `tfsdk:"signing_keys"`
+	SystemAccount    types.String         `tfsdk:"system_account"`
+	ExpiresIn        timetypes.GoDuration `tfsdk:"expires_in"`
+	ExpiresAt        timetypes.RFC3339    `tfsdk:"expires_at"`
+	StartsIn         timetypes.GoDuration `tfsdk:"starts_in"`
+	StartsAt         timetypes.RFC3339    `tfsdk:"starts_at"`
+	CustomClaimsJSON types.String         `tfsdk:"custom_claims_json"`
+	ClaimsPatchJSON  types.String         `tfsdk:"claims_patch_json"`
+	JWT              types.String         `tfsdk:"jwt"`
+	IssuedAt         timetypes.RFC3339    `tfsdk:"issued_at"`
+	PinIssuedAt      types.Bool           `tfsdk:"pin_issued_at"`
+	JWTID            types.String         `tfsdk:"jwt_id"`
+	PublicKey        types.String         `tfsdk:"public_key"`
+	ServerConfig     types.String         `tfsdk:"server_config"`
+
+	// AccountJWTs are the JWTs of accounts issued under the operator, used
+	// for checks only.
+	AccountJWTs types.List `tfsdk:"account_jwts"`
 }
 
 func (r *OperatorResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -47,6 +71,7 @@ func (r *OperatorResource) Metadata(_ context.Context, req resource.MetadataRequ
 func (r *OperatorResource) Schema(_ context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Manages a NATS JWT Operator. Use with nsc_nkey for key generation.",
+		Version:             1,
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -68,18 +93,24 @@ func (r *OperatorResource) Schema(_ context.Context, req resource.SchemaRequest,
 				},
 			},
 			"issuer_seed": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
 				Sensitive:           true,
 				WriteOnly:           true,
-				MarkdownDescription: "Operator seed for signing the JWT (issuer). For operators, this is the same as subject's seed (self-issued). Never stored in state.",
+				MarkdownDescription: "Operator seed for signing the JWT (issuer). For operators, this is the same as subject's seed (self-issued). Never stored in state. Exactly one of `issuer_seed` and `issuer_seed_env` must be set.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("issuer_seed_env")),
+				},
 			},
+			"issuer_seed_env": issuerSeedEnvAttribute(
+				"Name of the environment variable holding the operator seed, e.g. `NATS_OPERATOR_SEED`. Read by the provider when the operator JWT is issued, so the seed only has to be present where Terraform applies, such as a CI secret. Only the name is stored in state. Alternative to `issuer_seed`.",
+			),
 			"signing_keys": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "Optional signing key public keys (for signing account JWTs)",
+				MarkdownDescription: "Optional signing keys (for signing account JWTs), given as public keys or seeds. Only the public keys derived from seeds are put into the JWT; seeds are kept in state as given, so pass them from sensitive values such as `nsc_nkey.signing.seed`. The JWT holds each key once, sorted, so reordering or repeating keys does not reissue it.",
 			},
 			"system_account": schema.StringAttribute{
 				Optional:            true,
@@ -89,6 +120,9 @@ func (r *OperatorResource) Schema(_ context.Context, req resource.SchemaRequest,
 				CustomType:          timetypes.GoDurationType{},
 				Optional:            true,
 				MarkdownDescription: "Relative expiry duration (e.g., '8760h' for 1 year). Mutually exclusive with expires_at.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
 			},
 			"expires_at": schema.StringAttribute{
 				CustomType:          timetypes.RFC3339Type{},
@@ -100,6 +134,9 @@ func (r *OperatorResource) Schema(_ context.Context, req resource.SchemaRequest,
 				CustomType:          timetypes.GoDurationType{},
 				Optional:            true,
 				MarkdownDescription: "Relative start delay (e.g., '72h' for 3 days). Mutually exclusive with starts_at.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
 			},
 			"starts_at": schema.StringAttribute{
 				CustomType:          timetypes.RFC3339Type{},
@@ -107,18 +144,44 @@ func (r *OperatorResource) Schema(_ context.Context, req resource.SchemaRequest,
 				Computed:            true,
 				MarkdownDescription: "Absolute start timestamp (RFC3339). Can be specified directly or computed from starts_in. Mutually exclusive with starts_in.",
 			},
+			"custom_claims_json": customClaimsJSONAttribute("operator"),
+			"claims_patch_json":  claimsPatchJSONAttribute("operator"),
+			"account_jwts": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "JWTs of accounts issued under this operator, e.g. `[for a in nsc_account.all : a.jwt]`. Not part of the operator JWT and changing it alone does not reissue it; when a plan removes a key from `signing_keys`, a warning lists the accounts whose JWTs were issued by that key. Do not set it from accounts that reference this operator's `jwt` in `operator_jwt`, as that is a dependency cycle.",
+			},
 			"jwt": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "Generated JWT token",
 			},
+			"pin_issued_at": pinIssuedAtAttribute(),
+			"issued_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Time the JWT was issued (`iat` claim). Set it to pin the JWT to a fixed `iat`, see `pin_issued_at`.",
+			},
+			"jwt_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID of the JWT (`jti` claim), a hash of its claims",
+			},
 			"public_key": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "Operator public key (same as subject)",
 			},
+			"server_config": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "nats-server configuration stanza with the `operator` JWT and, when set, the `system_account`. Combine with a resolver configuration such as `provider::nsc::resolver_preload`.",
+			},
 		},
 	}
 }
 
+func (r *OperatorResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = publicKeyIdentitySchema("Operator public key (subject)")
+}
+
 func (r *OperatorResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
 	var data OperatorResourceModel
 
@@ -142,10 +205,121 @@ func (r *OperatorResource) ValidateConfig(ctx context.Context, req resource.Vali
 			"Only one of 'starts_in' or 'starts_at' can be specified.",
 		)
 	}
+
+	resp.Diagnostics.Append(validateIssuedJWTs(data.AccountJWTs, "account_jwts", jwt.AccountClaim)...)
 }
 
 func (r *OperatorResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// No provider configuration needed
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.keys = providerData.Keys
+	r.warnExpiryWithin = providerData.WarnExpiryWithin
+	r.requireExpiry = providerData.RequireExpiry
+	r.policy = providerData.Policy
+	r.audit = providerData.Audit
+}
+
+// ModifyPlan marks the JWT unknown whenever it is reissued, so resources
+// referencing it plan their own updates in the same run.
+func (r *OperatorResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	resp.Diagnostics.Append(requireExpiryPlan(ctx, req, r.requireExpiry, "operator")...)
+	resp.Diagnostics.Append(r.checkPolicyPlan(ctx, req)...)
+	if !jwtPlanned(req) {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var plan, state OperatorResourceModel
+		resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		resp.Diagnostics.Append(removedSigningKeyWarnings(ctx, state.SigningKeys, plan.SigningKeys, plan.AccountJWTs, nkeys.PrefixByteOperator, "account")...)
+
+		// account_jwts is used for checks only, and signing_keys differing
+		// only in order or duplicates make the same JWT, so changes to them
+		// alone keep the JWT
+		checksOnly := []string{"account_jwts"}
+		if sameSigningKeys(ctx, state.SigningKeys, plan.SigningKeys, nkeys.PrefixByteOperator) {
+			checksOnly = append(checksOnly, "signing_keys")
+		}
+		if checksOnlyPlan(req, checksOnly...) {
+			state.AccountJWTs = plan.AccountJWTs
+			state.SigningKeys = plan.SigningKeys
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, &state)...)
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), types.StringUnknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("server_config"), types.StringUnknown())...)
+}
+
+// checkPolicyPlan evaluates the provider policy against the claims of the
+// plan the policy can check: the name and expiry, and custom claims.
+func (r *OperatorResource) checkPolicyPlan(ctx context.Context, req resource.ModifyPlanRequest) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(r.policy) == 0 || req.Plan.Raw.IsNull() {
+		return diags
+	}
+
+	var data OperatorResourceModel
+	diags.Append(req.Plan.Get(ctx, &data)...)
+	expiresIn, expiresAt, expiryUnknown, d := policyPlanExpiry(ctx, req)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	claims := jwt.NewOperatorClaims(planSubject(data.Subject).ValueString())
+	claims.Name = data.Name.ValueString()
+	claims.Expires, d = resolveTimestamp(expiresIn, &expiresAt)
+	if d.HasError() {
+		return diags
+	}
+
+	diags.Append(r.policy.checkPlan(claims, data.CustomClaimsJSON, data.ClaimsPatchJSON, policyUnknown{expiry: expiryUnknown})...)
+	return diags
+}
+
+func (r *OperatorResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 covers the legacy expiry/start attributes (ADR-007).
+		0: {
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				attrs, err := rawStateAttributes(req)
+				if err != nil {
+					resp.Diagnostics.AddError("Unable to Upgrade Operator State", err.Error())
+					return
+				}
+
+				renameStateAttribute(attrs, "expiry", "expires_in")
+				renameStateAttribute(attrs, "start", "starts_in")
+
+				var schemaResp resource.SchemaResponse
+				r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+				resp.DynamicValue, err = upgradedState(attrs, schemaResp.Schema)
+				if err != nil {
+					resp.Diagnostics.AddError("Unable to Upgrade Operator State", err.Error())
+				}
+			},
+		},
+	}
 }
 
 func (r *OperatorResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -175,7 +349,16 @@ func (r *OperatorResource) Create(ctx context.Context, req resource.CreateReques
 	}
 
 	// Get operator seed (issuer) for self-signing from Config
-	operatorSeedStr := config.IssuerSeed.ValueString()
+	issuerSeed, diags := issuerSeedValue(config.IssuerSeed, data.IssuerSeedEnv)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	operatorSeedStr, err := r.keys.seed(issuerSeed.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid operator seed", err.Error())
+		return
+	}
 	if operatorSeedStr == "" {
 		resp.Diagnostics.AddError(
 			"Missing operator seed",
@@ -279,22 +462,12 @@ func (r *OperatorResource) Create(ctx context.Context, req resource.CreateReques
 
 	// Add signing keys if provided
 	if !data.SigningKeys.IsNull() && !data.SigningKeys.IsUnknown() {
-		var signingKeys []string
-		resp.Diagnostics.Append(data.SigningKeys.ElementsAs(ctx, &signingKeys, false)...)
+		signingKeys, diags := signingKeyPublicKeys(ctx, data.SigningKeys, nkeys.PrefixByteOperator)
+		resp.Diagnostics.Append(diags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
-
-		for _, key := range signingKeys {
-			if !strings.HasPrefix(key, "O") {
-				resp.Diagnostics.AddError(
-					"Invalid signing key",
-					fmt.Sprintf("Signing keys must be operator public keys (start with 'O'), got: %s", key),
-				)
-				return
-			}
-			operatorClaims.SigningKeys.Add(key)
-		}
+		operatorClaims.SigningKeys.Add(signingKeys...)
 	}
 
 	// Set system account if provided
@@ -311,19 +484,37 @@ func (r *OperatorResource) Create(ctx context.Context, req resource.CreateReques
 	}
 
 	// Sign the JWT
-	operatorJWT, err := operatorClaims.Encode(operatorKP)
+	issuedAt, diags := pinnedIssuedAt(data.PinIssuedAt, config.IssuedAt, operatorClaims)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	operatorJWT, err := encodeClaims(ctx, operatorClaims, operatorKP, nil, data.CustomClaimsJSON, data.ClaimsPatchJSON, issuedAt)
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to encode operator JWT", err.Error())
 		return
 	}
+	resp.Diagnostics.Append(r.policy.checkToken(operatorJWT, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Set computed values
 	data.ID = types.StringValue(operatorPubKey)
 	data.PublicKey = types.StringValue(operatorPubKey)
 	data.JWT = types.StringValue(operatorJWT)
+	data.IssuedAt, data.JWTID, err = jwtIssueValues(operatorJWT)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode operator JWT", err.Error())
+		return
+	}
+	data.ServerConfig = types.StringValue(operatorServerConfig(operatorJWT, data.SystemAccount.ValueString()))
+
+	resp.Diagnostics.Append(r.audit.record("nsc_operator", "create", operatorJWT)...)
 
 	tflog.Trace(ctx, "created operator resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
 }
 
 func (r *OperatorResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -336,6 +527,24 @@ func (r *OperatorResource) Read(ctx context.Context, req resource.ReadRequest, r
 
 	// For state-only storage, nothing to read externally
 	// JWT remains valid in state
+
+	// Fill in server_config for states written before it existed
+	if data.ServerConfig.IsNull() && !data.JWT.IsNull() {
+		data.ServerConfig = types.StringValue(operatorServerConfig(data.JWT.ValueString(), data.SystemAccount.ValueString()))
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	}
+
+	// Fill in issued_at and jwt_id for states written before they existed
+	if data.JWTID.IsNull() && !data.JWT.IsNull() {
+		issuedAt, jwtID, err := jwtIssueValues(data.JWT.ValueString())
+		if err == nil {
+			data.IssuedAt, data.JWTID = issuedAt, jwtID
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		}
+	}
+
+	resp.Diagnostics.Append(expiryWarning("operator", data.Name.ValueString(), data.Subject.ValueString(), data.ExpiresAt, r.warnExpiryWithin)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
 }
 
 func (r *OperatorResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -346,6 +555,15 @@ func (r *OperatorResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	// ModifyPlan keeps the JWT when only account_jwts or the order of
+	// signing_keys changes
+	if !data.JWT.IsUnknown() {
+		tflog.Trace(ctx, "updated operator resource without reissuing the JWT")
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+		return
+	}
+
 	// Get current state to preserve immutable fields
 	var state OperatorResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -360,15 +578,32 @@ func (r *OperatorResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
-	// Get operator public key from state and seed from config (both immutable)
+	// Get operator public key from state and seed from config or, with
+	// issuer_seed_env, from the environment
 	operatorPubKey := state.Subject.ValueString()
-	operatorSeedStr := config.IssuerSeed.ValueString()
+	issuerSeed, diags := issuerSeedValue(config.IssuerSeed, data.IssuerSeedEnv)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	operatorSeedStr, err := r.keys.seed(issuerSeed.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to restore operator keypair", err.Error())
+		return
+	}
 
 	operatorKP, err := nkeys.FromSeed([]byte(operatorSeedStr))
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to restore operator keypair", err.Error())
 		return
 	}
+	if verifyPubKey, err := operatorKP.PublicKey(); err != nil || verifyPubKey != operatorPubKey {
+		resp.Diagnostics.AddError(
+			"Key mismatch",
+			fmt.Sprintf("Issuer seed does not produce the operator public key %s", operatorPubKey),
+		)
+		return
+	}
 
 	// Create new operator claims with updated values
 	operatorClaims := jwt.NewOperatorClaims(operatorPubKey)
@@ -434,22 +669,12 @@ func (r *OperatorResource) Update(ctx context.Context, req resource.UpdateReques
 
 	// Add signing keys if provided
 	if !data.SigningKeys.IsNull() && !data.SigningKeys.IsUnknown() {
-		var signingKeys []string
-		resp.Diagnostics.Append(data.SigningKeys.ElementsAs(ctx, &signingKeys, false)...)
+		signingKeys, diags := signingKeyPublicKeys(ctx, data.SigningKeys, nkeys.PrefixByteOperator)
+		resp.Diagnostics.Append(diags...)
 		if resp.Diagnostics.HasError() {
 			return
 		}
-
-		for _, key := range signingKeys {
-			if !strings.HasPrefix(key, "O") {
-				resp.Diagnostics.AddError(
-					"Invalid signing key",
-					fmt.Sprintf("Signing keys must be operator public keys (start with 'O'), got: %s", key),
-				)
-				return
-			}
-			operatorClaims.SigningKeys.Add(key)
-		}
+		operatorClaims.SigningKeys.Add(signingKeys...)
 	}
 
 	// Set system account if provided
@@ -466,20 +691,49 @@ func (r *OperatorResource) Update(ctx context.Context, req resource.UpdateReques
 	}
 
 	// Sign the JWT
-	operatorJWT, err := operatorClaims.Encode(operatorKP)
+	issuedAt, diags := pinnedIssuedAt(data.PinIssuedAt, config.IssuedAt, operatorClaims)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	operatorJWT, err := encodeClaims(ctx, operatorClaims, operatorKP, nil, data.CustomClaimsJSON, data.ClaimsPatchJSON, issuedAt)
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to encode operator JWT", err.Error())
 		return
 	}
+	resp.Diagnostics.Append(r.policy.checkToken(operatorJWT, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the stored JWT when no claim changed, so its iat and jti, and
+	// everything derived from the token, stay the same. A pinned iat makes
+	// the same JWT anyway.
+	stored := state.JWT.ValueString()
+	if issuedAt == 0 && sameJWTClaims(stored, operatorJWT) {
+		operatorJWT = stored
+	}
+	reissued := operatorJWT != stored
 
 	// Update JWT while preserving immutable fields
 	data.ID = state.ID
 	data.PublicKey = state.PublicKey
 	data.Subject = state.Subject
 	data.JWT = types.StringValue(operatorJWT)
+	data.IssuedAt, data.JWTID, err = jwtIssueValues(operatorJWT)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode operator JWT", err.Error())
+		return
+	}
+	data.ServerConfig = types.StringValue(operatorServerConfig(operatorJWT, data.SystemAccount.ValueString()))
+
+	if reissued {
+		resp.Diagnostics.Append(r.audit.record("nsc_operator", "update", operatorJWT)...)
+	}
 
 	tflog.Trace(ctx, "updated operator resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
 }
 
 func (r *OperatorResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -493,3 +747,14 @@ func (r *OperatorResource) Delete(ctx context.Context, req resource.DeleteReques
 	// Nothing to clean up - all data is in state
 	tflog.Trace(ctx, "deleted operator resource")
 }
+
+// operatorServerConfig renders the operator stanza of a nats-server
+// configuration.
+func operatorServerConfig(operatorJWT, systemAccount string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "operator: %s\n", operatorJWT)
+	if systemAccount != "" {
+		fmt.Fprintf(&b, "system_account: %s\n", systemAccount)
+	}
+	return b.String()
+}
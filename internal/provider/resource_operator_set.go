@@ -0,0 +1,388 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ resource.Resource = &OperatorSetResource{}
+var _ resource.ResourceWithConfigure = &OperatorSetResource{}
+var _ resource.ResourceWithModifyPlan = &OperatorSetResource{}
+
+const systemAccountInfoURL = "https://docs.nats.io/nats-server/configuration/sys_accounts"
+
+func NewOperatorSetResource() resource.Resource {
+	return &OperatorSetResource{}
+}
+
+// OperatorSetResource bootstraps a trust root: an operator with a signing key,
+// a system account and a system user. The keys are generated once and kept in
+// state; the JWTs are reissued when the names change.
+type OperatorSetResource struct {
+	keys          *keypairCache
+	cipher        *stateCipher
+	audit         *auditLog
+	requireExpiry []string
+	policy        claimsPolicy
+}
+
+type OperatorSetResourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	Name              types.String `tfsdk:"name"`
+	SystemAccountName types.String `tfsdk:"system_account_name"`
+	SystemUserName    types.String `tfsdk:"system_user_name"`
+
+	OperatorPublicKey      types.String `tfsdk:"operator_public_key"`
+	OperatorSeed           types.String `tfsdk:"operator_seed"`
+	OperatorSigningKey     types.String `tfsdk:"operator_signing_key"`
+	OperatorSigningKeySeed types.String `tfsdk:"operator_signing_key_seed"`
+	OperatorJWT            types.String `tfsdk:"operator_jwt"`
+	ServerConfig           types.String `tfsdk:"server_config"`
+
+	SystemAccountPublicKey types.String `tfsdk:"system_account_public_key"`
+	SystemAccountSeed      types.String `tfsdk:"system_account_seed"`
+	SystemAccountJWT       types.String `tfsdk:"system_account_jwt"`
+
+	SystemUserPublicKey types.String `tfsdk:"system_user_public_key"`
+	SystemUserSeed      types.String `tfsdk:"system_user_seed"`
+	SystemUserJWT       types.String `tfsdk:"system_user_jwt"`
+	SystemUserCreds     types.String `tfsdk:"system_user_creds"`
+}
+
+func (r *OperatorSetResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_operator_set"
+}
+
+func (r *OperatorSetResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	// Keys are generated on create and never change afterwards
+	key := func(description string, sensitive bool) schema.StringAttribute {
+		return schema.StringAttribute{
+			Computed:            true,
+			Sensitive:           sensitive,
+			MarkdownDescription: description,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		}
+	}
+	computed := func(description string, sensitive bool) schema.StringAttribute {
+		return schema.StringAttribute{
+			Computed:            true,
+			Sensitive:           sensitive,
+			MarkdownDescription: description,
+		}
+	}
+	name := func(description, defaultValue string) schema.StringAttribute {
+		return schema.StringAttribute{
+			Optional:            true,
+			Computed:            true,
+			Default:             stringdefault.StaticString(defaultValue),
+			MarkdownDescription: description,
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Bootstraps a complete trust root in one resource: an operator with a signing key, a system account with the standard monitoring exports, signed by the operator signing key, and a system user. " +
+			"All keys are generated on create and kept in state, like with `nsc_nkey`, with the seeds encrypted when the provider has a `state_encryption_key`; changing a name reissues the JWTs with the same keys. " +
+			"Use the separate `nsc_nkey`, `nsc_operator`, `nsc_account` and `nsc_user` resources when the trust root needs more control.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Operator public key (same as operator_public_key)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Operator name",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"system_account_name": name("System account name. Defaults to `SYS`.", "SYS"),
+			"system_user_name":    name("System user name. Defaults to `sys`.", "sys"),
+
+			"operator_public_key":       key("Operator public key", false),
+			"operator_seed":             key("Operator seed", true),
+			"operator_signing_key":      key("Public key of the operator signing key, which signs the system account", false),
+			"operator_signing_key_seed": key("Seed of the operator signing key, for signing further accounts with `nsc_account`", true),
+			"operator_jwt":              computed("Operator JWT", false),
+			"server_config":             computed("nats-server configuration stanza with the `operator` JWT and the `system_account`. Combine with a resolver configuration such as `provider::nsc::resolver_preload`.", false),
+
+			"system_account_public_key": key("System account public key", false),
+			"system_account_seed":       key("System account seed, for signing further system users with `nsc_user`", true),
+			"system_account_jwt":        computed("System account JWT", false),
+
+			"system_user_public_key": key("System user public key", false),
+			"system_user_seed":       key("System user seed", true),
+			"system_user_jwt":        computed("System user JWT", false),
+			"system_user_creds":      computed("System user credentials file content", true),
+		},
+	}
+}
+
+func (r *OperatorSetResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.keys = providerData.Keys
+	r.cipher = providerData.StateCipher
+	r.audit = providerData.Audit
+	r.requireExpiry = providerData.RequireExpiry
+	r.policy = providerData.Policy
+}
+
+// ModifyPlan marks the JWT outputs unknown whenever they are reissued, so
+// resources referencing them plan their own updates in the same run.
+func (r *OperatorSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	resp.Diagnostics.Append(requireExpiryUnsupported(req, r.requireExpiry, "nsc_operator_set", "operator", "account", "user")...)
+	if !jwtPlanned(req) {
+		return
+	}
+
+	for _, name := range []string{"operator_jwt", "server_config", "system_account_jwt", "system_user_jwt", "system_user_creds"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(name), types.StringUnknown())...)
+	}
+}
+
+func (r *OperatorSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data OperatorSetResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := []struct {
+		create    func() (nkeys.KeyPair, error)
+		publicKey *types.String
+		seed      *types.String
+	}{
+		{nkeys.CreateOperator, &data.OperatorPublicKey, &data.OperatorSeed},
+		{nkeys.CreateOperator, &data.OperatorSigningKey, &data.OperatorSigningKeySeed},
+		{nkeys.CreateAccount, &data.SystemAccountPublicKey, &data.SystemAccountSeed},
+		{nkeys.CreateUser, &data.SystemUserPublicKey, &data.SystemUserSeed},
+	}
+	for _, key := range keys {
+		kp, err := key.create()
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to generate key pair", err.Error())
+			return
+		}
+		publicKey, err := kp.PublicKey()
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to get public key", err.Error())
+			return
+		}
+		seed, err := kp.Seed()
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to get seed", err.Error())
+			return
+		}
+		stateSeed, err := r.cipher.encrypt(string(seed))
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to encrypt seed", err.Error())
+			return
+		}
+		*key.publicKey = types.StringValue(publicKey)
+		*key.seed = types.StringValue(stateSeed)
+	}
+
+	resp.Diagnostics.Append(r.issue(ctx, &data, "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "created operator set resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OperatorSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data OperatorSetResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// For state-only storage, nothing to read externally
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OperatorSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state OperatorSetResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Keep the keys of the state
+	data.OperatorPublicKey, data.OperatorSeed = state.OperatorPublicKey, state.OperatorSeed
+	data.OperatorSigningKey, data.OperatorSigningKeySeed = state.OperatorSigningKey, state.OperatorSigningKeySeed
+	data.SystemAccountPublicKey, data.SystemAccountSeed = state.SystemAccountPublicKey, state.SystemAccountSeed
+	data.SystemUserPublicKey, data.SystemUserSeed = state.SystemUserPublicKey, state.SystemUserSeed
+
+	resp.Diagnostics.Append(r.issue(ctx, &data, "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "updated operator set resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *OperatorSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data OperatorSetResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted operator set resource")
+}
+
+// issue signs the operator, system account and system user JWTs with the keys
+// of the model and sets the JWT outputs.
+func (r *OperatorSetResource) issue(ctx context.Context, data *OperatorSetResourceModel, action string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	tokens, err := issueOperatorSet(ctx, r.keys, data)
+	if err != nil {
+		diags.AddError("Failed to issue operator set", err.Error())
+		return diags
+	}
+	for _, token := range []string{tokens.operator, tokens.systemAccount, tokens.systemUser} {
+		diags.Append(r.policy.checkToken(token, false)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+	userSeed, err := r.keys.seed(data.SystemUserSeed.ValueString())
+	if err != nil {
+		diags.AddError("Invalid system user seed", err.Error())
+		return diags
+	}
+
+	data.ID = data.OperatorPublicKey
+	data.OperatorJWT = types.StringValue(tokens.operator)
+	data.ServerConfig = types.StringValue(operatorServerConfig(tokens.operator, data.SystemAccountPublicKey.ValueString()))
+	data.SystemAccountJWT = types.StringValue(tokens.systemAccount)
+	data.SystemUserJWT = types.StringValue(tokens.systemUser)
+	data.SystemUserCreds = types.StringValue(formatCreds(tokens.systemUser, userSeed))
+
+	diags.Append(r.audit.record("nsc_operator_set", action, tokens.operator)...)
+	diags.Append(r.audit.record("nsc_operator_set", action, tokens.systemAccount)...)
+	diags.Append(r.audit.record("nsc_operator_set", action, tokens.systemUser)...)
+
+	return diags
+}
+
+// operatorSetTokens are the JWTs of an operator set.
+type operatorSetTokens struct {
+	operator      string
+	systemAccount string
+	systemUser    string
+}
+
+// issueOperatorSet signs the JWTs of an operator set with the keys of the
+// model.
+func issueOperatorSet(ctx context.Context, keys *keypairCache, data *OperatorSetResourceModel) (operatorSetTokens, error) {
+	var tokens operatorSetTokens
+
+	operatorKP, err := keys.fromSeed(data.OperatorSeed.ValueString())
+	if err != nil {
+		return tokens, fmt.Errorf("invalid operator seed: %w", err)
+	}
+	signingKP, err := keys.fromSeed(data.OperatorSigningKeySeed.ValueString())
+	if err != nil {
+		return tokens, fmt.Errorf("invalid operator signing key seed: %w", err)
+	}
+	accountKP, err := keys.fromSeed(data.SystemAccountSeed.ValueString())
+	if err != nil {
+		return tokens, fmt.Errorf("invalid system account seed: %w", err)
+	}
+
+	operatorClaims := jwt.NewOperatorClaims(data.OperatorPublicKey.ValueString())
+	operatorClaims.Name = data.Name.ValueString()
+	operatorClaims.SigningKeys.Add(data.OperatorSigningKey.ValueString())
+	operatorClaims.SystemAccount = data.SystemAccountPublicKey.ValueString()
+	if tokens.operator, err = encodeClaims(ctx, operatorClaims, operatorKP, nil, types.StringNull(), types.StringNull(), 0); err != nil {
+		return tokens, fmt.Errorf("failed to encode operator JWT: %w", err)
+	}
+
+	accountClaims := jwt.NewAccountClaims(data.SystemAccountPublicKey.ValueString())
+	accountClaims.Name = data.SystemAccountName.ValueString()
+	accountClaims.Exports = systemAccountExports()
+	if tokens.systemAccount, err = encodeClaims(ctx, accountClaims, signingKP, nil, types.StringNull(), types.StringNull(), 0); err != nil {
+		return tokens, fmt.Errorf("failed to encode system account JWT: %w", err)
+	}
+
+	userClaims := jwt.NewUserClaims(data.SystemUserPublicKey.ValueString())
+	userClaims.Name = data.SystemUserName.ValueString()
+	if tokens.systemUser, err = encodeClaims(ctx, userClaims, accountKP, nil, types.StringNull(), types.StringNull(), 0); err != nil {
+		return tokens, fmt.Errorf("failed to encode system user JWT: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// systemAccountExports returns the monitoring exports nsc adds to the system
+// account, through which other accounts can query their own server state.
+func systemAccountExports() jwt.Exports {
+	return jwt.Exports{
+		&jwt.Export{
+			Name:                 "account-monitoring-streams",
+			Subject:              "$SYS.ACCOUNT.*.>",
+			Type:                 jwt.Stream,
+			AccountTokenPosition: 3,
+			Info: jwt.Info{
+				Description: "Account specific monitoring stream",
+				InfoURL:     systemAccountInfoURL,
+			},
+		},
+		&jwt.Export{
+			Name:                 "account-monitoring-services",
+			Subject:              "$SYS.REQ.ACCOUNT.*.*",
+			Type:                 jwt.Service,
+			ResponseType:         jwt.ResponseTypeStream,
+			AccountTokenPosition: 4,
+			Info: jwt.Info{
+				Description: "Request account specific monitoring services for: SUBSZ, CONNZ, LEAFZ, JSZ and INFO",
+				InfoURL:     systemAccountInfoURL,
+			},
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestIssueOperatorSet(t *testing.T) {
+	data := OperatorSetResourceModel{
+		Name:              types.StringValue("Acme"),
+		SystemAccountName: types.StringValue("SYS"),
+		SystemUserName:    types.StringValue("sys"),
+	}
+	for _, key := range []struct {
+		create    func() (nkeys.KeyPair, error)
+		publicKey *types.String
+		seed      *types.String
+	}{
+		{nkeys.CreateOperator, &data.OperatorPublicKey, &data.OperatorSeed},
+		{nkeys.CreateOperator, &data.OperatorSigningKey, &data.OperatorSigningKeySeed},
+		{nkeys.CreateAccount, &data.SystemAccountPublicKey, &data.SystemAccountSeed},
+		{nkeys.CreateUser, &data.SystemUserPublicKey, &data.SystemUserSeed},
+	} {
+		kp, _ := key.create()
+		publicKey, _ := kp.PublicKey()
+		seed, _ := kp.Seed()
+		*key.publicKey = types.StringValue(publicKey)
+		*key.seed = types.StringValue(string(seed))
+	}
+
+	tokens, err := issueOperatorSet(context.Background(), nil, &data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	operator, err := jwt.DecodeOperatorClaims(tokens.operator)
+	if err != nil {
+		t.Fatalf("failed to decode operator JWT: %v", err)
+	}
+	if operator.Name != "Acme" || operator.Issuer != data.OperatorPublicKey.ValueString() {
+		t.Errorf("unexpected operator claims: %+v", operator)
+	}
+	if !operator.SigningKeys.Contains(data.OperatorSigningKey.ValueString()) {
+		t.Errorf("expected signing key %s in operator claims", data.OperatorSigningKey.ValueString())
+	}
+	if operator.SystemAccount != data.SystemAccountPublicKey.ValueString() {
+		t.Errorf("expected system account %s, got %s", data.SystemAccountPublicKey.ValueString(), operator.SystemAccount)
+	}
+
+	account, err := jwt.DecodeAccountClaims(tokens.systemAccount)
+	if err != nil {
+		t.Fatalf("failed to decode system account JWT: %v", err)
+	}
+	if account.Name != "SYS" || account.Issuer != data.OperatorSigningKey.ValueString() {
+		t.Errorf("expected the system account to be named SYS and issued by the signing key, got %+v", account)
+	}
+	var vr jwt.ValidationResults
+	account.Validate(&vr)
+	if vr.IsBlocking(true) {
+		t.Errorf("system account claims are invalid: %v", vr.Errors())
+	}
+	if len(account.Exports) != 2 {
+		t.Errorf("expected 2 exports, got %d", len(account.Exports))
+	}
+
+	user, err := jwt.DecodeUserClaims(tokens.systemUser)
+	if err != nil {
+		t.Fatalf("failed to decode system user JWT: %v", err)
+	}
+	if user.Name != "sys" || user.Issuer != data.SystemAccountPublicKey.ValueString() {
+		t.Errorf("expected the system user to be named sys and issued by the system account, got %+v", user)
+	}
+}
+
+func TestAccOperatorSetResource(t *testing.T) {
+	var operatorPubKey string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOperatorSetResourceConfig("Acme"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckOperatorPublicKeyFormat("nsc_operator_set.test", "operator_public_key"),
+					resource.TestCheckResourceAttrPair("nsc_operator_set.test", "id", "nsc_operator_set.test", "operator_public_key"),
+					resource.TestCheckResourceAttr("nsc_operator_set.test", "system_account_name", "SYS"),
+					resource.TestCheckResourceAttr("nsc_operator_set.test", "system_user_name", "sys"),
+					testAccCheckUserCredsFormat("nsc_operator_set.test", "system_user_creds"),
+					func(s *terraform.State) error {
+						operatorPubKey = s.RootModule().Resources["nsc_operator_set.test"].Primary.Attributes["operator_public_key"]
+						return nil
+					},
+				),
+			},
+			{
+				// Renaming reissues the JWTs with the same keys
+				Config: testAccOperatorSetResourceConfig("Renamed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					func(s *terraform.State) error {
+						attrs := s.RootModule().Resources["nsc_operator_set.test"].Primary.Attributes
+						if attrs["operator_public_key"] != operatorPubKey {
+							return fmt.Errorf("expected operator key %s to be kept, got %s", operatorPubKey, attrs["operator_public_key"])
+						}
+						operator, err := jwt.DecodeOperatorClaims(attrs["operator_jwt"])
+						if err != nil {
+							return err
+						}
+						if operator.Name != "Renamed" {
+							return fmt.Errorf("expected operator name Renamed, got %s", operator.Name)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccOperatorSetResourceConfig(name string) string {
+	return fmt.Sprintf(`
+resource "nsc_operator_set" "test" {
+  name = %q
+}
+`, name)
+}
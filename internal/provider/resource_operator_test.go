@@ -1,12 +1,20 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nats-io/jwt/v2"
 	"github.com/nats-io/nkeys"
 )
 
@@ -25,6 +33,8 @@ func TestAccOperatorResource_basic(t *testing.T) {
 					resource.TestCheckResourceAttrSet("nsc_operator.test", "public_key"),
 					testAccCheckOperatorPublicKeyFormat("nsc_operator.test", "public_key"),
 					testAccCheckOperatorPublicKeyFormat("nsc_operator.test", "subject"),
+					resource.TestMatchResourceAttr("nsc_operator.test", "server_config", regexp.MustCompile(`^operator: eyJ[^\n]+\n$`)),
+					testAccCheckJWTIssueValues("nsc_operator.test", "jwt"),
 				),
 			},
 			// Update and Read testing
@@ -32,6 +42,7 @@ func TestAccOperatorResource_basic(t *testing.T) {
 				Config: testAccOperatorResourceConfig("UpdatedOperator"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("nsc_operator.test", "name", "UpdatedOperator"),
+					testAccCheckJWTIssueValues("nsc_operator.test", "jwt"),
 				),
 			},
 		},
@@ -52,6 +63,11 @@ func TestAccOperatorResource_withSigningKey(t *testing.T) {
 					resource.TestCheckResourceAttrSet("nsc_operator.test", "signing_keys.0"),
 				),
 			},
+			// The same signing key given as a seed
+			{
+				Config: testAccOperatorResourceConfigWithSigningKeySeed("TestOperator"),
+				Check:  testAccCheckOperatorSigningKey("nsc_operator.test", "nsc_nkey.signing_key"),
+			},
 		},
 	})
 }
@@ -96,6 +112,67 @@ resource "nsc_operator" "test" {
 `, name)
 }
 
+func TestAccOperatorResource_accountJWTs(t *testing.T) {
+	var operatorJWT string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOperatorResourceConfigWithAccountJWTs("Orders"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_operator.test", "account_jwts.#", "1"),
+					resource.TestCheckResourceAttrWith("nsc_operator.test", "jwt", func(value string) error {
+						operatorJWT = value
+						return nil
+					}),
+				),
+			},
+			// A change to the accounts alone keeps the operator JWT
+			{
+				Config: testAccOperatorResourceConfigWithAccountJWTs("Payments"),
+				Check: resource.TestCheckResourceAttrWith("nsc_operator.test", "jwt", func(value string) error {
+					if value != operatorJWT {
+						return fmt.Errorf("expected the operator JWT to be kept")
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func testAccOperatorResourceConfigWithAccountJWTs(accountName string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "signing_key" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_account" "test" {
+  name        = %[1]q
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.signing_key.seed
+}
+
+resource "nsc_operator" "test" {
+  name         = "TestOperator"
+  subject      = nsc_nkey.operator.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = [nsc_nkey.signing_key.public_key]
+  account_jwts = [nsc_account.test.jwt]
+}
+`, accountName)
+}
+
 func testAccOperatorResourceConfigWithSigningKey(name string) string {
 	return fmt.Sprintf(`
 resource "nsc_nkey" "operator" {
@@ -115,6 +192,50 @@ resource "nsc_operator" "test" {
 `, name)
 }
 
+func testAccOperatorResourceConfigWithSigningKeySeed(name string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "signing_key" {
+  type = "operator"
+}
+
+resource "nsc_operator" "test" {
+  name         = %[1]q
+  subject      = nsc_nkey.operator.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = [nsc_nkey.signing_key.seed]
+}
+`, name)
+}
+
+// testAccCheckOperatorSigningKey checks that the operator JWT lists the
+// public key of the nkey, whether it was configured as public key or seed.
+func testAccCheckOperatorSigningKey(operatorName, nkeyName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		operator, ok := s.RootModule().Resources[operatorName]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", operatorName)
+		}
+		nkey, ok := s.RootModule().Resources[nkeyName]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", nkeyName)
+		}
+
+		claims, err := jwt.DecodeOperatorClaims(operator.Primary.Attributes["jwt"])
+		if err != nil {
+			return err
+		}
+		publicKey := nkey.Primary.Attributes["public_key"]
+		if len(claims.SigningKeys) != 1 || claims.SigningKeys[0] != publicKey {
+			return fmt.Errorf("expected signing keys [%s], got %v", publicKey, claims.SigningKeys)
+		}
+		return nil
+	}
+}
+
 func testAccOperatorResourceConfigWithExpiry(name, expiry, start string) string {
 	return fmt.Sprintf(`
 resource "nsc_nkey" "operator" {
@@ -187,3 +308,307 @@ func testAccCheckOperatorSeedFormat(resourceName, attrName string) resource.Test
 		return nil
 	}
 }
+
+// testAccCheckJWTIssueValues checks that issued_at and jwt_id match the iat
+// and jti claims of the JWT in the given attribute.
+func testAccCheckJWTIssueValues(resourceName, jwtAttr string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+
+		claims, err := jwt.Decode(rs.Primary.Attributes[jwtAttr])
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", jwtAttr, err)
+		}
+		claimsData := claims.Claims()
+
+		issuedAt := time.Unix(claimsData.IssuedAt, 0).UTC().Format(time.RFC3339)
+		if got := rs.Primary.Attributes["issued_at"]; got != issuedAt {
+			return fmt.Errorf("expected issued_at %q, got %q", issuedAt, got)
+		}
+		if got := rs.Primary.Attributes["jwt_id"]; got != claimsData.ID {
+			return fmt.Errorf("expected jwt_id %q, got %q", claimsData.ID, got)
+		}
+
+		return nil
+	}
+}
+
+func TestOperatorServerConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		systemAccount string
+		want          string
+	}{
+		{
+			name: "operator only",
+			want: "operator: eyJ.e30.sig\n",
+		},
+		{
+			name:          "with system account",
+			systemAccount: "ADLGEVANYDKDQ6WYXPNBEGVUURXZY4LLLK5BJPOUDN6NGNXLNH4ATPWR",
+			want:          "operator: eyJ.e30.sig\nsystem_account: ADLGEVANYDKDQ6WYXPNBEGVUURXZY4LLLK5BJPOUDN6NGNXLNH4ATPWR\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := operatorServerConfig("eyJ.e30.sig", tt.systemAccount); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOperatorResource_upgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	r := &OperatorResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	// Legacy state using the deprecated expiry/start attributes
+	req := fwresource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{
+			JSON: []byte(`{
+				"id": "OCKGS7HHNNVAU3FZCSWS3ZQWT4UWJQ5ZSN4YVFVGMKTOKG6O4XLPCUSH",
+				"name": "Legacy",
+				"subject": "OCKGS7HHNNVAU3FZCSWS3ZQWT4UWJQ5ZSN4YVFVGMKTOKG6O4XLPCUSH",
+				"expiry": "8760h",
+				"start": null,
+				"jwt": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig",
+				"public_key": "OCKGS7HHNNVAU3FZCSWS3ZQWT4UWJQ5ZSN4YVFVGMKTOKG6O4XLPCUSH"
+			}`),
+		},
+	}
+	var resp fwresource.UpgradeStateResponse
+	r.UpgradeState(ctx)[0].StateUpgrader(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	raw, err := resp.DynamicValue.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+	if err != nil {
+		t.Fatalf("upgraded state does not match schema: %s", err)
+	}
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: raw}
+
+	var data OperatorResourceModel
+	if diags := state.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read upgraded state: %v", diags)
+	}
+
+	if data.ExpiresIn.ValueString() != "8760h" {
+		t.Errorf("expected expires_in = 8760h, got %q", data.ExpiresIn.ValueString())
+	}
+	if !data.StartsIn.IsNull() {
+		t.Errorf("expected starts_in to be null, got %q", data.StartsIn.ValueString())
+	}
+	if data.Name.ValueString() != "Legacy" {
+		t.Errorf("expected name = Legacy, got %q", data.Name.ValueString())
+	}
+}
+
+func TestAccOperatorResource_customClaimsJSON(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOperatorResourceConfigWithCustomClaims(`jsonencode({
+    org  = { name = "Example Corp" }
+    nats = { account_server_url = "https://accounts.example.com/jwt/v1" }
+  })`),
+				Check: resource.TestCheckResourceAttrWith("nsc_operator.test", "jwt", func(value string) error {
+					claims, err := jwt.DecodeOperatorClaims(value)
+					if err != nil {
+						return err
+					}
+					if claims.AccountServerURL != "https://accounts.example.com/jwt/v1" {
+						return fmt.Errorf("expected custom account server URL, got %q", claims.AccountServerURL)
+					}
+					return nil
+				}),
+			},
+			{
+				Config:      testAccOperatorResourceConfigWithCustomClaims(`"[]"`),
+				ExpectError: regexp.MustCompile(`custom claims must be a JSON object`),
+			},
+		},
+	})
+}
+
+func TestAccOperatorResource_claimsPatchJSON(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccOperatorResourceConfigWithClaimsPatch(`jsonencode({
+    nats = { account_server_url = "https://accounts.example.com/jwt/v1", assert_server_version = "2.11.0" }
+  })`),
+				Check: resource.TestCheckResourceAttrWith("nsc_operator.test", "jwt", func(value string) error {
+					claims, err := jwt.DecodeOperatorClaims(value)
+					if err != nil {
+						return err
+					}
+					if claims.AccountServerURL != "https://accounts.example.com/jwt/v1" || claims.AssertServerVersion != "2.11.0" {
+						return fmt.Errorf("expected patched claims, got %q and %q", claims.AccountServerURL, claims.AssertServerVersion)
+					}
+					return nil
+				}),
+			},
+			{
+				Config:      testAccOperatorResourceConfigWithClaimsPatch(`jsonencode({ nats = { type = "account" } })`),
+				ExpectError: regexp.MustCompile(`change the claim type`),
+			},
+			{
+				Config:      testAccOperatorResourceConfigWithClaimsPatch(`"[]"`),
+				ExpectError: regexp.MustCompile(`claims patch must be a JSON object`),
+			},
+		},
+	})
+}
+
+func testAccOperatorResourceConfigWithClaimsPatch(patch string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_operator" "test" {
+  name              = "TestOperator"
+  subject           = nsc_nkey.operator.public_key
+  issuer_seed       = nsc_nkey.operator.seed
+  claims_patch_json = %[1]s
+}
+`, patch)
+}
+
+func testAccOperatorResourceConfigWithCustomClaims(customClaims string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_operator" "test" {
+  name               = "TestOperator"
+  subject            = nsc_nkey.operator.public_key
+  issuer_seed        = nsc_nkey.operator.seed
+  custom_claims_json = %[1]s
+}
+`, customClaims)
+}
+
+func TestOperatorResource_modifyPlanAccountJWTs(t *testing.T) {
+	ctx := context.Background()
+	r := &OperatorResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	signingKP, _ := nkeys.CreateOperator()
+	signingPubKey, _ := signingKP.PublicKey()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+
+	accountClaims := jwt.NewAccountClaims(accountPubKey)
+	accountClaims.Name = "Orders"
+	accountJWT, err := accountClaims.Encode(signingKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := func(signingKeys, accountJWTs string, computed bool) tftypes.Value {
+		attrs := fmt.Sprintf(`"name": "TestOperator", "subject": %q, "signing_keys": %s, "account_jwts": %s`, operatorPubKey, signingKeys, accountJWTs)
+		if computed {
+			attrs += fmt.Sprintf(`, "id": %[1]q, "public_key": %[1]q, "jwt": "eyJ.e30.sig", "issued_at": "2025-01-01T00:00:00Z", "jwt_id": "ID", "server_config": "operator: eyJ.e30.sig\n"`, operatorPubKey)
+		}
+		return testRawValue(t, schemaResp.Schema, "{"+attrs+"}")
+	}
+	// planned marks the computed attributes unknown, as the framework does
+	// for an update
+	planned := func(v tftypes.Value) tftypes.Value {
+		v, err := tftypes.Transform(v, func(p *tftypes.AttributePath, v tftypes.Value) (tftypes.Value, error) {
+			switch p.String() {
+			case `AttributeName("id")`, `AttributeName("public_key")`, `AttributeName("jwt")`, `AttributeName("issued_at")`, `AttributeName("jwt_id")`, `AttributeName("server_config")`, `AttributeName("expires_at")`, `AttributeName("starts_at")`:
+				return tftypes.NewValue(v.Type(), tftypes.UnknownValue), nil
+			}
+			return v, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	// Only account_jwts changes: the JWT is kept
+	state := value(fmt.Sprintf("[%q]", signingPubKey), "[]", true)
+	config := value(fmt.Sprintf("[%q]", signingPubKey), fmt.Sprintf("[%q]", accountJWT), false)
+	req := fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config},
+		State:  tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+		Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: planned(value(fmt.Sprintf("[%q]", signingPubKey), fmt.Sprintf("[%q]", accountJWT), true))},
+	}
+	resp := fwresource.ModifyPlanResponse{Plan: req.Plan}
+	r.ModifyPlan(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	var data OperatorResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &data)...)
+	if data.JWT.ValueString() != "eyJ.e30.sig" {
+		t.Errorf("expected the JWT to be kept, got %s", data.JWT)
+	}
+	if len(data.AccountJWTs.Elements()) != 1 {
+		t.Errorf("expected planned account_jwts, got %s", data.AccountJWTs)
+	}
+
+	// Removing the signing key that issued an account warns and reissues
+	state = value(fmt.Sprintf("[%q]", signingPubKey), fmt.Sprintf("[%q]", accountJWT), true)
+	config = value("[]", fmt.Sprintf("[%q]", accountJWT), false)
+	req = fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config},
+		State:  tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+		Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: planned(value("[]", fmt.Sprintf("[%q]", accountJWT), true))},
+	}
+	resp = fwresource.ModifyPlanResponse{Plan: req.Plan}
+	r.ModifyPlan(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 || !strings.Contains(resp.Diagnostics[0].Detail(), "Orders ("+accountPubKey+")") {
+		t.Errorf("expected a warning naming the account, got %v", resp.Diagnostics)
+	}
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &data)...)
+	if !data.JWT.IsUnknown() {
+		t.Errorf("expected the JWT to be reissued, got %s", data.JWT)
+	}
+	// Reordering and repeating signing keys keeps the JWT
+	otherKP, _ := nkeys.CreateOperator()
+	otherPubKey, _ := otherKP.PublicKey()
+	state = value(fmt.Sprintf("[%q, %q]", signingPubKey, otherPubKey), "[]", true)
+	config = value(fmt.Sprintf("[%q, %q, %q]", otherPubKey, signingPubKey, otherPubKey), "[]", false)
+	req = fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config},
+		State:  tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+		Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: planned(value(fmt.Sprintf("[%q, %q, %q]", otherPubKey, signingPubKey, otherPubKey), "[]", true))},
+	}
+	resp = fwresource.ModifyPlanResponse{Plan: req.Plan}
+	r.ModifyPlan(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	data = OperatorResourceModel{}
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &data)...)
+	if data.JWT.ValueString() != "eyJ.e30.sig" {
+		t.Errorf("expected the JWT to be kept, got %s", data.JWT)
+	}
+	if len(data.SigningKeys.Elements()) != 3 {
+		t.Errorf("expected planned signing_keys, got %s", data.SigningKeys)
+	}
+}
@@ -0,0 +1,419 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ resource.Resource = &RevocationResource{}
+var _ resource.ResourceWithValidateConfig = &RevocationResource{}
+var _ resource.ResourceWithIdentity = &RevocationResource{}
+var _ resource.ResourceWithImportState = &RevocationResource{}
+
+func NewRevocationResource() resource.Resource {
+	return &RevocationResource{}
+}
+
+// RevocationResource records a single revocation entry. The entry only takes
+// effect once it is passed to the revocations attribute of the nsc_account it
+// belongs to, which lets revocations live in a different module or state than
+// the account itself.
+type RevocationResource struct{}
+
+type RevocationResourceModel struct {
+	ID types.String `tfsdk:"id"`
+	RevocationModel
+}
+
+// RevocationModel is a revocation entry as accepted by the revocations
+// attribute of nsc_account.
+type RevocationModel struct {
+	Account       types.String      `tfsdk:"account"`
+	PublicKey     types.String      `tfsdk:"public_key"`
+	ExportSubject types.String      `tfsdk:"export_subject"`
+	RevokedAt     timetypes.RFC3339 `tfsdk:"revoked_at"`
+}
+
+// RevocationIdentityModel is the resource identity of nsc_revocation.
+type RevocationIdentityModel struct {
+	Account       types.String `tfsdk:"account"`
+	PublicKey     types.String `tfsdk:"public_key"`
+	ExportSubject types.String `tfsdk:"export_subject"`
+}
+
+func (r *RevocationResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_revocation"
+}
+
+func (r *RevocationResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a single revocation entry of an account: a user, or an account importing one of its exports. " +
+			"Pass the entry to the `revocations` attribute of the `nsc_account` to revoke. Entries can be kept in a module or state separate from the account.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Revocation identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"account": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key of the account the revocation belongs to",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^A[A-Z2-7]{55}$`),
+						"must be a valid account public key starting with 'A'",
+					),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Public key to revoke: a user public key, or the public key of an importing account when `export_subject` is set. `*` revokes all users, or all activations of the export, issued at or before `revoked_at`, for instance when every credential must be rotated.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^(\*|[UA][A-Z2-7]{55})$`),
+						"must be a valid user or account public key, or * to revoke all",
+					),
+				},
+			},
+			"export_subject": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Subject of the export to revoke the activation of. When not set, the entry revokes a user.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"revoked_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "JWTs issued at or before this time are revoked (RFC3339). Defaults to the time the revocation is created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *RevocationResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"account": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "Public key of the account the revocation belongs to",
+			},
+			"public_key": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       "Revoked user or importing account public key",
+			},
+			"export_subject": identityschema.StringAttribute{
+				OptionalForImport: true,
+				Description:       "Subject of the export the activation is revoked for",
+			},
+		},
+	}
+}
+
+func (r *RevocationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// No provider configuration needed
+}
+
+func (r *RevocationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data RevocationResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.RevocationModel.validate(path.Empty())...)
+}
+
+func (r *RevocationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data RevocationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.RevokedAt.IsNull() || data.RevokedAt.IsUnknown() {
+		data.RevokedAt = timetypes.NewRFC3339TimeValue(time.Now().UTC().Truncate(time.Second))
+	}
+
+	data.ID = data.id()
+
+	tflog.Trace(ctx, "created revocation resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(data.setIdentity(ctx, resp.Identity)...)
+}
+
+func (r *RevocationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data RevocationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// For state-only storage, nothing to read externally
+	resp.Diagnostics.Append(data.setIdentity(ctx, resp.Identity)...)
+}
+
+func (r *RevocationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data RevocationResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only revoked_at can change in place; removing it keeps the prior time
+	if data.RevokedAt.IsNull() || data.RevokedAt.IsUnknown() {
+		var state RevocationResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		data.RevokedAt = state.RevokedAt
+	}
+	// Imported entries have no time until the first apply
+	if data.RevokedAt.IsNull() {
+		data.RevokedAt = timetypes.NewRFC3339TimeValue(time.Now().UTC().Truncate(time.Second))
+	}
+
+	tflog.Trace(ctx, "updated revocation resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(data.setIdentity(ctx, resp.Identity)...)
+}
+
+func (r *RevocationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data RevocationResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted revocation resource")
+}
+
+// revocationsAttribute returns the schema of the revocations attribute of
+// nsc_account. Its elements have the attributes of nsc_revocation, so
+// revocation resources can be passed in as they are.
+func revocationsAttribute() schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Optional: true,
+		MarkdownDescription: "Revoked users and export activations. Accepts `nsc_revocation` resources directly. " +
+			"Entries whose `account` is set to a different account are ignored, so a single list can serve several accounts.",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"account": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: "Public key of the account the entry belongs to. Entries for other accounts are ignored.",
+				},
+				"public_key": schema.StringAttribute{
+					Required:            true,
+					MarkdownDescription: "Public key to revoke: a user public key, or the public key of an importing account when `export_subject` is set. `*` revokes all users, or all activations of the export.",
+				},
+				"export_subject": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: "Subject of the export to revoke the activation of",
+				},
+				"revoked_at": schema.StringAttribute{
+					CustomType:          timetypes.RFC3339Type{},
+					Required:            true,
+					MarkdownDescription: "JWTs issued at or before this time are revoked (RFC3339)",
+				},
+			},
+		},
+	}
+}
+
+// validate checks that the public key matches the kind of revocation. The
+// wildcard jwt.All revokes all users or activations and matches either kind.
+func (m *RevocationModel) validate(p path.Path) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.PublicKey.IsNull() || m.PublicKey.IsUnknown() || m.ExportSubject.IsUnknown() || m.PublicKey.ValueString() == jwt.All {
+		return diags
+	}
+
+	publicKey := m.PublicKey.ValueString()
+	if m.ExportSubject.IsNull() && (publicKey == "" || publicKey[0] != 'U') {
+		diags.AddAttributeError(
+			p.AtName("public_key"),
+			"Invalid revocation public key",
+			fmt.Sprintf("Revoking a user requires a user public key starting with 'U', got: %s", publicKey),
+		)
+	}
+	if !m.ExportSubject.IsNull() && (publicKey == "" || publicKey[0] != 'A') {
+		diags.AddAttributeError(
+			p.AtName("public_key"),
+			"Invalid revocation public key",
+			fmt.Sprintf("Revoking an export activation requires an account public key starting with 'A', got: %s", publicKey),
+		)
+	}
+
+	return diags
+}
+
+// applyRevocations enters the revocations of the account into its claims.
+// Export revocations must name one of the account's exports.
+func applyRevocations(ctx context.Context, revocations types.List, claims *jwt.AccountClaims) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if revocations.IsNull() || revocations.IsUnknown() {
+		return diags
+	}
+
+	var entries []RevocationModel
+	diags.Append(revocations.ElementsAs(ctx, &entries, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, entry := range entries {
+		if !entry.Account.IsNull() && entry.Account.ValueString() != claims.Subject {
+			continue
+		}
+
+		p := path.Root("revocations").AtListIndex(i)
+		diags.Append(entry.validate(p)...)
+		if diags.HasError() {
+			return diags
+		}
+
+		revokedAt, d := entry.RevokedAt.ValueRFC3339Time()
+		diags.Append(d...)
+		if diags.HasError() {
+			return diags
+		}
+
+		if entry.ExportSubject.IsNull() {
+			claims.RevokeAt(entry.PublicKey.ValueString(), revokedAt)
+			continue
+		}
+
+		var export *jwt.Export
+		for _, e := range claims.Exports {
+			if string(e.Subject) == entry.ExportSubject.ValueString() {
+				export = e
+				break
+			}
+		}
+		if export == nil {
+			diags.AddAttributeError(
+				p.AtName("export_subject"),
+				"Unknown export",
+				fmt.Sprintf("Account has no export with subject %q", entry.ExportSubject.ValueString()),
+			)
+			return diags
+		}
+		export.RevokeAt(entry.PublicKey.ValueString(), revokedAt)
+	}
+
+	return diags
+}
+
+// ImportState accepts the ID of the entry, `<account>/<public_key>` or
+// `<account>/<export_subject>/<public_key>`, or its identity. The revocation
+// time is not part of either; set revoked_at to keep the time of the entry in
+// the account JWT.
+func (r *RevocationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	var identity RevocationIdentityModel
+
+	if req.ID != "" {
+		var err error
+		identity, err = parseRevocationID(req.ID)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid import ID", err.Error())
+			return
+		}
+	} else {
+		resp.Diagnostics.Append(req.Identity.Get(ctx, &identity)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	data := RevocationResourceModel{
+		RevocationModel: RevocationModel{
+			Account:       identity.Account,
+			PublicKey:     identity.PublicKey,
+			ExportSubject: identity.ExportSubject,
+			RevokedAt:     timetypes.NewRFC3339Null(),
+		},
+	}
+	data.ID = data.id()
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// parseRevocationID parses `<account>/<public_key>` or
+// `<account>/<export_subject>/<public_key>`. Export subjects may contain
+// slashes.
+func parseRevocationID(id string) (RevocationIdentityModel, error) {
+	parts := strings.Split(id, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[len(parts)-1] == "" {
+		return RevocationIdentityModel{}, fmt.Errorf("expected <account>/<public_key> or <account>/<export_subject>/<public_key>, got: %s", id)
+	}
+
+	identity := RevocationIdentityModel{
+		Account:       types.StringValue(parts[0]),
+		PublicKey:     types.StringValue(parts[len(parts)-1]),
+		ExportSubject: types.StringNull(),
+	}
+	if len(parts) > 2 {
+		identity.ExportSubject = types.StringValue(strings.Join(parts[1:len(parts)-1], "/"))
+	}
+	return identity, nil
+}
+
+// id returns the ID of the entry.
+func (m RevocationResourceModel) id() types.String {
+	if !m.ExportSubject.IsNull() {
+		return types.StringValue(m.Account.ValueString() + "/" + m.ExportSubject.ValueString() + "/" + m.PublicKey.ValueString())
+	}
+	return types.StringValue(m.Account.ValueString() + "/" + m.PublicKey.ValueString())
+}
+
+// setIdentity sets the resource identity of the entry. The identity is nil
+// when Terraform does not support resource identity.
+func (m RevocationResourceModel) setIdentity(ctx context.Context, identity *tfsdk.ResourceIdentity) diag.Diagnostics {
+	if identity == nil {
+		return nil
+	}
+	return identity.Set(ctx, RevocationIdentityModel{
+		Account:       m.Account,
+		PublicKey:     m.PublicKey,
+		ExportSubject: m.ExportSubject,
+	})
+}
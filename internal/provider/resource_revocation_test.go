@@ -0,0 +1,341 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccRevocationResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRevocationResourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("nsc_revocation.user", "revoked_at"),
+					resource.TestCheckResourceAttr("nsc_revocation.import", "revoked_at", "2025-01-01T00:00:00Z"),
+					testAccCheckAccountRevocations("nsc_account.test", "nsc_nkey.user", "nsc_nkey.importer"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccRevocationResource_import(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRevocationResourceConfig,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentity("nsc_revocation.import", map[string]knownvalue.Check{
+						"account":        knownvalue.NotNull(),
+						"public_key":     knownvalue.NotNull(),
+						"export_subject": knownvalue.StringExact("private.>"),
+					}),
+				},
+			},
+			// Import by ID; revoked_at is not part of the ID
+			{
+				ResourceName:            "nsc_revocation.import",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"revoked_at"},
+			},
+			// Import by identity; revoked_at is taken from the configuration
+			{
+				ResourceName:       "nsc_revocation.import",
+				ImportState:        true,
+				ImportStateKind:    resource.ImportBlockWithResourceIdentity,
+				ExpectNonEmptyPlan: true,
+				ImportPlanChecks: resource.ImportPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("nsc_revocation.import", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestAccRevocationResource_userKeyRequired(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_revocation" "test" {
+  account    = nsc_nkey.account.public_key
+  public_key = nsc_nkey.account.public_key
+}
+`,
+				ExpectError: regexp.MustCompile(`Invalid revocation public key`),
+			},
+		},
+	})
+}
+
+func TestAccRevocationResource_all(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfig("TestUser") + `
+resource "nsc_revocation" "all" {
+  account    = nsc_nkey.account.public_key
+  public_key = "*"
+}
+
+resource "nsc_account" "revoked" {
+  name        = "RevokedAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+  revocations = [nsc_revocation.all]
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("nsc_revocation.all", "id", regexp.MustCompile(`^A[A-Z2-7]{55}/\*$`)),
+					testAccCheckAccountClaims("nsc_account.revoked", func(claims *jwt.AccountClaims) error {
+						if _, ok := claims.Revocations[jwt.All]; !ok {
+							return fmt.Errorf("expected a revocation of all users, got %v", claims.Revocations)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+const testAccRevocationResourceConfig = `
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "importer" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_revocation" "user" {
+  account    = nsc_nkey.account.public_key
+  public_key = nsc_nkey.user.public_key
+}
+
+resource "nsc_revocation" "import" {
+  account        = nsc_nkey.account.public_key
+  public_key     = nsc_nkey.importer.public_key
+  export_subject = "private.>"
+  revoked_at     = "2025-01-01T00:00:00Z"
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+  revocations = [nsc_revocation.user, nsc_revocation.import]
+
+  export {
+    subject        = "private.>"
+    type           = "stream"
+    token_required = true
+  }
+}
+`
+
+// testAccCheckAccountRevocations checks that the account JWT revokes the user
+// and the activation of the importing account.
+func testAccCheckAccountRevocations(accountName, userName, importerName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		account := s.RootModule().Resources[accountName].Primary.Attributes
+		user := s.RootModule().Resources[userName].Primary.Attributes
+		importer := s.RootModule().Resources[importerName].Primary.Attributes
+
+		claims, err := jwt.DecodeAccountClaims(account["jwt"])
+		if err != nil {
+			return err
+		}
+		if !claims.IsClaimRevoked(&jwt.UserClaims{ClaimsData: jwt.ClaimsData{Subject: user["public_key"], IssuedAt: time.Now().Add(-time.Minute).Unix()}}) {
+			return fmt.Errorf("expected user %s to be revoked", user["public_key"])
+		}
+		if len(claims.Exports) != 1 || !claims.Exports[0].Revocations.IsRevoked(importer["public_key"], time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+			return fmt.Errorf("expected activation of %s to be revoked", importer["public_key"])
+		}
+		return nil
+	}
+}
+
+func TestApplyRevocations(t *testing.T) {
+	ctx := context.Background()
+
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	otherKP, _ := nkeys.CreateAccount()
+	otherPubKey, _ := otherKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	otherUserKP, _ := nkeys.CreateUser()
+	otherUserPubKey, _ := otherUserKP.PublicKey()
+
+	revokedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(account, publicKey, exportSubject string) RevocationModel {
+		m := RevocationModel{
+			Account:       types.StringNull(),
+			PublicKey:     types.StringValue(publicKey),
+			ExportSubject: types.StringNull(),
+			RevokedAt:     timetypes.NewRFC3339TimeValue(revokedAt),
+		}
+		if account != "" {
+			m.Account = types.StringValue(account)
+		}
+		if exportSubject != "" {
+			m.ExportSubject = types.StringValue(exportSubject)
+		}
+		return m
+	}
+	list := func(t *testing.T, entries ...RevocationModel) types.List {
+		t.Helper()
+		attrTypes := revocationsAttribute().NestedObject.Type().(types.ObjectType)
+		l, diags := types.ListValueFrom(ctx, attrTypes, entries)
+		if diags.HasError() {
+			t.Fatalf("failed to build list: %v", diags)
+		}
+		return l
+	}
+	newClaims := func() *jwt.AccountClaims {
+		claims := jwt.NewAccountClaims(accountPubKey)
+		claims.Exports.Add(&jwt.Export{Subject: "private.>", Type: jwt.Stream, TokenReq: true})
+		return claims
+	}
+
+	claims := newClaims()
+	diags := applyRevocations(ctx, list(t,
+		entry("", userPubKey, ""),
+		entry(accountPubKey, otherPubKey, "private.>"),
+		entry(otherPubKey, otherUserPubKey, ""),
+	), claims)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if got := claims.Revocations[userPubKey]; got != revokedAt.Unix() {
+		t.Errorf("expected user revoked at %d, got %d", revokedAt.Unix(), got)
+	}
+	if _, ok := claims.Revocations[otherUserPubKey]; ok {
+		t.Error("expected revocation of another account to be ignored")
+	}
+	if got := claims.Exports[0].Revocations[otherPubKey]; got != revokedAt.Unix() {
+		t.Errorf("expected activation revoked at %d, got %d", revokedAt.Unix(), got)
+	}
+
+	// The wildcard revokes all users and all activations of an export
+	claims = newClaims()
+	diags = applyRevocations(ctx, list(t,
+		entry("", jwt.All, ""),
+		entry("", jwt.All, "private.>"),
+	), claims)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if !claims.IsClaimRevoked(&jwt.UserClaims{ClaimsData: jwt.ClaimsData{Subject: userPubKey, IssuedAt: revokedAt.Unix()}}) {
+		t.Error("expected all users issued at the revocation time to be revoked")
+	}
+	if claims.IsClaimRevoked(&jwt.UserClaims{ClaimsData: jwt.ClaimsData{Subject: userPubKey, IssuedAt: revokedAt.Add(time.Second).Unix()}}) {
+		t.Error("expected users issued after the revocation time not to be revoked")
+	}
+	if !claims.Exports[0].Revocations.IsRevoked(otherPubKey, revokedAt) {
+		t.Error("expected all activations of the export to be revoked")
+	}
+
+	for name, entries := range map[string][]RevocationModel{
+		"unknown export":             {entry("", otherPubKey, "public.>")},
+		"account key without export": {entry("", otherPubKey, "")},
+		"user key with export":       {entry("", userPubKey, "private.>")},
+	} {
+		if diags := applyRevocations(ctx, list(t, entries...), newClaims()); !diags.HasError() {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseRevocationID(t *testing.T) {
+	tests := map[string]struct {
+		id            string
+		account       string
+		exportSubject types.String
+		publicKey     string
+		expectError   bool
+	}{
+		"user": {
+			id:            "ACCOUNT/USER",
+			account:       "ACCOUNT",
+			exportSubject: types.StringNull(),
+			publicKey:     "USER",
+		},
+		"export": {
+			id:            "ACCOUNT/private.>/IMPORTER",
+			account:       "ACCOUNT",
+			exportSubject: types.StringValue("private.>"),
+			publicKey:     "IMPORTER",
+		},
+		"export subject with slashes": {
+			id:            "ACCOUNT/files/a/b/IMPORTER",
+			account:       "ACCOUNT",
+			exportSubject: types.StringValue("files/a/b"),
+			publicKey:     "IMPORTER",
+		},
+		"missing public key": {
+			id:          "ACCOUNT",
+			expectError: true,
+		},
+		"empty public key": {
+			id:          "ACCOUNT/",
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			identity, err := parseRevocationID(tc.id)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %+v", identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if identity.Account.ValueString() != tc.account || identity.PublicKey.ValueString() != tc.publicKey || !identity.ExportSubject.Equal(tc.exportSubject) {
+				t.Errorf("unexpected identity: %+v", identity)
+			}
+		})
+	}
+}
@@ -0,0 +1,465 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+var _ resource.Resource = &SigningKeyRotationResource{}
+var _ resource.ResourceWithConfigure = &SigningKeyRotationResource{}
+var _ resource.ResourceWithModifyPlan = &SigningKeyRotationResource{}
+var _ resource.ResourceWithValidateConfig = &SigningKeyRotationResource{}
+var _ resource.ResourceWithIdentity = &SigningKeyRotationResource{}
+
+func NewOperatorSigningKeyRotationResource() resource.Resource {
+	return &SigningKeyRotationResource{keyType: "operator"}
+}
+
+func NewAccountSigningKeyRotationResource() resource.Resource {
+	return &SigningKeyRotationResource{keyType: "account"}
+}
+
+// SigningKeyRotationResource manages a signing key together with its
+// predecessor, so a rotation can overlap: the new key signs right away while
+// the previous key stays listed on the issuer until its grace period ends and,
+// for accounts, until no user JWT issued by it is valid anymore.
+type SigningKeyRotationResource struct {
+	keyType string
+	keys    *keypairCache
+	cipher  *stateCipher
+}
+
+type SigningKeyRotationResourceModel struct {
+	ID                types.String         `tfsdk:"id"`
+	RotationTrigger   types.String         `tfsdk:"rotation_trigger"`
+	GracePeriod       timetypes.GoDuration `tfsdk:"grace_period"`
+	CurrentPublicKey  types.String         `tfsdk:"current_public_key"`
+	CurrentSeed       types.String         `tfsdk:"current_seed"`
+	PreviousPublicKey types.String         `tfsdk:"previous_public_key"`
+	PreviousSeed      types.String         `tfsdk:"previous_seed"`
+	PreviousRetireAt  timetypes.RFC3339    `tfsdk:"previous_retire_at"`
+	SigningKeys       types.List           `tfsdk:"signing_keys"`
+}
+
+// AccountSigningKeyRotationResourceModel adds the user JWTs that keep the
+// previous key listed to the attributes of the account rotation.
+type AccountSigningKeyRotationResourceModel struct {
+	SigningKeyRotationResourceModel
+	UserJWTs types.List `tfsdk:"user_jwts"`
+}
+
+// newModel returns the model of the resource's key type to get and set, and
+// the attributes shared by both key types within it.
+func (r *SigningKeyRotationResource) newModel() (any, *SigningKeyRotationResourceModel) {
+	if r.keyType == "account" {
+		model := &AccountSigningKeyRotationResourceModel{}
+		return model, &model.SigningKeyRotationResourceModel
+	}
+	model := &SigningKeyRotationResourceModel{}
+	return model, model
+}
+
+// userJWTs returns the user_jwts of a model returned by newModel, which is
+// null for the operator rotation.
+func userJWTs(model any) types.List {
+	if m, ok := model.(*AccountSigningKeyRotationResourceModel); ok {
+		return m.UserJWTs
+	}
+	return types.ListNull(types.StringType)
+}
+
+func (r *SigningKeyRotationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + r.keyType + "_signing_key_rotation"
+}
+
+func (r *SigningKeyRotationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	description := fmt.Sprintf("Rotates %[1]s signing keys with an overlap window. Sign with `current_seed` and list `signing_keys` on the %[1]s. "+
+		"Changing `rotation_trigger` generates a new current key; the previous key stays in `signing_keys` until `grace_period` has passed, "+
+		"after which the next plan removes it.", r.keyType)
+	if r.keyType == "account" {
+		description += " With `user_jwts`, the previous key also stays until none of the listed user JWTs issued by it is valid, " +
+			"and rotating again while one is fails."
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: description,
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Identifier (public key of the first signing key)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"rotation_trigger": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Arbitrary value; changing it rotates the signing key (e.g. `\"2026-q1\"`).",
+			},
+			"grace_period": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Required:            true,
+				MarkdownDescription: "How long the previous signing key stays listed after a rotation (e.g. `\"720h\"`). Must cover the time until every JWT signed with it has been re-issued or has expired. Changes apply to the next rotation.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"current_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the signing key to sign with",
+			},
+			"current_seed": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the signing key to sign with. Encrypted when the provider has a `state_encryption_key`; other resources of the provider decrypt it when it is passed on as `issuer_seed`.",
+			},
+			"previous_public_key": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the previous signing key while it is in its grace period",
+			},
+			"previous_seed": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Seed of the previous signing key while it is in its grace period. Encrypted like `current_seed`.",
+			},
+			"previous_retire_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Computed:            true,
+				MarkdownDescription: "Time after which the previous signing key is removed (RFC3339)",
+			},
+			"signing_keys": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Computed:            true,
+				MarkdownDescription: fmt.Sprintf("Signing keys to list on the %s: the current key followed by the previous key during its grace period", r.keyType),
+			},
+		},
+	}
+
+	if r.keyType == "account" {
+		resp.Schema.Attributes["user_jwts"] = schema.ListAttribute{
+			ElementType: types.StringType,
+			Optional:    true,
+			MarkdownDescription: "JWTs of the users issued by the signing keys of this rotation. The previous key is kept past its grace period while " +
+				"any of them is issued by it and has not expired, and removed once all of them have been reissued or have expired. " +
+				"Users that sign with `current_seed` depend on this resource, so take their JWTs from outside the configuration, " +
+				"e.g. from `terraform_remote_state`; referencing their `jwt` here is a dependency cycle.",
+		}
+	}
+}
+
+func (r *SigningKeyRotationResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = publicKeyIdentitySchema("Public key of the first signing key of the rotation (same as id)")
+}
+
+func (r *SigningKeyRotationResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.keys = providerData.Keys
+	r.cipher = providerData.StateCipher
+}
+
+func (r *SigningKeyRotationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	if r.keyType != "account" {
+		return
+	}
+
+	var issuedJWTs types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("user_jwts"), &issuedJWTs)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(validateIssuedJWTs(issuedJWTs, "user_jwts", jwt.UserClaim)...)
+}
+
+// ModifyPlan plans the outcome of the rotation: a new current key when the
+// trigger changes, removal of the previous key once its grace period has
+// passed and no user JWT issued by it is valid, and the prior keys otherwise.
+func (r *SigningKeyRotationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on create or destroy
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	planModel, plan := r.newModel()
+	stateModel, state := r.newModel()
+	resp.Diagnostics.Append(req.Plan.Get(ctx, planModel)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, stateModel)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.RotationTrigger.IsUnknown() {
+		return
+	}
+
+	previousUsers, unknownUsers := signingKeyValidUsers(userJWTs(planModel), state.PreviousPublicKey.ValueString())
+
+	if !plan.RotationTrigger.Equal(state.RotationTrigger) {
+		// Rotating again drops the previous key
+		if len(previousUsers) > 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("rotation_trigger"),
+				"Previous signing key still in use",
+				fmt.Sprintf("Rotating drops the previous signing key %s, but it issued the JWTs of these users, which have not expired:\n\n  %s\n\n"+
+					"Reissue them with the current key before rotating again.",
+					state.PreviousPublicKey.ValueString(), strings.Join(previousUsers, "\n  ")),
+			)
+			return
+		}
+
+		// Rotate: the current key becomes the previous key
+		plan.CurrentPublicKey = types.StringUnknown()
+		plan.CurrentSeed = types.StringUnknown()
+		plan.PreviousPublicKey = state.CurrentPublicKey
+		plan.PreviousSeed = state.CurrentSeed
+		plan.PreviousRetireAt = timetypes.NewRFC3339Unknown()
+		plan.SigningKeys = types.ListUnknown(types.StringType)
+	} else {
+		plan.CurrentPublicKey = state.CurrentPublicKey
+		plan.CurrentSeed = state.CurrentSeed
+		plan.PreviousPublicKey = state.PreviousPublicKey
+		plan.PreviousSeed = state.PreviousSeed
+		plan.PreviousRetireAt = state.PreviousRetireAt
+		plan.SigningKeys = state.SigningKeys
+
+		retired, diags := previousKeyRetired(state.PreviousRetireAt)
+		resp.Diagnostics.Append(diags...)
+		if retired && len(previousUsers) == 0 && !unknownUsers {
+			plan.PreviousPublicKey = types.StringNull()
+			plan.PreviousSeed = types.StringNull()
+			plan.PreviousRetireAt = timetypes.NewRFC3339Null()
+
+			signingKeys, d := types.ListValueFrom(ctx, types.StringType, []string{state.CurrentPublicKey.ValueString()})
+			resp.Diagnostics.Append(d...)
+			plan.SigningKeys = signingKeys
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, planModel)...)
+}
+
+func (r *SigningKeyRotationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	model, data := r.newModel()
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	publicKey, seed, err := r.createKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to create signing key", err.Error())
+		return
+	}
+
+	data.ID = types.StringValue(publicKey)
+	data.CurrentPublicKey = types.StringValue(publicKey)
+	data.CurrentSeed = types.StringValue(seed)
+	data.PreviousPublicKey = types.StringNull()
+	data.PreviousSeed = types.StringNull()
+	data.PreviousRetireAt = timetypes.NewRFC3339Null()
+
+	signingKeys, diags := types.ListValueFrom(ctx, types.StringType, []string{publicKey})
+	resp.Diagnostics.Append(diags...)
+	data.SigningKeys = signingKeys
+
+	tflog.Trace(ctx, "created signing key rotation resource", map[string]any{"type": r.keyType})
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *SigningKeyRotationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	model, data := r.newModel()
+
+	resp.Diagnostics.Append(req.State.Get(ctx, model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// For state-only storage, nothing to read externally
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *SigningKeyRotationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	model, data := r.newModel()
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	stateModel, state := r.newModel()
+	resp.Diagnostics.Append(req.State.Get(ctx, stateModel)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.ID = state.ID
+
+	// ModifyPlan leaves the current key unknown when a rotation is due
+	if data.CurrentPublicKey.IsUnknown() {
+		// The current key stays in use during the grace period, so it has
+		// to remain readable with the configured state encryption key
+		if _, err := r.keys.seed(state.CurrentSeed.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("current_seed"), "Invalid signing key seed", err.Error())
+			return
+		}
+
+		publicKey, seed, err := r.createKey()
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to create signing key", err.Error())
+			return
+		}
+
+		gracePeriod, diags := data.GracePeriod.ValueGoDuration()
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		data.CurrentPublicKey = types.StringValue(publicKey)
+		data.CurrentSeed = types.StringValue(seed)
+		data.PreviousPublicKey = state.CurrentPublicKey
+		data.PreviousSeed = state.CurrentSeed
+		data.PreviousRetireAt = timetypes.NewRFC3339TimeValue(time.Now().UTC().Add(gracePeriod).Truncate(time.Second))
+
+		tflog.Debug(ctx, "rotated signing key", map[string]any{
+			"type":     r.keyType,
+			"current":  publicKey,
+			"previous": state.CurrentPublicKey.ValueString(),
+		})
+	}
+
+	keys := []string{data.CurrentPublicKey.ValueString()}
+	if !data.PreviousPublicKey.IsNull() {
+		keys = append(keys, data.PreviousPublicKey.ValueString())
+	}
+	signingKeys, diags := types.ListValueFrom(ctx, types.StringType, keys)
+	resp.Diagnostics.Append(diags...)
+	data.SigningKeys = signingKeys
+
+	tflog.Trace(ctx, "updated signing key rotation resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *SigningKeyRotationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	model, _ := r.newModel()
+
+	resp.Diagnostics.Append(req.State.Get(ctx, model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted signing key rotation resource")
+}
+
+// createKey generates a new signing key of the resource's key type and
+// returns its public key and its seed, encrypted for state when a state
+// encryption key is configured.
+func (r *SigningKeyRotationResource) createKey() (string, string, error) {
+	var kp nkeys.KeyPair
+	var err error
+
+	switch r.keyType {
+	case "operator":
+		kp, err = nkeys.CreateOperator()
+	case "account":
+		kp, err = nkeys.CreateAccount()
+	default:
+		return "", "", fmt.Errorf("unsupported signing key type %q", r.keyType)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return "", "", err
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return "", "", err
+	}
+	stateSeed, err := r.cipher.encrypt(string(seed))
+	if err != nil {
+		return "", "", err
+	}
+
+	return publicKey, stateSeed, nil
+}
+
+// previousKeyRetired reports whether the grace period of the previous key
+// has passed.
+func previousKeyRetired(retireAt timetypes.RFC3339) (bool, diag.Diagnostics) {
+	if retireAt.IsNull() || retireAt.IsUnknown() {
+		return false, nil
+	}
+
+	t, diags := retireAt.ValueRFC3339Time()
+	if diags.HasError() {
+		return false, diags
+	}
+
+	return !time.Now().Before(t), diags
+}
+
+// signingKeyValidUsers returns the users whose JWTs in userJWTs are issued by
+// publicKey and have not expired, as name and subject. Unknown reports JWTs
+// that are not known yet and may be issued by the key as well.
+func signingKeyValidUsers(userJWTs types.List, publicKey string) (users []string, unknown bool) {
+	if publicKey == "" || userJWTs.IsNull() {
+		return nil, false
+	}
+	if userJWTs.IsUnknown() {
+		return nil, true
+	}
+
+	now := time.Now().Unix()
+	for _, element := range userJWTs.Elements() {
+		token, ok := element.(types.String)
+		if !ok || token.IsNull() {
+			continue
+		}
+		if token.IsUnknown() {
+			unknown = true
+			continue
+		}
+		claims, err := jwt.Decode(token.ValueString())
+		if err != nil {
+			continue
+		}
+		data := claims.Claims()
+		if data.Issuer == publicKey && (data.Expires == 0 || data.Expires > now) {
+			users = append(users, fmt.Sprintf("%s (%s)", data.Name, data.Subject))
+		}
+	}
+	return users, unknown
+}
@@ -0,0 +1,491 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccOperatorSigningKeyRotationResource(t *testing.T) {
+	var firstKey string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSigningKeyRotationResourceConfig("operator", "1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("nsc_operator_signing_key_rotation.test", "current_public_key", regexp.MustCompile(`^O`)),
+					resource.TestCheckResourceAttrPair("nsc_operator_signing_key_rotation.test", "id", "nsc_operator_signing_key_rotation.test", "current_public_key"),
+					resource.TestCheckResourceAttr("nsc_operator_signing_key_rotation.test", "signing_keys.#", "1"),
+					resource.TestCheckNoResourceAttr("nsc_operator_signing_key_rotation.test", "previous_public_key"),
+					resource.TestCheckResourceAttrPair("nsc_operator.test", "signing_keys.0", "nsc_operator_signing_key_rotation.test", "current_public_key"),
+					testAccCaptureAttr("nsc_operator_signing_key_rotation.test", "current_public_key", &firstKey),
+				),
+			},
+			// Rotation keeps the previous key listed during the grace period
+			{
+				Config: testAccSigningKeyRotationResourceConfig("operator", "2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_operator_signing_key_rotation.test", "signing_keys.#", "2"),
+					resource.TestCheckResourceAttrPtr("nsc_operator_signing_key_rotation.test", "previous_public_key", &firstKey),
+					resource.TestCheckResourceAttrPtr("nsc_operator_signing_key_rotation.test", "signing_keys.1", &firstKey),
+					resource.TestCheckResourceAttrPtr("nsc_operator_signing_key_rotation.test", "id", &firstKey),
+					resource.TestCheckResourceAttrSet("nsc_operator_signing_key_rotation.test", "previous_retire_at"),
+					resource.TestCheckResourceAttr("nsc_operator.test", "signing_keys.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSigningKeyRotationResourceConfig(keyType, trigger string) string {
+	return fmt.Sprintf(`
+resource "nsc_%[1]s_signing_key_rotation" "test" {
+  rotation_trigger = %[2]q
+  grace_period     = "720h"
+}
+
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_operator" "test" {
+  name         = "TestOperator"
+  subject      = nsc_nkey.operator.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = nsc_%[1]s_signing_key_rotation.test.signing_keys
+}
+`, keyType, trigger)
+}
+
+func TestAccAccountSigningKeyRotationResource(t *testing.T) {
+	var firstKey string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccAccountSigningKeyRotationResourceConfig("1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("nsc_account_signing_key_rotation.test", "current_public_key", regexp.MustCompile(`^A`)),
+					resource.TestCheckResourceAttr("nsc_account_signing_key_rotation.test", "signing_keys.#", "1"),
+					resource.TestCheckResourceAttrPair("nsc_account.test", "signing_keys.0", "nsc_account_signing_key_rotation.test", "current_public_key"),
+					testAccCheckJWTIssuer("nsc_user.test", "nsc_account_signing_key_rotation.test", "current_public_key"),
+					testAccCaptureAttr("nsc_account_signing_key_rotation.test", "current_public_key", &firstKey),
+				),
+			},
+			// The user is re-issued with the new key while the old key stays listed
+			{
+				Config: testAccAccountSigningKeyRotationResourceConfig("2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_account.test", "signing_keys.#", "2"),
+					resource.TestCheckResourceAttrPtr("nsc_account.test", "signing_keys.1", &firstKey),
+					testAccCheckJWTIssuer("nsc_user.test", "nsc_account_signing_key_rotation.test", "current_public_key"),
+				),
+			},
+		},
+	})
+}
+
+func testAccAccountSigningKeyRotationResourceConfig(trigger string) string {
+	return fmt.Sprintf(`
+resource "nsc_account_signing_key_rotation" "test" {
+  rotation_trigger = %q
+  grace_period     = "24h"
+}
+
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_account" "test" {
+  name         = "TestAccount"
+  subject      = nsc_nkey.account.public_key
+  issuer_seed  = nsc_nkey.operator.seed
+  signing_keys = nsc_account_signing_key_rotation.test.signing_keys
+}
+
+resource "nsc_user" "test" {
+  name           = "TestUser"
+  subject        = nsc_nkey.user.public_key
+  issuer_seed    = nsc_account_signing_key_rotation.test.current_seed
+  issuer_account = nsc_account.test.subject
+
+  lifecycle {
+    replace_triggered_by = [nsc_account_signing_key_rotation.test.current_public_key]
+  }
+}
+`, trigger)
+}
+
+// testAccCheckJWTIssuer checks that the JWT of a resource is issued by the
+// public key held in an attribute of another resource.
+func testAccCheckJWTIssuer(resourceName, issuerResourceName, issuerAttr string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+		issuer, ok := s.RootModule().Resources[issuerResourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", issuerResourceName)
+		}
+
+		claims, err := jwt.Decode(rs.Primary.Attributes["jwt"])
+		if err != nil {
+			return fmt.Errorf("failed to decode JWT: %s", err)
+		}
+		if want := issuer.Primary.Attributes[issuerAttr]; claims.Claims().Issuer != want {
+			return fmt.Errorf("expected issuer %s, got %s", want, claims.Claims().Issuer)
+		}
+		return nil
+	}
+}
+
+// testAccCaptureAttr stores the value of a resource attribute for later steps.
+func testAccCaptureAttr(resourceName, attr string, value *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("resource not found: %s", resourceName)
+		}
+		*value = rs.Primary.Attributes[attr]
+		return nil
+	}
+}
+
+func TestSigningKeyRotationResource_modifyPlan(t *testing.T) {
+	ctx := context.Background()
+	r := &SigningKeyRotationResource{keyType: "operator"}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	const current = "OCCURRENTKEY"
+	const previous = "OCPREVIOUSKEY"
+
+	stateJSON := func(trigger, retireAt string) string {
+		return fmt.Sprintf(`{
+			"id": %[3]q,
+			"rotation_trigger": %[1]q,
+			"grace_period": "1h",
+			"current_public_key": %[3]q,
+			"current_seed": "SOCURRENT",
+			"previous_public_key": %[4]q,
+			"previous_seed": "SOPREVIOUS",
+			"previous_retire_at": %[2]q,
+			"signing_keys": [%[3]q, %[4]q]
+		}`, trigger, retireAt, current, previous)
+	}
+
+	tests := []struct {
+		name             string
+		state            string
+		plan             string
+		wantCurrent      types.String
+		wantPrevious     types.String
+		wantSigningKeys  []string
+		wantKeysUnknown  bool
+		wantRetireAtNull bool
+	}{
+		{
+			name:            "grace period running",
+			state:           stateJSON("1", "2999-01-01T00:00:00Z"),
+			plan:            stateJSON("1", "2999-01-01T00:00:00Z"),
+			wantCurrent:     types.StringValue(current),
+			wantPrevious:    types.StringValue(previous),
+			wantSigningKeys: []string{current, previous},
+		},
+		{
+			name:             "grace period passed",
+			state:            stateJSON("1", "2000-01-01T00:00:00Z"),
+			plan:             stateJSON("1", "2000-01-01T00:00:00Z"),
+			wantCurrent:      types.StringValue(current),
+			wantPrevious:     types.StringNull(),
+			wantSigningKeys:  []string{current},
+			wantRetireAtNull: true,
+		},
+		{
+			name:            "rotation",
+			state:           stateJSON("1", "2999-01-01T00:00:00Z"),
+			plan:            stateJSON("2", "2999-01-01T00:00:00Z"),
+			wantCurrent:     types.StringUnknown(),
+			wantPrevious:    types.StringValue(current),
+			wantKeysUnknown: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := testRawValue(t, schemaResp.Schema, tt.state)
+			plan := testRawValue(t, schemaResp.Schema, tt.plan)
+
+			req := fwresource.ModifyPlanRequest{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+				Plan:  tfsdk.Plan{Schema: schemaResp.Schema, Raw: plan},
+			}
+			resp := fwresource.ModifyPlanResponse{
+				Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: plan},
+			}
+			r.ModifyPlan(ctx, req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var data SigningKeyRotationResourceModel
+			if diags := resp.Plan.Get(ctx, &data); diags.HasError() {
+				t.Fatalf("failed to read plan: %v", diags)
+			}
+
+			if !data.CurrentPublicKey.Equal(tt.wantCurrent) {
+				t.Errorf("expected current_public_key %s, got %s", tt.wantCurrent, data.CurrentPublicKey)
+			}
+			if !data.PreviousPublicKey.Equal(tt.wantPrevious) {
+				t.Errorf("expected previous_public_key %s, got %s", tt.wantPrevious, data.PreviousPublicKey)
+			}
+			if data.PreviousRetireAt.IsNull() != tt.wantRetireAtNull {
+				t.Errorf("expected previous_retire_at null to be %t, got %s", tt.wantRetireAtNull, data.PreviousRetireAt)
+			}
+			if tt.wantKeysUnknown {
+				if !data.SigningKeys.IsUnknown() {
+					t.Errorf("expected signing_keys to be unknown, got %s", data.SigningKeys)
+				}
+				return
+			}
+
+			keys, diags := stringListValues(ctx, data.SigningKeys)
+			if diags.HasError() {
+				t.Fatalf("failed to read signing_keys: %v", diags)
+			}
+			if fmt.Sprint(keys) != fmt.Sprint(tt.wantSigningKeys) {
+				t.Errorf("expected signing_keys %v, got %v", tt.wantSigningKeys, keys)
+			}
+		})
+	}
+}
+
+func TestSigningKeyRotationResource_modifyPlanUserJWTs(t *testing.T) {
+	ctx := context.Background()
+	r := &SigningKeyRotationResource{keyType: "account"}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	currentKP, _ := nkeys.CreateAccount()
+	current, _ := currentKP.PublicKey()
+	previousKP, _ := nkeys.CreateAccount()
+	previous, _ := previousKP.PublicKey()
+
+	userJWT := func(issuer nkeys.KeyPair, expires int64) string {
+		userKP, _ := nkeys.CreateUser()
+		userPubKey, _ := userKP.PublicKey()
+		claims := jwt.NewUserClaims(userPubKey)
+		claims.Name = "user"
+		claims.Expires = expires
+		token, err := claims.Encode(issuer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	live := userJWT(previousKP, 0)
+	expired := userJWT(previousKP, time.Now().Add(-time.Hour).Unix())
+	reissued := userJWT(currentKP, time.Now().Add(time.Hour).Unix())
+
+	valueJSON := func(trigger, retireAt string, userJWTs ...string) string {
+		tokens, _ := json.Marshal(userJWTs)
+		return fmt.Sprintf(`{
+			"id": %[3]q,
+			"rotation_trigger": %[1]q,
+			"grace_period": "1h",
+			"current_public_key": %[3]q,
+			"current_seed": "SACURRENT",
+			"previous_public_key": %[4]q,
+			"previous_seed": "SAPREVIOUS",
+			"previous_retire_at": %[2]q,
+			"signing_keys": [%[3]q, %[4]q],
+			"user_jwts": %[5]s
+		}`, trigger, retireAt, current, previous, tokens)
+	}
+
+	tests := []struct {
+		name            string
+		state           string
+		plan            string
+		wantSigningKeys []string
+		wantError       bool
+	}{
+		{
+			name:            "still referenced after the grace period",
+			state:           valueJSON("1", "2000-01-01T00:00:00Z"),
+			plan:            valueJSON("1", "2000-01-01T00:00:00Z", reissued, live, expired),
+			wantSigningKeys: []string{current, previous},
+		},
+		{
+			name:            "all expired or reissued",
+			state:           valueJSON("1", "2000-01-01T00:00:00Z"),
+			plan:            valueJSON("1", "2000-01-01T00:00:00Z", reissued, expired),
+			wantSigningKeys: []string{current},
+		},
+		{
+			name:            "all reissued during the grace period",
+			state:           valueJSON("1", "2999-01-01T00:00:00Z"),
+			plan:            valueJSON("1", "2999-01-01T00:00:00Z", reissued),
+			wantSigningKeys: []string{current, previous},
+		},
+		{
+			name:      "rotation while referenced",
+			state:     valueJSON("1", "2000-01-01T00:00:00Z"),
+			plan:      valueJSON("2", "2000-01-01T00:00:00Z", live),
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := testRawValue(t, schemaResp.Schema, tt.state)
+			plan := testRawValue(t, schemaResp.Schema, tt.plan)
+
+			req := fwresource.ModifyPlanRequest{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+				Plan:  tfsdk.Plan{Schema: schemaResp.Schema, Raw: plan},
+			}
+			resp := fwresource.ModifyPlanResponse{
+				Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: plan},
+			}
+			r.ModifyPlan(ctx, req, &resp)
+			if resp.Diagnostics.HasError() != tt.wantError {
+				t.Fatalf("expected error %t, got %v", tt.wantError, resp.Diagnostics)
+			}
+			if tt.wantError {
+				return
+			}
+
+			var data AccountSigningKeyRotationResourceModel
+			if diags := resp.Plan.Get(ctx, &data); diags.HasError() {
+				t.Fatalf("failed to read plan: %v", diags)
+			}
+			keys, diags := stringListValues(ctx, data.SigningKeys)
+			if diags.HasError() {
+				t.Fatalf("failed to read signing_keys: %v", diags)
+			}
+			if fmt.Sprint(keys) != fmt.Sprint(tt.wantSigningKeys) {
+				t.Errorf("expected signing_keys %v, got %v", tt.wantSigningKeys, keys)
+			}
+			if len(data.UserJWTs.Elements()) == 0 {
+				t.Errorf("expected user_jwts to be kept in the plan")
+			}
+		})
+	}
+}
+
+func TestSigningKeyValidUsers(t *testing.T) {
+	issuerKP, _ := nkeys.CreateAccount()
+	issuer, _ := issuerKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	claims := jwt.NewUserClaims(userPubKey)
+	claims.Name = "alice"
+	token, err := claims.Encode(issuerKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, unknown := signingKeyValidUsers(types.ListValueMust(types.StringType, []attr.Value{types.StringValue(token)}), issuer)
+	if fmt.Sprint(users) != fmt.Sprintf("[alice (%s)]", userPubKey) || unknown {
+		t.Errorf("unexpected users %v, unknown %t", users, unknown)
+	}
+
+	_, unknown = signingKeyValidUsers(types.ListUnknown(types.StringType), issuer)
+	if !unknown {
+		t.Error("expected an unknown list to be reported")
+	}
+	_, unknown = signingKeyValidUsers(types.ListValueMust(types.StringType, []attr.Value{types.StringUnknown()}), issuer)
+	if !unknown {
+		t.Error("expected an unknown element to be reported")
+	}
+	if users, unknown := signingKeyValidUsers(types.ListUnknown(types.StringType), ""); users != nil || unknown {
+		t.Error("expected no users without a previous key")
+	}
+}
+
+func TestSigningKeyRotationResource_createKeyEncrypted(t *testing.T) {
+	cipher, err := newStateCipher(testStateEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := newKeypairCache()
+	keys.cipher = cipher
+	r := &SigningKeyRotationResource{keyType: "account", keys: keys, cipher: cipher}
+
+	publicKey, seed, err := r.createKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncryptedSeed(seed) {
+		t.Fatalf("expected an encrypted seed, got %s", seed)
+	}
+	kp, err := keys.fromSeed(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := kp.PublicKey(); got != publicKey {
+		t.Errorf("expected public key %s, got %s", publicKey, got)
+	}
+}
+
+func TestAccSigningKeyRotationResource_stateEncryption(t *testing.T) {
+	encrypted := regexp.MustCompile("^" + regexp.QuoteMeta(encryptedSeedPrefix))
+	config := func(trigger string) string {
+		return fmt.Sprintf(`
+provider "nsc" {
+  state_encryption_key = %q
+}
+`, testStateEncryptionKey) + testAccAccountSigningKeyRotationResourceConfig(trigger)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("nsc_account_signing_key_rotation.test", "current_seed", encrypted),
+					testAccCheckJWTIssuer("nsc_user.test", "nsc_account_signing_key_rotation.test", "current_public_key"),
+				),
+			},
+			{
+				Config: config("2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("nsc_account_signing_key_rotation.test", "current_seed", encrypted),
+					resource.TestMatchResourceAttr("nsc_account_signing_key_rotation.test", "previous_seed", encrypted),
+					testAccCheckJWTIssuer("nsc_user.test", "nsc_account_signing_key_rotation.test", "current_public_key"),
+				),
+			},
+		},
+	})
+}
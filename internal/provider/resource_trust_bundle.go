@@ -0,0 +1,375 @@
+package provider
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/nats-io/jwt/v2"
+)
+
+var _ resource.Resource = &TrustBundleResource{}
+var _ resource.ResourceWithValidateConfig = &TrustBundleResource{}
+var _ resource.ResourceWithIdentity = &TrustBundleResource{}
+
+const (
+	trustBundleFormatJSON = "json"
+	trustBundleFormatTar  = "tar"
+)
+
+func NewTrustBundleResource() resource.Resource {
+	return &TrustBundleResource{}
+}
+
+// TrustBundleResource assembles the operator JWT and account JWTs into a
+// single artifact. The bundle is kept in state only; its version is bumped
+// whenever the content changes.
+type TrustBundleResource struct{}
+
+type TrustBundleResourceModel struct {
+	ID               types.String `tfsdk:"id"`
+	OperatorJWT      types.String `tfsdk:"operator_jwt"`
+	SystemAccountJWT types.String `tfsdk:"system_account_jwt"`
+	AccountJWTs      types.List   `tfsdk:"account_jwts"`
+	Format           types.String `tfsdk:"format"`
+	Version          types.Int64  `tfsdk:"version"`
+	Content          types.String `tfsdk:"content"`
+	ContentBase64    types.String `tfsdk:"content_base64"`
+	SHA256           types.String `tfsdk:"sha256"`
+}
+
+// trustBundle is the JSON form of a trust bundle, also written as
+// manifest.json into tar bundles.
+type trustBundle struct {
+	Version       int64             `json:"version"`
+	Operator      string            `json:"operator"`
+	OperatorJWT   string            `json:"operator_jwt,omitempty"`
+	SystemAccount string            `json:"system_account,omitempty"`
+	Accounts      map[string]string `json:"accounts"`
+}
+
+func (r *TrustBundleResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_trust_bundle"
+	// The identity follows the operator, which can change in place
+	resp.ResourceBehavior.MutableIdentity = true
+}
+
+func (r *TrustBundleResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Assembles the operator JWT, the system account JWT and optionally further account JWTs into a single versioned bundle with a checksum, for distribution to server fleets and air-gapped sites. " +
+			"Account JWTs must be issued by the operator or one of its signing keys.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Operator public key",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"operator_jwt": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Operator JWT",
+			},
+			"system_account_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "System account JWT. Must match the operator's `system_account` when the operator sets one.",
+			},
+			"account_jwts": schema.ListAttribute{
+				ElementType:         types.StringType,
+				Optional:            true,
+				MarkdownDescription: "Further account JWTs to include",
+			},
+			"format": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(trustBundleFormatJSON),
+				MarkdownDescription: "Bundle format: `json` (default) or `tar`. A tar bundle holds `operator.jwt`, `accounts/<public key>.jwt` and a `manifest.json` in the JSON format without the JWTs.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(trustBundleFormatJSON, trustBundleFormatTar),
+				},
+			},
+			"version": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Bundle version, starting at 1 and incremented whenever the bundle changes",
+			},
+			"content": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "JSON bundle with the `version`, the `operator` public key and `operator_jwt`, the `system_account` public key and the `accounts` map of public keys to JWTs. Null for tar bundles.",
+			},
+			"content_base64": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Base64 encoded bundle, in either format",
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded SHA-256 checksum of the bundle",
+			},
+		},
+	}
+}
+
+func (r *TrustBundleResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = publicKeyIdentitySchema("Operator public key of the bundle")
+}
+
+func (r *TrustBundleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// No provider configuration needed
+}
+
+func (r *TrustBundleResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data TrustBundleResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.OperatorJWT.IsUnknown() || data.SystemAccountJWT.IsUnknown() || data.AccountJWTs.IsUnknown() {
+		return
+	}
+	for _, element := range data.AccountJWTs.Elements() {
+		if element.IsUnknown() {
+			return
+		}
+	}
+
+	_, diags := buildTrustBundle(ctx, &data, 0)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *TrustBundleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data TrustBundleResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bundle, diags := buildTrustBundle(ctx, &data, 1)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.setContent(bundle)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(bundle.Operator)
+
+	tflog.Trace(ctx, "created trust bundle resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *TrustBundleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data TrustBundleResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// For state-only storage, nothing to read externally
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *TrustBundleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state TrustBundleResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bundle, diags := buildTrustBundle(ctx, &data, state.Version.ValueInt64()+1)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(data.setContent(bundle)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = types.StringValue(bundle.Operator)
+
+	tflog.Trace(ctx, "updated trust bundle resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *TrustBundleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data TrustBundleResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted trust bundle resource")
+}
+
+// buildTrustBundle decodes and cross-checks the JWTs of the bundle.
+func buildTrustBundle(ctx context.Context, data *TrustBundleResourceModel, version int64) (*trustBundle, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	operator, err := jwt.DecodeOperatorClaims(data.OperatorJWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("operator_jwt"), "Invalid operator JWT", err.Error())
+		return nil, diags
+	}
+
+	bundle := &trustBundle{
+		Version:     version,
+		Operator:    operator.Subject,
+		OperatorJWT: data.OperatorJWT.ValueString(),
+		Accounts:    map[string]string{},
+	}
+
+	addAccount := func(p path.Path, token string) *jwt.AccountClaims {
+		account, err := jwt.DecodeAccountClaims(token)
+		if err != nil {
+			diags.AddAttributeError(p, "Invalid account JWT", err.Error())
+			return nil
+		}
+		if account.Issuer != operator.Subject && !operator.SigningKeys.Contains(account.Issuer) {
+			diags.AddAttributeError(p, "Invalid account JWT",
+				fmt.Sprintf("Account %s is issued by %s, which is neither the operator %s nor one of its signing keys", account.Subject, account.Issuer, operator.Subject))
+			return nil
+		}
+		if existing, ok := bundle.Accounts[account.Subject]; ok && existing != token {
+			diags.AddAttributeError(p, "Duplicate account JWT",
+				fmt.Sprintf("Account %s is included more than once with different JWTs", account.Subject))
+			return nil
+		}
+		bundle.Accounts[account.Subject] = token
+		return account
+	}
+
+	if !data.SystemAccountJWT.IsNull() {
+		systemAccount := addAccount(path.Root("system_account_jwt"), data.SystemAccountJWT.ValueString())
+		if systemAccount != nil {
+			if operator.SystemAccount != "" && operator.SystemAccount != systemAccount.Subject {
+				diags.AddAttributeError(path.Root("system_account_jwt"), "Invalid system account JWT",
+					fmt.Sprintf("Operator %s names %s as its system account, got a JWT for %s", operator.Subject, operator.SystemAccount, systemAccount.Subject))
+			}
+			bundle.SystemAccount = systemAccount.Subject
+		}
+	}
+
+	accountJWTs, d := stringListValues(ctx, data.AccountJWTs)
+	diags.Append(d...)
+	for i, token := range accountJWTs {
+		addAccount(path.Root("account_jwts").AtListIndex(i), token)
+	}
+
+	if diags.HasError() {
+		return nil, diags
+	}
+	return bundle, diags
+}
+
+// setContent renders the bundle in the configured format.
+func (m *TrustBundleResourceModel) setContent(bundle *trustBundle) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var content []byte
+	var err error
+	switch m.Format.ValueString() {
+	case trustBundleFormatTar:
+		content, err = bundle.tar()
+	default:
+		content, err = json.MarshalIndent(bundle, "", "  ")
+	}
+	if err != nil {
+		diags.AddError("Failed to render trust bundle", err.Error())
+		return diags
+	}
+
+	sum := sha256.Sum256(content)
+	m.Version = types.Int64Value(bundle.Version)
+	m.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+	m.SHA256 = types.StringValue(hex.EncodeToString(sum[:]))
+	m.Content = types.StringNull()
+	if m.Format.ValueString() != trustBundleFormatTar {
+		m.Content = types.StringValue(string(content))
+	}
+
+	return diags
+}
+
+// tar renders the bundle as a tar archive. File times are fixed so the same
+// bundle always yields the same archive and checksum.
+func (b *trustBundle) tar() ([]byte, error) {
+	manifest := *b
+	manifest.OperatorJWT = ""
+	manifest.Accounts = map[string]string{}
+	for key := range b.Accounts {
+		manifest.Accounts[key] = "accounts/" + key + ".jwt"
+	}
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(b.Accounts))
+	for key := range b.Accounts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	write := func(name string, content []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(content)),
+			ModTime: time.Unix(0, 0),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(content)
+		return err
+	}
+
+	if err := write("manifest.json", manifestJSON); err != nil {
+		return nil, err
+	}
+	if err := write("operator.jwt", []byte(b.OperatorJWT)); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := write("accounts/"+key+".jwt", []byte(b.Accounts[key])); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestAccTrustBundleResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTrustBundleResourceConfig("json", "[]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_trust_bundle.test", "version", "1"),
+					resource.TestCheckResourceAttrPair("nsc_trust_bundle.test", "id", "nsc_operator.test", "public_key"),
+					resource.TestMatchResourceAttr("nsc_trust_bundle.test", "sha256", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+					resource.TestCheckResourceAttrWith("nsc_trust_bundle.test", "content", func(value string) error {
+						var bundle trustBundle
+						if err := json.Unmarshal([]byte(value), &bundle); err != nil {
+							return err
+						}
+						if bundle.Version != 1 || bundle.SystemAccount == "" || len(bundle.Accounts) != 1 {
+							return fmt.Errorf("unexpected bundle: %s", value)
+						}
+						return nil
+					}),
+				),
+			},
+			// Unchanged configuration keeps the version
+			{
+				Config:   testAccTrustBundleResourceConfig("json", "[]"),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccTrustBundleResourceConfig("tar", "[nsc_account.app.jwt]"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_trust_bundle.test", "version", "2"),
+					resource.TestCheckNoResourceAttr("nsc_trust_bundle.test", "content"),
+					resource.TestCheckResourceAttrSet("nsc_trust_bundle.test", "content_base64"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccTrustBundleResource_foreignAccount(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccTrustBundleResourceConfig("json", "[nsc_account.foreign.jwt]"),
+				ExpectError: regexp.MustCompile(`neither the operator`),
+			},
+		},
+	})
+}
+
+func testAccTrustBundleResourceConfig(format, accountJWTs string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "other_operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "system" {
+  type = "account"
+}
+
+resource "nsc_nkey" "app" {
+  type = "account"
+}
+
+resource "nsc_operator" "test" {
+  name           = "TestOperator"
+  subject        = nsc_nkey.operator.public_key
+  issuer_seed    = nsc_nkey.operator.seed
+  system_account = nsc_nkey.system.public_key
+}
+
+resource "nsc_account" "system" {
+  name        = "SYS"
+  subject     = nsc_nkey.system.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_account" "app" {
+  name        = "App"
+  subject     = nsc_nkey.app.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_account" "foreign" {
+  name        = "Foreign"
+  subject     = nsc_nkey.app.public_key
+  issuer_seed = nsc_nkey.other_operator.seed
+}
+
+resource "nsc_trust_bundle" "test" {
+  operator_jwt       = nsc_operator.test.jwt
+  system_account_jwt = nsc_account.system.jwt
+  account_jwts       = %[2]s
+  format             = %[1]q
+}
+`, format, accountJWTs)
+}
+
+func TestTrustBundle(t *testing.T) {
+	ctx := context.Background()
+
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	signingKP, _ := nkeys.CreateOperator()
+	signingPubKey, _ := signingKP.PublicKey()
+	systemKP, _ := nkeys.CreateAccount()
+	systemPubKey, _ := systemKP.PublicKey()
+	appKP, _ := nkeys.CreateAccount()
+	appPubKey, _ := appKP.PublicKey()
+
+	operatorClaims := jwt.NewOperatorClaims(operatorPubKey)
+	operatorClaims.SystemAccount = systemPubKey
+	operatorClaims.SigningKeys.Add(signingPubKey)
+	operatorJWT, _ := operatorClaims.Encode(operatorKP)
+	systemJWT, _ := jwt.NewAccountClaims(systemPubKey).Encode(operatorKP)
+	appJWT, _ := jwt.NewAccountClaims(appPubKey).Encode(signingKP)
+
+	data := &TrustBundleResourceModel{
+		OperatorJWT:      types.StringValue(operatorJWT),
+		SystemAccountJWT: types.StringValue(systemJWT),
+		AccountJWTs:      types.ListValueMust(types.StringType, []attr.Value{types.StringValue(appJWT), types.StringValue(systemJWT)}),
+		Format:           types.StringValue(trustBundleFormatTar),
+	}
+
+	bundle, diags := buildTrustBundle(ctx, data, 3)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if bundle.SystemAccount != systemPubKey || len(bundle.Accounts) != 2 || bundle.Accounts[appPubKey] != appJWT {
+		t.Fatalf("unexpected bundle: %+v", bundle)
+	}
+
+	diags = data.setContent(bundle)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	checksum := data.SHA256.ValueString()
+	diags = data.setContent(bundle)
+	if diags.HasError() || data.SHA256.ValueString() != checksum {
+		t.Errorf("expected a reproducible tar bundle")
+	}
+
+	content, _ := base64.StdEncoding.DecodeString(data.ContentBase64.ValueString())
+	tr := tar.NewReader(bytes.NewReader(content))
+	files := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read tar bundle: %v", err)
+		}
+		body, _ := io.ReadAll(tr)
+		files[header.Name] = string(body)
+	}
+	if files["operator.jwt"] != operatorJWT || files["accounts/"+appPubKey+".jwt"] != appJWT {
+		t.Errorf("unexpected tar content: %v", files)
+	}
+	var manifest trustBundle
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil {
+		t.Fatalf("failed to decode manifest: %v", err)
+	}
+	if manifest.Version != 3 || manifest.OperatorJWT != "" || manifest.Accounts[appPubKey] != "accounts/"+appPubKey+".jwt" {
+		t.Errorf("unexpected manifest: %s", files["manifest.json"])
+	}
+
+	// A system account other than the one named by the operator
+	data.SystemAccountJWT = types.StringValue(appJWT)
+	if _, diags := buildTrustBundle(ctx, data, 1); !diags.HasError() {
+		t.Error("expected error for mismatched system account")
+	}
+}
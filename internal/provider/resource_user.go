@@ -8,7 +8,11 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -16,6 +20,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/nats-io/jwt/v2"
@@ -23,18 +28,54 @@ import (
 )
 
 var _ resource.Resource = &UserResource{}
+var _ resource.ResourceWithConfigure = &UserResource{}
+var _ resource.ResourceWithUpgradeState = &UserResource{}
+var _ resource.ResourceWithModifyPlan = &UserResource{}
+var _ resource.ResourceWithIdentity = &UserResource{}
 
 func NewUserResource() resource.Resource {
 	return &UserResource{}
 }
 
-type UserResource struct{}
+type UserResource struct {
+	signer           externalSigner
+	keys             *keypairCache
+	warnExpiryWithin time.Duration
+	requireExpiry    []string
+	policy           claimsPolicy
+	audit            *auditLog
+}
 
 type UserResourceModel struct {
-	ID               types.String         `tfsdk:"id"`
+	ID              types.String `tfsdk:"id"`
+	IssuerSeed      types.String `tfsdk:"issuer_seed"`
+	IssuerSeedEnv   types.String `tfsdk:"issuer_seed_env"`
+	IssuerKeyName   types.String `tfsdk:"issuer_key_name"`
+	IssuerPublicKey types.String `tfsdk:"issuer_public_key"`
+
+	UserClaimsModel
+
+	JWT          types.String      `tfsdk:"jwt"`
+	JWTSensitive types.String      `tfsdk:"jwt_sensitive"`
+	JWTOutput    types.String      `tfsdk:"jwt_output"`
+	IssuedAt     timetypes.RFC3339 `tfsdk:"issued_at"`
+	PinIssuedAt  types.Bool        `tfsdk:"pin_issued_at"`
+	JWTID        types.String      `tfsdk:"jwt_id"`
+	PublicKey    types.String      `tfsdk:"public_key"`
+	Seed         types.String      `tfsdk:"seed"`
+	Creds        types.String      `tfsdk:"creds"`
+
+	RotationPeriod timetypes.GoDuration `tfsdk:"rotation_period"`
+	RotateAt       timetypes.RFC3339    `tfsdk:"rotate_at"`
+
+	ClaimsPatchJSON types.String `tfsdk:"claims_patch_json"`
+}
+
+// UserClaimsModel holds the attributes that make up the user claims.
+// It is shared with the nsc_user_claims data source.
+type UserClaimsModel struct {
 	Name             types.String         `tfsdk:"name"`
 	Subject          types.String         `tfsdk:"subject"`
-	IssuerSeed       types.String         `tfsdk:"issuer_seed"`
 	IssuerAccount    types.String         `tfsdk:"issuer_account"`
 	AllowPub         types.List           `tfsdk:"allow_pub"`
 	AllowSub         types.List           `tfsdk:"allow_sub"`
@@ -42,23 +83,30 @@ type UserResourceModel struct {
 	DenySub          types.List           `tfsdk:"deny_sub"`
 	AllowPubResponse types.Int64          `tfsdk:"allow_pub_response"`
 	ResponseTTL      timetypes.GoDuration `tfsdk:"response_ttl"`
-	Bearer           types.Bool           `tfsdk:"bearer"`
-	Tag              types.List           `tfsdk:"tag"`
-	SourceNetwork    types.List           `tfsdk:"source_network"`
+
+	Permissions          *PermissionsModel `tfsdk:"permissions"`
+	NormalizePermissions types.Bool        `tfsdk:"normalize_permissions"`
+
+	Bearer        types.Bool `tfsdk:"bearer"`
+	Tag           types.List `tfsdk:"tag"`
+	SourceNetwork types.List `tfsdk:"source_network"`
 
 	// User Limits
-	MaxSubscriptions       types.Int64 `tfsdk:"max_subscriptions"`
-	MaxData                types.Int64 `tfsdk:"max_data"`
-	MaxPayload             types.Int64 `tfsdk:"max_payload"`
-	AllowedConnectionTypes types.List  `tfsdk:"allowed_connection_types"`
-
-	ExpiresIn    timetypes.GoDuration `tfsdk:"expires_in"`
-	ExpiresAt    timetypes.RFC3339    `tfsdk:"expires_at"`
-	StartsIn     timetypes.GoDuration `tfsdk:"starts_in"`
-	StartsAt     timetypes.RFC3339    `tfsdk:"starts_at"`
-	JWT          types.String         `tfsdk:"jwt"`
-	JWTSensitive types.String         `tfsdk:"jwt_sensitive"`
-	PublicKey    types.String         `tfsdk:"public_key"`
+	MaxSubscriptions       Limit      `tfsdk:"max_subscriptions"`
+	MaxData                ByteSize   `tfsdk:"max_data"`
+	MaxPayload             ByteSize   `tfsdk:"max_payload"`
+	AllowedConnectionTypes types.List `tfsdk:"allowed_connection_types"`
+
+	// Role is the JSON of an nsc_role data source, filling the permissions
+	// and limits not set on the user.
+	Role types.String `tfsdk:"role"`
+
+	// AccountJWT is the JWT of the issuing account, used for checks only.
+	AccountJWT types.String `tfsdk:"account_jwt"`
+
+	ValidityModel
+
+	CustomClaimsJSON types.String `tfsdk:"custom_claims_json"`
 }
 
 func (r *UserResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -68,6 +116,7 @@ func (r *UserResource) Metadata(ctx context.Context, req resource.MetadataReques
 func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Manages a NATS JWT User",
+		Version:             3,
 
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -89,13 +138,35 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				},
 			},
 			"issuer_seed": schema.StringAttribute{
-				Required:            true,
+				Optional:            true,
 				Sensitive:           true,
 				WriteOnly:           true,
-				MarkdownDescription: "Account seed for signing the user JWT (issuer). Never stored in state.",
+				MarkdownDescription: "Account seed for signing the user JWT (issuer). Never stored in state. Conflicts with `issuer_seed_env`, `issuer_key_name` and `issuer_public_key`; one of the four must be set.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("issuer_seed_env"), path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key")),
+					stringvalidator.AtLeastOneOf(path.MatchRoot("issuer_seed_env"), path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key")),
+				},
+			},
+			"issuer_seed_env": issuerSeedEnvAttribute(
+				"Name of the environment variable holding the account (or account signing) seed, e.g. `NATS_ACCOUNT_SEED`. Read by the provider when the user JWT is issued, so the seed only has to be present where Terraform applies, such as a CI secret. Only the name is stored in state. Alternative to `issuer_seed`.",
+				path.MatchRoot("issuer_key_name"), path.MatchRoot("issuer_public_key"),
+			),
+			"issuer_key_name": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Name of the account (or account signing) key held by the provider's external `signer` (e.g. the Vault transit key name). Alternative to `issuer_seed`; the account seed never enters Terraform.",
+			},
+			"issuer_public_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Public key of the account (or account signing) key held by the provider's external `signer`. Alternative to `issuer_seed`. When `issuer_key_name` is not set, the public key is the key reference passed to the signer; when it is set, the public key is not looked up from the signer.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^A[A-Z2-7]{55}$`),
+						"must be a valid account public key starting with 'A'",
+					),
+				},
 			},
 			"issuer_account": schema.StringAttribute{
 				Optional:            true,
@@ -112,32 +183,57 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				ElementType:         types.StringType,
 				Optional:            true,
 				MarkdownDescription: "Publish permissions. If not specified, inherits from account default permissions.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(publishPermission()),
+				},
 			},
 			"allow_sub": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "Subscribe permissions. If not specified, inherits from account default permissions.",
+				MarkdownDescription: "Subscribe permissions. Use `\"subject queue\"` to restrict subscriptions to a queue group. If not specified, inherits from account default permissions.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(subscribePermission()),
+				},
 			},
 			"deny_pub": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
 				MarkdownDescription: "Deny publish permissions. If not specified, inherits from account default permissions.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(publishPermission()),
+				},
 			},
 			"deny_sub": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
-				MarkdownDescription: "Deny subscribe permissions. If not specified, inherits from account default permissions.",
+				MarkdownDescription: "Deny subscribe permissions. Use `\"subject queue\"` to target a queue group. If not specified, inherits from account default permissions.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(subscribePermission()),
+				},
 			},
 			"allow_pub_response": schema.Int64Attribute{
 				Optional:            true,
 				Computed:            true,
 				Default:             int64default.StaticInt64(0),
-				MarkdownDescription: "Allow publishing to reply subjects",
+				MarkdownDescription: "Allow publishing to reply subjects of received requests, up to this many responses per request (-1 for unlimited, 0 to disallow)",
+				Validators: []validator.Int64{
+					int64validator.Any(
+						int64validator.OneOf(0),
+						responseMaxMsgs(),
+					),
+				},
 			},
 			"response_ttl": schema.StringAttribute{
 				CustomType:          timetypes.GoDurationType{},
 				Optional:            true,
 				MarkdownDescription: "Time limit for response permissions",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"normalize_permissions": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Sort the permission subjects and remove duplicates before they go into the JWT, so reordering or repeating them, for example when refactoring variables, does not reissue the JWT. Defaults to `false`.",
 			},
 			"bearer": schema.BoolAttribute{
 				Optional:            true,
@@ -159,6 +255,9 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				CustomType:          timetypes.GoDurationType{},
 				Optional:            true,
 				MarkdownDescription: "Relative expiry duration (e.g., '720h' for 30 days, '0s' for no expiry). Mutually exclusive with `expires_at`. JWT regenerates with new expiry on any resource change (rolling expiry).",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
 			},
 			"expires_at": schema.StringAttribute{
 				CustomType:          timetypes.RFC3339Type{},
@@ -170,6 +269,9 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				CustomType:          timetypes.GoDurationType{},
 				Optional:            true,
 				MarkdownDescription: "Relative start duration (e.g., '24h' for 1 day from now, '0s' for immediately). Mutually exclusive with `starts_at`. JWT regenerates with new start time on any resource change.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
 			},
 			"starts_at": schema.StringAttribute{
 				CustomType:          timetypes.RFC3339Type{},
@@ -177,42 +279,124 @@ func (r *UserResource) Schema(ctx context.Context, req resource.SchemaRequest, r
 				Computed:            true,
 				MarkdownDescription: "Absolute start timestamp in RFC3339 format (e.g., '2025-01-01T00:00:00Z'). Can be specified directly or computed from `starts_in`. Mutually exclusive with `starts_in`. Use this for fixed start times that won't change.",
 			},
+			"backdate": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Moves the start of validity (`nbf`) into the past by this duration, e.g. `5m`, so that servers with a slightly slow clock do not reject freshly issued JWTs as not yet valid. Applies when the start is relative to now, i.e. unset or `starts_in`; has no effect with `starts_at`. Defaults to no backdating.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"rotation_period": schema.StringAttribute{
+				CustomType:          timetypes.GoDurationType{},
+				Optional:            true,
+				MarkdownDescription: "Re-issue the JWT once this period has passed since it was issued (e.g., '168h' for weekly), independent of expiry. The first plan after `rotate_at` re-issues the JWT. Combine with an `expires_in` longer than the period so credentials are replaced before they expire.",
+				Validators: []validator.String{
+					nonNegativeDuration(),
+				},
+			},
+			"rotate_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Computed:            true,
+				MarkdownDescription: "Time after which the next plan re-issues the JWT (RFC3339). Null without `rotation_period`.",
+			},
 			"jwt": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "Generated JWT token. Only populated when bearer = false. For bearer tokens, use jwt_sensitive instead.",
+				MarkdownDescription: "Generated JWT token. Only populated when `jwt_output = \"always\"` (the default when bearer = false). For bearer tokens, use jwt_sensitive instead.",
 			},
 			"jwt_sensitive": schema.StringAttribute{
 				Computed:            true,
 				Sensitive:           true,
-				MarkdownDescription: "Generated JWT token (always populated, marked as sensitive). Use this when bearer = true.",
+				MarkdownDescription: "Generated JWT token (marked as sensitive). Populated unless `jwt_output = \"never\"`. Use this when bearer = true.",
+			},
+			"jwt_output": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Controls which JWT attributes are populated: `always` populates both `jwt` and `jwt_sensitive`, `sensitive_only` populates `jwt_sensitive` only, `never` populates neither (use `creds` instead). Defaults to `always` for regular users and `sensitive_only` for bearer users.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(jwtOutputAlways, jwtOutputSensitiveOnly, jwtOutputNever),
+				},
+			},
+			"pin_issued_at": pinIssuedAtAttribute(),
+			"issued_at": schema.StringAttribute{
+				CustomType:          timetypes.RFC3339Type{},
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Time the JWT was issued (`iat` claim). Set it to pin the JWT to a fixed `iat`, see `pin_issued_at`. Revocations of the user apply to JWTs issued at or before the revocation time.",
+			},
+			"jwt_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID of the JWT (`jti` claim), a hash of its claims. Populated regardless of `jwt_output`.",
 			},
 			"public_key": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "User public key (same as subject)",
 			},
+			"seed": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "User seed (private key). When provided, `creds` is populated with a ready-to-use credentials file. Must match `subject`. Seeds encrypted with the provider's `state_encryption_key` are decrypted for `creds`.",
+			},
+			"creds": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Credentials file content in NATS format. Only populated when `seed` is set.",
+			},
 
 			// User Limits
-			"max_subscriptions": schema.Int64Attribute{
+			"max_subscriptions": schema.StringAttribute{
+				CustomType:          LimitType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of subscriptions (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of subscriptions (-1 or `unlimited` for unlimited)",
+				Validators: []validator.String{
+					limitAtLeast(-1),
+				},
 			},
-			"max_data": schema.Int64Attribute{
+			"max_data": schema.StringAttribute{
+				CustomType:          ByteSizeType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum number of bytes (-1 for unlimited)",
+				MarkdownDescription: "Maximum number of bytes, e.g. `100MiB` (-1 or `unlimited` for unlimited)",
+				Validators: []validator.String{
+					limitAtLeast(-1),
+				},
 			},
-			"max_payload": schema.Int64Attribute{
+			"max_payload": schema.StringAttribute{
+				CustomType:          ByteSizeType{},
 				Optional:            true,
-				MarkdownDescription: "Maximum message payload in bytes (-1 for unlimited)",
+				MarkdownDescription: "Maximum message payload, e.g. `1MiB` (-1 or `unlimited` for unlimited). Cannot exceed `max_data`.",
+				Validators: []validator.String{
+					limitAtLeast(-1),
+				},
 			},
 			"allowed_connection_types": schema.ListAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
 				MarkdownDescription: "Allowed connection types (STANDARD, WEBSOCKET, LEAFNODE, LEAFNODE_WS, MQTT, MQTT_WS, IN_PROCESS)",
 			},
+			"role": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Role of the user, e.g. `data.nsc_role.publisher.role`. Permissions, limits and allowed connection types not set on the user are taken from the role; an `allow_pub_response` of `0` counts as not set. With a `permissions` block, the permissions of the role are not used.",
+				Validators: []validator.String{
+					userRoleValidator{},
+				},
+			},
+			"account_jwt": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "JWT of the issuing account. Not part of the user JWT; when set, the issuer must be the account or one of its signing keys, and `max_subscriptions`, `max_data` and `max_payload` are checked against the account limits.",
+			},
+			"custom_claims_json": customClaimsJSONAttribute("user"),
+			"claims_patch_json":  claimsPatchJSONAttribute("user"),
+		},
+		Blocks: map[string]schema.Block{
+			"permissions": permissionsBlock("Permissions of the user."),
 		},
 	}
 }
 
+func (r *UserResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = publicKeyIdentitySchema("User public key (subject)")
+}
+
 func (r *UserResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
 	var data UserResourceModel
 
@@ -221,27 +405,212 @@ func (r *UserResource) ValidateConfig(ctx context.Context, req resource.Validate
 		return
 	}
 
-	// Validate expiry attributes are mutually exclusive
-	if !data.ExpiresIn.IsNull() && !data.ExpiresIn.IsUnknown() && !data.ExpiresAt.IsNull() && !data.ExpiresAt.IsUnknown() {
-		resp.Diagnostics.AddError(
-			"Conflicting Expiry Configuration",
-			"Only one of 'expires_in' or 'expires_at' can be specified.",
+	resp.Diagnostics.Append(data.validate()...)
+	resp.Diagnostics.Append(data.validateLimits()...)
+
+	// A pinned JWT keeps its iat when re-issued, so rotate_at would stay due
+	if !data.RotationPeriod.IsNull() && (data.PinIssuedAt.ValueBool() || !data.IssuedAt.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rotation_period"),
+			"Conflicting rotation_period",
+			"rotation_period cannot be combined with pin_issued_at or issued_at, as a pinned JWT keeps its iat when re-issued",
 		)
 	}
+	if data.Permissions != nil {
+		resp.Diagnostics.Append(data.Permissions.validate(ctx, path.Root("permissions"))...)
+	}
+
+	// The issuer is only known here when given as a seed or public key;
+	// issuer_key_name and issuer_seed_env are checked on apply. Seeds
+	// encrypted with the state encryption key can only be checked once the
+	// provider is configured, which terraform validate does not do.
+	if !data.IssuerSeed.IsNull() && !data.IssuerSeed.IsUnknown() {
+		if kp, err := r.keys.fromSeed(data.IssuerSeed.ValueString()); err == nil {
+			if issuerPubKey, err := kp.PublicKey(); err == nil {
+				resp.Diagnostics.Append(data.validateIssuer(issuerPubKey)...)
+			}
+		}
+	} else if !data.IssuerPublicKey.IsNull() && !data.IssuerPublicKey.IsUnknown() {
+		resp.Diagnostics.Append(data.validateIssuer(data.IssuerPublicKey.ValueString())...)
+	}
+}
+
+func (r *UserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
 
-	// Validate start attributes are mutually exclusive
-	if !data.StartsIn.IsNull() && !data.StartsIn.IsUnknown() && !data.StartsAt.IsNull() && !data.StartsAt.IsUnknown() {
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
 		resp.Diagnostics.AddError(
-			"Conflicting Start Configuration",
-			"Only one of 'starts_in' or 'starts_at' can be specified.",
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
+		return
+	}
+
+	r.signer = providerData.Signer
+	r.keys = providerData.Keys
+	r.warnExpiryWithin = providerData.WarnExpiryWithin
+	r.requireExpiry = providerData.RequireExpiry
+	r.policy = providerData.Policy
+	r.audit = providerData.Audit
+}
+
+// ModifyPlan marks the JWT outputs and creds unknown whenever the JWT is
+// reissued, so resources referencing them plan their own updates in the same
+// run. Outputs that stay empty under the jwt_output mode are planned as null.
+func (r *UserResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	resp.Diagnostics.Append(requireExpiryPlan(ctx, req, r.requireExpiry, "user")...)
+	resp.Diagnostics.Append(r.checkPolicyPlan(ctx, req)...)
+
+	rotationDue, diags := userRotationDue(ctx, req.State)
+	resp.Diagnostics.Append(diags...)
+	if !jwtPlanned(req) && !rotationDue {
+		return
+	}
+
+	var data UserResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Under normalize_permissions, permissions differing only in order or
+	// duplicates make the same JWT, so changes to them alone keep it
+	if !req.State.Raw.IsNull() && !rotationDue && data.NormalizePermissions.ValueBool() {
+		var state UserResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if state.NormalizePermissions.ValueBool() &&
+			checksOnlyPlan(req, normalizedPermissionAttributes(ctx, "permissions",
+				userPermissionAttributes(state), userPermissionAttributes(data))...) {
+			state.AllowPub, state.AllowSub = data.AllowPub, data.AllowSub
+			state.DenyPub, state.DenySub = data.DenyPub, data.DenySub
+			state.Permissions = data.Permissions
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, &state)...)
+			return
+		}
+	}
+
+	// A re-issue moves rotate_at, the timestamps given as durations and
+	// issued_at unless it is pinned
+	rotateAt := timetypes.NewRFC3339Null()
+	if !data.RotationPeriod.IsNull() {
+		rotateAt = timetypes.NewRFC3339Unknown()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("rotate_at"), rotateAt)...)
+	if !data.ExpiresIn.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("expires_at"), timetypes.NewRFC3339Unknown())...)
+	}
+	if !data.StartsIn.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("starts_at"), timetypes.NewRFC3339Unknown())...)
+	}
+	var issuedAt timetypes.RFC3339
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("issued_at"), &issuedAt)...)
+	if issuedAt.IsNull() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("issued_at"), timetypes.NewRFC3339Unknown())...)
 	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt_id"), types.StringUnknown())...)
+
+	// jwt_output defaults depend on bearer, which may itself be unknown
+	if data.JWTOutput.IsUnknown() && data.Bearer.IsUnknown() {
+		return
+	}
+
+	jwtOutput := userJWTOutput(data)
+	token, tokenSensitive := jwtOutputPlanValues(jwtOutput)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt_output"), jwtOutput)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt"), token)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwt_sensitive"), tokenSensitive)...)
+
+	creds := types.StringUnknown()
+	if data.Seed.IsNull() {
+		creds = types.StringNull()
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("creds"), creds)...)
 }
 
-func (r *UserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// No provider configuration needed
+// checkPolicyPlan evaluates the provider policy against the claims built from
+// the plan. Plans with claims that fail to build are left to validation and
+// apply.
+func (r *UserResource) checkPolicyPlan(ctx context.Context, req resource.ModifyPlanRequest) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if len(r.policy) == 0 || req.Plan.Raw.IsNull() {
+		return diags
+	}
+
+	var data UserResourceModel
+	diags.Append(req.Plan.Get(ctx, &data)...)
+	expiresIn, expiresAt, expiryUnknown, d := policyPlanExpiry(ctx, req)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	data.Subject = planSubject(data.Subject)
+	data.ExpiresIn, data.ExpiresAt = expiresIn, expiresAt
+	claims, d := buildUserClaims(ctx, &data.UserClaimsModel)
+	if d.HasError() {
+		return diags
+	}
+
+	diags.Append(r.policy.checkPlan(claims, data.CustomClaimsJSON, data.ClaimsPatchJSON, policyUnknown{
+		tags:   data.Tag.IsUnknown(),
+		expiry: expiryUnknown,
+	})...)
+	return diags
+}
+
+func (r *UserResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	return map[int64]resource.StateUpgrader{
+		// Version 0 covers both the legacy expiry/start attributes (ADR-007)
+		// and states written before jwt_output existed.
+		0: {
+			StateUpgrader: func(_ context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				attrs, err := rawStateAttributes(req)
+				if err != nil {
+					resp.Diagnostics.AddError("Unable to Upgrade User State", err.Error())
+					return
+				}
+
+				renameStateAttribute(attrs, "expiry", "expires_in")
+				renameStateAttribute(attrs, "start", "starts_in")
+
+				// Bearer users never had jwt populated, which matches sensitive_only
+				if bearer, _ := attrs["bearer"].(bool); bearer {
+					defaultStateAttribute(attrs, "jwt_output", jwtOutputSensitiveOnly)
+				} else {
+					defaultStateAttribute(attrs, "jwt_output", jwtOutputAlways)
+				}
+
+				stringifyStateAttributes(attrs, userLimitAttributes...)
+
+				resp.DynamicValue, err = upgradedState(attrs, schemaResp.Schema)
+				if err != nil {
+					resp.Diagnostics.AddError("Unable to Upgrade User State", err.Error())
+				}
+			},
+		},
+		// Versions 1 and 2 stored limits as numbers.
+		1: stringifyUpgrader(schemaResp.Schema, "User", userLimitAttributes...),
+		2: stringifyUpgrader(schemaResp.Schema, "User", userLimitAttributes...),
+	}
 }
 
+// userLimitAttributes lists the limits that changed from numbers to strings:
+// byte limits in schema version 2 and max_subscriptions in version 3.
+var userLimitAttributes = []string{"max_data", "max_payload", "max_subscriptions"}
+
 func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var data UserResourceModel
 
@@ -268,253 +637,264 @@ func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, r
 		return
 	}
 
-	// Get account seed (issuer) for signing from Config
-	accountSeedStr := config.IssuerSeed.ValueString()
-	if accountSeedStr == "" {
-		resp.Diagnostics.AddError(
-			"Missing account seed",
-			"Account seed (issuer_seed) is required",
-		)
-		return
-	}
-
-	// Validate issuer_seed starts with 'SA' (account seed)
-	if !strings.HasPrefix(accountSeedStr, "SA") {
-		prefix := accountSeedStr
-		if len(prefix) > 2 {
-			prefix = prefix[:2]
-		}
-		resp.Diagnostics.AddError(
-			"Invalid issuer seed",
-			fmt.Sprintf("Account seed must start with 'SA', got: %s", prefix),
-		)
+	// Get account keypair (issuer) for signing
+	issuerSeed, diags := issuerSeedValue(config.IssuerSeed, data.IssuerSeedEnv)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	accountKP, err := nkeys.FromSeed([]byte(accountSeedStr))
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to parse issuer seed", err.Error())
+	accountKP, signFn, diags := userIssuer(ctx, r.signer, r.keys, data.IssuerKeyName, data.IssuerPublicKey, issuerSeed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Get the public key from issuer_seed (could be primary or signing key)
+	// Get the public key of the issuer (could be primary or signing key)
 	issuerPubKey, err := accountKP.PublicKey()
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to get public key from issuer seed", err.Error())
-		return
-	}
-	if !strings.HasPrefix(issuerPubKey, "A") {
-		resp.Diagnostics.AddError(
-			"Invalid issuer seed",
-			fmt.Sprintf("Issuer seed does not generate an account public key (expected A*, got %s)", issuerPubKey),
-		)
+		resp.Diagnostics.AddError("Failed to get public key from issuer", err.Error())
 		return
 	}
 
 	// Determine IssuerAccount field for user JWT
 	// This should be the account's primary/subject key, NOT the signing key
-	var issuerAccount string
-	if !data.IssuerAccount.IsNull() && !data.IssuerAccount.IsUnknown() {
-		// User provided explicit issuer_account
-		issuerAccount = data.IssuerAccount.ValueString()
-		// Validation already done by schema validator (must start with 'A')
-	} else {
+	// An explicit issuer_account is validated by the schema (must start with 'A')
+	if data.IssuerAccount.IsNull() || data.IssuerAccount.IsUnknown() {
 		// Auto-derive from issuer_seed (backwards compatible)
 		// When issuer_seed is the account's primary key, this works correctly
 		// When issuer_seed is a signing key, user MUST provide issuer_account explicitly
-		issuerAccount = issuerPubKey
-		data.IssuerAccount = types.StringValue(issuerAccount)
+		data.IssuerAccount = types.StringValue(issuerPubKey)
+	}
+	resp.Diagnostics.Append(data.validateIssuer(issuerPubKey)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// Create user claims
-	userClaims := jwt.NewUserClaims(userPubKey)
-	userClaims.Name = data.Name.ValueString()
-	userClaims.IssuerAccount = issuerAccount
-
-	// Handle permissions
-	if !data.AllowPub.IsNull() {
-		var allowPub []string
-		resp.Diagnostics.Append(data.AllowPub.ElementsAs(ctx, &allowPub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.Permissions.Pub.Allow = allowPub
+	userClaims, diags := buildUserClaims(ctx, &data.UserClaimsModel)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if !data.AllowSub.IsNull() {
-		var allowSub []string
-		resp.Diagnostics.Append(data.AllowSub.ElementsAs(ctx, &allowSub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.Permissions.Sub.Allow = allowSub
+	// Sign the JWT with account key
+	issuedAt, diags := pinnedIssuedAt(data.PinIssuedAt, config.IssuedAt, userClaims)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	userJWT, err := encodeClaims(ctx, userClaims, accountKP, signFn, data.CustomClaimsJSON, data.ClaimsPatchJSON, issuedAt)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to encode user JWT", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(r.policy.checkToken(userJWT, true)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if !data.DenyPub.IsNull() {
-		var denyPub []string
-		resp.Diagnostics.Append(data.DenyPub.ElementsAs(ctx, &denyPub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.Permissions.Pub.Deny = denyPub
+	// Set computed values
+	data.ID = types.StringValue(userPubKey)
+	data.PublicKey = types.StringValue(userPubKey)
+
+	// Populate jwt/jwt_sensitive according to jwt_output
+	data.JWTOutput = types.StringValue(userJWTOutput(data))
+	data.JWT, data.JWTSensitive = jwtOutputValues(data.JWTOutput.ValueString(), userJWT)
+	data.IssuedAt, data.JWTID, err = jwtIssueValues(userJWT)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode user JWT", err.Error())
+		return
+	}
+	issuedTime, diags := data.IssuedAt.ValueRFC3339Time()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.RotateAt, diags = userRotateAt(data.RotationPeriod, issuedTime.Unix())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	if !data.DenySub.IsNull() {
-		var denySub []string
-		resp.Diagnostics.Append(data.DenySub.ElementsAs(ctx, &denySub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.Permissions.Sub.Deny = denySub
+	creds, diags := userCreds(r.keys, data.Seed, userPubKey, userJWT)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	data.Creds = creds
 
-	// Handle response permissions
-	if !data.AllowPubResponse.IsNull() {
-		max := data.AllowPubResponse.ValueInt64()
-		if max > 0 {
-			userClaims.Permissions.Resp = &jwt.ResponsePermission{
-				MaxMsgs: int(max),
-			}
+	resp.Diagnostics.Append(r.audit.record("nsc_user", "create", userJWT)...)
 
-			if !data.ResponseTTL.IsNull() && !data.ResponseTTL.IsUnknown() {
-				duration, diags := data.ResponseTTL.ValueGoDuration()
-				resp.Diagnostics.Append(diags...)
-				if resp.Diagnostics.HasError() {
-					return
-				}
-				userClaims.Permissions.Resp.Expires = duration
-			}
+	tflog.Trace(ctx, "created user resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
+
+func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data UserResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Fill in issued_at and jwt_id for states written before they existed
+	if data.JWTID.IsNull() {
+		if issuedAt, jwtID, err := jwtIssueValues(userStoredJWT(data)); err == nil {
+			data.IssuedAt, data.JWTID = issuedAt, jwtID
+			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		}
 	}
 
-	// Handle bearer token
-	userClaims.BearerToken = data.Bearer.ValueBool()
+	// For state-only storage, nothing to read externally
+	resp.Diagnostics.Append(expiryWarning("user", data.Name.ValueString(), data.Subject.ValueString(), data.ExpiresAt, r.warnExpiryWithin)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+}
 
-	// Handle tags
-	if !data.Tag.IsNull() {
-		var tags []string
-		resp.Diagnostics.Append(data.Tag.ElementsAs(ctx, &tags, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.Tags = tags
+func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data UserResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Handle source networks
-	if !data.SourceNetwork.IsNull() {
-		var networks []string
-		resp.Diagnostics.Append(data.SourceNetwork.ElementsAs(ctx, &networks, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.Src = networks
+	// ModifyPlan keeps the JWT when only the order of permissions changes
+	// under normalize_permissions
+	if !data.JWTID.IsUnknown() {
+		tflog.Trace(ctx, "updated user resource without reissuing the JWT")
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
+		return
 	}
 
-	// Handle expiry (support old, new, and absolute variants)
-	var expiresAtTime time.Time
-	if !data.ExpiresIn.IsNull() && !data.ExpiresIn.IsUnknown() {
-		// New relative duration - compute and store absolute
-		duration, diags := data.ExpiresIn.ValueGoDuration()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		if duration != 0 {
-			expiresAtTime = time.Now().Add(duration)
-			data.ExpiresAt = timetypes.NewRFC3339TimeValue(expiresAtTime)
-			userClaims.Expires = expiresAtTime.Unix()
-		} else {
-			data.ExpiresAt = timetypes.NewRFC3339Null()
-		}
-	} else if !data.ExpiresAt.IsNull() && !data.ExpiresAt.IsUnknown() {
-		// Absolute timestamp provided
-		expiresAtTime, diags := data.ExpiresAt.ValueRFC3339Time()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.Expires = expiresAtTime.Unix()
-	} else {
-		// No expiry specified - set to null
-		data.ExpiresAt = timetypes.NewRFC3339Null()
-	}
-
-	// Handle start time (support old, new, and absolute variants)
-	var startsAtTime time.Time
-	if !data.StartsIn.IsNull() && !data.StartsIn.IsUnknown() {
-		// New relative duration - compute and store absolute
-		duration, diags := data.StartsIn.ValueGoDuration()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		if duration != 0 {
-			startsAtTime = time.Now().Add(duration)
-			data.StartsAt = timetypes.NewRFC3339TimeValue(startsAtTime)
-			userClaims.NotBefore = startsAtTime.Unix()
-		} else {
-			data.StartsAt = timetypes.NewRFC3339Null()
-		}
-	} else if !data.StartsAt.IsNull() && !data.StartsAt.IsUnknown() {
-		// Absolute timestamp provided
-		startsAtTime, diags := data.StartsAt.ValueRFC3339Time()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.NotBefore = startsAtTime.Unix()
-	} else {
-		// No start time specified - set to null
-		data.StartsAt = timetypes.NewRFC3339Null()
+	// Get current state to preserve immutable fields
+	var state UserResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Set User Limits
-	if !data.MaxSubscriptions.IsNull() {
-		userClaims.Limits.Subs = data.MaxSubscriptions.ValueInt64()
+	// Get WriteOnly issuer_seed from Config
+	var config UserResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !data.MaxData.IsNull() {
-		userClaims.Limits.Data = data.MaxData.ValueInt64()
+
+	// Get user public key from state (immutable)
+	userPubKey := state.Subject.ValueString()
+
+	// Get account keypair (issuer) for signing
+	issuerSeed, diags := issuerSeedValue(config.IssuerSeed, data.IssuerSeedEnv)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	if !data.MaxPayload.IsNull() {
-		userClaims.Limits.Payload = data.MaxPayload.ValueInt64()
+	accountKP, signFn, diags := userIssuer(ctx, r.signer, r.keys, data.IssuerKeyName, data.IssuerPublicKey, issuerSeed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Set allowed connection types
-	if !data.AllowedConnectionTypes.IsNull() {
-		var connTypes []string
-		resp.Diagnostics.Append(data.AllowedConnectionTypes.ElementsAs(ctx, &connTypes, false)...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.AllowedConnectionTypes = connTypes
+	// Get the public key of the issuer (could be primary or signing key)
+	issuerPubKey, err := accountKP.PublicKey()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to get account public key", err.Error())
+		return
+	}
+
+	// Determine IssuerAccount field for user JWT
+	// This should be the account's primary/subject key, NOT the signing key
+	// An explicit issuer_account is validated by the schema (must start with 'A')
+	if data.IssuerAccount.IsNull() || data.IssuerAccount.IsUnknown() {
+		// Auto-derive from issuer_seed (backwards compatible)
+		// When issuer_seed is the account's primary key, this works correctly
+		// When issuer_seed is a signing key, user MUST provide issuer_account explicitly
+		data.IssuerAccount = types.StringValue(issuerPubKey)
+	}
+	resp.Diagnostics.Append(data.validateIssuer(issuerPubKey)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Create user claims with updated values
+	data.Subject = state.Subject
+	userClaims, diags := buildUserClaims(ctx, &data.UserClaimsModel)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
 	// Sign the JWT with account key
-	userJWT, err := userClaims.Encode(accountKP)
+	issuedAt, diags := pinnedIssuedAt(data.PinIssuedAt, config.IssuedAt, userClaims)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	userJWT, err := encodeClaims(ctx, userClaims, accountKP, signFn, data.CustomClaimsJSON, data.ClaimsPatchJSON, issuedAt)
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to encode user JWT", err.Error())
 		return
 	}
+	resp.Diagnostics.Append(r.policy.checkToken(userJWT, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Set computed values
-	data.ID = types.StringValue(userPubKey)
-	data.PublicKey = types.StringValue(userPubKey)
+	// Keep the stored JWT when no claim changed, so its iat and jti, the
+	// creds and everything else derived from the token stay the same. A
+	// rotation always issues a new JWT, and a pinned iat makes the same JWT
+	// anyway.
+	rotationDue, diags := userRotationDue(ctx, req.State)
+	resp.Diagnostics.Append(diags...)
+	stored := userStoredJWT(state)
+	if issuedAt == 0 && !rotationDue && sameJWTClaims(stored, userJWT) {
+		userJWT = stored
+	}
+	reissued := userJWT != stored
+
+	// Update JWT while preserving immutable fields
+	data.ID = state.ID
+	data.PublicKey = state.PublicKey
+	data.Subject = state.Subject
+
+	// Populate jwt/jwt_sensitive according to jwt_output
+	data.JWTOutput = types.StringValue(userJWTOutput(data))
+	data.JWT, data.JWTSensitive = jwtOutputValues(data.JWTOutput.ValueString(), userJWT)
+	data.IssuedAt, data.JWTID, err = jwtIssueValues(userJWT)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to decode user JWT", err.Error())
+		return
+	}
+	issuedTime, diags := data.IssuedAt.ValueRFC3339Time()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.RotateAt, diags = userRotateAt(data.RotationPeriod, issuedTime.Unix())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Always populate jwt_sensitive
-	data.JWTSensitive = types.StringValue(userJWT)
+	creds, diags := userCreds(r.keys, data.Seed, userPubKey, userJWT)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Creds = creds
 
-	// Only populate jwt when bearer = false (non-bearer tokens are not secrets)
-	if !data.Bearer.ValueBool() {
-		data.JWT = types.StringValue(userJWT)
-	} else {
-		data.JWT = types.StringNull()
+	if reissued {
+		resp.Diagnostics.Append(r.audit.record("nsc_user", "update", userJWT)...)
 	}
 
-	tflog.Trace(ctx, "created user resource")
+	tflog.Trace(ctx, "updated user resource")
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resp.Diagnostics.Append(setPublicKeyIdentity(ctx, resp.Identity, data.ID)...)
 }
 
-func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var data UserResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -522,101 +902,149 @@ func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	// For state-only storage, nothing to read externally
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted user resource")
 }
 
-func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var data UserResourceModel
+// userJWTOutput returns the effective jwt_output mode. When not configured,
+// non-bearer JWTs are exposed in jwt as they are not secrets on their own,
+// while bearer JWTs are only exposed through jwt_sensitive.
+func userJWTOutput(data UserResourceModel) string {
+	if !data.JWTOutput.IsNull() && !data.JWTOutput.IsUnknown() {
+		return data.JWTOutput.ValueString()
+	}
+	if data.Bearer.ValueBool() {
+		return jwtOutputSensitiveOnly
+	}
+	return jwtOutputAlways
+}
 
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() {
-		return
+// userStoredJWT returns the JWT of a user in state. With jwt_output =
+// "never" it is only kept in creds, if at all.
+func userStoredJWT(data UserResourceModel) string {
+	if data.JWTSensitive.IsNull() && !data.Creds.IsNull() {
+		token, _ := jwt.ParseDecoratedJWT([]byte(data.Creds.ValueString()))
+		return token
 	}
+	return data.JWTSensitive.ValueString()
+}
 
-	// Get current state to preserve immutable fields
-	var state UserResourceModel
-	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
-	if resp.Diagnostics.HasError() {
-		return
+// userRotateAt returns the time after which a JWT issued at issuedAt is due
+// for rotation, or null without a rotation period.
+func userRotateAt(period timetypes.GoDuration, issuedAt int64) (timetypes.RFC3339, diag.Diagnostics) {
+	if period.IsNull() || period.IsUnknown() {
+		return timetypes.NewRFC3339Null(), nil
 	}
 
-	// Get WriteOnly issuer_seed from Config
-	var config UserResourceModel
-	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
-	if resp.Diagnostics.HasError() {
-		return
+	duration, diags := period.ValueGoDuration()
+	if diags.HasError() {
+		return timetypes.NewRFC3339Null(), diags
 	}
 
-	// Get user public key from state and account seed from config (both immutable)
-	userPubKey := state.Subject.ValueString()
-	accountSeedStr := config.IssuerSeed.ValueString()
+	return timetypes.NewRFC3339TimeValue(time.Unix(issuedAt, 0).UTC().Add(duration)), diags
+}
+
+// userRotationDue reports whether the JWT in state has passed its rotate_at.
+func userRotationDue(ctx context.Context, state tfsdk.State) (bool, diag.Diagnostics) {
+	if state.Raw.IsNull() {
+		return false, nil
+	}
+
+	var rotateAt timetypes.RFC3339
+	diags := state.GetAttribute(ctx, path.Root("rotate_at"), &rotateAt)
+	if diags.HasError() || rotateAt.IsNull() || rotateAt.IsUnknown() {
+		return false, diags
+	}
+
+	t, d := rotateAt.ValueRFC3339Time()
+	diags.Append(d...)
+	if diags.HasError() {
+		return false, diags
+	}
+
+	return !time.Now().Before(t), diags
+}
+
+// userCreds renders the creds attribute when a user seed is configured.
+// The seed must belong to the user the JWT was issued for.
+func userCreds(keys *keypairCache, seed types.String, userPubKey, userJWT string) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
 
-	accountKP, err := nkeys.FromSeed([]byte(accountSeedStr))
+	if seed.IsNull() || seed.IsUnknown() {
+		return types.StringNull(), diags
+	}
+
+	userSeed, err := keys.seed(seed.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to restore account keypair", err.Error())
-		return
+		diags.AddAttributeError(path.Root("seed"), "Failed to decrypt user seed", err.Error())
+		return types.StringNull(), diags
 	}
 
-	// Get the public key from issuer_seed (could be primary or signing key)
-	issuerPubKey, err := accountKP.PublicKey()
+	userKP, err := nkeys.FromSeed([]byte(userSeed))
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to get account public key", err.Error())
-		return
+		diags.AddAttributeError(path.Root("seed"), "Failed to parse user seed", err.Error())
+		return types.StringNull(), diags
 	}
 
-	// Determine IssuerAccount field for user JWT
-	// This should be the account's primary/subject key, NOT the signing key
-	var issuerAccount string
-	if !data.IssuerAccount.IsNull() && !data.IssuerAccount.IsUnknown() {
-		// User provided explicit issuer_account
-		issuerAccount = data.IssuerAccount.ValueString()
-		// Validation already done by schema validator (must start with 'A')
-	} else {
-		// Auto-derive from issuer_seed (backwards compatible)
-		// When issuer_seed is the account's primary key, this works correctly
-		// When issuer_seed is a signing key, user MUST provide issuer_account explicitly
-		issuerAccount = issuerPubKey
-		data.IssuerAccount = types.StringValue(issuerAccount)
+	seedPubKey, err := userKP.PublicKey()
+	if err != nil {
+		diags.AddAttributeError(path.Root("seed"), "Failed to get public key from user seed", err.Error())
+		return types.StringNull(), diags
+	}
+	if seedPubKey != userPubKey {
+		diags.AddAttributeError(
+			path.Root("seed"),
+			"Key mismatch",
+			fmt.Sprintf("User seed produces public key %s, but subject is %s", seedPubKey, userPubKey),
+		)
+		return types.StringNull(), diags
 	}
 
-	// Create user claims with updated values
-	userClaims := jwt.NewUserClaims(userPubKey)
+	return types.StringValue(formatCreds(userJWT, userSeed)), diags
+}
+
+// buildUserClaims creates unsigned user claims from the model. Relative
+// expiry and start times are resolved into the absolute model attributes.
+func buildUserClaims(ctx context.Context, data *UserClaimsModel) (*jwt.UserClaims, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	userClaims := jwt.NewUserClaims(data.Subject.ValueString())
 	userClaims.Name = data.Name.ValueString()
-	userClaims.IssuerAccount = issuerAccount
+	userClaims.IssuerAccount = data.IssuerAccount.ValueString()
 
-	// Handle permissions (same as create)
+	// Handle permissions
 	if !data.AllowPub.IsNull() {
-		var allowPub []string
-		resp.Diagnostics.Append(data.AllowPub.ElementsAs(ctx, &allowPub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		allowPub, d := stringListValues(ctx, data.AllowPub)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		userClaims.Permissions.Pub.Allow = allowPub
 	}
 
 	if !data.AllowSub.IsNull() {
-		var allowSub []string
-		resp.Diagnostics.Append(data.AllowSub.ElementsAs(ctx, &allowSub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		allowSub, d := stringListValues(ctx, data.AllowSub)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		userClaims.Permissions.Sub.Allow = allowSub
 	}
 
 	if !data.DenyPub.IsNull() {
-		var denyPub []string
-		resp.Diagnostics.Append(data.DenyPub.ElementsAs(ctx, &denyPub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		denyPub, d := stringListValues(ctx, data.DenyPub)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		userClaims.Permissions.Pub.Deny = denyPub
 	}
 
 	if !data.DenySub.IsNull() {
-		var denySub []string
-		resp.Diagnostics.Append(data.DenySub.ElementsAs(ctx, &denySub, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		denySub, d := stringListValues(ctx, data.DenySub)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		userClaims.Permissions.Sub.Deny = denySub
 	}
@@ -624,101 +1052,57 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 	// Handle response permissions
 	if !data.AllowPubResponse.IsNull() {
 		max := data.AllowPubResponse.ValueInt64()
-		if max > 0 {
+		if max > 0 || max == responseUnlimited {
 			userClaims.Permissions.Resp = &jwt.ResponsePermission{
 				MaxMsgs: int(max),
 			}
 
 			if !data.ResponseTTL.IsNull() && !data.ResponseTTL.IsUnknown() {
-				duration, diags := data.ResponseTTL.ValueGoDuration()
-				resp.Diagnostics.Append(diags...)
-				if resp.Diagnostics.HasError() {
-					return
+				duration, d := data.ResponseTTL.ValueGoDuration()
+				diags.Append(d...)
+				if diags.HasError() {
+					return nil, diags
 				}
 				userClaims.Permissions.Resp.Expires = duration
 			}
 		}
 	}
 
+	// Handle structured permissions (exclusive with the flat attributes)
+	if data.Permissions != nil {
+		diags.Append(data.Permissions.apply(ctx, &userClaims.Permissions)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
 	// Handle bearer token
 	userClaims.BearerToken = data.Bearer.ValueBool()
 
 	// Handle tags
 	if !data.Tag.IsNull() {
-		var tags []string
-		resp.Diagnostics.Append(data.Tag.ElementsAs(ctx, &tags, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		tags, d := stringListValues(ctx, data.Tag)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		userClaims.Tags = tags
 	}
 
 	// Handle source networks
 	if !data.SourceNetwork.IsNull() {
-		var networks []string
-		resp.Diagnostics.Append(data.SourceNetwork.ElementsAs(ctx, &networks, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		networks, d := stringListValues(ctx, data.SourceNetwork)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		userClaims.Src = networks
 	}
 
-	// Handle expiry (support old, new, and absolute variants)
-	var expiresAtTime time.Time
-	if !data.ExpiresIn.IsNull() && !data.ExpiresIn.IsUnknown() {
-		// New relative duration - compute and store absolute
-		duration, diags := data.ExpiresIn.ValueGoDuration()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		if duration != 0 {
-			expiresAtTime = time.Now().Add(duration)
-			data.ExpiresAt = timetypes.NewRFC3339TimeValue(expiresAtTime)
-			userClaims.Expires = expiresAtTime.Unix()
-		} else {
-			data.ExpiresAt = timetypes.NewRFC3339Null()
-		}
-	} else if !data.ExpiresAt.IsNull() && !data.ExpiresAt.IsUnknown() {
-		// Absolute timestamp provided
-		expiresAtTime, diags := data.ExpiresAt.ValueRFC3339Time()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.Expires = expiresAtTime.Unix()
-	} else {
-		// No expiry specified - set to null
-		data.ExpiresAt = timetypes.NewRFC3339Null()
-	}
-
-	// Handle start time (support old, new, and absolute variants)
-	var startsAtTime time.Time
-	if !data.StartsIn.IsNull() && !data.StartsIn.IsUnknown() {
-		// New relative duration - compute and store absolute
-		duration, diags := data.StartsIn.ValueGoDuration()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		if duration != 0 {
-			startsAtTime = time.Now().Add(duration)
-			data.StartsAt = timetypes.NewRFC3339TimeValue(startsAtTime)
-			userClaims.NotBefore = startsAtTime.Unix()
-		} else {
-			data.StartsAt = timetypes.NewRFC3339Null()
-		}
-	} else if !data.StartsAt.IsNull() && !data.StartsAt.IsUnknown() {
-		// Absolute timestamp provided
-		startsAtTime, diags := data.StartsAt.ValueRFC3339Time()
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		userClaims.NotBefore = startsAtTime.Unix()
-	} else {
-		// No start time specified - set to null
-		data.StartsAt = timetypes.NewRFC3339Null()
+	// Handle expiry and start time
+	diags.Append(data.ValidityModel.apply(&userClaims.ClaimsData)...)
+	if diags.HasError() {
+		return nil, diags
 	}
 
 	// Set User Limits
@@ -734,48 +1118,185 @@ func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, r
 
 	// Set allowed connection types
 	if !data.AllowedConnectionTypes.IsNull() {
-		var connTypes []string
-		resp.Diagnostics.Append(data.AllowedConnectionTypes.ElementsAs(ctx, &connTypes, false)...)
-		if resp.Diagnostics.HasError() {
-			return
+		connTypes, d := stringListValues(ctx, data.AllowedConnectionTypes)
+		diags.Append(d...)
+		if diags.HasError() {
+			return nil, diags
 		}
 		userClaims.AllowedConnectionTypes = connTypes
 	}
 
-	// Sign the JWT with account key
-	userJWT, err := userClaims.Encode(accountKP)
+	// Fill in the rest from the role
+	if !data.Role.IsNull() && !data.Role.IsUnknown() {
+		role, err := decodeUserRole(data.Role.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("role"), "Invalid role", err.Error())
+			return nil, diags
+		}
+		role.apply(data, userClaims)
+	}
+
+	if data.NormalizePermissions.ValueBool() {
+		normalizePermissionLists(&userClaims.Permissions)
+	}
+
+	return userClaims, diags
+}
+
+// validateLimits checks the user limits against each other and, when
+// account_jwt is set, against the limits of the issuing account. Unknown
+// values are skipped.
+func (m UserClaimsModel) validateLimits() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	maxPayload, maxData := m.MaxPayload, m.MaxData
+	if !maxPayload.IsNull() && !maxPayload.IsUnknown() && !maxData.IsNull() && !maxData.IsUnknown() &&
+		maxData.ValueInt64() >= 0 && (maxPayload.ValueInt64() < 0 || maxPayload.ValueInt64() > maxData.ValueInt64()) {
+		diags.AddAttributeError(
+			path.Root("max_payload"),
+			"Invalid user limits",
+			fmt.Sprintf("max_payload (%d) cannot exceed max_data (%d); a single message would exceed the data limit.", maxPayload.ValueInt64(), maxData.ValueInt64()),
+		)
+	}
+
+	if m.AccountJWT.IsNull() || m.AccountJWT.IsUnknown() {
+		return diags
+	}
+	account, err := jwt.DecodeAccountClaims(m.AccountJWT.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to encode user JWT", err.Error())
-		return
+		diags.AddAttributeError(path.Root("account_jwt"), "Invalid account JWT", "Failed to decode account JWT: "+err.Error())
+		return diags
 	}
 
-	// Update JWT while preserving immutable fields
-	data.ID = state.ID
-	data.PublicKey = state.PublicKey
-	data.Subject = state.Subject
+	limits := []struct {
+		name    string
+		value   types.Int64
+		account int64
+	}{
+		{"max_subscriptions", m.MaxSubscriptions.Int64(), account.Limits.Subs},
+		{"max_data", m.MaxData.Int64(), account.Limits.Data},
+		{"max_payload", m.MaxPayload.Int64(), account.Limits.Payload},
+	}
+	for _, limit := range limits {
+		if limit.value.IsNull() || limit.value.IsUnknown() || limit.account < 0 {
+			continue
+		}
+		if value := limit.value.ValueInt64(); value < 0 || value > limit.account {
+			diags.AddAttributeError(
+				path.Root(limit.name),
+				"User limit exceeds account limit",
+				fmt.Sprintf("%s (%d) exceeds the limit of %d set by account %s.", limit.name, value, limit.account, account.Subject),
+			)
+		}
+	}
+
+	return diags
+}
 
-	// Always populate jwt_sensitive
-	data.JWTSensitive = types.StringValue(userJWT)
+// validateIssuer checks, when account_jwt is set, that the issuer is the
+// account or one of its signing keys and that issuer_account names the
+// account. A null issuer_account defaults to the issuer.
+func (m UserClaimsModel) validateIssuer(issuer string) diag.Diagnostics {
+	var diags diag.Diagnostics
 
-	// Only populate jwt when bearer = false (non-bearer tokens are not secrets)
-	if !data.Bearer.ValueBool() {
-		data.JWT = types.StringValue(userJWT)
-	} else {
-		data.JWT = types.StringNull()
+	if m.AccountJWT.IsNull() || m.AccountJWT.IsUnknown() {
+		return diags
+	}
+	account, err := jwt.DecodeAccountClaims(m.AccountJWT.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("account_jwt"), "Invalid account JWT", "Failed to decode account JWT: "+err.Error())
+		return diags
 	}
 
-	tflog.Trace(ctx, "updated user resource")
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if issuer != account.Subject && !account.SigningKeys.Contains(issuer) {
+		diags.AddAttributeError(
+			path.Root("account_jwt"),
+			"Issuer does not belong to account",
+			fmt.Sprintf("The user is issued by %s, which is neither account %s nor one of its signing keys.", issuer, account.Subject),
+		)
+		return diags
+	}
+
+	if m.IssuerAccount.IsUnknown() {
+		return diags
+	}
+	issuerAccount := issuer
+	if !m.IssuerAccount.IsNull() {
+		issuerAccount = m.IssuerAccount.ValueString()
+	}
+	if issuerAccount != account.Subject {
+		diags.AddAttributeError(
+			path.Root("issuer_account"),
+			"Issuer account mismatch",
+			fmt.Sprintf("issuer_account is %s, but the issuer belongs to account %s. Set issuer_account to the account public key.", issuerAccount, account.Subject),
+		)
+	}
+
+	return diags
 }
 
-func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var data UserResourceModel
+// userIssuer returns the account keypair used to sign the user JWT.
+// With issuer_key_name or issuer_public_key the keypair only holds the public
+// key and signing is delegated to the provider's external signer through the
+// returned SignFn.
+func userIssuer(ctx context.Context, signer externalSigner, keys *keypairCache, keyName, publicKey, seed types.String) (nkeys.KeyPair, jwt.SignFn, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !keyName.IsNull() || !publicKey.IsNull() {
+		accountKP, signFn, err := externalIssuer(ctx, signer, keyName.ValueString(), publicKey.ValueString(), nkeys.PrefixByteAccount)
+		if err != nil {
+			diags.AddError("Failed to load account key", err.Error())
+			return nil, nil, diags
+		}
+		return accountKP, signFn, diags
+	}
 
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() {
-		return
+	// Get account seed (issuer) for signing from Config
+	accountSeedStr, err := keys.seed(seed.ValueString())
+	if err != nil {
+		diags.AddError("Invalid issuer seed", err.Error())
+		return nil, nil, diags
+	}
+	if accountSeedStr == "" {
+		diags.AddError(
+			"Missing account seed",
+			"Account seed (issuer_seed) is required",
+		)
+		return nil, nil, diags
 	}
 
-	// Nothing to clean up - all data is in state
-	tflog.Trace(ctx, "deleted user resource")
+	// Validate issuer_seed starts with 'SA' (account seed)
+	if !strings.HasPrefix(accountSeedStr, "SA") {
+		prefix := accountSeedStr
+		if len(prefix) > 2 {
+			prefix = prefix[:2]
+		}
+		diags.AddError(
+			"Invalid issuer seed",
+			fmt.Sprintf("Account seed must start with 'SA', got: %s", prefix),
+		)
+		return nil, nil, diags
+	}
+
+	accountKP, err := keys.fromSeed(accountSeedStr)
+	if err != nil {
+		diags.AddError("Failed to parse issuer seed", err.Error())
+		return nil, nil, diags
+	}
+
+	// Get the public key from issuer_seed (could be primary or signing key)
+	issuerPubKey, err := accountKP.PublicKey()
+	if err != nil {
+		diags.AddError("Failed to get public key from issuer seed", err.Error())
+		return nil, nil, diags
+	}
+	if !strings.HasPrefix(issuerPubKey, "A") {
+		diags.AddError(
+			"Invalid issuer seed",
+			fmt.Sprintf("Issuer seed does not generate an account public key (expected A*, got %s)", issuerPubKey),
+		)
+		return nil, nil, diags
+	}
+
+	return accountKP, nil, diags
 }
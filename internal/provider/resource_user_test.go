@@ -1,13 +1,22 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
 )
 
 func TestAccUserResource_basic(t *testing.T) {
@@ -26,6 +35,7 @@ func TestAccUserResource_basic(t *testing.T) {
 					resource.TestCheckResourceAttrSet("nsc_user.test", "public_key"),
 					testAccCheckUserPublicKeyFormat("nsc_user.test", "public_key"),
 					testAccCheckUserPublicKeyFormat("nsc_user.test", "subject"),
+					testAccCheckJWTIssueValues("nsc_user.test", "jwt"),
 				),
 			},
 			// Update and Read testing
@@ -33,6 +43,7 @@ func TestAccUserResource_basic(t *testing.T) {
 				Config: testAccUserResourceConfig("UpdatedUser"),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("nsc_user.test", "name", "UpdatedUser"),
+					testAccCheckJWTIssueValues("nsc_user.test", "jwt"),
 				),
 			},
 		},
@@ -75,6 +86,47 @@ func TestAccUserResource_withPermissions(t *testing.T) {
 	})
 }
 
+func TestAccUserResource_normalizePermissions(t *testing.T) {
+	var userJWTID string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfigWithNormalizedPermissions(`"app.b.>", "app.a.>", "app.b.>"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "allow_pub.#", "3"),
+					testAccCheckUserClaims("nsc_user.test", func(claims *jwt.UserClaims) error {
+						expected := jwt.StringList{"app.a.>", "app.b.>"}
+						if !slices.Equal(claims.Pub.Allow, expected) {
+							return fmt.Errorf("expected allow_pub %v in the JWT, got %v", expected, claims.Pub.Allow)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttrWith("nsc_user.test", "jwt_id", func(value string) error {
+						userJWTID = value
+						return nil
+					}),
+				),
+			},
+			// Reordered permissions make the same JWT
+			{
+				Config: testAccUserResourceConfigWithNormalizedPermissions(`"app.a.>", "app.b.>"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "allow_pub.#", "2"),
+					resource.TestCheckResourceAttrWith("nsc_user.test", "jwt_id", func(value string) error {
+						if value != userJWTID {
+							return fmt.Errorf("expected the user JWT to be kept")
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
 func TestAccUserResource_withLimits(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
@@ -118,13 +170,34 @@ func TestAccUserResource_withResponsePermissions(t *testing.T) {
 		Steps: []resource.TestStep{
 			// Create with response permissions
 			{
-				Config: testAccUserResourceConfigWithResponsePermissions(),
+				Config: testAccUserResourceConfigWithResponsePermissions(3),
 				Check: resource.ComposeAggregateTestCheckFunc(
 					resource.TestCheckResourceAttr("nsc_user.test", "name", "TestUser"),
 					resource.TestCheckResourceAttr("nsc_user.test", "allow_pub_response", "3"),
 					resource.TestCheckResourceAttr("nsc_user.test", "response_ttl", "5s"),
 				),
 			},
+			// Unlimited responses
+			{
+				Config: testAccUserResourceConfigWithResponsePermissions(-1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "allow_pub_response", "-1"),
+					resource.TestCheckResourceAttrWith("nsc_user.test", "jwt", func(value string) error {
+						claims, err := jwt.DecodeUserClaims(value)
+						if err != nil {
+							return err
+						}
+						if claims.Permissions.Resp == nil || claims.Permissions.Resp.MaxMsgs != -1 {
+							return fmt.Errorf("expected unlimited response permission, got %+v", claims.Permissions.Resp)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config:      testAccUserResourceConfigWithResponsePermissions(-2),
+				ExpectError: regexp.MustCompile(`allow_pub_response`),
+			},
 		},
 	})
 }
@@ -314,6 +387,42 @@ resource "nsc_user" "test" {
 `
 }
 
+func testAccUserResourceConfigWithNormalizedPermissions(allowPub string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_operator" "test" {
+  name        = "TestOperator"
+  subject     = nsc_nkey.operator.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_user" "test" {
+  name                  = "TestUser"
+  subject               = nsc_nkey.user.public_key
+  issuer_seed           = nsc_nkey.account.seed
+  allow_pub             = [%s]
+  normalize_permissions = true
+}
+`, allowPub)
+}
+
 func testAccUserResourceConfigWithLimits() string {
 	return `
 resource "nsc_nkey" "operator" {
@@ -392,8 +501,8 @@ resource "nsc_user" "test" {
 `
 }
 
-func testAccUserResourceConfigWithResponsePermissions() string {
-	return `
+func testAccUserResourceConfigWithResponsePermissions(allowPubResponse int) string {
+	return fmt.Sprintf(`
 resource "nsc_nkey" "operator" {
   type = "operator"
 }
@@ -422,10 +531,10 @@ resource "nsc_user" "test" {
   name               = "TestUser"
   subject            = nsc_nkey.user.public_key
   issuer_seed        = nsc_nkey.account.seed
-  allow_pub_response = 3
+  allow_pub_response = %d
   response_ttl       = "5s"
 }
-`
+`, allowPubResponse)
 }
 
 func testAccUserResourceConfigWithBearerAndTags() string {
@@ -626,6 +735,36 @@ func TestAccUserResource_withStartsAt(t *testing.T) {
 	})
 }
 
+func TestAccUserResource_backdate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfigWithBackdate("5m"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "backdate", "5m"),
+					resource.TestCheckNoResourceAttr("nsc_user.test", "starts_at"),
+					resource.TestCheckResourceAttrWith("nsc_user.test", "jwt", func(value string) error {
+						claims, err := jwt.DecodeUserClaims(value)
+						if err != nil {
+							return err
+						}
+						if claims.NotBefore == 0 || claims.NotBefore > time.Now().Add(-4*time.Minute).Unix() {
+							return fmt.Errorf("expected not before about 5m in the past, got %d", claims.NotBefore)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config:      testAccUserResourceConfigWithBackdate("-5m"),
+				ExpectError: regexp.MustCompile("must not be negative"),
+			},
+		},
+	})
+}
+
 func TestAccUserResource_conflictingExpiryAttributes(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
@@ -759,6 +898,29 @@ resource "nsc_user" "test" {
 `
 }
 
+func testAccUserResourceConfigWithBackdate(backdate string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name        = "TestUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  backdate    = %q
+}
+`, backdate)
+}
+
 func testAccUserResourceConfigWithStartsAt() string {
 	return `
 resource "nsc_nkey" "operator" {
@@ -889,3 +1051,1015 @@ func testAccCheckUserCredsFormat(resourceName, attrName string) resource.TestChe
 		return nil
 	}
 }
+
+func TestAccUserResource_withQueuePermissions(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create with queue group subscribe permissions
+			{
+				Config: testAccUserResourceConfigWithQueuePermissions(`["orders.> workers", "events.*"]`, `["orders.admin.> *"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "allow_sub.#", "2"),
+					resource.TestCheckResourceAttr("nsc_user.test", "allow_sub.0", "orders.> workers"),
+					resource.TestCheckResourceAttr("nsc_user.test", "allow_sub.1", "events.*"),
+					resource.TestCheckResourceAttr("nsc_user.test", "deny_sub.0", "orders.admin.> *"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_invalidQueuePermissions(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Too many spaces in a subscribe permission
+			{
+				Config:      testAccUserResourceConfigWithQueuePermissions(`["orders.> workers extra"]`, `[]`),
+				ExpectError: regexp.MustCompile("contains too many spaces"),
+			},
+			// Wildcard that is not a full token in the queue group
+			{
+				Config:      testAccUserResourceConfigWithQueuePermissions(`["orders.> work*"]`, `[]`),
+				ExpectError: regexp.MustCompile("invalid queue group"),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_queueOnPublishPermission(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name        = "TestUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+
+  allow_pub = ["orders.> workers"]
+}
+`,
+				ExpectError: regexp.MustCompile("cannot contain a queue group"),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_templatePermissions(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfigWithQueuePermissions(`["users.{{name()}}.>", "regions.{{tag(region)}}.* {{account-name()}}"]`, `[]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "allow_sub.0", "users.{{name()}}.>"),
+					resource.TestCheckResourceAttr("nsc_user.test", "allow_sub.1", "regions.{{tag(region)}}.* {{account-name()}}"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_invalidTemplatePermissions(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Unknown template function
+			{
+				Config:      testAccUserResourceConfigWithQueuePermissions(`["users.{{username()}}.>"]`, `[]`),
+				ExpectError: regexp.MustCompile("unsupported template"),
+			},
+			// Missing closing braces
+			{
+				Config:      testAccUserResourceConfigWithQueuePermissions(`["users.{{name()"]`, `[]`),
+				ExpectError: regexp.MustCompile("unterminated template"),
+			},
+		},
+	})
+}
+
+func testAccUserResourceConfigWithQueuePermissions(allowSub, denySub string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name        = "QueueUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+
+  allow_sub = %[1]s
+  deny_sub  = %[2]s
+}
+`, allowSub, denySub)
+}
+
+func TestAccUserResource_withCreds(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create with seed - creds should be populated
+			{
+				Config: testAccUserResourceConfigWithCreds("nsc_nkey.user.seed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("nsc_user.test", "creds"),
+					testAccCheckUserCredsFormat("nsc_user.test", "creds"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_withCredsSeedMismatch(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Seed of a different user must be rejected
+			{
+				Config:      testAccUserResourceConfigWithCreds("nsc_nkey.other.seed"),
+				ExpectError: regexp.MustCompile("Key mismatch"),
+			},
+		},
+	})
+}
+
+func testAccUserResourceConfigWithCreds(seedRef string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_nkey" "other" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name        = "CredsUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  seed        = %[1]s
+}
+`, seedRef)
+}
+
+func TestAccUserResource_jwtOutput(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Non-bearer user hidden from plain outputs
+			{
+				Config: testAccUserResourceConfigWithJWTOutput(false, "sensitive_only"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "jwt_output", "sensitive_only"),
+					resource.TestCheckNoResourceAttr("nsc_user.test", "jwt"),
+					resource.TestCheckResourceAttrSet("nsc_user.test", "jwt_sensitive"),
+				),
+			},
+			// Bearer user explicitly exposed
+			{
+				Config: testAccUserResourceConfigWithJWTOutput(true, "always"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "jwt_output", "always"),
+					resource.TestCheckResourceAttrSet("nsc_user.test", "jwt"),
+					resource.TestCheckResourceAttrSet("nsc_user.test", "jwt_sensitive"),
+				),
+			},
+			// Never expose the JWT directly
+			{
+				Config: testAccUserResourceConfigWithJWTOutput(false, "never"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "jwt_output", "never"),
+					resource.TestCheckNoResourceAttr("nsc_user.test", "jwt"),
+					resource.TestCheckNoResourceAttr("nsc_user.test", "jwt_sensitive"),
+					resource.TestCheckResourceAttrSet("nsc_user.test", "issued_at"),
+					resource.TestCheckResourceAttrSet("nsc_user.test", "jwt_id"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_jwtOutputDefault(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Default follows bearer
+			{
+				Config: testAccUserResourceConfigWithBearerAndTags(),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "jwt_output", "sensitive_only"),
+					resource.TestCheckNoResourceAttr("nsc_user.test", "jwt"),
+				),
+			},
+			{
+				Config: testAccUserResourceConfig("TestUser"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "jwt_output", "always"),
+					resource.TestCheckResourceAttrSet("nsc_user.test", "jwt"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_unchangedClaims(t *testing.T) {
+	var userJWT string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfigWithJWTOutput(false, "always"),
+				Check: resource.TestCheckResourceAttrWith("nsc_user.test", "jwt_sensitive", func(value string) error {
+					userJWT = value
+					return nil
+				}),
+			},
+			// jwt_output is not a claim, so the stored JWT is kept
+			{
+				Config: testAccUserResourceConfigWithJWTOutput(false, "sensitive_only"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("nsc_user.test", "jwt"),
+					resource.TestCheckResourceAttrWith("nsc_user.test", "jwt_sensitive", func(value string) error {
+						if value != userJWT {
+							return fmt.Errorf("expected the user JWT to be kept")
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccUserResourceConfigWithJWTOutput(bearer bool, mode string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name        = "OutputUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+  bearer      = %[1]t
+  jwt_output  = %[2]q
+}
+`, bearer, mode)
+}
+
+func TestUserResource_upgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+	r := &UserResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	tests := []struct {
+		name      string
+		bearer    bool
+		jwtOutput string
+	}{
+		{name: "regular user", bearer: false, jwtOutput: jwtOutputAlways},
+		{name: "bearer user", bearer: true, jwtOutput: jwtOutputSensitiveOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Legacy state using the deprecated expiry/start attributes
+			req := fwresource.UpgradeStateRequest{
+				RawState: &tfprotov6.RawState{
+					JSON: []byte(fmt.Sprintf(`{
+						"id": "UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4",
+						"name": "Legacy",
+						"subject": "UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4",
+						"issuer_account": "ACZSWBJ4SYILK7QVDELO64VX3EFWB6CXCPMEBN3OLRLMH5H7BVCDHGPF",
+						"bearer": %t,
+						"expiry": "720h",
+						"start": "1h",
+						"allow_pub_response": 0,
+						"jwt_sensitive": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig",
+						"public_key": "UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4"
+					}`, tt.bearer)),
+				},
+			}
+			var resp fwresource.UpgradeStateResponse
+			r.UpgradeState(ctx)[0].StateUpgrader(ctx, req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			raw, err := resp.DynamicValue.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+			if err != nil {
+				t.Fatalf("upgraded state does not match schema: %s", err)
+			}
+			state := tfsdk.State{Schema: schemaResp.Schema, Raw: raw}
+
+			var data UserResourceModel
+			if diags := state.Get(ctx, &data); diags.HasError() {
+				t.Fatalf("failed to read upgraded state: %v", diags)
+			}
+
+			if data.ExpiresIn.ValueString() != "720h" {
+				t.Errorf("expected expires_in = 720h, got %q", data.ExpiresIn.ValueString())
+			}
+			if data.StartsIn.ValueString() != "1h" {
+				t.Errorf("expected starts_in = 1h, got %q", data.StartsIn.ValueString())
+			}
+			if data.JWTOutput.ValueString() != tt.jwtOutput {
+				t.Errorf("expected jwt_output = %s, got %q", tt.jwtOutput, data.JWTOutput.ValueString())
+			}
+		})
+	}
+}
+
+func TestUserResource_modifyPlan(t *testing.T) {
+	ctx := context.Background()
+	r := &UserResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	state := testRawValue(t, schemaResp.Schema, `{
+		"name": "Before",
+		"subject": "UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4",
+		"bearer": false,
+		"jwt_output": "always",
+		"jwt": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig",
+		"jwt_sensitive": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig"
+	}`)
+
+	// Switching to a bearer user with jwt_output left to its default
+	plan := testRawValue(t, schemaResp.Schema, `{
+		"name": "Before",
+		"subject": "UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4",
+		"bearer": true,
+		"jwt": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig",
+		"jwt_sensitive": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig"
+	}`)
+
+	req := fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: plan},
+		State:  tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+		Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: plan},
+	}
+	resp := fwresource.ModifyPlanResponse{
+		Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: plan},
+	}
+	r.ModifyPlan(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var data UserResourceModel
+	if diags := resp.Plan.Get(ctx, &data); diags.HasError() {
+		t.Fatalf("failed to read plan: %v", diags)
+	}
+	if data.JWTOutput.ValueString() != jwtOutputSensitiveOnly {
+		t.Errorf("expected jwt_output = %s, got %s", jwtOutputSensitiveOnly, data.JWTOutput)
+	}
+	if !data.JWT.IsNull() {
+		t.Errorf("expected jwt to be null, got %s", data.JWT)
+	}
+	if !data.JWTSensitive.IsUnknown() {
+		t.Errorf("expected jwt_sensitive to be unknown, got %s", data.JWTSensitive)
+	}
+	if !data.Creds.IsNull() {
+		t.Errorf("expected creds to be null without seed, got %s", data.Creds)
+	}
+}
+
+func TestAccUserResource_rotationPeriod(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name            = "RotatedUser"
+  subject         = nsc_nkey.user.public_key
+  issuer_seed     = nsc_nkey.account.seed
+  expires_in      = "720h"
+  rotation_period = "168h"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "rotation_period", "168h"),
+					resource.TestCheckResourceAttrSet("nsc_user.test", "rotate_at"),
+				),
+			},
+		},
+	})
+}
+
+func TestUserResource_modifyPlanRotation(t *testing.T) {
+	ctx := context.Background()
+	r := &UserResource{}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	stateJSON := func(rotateAt string) string {
+		return fmt.Sprintf(`{
+			"name": "Rotated",
+			"subject": "UDXU4RCSJNZOIQHZNWXHXORDPRTGNJAHAHFRGZNEEJCPQTT2M7NLCNF4",
+			"bearer": false,
+			"jwt_output": "always",
+			"jwt": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig",
+			"jwt_sensitive": "eyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.sig",
+			"expires_in": "720h",
+			"expires_at": "2999-01-01T00:00:00Z",
+			"rotation_period": "168h",
+			"rotate_at": %q
+		}`, rotateAt)
+	}
+
+	tests := []struct {
+		name     string
+		rotateAt string
+		reissue  bool
+	}{
+		{name: "not due", rotateAt: "2999-01-01T00:00:00Z", reissue: false},
+		{name: "due", rotateAt: "2000-01-01T00:00:00Z", reissue: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := testRawValue(t, schemaResp.Schema, stateJSON(tt.rotateAt))
+
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: state},
+				State:  tfsdk.State{Schema: schemaResp.Schema, Raw: state},
+				Plan:   tfsdk.Plan{Schema: schemaResp.Schema, Raw: state},
+			}
+			resp := fwresource.ModifyPlanResponse{
+				Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: state},
+			}
+			r.ModifyPlan(ctx, req, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			if !tt.reissue {
+				if !resp.Plan.Raw.Equal(state) {
+					t.Error("expected plan not to be modified before rotate_at")
+				}
+				return
+			}
+
+			var data UserResourceModel
+			if diags := resp.Plan.Get(ctx, &data); diags.HasError() {
+				t.Fatalf("failed to read plan: %v", diags)
+			}
+			if !data.JWT.IsUnknown() || !data.JWTSensitive.IsUnknown() {
+				t.Errorf("expected JWT to be re-issued, got jwt %s and jwt_sensitive %s", data.JWT, data.JWTSensitive)
+			}
+			if !data.RotateAt.IsUnknown() {
+				t.Errorf("expected rotate_at to be unknown, got %s", data.RotateAt)
+			}
+			if !data.ExpiresAt.IsUnknown() {
+				t.Errorf("expected expires_at to be unknown, got %s", data.ExpiresAt)
+			}
+		})
+	}
+}
+
+// BenchmarkUserJWTEncode covers the per-resource work of signing a user JWT,
+// which dominates applies of workspaces with thousands of users.
+func BenchmarkUserJWTEncode(b *testing.B) {
+	ctx := context.Background()
+
+	accountKP, err := nkeys.CreateAccount()
+	if err != nil {
+		b.Fatal(err)
+	}
+	accountSeed, _ := accountKP.Seed()
+	accountPubKey, _ := accountKP.PublicKey()
+	userKP, err := nkeys.CreateUser()
+	if err != nil {
+		b.Fatal(err)
+	}
+	userPubKey, _ := userKP.PublicKey()
+
+	list := func(values ...string) types.List {
+		l, _ := types.ListValueFrom(ctx, types.StringType, values)
+		return l
+	}
+	data := UserClaimsModel{
+		Name:                   types.StringValue("BenchUser"),
+		Subject:                types.StringValue(userPubKey),
+		IssuerAccount:          types.StringValue(accountPubKey),
+		AllowPub:               list("app.>", "events.>"),
+		AllowSub:               list("app.>", "_INBOX.>"),
+		DenyPub:                list("admin.>"),
+		DenySub:                types.ListNull(types.StringType),
+		Tag:                    list("team:a", "env:prod"),
+		SourceNetwork:          types.ListNull(types.StringType),
+		AllowedConnectionTypes: list("STANDARD"),
+	}
+	keys := newKeypairCache()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		userClaims, diags := buildUserClaims(ctx, &data)
+		if diags.HasError() {
+			b.Fatal(diags)
+		}
+		kp, err := keys.fromSeed(string(accountSeed))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := userClaims.Encode(kp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestAccUserResource_customClaimsJSON(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfigWithCustomClaims(`jsonencode({ nats = { payload = 1024 }, vendor = { tier = "gold" } })`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("nsc_user.test", "jwt", func(value string) error {
+						claims, err := jwt.DecodeUserClaims(value)
+						if err != nil {
+							return err
+						}
+						if claims.Limits.Payload != 1024 {
+							return fmt.Errorf("expected payload limit 1024, got %d", claims.Limits.Payload)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config:      testAccUserResourceConfigWithCustomClaims(`jsonencode({ sub = "UABC" })`),
+				ExpectError: regexp.MustCompile(`cannot set the standard field "sub"`),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_claimsPatchJSON(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfigWithClaimsPatch(`jsonencode({ nats = { payload = 1024, pub = { allow = ["app.>"] } } })`),
+				Check: testAccCheckUserClaims("nsc_user.test", func(claims *jwt.UserClaims) error {
+					if claims.Limits.Payload != 1024 || !claims.Pub.Allow.Contains("app.>") {
+						return fmt.Errorf("expected patched claims, got payload %d and allow_pub %v", claims.Limits.Payload, claims.Pub.Allow)
+					}
+					return nil
+				}),
+			},
+			{
+				Config:      testAccUserResourceConfigWithClaimsPatch(`jsonencode({ sub = "UABC" })`),
+				ExpectError: regexp.MustCompile(`claims patch cannot change "sub"`),
+			},
+		},
+	})
+}
+
+func testAccUserResourceConfigWithClaimsPatch(patch string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name              = "TestUser"
+  subject           = nsc_nkey.user.public_key
+  issuer_seed       = nsc_nkey.account.seed
+  claims_patch_json = %[1]s
+}
+`, patch)
+}
+
+func testAccUserResourceConfigWithCustomClaims(customClaims string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name               = "TestUser"
+  subject            = nsc_nkey.user.public_key
+  issuer_seed        = nsc_nkey.account.seed
+  custom_claims_json = %[1]s
+}
+`, customClaims)
+}
+
+func TestAccUserResource_permissionsBlock(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResourceConfigWithPermissionsBlock(`
+  permissions {
+    pub {
+      allow = ["app.>", "_INBOX.>"]
+      deny  = ["app.admin.>"]
+    }
+    sub {
+      allow = ["app.requests.>"]
+    }
+    resp {
+      max = 5
+      ttl = "1m"
+    }
+  }
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("nsc_user.test", "permissions.pub.allow.#", "2"),
+					resource.TestCheckResourceAttr("nsc_user.test", "permissions.pub.deny.0", "app.admin.>"),
+					resource.TestCheckNoResourceAttr("nsc_user.test", "allow_pub.#"),
+					testAccCheckUserClaims("nsc_user.test", func(claims *jwt.UserClaims) error {
+						if got := claims.Permissions.Pub.Allow; len(got) != 2 || got[0] != "app.>" {
+							return fmt.Errorf("expected allow_pub from permissions block, got %v", got)
+						}
+						if got := claims.Permissions.Sub.Allow; len(got) != 1 || got[0] != "app.requests.>" {
+							return fmt.Errorf("expected allow_sub from permissions block, got %v", got)
+						}
+						if claims.Permissions.Resp == nil || claims.Permissions.Resp.MaxMsgs != 5 || claims.Permissions.Resp.Expires != time.Minute {
+							return fmt.Errorf("expected response permission from permissions block, got %+v", claims.Permissions.Resp)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: testAccUserResourceConfigWithPermissionsBlock(`
+  allow_pub = ["app.>"]
+
+  permissions {
+    sub {
+      allow = ["app.>"]
+    }
+  }
+`),
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func testAccUserResourceConfigWithPermissionsBlock(body string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_user" "test" {
+  name        = "TestUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+%[1]s}
+`, body)
+}
+
+func TestAccUserResource_invalidLimits(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Negative values other than -1
+			{
+				Config:      testAccUserResourceConfigWithAccountLimits("-1", "max_subscriptions = -2"),
+				ExpectError: regexp.MustCompile("must be at least -1"),
+			},
+			// Payload larger than the data limit
+			{
+				Config:      testAccUserResourceConfigWithAccountLimits("-1", "max_data = 1024\n  max_payload = 2048"),
+				ExpectError: regexp.MustCompile(`max_payload \(2048\) cannot exceed max_data \(1024\)`),
+			},
+			// Payload larger than the account payload limit
+			{
+				Config:      testAccUserResourceConfigWithAccountLimits("1024", "max_payload = 2048\n  account_jwt = nsc_account.test.jwt"),
+				ExpectError: regexp.MustCompile(`max_payload \(2048\) exceeds the limit of 1024`),
+			},
+		},
+	})
+}
+
+func testAccUserResourceConfigWithAccountLimits(accountMaxPayload, userLimits string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+  max_payload = %[1]s
+}
+
+resource "nsc_user" "test" {
+  name        = "TestUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = nsc_nkey.account.seed
+
+  %[2]s
+}
+`, accountMaxPayload, userLimits)
+}
+
+func TestUserClaimsModel_validateLimits(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	accountClaims := jwt.NewAccountClaims(accountPubKey)
+	accountClaims.Limits.Subs = 10
+	accountClaims.Limits.Payload = 1024
+	accountJWT, _ := accountClaims.Encode(operatorKP)
+
+	tests := []struct {
+		name  string
+		model UserClaimsModel
+		err   string
+	}{
+		{
+			name:  "unset",
+			model: UserClaimsModel{},
+		},
+		{
+			name:  "payload within data",
+			model: UserClaimsModel{MaxData: NewByteSizeValue("2KiB"), MaxPayload: NewByteSizeValue("1024")},
+		},
+		{
+			name:  "payload exceeds data",
+			model: UserClaimsModel{MaxData: NewByteSizeValue("1024"), MaxPayload: NewByteSizeValue("2048")},
+			err:   "cannot exceed max_data",
+		},
+		{
+			name:  "unlimited payload with data limit",
+			model: UserClaimsModel{MaxData: NewByteSizeValue("1024"), MaxPayload: NewByteSizeValue("-1")},
+			err:   "cannot exceed max_data",
+		},
+		{
+			name:  "within account limits",
+			model: UserClaimsModel{MaxSubscriptions: NewLimitValue("10"), MaxData: NewByteSizeValue("-1"), AccountJWT: types.StringValue(accountJWT)},
+		},
+		{
+			name:  "unlimited subscriptions with account limit",
+			model: UserClaimsModel{MaxSubscriptions: NewLimitValue("unlimited"), AccountJWT: types.StringValue(accountJWT)},
+			err:   "max_subscriptions (-1) exceeds the limit of 10",
+		},
+		{
+			name:  "invalid account JWT",
+			model: UserClaimsModel{AccountJWT: types.StringValue("not-a-jwt")},
+			err:   "Failed to decode account JWT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := tt.model.validateLimits()
+			if tt.err == "" {
+				if diags.HasError() {
+					t.Errorf("unexpected error: %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || !strings.Contains(diags[0].Detail(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, diags)
+			}
+		})
+	}
+}
+
+func TestAccUserResource_accountJWTIssuer(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Signed with a different account
+			{
+				Config:      testAccUserResourceConfigWithAccountJWT("nsc_nkey.other.seed"),
+				ExpectError: regexp.MustCompile("neither account"),
+			},
+			{
+				Config: testAccUserResourceConfigWithAccountJWT("nsc_nkey.account.seed"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("nsc_user.test", "issuer_account", "nsc_nkey.account", "public_key"),
+				),
+			},
+		},
+	})
+}
+
+func testAccUserResourceConfigWithAccountJWT(issuerSeed string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "operator" {
+  type = "operator"
+}
+
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_nkey" "other" {
+  type = "account"
+}
+
+resource "nsc_nkey" "user" {
+  type = "user"
+}
+
+resource "nsc_account" "test" {
+  name        = "TestAccount"
+  subject     = nsc_nkey.account.public_key
+  issuer_seed = nsc_nkey.operator.seed
+}
+
+resource "nsc_user" "test" {
+  name        = "TestUser"
+  subject     = nsc_nkey.user.public_key
+  issuer_seed = %s
+  account_jwt = nsc_account.test.jwt
+}
+`, issuerSeed)
+}
+
+func TestUserClaimsModel_validateIssuer(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	signingKP, _ := nkeys.CreateAccount()
+	signingPubKey, _ := signingKP.PublicKey()
+	otherKP, _ := nkeys.CreateAccount()
+	otherPubKey, _ := otherKP.PublicKey()
+	accountClaims := jwt.NewAccountClaims(accountPubKey)
+	accountClaims.SigningKeys.Add(signingPubKey)
+	accountJWT, _ := accountClaims.Encode(operatorKP)
+
+	tests := []struct {
+		name   string
+		model  UserClaimsModel
+		issuer string
+		err    string
+	}{
+		{
+			name:   "no account JWT",
+			model:  UserClaimsModel{},
+			issuer: otherPubKey,
+		},
+		{
+			name:   "account key",
+			model:  UserClaimsModel{AccountJWT: types.StringValue(accountJWT)},
+			issuer: accountPubKey,
+		},
+		{
+			name:   "signing key",
+			model:  UserClaimsModel{IssuerAccount: types.StringValue(accountPubKey), AccountJWT: types.StringValue(accountJWT)},
+			issuer: signingPubKey,
+		},
+		{
+			name:   "signing key without issuer_account",
+			model:  UserClaimsModel{AccountJWT: types.StringValue(accountJWT)},
+			issuer: signingPubKey,
+			err:    "Set issuer_account",
+		},
+		{
+			name:   "other account",
+			model:  UserClaimsModel{AccountJWT: types.StringValue(accountJWT)},
+			issuer: otherPubKey,
+			err:    "neither account",
+		},
+		{
+			name:   "account key with other issuer_account",
+			model:  UserClaimsModel{IssuerAccount: types.StringValue(otherPubKey), AccountJWT: types.StringValue(accountJWT)},
+			issuer: accountPubKey,
+			err:    "Set issuer_account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := tt.model.validateIssuer(tt.issuer)
+			if tt.err == "" {
+				if diags.HasError() {
+					t.Errorf("unexpected error: %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() || !strings.Contains(diags[0].Detail(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, diags)
+			}
+		})
+	}
+}
+
+func TestUserResource_validateConfigEncryptedIssuerSeed(t *testing.T) {
+	ctx := context.Background()
+
+	cipher, err := newStateCipher(testStateEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := newKeypairCache()
+	keys.cipher = cipher
+	r := &UserResource{keys: keys}
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+
+	operatorKP, _ := nkeys.CreateOperator()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	accountJWT, _ := jwt.NewAccountClaims(accountPubKey).Encode(operatorKP)
+	otherKP, _ := nkeys.CreateAccount()
+	otherSeed, _ := otherKP.Seed()
+	encryptedSeed, err := cipher.encrypt(string(otherSeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := testRawValue(t, schemaResp.Schema, fmt.Sprintf(`{
+		"name": "TestUser",
+		"subject": "UBNNSMKKDZ3DDTIP5EWMWGAZYHIAWTLJ2RWTK3HE4XKHJ2CQIA7V3DTS",
+		"issuer_seed": %q,
+		"issuer_account": %q,
+		"account_jwt": %q
+	}`, encryptedSeed, accountPubKey, accountJWT))
+
+	resp := fwresource.ValidateConfigResponse{}
+	r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: config},
+	}, &resp)
+	if !resp.Diagnostics.HasError() || !strings.Contains(fmt.Sprint(resp.Diagnostics), "neither account") {
+		t.Errorf("expected issuer mismatch error, got %v", resp.Diagnostics)
+	}
+}
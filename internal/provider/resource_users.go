@@ -0,0 +1,722 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/nats-io/nkeys"
+)
+
+var _ resource.Resource = &UsersResource{}
+var _ resource.ResourceWithConfigure = &UsersResource{}
+var _ resource.ResourceWithModifyPlan = &UsersResource{}
+
+func NewUsersResource() resource.Resource {
+	return &UsersResource{}
+}
+
+// UsersResource creates many users of one account in a single resource. The
+// user keys are generated once and kept in state; only the JWTs of users
+// whose claims change are reissued.
+type UsersResource struct {
+	keys          *keypairCache
+	cipher        *stateCipher
+	audit         *auditLog
+	requireExpiry []string
+	policy        claimsPolicy
+}
+
+type UsersResourceModel struct {
+	ID            types.String     `tfsdk:"id"`
+	IssuerSeed    types.String     `tfsdk:"issuer_seed"`
+	IssuerSeedEnv types.String     `tfsdk:"issuer_seed_env"`
+	IssuerAccount types.String     `tfsdk:"issuer_account"`
+	Template      *UsersEntryModel `tfsdk:"template"`
+	Users         types.Map        `tfsdk:"users"`
+	PublicKeys    types.Map        `tfsdk:"public_keys"`
+	Seeds         types.Map        `tfsdk:"seeds"`
+	JWTs          types.Map        `tfsdk:"jwts"`
+	Creds         types.Map        `tfsdk:"creds"`
+}
+
+// UsersEntryModel holds the claims of the template and of the entries of
+// users. Attributes set on an entry override those of the template.
+type UsersEntryModel struct {
+	AllowPub               types.List           `tfsdk:"allow_pub"`
+	AllowSub               types.List           `tfsdk:"allow_sub"`
+	DenyPub                types.List           `tfsdk:"deny_pub"`
+	DenySub                types.List           `tfsdk:"deny_sub"`
+	AllowPubResponse       types.Int64          `tfsdk:"allow_pub_response"`
+	ResponseTTL            timetypes.GoDuration `tfsdk:"response_ttl"`
+	Bearer                 types.Bool           `tfsdk:"bearer"`
+	Tag                    types.List           `tfsdk:"tag"`
+	SourceNetwork          types.List           `tfsdk:"source_network"`
+	MaxSubscriptions       Limit                `tfsdk:"max_subscriptions"`
+	MaxData                ByteSize             `tfsdk:"max_data"`
+	MaxPayload             ByteSize             `tfsdk:"max_payload"`
+	AllowedConnectionTypes types.List           `tfsdk:"allowed_connection_types"`
+	Role                   types.String         `tfsdk:"role"`
+	ExpiresIn              timetypes.GoDuration `tfsdk:"expires_in"`
+}
+
+func (r *UsersResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_users"
+}
+
+// usersEntryAttributes returns the claim attributes shared by the template
+// block and the entries of users.
+func usersEntryAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"allow_pub": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Publish permissions",
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(publishPermission()),
+			},
+		},
+		"allow_sub": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Subscribe permissions. Use `\"subject queue\"` to restrict subscriptions to a queue group.",
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(subscribePermission()),
+			},
+		},
+		"deny_pub": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Deny publish permissions",
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(publishPermission()),
+			},
+		},
+		"deny_sub": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Deny subscribe permissions. Use `\"subject queue\"` to target a queue group.",
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(subscribePermission()),
+			},
+		},
+		"allow_pub_response": schema.Int64Attribute{
+			Optional:            true,
+			MarkdownDescription: "Allow publishing to reply subjects of received requests, up to this many responses per request (-1 for unlimited, 0 to disallow)",
+			Validators: []validator.Int64{
+				int64validator.Any(
+					int64validator.OneOf(0),
+					responseMaxMsgs(),
+				),
+			},
+		},
+		"response_ttl": schema.StringAttribute{
+			CustomType:          timetypes.GoDurationType{},
+			Optional:            true,
+			MarkdownDescription: "Time limit for response permissions",
+			Validators: []validator.String{
+				nonNegativeDuration(),
+			},
+		},
+		"bearer": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "No connect challenge required for the user. The JWTs of bearer users are left out of `jwts`.",
+		},
+		"tag": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Tags for the user",
+		},
+		"source_network": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Source network for connection",
+		},
+		"max_subscriptions": schema.StringAttribute{
+			CustomType:          LimitType{},
+			Optional:            true,
+			MarkdownDescription: "Maximum number of subscriptions (-1 or `unlimited` for unlimited)",
+			Validators: []validator.String{
+				limitAtLeast(-1),
+			},
+		},
+		"max_data": schema.StringAttribute{
+			CustomType:          ByteSizeType{},
+			Optional:            true,
+			MarkdownDescription: "Maximum number of bytes, e.g. `100MiB` (-1 or `unlimited` for unlimited)",
+			Validators: []validator.String{
+				limitAtLeast(-1),
+			},
+		},
+		"max_payload": schema.StringAttribute{
+			CustomType:          ByteSizeType{},
+			Optional:            true,
+			MarkdownDescription: "Maximum message payload, e.g. `1MiB` (-1 or `unlimited` for unlimited)",
+			Validators: []validator.String{
+				limitAtLeast(-1),
+			},
+		},
+		"allowed_connection_types": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Allowed connection types (STANDARD, WEBSOCKET, LEAFNODE, LEAFNODE_WS, MQTT, MQTT_WS, IN_PROCESS)",
+		},
+		"role": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "Role of the user, e.g. `data.nsc_role.publisher.role`. Permissions, limits and allowed connection types not set otherwise are taken from the role.",
+			Validators: []validator.String{
+				userRoleValidator{},
+			},
+		},
+		"expires_in": schema.StringAttribute{
+			CustomType:          timetypes.GoDurationType{},
+			Optional:            true,
+			MarkdownDescription: "Relative expiry duration from the time the JWT is issued (e.g., '720h' for 30 days, '0s' for no expiry). The JWT of a user is only reissued, with a new expiry, when its claims change.",
+			Validators: []validator.String{
+				nonNegativeDuration(),
+			},
+		},
+	}
+}
+
+func (r *UsersResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	computedMap := func(description string, sensitive bool) schema.MapAttribute {
+		return schema.MapAttribute{
+			ElementType:         types.StringType,
+			Computed:            true,
+			Sensitive:           sensitive,
+			MarkdownDescription: description,
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Creates many users of one account in a single resource, instead of an `nsc_nkey`, `nsc_user` and creds per user. " +
+			"Each entry of `users` is a user named by its key, with the claims of `template` overridden attribute by attribute by those set on the entry. " +
+			"User keys are generated on create and kept in state, like with `nsc_nkey`, with the seeds encrypted when the provider has a `state_encryption_key`. " +
+			"Only the JWTs of users whose entry changes are reissued; changes to the template or the issuer reissue all of them.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Public key of the account the users belong to (same as issuer_account)",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"issuer_seed": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				MarkdownDescription: "Account seed for signing the user JWTs (issuer). Never stored in state. Conflicts with `issuer_seed_env`; one of the two must be set.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("issuer_seed_env")),
+					stringvalidator.AtLeastOneOf(path.MatchRoot("issuer_seed_env")),
+				},
+			},
+			"issuer_seed_env": issuerSeedEnvAttribute(
+				"Name of the environment variable holding the account (or account signing) seed, e.g. `NATS_ACCOUNT_SEED`. Only the name is stored in state. Alternative to `issuer_seed`.",
+			),
+			"issuer_account": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Account public key when the issuer seed is an account signing key. Defaults to the public key of the issuer seed.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^A[A-Z2-7]{55}$`),
+						"must be a valid account public key starting with 'A'",
+					),
+				},
+			},
+			"users": schema.MapNestedAttribute{
+				Required:            true,
+				MarkdownDescription: "Users by name. Each entry sets the claims in which the user differs from `template`; an empty entry, `{}`, takes the template as is.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: usersEntryAttributes(),
+				},
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+				},
+			},
+
+			"public_keys": computedMap("User public keys by name", false),
+			"seeds":       computedMap("User seeds by name", true),
+			"jwts":        computedMap("User JWTs by name. Bearer users are left out, use `creds` for them.", false),
+			"creds":       computedMap("User credentials file contents by name", true),
+		},
+		Blocks: map[string]schema.Block{
+			"template": schema.SingleNestedBlock{
+				MarkdownDescription: "Claims of all users, unless overridden by their entry in `users`.",
+				Attributes:          usersEntryAttributes(),
+			},
+		},
+	}
+}
+
+func (r *UsersResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	providerData, ok := req.ProviderData.(*NSCProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *NSCProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	r.keys = providerData.Keys
+	r.cipher = providerData.StateCipher
+	r.audit = providerData.Audit
+	r.requireExpiry = providerData.RequireExpiry
+	r.policy = providerData.Policy
+}
+
+// ModifyPlan checks the users against require_expiry and the policy of the
+// provider, and plans the outputs: keys of existing users are kept, and the
+// JWTs and creds of users that are reissued are marked unknown. Create and
+// Update reissue the users with unknown creds.
+func (r *UsersResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan UsersResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.Users.IsUnknown() {
+		return
+	}
+	users, diags := plan.entries(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	names := sortedKeys(users)
+
+	for _, name := range names {
+		resp.Diagnostics.Append(r.checkPlan(ctx, name, users[name])...)
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), plan.IssuerAccount)...)
+
+	if req.State.Raw.IsNull() || !jwtPlanned(req) {
+		return
+	}
+
+	var state UsersResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Changes to the template or the issuer reissue every user
+	reissueAll := plan.IssuerSeedEnv != state.IssuerSeedEnv ||
+		!plan.IssuerAccount.Equal(state.IssuerAccount) ||
+		rawAttributeChanged(req, tftypes.NewAttributePath().WithAttributeName("template"))
+
+	publicKeys := make(map[string]attr.Value, len(names))
+	seeds := make(map[string]attr.Value, len(names))
+	jwts := make(map[string]attr.Value, len(names))
+	creds := make(map[string]attr.Value, len(names))
+	jwtsUnknown := false
+	for _, name := range names {
+		publicKey, known := mapStringElement(state.PublicKeys, name)
+		if !known {
+			publicKeys[name], seeds[name] = types.StringUnknown(), types.StringUnknown()
+		} else {
+			publicKeys[name] = publicKey
+			seeds[name], _ = mapStringElement(state.Seeds, name)
+		}
+
+		reissue := !known || reissueAll ||
+			rawAttributeChanged(req, tftypes.NewAttributePath().WithAttributeName("users").WithElementKeyString(name))
+		if !reissue {
+			creds[name], _ = mapStringElement(state.Creds, name)
+			if token, ok := mapStringElement(state.JWTs, name); ok {
+				jwts[name] = token
+			}
+			continue
+		}
+
+		creds[name] = types.StringUnknown()
+		bearer := users[name].Bearer
+		if bearer.IsUnknown() {
+			jwtsUnknown = true
+		} else if !bearer.ValueBool() {
+			jwts[name] = types.StringUnknown()
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("public_keys"), types.MapValueMust(types.StringType, publicKeys))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("seeds"), types.MapValueMust(types.StringType, seeds))...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("creds"), types.MapValueMust(types.StringType, creds))...)
+	if jwtsUnknown {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwts"), types.MapUnknown(types.StringType))...)
+	} else {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("jwts"), types.MapValueMust(types.StringType, jwts))...)
+	}
+}
+
+// checkPlan checks the claims of a planned user against require_expiry and
+// the policy of the provider.
+func (r *UsersResource) checkPlan(ctx context.Context, name string, user UserClaimsModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if slices.Contains(r.requireExpiry, "user") && !user.ExpiresIn.IsUnknown() {
+		expiresIn, d := user.ExpiresIn.ValueGoDuration()
+		if user.ExpiresIn.IsNull() || (!d.HasError() && expiresIn == 0) {
+			diags.AddAttributeError(
+				path.Root("users").AtMapKey(name),
+				"Missing expiry",
+				fmt.Sprintf("The provider requires user JWTs to expire (require_expiry). Set expires_in for user %q or in the template.", name),
+			)
+		}
+	}
+
+	if len(r.policy) == 0 {
+		return diags
+	}
+	user.Name = types.StringValue(name)
+	user.Subject = types.StringValue(policyPlanSubject)
+	claims, d := buildUserClaims(ctx, &user)
+	if d.HasError() {
+		return diags
+	}
+	diags.Append(r.policy.checkPlan(claims, types.StringNull(), types.StringNull(), policyUnknown{
+		tags:   user.Tag.IsUnknown(),
+		expiry: user.ExpiresIn.IsUnknown(),
+	})...)
+	return diags
+}
+
+func (r *UsersResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data, config UsersResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.issue(ctx, &data, UsersResourceModel{}, config.IssuerSeed, "create")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "created users resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UsersResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data UsersResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// For state-only storage, nothing to read externally
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UsersResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data, state, config UsersResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.issue(ctx, &data, state, config.IssuerSeed, "update")...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Trace(ctx, "updated users resource")
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *UsersResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data UsersResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing to clean up - all data is in state
+	tflog.Trace(ctx, "deleted users resource")
+}
+
+// issue generates the keys of new users and signs the JWTs of the users with
+// unknown creds in the plan, keeping the outputs of the others. Keys unknown
+// in the plan are taken from the prior state when it has them. The issuer
+// seed is write-only and so comes from the configuration.
+func (r *UsersResource) issue(ctx context.Context, data *UsersResourceModel, prior UsersResourceModel, configSeed types.String, action string) diag.Diagnostics {
+	issuerSeed, diags := issuerSeedValue(configSeed, data.IssuerSeedEnv)
+	if diags.HasError() {
+		return diags
+	}
+	issuerKP, err := r.keys.fromSeed(issuerSeed.ValueString())
+	if err != nil {
+		diags.AddError("Invalid issuer seed", err.Error())
+		return diags
+	}
+	issuerPubKey, err := issuerKP.PublicKey()
+	if err != nil {
+		diags.AddError("Failed to get public key from issuer", err.Error())
+		return diags
+	}
+	if !nkeys.IsValidPublicAccountKey(issuerPubKey) {
+		diags.AddError("Invalid issuer seed", "The issuer seed must be an account or account signing key seed")
+		return diags
+	}
+	if data.IssuerAccount.IsNull() || data.IssuerAccount.IsUnknown() {
+		data.IssuerAccount = types.StringValue(issuerPubKey)
+	}
+
+	users, d := data.entries(ctx)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	publicKeys := make(map[string]attr.Value, len(users))
+	seeds := make(map[string]attr.Value, len(users))
+	jwts := make(map[string]attr.Value, len(users))
+	creds := make(map[string]attr.Value, len(users))
+	for _, name := range sortedKeys(users) {
+		publicKey, planned := mapStringElement(data.PublicKeys, name)
+		seed, _ := mapStringElement(data.Seeds, name)
+		if !planned {
+			var known bool
+			if publicKey, known = mapStringElement(prior.PublicKeys, name); known {
+				seed, _ = mapStringElement(prior.Seeds, name)
+			} else if publicKey, seed, err = r.generateKey(); err != nil {
+				diags.AddAttributeError(path.Root("users").AtMapKey(name), "Failed to generate key pair", err.Error())
+				return diags
+			}
+		}
+		publicKeys[name], seeds[name] = publicKey, seed
+
+		if cred, ok := mapStringElement(data.Creds, name); ok && planned {
+			creds[name] = cred
+			if token, ok := mapStringElement(data.JWTs, name); ok {
+				jwts[name] = token
+			}
+			continue
+		}
+
+		user := users[name]
+		user.Name = types.StringValue(name)
+		user.Subject = publicKey
+		if data.IssuerAccount.ValueString() != issuerPubKey {
+			user.IssuerAccount = data.IssuerAccount
+		}
+		claims, d := buildUserClaims(ctx, &user)
+		diags.Append(d...)
+		if diags.HasError() {
+			return diags
+		}
+		userJWT, err := encodeClaims(ctx, claims, issuerKP, nil, types.StringNull(), types.StringNull(), 0)
+		if err != nil {
+			diags.AddAttributeError(path.Root("users").AtMapKey(name), "Failed to encode user JWT", err.Error())
+			return diags
+		}
+		diags.Append(r.policy.checkToken(userJWT, true)...)
+		if diags.HasError() {
+			return diags
+		}
+		userSeed, err := r.keys.seed(seed.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("users").AtMapKey(name), "Invalid user seed", err.Error())
+			return diags
+		}
+
+		creds[name] = types.StringValue(formatCreds(userJWT, userSeed))
+		if !claims.BearerToken {
+			jwts[name] = types.StringValue(userJWT)
+		}
+		diags.Append(r.audit.record("nsc_users", action, userJWT)...)
+	}
+
+	data.ID = data.IssuerAccount
+	data.PublicKeys = types.MapValueMust(types.StringType, publicKeys)
+	data.Seeds = types.MapValueMust(types.StringType, seeds)
+	data.JWTs = types.MapValueMust(types.StringType, jwts)
+	data.Creds = types.MapValueMust(types.StringType, creds)
+	return diags
+}
+
+// generateKey creates a user key pair and returns its public key and its
+// seed, encrypted for state when a state encryption key is configured.
+func (r *UsersResource) generateKey() (types.String, types.String, error) {
+	kp, err := nkeys.CreateUser()
+	if err != nil {
+		return types.String{}, types.String{}, err
+	}
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return types.String{}, types.String{}, err
+	}
+	seed, err := kp.Seed()
+	if err != nil {
+		return types.String{}, types.String{}, err
+	}
+	stateSeed, err := r.cipher.encrypt(string(seed))
+	if err != nil {
+		return types.String{}, types.String{}, err
+	}
+	return types.StringValue(publicKey), types.StringValue(stateSeed), nil
+}
+
+// entries returns the user claims of the entries of users by name, with the
+// attributes not set on an entry taken from the template.
+func (m UsersResourceModel) entries(ctx context.Context) (map[string]UserClaimsModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var entries map[string]UsersEntryModel
+	diags.Append(m.Users.ElementsAs(ctx, &entries, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	var template UsersEntryModel
+	if m.Template != nil {
+		template = *m.Template
+	}
+
+	users := make(map[string]UserClaimsModel, len(entries))
+	for name, entry := range entries {
+		users[name] = entry.merge(template).userClaims()
+	}
+	return users, diags
+}
+
+// merge returns the entry with the attributes it does not set taken from
+// the template.
+func (m UsersEntryModel) merge(template UsersEntryModel) UsersEntryModel {
+	pick := func(entry, template attr.Value) bool {
+		return entry.IsNull() && !template.IsNull()
+	}
+
+	if pick(m.AllowPub, template.AllowPub) {
+		m.AllowPub = template.AllowPub
+	}
+	if pick(m.AllowSub, template.AllowSub) {
+		m.AllowSub = template.AllowSub
+	}
+	if pick(m.DenyPub, template.DenyPub) {
+		m.DenyPub = template.DenyPub
+	}
+	if pick(m.DenySub, template.DenySub) {
+		m.DenySub = template.DenySub
+	}
+	if pick(m.AllowPubResponse, template.AllowPubResponse) {
+		m.AllowPubResponse = template.AllowPubResponse
+	}
+	if pick(m.ResponseTTL, template.ResponseTTL) {
+		m.ResponseTTL = template.ResponseTTL
+	}
+	if pick(m.Bearer, template.Bearer) {
+		m.Bearer = template.Bearer
+	}
+	if pick(m.Tag, template.Tag) {
+		m.Tag = template.Tag
+	}
+	if pick(m.SourceNetwork, template.SourceNetwork) {
+		m.SourceNetwork = template.SourceNetwork
+	}
+	if pick(m.MaxSubscriptions, template.MaxSubscriptions) {
+		m.MaxSubscriptions = template.MaxSubscriptions
+	}
+	if pick(m.MaxData, template.MaxData) {
+		m.MaxData = template.MaxData
+	}
+	if pick(m.MaxPayload, template.MaxPayload) {
+		m.MaxPayload = template.MaxPayload
+	}
+	if pick(m.AllowedConnectionTypes, template.AllowedConnectionTypes) {
+		m.AllowedConnectionTypes = template.AllowedConnectionTypes
+	}
+	if pick(m.Role, template.Role) {
+		m.Role = template.Role
+	}
+	if pick(m.ExpiresIn, template.ExpiresIn) {
+		m.ExpiresIn = template.ExpiresIn
+	}
+	return m
+}
+
+// userClaims returns the entry as the claims model of nsc_user, so the JWTs
+// are built the same way.
+func (m UsersEntryModel) userClaims() UserClaimsModel {
+	return UserClaimsModel{
+		AllowPub:               m.AllowPub,
+		AllowSub:               m.AllowSub,
+		DenyPub:                m.DenyPub,
+		DenySub:                m.DenySub,
+		AllowPubResponse:       m.AllowPubResponse,
+		ResponseTTL:            m.ResponseTTL,
+		Bearer:                 m.Bearer,
+		Tag:                    m.Tag,
+		SourceNetwork:          m.SourceNetwork,
+		MaxSubscriptions:       m.MaxSubscriptions,
+		MaxData:                m.MaxData,
+		MaxPayload:             m.MaxPayload,
+		AllowedConnectionTypes: m.AllowedConnectionTypes,
+		Role:                   m.Role,
+		ValidityModel: ValidityModel{
+			ExpiresIn: m.ExpiresIn,
+		},
+	}
+}
+
+// mapStringElement returns the known string element of a map, and whether
+// the map has it.
+func mapStringElement(m types.Map, key string) (types.String, bool) {
+	if m.IsNull() || m.IsUnknown() {
+		return types.String{}, false
+	}
+	value, ok := m.Elements()[key].(types.String)
+	if !ok || value.IsNull() || value.IsUnknown() {
+		return types.String{}, false
+	}
+	return value, true
+}
+
+// rawAttributeChanged reports whether the planned value at the path differs
+// from the prior state.
+func rawAttributeChanged(req resource.ModifyPlanRequest, p *tftypes.AttributePath) bool {
+	plan, _, err := tftypes.WalkAttributePath(req.Plan.Raw, p)
+	if err != nil {
+		return true
+	}
+	state, _, err := tftypes.WalkAttributePath(req.State.Raw, p)
+	if err != nil {
+		return true
+	}
+	return !plan.(tftypes.Value).Equal(state.(tftypes.Value))
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestUsersResourceModel_entries(t *testing.T) {
+	ctx := context.Background()
+
+	var schemaResp fwresource.SchemaResponse
+	(&UsersResource{}).Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	entryType := schemaResp.Schema.Attributes["users"].GetType().(types.MapType).ElemType
+
+	data := UsersResourceModel{
+		Template: &UsersEntryModel{
+			AllowPub:  types.ListValueMust(types.StringType, []attr.Value{types.StringValue("app.>")}),
+			Bearer:    types.BoolValue(true),
+			ExpiresIn: timetypes.NewGoDurationValueFromStringMust("720h"),
+		},
+	}
+	users, diags := types.MapValueFrom(ctx, entryType, map[string]UsersEntryModel{
+		"alice": usersEntryNull(),
+		"bob": func() UsersEntryModel {
+			bob := usersEntryNull()
+			bob.AllowPub = types.ListValueMust(types.StringType, []attr.Value{types.StringValue("bob.>")})
+			bob.Bearer = types.BoolValue(false)
+			return bob
+		}(),
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	data.Users = users
+
+	entries, diags := data.entries(ctx)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 users, got %d", len(entries))
+	}
+
+	// Subjects are set on issue
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	alice, bob := entries["alice"], entries["bob"]
+	alice.Subject, bob.Subject = types.StringValue(userPubKey), types.StringValue(userPubKey)
+	if !alice.AllowPub.Equal(data.Template.AllowPub) || !alice.Bearer.ValueBool() || !alice.ExpiresIn.Equal(data.Template.ExpiresIn) {
+		t.Errorf("expected alice to take the template, got %+v", alice)
+	}
+	aliceClaims, diags := buildUserClaims(ctx, &alice)
+	if diags.HasError() || !aliceClaims.BearerToken || aliceClaims.Expires == 0 {
+		t.Errorf("unexpected claims of alice: %+v, %v", aliceClaims, diags)
+	}
+
+	bobClaims, diags := buildUserClaims(ctx, &bob)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if bobClaims.BearerToken || len(bobClaims.Pub.Allow) != 1 || bobClaims.Pub.Allow[0] != "bob.>" || bobClaims.Expires == 0 {
+		t.Errorf("expected bob to override permissions and bearer only, got %+v", bobClaims)
+	}
+}
+
+func TestAccUsersResource(t *testing.T) {
+	var aliceCreds string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUsersResourceConfig(`["bob.>"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("nsc_users.test", "id", "nsc_nkey.account", "public_key"),
+					resource.TestCheckResourceAttr("nsc_users.test", "public_keys.%", "2"),
+					resource.TestCheckResourceAttr("nsc_users.test", "creds.%", "2"),
+					testAccCheckUserCredsFormat("nsc_users.test", "creds.alice"),
+					testAccCheckUsersClaims("nsc_users.test", "bob", func(claims *jwt.UserClaims) error {
+						if claims.Name != "bob" || !claims.Pub.Allow.Contains("bob.>") || claims.Limits.Subs != 10 {
+							return fmt.Errorf("unexpected claims of bob: %+v", claims)
+						}
+						return nil
+					}),
+					func(s *terraform.State) error {
+						aliceCreds = s.RootModule().Resources["nsc_users.test"].Primary.Attributes["creds.alice"]
+						return nil
+					},
+				),
+			},
+			{
+				// Changing the entry of bob leaves the JWT of alice as is
+				Config: testAccUsersResourceConfig(`["bob.events.>"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckUsersClaims("nsc_users.test", "bob", func(claims *jwt.UserClaims) error {
+						if !claims.Pub.Allow.Contains("bob.events.>") {
+							return fmt.Errorf("expected bob to be reissued, got %v", claims.Pub.Allow)
+						}
+						return nil
+					}),
+					func(s *terraform.State) error {
+						if creds := s.RootModule().Resources["nsc_users.test"].Primary.Attributes["creds.alice"]; creds != aliceCreds {
+							return fmt.Errorf("expected the creds of alice to be kept")
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+// usersEntryNull returns an entry of users setting no attributes.
+func usersEntryNull() UsersEntryModel {
+	return UsersEntryModel{
+		AllowPub:               types.ListNull(types.StringType),
+		AllowSub:               types.ListNull(types.StringType),
+		DenyPub:                types.ListNull(types.StringType),
+		DenySub:                types.ListNull(types.StringType),
+		AllowPubResponse:       types.Int64Null(),
+		ResponseTTL:            timetypes.NewGoDurationNull(),
+		Bearer:                 types.BoolNull(),
+		Tag:                    types.ListNull(types.StringType),
+		SourceNetwork:          types.ListNull(types.StringType),
+		MaxSubscriptions:       NewLimitNull(),
+		MaxData:                NewByteSizeNull(),
+		MaxPayload:             NewByteSizeNull(),
+		AllowedConnectionTypes: types.ListNull(types.StringType),
+		Role:                   types.StringNull(),
+		ExpiresIn:              timetypes.NewGoDurationNull(),
+	}
+}
+
+func testAccCheckUsersClaims(resourceName, user string, check func(*jwt.UserClaims) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("Resource not found: %s", resourceName)
+		}
+
+		claims, err := jwt.DecodeUserClaims(rs.Primary.Attributes["jwts."+user])
+		if err != nil {
+			return fmt.Errorf("failed to decode user JWT of %s: %w", user, err)
+		}
+		return check(claims)
+	}
+}
+
+func testAccUsersResourceConfig(bobAllowPub string) string {
+	return fmt.Sprintf(`
+resource "nsc_nkey" "account" {
+  type = "account"
+}
+
+resource "nsc_users" "test" {
+  issuer_seed = nsc_nkey.account.seed
+
+  template {
+    allow_pub         = ["app.>"]
+    allow_sub         = ["_INBOX.>"]
+    max_subscriptions = 10
+  }
+
+  users = {
+    alice = {}
+    bob = {
+      allow_pub = %s
+    }
+  }
+}
+`, bobAllowPub)
+}
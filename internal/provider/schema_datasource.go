@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+)
+
+// claimsDataSourceSchema derives data source attributes and blocks from a
+// resource schema, keeping only those mapped by the given model struct. This
+// keeps the claims preview data sources in sync with the resources they mirror.
+func claimsDataSourceSchema(s rschema.Schema, model any) (map[string]dschema.Attribute, map[string]dschema.Block) {
+	attributes := map[string]dschema.Attribute{}
+	blocks := map[string]dschema.Block{}
+
+	for _, name := range modelAttributeNames(reflect.TypeOf(model)) {
+		if a, ok := s.Attributes[name]; ok {
+			attributes[name] = dataSourceAttribute(a)
+			continue
+		}
+		if b, ok := s.Blocks[name]; ok {
+			blocks[name] = dataSourceBlock(b)
+			continue
+		}
+		panic(fmt.Sprintf("model attribute %q not found in resource schema", name))
+	}
+
+	return attributes, blocks
+}
+
+// modelAttributeNames returns the tfsdk names of a model struct, including
+// those of embedded structs.
+func modelAttributeNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			names = append(names, modelAttributeNames(field.Type)...)
+			continue
+		}
+		if tag := field.Tag.Get("tfsdk"); tag != "" && tag != "-" {
+			names = append(names, strings.Split(tag, ",")[0])
+		}
+	}
+	return names
+}
+
+// dataSourceAttribute converts a resource attribute into its data source
+// equivalent. Defaults and plan modifiers have no data source counterpart.
+func dataSourceAttribute(a rschema.Attribute) dschema.Attribute {
+	switch a := a.(type) {
+	case rschema.StringAttribute:
+		return dschema.StringAttribute{
+			CustomType:          a.CustomType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			Description:         a.Description,
+			MarkdownDescription: a.MarkdownDescription,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case rschema.Int64Attribute:
+		return dschema.Int64Attribute{
+			CustomType:          a.CustomType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			Description:         a.Description,
+			MarkdownDescription: a.MarkdownDescription,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case rschema.BoolAttribute:
+		return dschema.BoolAttribute{
+			CustomType:          a.CustomType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			Description:         a.Description,
+			MarkdownDescription: a.MarkdownDescription,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case rschema.ListAttribute:
+		return dschema.ListAttribute{
+			ElementType:         a.ElementType,
+			CustomType:          a.CustomType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			Description:         a.Description,
+			MarkdownDescription: a.MarkdownDescription,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case rschema.MapAttribute:
+		return dschema.MapAttribute{
+			ElementType:         a.ElementType,
+			CustomType:          a.CustomType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			Description:         a.Description,
+			MarkdownDescription: a.MarkdownDescription,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	case rschema.ListNestedAttribute:
+		attributes, _ := dataSourceNestedSchema(a.NestedObject.Attributes, nil)
+		return dschema.ListNestedAttribute{
+			NestedObject: dschema.NestedAttributeObject{
+				Attributes: attributes,
+				CustomType: a.NestedObject.CustomType,
+				Validators: a.NestedObject.Validators,
+			},
+			CustomType:          a.CustomType,
+			Required:            a.Required,
+			Optional:            a.Optional,
+			Computed:            a.Computed,
+			Sensitive:           a.Sensitive,
+			Description:         a.Description,
+			MarkdownDescription: a.MarkdownDescription,
+			DeprecationMessage:  a.DeprecationMessage,
+			Validators:          a.Validators,
+		}
+	default:
+		panic(fmt.Sprintf("unsupported resource attribute type %T", a))
+	}
+}
+
+// dataSourceBlock converts a resource block into its data source equivalent.
+func dataSourceBlock(b rschema.Block) dschema.Block {
+	switch b := b.(type) {
+	case rschema.ListNestedBlock:
+		return dschema.ListNestedBlock{
+			NestedObject:        dataSourceNestedBlockObject(b.NestedObject),
+			CustomType:          b.CustomType,
+			Description:         b.Description,
+			MarkdownDescription: b.MarkdownDescription,
+			DeprecationMessage:  b.DeprecationMessage,
+			Validators:          b.Validators,
+		}
+	case rschema.SingleNestedBlock:
+		attributes, blocks := dataSourceNestedSchema(b.Attributes, b.Blocks)
+		return dschema.SingleNestedBlock{
+			Attributes:          attributes,
+			Blocks:              blocks,
+			CustomType:          b.CustomType,
+			Description:         b.Description,
+			MarkdownDescription: b.MarkdownDescription,
+			DeprecationMessage:  b.DeprecationMessage,
+			Validators:          b.Validators,
+		}
+	default:
+		panic(fmt.Sprintf("unsupported resource block type %T", b))
+	}
+}
+
+func dataSourceNestedBlockObject(o rschema.NestedBlockObject) dschema.NestedBlockObject {
+	attributes, blocks := dataSourceNestedSchema(o.Attributes, o.Blocks)
+	return dschema.NestedBlockObject{
+		Attributes: attributes,
+		Blocks:     blocks,
+		CustomType: o.CustomType,
+		Validators: o.Validators,
+	}
+}
+
+func dataSourceNestedSchema(a map[string]rschema.Attribute, b map[string]rschema.Block) (map[string]dschema.Attribute, map[string]dschema.Block) {
+	attributes := make(map[string]dschema.Attribute, len(a))
+	for name, attr := range a {
+		attributes[name] = dataSourceAttribute(attr)
+	}
+
+	var blocks map[string]dschema.Block
+	if len(b) > 0 {
+		blocks = make(map[string]dschema.Block, len(b))
+		for name, block := range b {
+			blocks[name] = dataSourceBlock(block)
+		}
+	}
+
+	return attributes, blocks
+}
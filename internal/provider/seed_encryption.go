@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// encryptSeed encrypts a seed with age and returns the ASCII armored
+// ciphertext. The seed is encrypted either to a set of X25519 recipients
+// ("age1...") or with a passphrase; age does not allow mixing the two.
+func encryptSeed(seed string, recipients []string, passphrase string) (string, error) {
+	var ageRecipients []age.Recipient
+
+	switch {
+	case len(recipients) > 0 && passphrase != "":
+		return "", fmt.Errorf("seed can be encrypted either to recipients or with a passphrase, not both")
+	case passphrase != "":
+		r, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return "", fmt.Errorf("invalid passphrase: %w", err)
+		}
+		ageRecipients = append(ageRecipients, r)
+	case len(recipients) > 0:
+		for _, recipient := range recipients {
+			r, err := age.ParseX25519Recipient(recipient)
+			if err != nil {
+				return "", fmt.Errorf("invalid recipient %q: %w", recipient, err)
+			}
+			ageRecipients = append(ageRecipients, r)
+		}
+	default:
+		return "", fmt.Errorf("no recipients or passphrase provided")
+	}
+
+	var buf bytes.Buffer
+	armored := armor.NewWriter(&buf)
+	w, err := age.Encrypt(armored, ageRecipients...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write([]byte(seed)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	if err := armored.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// seedDecryptsWithPassphrase reports whether an encrypted seed produced by
+// encryptSeed decrypts with the passphrase. This costs one scrypt key
+// derivation.
+func seedDecryptsWithPassphrase(encrypted, passphrase string) bool {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return false
+	}
+	_, err = age.Decrypt(armor.NewReader(strings.NewReader(encrypted)), identity)
+	return err == nil
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+func TestEncryptSeed(t *testing.T) {
+	const seed = "SUAIBDPBAUTWCWBKIO6XHQNINK5FWJW4OHLXC3HQ2KFE4PEJUA44CNHTC4"
+
+	recipient, err := age.ParseX25519Identity(testAccAgeIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase, err := age.NewScryptIdentity("passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		recipients []string
+		passphrase string
+		identity   age.Identity
+		wantErr    string
+	}{
+		{name: "recipient", recipients: []string{testAccAgeRecipient}, identity: recipient},
+		{name: "passphrase", passphrase: "passphrase", identity: passphrase},
+		{name: "both", recipients: []string{testAccAgeRecipient}, passphrase: "passphrase", wantErr: "not both"},
+		{name: "invalid recipient", recipients: []string{"age1invalid"}, wantErr: "invalid recipient"},
+		{name: "none", wantErr: "no recipients"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted, err := encryptSeed(seed, tt.recipients, tt.passphrase)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(encrypted, armor.Header) {
+				t.Fatalf("expected armored output, got %q", encrypted)
+			}
+
+			r, err := age.Decrypt(armor.NewReader(strings.NewReader(encrypted)), tt.identity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decrypted, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(decrypted) != seed {
+				t.Fatalf("expected %q, got %q", seed, decrypted)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// externalSigner signs JWTs with keys held outside of Terraform, so that
+// issuer seeds never have to appear in configuration or state.
+type externalSigner interface {
+	// PublicKey returns the ed25519 public key of the named key.
+	PublicKey(ctx context.Context, keyName string) (ed25519.PublicKey, error)
+	// Sign returns the ed25519 signature of data made with the named key.
+	Sign(ctx context.Context, keyName string, data []byte) ([]byte, error)
+}
+
+// externalIssuer returns a public-only keypair and a signing function for a
+// key held by the external signer. The prefix selects the NKey type of the
+// issuer (operator for account JWTs, account for user JWTs).
+//
+// The key is referenced by keyName, by publicKey or by both. Without
+// publicKey the public key is looked up from the signer; without keyName the
+// public key itself is the key reference passed to the signer.
+func externalIssuer(ctx context.Context, signer externalSigner, keyName, publicKey string, prefix nkeys.PrefixByte) (nkeys.KeyPair, jwt.SignFn, error) {
+	if signer == nil {
+		return nil, nil, fmt.Errorf("external signing requires a signer to be configured on the provider")
+	}
+
+	if publicKey == "" {
+		raw, err := signer.PublicKey(ctx, keyName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get public key of %q: %w", keyName, err)
+		}
+
+		encoded, err := nkeys.Encode(prefix, raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode public key of %q: %w", keyName, err)
+		}
+		publicKey = string(encoded)
+	}
+	if keyName == "" {
+		keyName = publicKey
+	}
+
+	if nkeys.Prefix(publicKey) != prefix {
+		return nil, nil, fmt.Errorf("public key %s of %q is not of the expected type", publicKey, keyName)
+	}
+
+	kp, err := nkeys.FromPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signFn := func(_ string, data []byte) ([]byte, error) {
+		sig, err := signer.Sign(ctx, keyName, data)
+		if err != nil {
+			return nil, err
+		}
+		// Catch signers returning signatures of a different key than the
+		// issuer, which would otherwise yield JWTs that fail verification.
+		if err := kp.Verify(data, sig); err != nil {
+			return nil, fmt.Errorf("signature of %q does not match public key %s", keyName, publicKey)
+		}
+		return sig, nil
+	}
+
+	return kp, signFn, nil
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/nats-io/nkeys"
+)
+
+var _ externalSigner = &execSigner{}
+
+// execSigner delegates signing to an external command, which makes it possible
+// to keep issuer keys in an HSM, a cloud KMS or any other key store that can
+// produce ed25519 signatures.
+//
+// The command is run once per operation. The operation and the key reference
+// are passed in the environment:
+//
+//   - NSC_SIGNER_OPERATION: "public_key" or "sign"
+//   - NSC_SIGNER_KEY: issuer_key_name, or issuer_public_key when no key name is set
+//
+// For "public_key" the command prints the NKey public key (e.g. "O...") of the
+// referenced key. For "sign" the command reads the data to sign from stdin and
+// prints the base64 encoded ed25519 signature.
+type execSigner struct {
+	command string
+	args    []string
+	env     []string
+}
+
+func newExecSigner(command string, args []string, env map[string]string) *execSigner {
+	s := &execSigner{
+		command: command,
+		args:    args,
+	}
+	for k, v := range env {
+		s.env = append(s.env, k+"="+v)
+	}
+	return s
+}
+
+func (s *execSigner) PublicKey(ctx context.Context, keyName string) (ed25519.PublicKey, error) {
+	out, err := s.run(ctx, "public_key", keyName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := strings.TrimSpace(string(out))
+	if _, err := nkeys.FromPublicKey(publicKey); err != nil {
+		return nil, fmt.Errorf("signer command returned an invalid public key for %q: %w", keyName, err)
+	}
+
+	raw, err := nkeys.Decode(nkeys.Prefix(publicKey), []byte(publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("signer command returned an invalid public key for %q: %w", keyName, err)
+	}
+
+	return raw, nil
+}
+
+func (s *execSigner) Sign(ctx context.Context, keyName string, data []byte) ([]byte, error) {
+	out, err := s.run(ctx, "sign", keyName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("signer command returned an invalid signature for %q: %w", keyName, err)
+	}
+
+	return sig, nil
+}
+
+// run executes the signer command and returns its standard output.
+func (s *execSigner) run(ctx context.Context, operation, keyName string, stdin []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, s.command, s.args...)
+	cmd.Env = append(os.Environ(), s.env...)
+	cmd.Env = append(cmd.Env,
+		"NSC_SIGNER_OPERATION="+operation,
+		"NSC_SIGNER_KEY="+keyName,
+	)
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("signer command failed (%s): %w: %s", operation, err, msg)
+		}
+		return nil, fmt.Errorf("signer command failed (%s): %w", operation, err)
+	}
+
+	return stdout.Bytes(), nil
+}
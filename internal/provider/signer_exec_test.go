@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// TestExecSignerHelperProcess is not a real test. It is run as the signer
+// command by the exec signer tests and signs with the seed from the
+// environment.
+func TestExecSignerHelperProcess(t *testing.T) {
+	if os.Getenv("NSC_TEST_SIGNER_SEED") == "" {
+		t.Skip("helper process")
+	}
+
+	kp, err := nkeys.FromSeed([]byte(os.Getenv("NSC_TEST_SIGNER_SEED")))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch os.Getenv("NSC_SIGNER_OPERATION") {
+	case "public_key":
+		publicKey, _ := kp.PublicKey()
+		fmt.Println(publicKey)
+	case "sign":
+		data, _ := io.ReadAll(os.Stdin)
+		sig, err := kp.Sign(data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println(base64.StdEncoding.EncodeToString(sig))
+	default:
+		fmt.Fprintln(os.Stderr, "unknown operation")
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func testExecSigner(seed []byte) *execSigner {
+	return newExecSigner(os.Args[0], []string{"-test.run=^TestExecSignerHelperProcess$"}, map[string]string{
+		"NSC_TEST_SIGNER_SEED": string(seed),
+	})
+}
+
+func TestExecSigner_signAccountJWT(t *testing.T) {
+	ctx := context.Background()
+
+	operatorKP, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorSeed, _ := operatorKP.Seed()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	accountKP, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountPubKey, _ := accountKP.PublicKey()
+
+	signer := testExecSigner(operatorSeed)
+
+	tests := []struct {
+		name      string
+		keyName   string
+		publicKey string
+	}{
+		{name: "key name", keyName: "operator"},
+		{name: "public key", publicKey: operatorPubKey},
+		{name: "key name and public key", keyName: "operator", publicKey: operatorPubKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuerKP, signFn, err := externalIssuer(ctx, signer, tt.keyName, tt.publicKey, nkeys.PrefixByteOperator)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims := jwt.NewAccountClaims(accountPubKey)
+			token, err := claims.EncodeWithSigner(issuerKP, signFn)
+			if err != nil {
+				t.Fatalf("failed to encode account JWT: %s", err)
+			}
+
+			decoded, err := jwt.DecodeAccountClaims(token)
+			if err != nil {
+				t.Fatalf("failed to decode account JWT: %s", err)
+			}
+			if decoded.Issuer != operatorPubKey {
+				t.Errorf("expected issuer %s, got %s", operatorPubKey, decoded.Issuer)
+			}
+		})
+	}
+}
+
+func TestExecSigner_mismatchedPublicKey(t *testing.T) {
+	ctx := context.Background()
+
+	operatorKP, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorSeed, _ := operatorKP.Seed()
+	otherKP, err := nkeys.CreateOperator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPubKey, _ := otherKP.PublicKey()
+	accountKP, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountPubKey, _ := accountKP.PublicKey()
+
+	issuerKP, signFn, err := externalIssuer(ctx, testExecSigner(operatorSeed), "", otherPubKey, nkeys.PrefixByteOperator)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := jwt.NewAccountClaims(accountPubKey)
+	if _, err := claims.EncodeWithSigner(issuerKP, signFn); err == nil {
+		t.Fatal("expected error for signature of a different key")
+	}
+}
+
+func TestExecSigner_commandFailure(t *testing.T) {
+	signer := newExecSigner(os.Args[0], []string{"-test.run=^TestExecSignerHelperProcess$"}, map[string]string{
+		"NSC_TEST_SIGNER_SEED": "not-a-seed",
+	})
+
+	if _, err := signer.PublicKey(context.Background(), "operator"); err == nil {
+		t.Fatal("expected error for failing signer command")
+	}
+}
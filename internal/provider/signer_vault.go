@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ externalSigner = &vaultSigner{}
+
+// vaultSigner signs with ed25519 keys of a HashiCorp Vault transit secrets
+// engine. Only the Vault HTTP API is used, no Vault client library.
+type vaultSigner struct {
+	address   string
+	token     string
+	namespace string
+	mount     string
+	client    *http.Client
+}
+
+func newVaultSigner(address, token, namespace, mount string) *vaultSigner {
+	return &vaultSigner{
+		address:   strings.TrimSuffix(address, "/"),
+		token:     token,
+		namespace: namespace,
+		mount:     strings.Trim(mount, "/"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *vaultSigner) PublicKey(ctx context.Context, keyName string) (ed25519.PublicKey, error) {
+	var out struct {
+		Data struct {
+			Type          string `json:"type"`
+			LatestVersion int    `json:"latest_version"`
+			Keys          map[string]struct {
+				PublicKey string `json:"public_key"`
+			} `json:"keys"`
+		} `json:"data"`
+	}
+
+	if err := s.do(ctx, http.MethodGet, "keys/"+url.PathEscape(keyName), nil, &out); err != nil {
+		return nil, err
+	}
+	if out.Data.Type != "ed25519" {
+		return nil, fmt.Errorf("transit key %q has type %q, expected ed25519", keyName, out.Data.Type)
+	}
+
+	key, ok := out.Data.Keys[strconv.Itoa(out.Data.LatestVersion)]
+	if !ok {
+		return nil, fmt.Errorf("transit key %q has no version %d", keyName, out.Data.LatestVersion)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode public key of transit key %q: %w", keyName, err)
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("transit key %q has an invalid public key size %d", keyName, len(publicKey))
+	}
+
+	return publicKey, nil
+}
+
+func (s *vaultSigner) Sign(ctx context.Context, keyName string, data []byte) ([]byte, error) {
+	in := map[string]string{
+		"input": base64.StdEncoding.EncodeToString(data),
+	}
+	var out struct {
+		Data struct {
+			Signature string `json:"signature"`
+		} `json:"data"`
+	}
+
+	if err := s.do(ctx, http.MethodPost, "sign/"+url.PathEscape(keyName), in, &out); err != nil {
+		return nil, err
+	}
+
+	// Signatures are returned as "vault:v<version>:<base64>"
+	parts := strings.SplitN(out.Data.Signature, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		return nil, fmt.Errorf("unexpected signature format from transit key %q", keyName)
+	}
+
+	return base64.StdEncoding.DecodeString(parts[2])
+}
+
+// do performs a request against the transit mount and decodes the response.
+func (s *vaultSigner) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/v1/%s/%s", s.address, s.mount, path), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		if len(errResp.Errors) > 0 {
+			return fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(errResp.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	return nil
+}
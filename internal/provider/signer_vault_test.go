@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// testVaultTransit serves the subset of the Vault transit API used by
+// vaultSigner for a single ed25519 key.
+func testVaultTransit(t *testing.T, keyName string, key ed25519.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/transit/keys/"+keyName, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"type":           "ed25519",
+				"latest_version": 1,
+				"keys": map[string]any{
+					"1": map[string]any{
+						"public_key": base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+					},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("POST /v1/transit/sign/"+keyName, func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Input string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		input, err := base64.StdEncoding.DecodeString(in.Input)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"signature": "vault:v1:" + base64.StdEncoding.EncodeToString(ed25519.Sign(key, input)),
+			},
+		})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultSigner_signAccountJWT(t *testing.T) {
+	ctx := context.Background()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := testVaultTransit(t, "operator", privateKey)
+	signer := newVaultSigner(server.URL, "test-token", "", "transit")
+
+	operatorKP, signFn, err := externalIssuer(ctx, signer, "operator", "", nkeys.PrefixByteOperator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operatorPubKey, err := operatorKP.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := nkeys.Encode(nkeys.PrefixByteOperator, publicKey)
+	if operatorPubKey != string(expected) {
+		t.Fatalf("expected operator public key %s, got %s", expected, operatorPubKey)
+	}
+
+	accountKP, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountPubKey, _ := accountKP.PublicKey()
+
+	claims := jwt.NewAccountClaims(accountPubKey)
+	claims.Name = "TestAccount"
+	token, err := claims.EncodeWithSigner(operatorKP, signFn)
+	if err != nil {
+		t.Fatalf("failed to encode account JWT: %s", err)
+	}
+
+	// Decoding verifies the signature against the issuer
+	decoded, err := jwt.DecodeAccountClaims(token)
+	if err != nil {
+		t.Fatalf("failed to decode account JWT: %s", err)
+	}
+	if decoded.Issuer != operatorPubKey {
+		t.Errorf("expected issuer %s, got %s", operatorPubKey, decoded.Issuer)
+	}
+}
+
+func TestVaultSigner_errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := testVaultTransit(t, "operator", privateKey)
+
+	if _, err := newVaultSigner(server.URL, "bad-token", "", "transit").PublicKey(ctx, "operator"); err == nil {
+		t.Error("expected error for invalid token")
+	}
+	if _, err := newVaultSigner(server.URL, "test-token", "", "transit").PublicKey(ctx, "missing"); err == nil {
+		t.Error("expected error for missing key")
+	}
+	if _, _, err := externalIssuer(ctx, nil, "operator", "", nkeys.PrefixByteOperator); err == nil {
+		t.Error("expected error without signer")
+	}
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+// signingKeyPublicKey returns the public key of a signing_keys entry, which
+// is either a public key or a seed of the key type given by prefix. Seeds are
+// only used to derive the public key and never end up in a JWT. Errors do not
+// include the entry, as it may be a seed.
+func signingKeyPublicKey(key string, prefix nkeys.PrefixByte) (string, error) {
+	if isEncryptedSeed(key) {
+		return "", fmt.Errorf("signing keys cannot be encrypted seeds; pass the public key of the signing key instead")
+	}
+	if !strings.HasPrefix(key, "S") {
+		if nkeys.Prefix(key) != prefix {
+			return "", fmt.Errorf("signing keys must be %[1]s public keys or %[1]s seeds, got: %[2]s", prefix, key)
+		}
+		return key, nil
+	}
+
+	kp, err := nkeys.FromSeed([]byte(key))
+	if err != nil {
+		return "", fmt.Errorf("signing key seed is invalid: %w", err)
+	}
+	publicKey, err := kp.PublicKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to derive the public key of a signing key seed: %w", err)
+	}
+	if keyPrefix := nkeys.Prefix(publicKey); keyPrefix != prefix {
+		return "", fmt.Errorf("signing key seed of %s %s is not an %s seed", keyPrefix, publicKey, prefix)
+	}
+	return publicKey, nil
+}
+
+// signingKeyPublicKeys returns the public keys of the signing_keys entries,
+// sorted and without duplicates, so neither the order of the entries nor
+// repeated keys change the JWT.
+func signingKeyPublicKeys(ctx context.Context, list types.List, prefix nkeys.PrefixByte) ([]string, diag.Diagnostics) {
+	keys, diags := stringListValues(ctx, list)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	publicKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		publicKey, err := signingKeyPublicKey(key, prefix)
+		if err != nil {
+			diags.AddAttributeError(path.Root("signing_keys"), "Invalid signing key", err.Error())
+			return nil, diags
+		}
+		publicKeys = append(publicKeys, publicKey)
+	}
+	slices.Sort(publicKeys)
+	return slices.Compact(publicKeys), diags
+}
+
+// sameSigningKeys reports whether two signing_keys values make the same JWT,
+// i.e. differ only in order, duplicates, or seeds given for public keys.
+func sameSigningKeys(ctx context.Context, a, b types.List, prefix nkeys.PrefixByte) bool {
+	if listHasUnknown(a) || listHasUnknown(b) {
+		return false
+	}
+	aKeys, diags := signingKeyPublicKeys(ctx, a, prefix)
+	if diags.HasError() {
+		return false
+	}
+	bKeys, diags := signingKeyPublicKeys(ctx, b, prefix)
+	if diags.HasError() {
+		return false
+	}
+	return slices.Equal(aKeys, bKeys)
+}
+
+// removedSigningKeyWarnings warns about the JWTs issued by a signing key that
+// a plan removes from signing_keys, as nats-server no longer trusts them once
+// the new JWT of the issuer is deployed. Kind names the claims of the issued
+// JWTs (account or user). Unknown values are skipped: JWTs being reissued in
+// the same run are not affected.
+func removedSigningKeyWarnings(ctx context.Context, stateKeys, planKeys, issuedJWTs types.List, prefix nkeys.PrefixByte, kind string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if stateKeys.IsNull() || listHasUnknown(planKeys) || issuedJWTs.IsNull() || issuedJWTs.IsUnknown() {
+		return diags
+	}
+
+	keep := map[string]bool{}
+	planned, d := stringListValues(ctx, planKeys)
+	diags.Append(d...)
+	for _, key := range planned {
+		if publicKey, err := signingKeyPublicKey(key, prefix); err == nil {
+			keep[publicKey] = true
+		}
+	}
+	current, d := stringListValues(ctx, stateKeys)
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	affected := map[string][]string{}
+	var removed []string
+	for _, key := range current {
+		publicKey, err := signingKeyPublicKey(key, prefix)
+		if err != nil || keep[publicKey] {
+			continue
+		}
+		removed = append(removed, publicKey)
+		affected[publicKey] = nil
+	}
+	if len(removed) == 0 {
+		return diags
+	}
+
+	for _, element := range issuedJWTs.Elements() {
+		token, ok := element.(types.String)
+		if !ok || token.IsNull() || token.IsUnknown() {
+			continue
+		}
+		claims, err := jwt.Decode(token.ValueString())
+		if err != nil {
+			continue
+		}
+		data := claims.Claims()
+		if issued, ok := affected[data.Issuer]; ok {
+			affected[data.Issuer] = append(issued, fmt.Sprintf("%s (%s)", data.Name, data.Subject))
+		}
+	}
+
+	for _, publicKey := range removed {
+		if len(affected[publicKey]) == 0 {
+			continue
+		}
+		diags.AddAttributeWarning(
+			path.Root("signing_keys"),
+			"Removed signing key issued "+kind+" JWTs",
+			fmt.Sprintf("Signing key %s is removed, but it issued the JWTs of these %ss:\n\n  %s\n\n"+
+				"They are no longer trusted once the new JWT without the key is deployed. Reissue them with another key in the same change.",
+				publicKey, kind, strings.Join(affected[publicKey], "\n  ")),
+		)
+	}
+
+	return diags
+}
+
+// validateIssuedJWTs checks that the known elements of a list of issued JWTs,
+// such as account_jwts, decode to claims of the given type.
+func validateIssuedJWTs(issuedJWTs types.List, attribute string, claimType jwt.ClaimType) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if issuedJWTs.IsNull() || issuedJWTs.IsUnknown() {
+		return diags
+	}
+
+	for i, element := range issuedJWTs.Elements() {
+		token, ok := element.(types.String)
+		if !ok || token.IsNull() || token.IsUnknown() {
+			continue
+		}
+		claims, err := jwt.Decode(token.ValueString())
+		if err == nil && claims.ClaimType() != claimType {
+			err = fmt.Errorf("expected claim type %s, got %s", claimType, claims.ClaimType())
+		}
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root(attribute).AtListIndex(i),
+				"Invalid "+string(claimType)+" JWT",
+				"Failed to decode "+string(claimType)+" JWT: "+err.Error(),
+			)
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/nats-io/jwt/v2"
+	"github.com/nats-io/nkeys"
+)
+
+func TestSigningKeyPublicKey(t *testing.T) {
+	operatorKP, _ := nkeys.CreateOperator()
+	operatorPubKey, _ := operatorKP.PublicKey()
+	operatorSeed, _ := operatorKP.Seed()
+	accountKP, _ := nkeys.CreateAccount()
+	accountPubKey, _ := accountKP.PublicKey()
+	accountSeed, _ := accountKP.Seed()
+
+	tests := map[string]struct {
+		key           string
+		expected      string
+		expectedError string
+	}{
+		"public key":         {key: operatorPubKey, expected: operatorPubKey},
+		"seed":               {key: string(operatorSeed), expected: operatorPubKey},
+		"account public key": {key: accountPubKey, expectedError: "must be operator public keys or operator seeds"},
+		"account seed":       {key: string(accountSeed), expectedError: "is not an operator seed"},
+		"invalid seed":       {key: "SOBROKEN", expectedError: "signing key seed is invalid"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			publicKey, err := signingKeyPublicKey(tc.key, nkeys.PrefixByteOperator)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tc.expectedError, err)
+				}
+				if strings.Contains(err.Error(), string(accountSeed)) {
+					t.Errorf("error leaks the seed: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if publicKey != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, publicKey)
+			}
+		})
+	}
+
+	publicKey, err := signingKeyPublicKey(string(accountSeed), nkeys.PrefixByteAccount)
+	if err != nil || publicKey != accountPubKey {
+		t.Errorf("expected account seed to derive %s, got %s, %v", accountPubKey, publicKey, err)
+	}
+}
+
+func TestSigningKeyPublicKeys(t *testing.T) {
+	ctx := context.Background()
+	aKP, _ := nkeys.CreateAccount()
+	aPubKey, _ := aKP.PublicKey()
+	aSeed, _ := aKP.Seed()
+	bKP, _ := nkeys.CreateAccount()
+	bPubKey, _ := bKP.PublicKey()
+
+	keys := func(values ...string) types.List {
+		list, _ := types.ListValueFrom(ctx, types.StringType, values)
+		return list
+	}
+
+	want := []string{aPubKey, bPubKey}
+	slices.Sort(want)
+	got, diags := signingKeyPublicKeys(ctx, keys(bPubKey, string(aSeed), aPubKey, bPubKey), nkeys.PrefixByteAccount)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if _, diags := signingKeyPublicKeys(ctx, keys(aPubKey, "SABROKEN"), nkeys.PrefixByteAccount); !diags.HasError() {
+		t.Error("expected error for invalid signing key")
+	}
+
+	// Order, duplicates and seeds for public keys make the same JWT
+	if !sameSigningKeys(ctx, keys(aPubKey, bPubKey), keys(bPubKey, string(aSeed), bPubKey), nkeys.PrefixByteAccount) {
+		t.Error("expected reordered signing keys to be the same")
+	}
+	if !sameSigningKeys(ctx, types.ListNull(types.StringType), keys(), nkeys.PrefixByteAccount) {
+		t.Error("expected null and empty signing keys to be the same")
+	}
+	if sameSigningKeys(ctx, keys(aPubKey, bPubKey), keys(aPubKey), nkeys.PrefixByteAccount) {
+		t.Error("expected a removed signing key to differ")
+	}
+	if sameSigningKeys(ctx, keys(aPubKey), types.ListUnknown(types.StringType), nkeys.PrefixByteAccount) {
+		t.Error("expected unknown signing keys to differ")
+	}
+}
+
+func TestRemovedSigningKeyWarnings(t *testing.T) {
+	ctx := context.Background()
+
+	list := func(values ...string) types.List {
+		l, _ := types.ListValueFrom(ctx, types.StringType, values)
+		return l
+	}
+
+	signingKP, _ := nkeys.CreateAccount()
+	signingPubKey, _ := signingKP.PublicKey()
+	signingSeed, _ := signingKP.Seed()
+	otherKP, _ := nkeys.CreateAccount()
+	otherPubKey, _ := otherKP.PublicKey()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+
+	userClaims := jwt.NewUserClaims(userPubKey)
+	userClaims.Name = "alice"
+	userJWT, err := userClaims.Encode(signingKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Kept keys, also when switching between seed and public key, do not warn
+	diags := removedSigningKeyWarnings(ctx, list(string(signingSeed)), list(signingPubKey), list(userJWT), nkeys.PrefixByteAccount, "user")
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+
+	// A removed key that issued no JWT does not warn
+	diags = removedSigningKeyWarnings(ctx, list(signingPubKey, otherPubKey), list(signingPubKey), list(userJWT), nkeys.PrefixByteAccount, "user")
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+
+	diags = removedSigningKeyWarnings(ctx, list(signingPubKey), list(), list(userJWT, "unknown"), nkeys.PrefixByteAccount, "user")
+	if diags.WarningsCount() != 1 || !strings.Contains(diags[0].Detail(), "alice ("+userPubKey+")") {
+		t.Errorf("expected a warning naming the user, got %v", diags)
+	}
+
+	// Unknown JWTs are being reissued
+	diags = removedSigningKeyWarnings(ctx, list(signingPubKey), list(), types.ListUnknown(types.StringType), nkeys.PrefixByteAccount, "user")
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+
+	// An unknown planned key may be the current one
+	planned := types.ListValueMust(types.StringType, []attr.Value{types.StringUnknown()})
+	diags = removedSigningKeyWarnings(ctx, list(signingPubKey), planned, list(userJWT), nkeys.PrefixByteAccount, "user")
+	if len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+}
+
+func TestValidateIssuedJWTs(t *testing.T) {
+	ctx := context.Background()
+
+	accountKP, _ := nkeys.CreateAccount()
+	userKP, _ := nkeys.CreateUser()
+	userPubKey, _ := userKP.PublicKey()
+	userJWT, err := jwt.NewUserClaims(userPubKey).Encode(accountKP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid, _ := types.ListValueFrom(ctx, types.StringType, []string{userJWT})
+	if diags := validateIssuedJWTs(valid, "user_jwts", jwt.UserClaim); diags.HasError() {
+		t.Errorf("unexpected error: %v", diags)
+	}
+	if diags := validateIssuedJWTs(valid, "account_jwts", jwt.AccountClaim); !diags.HasError() || !strings.Contains(diags[0].Detail(), "expected claim type account") {
+		t.Errorf("expected claim type error, got %v", diags)
+	}
+	invalid, _ := types.ListValueFrom(ctx, types.StringType, []string{"not a jwt"})
+	if diags := validateIssuedJWTs(invalid, "user_jwts", jwt.UserClaim); !diags.HasError() {
+		t.Error("expected error for invalid JWT")
+	}
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// stateEncryptionKeyEnv is the environment variable the state_encryption_key
+// provider attribute defaults to.
+const stateEncryptionKeyEnv = "NSC_STATE_ENCRYPTION_KEY"
+
+// encryptedSeedPrefix marks seeds encrypted with the state encryption key.
+// Plain seeds always start with 'S', so the two cannot be confused.
+const encryptedSeedPrefix = "nscenc:v1:"
+
+// stateCipher encrypts seeds before they are written to state and decrypts
+// them when they are used, with AES-256-GCM.
+type stateCipher struct {
+	aead cipher.AEAD
+}
+
+// newStateCipher parses a base64 encoded 256-bit key.
+func newStateCipher(key string) (*stateCipher, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64 encoded: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d; generate one with `openssl rand -base64 32`", len(raw))
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &stateCipher{aead: aead}, nil
+}
+
+// isEncryptedSeed reports whether a value is a seed encrypted with a state
+// encryption key.
+func isEncryptedSeed(value string) bool {
+	return strings.HasPrefix(value, encryptedSeedPrefix)
+}
+
+// encrypt returns the encrypted form of a seed. A nil cipher returns the seed
+// unchanged, as does an already encrypted seed.
+func (c *stateCipher) encrypt(seed string) (string, error) {
+	if c == nil || isEncryptedSeed(seed) {
+		return seed, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(seed), nil)
+	return encryptedSeedPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt returns the plain form of a seed. Plain seeds are returned
+// unchanged, so state written before the key was configured keeps working.
+// Errors do not include the value.
+func (c *stateCipher) decrypt(value string) (string, error) {
+	if !isEncryptedSeed(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", fmt.Errorf("seed is encrypted with a state encryption key; set state_encryption_key on the provider or the %s environment variable", stateEncryptionKeyEnv)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, encryptedSeedPrefix))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", fmt.Errorf("encrypted seed is malformed")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	seed, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt seed; it was encrypted with a different state encryption key")
+	}
+	return string(seed), nil
+}
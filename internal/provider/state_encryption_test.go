@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/nats-io/nkeys"
+)
+
+const testStateEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestNewStateCipher(t *testing.T) {
+	if _, err := newStateCipher(testStateEncryptionKey); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := newStateCipher("not base64!"); err == nil || !strings.Contains(err.Error(), "base64") {
+		t.Errorf("expected base64 error, got %v", err)
+	}
+	if _, err := newStateCipher("c2hvcnQ="); err == nil || !strings.Contains(err.Error(), "32 bytes") {
+		t.Errorf("expected key length error, got %v", err)
+	}
+}
+
+func TestStateCipher(t *testing.T) {
+	const seed = "SUAIBDPBAUTWCWBKIO6XHQNINK5FWJW4OHLXC3HQ2KFE4PEJUA44CNHTC4"
+
+	c, err := newStateCipher(testStateEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := c.encrypt(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncryptedSeed(encrypted) || strings.Contains(encrypted, seed) {
+		t.Fatalf("expected an encrypted seed, got %s", encrypted)
+	}
+	if again, _ := c.encrypt(encrypted); again != encrypted {
+		t.Error("expected an encrypted seed not to be encrypted twice")
+	}
+
+	decrypted, err := c.decrypt(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted != seed {
+		t.Errorf("expected %s, got %s", seed, decrypted)
+	}
+
+	// Plain seeds pass through, with or without a key
+	if got, err := c.decrypt(seed); err != nil || got != seed {
+		t.Errorf("expected plain seed to pass through, got %q, %v", got, err)
+	}
+	var none *stateCipher
+	if got, _ := none.encrypt(seed); got != seed {
+		t.Errorf("expected a nil cipher not to encrypt, got %s", got)
+	}
+
+	// Encrypted seeds need the key they were encrypted with
+	if _, err := none.decrypt(encrypted); err == nil || !strings.Contains(err.Error(), "state_encryption_key") {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+	other, err := newStateCipher("ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.decrypt(encrypted); err == nil || !strings.Contains(err.Error(), "different state encryption key") {
+		t.Errorf("expected wrong key error, got %v", err)
+	}
+	if _, err := c.decrypt(encryptedSeedPrefix + "!!!"); err == nil || !strings.Contains(err.Error(), "malformed") {
+		t.Errorf("expected malformed error, got %v", err)
+	}
+}
+
+func TestKeypairCache_fromEncryptedSeed(t *testing.T) {
+	kp, err := nkeys.CreateAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, _ := kp.Seed()
+	publicKey, _ := kp.PublicKey()
+
+	c, err := newStateCipher(testStateEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := c.encrypt(string(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newKeypairCache()
+	cache.cipher = c
+	got, err := cache.fromSeed(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPublicKey, _ := got.PublicKey(); gotPublicKey != publicKey {
+		t.Errorf("expected public key %s, got %s", publicKey, gotPublicKey)
+	}
+
+	if _, err := newKeypairCache().fromSeed(encrypted); err == nil {
+		t.Error("expected error for encrypted seed without key")
+	}
+}
+
+func TestAccStateEncryption(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "nsc" {
+  state_encryption_key = %q
+}
+`, testStateEncryptionKey) + testAccUserResourceConfig("TestUser"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("nsc_nkey.account", "seed", regexp.MustCompile("^"+regexp.QuoteMeta(encryptedSeedPrefix))),
+					resource.TestCheckResourceAttrSet("nsc_user.test", "jwt"),
+					testAccCheckJWTIssuer("nsc_user.test", "nsc_nkey.account", "public_key"),
+				),
+			},
+			{
+				Config:      providerConfig + testAccUserResourceConfig("UpdatedUser"),
+				ExpectError: regexp.MustCompile("state_encryption_key"),
+			},
+		},
+	})
+}
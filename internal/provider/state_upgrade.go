@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// rawStateAttributes decodes the JSON prior state of a resource into a map
+// of attribute values. Upgraders work on raw JSON rather than a prior schema
+// so a single upgrader can handle every state shape a version has produced.
+func rawStateAttributes(req resource.UpgradeStateRequest) (map[string]any, error) {
+	if req.RawState == nil || req.RawState.JSON == nil {
+		return nil, fmt.Errorf("prior state is not available in JSON format")
+	}
+
+	// Keep numbers as written; large byte limits do not fit a float64
+	decoder := json.NewDecoder(bytes.NewReader(req.RawState.JSON))
+	decoder.UseNumber()
+
+	var attrs map[string]any
+	if err := decoder.Decode(&attrs); err != nil {
+		return nil, fmt.Errorf("failed to decode prior state: %w", err)
+	}
+
+	return attrs, nil
+}
+
+// renameStateAttribute moves a value from a legacy attribute name to its
+// replacement, unless the replacement already holds a value.
+func renameStateAttribute(attrs map[string]any, from, to string) {
+	value, ok := attrs[from]
+	if !ok {
+		return
+	}
+	delete(attrs, from)
+
+	if value == nil {
+		return
+	}
+	if current, ok := attrs[to]; ok && current != nil {
+		return
+	}
+	attrs[to] = value
+}
+
+// defaultStateAttribute sets an attribute that is missing or null in prior state.
+func defaultStateAttribute(attrs map[string]any, name string, value any) {
+	if current, ok := attrs[name]; !ok || current == nil {
+		attrs[name] = value
+	}
+}
+
+// stringifyStateAttributes converts numbers to strings, for attributes that
+// changed from a number to a string type such as ByteSizeType.
+func stringifyStateAttributes(attrs map[string]any, names ...string) {
+	for _, name := range names {
+		if value, ok := attrs[name].(json.Number); ok {
+			attrs[name] = value.String()
+		}
+	}
+}
+
+// stringifyUpgrader returns a state upgrader to the schema s that only
+// converts the named attributes from numbers to strings. kind names the
+// resource in errors.
+func stringifyUpgrader(s schema.Schema, kind string, names ...string) resource.StateUpgrader {
+	return resource.StateUpgrader{
+		StateUpgrader: func(_ context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+			attrs, err := rawStateAttributes(req)
+			if err != nil {
+				resp.Diagnostics.AddError("Unable to Upgrade "+kind+" State", err.Error())
+				return
+			}
+
+			stringifyStateAttributes(attrs, names...)
+
+			resp.DynamicValue, err = upgradedState(attrs, s)
+			if err != nil {
+				resp.Diagnostics.AddError("Unable to Upgrade "+kind+" State", err.Error())
+			}
+		},
+	}
+}
+
+// upgradedState encodes attributes as state for the given schema. Attributes
+// that are no longer part of the schema are dropped, missing ones become null.
+// Lists and sets share the same JSON encoding, so changing an attribute from
+// a list to a set only requires a schema version bump.
+func upgradedState(attrs map[string]any, s schema.Schema) (*tfprotov6.DynamicValue, error) {
+	for name := range attrs {
+		_, isAttribute := s.Attributes[name]
+		_, isBlock := s.Blocks[name]
+		if !isAttribute && !isBlock {
+			delete(attrs, name)
+		}
+	}
+
+	encoded, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode upgraded state: %w", err)
+	}
+
+	return &tfprotov6.DynamicValue{JSON: encoded}, nil
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var _ validator.String = permissionSubjectValidator{}
+var _ validator.String = nonNegativeDurationValidator{}
+
+// permissionSubjectValidator validates a single allow/deny permission entry.
+// Subscribe permissions may carry a queue group in the "subject queue" form,
+// publish permissions may not.
+type permissionSubjectValidator struct {
+	permitQueue bool
+}
+
+// publishPermission returns a validator for allow_pub/deny_pub entries.
+func publishPermission() validator.String {
+	return permissionSubjectValidator{permitQueue: false}
+}
+
+// subscribePermission returns a validator for allow_sub/deny_sub entries.
+func subscribePermission() validator.String {
+	return permissionSubjectValidator{permitQueue: true}
+}
+
+func (v permissionSubjectValidator) Description(_ context.Context) string {
+	if v.permitQueue {
+		return "must be a valid NATS subject, optionally followed by a space and a queue group name"
+	}
+	return "must be a valid NATS subject"
+}
+
+func (v permissionSubjectValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v permissionSubjectValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	tokens := strings.Split(value, " ")
+
+	switch len(tokens) {
+	case 1:
+		if err := validatePermissionSubject(tokens[0]); err != nil {
+			resp.Diagnostics.AddAttributeError(req.Path, "Invalid permission subject", err.Error())
+		}
+	case 2:
+		if !v.permitQueue {
+			resp.Diagnostics.AddAttributeError(
+				req.Path,
+				"Invalid permission subject",
+				fmt.Sprintf("Publish permission %q cannot contain a queue group; queue groups are only allowed on subscribe permissions", value),
+			)
+			return
+		}
+		if err := validatePermissionSubject(tokens[0]); err != nil {
+			resp.Diagnostics.AddAttributeError(req.Path, "Invalid permission subject", err.Error())
+		}
+		if err := validateQueueName(tokens[1]); err != nil {
+			resp.Diagnostics.AddAttributeError(req.Path, "Invalid permission queue group", err.Error())
+		}
+	default:
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid permission subject",
+			fmt.Sprintf("Permission %q contains too many spaces; expected \"subject\" or \"subject queue\"", value),
+		)
+	}
+}
+
+// permissionTemplate matches a single template in a permission subject, e.g.
+// {{name()}} or {{tag(region)}}.
+var permissionTemplate = regexp.MustCompile(`{{[^{}]*}}`)
+
+// permissionTemplateFunc matches the template functions nats-server expands
+// for users issued by a scoped signing key.
+var permissionTemplateFunc = regexp.MustCompile(`^{{(?:(?:name|subject|account-name|account-subject)\(\)|(?:tag|account-tag)\([^()\s.*>]+\))}}$`)
+
+// expandPermissionTemplates checks the templates of a permission subject and
+// replaces each with a literal token, so the subject can be validated as if
+// the templates were expanded.
+func expandPermissionTemplates(subject string) (string, error) {
+	for _, template := range permissionTemplate.FindAllString(subject, -1) {
+		if !permissionTemplateFunc.MatchString(template) {
+			return "", fmt.Errorf("subject %q contains unsupported template %s; supported templates are {{name()}}, {{subject()}}, {{tag(name)}}, {{account-name()}}, {{account-subject()}} and {{account-tag(name)}}", subject, template)
+		}
+	}
+	expanded := permissionTemplate.ReplaceAllString(subject, "template")
+	if strings.ContainsAny(expanded, "{}") {
+		return "", fmt.Errorf("subject %q contains an unterminated template", subject)
+	}
+	return expanded, nil
+}
+
+// validatePermissionSubject checks the subject part of a permission entry.
+func validatePermissionSubject(subject string) error {
+	if subject == "" {
+		return fmt.Errorf("subject cannot be empty (check for leading, trailing or repeated spaces)")
+	}
+	expanded, err := expandPermissionTemplates(subject)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(expanded, ".") || strings.HasSuffix(expanded, ".") {
+		return fmt.Errorf("subject %q cannot start or end with '.'", subject)
+	}
+	if strings.Contains(expanded, "..") {
+		return fmt.Errorf("subject %q cannot contain consecutive '.'", subject)
+	}
+	tokens := strings.Split(expanded, ".")
+	for i, token := range tokens {
+		if token == ">" && i != len(tokens)-1 {
+			return fmt.Errorf("subject %q can only use '>' as the last token", subject)
+		}
+		if len(token) > 1 && strings.ContainsAny(token, "*>") {
+			return fmt.Errorf("subject %q contains a wildcard that is not a full token", subject)
+		}
+	}
+	return nil
+}
+
+// validateQueueName checks the queue group part of a subscribe permission.
+// Queue permissions support the same wildcard tokens as subjects.
+func validateQueueName(queue string) error {
+	if queue == "" {
+		return fmt.Errorf("queue group cannot be empty")
+	}
+	if err := validatePermissionSubject(queue); err != nil {
+		return fmt.Errorf("invalid queue group: %w", err)
+	}
+	return nil
+}
+
+// nonNegativeDuration returns a validator that rejects negative durations of
+// GoDuration attributes. Malformed durations are reported by the type.
+func nonNegativeDuration() validator.String {
+	return nonNegativeDurationValidator{}
+}
+
+type nonNegativeDurationValidator struct{}
+
+func (v nonNegativeDurationValidator) Description(_ context.Context) string {
+	return "value must not be a negative duration"
+}
+
+func (v nonNegativeDurationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v nonNegativeDurationValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	duration, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil {
+		return
+	}
+	if duration < 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid duration",
+			fmt.Sprintf("Attribute %s must not be negative, got: %s", req.Path, req.ConfigValue.ValueString()),
+		)
+	}
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestValidatePermissionSubject_templates(t *testing.T) {
+	tests := []struct {
+		subject string
+		err     string
+	}{
+		{subject: "users.{{name()}}.>"},
+		{subject: "{{subject()}}.inbox"},
+		{subject: "regions.{{tag(region)}}.*"},
+		{subject: "_INBOX_{{account-name()}}.>"},
+		{subject: "accounts.{{account-subject()}}.{{account-tag(team)}}"},
+		{subject: "users.{{username()}}", err: "unsupported template"},
+		{subject: "users.{{tag()}}", err: "unsupported template"},
+		{subject: "users.{{tag(a.b)}}", err: "unsupported template"},
+		{subject: "users.{{name()", err: "unterminated template"},
+		{subject: "users.name()}}", err: "unterminated template"},
+		{subject: "{{name()}}.>.x", err: "'>' as the last token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			err := validatePermissionSubject(tt.subject)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestNonNegativeDuration(t *testing.T) {
+	tests := map[string]struct {
+		value       types.String
+		expectError bool
+	}{
+		"positive":  {value: types.StringValue("5m")},
+		"zero":      {value: types.StringValue("0s")},
+		"negative":  {value: types.StringValue("-1h"), expectError: true},
+		"malformed": {value: types.StringValue("soon")},
+		"null":      {value: types.StringNull()},
+		"unknown":   {value: types.StringUnknown()},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := validator.StringRequest{Path: path.Root("expires_in"), ConfigValue: tc.value}
+			var resp validator.StringResponse
+			nonNegativeDuration().ValidateString(context.Background(), req, &resp)
+			if resp.Diagnostics.HasError() != tc.expectError {
+				t.Errorf("expected error = %t, got %v", tc.expectError, resp.Diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/nats-io/jwt/v2"
+)
+
+// ValidityModel holds the expiry and start attributes shared by all JWT
+// issuing resources (ADR-007). Relative durations are resolved into the
+// absolute attributes whenever a JWT is issued.
+type ValidityModel struct {
+	ExpiresIn timetypes.GoDuration `tfsdk:"expires_in"`
+	ExpiresAt timetypes.RFC3339    `tfsdk:"expires_at"`
+	StartsIn  timetypes.GoDuration `tfsdk:"starts_in"`
+	StartsAt  timetypes.RFC3339    `tfsdk:"starts_at"`
+	Backdate  timetypes.GoDuration `tfsdk:"backdate"`
+}
+
+// validate checks that relative and absolute variants are not combined.
+func (m ValidityModel) validate() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// Validate expiry attributes are mutually exclusive
+	if !m.ExpiresIn.IsNull() && !m.ExpiresIn.IsUnknown() && !m.ExpiresAt.IsNull() && !m.ExpiresAt.IsUnknown() {
+		diags.AddError(
+			"Conflicting Expiry Configuration",
+			"Only one of 'expires_in' or 'expires_at' can be specified.",
+		)
+	}
+
+	// Validate start attributes are mutually exclusive
+	if !m.StartsIn.IsNull() && !m.StartsIn.IsUnknown() && !m.StartsAt.IsNull() && !m.StartsAt.IsUnknown() {
+		diags.AddError(
+			"Conflicting Start Configuration",
+			"Only one of 'starts_in' or 'starts_at' can be specified.",
+		)
+	}
+
+	return diags
+}
+
+// apply sets the expiry and start time of the claims and stores the resolved
+// absolute timestamps back into the model.
+func (m *ValidityModel) apply(claims *jwt.ClaimsData) diag.Diagnostics {
+	var diags diag.Diagnostics
+	var d diag.Diagnostics
+
+	claims.Expires, d = resolveTimestamp(m.ExpiresIn, &m.ExpiresAt)
+	diags.Append(d...)
+
+	// Only starts relative to now are backdated; starts_at is taken as is
+	relativeStart := (!m.StartsIn.IsNull() && !m.StartsIn.IsUnknown()) || m.StartsAt.IsNull() || m.StartsAt.IsUnknown()
+
+	claims.NotBefore, d = resolveTimestamp(m.StartsIn, &m.StartsAt)
+	diags.Append(d...)
+
+	if relativeStart && !m.Backdate.IsNull() && !m.Backdate.IsUnknown() {
+		backdate, d := m.Backdate.ValueGoDuration()
+		diags.Append(d...)
+		if backdate > 0 {
+			if claims.NotBefore == 0 {
+				// No start configured: valid from now - backdate, but keep
+				// starts_at null so it does not change on every re-issue
+				claims.NotBefore = time.Now().Add(-backdate).Unix()
+			} else {
+				start := time.Unix(claims.NotBefore, 0).Add(-backdate)
+				claims.NotBefore = start.Unix()
+				m.StartsAt = timetypes.NewRFC3339TimeValue(start)
+			}
+		}
+	}
+
+	return diags
+}
+
+// resolveTimestamp returns the Unix timestamp for a relative/absolute
+// attribute pair, or 0 when neither is set. A relative duration is computed
+// from now and written to the absolute attribute; a zero duration clears it.
+func resolveTimestamp(in timetypes.GoDuration, at *timetypes.RFC3339) (int64, diag.Diagnostics) {
+	if !in.IsNull() && !in.IsUnknown() {
+		// Relative duration - compute and store absolute
+		duration, diags := in.ValueGoDuration()
+		if diags.HasError() {
+			return 0, diags
+		}
+		if duration == 0 {
+			*at = timetypes.NewRFC3339Null()
+			return 0, diags
+		}
+		t := time.Now().Add(duration)
+		*at = timetypes.NewRFC3339TimeValue(t)
+		return t.Unix(), diags
+	}
+
+	if !at.IsNull() && !at.IsUnknown() {
+		// Absolute timestamp provided
+		t, diags := at.ValueRFC3339Time()
+		if diags.HasError() {
+			return 0, diags
+		}
+		return t.Unix(), diags
+	}
+
+	// Not specified - set to null
+	*at = timetypes.NewRFC3339Null()
+	return 0, nil
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timetypes/timetypes"
+	"github.com/nats-io/jwt/v2"
+)
+
+func TestValidityModel_applyBackdate(t *testing.T) {
+	now := time.Now()
+
+	tests := map[string]struct {
+		model         ValidityModel
+		expectedStart time.Time
+		expectStartAt bool
+	}{
+		"no backdate": {
+			model: ValidityModel{},
+		},
+		"no start": {
+			model:         ValidityModel{Backdate: timetypes.NewGoDurationValue(5 * time.Minute)},
+			expectedStart: now.Add(-5 * time.Minute),
+		},
+		"starts in": {
+			model: ValidityModel{
+				StartsIn: timetypes.NewGoDurationValue(time.Hour),
+				Backdate: timetypes.NewGoDurationValue(5 * time.Minute),
+			},
+			expectedStart: now.Add(55 * time.Minute),
+			expectStartAt: true,
+		},
+		"starts at": {
+			model: ValidityModel{
+				StartsAt: timetypes.NewRFC3339TimeValue(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+				Backdate: timetypes.NewGoDurationValue(5 * time.Minute),
+			},
+			expectedStart: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectStartAt: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			model := tc.model
+
+			var claims jwt.ClaimsData
+			if diags := model.apply(&claims); diags.HasError() {
+				t.Fatalf("unexpected error: %v", diags)
+			}
+
+			if tc.expectedStart.IsZero() {
+				if claims.NotBefore != 0 {
+					t.Errorf("expected no start, got %d", claims.NotBefore)
+				}
+				return
+			}
+			if diff := claims.NotBefore - tc.expectedStart.Unix(); diff < -2 || diff > 2 {
+				t.Errorf("expected start %d, got %d", tc.expectedStart.Unix(), claims.NotBefore)
+			}
+			if model.StartsAt.IsNull() == tc.expectStartAt {
+				t.Errorf("expected starts_at set = %t, got %q", tc.expectStartAt, model.StartsAt.ValueString())
+			}
+		})
+	}
+}